@@ -17,10 +17,13 @@ import (
 	"github.com/docker/machine/drivers/generic"
 	"github.com/docker/machine/drivers/google"
 	"github.com/docker/machine/drivers/hyperv"
+	"github.com/docker/machine/drivers/libvirt"
 	"github.com/docker/machine/drivers/none"
 	"github.com/docker/machine/drivers/openstack"
 	"github.com/docker/machine/drivers/rackspace"
+	"github.com/docker/machine/drivers/scaleway"
 	"github.com/docker/machine/drivers/softlayer"
+	"github.com/docker/machine/drivers/vagrant"
 	"github.com/docker/machine/drivers/virtualbox"
 	"github.com/docker/machine/drivers/vmwarefusion"
 	"github.com/docker/machine/drivers/vmwarevcloudair"
@@ -117,6 +120,16 @@ func main() {
 			Value:  mcndirs.GetBaseDir(),
 			Usage:  "Configures storage path",
 		},
+		cli.BoolFlag{
+			EnvVar: "MACHINE_STORAGE_READ_ONLY",
+			Name:   "storage-read-only",
+			Usage:  "Refuse to change the store, e.g. to look at the machines of a store shared by a team",
+		},
+		cli.BoolFlag{
+			EnvVar: "MACHINE_STORAGE_KEYRING",
+			Name:   "storage-keyring",
+			Usage:  "Keep the secrets of the drivers in the keychain of the OS instead of config.json, for a store nobody else uses",
+		},
 		cli.StringFlag{
 			EnvVar: "MACHINE_TLS_CA_CERT",
 			Name:   "tls-ca-cert",
@@ -141,6 +154,12 @@ func main() {
 			Usage:  "Private key used in client TLS auth",
 			Value:  "",
 		},
+		cli.StringSliceFlag{
+			EnvVar: "MACHINE_DRIVER_CALL_TIMEOUT",
+			Name:   "driver-call-timeout",
+			Usage:  "Timeout of the calls to the drivers querying the machines, e.g. 30s, or of one call, e.g. GetState=30s",
+			Value:  &cli.StringSlice{},
+		},
 		cli.StringFlag{
 			EnvVar: "MACHINE_GITHUB_API_TOKEN",
 			Name:   "github-api-token",
@@ -152,6 +171,16 @@ func main() {
 			Name:   "native-ssh",
 			Usage:  "Use the native (Go-based) SSH implementation.",
 		},
+		cli.BoolFlag{
+			EnvVar: "MACHINE_MANAGE_SSH_CONFIG",
+			Name:   "manage-ssh-config",
+			Usage:  "Keep the entries of the machines up to date in the ssh_config file of the store, to include from ~/.ssh/config",
+		},
+		cli.BoolFlag{
+			EnvVar: "MACHINE_NO_SSH_MULTIPLEXING",
+			Name:   "no-ssh-multiplexing",
+			Usage:  "Open a new SSH connection for every command run on the machines instead of reusing them",
+		},
 		cli.StringFlag{
 			EnvVar: "MACHINE_BUGSNAG_API_TOKEN",
 			Name:   "bugsnag-api-token",
@@ -181,14 +210,20 @@ func runDriver(driverName string) {
 		plugin.RegisterDriver(google.NewDriver("", ""))
 	case "hyperv":
 		plugin.RegisterDriver(hyperv.NewDriver("", ""))
+	case "libvirt":
+		plugin.RegisterDriver(libvirt.NewDriver("", ""))
 	case "none":
 		plugin.RegisterDriver(none.NewDriver("", ""))
 	case "openstack":
 		plugin.RegisterDriver(openstack.NewDriver("", ""))
 	case "rackspace":
 		plugin.RegisterDriver(rackspace.NewDriver("", ""))
+	case "scaleway":
+		plugin.RegisterDriver(scaleway.NewDriver("", ""))
 	case "softlayer":
 		plugin.RegisterDriver(softlayer.NewDriver("", ""))
+	case "vagrant":
+		plugin.RegisterDriver(vagrant.NewDriver("", ""))
 	case "virtualbox":
 		plugin.RegisterDriver(virtualbox.NewDriver("", ""))
 	case "vmwarefusion":
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/docker/machine/commands/formatter"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+const auditDefaultFormat = "table {{ .Time }}\t{{ .User }}\t{{ .Machine }}\t{{ .Operation }}\t{{ .Details }}\t{{ .Error }}"
+
+var auditHeader = map[string]string{
+	"Time":      "TIME",
+	"User":      "USER",
+	"Hostname":  "HOSTNAME",
+	"Machine":   "MACHINE",
+	"Operation": "OPERATION",
+	"Details":   "DETAILS",
+	"Error":     "ERROR",
+}
+
+// auditItem is an entry of the audit log as printed by the templates.
+type auditItem struct {
+	Time      string
+	User      string
+	Hostname  string
+	Machine   string
+	Operation string
+	Details   string
+	Error     string
+}
+
+func cmdAudit(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 1 {
+		return ErrTooManyArguments
+	}
+
+	filter := persist.AuditFilter{
+		Machine:   c.Args().First(),
+		Operation: c.String("operation"),
+		User:      c.String("user"),
+	}
+
+	if since := c.String("since"); since != "" {
+		t, err := parseAuditTime(since, time.Now())
+		if err != nil {
+			return err
+		}
+		filter.Since = t
+	}
+
+	entries, err := libmachine.QueryAudit(api, filter)
+	if err != nil {
+		return err
+	}
+
+	format, err := formatter.Parse(c.String("format"), auditDefaultFormat)
+	if err != nil {
+		return err
+	}
+
+	w, err := format.Writer(os.Stdout, auditHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		item := auditItem{
+			Time:      entry.Time.Local().Format("2006-01-02 15:04:05"),
+			User:      entry.User,
+			Hostname:  entry.Hostname,
+			Machine:   entry.Machine,
+			Operation: entry.Operation,
+			Details:   entry.Details,
+			Error:     entry.Error,
+		}
+		if err := w.Write(item, entry); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+// parseAuditTime parses either a duration before now, e.g. 24h, or a RFC 3339
+// time.
+func parseAuditTime(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid time %q, expected a duration, e.g. 24h, or a RFC 3339 time", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,23 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuditTime(t *testing.T) {
+	now := time.Date(2017, 10, 2, 12, 0, 0, 0, time.UTC)
+
+	since, err := parseAuditTime("24h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 10, 1, 12, 0, 0, 0, time.UTC), since)
+
+	since, err = parseAuditTime("2017-09-30T08:00:00Z", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 9, 30, 8, 0, 0, 0, time.UTC), since)
+
+	_, err = parseAuditTime("yesterday", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,16 @@
+package commands
+
+import (
+	"github.com/docker/machine/commands/mcndirs"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+func cmdAutostart(c CommandLine, api libmachine.API) error {
+	enabled := !c.Bool("disable")
+
+	return runHostAction(persist.AuditConfigChange, func(h *host.Host) error {
+		return h.SetAutostart(enabled, mcndirs.GetBaseDir())
+	}, c, api)
+}
@@ -10,11 +10,14 @@ import (
 	"github.com/docker/machine/commands/mcndirs"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/crashreport"
+	"github.com/docker/machine/libmachine/credentials"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/mcnutils"
 	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/secrets"
 	"github.com/docker/machine/libmachine/ssh"
 )
 
@@ -54,6 +57,8 @@ type CommandLine interface {
 
 	GlobalString(name string) string
 
+	GlobalBool(name string) bool
+
 	FlagNames() (names []string)
 
 	Generic(name string) interface{}
@@ -95,6 +100,52 @@ func targetHost(c CommandLine, api libmachine.API) (string, error) {
 }
 
 func runAction(actionName string, c CommandLine, api libmachine.API) error {
+	hosts, err := loadActionHosts(c, api)
+	if err != nil {
+		return err
+	}
+
+	errs := runActionForeachMachine(actionName, api, hosts)
+	if len(errs) > 0 {
+		return consolidateErrs(errs)
+	}
+
+	return saveHosts(api, hosts)
+}
+
+// runHostAction is runAction for actions which take options, e.g. a graceful
+// stop. The action is recorded in the audit log as the operation, unless
+// empty.
+func runHostAction(operation string, action func(h *host.Host) error, c CommandLine, api libmachine.API) error {
+	hosts, err := loadActionHosts(c, api)
+	if err != nil {
+		return err
+	}
+
+	errorChan := make(chan error)
+	for _, h := range hosts {
+		go func(h *host.Host) {
+			errorChan <- libmachine.RunOperation(api, h.Name, operation, func() error {
+				return action(h)
+			})
+		}(h)
+	}
+
+	errs := []error{}
+	for range hosts {
+		if err := <-errorChan; err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return consolidateErrs(errs)
+	}
+
+	return saveHosts(api, hosts)
+}
+
+func loadActionHosts(c CommandLine, api libmachine.API) ([]*host.Host, error) {
 	var (
 		hostsToLoad []string
 	)
@@ -105,7 +156,7 @@ func runAction(actionName string, c CommandLine, api libmachine.API) error {
 	if len(c.Args()) == 0 {
 		target, err := targetHost(c, api)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		hostsToLoad = []string{target}
@@ -120,17 +171,23 @@ func runAction(actionName string, c CommandLine, api libmachine.API) error {
 		for _, err := range hostsInError {
 			errs = append(errs, err)
 		}
-		return consolidateErrs(errs)
+		return nil, consolidateErrs(errs)
 	}
 
 	if len(hosts) == 0 {
-		return ErrHostLoad
+		return nil, ErrHostLoad
 	}
 
-	if errs := runActionForeachMachine(actionName, hosts); len(errs) > 0 {
-		return consolidateErrs(errs)
+	for _, h := range hosts {
+		if h.InstanceMissing {
+			return nil, host.ErrInstanceMissing{Name: h.Name}
+		}
 	}
 
+	return hosts, nil
+}
+
+func saveHosts(api libmachine.API, hosts []*host.Host) error {
 	for _, h := range hosts {
 		if err := api.Save(h); err != nil {
 			return fmt.Errorf("Error saving host to store: %s", err)
@@ -150,6 +207,16 @@ func runCommand(command func(commandLine CommandLine, api libmachine.API) error)
 		}
 		api.GithubAPIToken = context.GlobalString("github-api-token")
 		api.Filestore.Path = context.GlobalString("storage-path")
+		api.Filestore.ReadOnly = context.GlobalBool("storage-read-only")
+		api.ManageSSHConfig = context.GlobalBool("manage-ssh-config")
+
+		callTimeouts, err := drivers.ParseCallTimeouts(context.GlobalStringSlice("driver-call-timeout"))
+		if err != nil {
+			log.Error(err)
+			osExit(1)
+			return
+		}
+		api.CallTimeouts = callTimeouts
 
 		// TODO (nathanleclaire): These should ultimately be accessed
 		// through the libmachine client by the rest of the code and
@@ -159,6 +226,23 @@ func runCommand(command func(commandLine CommandLine, api libmachine.API) error)
 		mcndirs.BaseDir = api.Filestore.Path
 		mcnutils.GithubAPIToken = api.GithubAPIToken
 		ssh.SetDefaultClient(api.SSHClientType)
+		if !context.GlobalBool("no-ssh-multiplexing") {
+			ssh.SetMultiplexing(ssh.DefaultControlDir(), ssh.DefaultControlPersist)
+			defer ssh.CloseConnections()
+		}
+
+		secretBox, err := secrets.DefaultBox()
+		if err != nil {
+			log.Error(err)
+			osExit(1)
+			return
+		}
+		api.Filestore.SecretBox = secretBox
+		// The keychain is personal, other users of the store could not
+		// read the secrets moved to it
+		if secretBox == nil && context.GlobalBool("storage-keyring") && !api.Filestore.ReadOnly {
+			api.Filestore.Keyring = credentials.OSKeyring{}
+		}
 
 		if err := command(&contextCommandLine{context}, api); err != nil {
 			log.Error(err)
@@ -205,6 +289,54 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:        "audit",
+		Usage:       "Print the audit log of the operations on the machines",
+		Description: "Argument is an optional machine name.",
+		Action:      runCommand(cmdAudit),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "operation",
+				Usage: "Only print the operations of this type, e.g. create or stop",
+			},
+			cli.StringFlag{
+				Name:  "user",
+				Usage: "Only print the operations of this user",
+			},
+			cli.StringFlag{
+				Name:  "since",
+				Usage: "Only print the operations since this time, either a duration before now, e.g. 24h, or a RFC 3339 time",
+			},
+			cli.StringFlag{
+				Name:  "format, f",
+				Usage: "Pretty-print the entries using a Go template, or json",
+			},
+		},
+	},
+	{
+		Name:        "autostart",
+		Usage:       "Start a machine when the host OS boots",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdAutostart),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "disable",
+				Usage: "Stop starting the machine when the host OS boots",
+			},
+		},
+	},
+	{
+		Name:   "completion",
+		Usage:  "Print the commands, machines and driver flags as JSON for shell completion",
+		Action: runCommand(cmdCompletion),
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "driver",
+				Usage: "Only include the flags of the driver, may be repeated. All the drivers by default",
+				Value: &cli.StringSlice{},
+			},
+		},
+	},
 	{
 		Name:        "config",
 		Usage:       "Print the connection config for machine",
@@ -225,6 +357,42 @@ var Commands = []cli.Command{
 		Action:          runCommand(cmdCreateOuter),
 		SkipFlagParsing: true,
 	},
+	{
+		Name:        "deregister",
+		Usage:       "Remove a machine from the store without removing its instance",
+		Description: "Argument is a machine name.",
+		Action:      runCommand(cmdDeregister),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "archive",
+				Usage: "Write the machine to an archive first, to register it again with the register command",
+			},
+			cli.BoolFlag{
+				Name:  "y",
+				Usage: "Assumes automatic yes to proceed without an archive, without prompting further user confirmation",
+			},
+		},
+	},
+	{
+		Name:        "diagnose",
+		Usage:       "Check that machines match their configuration",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdDiagnose),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "repair",
+				Usage: "Repair what does not match the configuration",
+			},
+		},
+	},
+	{
+		Flags:           discoverFlags,
+		Name:            "discover",
+		Usage:           "List the instances tagged as docker hosts which are missing from the store, and adopt them",
+		Description:     fmt.Sprintf("Arguments are the names of the machines to adopt with --adopt. Run '%s discover --driver name --help' to include the flags of that driver in the help text.", os.Args[0]),
+		Action:          runCommand(cmdDiscoverOuter),
+		SkipFlagParsing: true,
+	},
 	{
 		Name:        "env",
 		Usage:       "Display the commands to set up the environment for the Docker client",
@@ -257,9 +425,13 @@ var Commands = []cli.Command{
 		Flags: []cli.Flag{
 			cli.StringFlag{
 				Name:  "format, f",
-				Usage: "Format the output using the given go template.",
+				Usage: "Format the output using the given go template, or json for the stable JSON schema",
 				Value: "",
 			},
+			cli.BoolFlag{
+				Name:  "show-secrets",
+				Usage: "Show the API tokens, passwords and other secrets of the driver, redacted otherwise",
+			},
 		},
 	},
 	{
@@ -295,7 +467,66 @@ var Commands = []cli.Command{
 			},
 			cli.StringFlag{
 				Name:  "format, f",
-				Usage: "Pretty-print machines using a Go template",
+				Usage: "Pretty-print machines using a Go template, or json for the stable JSON schema",
+			},
+		},
+	},
+	{
+		Name:  "port",
+		Usage: "Open or close ports of a machine in the firewall of the provider",
+		Subcommands: []cli.Command{
+			{
+				Name:        "open",
+				Usage:       "Open ports of a machine",
+				Description: "Arguments are a machine name and one or more ports in the port[/protocol] format.",
+				Action:      runCommand(cmdPortOpen),
+			},
+			{
+				Name:        "close",
+				Usage:       "Close ports of a machine",
+				Description: "Arguments are a machine name and one or more ports in the port[/protocol] format.",
+				Action:      runCommand(cmdPortClose),
+			},
+		},
+	},
+	{
+		Name:  "profile",
+		Usage: "Manage the profiles to create machines from",
+		Subcommands: []cli.Command{
+			{
+				Name:   "ls",
+				Usage:  "List profiles",
+				Action: runCommand(cmdProfileLs),
+			},
+			{
+				Name:        "inspect",
+				Usage:       "Print the flags of a profile",
+				Description: "Argument is a profile name.",
+				Action:      runCommand(cmdProfileInspect),
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "show-secrets",
+						Usage: "Show the values of the flags setting API tokens, passwords and other secrets, redacted otherwise",
+					},
+				},
+			},
+			{
+				Name:        "rm",
+				Usage:       "Remove profiles",
+				Description: "Argument(s) are one or more profile names.",
+				Action:      runCommand(cmdProfileRm),
+			},
+		},
+	},
+	{
+		Name:        "protect",
+		Usage:       "Protect machines against removal",
+		Description: "Argument(s) are one or more machine names. Protected machines are only removed by rm --override-protection.",
+		Action:      runCommand(cmdProtect),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "off",
+				Usage: "Lift the protection of the machines",
 			},
 		},
 	},
@@ -304,6 +535,21 @@ var Commands = []cli.Command{
 		Usage:  "Re-provision existing machines",
 		Action: runCommand(cmdProvision),
 	},
+	{
+		Name:   "prune",
+		Usage:  "Find the machines the instance of which was deleted at the provider",
+		Action: runCommand(cmdPrune),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "remove",
+				Usage: "Remove the machines from the store rather than flagging them",
+			},
+			cli.BoolFlag{
+				Name:  "y",
+				Usage: "Assumes automatic yes to proceed with removal, without prompting further user confirmation",
+			},
+		},
+	},
 	{
 		Name:        "regenerate-certs",
 		Usage:       "Regenerate TLS Certificates for a machine",
@@ -316,6 +562,41 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:        "regenerate-ssh-key",
+		Usage:       "Replace the SSH key of a machine with a new one",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdRegenerateSSHKey),
+	},
+	{
+		Name:        "register",
+		Usage:       "Add a machine written to an archive by deregister to the store",
+		Description: "Argument is the path of the archive.",
+		Action:      runCommand(cmdRegister),
+	},
+	{
+		Name:        "remove-expired",
+		Usage:       "Remove the machines older than their TTL",
+		Description: "Machines are given a TTL with the --ttl option of create. Protected machines are not removed.",
+		Action:      runCommand(cmdRemoveExpired),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep checking for expired machines until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Seconds between two checks when watching",
+				Value: defaultExpiryCheckInterval,
+			},
+		},
+	},
+	{
+		Name:        "restore-config",
+		Usage:       "Restore the configuration of a machine from its last backup",
+		Description: "Argument is a machine name.",
+		Action:      runCommand(cmdRestoreConfig),
+	},
 	{
 		Name:        "restart",
 		Usage:       "Restart a machine",
@@ -332,12 +613,71 @@ var Commands = []cli.Command{
 				Name:  "y",
 				Usage: "Assumes automatic yes to proceed with remove, without prompting further user confirmation",
 			},
+			cli.BoolFlag{
+				Name:  "override-protection",
+				Usage: "Remove the machines even if they are protected",
+			},
 		},
 		Name:        "rm",
 		Usage:       "Remove a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdRm),
 	},
+	{
+		Name:        "run-schedules",
+		Usage:       "Start and stop the machines on their schedule",
+		Description: "Machines are given a schedule with the schedule command or the --schedule-start and --schedule-stop options of create.",
+		Action:      runCommand(cmdRunSchedules),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep applying the schedules until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Seconds between two checks when watching, or how far back to look for the start and stop times otherwise",
+				Value: defaultScheduleCheckInterval,
+			},
+		},
+	},
+	{
+		Name:        "schedule",
+		Usage:       "Set when machines are started and stopped by the run-schedules command",
+		Description: "Argument(s) are one or more machine names.",
+		Action:      runCommand(cmdSchedule),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "start",
+				Usage: "Cron expression of the times to start the machine at, e.g. \"0 8 * * 1-5\"",
+			},
+			cli.StringFlag{
+				Name:  "stop",
+				Usage: "Cron expression of the times to stop the machine at, e.g. \"0 20 * * *\"",
+			},
+			cli.BoolFlag{
+				Name:  "clear",
+				Usage: "Remove the schedule of the machine",
+			},
+		},
+	},
+	{
+		Name:        "serve",
+		Usage:       "Serve the machine operations over an HTTP API",
+		Description: "Clients must authenticate with the certificate serve-client.pem of the certificate directory, signed by a CA of the API clients rather than by the machine CA.",
+		Action:      runCommand(cmdServe),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "addr",
+				Usage: "Address to listen on",
+				Value: serveDefaultAddr,
+			},
+			cli.StringSliceFlag{
+				Name:  "tls-san",
+				Usage: "Extra SANs for the API server certificate",
+				Value: &cli.StringSlice{},
+			},
+		},
+	},
 	{
 		Name:            "ssh",
 		Usage:           "Log into or run a command on a machine with SSH.",
@@ -345,6 +685,12 @@ var Commands = []cli.Command{
 		Action:          runCommand(cmdSSH),
 		SkipFlagParsing: true,
 	},
+	{
+		Name:        "ssh-config",
+		Usage:       "Print the ssh_config entries of machines",
+		Description: "Argument(s) are one or more machine names. Append the entries to ~/.ssh/config to reach the machines with ssh, or set --manage-ssh-config.",
+		Action:      runCommand(cmdSSHConfig),
+	},
 	{
 		Name:        "scp",
 		Usage:       "Copy files between machines",
@@ -361,6 +707,31 @@ var Commands = []cli.Command{
 			},
 		},
 	},
+	{
+		Name:        "sync",
+		Usage:       "Copy the changes of a local directory to a machine",
+		Description: "Arguments are [machine] [local directory] [remote directory].",
+		Action:      runCommand(cmdSync),
+		Flags: []cli.Flag{
+			cli.StringSliceFlag{
+				Name:  "exclude",
+				Usage: "Pattern of the files not to copy, matched against their name and relative path",
+				Value: &cli.StringSlice{},
+			},
+			cli.BoolFlag{
+				Name:  "delete",
+				Usage: "Delete the remote files which do not exist locally",
+			},
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep copying the changes until interrupted",
+			},
+			cli.BoolFlag{
+				Name:  "builtin",
+				Usage: "Compare and copy the files without rsync",
+			},
+		},
+	},
 	{
 		Name:        "mount",
 		Usage:       "Mount or unmount a directory from a machine with SSHFS.",
@@ -390,12 +761,100 @@ var Commands = []cli.Command{
 		Usage:       "Stop a machine",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdStop),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "graceful, g",
+				Usage: "Stop the Docker engine over SSH before stopping the machine",
+			},
+			cli.IntFlag{
+				Name:  "timeout, t",
+				Usage: "Kill the machine if it is not stopped after this many seconds, 0 to never kill it",
+			},
+		},
+	},
+	{
+		Name:        "stop-idle",
+		Usage:       "Stop the machines idle for longer than their idle timeout",
+		Description: "Machines are given an idle timeout with the --idle-timeout option of create.",
+		Action:      runCommand(cmdStopIdle),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep checking for idle machines until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Seconds between two checks when watching",
+				Value: defaultIdleCheckInterval,
+			},
+		},
+	},
+	{
+		Name:        "sync-clocks",
+		Usage:       "Set the clocks of the local VMs lagging behind after the host slept",
+		Description: "The clocks of the running VirtualBox and VMware machines more than a few seconds off are set to the time of the host.",
+		Action:      runCommand(cmdSyncClocks),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep checking the clocks until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Seconds between two checks when watching",
+				Value: defaultClockCheckInterval,
+			},
+		},
+	},
+	{
+		Name:  "trash",
+		Usage: "Manage the removed machines kept in the trash",
+		Subcommands: []cli.Command{
+			{
+				Name:   "ls",
+				Usage:  "List the removed machines",
+				Action: runCommand(cmdTrashLs),
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "format, f",
+						Usage: "Pretty-print the removed machines using a Go template",
+					},
+				},
+			},
+			{
+				Name:        "restore",
+				Usage:       "Put removed machines back",
+				Description: "Argument(s) are one or more machine names. The most recently removed machine of each name is restored.",
+				Action:      runCommand(cmdTrashRestore),
+			},
+			{
+				Name:   "purge",
+				Usage:  "Delete the removed machines for good",
+				Action: runCommand(cmdTrashPurge),
+				Flags: []cli.Flag{
+					cli.BoolFlag{
+						Name:  "y",
+						Usage: "Assumes automatic yes to proceed with the deletion, without prompting further user confirmation",
+					},
+				},
+			},
+		},
 	},
 	{
 		Name:        "upgrade",
 		Usage:       "Upgrade a machine to the latest version of Docker",
 		Description: "Argument(s) are one or more machine names.",
 		Action:      runCommand(cmdUpgrade),
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "version",
+				Usage: "Upgrade a single machine to the given version of Docker rather than the latest one",
+			},
+			cli.BoolFlag{
+				Name:  "allow-downgrade",
+				Usage: "Allow --version to be older than the version running on the machine",
+			},
+		},
 	},
 	{
 		Name:        "url",
@@ -403,6 +862,23 @@ var Commands = []cli.Command{
 		Description: "Argument is a machine name.",
 		Action:      runCommand(cmdURL),
 	},
+	{
+		Name:        "watchdog",
+		Usage:       "Restart the engines or the machines which stopped answering",
+		Description: "Argument(s) are the machine names to check, all the machines by default. Machines are given a watchdog policy with the --watchdog option of create.",
+		Action:      runCommand(cmdWatchdog),
+		Flags: []cli.Flag{
+			cli.BoolFlag{
+				Name:  "watch, w",
+				Usage: "Keep checking the machines until interrupted",
+			},
+			cli.IntFlag{
+				Name:  "interval",
+				Usage: "Seconds between two checks when watching",
+				Value: defaultHealthCheckInterval,
+			},
+		},
+	},
 	{
 		Name:   "version",
 		Usage:  "Show the Docker Machine version or a machine docker version",
@@ -423,28 +899,21 @@ func printIP(h *host.Host) func() error {
 	}
 }
 
-// machineCommand maps the command name to the corresponding machine command.
-// We run commands concurrently and communicate back an error if there was one.
-func machineCommand(actionName string, host *host.Host, errorChan chan<- error) {
-	// TODO: These actions should have their own type.
-	commands := map[string](func() error){
-		"configureAuth": host.ConfigureAuth,
-		"start":         host.Start,
-		"stop":          host.Stop,
-		"restart":       host.Restart,
-		"kill":          host.Kill,
-		"upgrade":       host.Upgrade,
-		"ip":            printIP(host),
-		"provision":     host.Provision,
-	}
-
+// machineCommand runs the action on the machine. We run commands
+// concurrently and communicate back an error if there was one.
+func machineCommand(actionName string, api libmachine.API, host *host.Host, errorChan chan<- error) {
 	log.Debugf("command=%s machine=%s", actionName, host.Name)
 
-	errorChan <- commands[actionName]()
+	if actionName == "ip" {
+		errorChan <- printIP(host)()
+		return
+	}
+
+	errorChan <- libmachine.RunAction(api, host, actionName)
 }
 
-// runActionForeachMachine will run the command across multiple machines
-func runActionForeachMachine(actionName string, machines []*host.Host) []error {
+// runActionForeachMachine will run the command across multiple machines.
+func runActionForeachMachine(actionName string, api libmachine.API, machines []*host.Host) []error {
 	var (
 		numConcurrentActions = 0
 		errorChan            = make(chan error)
@@ -453,7 +922,7 @@ func runActionForeachMachine(actionName string, machines []*host.Host) []error {
 
 	for _, machine := range machines {
 		numConcurrentActions++
-		go machineCommand(actionName, machine, errorChan)
+		go machineCommand(actionName, api, machine, errorChan)
 	}
 
 	// TODO: We should probably only do 5-10 of these
@@ -12,6 +12,7 @@ import (
 	"github.com/docker/machine/libmachine/crashreport"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/hosttest"
+	"github.com/docker/machine/libmachine/libmachinetest"
 	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/provision"
 	"github.com/docker/machine/libmachine/state"
@@ -75,7 +76,7 @@ func TestRunActionForeachMachine(t *testing.T) {
 		},
 	}
 
-	runActionForeachMachine("start", machines)
+	runActionForeachMachine("start", &libmachinetest.FakeAPI{Hosts: machines}, machines)
 
 	for _, machine := range machines {
 		machineState, _ := machine.Driver.GetState()
@@ -83,7 +84,7 @@ func TestRunActionForeachMachine(t *testing.T) {
 		assert.Equal(t, state.Running, machineState)
 	}
 
-	runActionForeachMachine("stop", machines)
+	runActionForeachMachine("stop", &libmachinetest.FakeAPI{Hosts: machines}, machines)
 
 	for _, machine := range machines {
 		machineState, _ := machine.Driver.GetState()
@@ -56,6 +56,9 @@ func (fcli *FakeCommandLine) StringSlice(key string) []string {
 }
 
 func (fcli *FakeCommandLine) Int(key string) int {
+	if fcli.LocalFlags == nil {
+		return 0
+	}
 	return fcli.LocalFlags.Int(key)
 }
 
@@ -70,6 +73,13 @@ func (fcli *FakeCommandLine) GlobalString(key string) string {
 	return fcli.GlobalFlags.String(key)
 }
 
+func (fcli *FakeCommandLine) GlobalBool(key string) bool {
+	if fcli.GlobalFlags == nil {
+		return false
+	}
+	return fcli.GlobalFlags.Bool(key)
+}
+
 func (fcli *FakeCommandLine) Generic(name string) interface{} {
 	return fcli.LocalFlags.Data[name]
 }
@@ -0,0 +1,96 @@
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/commands/formatter"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/completion"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// completionDocument is the JSON document printed by the completion command.
+type completionDocument struct {
+	SchemaVersion int                  `json:"SchemaVersion"`
+	Commands      []completionCommand  `json:"Commands"`
+	Machines      []string             `json:"Machines"`
+	Drivers       []*completion.Driver `json:"Drivers"`
+}
+
+type completionCommand struct {
+	Name        string              `json:"Name"`
+	Aliases     []string            `json:"Aliases,omitempty"`
+	Usage       string              `json:"Usage"`
+	Flags       []string            `json:"Flags"`
+	Subcommands []completionCommand `json:"Subcommands,omitempty"`
+}
+
+func cmdCompletion(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	machines, err := completion.MachineNames(api)
+	if err != nil {
+		return err
+	}
+
+	driverNames := c.StringSlice("driver")
+	if len(driverNames) == 0 {
+		driverNames = completion.DriverNames()
+	}
+
+	driverSchemas := []*completion.Driver{}
+	for _, driverName := range driverNames {
+		schema, err := completion.DriverSchema(api, driverName)
+		if err != nil {
+			log.Warnf("Error reading the flags of driver %q: %s", driverName, err)
+			continue
+		}
+		driverSchemas = append(driverSchemas, schema)
+	}
+
+	return formatter.WriteJSON(os.Stdout, &completionDocument{
+		SchemaVersion: formatter.SchemaVersion,
+		Commands:      completionCommands(c.Application().Commands),
+		Machines:      machines,
+		Drivers:       driverSchemas,
+	})
+}
+
+func completionCommands(commands []cli.Command) []completionCommand {
+	schemas := []completionCommand{}
+	for _, cmd := range commands {
+		schemas = append(schemas, completionCommand{
+			Name:        cmd.Name,
+			Aliases:     cmd.Aliases,
+			Usage:       cmd.Usage,
+			Flags:       completionFlagNames(cmd.Flags),
+			Subcommands: completionCommands(cmd.Subcommands),
+		})
+	}
+	return schemas
+}
+
+// completionFlagNames returns the names of the flags, including their short
+// names, prefixed with dashes.
+func completionFlagNames(flags []cli.Flag) []string {
+	names := []string{}
+	for _, f := range flags {
+		flagNames, _ := cliFlagInfo(f)
+		for _, name := range strings.Split(flagNames, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if len(name) == 1 {
+				names = append(names, "-"+name)
+			} else {
+				names = append(names, "--"+name)
+			}
+		}
+	}
+	return names
+}
@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 
@@ -18,6 +18,7 @@ import (
 	"github.com/docker/machine/commands/mcndirs"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
 	"github.com/docker/machine/libmachine/crashreport"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/drivers/rpc"
@@ -26,6 +27,9 @@ import (
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/namegen"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/swarm"
 )
 
@@ -43,10 +47,23 @@ var (
 		},
 		cli.StringFlag{
 			Name:   "engine-install-url",
-			Usage:  "Custom URL to use for engine installation",
+			Usage:  "Custom URL to use for engine installation, or file:// path of a local install script. {arch} is replaced with the architecture of the machine.",
 			Value:  drivers.DefaultEngineInstallURL,
 			EnvVar: "MACHINE_DOCKER_INSTALL_URL",
 		},
+		cli.StringFlag{
+			Name:  "engine-install-bundle",
+			Usage: "Local tarball of the engine packages, or install.sh script, to install without network access. {arch} is replaced with the architecture of the machine.",
+		},
+		cli.BoolFlag{
+			Name:  "engine-install-cache",
+			Usage: "Keep the engine install script or bundle in the asset cache of the store and install from there. A #sha256=<checksum> URL fragment pins its checksum",
+		},
+		cli.StringFlag{
+			Name:   "engine-install-version",
+			Usage:  "Version of the engine to install rather than the latest one",
+			EnvVar: "MACHINE_DOCKER_INSTALL_VERSION",
+		},
 		cli.StringSliceFlag{
 			Name:  "engine-opt",
 			Usage: "Specify arbitrary flags to include with the created engine in the form flag=value",
@@ -72,11 +89,77 @@ var (
 			Name:  "engine-storage-driver",
 			Usage: "Specify a storage driver to use with the engine",
 		},
+		cli.StringFlag{
+			Name:  "engine-storage-device",
+			Usage: "Block device of the machine the devicemapper storage driver allocates its thin pool on",
+		},
+		cli.IntFlag{
+			Name:  "engine-port",
+			Usage: fmt.Sprintf("TCP port the engine listens on with TLS (default %d)", engine.DefaultPort),
+		},
+		cli.BoolFlag{
+			Name:  "engine-no-unix-socket",
+			Usage: "Have the engine listen on its TCP port only, not on /var/run/docker.sock too",
+		},
 		cli.StringSliceFlag{
 			Name:  "engine-env",
 			Usage: "Specify environment variables to set in the engine",
 			Value: &cli.StringSlice{},
 		},
+		cli.StringFlag{
+			Name:   "engine-http-proxy",
+			Usage:  "Specify the HTTP proxy for the engine to use",
+			EnvVar: "ENGINE_HTTP_PROXY",
+		},
+		cli.StringFlag{
+			Name:   "engine-https-proxy",
+			Usage:  "Specify the HTTPS proxy for the engine to use",
+			EnvVar: "ENGINE_HTTPS_PROXY",
+		},
+		cli.StringFlag{
+			Name:   "engine-no-proxy",
+			Usage:  "Comma separated list of hosts the engine reaches without proxy (the machine IP is always added)",
+			EnvVar: "ENGINE_NO_PROXY",
+		},
+		cli.StringFlag{
+			Name:  "engine-cluster-store",
+			Usage: "Key-value store keeping the multi-host networks of the engine, e.g. consul://10.0.0.2:8500",
+		},
+		cli.StringFlag{
+			Name:  "engine-cluster-advertise",
+			Usage: "Address the engine advertises to the cluster store (default: the machine IP and engine port)",
+		},
+		cli.StringSliceFlag{
+			Name:  "engine-cluster-store-opt",
+			Usage: "Specify options of the cluster store in the form option=value",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringSliceFlag{
+			Name:  "engine-authorization-plugin",
+			Usage: "Specify authorization plugins for the engine",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringFlag{
+			Name:  "engine-log-driver",
+			Usage: "Specify the default log driver of the containers of the engine",
+		},
+		cli.StringSliceFlag{
+			Name:  "engine-log-opt",
+			Usage: "Specify options of the log driver in the form option=value",
+			Value: &cli.StringSlice{},
+		},
+		cli.BoolFlag{
+			Name:  "engine-live-restore",
+			Usage: "Keep the containers running while the engine restarts",
+		},
+		cli.StringFlag{
+			Name:  "engine-cgroup-driver",
+			Usage: "Specify the cgroup driver of the engine: cgroupfs or systemd",
+		},
+		cli.BoolFlag{
+			Name:  "engine-gpu-runtime",
+			Usage: "Install the NVIDIA container runtime and run containers with it by default",
+		},
 		cli.BoolFlag{
 			Name:  "swarm",
 			Usage: "Configure Machine to join a Swarm cluster",
@@ -125,20 +208,250 @@ var (
 			Name:  "swarm-experimental",
 			Usage: "Enable Swarm experimental features",
 		},
+		cli.BoolFlag{
+			Name:  "swarm-discovery-tls",
+			Usage: "Connect to the key-value store of the Swarm discovery with TLS, using the certificates of the machine",
+		},
+		cli.StringFlag{
+			Name:  "swarm-discovery-machine",
+			Usage: "Machine running the key-value store to use as Swarm discovery, see --swarm-discovery-store",
+		},
+		cli.StringFlag{
+			Name:  "swarm-discovery-store",
+			Usage: fmt.Sprintf("Run a key-value store on the machine for the Swarm discovery of other machines (%s)", strings.Join(swarm.KVStores(), "|")),
+		},
+		cli.BoolFlag{
+			Name:  "swarm-cluster-networking",
+			Usage: "Use the Swarm discovery as cluster store of the engine, for overlay networks spanning the machines",
+		},
 		cli.StringSliceFlag{
 			Name:  "tls-san",
 			Usage: "Support extra SANs for TLS certs",
 			Value: &cli.StringSlice{},
 		},
+		cli.StringFlag{
+			Name:  "tls-key-type",
+			Usage: "Type of the keys of the generated certificates: rsa or ecdsa",
+			Value: "rsa",
+		},
+		cli.IntFlag{
+			Name:  "tls-key-bits",
+			Usage: "Size of the RSA keys of the generated certificates",
+			Value: 2048,
+		},
+		cli.StringFlag{
+			Name:  "tls-key-curve",
+			Usage: "Curve of the ECDSA keys of the generated certificates: P256, P384 or P521",
+			Value: "P256",
+		},
+		cli.IntFlag{
+			Name:  "tls-cert-validity",
+			Usage: "Number of days the generated certificates are valid",
+			Value: 1080,
+		},
+		cli.StringFlag{
+			Name:  "tls-organization",
+			Usage: "Organization of the generated certificates. Defaults to the name of the user.",
+		},
+		cli.StringFlag{
+			Name:  "tls-server-cert",
+			Usage: "Externally issued server certificate of the engine, instead of one generated with the CA key",
+		},
+		cli.StringFlag{
+			Name:  "tls-server-key",
+			Usage: "Private key of the externally issued server certificate",
+		},
+		cli.StringFlag{
+			Name:  "address-preference",
+			Usage: "Address to use for machines with both IPv4 and IPv6 addresses (ipv4, ipv6, ipv4-only or ipv6-only)",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "network-static-ip",
+			Usage: "Static IP of the machine on its private network, out of the range of its DHCP server (virtualbox only)",
+			Value: "",
+		},
+		cli.StringFlag{
+			Name:  "network-subnet",
+			Usage: "Subnet of the private network of the machine, e.g. 192.168.99.0/24 (virtualbox only)",
+			Value: "",
+		},
+		cli.StringSliceFlag{
+			Name:  "network-dns",
+			Usage: "DNS server to use in the machine (virtualbox only)",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringFlag{
+			Name:  "network-hostonly-name",
+			Usage: "Name of an existing host-only network to attach the machine to (virtualbox only)",
+			Value: "",
+		},
+		cli.StringSliceFlag{
+			Name:  "network-port-forward",
+			Usage: "Port of the machine to forward from the host, in the hostPort[:guestPort][/protocol] format (virtualbox only)",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringFlag{
+			Name:  "instance-profile",
+			Usage: "Identity the cloud instance runs with, e.g. an AWS IAM instance profile",
+			Value: "",
+		},
+		cli.StringSliceFlag{
+			Name:  "instance-tag",
+			Usage: "Tag to attach to the cloud instance, in the key=value format",
+			Value: &cli.StringSlice{},
+		},
+		cli.BoolFlag{
+			Name:  "no-resource-tags",
+			Usage: "Do not tag the cloud resources created for the machine with its name and a created-by marker, which are checked before deleting them",
+		},
+		cli.StringFlag{
+			Name:  "region",
+			Usage: "Region of the provider to create the machine in, for the drivers supporting it",
+		},
+		cli.StringFlag{
+			Name:  "zone",
+			Usage: "Availability zone of the provider to create the machine in, for the drivers supporting it",
+		},
+		cli.IntFlag{
+			Name:  "memory",
+			Usage: "Memory of the machine in MB, for the drivers supporting it, instead of the default of the driver",
+		},
+		cli.IntFlag{
+			Name:  "disk-size",
+			Usage: "Size of the disk of the machine in MB, for the drivers supporting it, instead of the default of the driver",
+		},
+		cli.StringSliceFlag{
+			Name:  "open-port",
+			Usage: "Port to open in the firewall of the provider on top of SSH and the engine port, in the port[/protocol] format",
+			Value: &cli.StringSlice{},
+		},
+		cli.IntFlag{
+			Name:  "swap-size",
+			Usage: "Size in MB of a swap file to add to the machine",
+		},
+		cli.IntFlag{
+			Name:  "swappiness",
+			Usage: "Swappiness of the kernel of the machine, -1 to keep the default of the system",
+			Value: -1,
+		},
+		cli.StringSliceFlag{
+			Name:  "preload-image",
+			Usage: "Image to pull into the engine once it is up, or local archive of images saved with docker save to load",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringSliceFlag{
+			Name:  "sysctl",
+			Usage: "Kernel parameter to set on the machine, in the key=value format, e.g. vm.max_map_count=262144",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringSliceFlag{
+			Name:  "extra-user",
+			Usage: "Additional SSH user to create on the machine and add to the docker group, in the name:public-key-file format",
+			Value: &cli.StringSlice{},
+		},
+		cli.StringFlag{
+			Name:  "provisioner",
+			Usage: "Provision the machine as the given distribution rather than the detected one, e.g. Ubuntu-SystemD",
+		},
+		cli.IntFlag{
+			Name:  "gpu-count",
+			Usage: "Number of GPUs the machine needs",
+		},
+		cli.StringFlag{
+			Name:  "gpu-type",
+			Usage: "Model of GPU the machine needs, e.g. k80",
+		},
+		cli.StringSliceFlag{
+			Name:  "gpu-pci-device",
+			Usage: "PCI address of a GPU of the host to pass through to the VM, e.g. 01:00.0",
+			Value: &cli.StringSlice{},
+		},
+		cli.BoolFlag{
+			Name:  "recreate-on-preemption",
+			Usage: "Bring the machine back when it is found preempted by the provider",
+		},
+		cli.BoolFlag{
+			Name:  "autostart",
+			Usage: "Start the machine when the host OS boots",
+		},
+		cli.BoolFlag{
+			Name:  "protected",
+			Usage: "Protect the machine against removal, see the protect command",
+		},
+		cli.BoolFlag{
+			Name:  "generate-name",
+			Usage: "Name the machine with a generated name no machine has, e.g. swift-otter, when no name is given",
+		},
+		cli.StringFlag{
+			Name:  "name-prefix",
+			Usage: "With --generate-name, name the machine <prefix>-<N> with the lowest N free",
+		},
+		cli.StringFlag{
+			Name:  "ttl",
+			Usage: "Remove the machine when it is older than the duration, e.g. 2h, see the remove-expired command",
+		},
+		cli.StringFlag{
+			Name:  "ssh-bastion",
+			Usage: "Reach the machine over SSH through this bastion host, as [user@]host[:port], e.g. for machines without a public address",
+		},
+		cli.StringFlag{
+			Name:  "ssh-bastion-key",
+			Usage: "Private key of the --ssh-bastion, the key of the machine by default",
+		},
+		cli.StringFlag{
+			Name:  "watchdog",
+			Usage: "Action of the watchdog command when the engine stops answering: restart-engine, restart-machine or notify",
+		},
+		cli.StringFlag{
+			Name:  "watchdog-grace-period",
+			Usage: "With --watchdog, how long the engine may not answer before the watchdog acts, e.g. 1m",
+		},
+		cli.IntFlag{
+			Name:  "idle-timeout",
+			Usage: "Minutes without Docker activity after which the stop-idle command stops the machine, 0 to never stop it",
+		},
+		cli.StringFlag{
+			Name:  "schedule-start",
+			Usage: "Cron expression of the times the run-schedules command starts the machine at, e.g. \"0 8 * * 1-5\"",
+		},
+		cli.StringFlag{
+			Name:  "schedule-stop",
+			Usage: "Cron expression of the times the run-schedules command stops the machine at, e.g. \"0 20 * * *\"",
+		},
+		cli.StringFlag{
+			Name:  "profile",
+			Usage: "Profile to take the flags not set on the command line from",
+		},
+		cli.StringFlag{
+			Name:  "save-profile",
+			Usage: "Save the flags of the machine as a profile of this name",
+		},
 	}
 )
 
 func cmdCreateInner(c CommandLine, api libmachine.API) error {
+	c, err := withProfile(c, api)
+	if err != nil {
+		return err
+	}
+
+	c, err = withConfigDefaults(c)
+	if err != nil {
+		return err
+	}
+
 	if len(c.Args()) > 1 {
 		return fmt.Errorf("Invalid command line. Found extra arguments %v", c.Args()[1:])
 	}
 
 	name := c.Args().First()
+	if name == "" && c.Bool("generate-name") {
+		if name, err = namegen.Unique(api, nameGenerator(c)); err != nil {
+			return err
+		}
+		log.Infof("Naming the machine %s", name)
+	}
 	if name == "" {
 		c.ShowHelp()
 		return errNoMachineName
@@ -149,10 +462,138 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Error creating machine: %s", mcnerror.ErrInvalidHostname)
 	}
 
-	if err := validateSwarmDiscovery(c.String("swarm-discovery")); err != nil {
+	swarmDiscovery, err := getSwarmDiscovery(c, api)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSwarmDiscovery(swarmDiscovery); err != nil {
 		return fmt.Errorf("Error parsing swarm discovery: %s", err)
 	}
 
+	if err := validateDiscoveryOptions(c, swarmDiscovery); err != nil {
+		return err
+	}
+
+	serverCertSource, serverKeySource, err := absPaths(c.String("tls-server-cert"), c.String("tls-server-key"))
+	if err != nil {
+		return err
+	}
+	if (serverCertSource == "") != (serverKeySource == "") {
+		return errors.New("--tls-server-cert and --tls-server-key must be given together")
+	}
+
+	if err := cert.ValidateKeyOptions(c.String("tls-key-type"), c.Int("tls-key-bits"), c.String("tls-key-curve")); err != nil {
+		return err
+	}
+	if c.Int("tls-cert-validity") < 1 {
+		return errors.New("--tls-cert-validity must be at least one day")
+	}
+
+	if cgroupDriver := c.String("engine-cgroup-driver"); cgroupDriver != "" && cgroupDriver != "cgroupfs" && cgroupDriver != "systemd" {
+		return fmt.Errorf("Unsupported cgroup driver %q, must be cgroupfs or systemd", cgroupDriver)
+	}
+
+	if provisionerName := c.String("provisioner"); provisionerName != "" {
+		if err := provision.ValidateName(provisionerName); err != nil {
+			return err
+		}
+	}
+
+	if c.Int("gpu-count") < 0 {
+		return errors.New("--gpu-count cannot be negative")
+	}
+
+	addressPreference, err := drivers.ParseAddressPreference(c.String("address-preference"))
+	if err != nil {
+		return err
+	}
+
+	instanceTags, err := parseInstanceTags(c.StringSlice("instance-tag"))
+	if err != nil {
+		return err
+	}
+
+	portForwards := []drivers.PortForward{}
+	for _, rule := range c.StringSlice("network-port-forward") {
+		portForward, err := drivers.ParsePortForward(rule)
+		if err != nil {
+			return err
+		}
+		portForwards = append(portForwards, portForward)
+	}
+
+	machineSchedule, err := scheduleFromFlags(c, "schedule-start", "schedule-stop")
+	if err != nil {
+		return err
+	}
+
+	users, err := parseExtraUsers(c.StringSlice("extra-user"))
+	if err != nil {
+		return err
+	}
+
+	sysctls, err := parseSysctls(c.StringSlice("sysctl"))
+	if err != nil {
+		return err
+	}
+
+	preloadImages := []string{}
+	for _, image := range c.StringSlice("preload-image") {
+		// The archives are read again when an interrupted creation resumes,
+		// from any directory
+		if _, err := os.Stat(image); err == nil {
+			if image, err = filepath.Abs(image); err != nil {
+				return err
+			}
+		}
+		preloadImages = append(preloadImages, image)
+	}
+	if err := provision.ValidatePreloadImages(preloadImages); err != nil {
+		return err
+	}
+
+	var swap *provision.Swap
+	if c.Int("swap-size") != 0 || c.Int("swappiness") != -1 {
+		swap = &provision.Swap{
+			Size:       c.Int("swap-size"),
+			Swappiness: c.Int("swappiness"),
+		}
+		if err := swap.Validate(); err != nil {
+			return err
+		}
+	}
+
+	openPorts := []drivers.FirewallRule{}
+	for _, port := range c.StringSlice("open-port") {
+		rule, err := drivers.ParseFirewallRule(port)
+		if err != nil {
+			return err
+		}
+		openPorts = append(openPorts, rule)
+	}
+
+	if port := c.Int("engine-port"); port < 0 || port > 65535 {
+		return fmt.Errorf("Invalid --engine-port %d", port)
+	}
+
+	var ttl time.Duration
+	if value := c.String("ttl"); value != "" {
+		if ttl, err = time.ParseDuration(value); err != nil || ttl <= 0 {
+			return fmt.Errorf("Invalid --ttl %q, expected a positive duration, e.g. 2h", value)
+		}
+	}
+
+	watchdog, err := watchdogPolicy(c)
+	if err != nil {
+		return err
+	}
+
+	bastion, err := sshBastion(c)
+	if err != nil {
+		return err
+	}
+
 	// TODO: Fix hacky JSON solution
 	rawDriver, err := json.Marshal(&drivers.BaseDriver{
 		MachineName: name,
@@ -169,6 +610,44 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 	}
 
 	h.HostOptions = &host.Options{
+		Memory:            c.Int("memory"),
+		Disk:              c.Int("disk-size"),
+		AddressPreference: addressPreference,
+		NetworkOptions: &drivers.NetworkOptions{
+			StaticIP:        c.String("network-static-ip"),
+			Subnet:          c.String("network-subnet"),
+			DNSServers:      c.StringSlice("network-dns"),
+			HostOnlyNetwork: c.String("network-hostonly-name"),
+			PortForwards:    portForwards,
+		},
+		InstanceOptions: &drivers.InstanceOptions{
+			InstanceProfile: c.String("instance-profile"),
+			Tags:            instanceTags,
+		},
+		GPUOptions: &drivers.GPUOptions{
+			Count:      c.Int("gpu-count"),
+			Type:       c.String("gpu-type"),
+			PCIDevices: c.StringSlice("gpu-pci-device"),
+		},
+		Provisioner:          c.String("provisioner"),
+		RecreateOnPreemption: c.Bool("recreate-on-preemption"),
+		Autostart:            c.Bool("autostart"),
+		Protected:            c.Bool("protected"),
+		IdleTimeout:          time.Duration(c.Int("idle-timeout")) * time.Minute,
+		TTL:                  ttl,
+		Watchdog:             watchdog,
+		SSHBastion:           bastion,
+		TagResources:         !c.Bool("no-resource-tags"),
+		OpenPorts:            openPorts,
+		Schedule:             machineSchedule,
+		Users:                users,
+		Sysctls:              sysctls,
+		Swap:                 swap,
+		PreloadImages:        preloadImages,
+		Placement: &drivers.Placement{
+			Region: c.String("region"),
+			Zone:   c.String("zone"),
+		},
 		AuthOptions: &auth.Options{
 			CertDir:          mcndirs.GetMachineCertDir(),
 			CaCertPath:       tlsPath(c, "tls-ca-cert", "ca.pem"),
@@ -179,29 +658,57 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 			ServerKeyPath:    filepath.Join(mcndirs.GetMachineDir(), name, "server-key.pem"),
 			StorePath:        filepath.Join(mcndirs.GetMachineDir(), name),
 			ServerCertSANs:   c.StringSlice("tls-san"),
+			ServerCertSource: serverCertSource,
+			ServerKeySource:  serverKeySource,
+			KeyType:          c.String("tls-key-type"),
+			KeyBits:          c.Int("tls-key-bits"),
+			KeyCurve:         c.String("tls-key-curve"),
+			CertValidity:     time.Duration(c.Int("tls-cert-validity")) * 24 * time.Hour,
+			CertOrganization: c.String("tls-organization"),
 		},
 		EngineOptions: &engine.Options{
-			ArbitraryFlags:   c.StringSlice("engine-opt"),
-			Env:              c.StringSlice("engine-env"),
-			InsecureRegistry: c.StringSlice("engine-insecure-registry"),
-			Labels:           c.StringSlice("engine-label"),
-			RegistryMirror:   c.StringSlice("engine-registry-mirror"),
-			StorageDriver:    c.String("engine-storage-driver"),
-			TLSVerify:        true,
-			InstallURL:       c.String("engine-install-url"),
+			ArbitraryFlags:       c.StringSlice("engine-opt"),
+			Env:                  c.StringSlice("engine-env"),
+			InsecureRegistry:     c.StringSlice("engine-insecure-registry"),
+			Labels:               c.StringSlice("engine-label"),
+			RegistryMirror:       c.StringSlice("engine-registry-mirror"),
+			StorageDriver:        c.String("engine-storage-driver"),
+			StorageDevice:        c.String("engine-storage-device"),
+			TLSVerify:            true,
+			InstallURL:           c.String("engine-install-url"),
+			InstallVersion:       c.String("engine-install-version"),
+			InstallBundle:        c.String("engine-install-bundle"),
+			AssetCacheDir:        assetCacheDir(c.Bool("engine-install-cache")),
+			HTTPProxy:            c.String("engine-http-proxy"),
+			HTTPSProxy:           c.String("engine-https-proxy"),
+			NoProxy:              c.String("engine-no-proxy"),
+			ClusterStore:         c.String("engine-cluster-store"),
+			ClusterAdvertise:     c.String("engine-cluster-advertise"),
+			ClusterStoreOpts:     c.StringSlice("engine-cluster-store-opt"),
+			AuthorizationPlugins: c.StringSlice("engine-authorization-plugin"),
+			LogDriver:            c.String("engine-log-driver"),
+			LogOpts:              c.StringSlice("engine-log-opt"),
+			LiveRestore:          c.Bool("engine-live-restore"),
+			CgroupDriver:         c.String("engine-cgroup-driver"),
+			GPURuntime:           c.Bool("engine-gpu-runtime"),
+			Port:                 c.Int("engine-port"),
+			DisableUnixSocket:    c.Bool("engine-no-unix-socket"),
 		},
 		SwarmOptions: &swarm.Options{
 			IsSwarm:            c.Bool("swarm") || c.Bool("swarm-master"),
 			Image:              c.String("swarm-image"),
 			Agent:              c.Bool("swarm"),
 			Master:             c.Bool("swarm-master"),
-			Discovery:          c.String("swarm-discovery"),
+			Discovery:          swarmDiscovery,
 			Address:            c.String("swarm-addr"),
 			Host:               c.String("swarm-host"),
 			Strategy:           c.String("swarm-strategy"),
 			ArbitraryFlags:     c.StringSlice("swarm-opt"),
 			ArbitraryJoinFlags: c.StringSlice("swarm-join-opt"),
 			IsExperimental:     c.Bool("swarm-experimental"),
+			ClusterNetworking:  c.Bool("swarm-cluster-networking"),
+			DiscoveryTLS:       c.Bool("swarm-discovery-tls"),
+			DiscoveryStore:     c.String("swarm-discovery-store"),
 		},
 	}
 
@@ -210,9 +717,18 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Error checking if host exists: %s", err)
 	}
 	if exists {
-		return mcnerror.ErrHostAlreadyExists{
-			Name: h.Name,
+		existing, err := api.Load(h.Name)
+		if err != nil {
+			return err
+		}
+		if !existing.CreateIncomplete() {
+			return mcnerror.ErrHostAlreadyExists{
+				Name: h.Name,
+			}
 		}
+
+		log.Infof("The creation of %q was interrupted, resuming it with the options it was started with...", h.Name)
+		return createHost(api, existing)
 	}
 
 	// driverOpts is the actual data we send over the wire to set the
@@ -225,6 +741,77 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
 	}
 
+	if profileName := c.String("save-profile"); profileName != "" {
+		if err := saveProfile(c, api, profileName); err != nil {
+			return err
+		}
+	}
+
+	return createHost(api, h)
+}
+
+// sshBastion returns the bastion of the --ssh-bastion flags, nil without
+// --ssh-bastion.
+func sshBastion(c CommandLine) (*ssh.Bastion, error) {
+	if c.String("ssh-bastion") == "" {
+		if c.String("ssh-bastion-key") != "" {
+			return nil, errors.New("--ssh-bastion-key needs --ssh-bastion")
+		}
+		return nil, nil
+	}
+
+	bastion, err := ssh.ParseBastion(c.String("ssh-bastion"))
+	if err != nil {
+		return nil, err
+	}
+
+	if keyPath := c.String("ssh-bastion-key"); keyPath != "" {
+		if bastion.KeyPath, err = filepath.Abs(keyPath); err != nil {
+			return nil, err
+		}
+	}
+
+	return bastion, nil
+}
+
+// watchdogPolicy returns the watchdog policy of the --watchdog flags, nil
+// without --watchdog.
+func watchdogPolicy(c CommandLine) (*host.WatchdogPolicy, error) {
+	value := c.String("watchdog-grace-period")
+	if c.String("watchdog") == "" {
+		if value != "" {
+			return nil, errors.New("--watchdog-grace-period needs --watchdog")
+		}
+		return nil, nil
+	}
+
+	action, err := host.ParseWatchdogAction(c.String("watchdog"))
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &host.WatchdogPolicy{Action: action}
+	if value != "" {
+		if policy.GracePeriod, err = time.ParseDuration(value); err != nil || policy.GracePeriod <= 0 {
+			return nil, fmt.Errorf("Invalid --watchdog-grace-period %q, expected a positive duration, e.g. 1m", value)
+		}
+	}
+
+	return policy, nil
+}
+
+// nameGenerator returns the generator of the names of the machines created
+// with --generate-name.
+func nameGenerator(c CommandLine) namegen.Generator {
+	if prefix := c.String("name-prefix"); prefix != "" {
+		return namegen.Prefixed{Prefix: prefix}
+	}
+	return namegen.AdjectiveNoun{}
+}
+
+// createHost creates the machine, or resumes its interrupted creation, and
+// saves it.
+func createHost(api libmachine.API, h *host.Host) error {
 	if err := api.Create(h); err != nil {
 		// Wait for all the logs to reach the client
 		time.Sleep(2 * time.Second)
@@ -247,7 +834,7 @@ func cmdCreateInner(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("Error attempting to save store: %s", err)
 	}
 
-	log.Infof("To see how to connect your Docker Client to the Docker Engine running on this virtual machine, run: %s env %s", os.Args[0], name)
+	log.Infof("To see how to connect your Docker Client to the Docker Engine running on this virtual machine, run: %s env %s", os.Args[0], h.Name)
 
 	return nil
 }
@@ -289,15 +876,40 @@ func flagHackLookup(flagName string) string {
 }
 
 func cmdCreateOuter(c CommandLine, api libmachine.API) error {
+	cliFlags, err := lookupDriverFlags(api)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.Application().Commands {
+		cmd := &c.Application().Commands[i]
+		if cmd.HasName("create") {
+			cmd = addDriverFlagsToCommand(cliFlags, cmd)
+		}
+	}
+
+	return c.Application().Run(os.Args)
+}
+
+// lookupDriverFlags returns the create flags of the driver given with
+// --driver, or else the one of the profile, of MACHINE_DRIVER or of the
+// configuration file, virtualbox by default.
+func lookupDriverFlags(api libmachine.API) ([]cli.Flag, error) {
 	const (
 		flagLookupMachineName = "flag-lookup"
 	)
 
 	// We didn't recognize the driver name.
 	driverName := flagHackLookup("--driver")
+	if driverName == "" {
+		driverName = profileDriver(api)
+	}
 	if driverName == "" {
 		//TODO: Check Environment have to include flagHackLookup function.
 		driverName = os.Getenv("MACHINE_DRIVER")
+	}
+	if driverName == "" {
+		driverName = configDriver()
 		if driverName == "" {
 			driverName = "virtualbox"
 		}
@@ -308,12 +920,12 @@ func cmdCreateOuter(c CommandLine, api libmachine.API) error {
 		MachineName: flagLookupMachineName,
 	})
 	if err != nil {
-		return fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
 	}
 
 	h, err := api.NewHost(driverName, rawDriver)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// TODO: So much flag manipulation and voodoo here, it seems to be
@@ -327,17 +939,10 @@ func cmdCreateOuter(c CommandLine, api libmachine.API) error {
 	// on the requested driver.
 	cliFlags, err := convertMcnFlagsToCliFlags(mcnFlags)
 	if err != nil {
-		return fmt.Errorf("Error trying to convert provided driver flags to cli flags: %s", err)
+		return nil, fmt.Errorf("Error trying to convert provided driver flags to cli flags: %s", err)
 	}
 
-	for i := range c.Application().Commands {
-		cmd := &c.Application().Commands[i]
-		if cmd.HasName("create") {
-			cmd = addDriverFlagsToCommand(cliFlags, cmd)
-		}
-	}
-
-	return c.Application().Run(os.Args)
+	return cliFlags, nil
 }
 
 func getDriverOpts(c CommandLine, mcnflags []mcnflag.Flag) drivers.DriverOptions {
@@ -401,10 +1006,17 @@ func convertMcnFlagsToCliFlags(mcnFlags []mcnflag.Flag) ([]cli.Flag, error) {
 			})
 		case *mcnflag.StringFlag:
 			f := f.(*mcnflag.StringFlag)
+			usage := f.Usage
+			if len(f.Choices) > 0 {
+				usage = fmt.Sprintf("%s (%s)", usage, strings.Join(f.Choices, "|"))
+			}
+			if f.Required {
+				usage += " [required]"
+			}
 			cliFlags = append(cliFlags, cli.StringFlag{
 				Name:   f.Name,
 				EnvVar: f.EnvVar,
-				Usage:  f.Usage,
+				Usage:  usage,
 				Value:  f.Value,
 			})
 		case *mcnflag.StringSliceFlag:
@@ -440,16 +1052,152 @@ func validateSwarmDiscovery(discovery string) error {
 		return nil
 	}
 
-	matched, err := regexp.MatchString(`[^:]*://.*`, discovery)
+	_, err := swarm.ParseDiscovery(discovery)
+	return err
+}
+
+// getSwarmDiscovery returns the discovery given with --swarm-discovery, or
+// the key-value store of the machine given with --swarm-discovery-machine.
+func getSwarmDiscovery(c CommandLine, api libmachine.API) (string, error) {
+	storeMachine := c.String("swarm-discovery-machine")
+	if storeMachine == "" {
+		return c.String("swarm-discovery"), nil
+	}
+
+	if c.String("swarm-discovery") != "" {
+		return "", errors.New("--swarm-discovery and --swarm-discovery-machine cannot be used together")
+	}
+
+	h, err := api.Load(storeMachine)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if matched {
-		return nil
+	if h.HostOptions == nil || h.HostOptions.SwarmOptions == nil || h.HostOptions.SwarmOptions.DiscoveryStore == "" {
+		return "", fmt.Errorf("%q does not run a discovery store, see --swarm-discovery-store", storeMachine)
+	}
+
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return "", fmt.Errorf("Error getting the IP of %q: %s", storeMachine, err)
+	}
+
+	discovery, err := swarm.KVStoreDiscovery(h.HostOptions.SwarmOptions.DiscoveryStore, ip)
+	if err != nil {
+		return "", err
 	}
 
-	return fmt.Errorf("Swarm Discovery URL was in the wrong format: %s", discovery)
+	return discovery.String(), nil
+}
+
+func validateDiscoveryOptions(c CommandLine, swarmDiscovery string) error {
+	if store := c.String("swarm-discovery-store"); store != "" {
+		if _, err := swarm.KVStoreDiscovery(store, ""); err != nil {
+			return err
+		}
+	}
+
+	if c.Bool("swarm-discovery-tls") {
+		discovery, err := swarm.ParseDiscovery(swarmDiscovery)
+		if err != nil || !discovery.IsKVStore() {
+			return errors.New("--swarm-discovery-tls needs the discovery to be a key-value store")
+		}
+	}
+
+	return nil
+}
+
+func parseInstanceTags(tags []string) (map[string]string, error) {
+	parsed := map[string]string{}
+
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Invalid instance tag %q, the key=value format is expected", tag)
+		}
+		parsed[parts[0]] = parts[1]
+	}
+
+	return parsed, nil
+}
+
+// parseSysctls parses the kernel parameters given in the key=value format.
+func parseSysctls(specs []string) (map[string]string, error) {
+	sysctls := map[string]string{}
+
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("Invalid sysctl %q, the key=value format is expected", spec)
+		}
+		sysctls[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	if err := provision.ValidateSysctls(sysctls); err != nil {
+		return nil, err
+	}
+
+	return sysctls, nil
+}
+
+// parseExtraUsers reads the public keys of the users given in the
+// name:public-key-file format. A user given several times gets all the keys.
+func parseExtraUsers(specs []string) ([]provision.User, error) {
+	users := []provision.User{}
+	indexes := map[string]int{}
+
+	for _, spec := range specs {
+		parts := strings.SplitN(spec, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("Invalid user %q, the name:public-key-file format is expected", spec)
+		}
+
+		data, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the public key of the user %s: %s", parts[0], err)
+		}
+
+		i, ok := indexes[parts[0]]
+		if !ok {
+			i = len(users)
+			indexes[parts[0]] = i
+			users = append(users, provision.User{Name: parts[0]})
+		}
+
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				users[i].PublicKeys = append(users[i].PublicKeys, line)
+			}
+		}
+	}
+
+	for _, user := range users {
+		if err := user.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return users, nil
+}
+
+// absPaths makes the given paths absolute, since the files are read again
+// when the certificates are regenerated, from any directory. Empty paths are
+// left empty.
+func absPaths(certPath, keyPath string) (string, string, error) {
+	paths := []string{certPath, keyPath}
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return "", "", err
+		}
+		paths[i] = abs
+	}
+
+	return paths[0], paths[1], nil
 }
 
 func tlsPath(c CommandLine, flag string, defaultName string) string {
@@ -460,3 +1208,13 @@ func tlsPath(c CommandLine, flag string, defaultName string) string {
 
 	return filepath.Join(mcndirs.GetMachineCertDir(), defaultName)
 }
+
+// assetCacheDir returns the directory of the asset cache when the engine is
+// to be installed from it, and an empty string otherwise.
+func assetCacheDir(enabled bool) string {
+	if !enabled {
+		return ""
+	}
+
+	return mcndirs.GetAssetCacheDir()
+}
@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"os"
+	"strings"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/commands/mcnconfig"
+	"github.com/docker/machine/commands/mcndirs"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// withConfigDefaults returns the command line taking the flags it does not
+// set, either on the command line, with their environment variable or in a
+// profile, from the configuration file of the store.
+func withConfigDefaults(c CommandLine) (CommandLine, error) {
+	config, err := mcnconfig.Load(mcndirs.GetConfigFilePath())
+	if err != nil {
+		return nil, err
+	}
+
+	driverName := c.String("driver")
+	if configDriver := config.Driver(); configDriver != "" && !c.IsSet("driver") && !flagEnvSet(c, "driver") {
+		driverName = configDriver
+	}
+
+	flags := config.Flags(driverName)
+	for name := range flags {
+		if flagEnvSet(c, name) {
+			delete(flags, name)
+		}
+	}
+	flags["driver"] = driverName
+
+	return &profileCommandLine{c, &persist.Profile{Name: "config", Flags: flags}}, nil
+}
+
+// configDriver returns the default driver of the configuration file, before
+// the create flags of the driver are known.
+func configDriver() string {
+	config, err := mcnconfig.Load(mcndirs.GetConfigFilePath())
+	if err != nil {
+		log.Debugf("Unable to load the configuration file: %s", err)
+		return ""
+	}
+
+	return config.Driver()
+}
+
+// flagEnvSet reports whether the create flag takes its value from an
+// environment variable.
+func flagEnvSet(c CommandLine, name string) bool {
+	app := c.Application()
+	if app == nil {
+		return false
+	}
+
+	for _, cmd := range app.Commands {
+		if !cmd.HasName("create") {
+			continue
+		}
+
+		for _, f := range cmd.Flags {
+			if envVar := flagEnvVar(f, name); envVar != "" && os.Getenv(envVar) != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// flagEnvVar returns the environment variable of the flag if it has the
+// given name.
+func flagEnvVar(f cli.Flag, name string) string {
+	flagName, envVar := cliFlagInfo(f)
+	for _, n := range strings.Split(flagName, ",") {
+		if strings.TrimSpace(n) == name {
+			return envVar
+		}
+	}
+	return ""
+}
+
+// cliFlagInfo returns the names, separated by commas, and the environment
+// variable of a flag.
+func cliFlagInfo(f cli.Flag) (string, string) {
+	switch f := f.(type) {
+	case cli.StringFlag:
+		return f.Name, f.EnvVar
+	case cli.IntFlag:
+		return f.Name, f.EnvVar
+	case cli.BoolFlag:
+		return f.Name, f.EnvVar
+	case cli.StringSliceFlag:
+		return f.Name, f.EnvVar
+	}
+	return "", ""
+}
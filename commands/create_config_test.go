@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/commands/commandstest"
+	"github.com/docker/machine/commands/mcndirs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithConfigDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(baseDir string) { mcndirs.BaseDir = baseDir }(mcndirs.BaseDir)
+	mcndirs.BaseDir = dir
+
+	config := `driver = "amazonec2"
+
+[engine]
+storage-driver = "overlay2"
+label = ["env=dev"]
+
+[drivers.amazonec2]
+region = "eu-west-1"
+
+[drivers.virtualbox]
+memory = 2048
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.toml"), []byte(config), 0600))
+
+	c, err := withConfigDefaults(&commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"engine-storage-driver": "aufs",
+			},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "amazonec2", c.String("driver"))
+	assert.Equal(t, "aufs", c.String("engine-storage-driver"))
+	assert.Equal(t, []string{"env=dev"}, c.StringSlice("engine-label"))
+	assert.Equal(t, "eu-west-1", c.String("amazonec2-region"))
+	assert.False(t, c.IsSet("virtualbox-memory"))
+}
+
+func TestWithConfigDefaultsExplicitDriver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-config-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(baseDir string) { mcndirs.BaseDir = baseDir }(mcndirs.BaseDir)
+	mcndirs.BaseDir = dir
+
+	config := `driver = "amazonec2"
+
+[drivers.virtualbox]
+memory = 2048
+`
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.toml"), []byte(config), 0600))
+
+	c, err := withConfigDefaults(&commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"driver": "virtualbox",
+			},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "virtualbox", c.String("driver"))
+	assert.Equal(t, 2048, c.Int("virtualbox-memory"))
+}
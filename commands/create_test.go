@@ -1,11 +1,16 @@
 package commands
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"flag"
 	"github.com/docker/machine/commands/commandstest"
 	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/namegen"
+	"github.com/docker/machine/libmachine/provision"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -117,3 +122,83 @@ func TestGetDriverOpts(t *testing.T) {
 		assert.Equal(t, tt.expected["stringslice_defaulted"], driverOpts.StringSlice("stringslice_defaulted"))
 	}
 }
+
+func TestParseInstanceTags(t *testing.T) {
+	tags, err := parseInstanceTags([]string{"env=test", "owner=alice", "empty="})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"env":   "test",
+		"owner": "alice",
+		"empty": "",
+	}, tags)
+}
+
+func TestParseInstanceTagsInvalid(t *testing.T) {
+	for _, tag := range []string{"env", "=test"} {
+		_, err := parseInstanceTags([]string{tag})
+
+		assert.Error(t, err)
+	}
+}
+
+func TestValidateSwarmDiscoveryErrorsGivenUnsupportedBackend(t *testing.T) {
+	err := validateSwarmDiscovery("redis://10.0.0.2:6379")
+	assert.Error(t, err)
+}
+
+func TestParseExtraUsers(t *testing.T) {
+	keyFile, err := ioutil.TempFile("", "docker-machine-test-key")
+	assert.NoError(t, err)
+	defer os.Remove(keyFile.Name())
+
+	fmt.Fprintln(keyFile, "# the laptop of alice")
+	fmt.Fprintln(keyFile, testPublicKey+" alice@laptop")
+	keyFile.Close()
+
+	users, err := parseExtraUsers([]string{"alice:" + keyFile.Name(), "bob:" + keyFile.Name(), "alice:" + keyFile.Name()})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []provision.User{
+		{Name: "alice", PublicKeys: []string{testPublicKey + " alice@laptop", testPublicKey + " alice@laptop"}},
+		{Name: "bob", PublicKeys: []string{testPublicKey + " alice@laptop"}},
+	}, users)
+}
+
+func TestParseExtraUsersInvalid(t *testing.T) {
+	for _, spec := range []string{"alice", ":key.pub", "alice:", "alice:/nonexistent/key.pub"} {
+		_, err := parseExtraUsers([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+const testPublicKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHJz0kdy0xOwGQ9mLE3sCmYMoAUc07b+bz3MbpZsK+hc"
+
+func TestParseSysctls(t *testing.T) {
+	sysctls, err := parseSysctls([]string{"vm.max_map_count=262144", "net.ipv4.ip_local_port_range = 1024 65000"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"vm.max_map_count":             "262144",
+		"net.ipv4.ip_local_port_range": "1024 65000",
+	}, sysctls)
+
+	for _, spec := range []string{"vm.max_map_count", "=1", "vm.max_map_count="} {
+		_, err := parseSysctls([]string{spec})
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNameGenerator(t *testing.T) {
+	prefixed := nameGenerator(&commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{"name-prefix": "ci"},
+		},
+	})
+	assert.Equal(t, "ci-2", prefixed.Generate(1))
+
+	generated := nameGenerator(&commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{Data: map[string]interface{}{}},
+	})
+	assert.Equal(t, namegen.AdjectiveNoun{}, generated)
+}
@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+)
+
+func cmdDiagnose(c CommandLine, api libmachine.API) error {
+	repair := c.Bool("repair")
+
+	return runHostAction("", func(h *host.Host) error {
+		diagnosis := h.Diagnose()
+		fmt.Print(formatDiagnosis(diagnosis))
+
+		if !repair || diagnosis.Healthy() {
+			return nil
+		}
+
+		return diagnosis.Repair()
+	}, c, api)
+}
+
+func formatDiagnosis(diagnosis *host.Diagnosis) string {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s:\n", diagnosis.Host)
+	for _, check := range diagnosis.Checks {
+		fmt.Fprintf(&buf, "  [%s] %s", check.Status, check.Name)
+		if check.Message != "" {
+			fmt.Fprintf(&buf, ": %s", check.Message)
+		}
+		if check.Status == host.CheckFailed && check.RepairAction != "" {
+			fmt.Fprintf(&buf, " (--repair to %s)", check.RepairAction)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/codegangsta/cli"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// discoverFlags are the flags of discover, on top of the create flags of
+// the driver.
+var discoverFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "driver, d",
+		Usage:  "Driver to discover instances with",
+		Value:  "virtualbox",
+		EnvVar: "MACHINE_DRIVER",
+	},
+	cli.BoolFlag{
+		Name:  "adopt",
+		Usage: "Adopt the discovered instances given as arguments, or all of them without arguments",
+	},
+}
+
+func cmdDiscoverOuter(c CommandLine, api libmachine.API) error {
+	cliFlags, err := lookupDriverFlags(api)
+	if err != nil {
+		return err
+	}
+
+	for i := range c.Application().Commands {
+		cmd := &c.Application().Commands[i]
+		if cmd.HasName("discover") {
+			cmd.Flags = append(append([]cli.Flag{}, discoverFlags...), cliFlags...)
+			cmd.SkipFlagParsing = false
+			cmd.Action = runCommand(cmdDiscoverInner)
+			sort.Sort(ByFlagName(cmd.Flags))
+		}
+	}
+
+	return c.Application().Run(os.Args)
+}
+
+func cmdDiscoverInner(c CommandLine, api libmachine.API) error {
+	h, err := newDiscoveryHost(c, api, "discovery")
+	if err != nil {
+		return err
+	}
+
+	instances, err := libmachine.Discover(api, h.Driver)
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("adopt") {
+		if len(c.Args()) > 0 {
+			return fmt.Errorf("Invalid command line. Found extra arguments %v, set --adopt to adopt instances", c.Args())
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
+		fmt.Fprintln(w, "NAME\tID\tIP\tSTATE")
+		for _, instance := range instances {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", instance.MachineName, instance.ID, instance.IP, instance.State)
+		}
+		return w.Flush()
+	}
+
+	toAdopt, err := instancesToAdopt(instances, c.Args())
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, instance := range toAdopt {
+		log.Infof("Adopting the instance %s as %s...", instance.ID, instance.MachineName)
+
+		h, err := newDiscoveryHost(c, api, instance.MachineName)
+		if err == nil {
+			err = libmachine.Adopt(api, h, instance)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Error adopting %s: %s", instance.MachineName, err))
+			continue
+		}
+
+		log.Infof("Adopted %s, run %s env %s to connect to it", instance.MachineName, os.Args[0], instance.MachineName)
+	}
+
+	if len(errs) > 0 {
+		return consolidateErrs(errs)
+	}
+
+	return nil
+}
+
+// newDiscoveryHost returns a host of the driver of the flags, named after
+// the instance it is to adopt.
+func newDiscoveryHost(c CommandLine, api libmachine.API, name string) (*host.Host, error) {
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: name,
+		StorePath:   c.GlobalString("storage-path"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+	}
+
+	h, err := api.NewHost(c.String("driver"), rawDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.Driver.SetConfigFromFlags(getDriverOpts(c, h.Driver.GetCreateFlags())); err != nil {
+		return nil, fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
+	}
+
+	return h, nil
+}
+
+// instancesToAdopt returns the discovered instances of the given machine
+// names, all of them if no name is given.
+func instancesToAdopt(instances []drivers.DiscoveredInstance, names []string) ([]drivers.DiscoveredInstance, error) {
+	if len(names) == 0 {
+		return instances, nil
+	}
+
+	toAdopt := []drivers.DiscoveredInstance{}
+	for _, name := range names {
+		found := false
+		for _, instance := range instances {
+			if instance.MachineName == name {
+				toAdopt = append(toAdopt, instance)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("No instance of a machine named %q was discovered", name)
+		}
+	}
+
+	return toAdopt, nil
+}
@@ -0,0 +1,157 @@
+// Package formatter renders the output of the listing and inspect commands,
+// either with a Go template given by the user or as JSON documents of the
+// schemas of this package, the field names of which are kept stable across
+// versions for scripts to parse.
+package formatter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+const (
+	// JSONFormat is the format printing the JSON documents of the schemas.
+	JSONFormat = "json"
+
+	tableFormatKey = "table"
+)
+
+// Funcs are the functions available to the templates.
+var Funcs = template.FuncMap{
+	"json": func(v interface{}) string {
+		a, _ := json.Marshal(v)
+		return string(a)
+	},
+	"prettyjson": func(v interface{}) string {
+		a, _ := json.MarshalIndent(v, "", "    ")
+		return string(a)
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// Format is a parsed output format.
+type Format struct {
+	template *template.Template
+	table    bool
+	json     bool
+}
+
+// Parse parses the format given by the user, defaultFormat when empty. The
+// format is either "json", or a Go template printed for every item, in which
+// \t and \n stand for tabs and new lines. A template prefixed with "table"
+// is printed in aligned columns under a header line.
+func Parse(format, defaultFormat string) (*Format, error) {
+	if format == "" {
+		format = defaultFormat
+	}
+
+	if format == JSONFormat {
+		return &Format{json: true}, nil
+	}
+
+	f := &Format{}
+	if strings.HasPrefix(format, tableFormatKey) {
+		f.table = true
+		format = format[len(tableFormatKey):]
+	}
+
+	format = strings.Trim(format, " ")
+	r := strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+	format = r.Replace(format)
+
+	tmpl, err := template.New("").Funcs(Funcs).Parse(format + "\n")
+	if err != nil {
+		return nil, fmt.Errorf("template parsing error: %v", err)
+	}
+	f.template = tmpl
+
+	return f, nil
+}
+
+// IsJSON reports whether the format prints the JSON documents of the
+// schemas rather than executing a template.
+func (f *Format) IsJSON() bool {
+	return f.json
+}
+
+// IsTable reports whether the template is printed in columns.
+func (f *Format) IsTable() bool {
+	return f.table
+}
+
+// Writer returns a writer of the items in the format to w, printing the
+// header first for tables. Close must be called once the items are written.
+func (f *Format) Writer(w io.Writer, header interface{}) (*Writer, error) {
+	writer := &Writer{format: f, out: w}
+
+	if f.json {
+		return writer, nil
+	}
+
+	if f.table {
+		writer.tabWriter = tabwriter.NewWriter(w, 5, 1, 3, ' ', 0)
+		writer.out = writer.tabWriter
+
+		if err := f.template.Execute(writer.out, header); err != nil {
+			return nil, err
+		}
+	}
+
+	return writer, nil
+}
+
+// Writer writes items in a format.
+type Writer struct {
+	format    *Format
+	out       io.Writer
+	tabWriter *tabwriter.Writer
+	documents []interface{}
+}
+
+// Write prints the item with the template of the format. With the JSON
+// format, the document of the item is kept to be printed by Close, as an
+// element of a JSON array.
+func (w *Writer) Write(item, document interface{}) error {
+	if w.format.json {
+		w.documents = append(w.documents, document)
+		return nil
+	}
+
+	return w.format.template.Execute(w.out, item)
+}
+
+// Close flushes the columns of a table, or prints the JSON array of the
+// documents.
+func (w *Writer) Close() error {
+	if w.tabWriter != nil {
+		return w.tabWriter.Flush()
+	}
+
+	if !w.format.json {
+		return nil
+	}
+
+	documents := w.documents
+	if documents == nil {
+		documents = []interface{}{}
+	}
+
+	return WriteJSON(w.out, documents)
+}
+
+// WriteJSON prints a JSON document, indented.
+func WriteJSON(w io.Writer, document interface{}) error {
+	data, err := json.MarshalIndent(document, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
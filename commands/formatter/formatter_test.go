@@ -0,0 +1,77 @@
+package formatter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type item struct {
+	Name  string
+	State string
+}
+
+func TestTemplateFormat(t *testing.T) {
+	format, err := Parse("", "{{ .Name }}:{{ lower .State }}")
+	assert.NoError(t, err)
+	assert.False(t, format.IsJSON())
+	assert.False(t, format.IsTable())
+
+	var buf bytes.Buffer
+	w, err := format.Writer(&buf, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Write(item{"dev", "Running"}, nil))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "dev:running\n", buf.String())
+}
+
+func TestTableFormat(t *testing.T) {
+	format, err := Parse(`table {{ .Name }}\t{{ .State }}`, "")
+	assert.NoError(t, err)
+	assert.True(t, format.IsTable())
+
+	var buf bytes.Buffer
+	w, err := format.Writer(&buf, map[string]string{"Name": "NAME", "State": "STATE"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Write(item{"dev", "Running"}, nil))
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "NAME   STATE\ndev    Running\n", buf.String())
+}
+
+func TestJSONFormat(t *testing.T) {
+	format, err := Parse("json", "")
+	assert.NoError(t, err)
+	assert.True(t, format.IsJSON())
+
+	var buf bytes.Buffer
+	w, err := format.Writer(&buf, nil)
+	assert.NoError(t, err)
+
+	assert.NoError(t, w.Write(item{"dev", "Running"}, Machine{SchemaVersion: SchemaVersion, Name: "dev", State: "Running"}))
+	assert.NoError(t, w.Close())
+
+	assert.Contains(t, buf.String(), `"SchemaVersion": 1`)
+	assert.Contains(t, buf.String(), `"Name": "dev"`)
+	assert.Contains(t, buf.String(), `"ResponseTimeMillis": 0`)
+}
+
+func TestJSONFormatWithoutItems(t *testing.T) {
+	format, _ := Parse("json", "")
+
+	var buf bytes.Buffer
+	w, _ := format.Writer(&buf, nil)
+	assert.NoError(t, w.Close())
+
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestParseInvalidTemplate(t *testing.T) {
+	_, err := Parse("{{ .Name", "")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package formatter
+
+// SchemaVersion is the version of the JSON documents. Fields are only ever
+// added to a schema; renaming or removing one bumps the version.
+const SchemaVersion = 1
+
+// Machine is the JSON document of a machine in listings.
+type Machine struct {
+	// SchemaVersion is the version of the document, see SchemaVersion.
+	SchemaVersion int `json:"SchemaVersion"`
+
+	// Name is the name of the machine.
+	Name string `json:"Name"`
+
+	// Active is true when the environment points at the engine of the
+	// machine, ActiveSwarm when it points at the swarm it manages.
+	Active      bool `json:"Active"`
+	ActiveSwarm bool `json:"ActiveSwarm"`
+
+	// DriverName is the name of the driver of the machine.
+	DriverName string `json:"DriverName"`
+
+	// State is the state of the machine, e.g. Running or Stopped.
+	State string `json:"State"`
+
+	// URL is the URL of the engine, empty when the machine is not running.
+	URL string `json:"URL"`
+
+	// Swarm is the name of the swarm master of the machine, SwarmMaster is
+	// true for the master itself.
+	Swarm       string `json:"Swarm"`
+	SwarmMaster bool   `json:"SwarmMaster"`
+
+	// DockerVersion is the version of the engine, e.g. v17.09.0-ce.
+	DockerVersion string `json:"DockerVersion"`
+
+	// Error is the error met while reading the state of the machine.
+	Error string `json:"Error"`
+
+	// ResponseTimeMillis is how long reading the state took.
+	ResponseTimeMillis int64 `json:"ResponseTimeMillis"`
+
+	// Owner is the user who last saved the machine.
+	Owner string `json:"Owner"`
+
+	// Region and Zone are where the provider runs the machine, empty for
+	// the drivers without regions.
+	Region string `json:"Region"`
+	Zone   string `json:"Zone"`
+
+	// StorageDriver, EngineOS and Containers are what the engine reports
+	// in docker info: its storage driver, the distribution of the machine
+	// and the number of containers. They are empty when the engine cannot
+	// be queried.
+	StorageDriver string `json:"StorageDriver"`
+	EngineOS      string `json:"EngineOS"`
+	Containers    int64  `json:"Containers"`
+}
+
+// MachineDetails is the JSON document of inspect. It is read from the store
+// only, without querying the machine.
+type MachineDetails struct {
+	// SchemaVersion is the version of the document, see SchemaVersion.
+	SchemaVersion int `json:"SchemaVersion"`
+
+	// Name is the name of the machine.
+	Name string `json:"Name"`
+
+	// DriverName is the name of the driver of the machine.
+	DriverName string `json:"DriverName"`
+
+	// IPAddress is the last IP address the driver recorded, if any.
+	IPAddress string `json:"IPAddress"`
+
+	// SSHUser, SSHPort and SSHKeyPath are how to connect to the machine.
+	SSHUser    string `json:"SSHUser"`
+	SSHPort    int    `json:"SSHPort"`
+	SSHKeyPath string `json:"SSHKeyPath"`
+
+	// Engine holds the options of the engine of the machine.
+	Engine EngineDetails `json:"Engine"`
+
+	// Swarm holds the swarm options of the machine.
+	Swarm SwarmDetails `json:"Swarm"`
+
+	// TLS holds the paths of the certificates used to reach the engine.
+	TLS TLSDetails `json:"TLS"`
+
+	// CreatePhase is the last completed phase of the creation, empty for
+	// machines created before the phases were recorded.
+	CreatePhase string `json:"CreatePhase"`
+
+	// Region and Zone are where the provider runs the machine, empty for
+	// the drivers without regions.
+	Region string `json:"Region"`
+	Zone   string `json:"Zone"`
+}
+
+// EngineDetails are the options of the engine in MachineDetails.
+type EngineDetails struct {
+	InstallURL       string   `json:"InstallURL"`
+	StorageDriver    string   `json:"StorageDriver"`
+	Labels           []string `json:"Labels"`
+	Env              []string `json:"Env"`
+	InsecureRegistry []string `json:"InsecureRegistry"`
+	RegistryMirror   []string `json:"RegistryMirror"`
+	ArbitraryFlags   []string `json:"ArbitraryFlags"`
+}
+
+// SwarmDetails are the swarm options in MachineDetails.
+type SwarmDetails struct {
+	Master    bool   `json:"Master"`
+	Discovery string `json:"Discovery"`
+	Host      string `json:"Host"`
+}
+
+// TLSDetails are the certificate paths in MachineDetails.
+type TLSDetails struct {
+	CaCertPath     string `json:"CaCertPath"`
+	ClientCertPath string `json:"ClientCertPath"`
+	ClientKeyPath  string `json:"ClientKeyPath"`
+	StorePath      string `json:"StorePath"`
+}
@@ -1,25 +1,16 @@
 package commands
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
-	"text/template"
 
+	"github.com/docker/machine/commands/formatter"
 	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
 )
 
-var funcMap = template.FuncMap{
-	"json": func(v interface{}) string {
-		a, _ := json.Marshal(v)
-		return string(a)
-	},
-	"prettyjson": func(v interface{}) string {
-		a, _ := json.MarshalIndent(v, "", "    ")
-		return string(a)
-	},
-}
-
 func cmdInspect(c CommandLine, api libmachine.API) error {
 	if len(c.Args()) > 1 {
 		c.ShowHelp()
@@ -31,20 +22,28 @@ func cmdInspect(c CommandLine, api libmachine.API) error {
 		return err
 	}
 
-	host, err := api.Load(target)
+	h, err := api.Load(target)
 	if err != nil {
 		return err
 	}
 
 	tmplString := c.String("format")
+	if tmplString == formatter.JSONFormat {
+		details, err := machineDetails(h)
+		if err != nil {
+			return err
+		}
+
+		return formatter.WriteJSON(os.Stdout, details)
+	}
+
 	if tmplString != "" {
-		var tmpl *template.Template
-		var err error
-		if tmpl, err = template.New("").Funcs(funcMap).Parse(tmplString); err != nil {
-			return fmt.Errorf("template parsing error: %v", err)
+		format, err := formatter.Parse(tmplString, "")
+		if err != nil {
+			return err
 		}
 
-		jsonHost, err := json.Marshal(host)
+		jsonHost, err := marshalHost(h, c.Bool("show-secrets"))
 		if err != nil {
 			return err
 		}
@@ -54,19 +53,111 @@ func cmdInspect(c CommandLine, api libmachine.API) error {
 			return err
 		}
 
-		if err := tmpl.Execute(os.Stdout, obj); err != nil {
+		w, err := format.Writer(os.Stdout, nil)
+		if err != nil {
 			return err
 		}
 
-		os.Stdout.Write([]byte{'\n'})
-	} else {
-		prettyJSON, err := json.MarshalIndent(host, "", "    ")
-		if err != nil {
+		if err := w.Write(obj, nil); err != nil {
 			return err
 		}
 
-		fmt.Println(string(prettyJSON))
+		return w.Close()
 	}
 
+	jsonHost, err := marshalHost(h, c.Bool("show-secrets"))
+	if err != nil {
+		return err
+	}
+
+	var prettyJSON bytes.Buffer
+	if err := json.Indent(&prettyJSON, jsonHost, "", "    "); err != nil {
+		return err
+	}
+
+	fmt.Println(prettyJSON.String())
+
 	return nil
 }
+
+// marshalHost returns the configuration of the machine with the secrets of
+// its driver redacted, unless showSecrets is set.
+func marshalHost(h *host.Host, showSecrets bool) ([]byte, error) {
+	if showSecrets {
+		return json.Marshal(h)
+	}
+
+	return host.MarshalRedacted(h)
+}
+
+// machineDetails returns the JSON document of the inspected machine. The
+// address and SSH settings are read from the driver configuration kept in
+// the store, which all the drivers embedding BaseDriver share.
+func machineDetails(h *host.Host) (*formatter.MachineDetails, error) {
+	details := &formatter.MachineDetails{
+		SchemaVersion: formatter.SchemaVersion,
+		Name:          h.Name,
+		DriverName:    h.DriverName,
+		CreatePhase:   string(h.CreatePhase),
+	}
+
+	rawDriver, err := json.Marshal(h.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var baseDriver struct {
+		IPAddress  string
+		SSHUser    string
+		SSHPort    int
+		SSHKeyPath string
+	}
+	if err := json.Unmarshal(rawDriver, &baseDriver); err != nil {
+		return nil, fmt.Errorf("Error reading the driver configuration of %q: %s", h.Name, err)
+	}
+
+	details.IPAddress = baseDriver.IPAddress
+	details.SSHUser = baseDriver.SSHUser
+	details.SSHPort = baseDriver.SSHPort
+	details.SSHKeyPath = baseDriver.SSHKeyPath
+
+	if h.HostOptions == nil {
+		return details, nil
+	}
+
+	if placement := h.HostOptions.Placement; placement != nil {
+		details.Region = placement.Region
+		details.Zone = placement.Zone
+	}
+
+	if engineOptions := h.HostOptions.EngineOptions; engineOptions != nil {
+		details.Engine = formatter.EngineDetails{
+			InstallURL:       engineOptions.InstallURL,
+			StorageDriver:    engineOptions.StorageDriver,
+			Labels:           engineOptions.Labels,
+			Env:              engineOptions.Env,
+			InsecureRegistry: engineOptions.InsecureRegistry,
+			RegistryMirror:   engineOptions.RegistryMirror,
+			ArbitraryFlags:   engineOptions.ArbitraryFlags,
+		}
+	}
+
+	if swarmOptions := h.HostOptions.SwarmOptions; swarmOptions != nil {
+		details.Swarm = formatter.SwarmDetails{
+			Master:    swarmOptions.Master,
+			Discovery: swarmOptions.Discovery,
+			Host:      swarmOptions.Host,
+		}
+	}
+
+	if authOptions := h.HostOptions.AuthOptions; authOptions != nil {
+		details.TLS = formatter.TLSDetails{
+			CaCertPath:     authOptions.CaCertPath,
+			ClientCertPath: authOptions.ClientCertPath,
+			ClientKeyPath:  authOptions.ClientKeyPath,
+			StorePath:      authOptions.StorePath,
+		}
+	}
+
+	return details, nil
+}
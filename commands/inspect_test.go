@@ -4,7 +4,11 @@ import (
 	"testing"
 
 	"github.com/docker/machine/commands/commandstest"
+	"github.com/docker/machine/drivers/fakedriver"
 	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/libmachinetest"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,3 +33,33 @@ func TestCmdInspect(t *testing.T) {
 		assert.Equal(t, tc.expectedErr, err)
 	}
 }
+
+func TestMachineDetails(t *testing.T) {
+	h := &host.Host{
+		Name:       "dev",
+		DriverName: "fakedriver",
+		Driver: &fakedriver.Driver{
+			BaseDriver: &drivers.BaseDriver{
+				IPAddress: "192.168.99.100",
+				SSHUser:   "docker",
+				SSHPort:   22,
+			},
+		},
+		HostOptions: &host.Options{
+			EngineOptions: &engine.Options{StorageDriver: "overlay2"},
+			Placement:     &drivers.Placement{Region: "eu-west-1", Zone: "eu-west-1a"},
+		},
+		CreatePhase: host.CreatePhaseProvisioned,
+	}
+
+	details, err := machineDetails(h)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.99.100", details.IPAddress)
+	assert.Equal(t, "docker", details.SSHUser)
+	assert.Equal(t, 22, details.SSHPort)
+	assert.Equal(t, "overlay2", details.Engine.StorageDriver)
+	assert.Equal(t, "provisioned", details.CreatePhase)
+	assert.Equal(t, "eu-west-1", details.Region)
+	assert.Equal(t, "eu-west-1a", details.Zone)
+}
@@ -7,12 +7,9 @@ import (
 	"regexp"
 	"sort"
 	"strings"
-	"text/tabwriter"
-	"text/template"
 	"time"
 
-	"io"
-
+	"github.com/docker/machine/commands/formatter"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/engine"
@@ -27,7 +24,6 @@ import (
 
 const (
 	lsDefaultTimeout = 10
-	tableFormatKey   = "table"
 	lsDefaultFormat  = "table {{ .Name }}\t{{ .Active }}\t{{ .DriverName}}\t{{ .State }}\t{{ .URL }}\t{{ .Swarm }}\t{{ .DockerVersion }}\t{{ .Error}}"
 )
 
@@ -46,6 +42,11 @@ var (
 		"Error":         "ERRORS",
 		"DockerVersion": "DOCKER",
 		"ResponseTime":  "RESPONSE",
+		"Owner":         "OWNER",
+		"Placement":     "PLACEMENT",
+		"StorageDriver": "STORAGE_DRIVER",
+		"EngineOS":      "ENGINE_OS",
+		"Containers":    "CONTAINERS",
 	}
 )
 
@@ -63,6 +64,11 @@ type HostListItem struct {
 	Error         string
 	DockerVersion string
 	ResponseTime  time.Duration
+	Owner         string
+	Placement     drivers.Placement
+	StorageDriver string
+	EngineOS      string
+	Containers    int64
 }
 
 // FilterOptions -
@@ -95,27 +101,19 @@ func cmdLs(c CommandLine, api libmachine.API) error {
 		return nil
 	}
 
-	template, table, err := parseFormat(c.String("format"))
+	format, err := formatter.Parse(c.String("format"), lsDefaultFormat)
 	if err != nil {
 		return err
 	}
 
-	var w io.Writer
-	if table {
-		tabWriter := tabwriter.NewWriter(os.Stdout, 5, 1, 3, ' ', 0)
-		defer tabWriter.Flush()
-
-		w = tabWriter
-
-		if err := template.Execute(w, headers); err != nil {
-			return err
-		}
-	} else {
-		w = os.Stdout
+	w, err := format.Writer(os.Stdout, headers)
+	if err != nil {
+		return err
 	}
 
 	timeout := time.Duration(c.Int("timeout")) * time.Second
 	items := getHostListItems(hostList, hostInError, timeout)
+	recordLastKnown(api, hostList, items)
 
 	swarmMasters := make(map[string]string)
 	swarmInfo := make(map[string]string)
@@ -143,37 +141,49 @@ func cmdLs(c CommandLine, api libmachine.API) error {
 		}
 		item.Swarm = swarmColumn
 
-		if err := template.Execute(w, item); err != nil {
+		if ownerReader, ok := api.(persist.OwnerReader); ok {
+			if owner, err := ownerReader.Owner(item.Name); err != nil {
+				log.Debugf("Unable to get the owner of %q: %s", item.Name, err)
+			} else if owner != nil {
+				item.Owner = owner.String()
+			}
+		}
+
+		if err := w.Write(item, machineDocument(item)); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return w.Close()
 }
 
-func parseFormat(format string) (*template.Template, bool, error) {
-	table := false
-	finalFormat := format
-
-	if finalFormat == "" {
-		finalFormat = lsDefaultFormat
-	}
-
-	if strings.HasPrefix(finalFormat, tableFormatKey) {
-		table = true
-		finalFormat = finalFormat[len(tableFormatKey):]
-	}
-
-	finalFormat = strings.Trim(finalFormat, " ")
-	r := strings.NewReplacer(`\t`, "\t", `\n`, "\n")
-	finalFormat = r.Replace(finalFormat)
-
-	template, err := template.New("").Parse(finalFormat + "\n")
-	if err != nil {
-		return nil, false, err
-	}
-
-	return template, table, nil
+// machineDocument returns the JSON document of the listed machine.
+func machineDocument(item HostListItem) formatter.Machine {
+	document := formatter.Machine{
+		SchemaVersion:      formatter.SchemaVersion,
+		Name:               item.Name,
+		Active:             item.ActiveHost,
+		ActiveSwarm:        item.ActiveSwarm,
+		DriverName:         item.DriverName,
+		State:              item.State.String(),
+		URL:                item.URL,
+		Swarm:              strings.TrimSuffix(item.Swarm, " (master)"),
+		DockerVersion:      item.DockerVersion,
+		Error:              item.Error,
+		ResponseTimeMillis: int64(item.ResponseTime / time.Millisecond),
+		Owner:              item.Owner,
+		Region:             item.Placement.Region,
+		Zone:               item.Placement.Zone,
+		StorageDriver:      item.StorageDriver,
+		EngineOS:           item.EngineOS,
+		Containers:         item.Containers,
+	}
+
+	if item.SwarmOptions != nil {
+		document.SwarmMaster = item.SwarmOptions.Master
+	}
+
+	return document
 }
 
 func parseFilters(filters []string) (FilterOptions, error) {
@@ -336,6 +346,8 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 	url := ""
 	currentState := state.None
 	dockerVersion := "Unknown"
+	storageDriver, engineOS := "", ""
+	var containers int64
 	hostError := ""
 
 	url, err := h.URL()
@@ -366,6 +378,15 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 		} else {
 			dockerVersion = fmt.Sprintf("v%s", dockerVersion)
 		}
+
+		// The engine answered, the details of docker info are best effort
+		if err == nil {
+			if info, infoErr := mcndockerclient.EngineInfo(dockerHost); infoErr != nil {
+				log.Debugf("Unable to get the engine info of %q: %s", h.Name, infoErr)
+			} else {
+				storageDriver, engineOS, containers = info.Driver, info.OperatingSystem, info.Containers
+			}
+		}
 	}
 
 	if err != nil {
@@ -377,9 +398,13 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 
 	var swarmOptions *swarm.Options
 	var engineOptions *engine.Options
+	var placement drivers.Placement
 	if h.HostOptions != nil {
 		swarmOptions = h.HostOptions.SwarmOptions
 		engineOptions = h.HostOptions.EngineOptions
+		if h.HostOptions.Placement != nil {
+			placement = *h.HostOptions.Placement
+		}
 	}
 
 	isMaster := false
@@ -409,7 +434,11 @@ func attemptGetHostState(h *host.Host, stateQueryChan chan<- HostListItem) {
 		URL:           url,
 		SwarmOptions:  swarmOptions,
 		EngineOptions: engineOptions,
+		Placement:     placement,
 		DockerVersion: dockerVersion,
+		StorageDriver: storageDriver,
+		EngineOS:      engineOS,
+		Containers:    containers,
 		Error:         hostError,
 		ResponseTime:  time.Now().Round(time.Millisecond).Sub(requestBeginning.Round(time.Millisecond)),
 	}
@@ -426,15 +455,68 @@ func getHostState(h *host.Host, hostListItemsChan chan<- HostListItem, timeout t
 	// If we get back useful information, great.  Forward it straight to
 	// the original parent channel.
 	case hli := <-stateQueryChan:
-		hostListItemsChan <- hli
+		hostListItemsChan <- withLastKnown(h, hli)
 
 	// Otherwise, give up after a predetermined duration.
 	case <-time.After(timeout):
-		hostListItemsChan <- HostListItem{
+		hostListItemsChan <- withLastKnown(h, HostListItem{
 			Name:         h.Name,
 			DriverName:   h.Driver.DriverName(),
 			State:        state.Timeout,
 			ResponseTime: timeout,
+		})
+	}
+}
+
+// withLastKnown fills the item of a machine which could not be queried with
+// what was last learnt about the machine.
+func withLastKnown(h *host.Host, item HostListItem) HostListItem {
+	if h.LastKnown == nil || h.LastKnown.StateTime.IsZero() {
+		return item
+	}
+	if item.State != state.Timeout && item.Error == "" {
+		return item
+	}
+
+	reason := item.Error
+	if item.State == state.Timeout {
+		reason = "Timeout"
+	}
+
+	item.State = h.LastKnown.State
+	if (item.DockerVersion == "" || item.DockerVersion == "Unknown") && h.LastKnown.EngineVersion != "" {
+		item.DockerVersion = "v" + h.LastKnown.EngineVersion
+	}
+	item.Error = fmt.Sprintf("%s (last known state from %s)", reason, h.LastKnown.StateTime.Format(time.RFC3339))
+
+	return item
+}
+
+// recordLastKnown records in the store what the listing learnt about the
+// machines which could be queried. Only the machines whose state or engine
+// version changed are saved.
+func recordLastKnown(api libmachine.API, hostList []*host.Host, items []HostListItem) {
+	hosts := map[string]*host.Host{}
+	for _, h := range hostList {
+		hosts[h.Name] = h
+	}
+
+	for _, item := range items {
+		h, ok := hosts[item.Name]
+		if !ok || item.State == state.Timeout || item.Error != "" {
+			continue
+		}
+
+		changed := h.RecordState(item.State)
+		if strings.HasPrefix(item.DockerVersion, "v") {
+			changed = h.RecordEngineVersion(strings.TrimPrefix(item.DockerVersion, "v")) || changed
+		}
+
+		if !changed {
+			continue
+		}
+		if err := api.Save(h); err != nil {
+			log.Debugf("Unable to save the last known state of %q: %s", h.Name, err)
 		}
 	}
 }
@@ -11,9 +11,11 @@ import (
 	"github.com/docker/machine/drivers/fakedriver"
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/libmachinetest"
 	"github.com/docker/machine/libmachine/mcndockerclient"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/swarm"
+	"github.com/samalba/dockerclient"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -306,6 +308,8 @@ func TestFilterHostsDifferentFlagsProduceAND(t *testing.T) {
 func TestGetHostListItems(t *testing.T) {
 	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
 	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
+	defer func(inspector mcndockerclient.EngineInspector) { mcndockerclient.CurrentEngineInspector = inspector }(mcndockerclient.CurrentEngineInspector)
+	mcndockerclient.CurrentEngineInspector = &mcndockerclient.FakeEngineInspector{Info: &dockerclient.Info{Driver: "overlay2", OperatingSystem: "Ubuntu 16.04.3 LTS", Containers: 3}}
 
 	// TODO: Ideally this would mockable via interface instead.
 	defer func(host string) { os.Setenv("DOCKER_HOST", host) }(os.Getenv("DOCKER_HOST"))
@@ -354,11 +358,18 @@ func TestGetHostListItems(t *testing.T) {
 		assert.Equal(t, expected[i].version, items[i].DockerVersion)
 		assert.Equal(t, expected[i].error, items[i].Error)
 	}
+
+	assert.Equal(t, "overlay2", items[2].StorageDriver)
+	assert.Equal(t, "Ubuntu 16.04.3 LTS", items[2].EngineOS)
+	assert.Equal(t, int64(3), items[2].Containers)
+	assert.Empty(t, items[1].StorageDriver)
 }
 
 func TestGetHostListItemsEnvDockerHostUnset(t *testing.T) {
 	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
 	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
+	defer func(inspector mcndockerclient.EngineInspector) { mcndockerclient.CurrentEngineInspector = inspector }(mcndockerclient.CurrentEngineInspector)
+	mcndockerclient.CurrentEngineInspector = &mcndockerclient.FakeEngineInspector{Info: &dockerclient.Info{Driver: "overlay2", OperatingSystem: "Ubuntu 16.04.3 LTS", Containers: 3}}
 
 	defer func(host string) { os.Setenv("DOCKER_HOST", host) }(os.Getenv("DOCKER_HOST"))
 	os.Unsetenv("DOCKER_HOST")
@@ -494,9 +505,51 @@ func TestGetHostStateError(t *testing.T) {
 	assert.Nil(t, hostItem.SwarmOptions)
 }
 
+func TestGetHostStateErrorWithLastKnown(t *testing.T) {
+	seen := time.Date(2017, 3, 1, 10, 0, 0, 0, time.UTC)
+	hosts := []*host.Host{
+		{
+			Name: "foo",
+			Driver: &fakedriver.Driver{
+				MockState: state.Error,
+			},
+			LastKnown: &host.LastKnown{
+				State:         state.Running,
+				StateTime:     seen,
+				EngineVersion: "1.9",
+			},
+		},
+	}
+
+	hostItem := getHostListItems(hosts, nil, 10*time.Second)[0]
+
+	assert.Equal(t, state.Running, hostItem.State)
+	assert.Equal(t, "v1.9", hostItem.DockerVersion)
+	assert.Equal(t, "Unable to get ip (last known state from 2017-03-01T10:00:00Z)", hostItem.Error)
+}
+
+func TestRecordLastKnown(t *testing.T) {
+	running := &host.Host{Name: "running"}
+	unreachable := &host.Host{Name: "unreachable"}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{running, unreachable},
+	}
+
+	recordLastKnown(api, api.Hosts, []HostListItem{
+		{Name: "running", State: state.Running, DockerVersion: "v1.9"},
+		{Name: "unreachable", State: state.Error, DockerVersion: "Unknown", Error: "Unable to get ip"},
+	})
+
+	assert.Equal(t, state.Running, running.LastKnown.State)
+	assert.Equal(t, "1.9", running.LastKnown.EngineVersion)
+	assert.Nil(t, unreachable.LastKnown)
+}
+
 func TestGetSomeHostInError(t *testing.T) {
 	defer func(versioner mcndockerclient.DockerVersioner) { mcndockerclient.CurrentDockerVersioner = versioner }(mcndockerclient.CurrentDockerVersioner)
 	mcndockerclient.CurrentDockerVersioner = &mcndockerclient.FakeDockerVersioner{Version: "1.9"}
+	defer func(inspector mcndockerclient.EngineInspector) { mcndockerclient.CurrentEngineInspector = inspector }(mcndockerclient.CurrentEngineInspector)
+	mcndockerclient.CurrentEngineInspector = &mcndockerclient.FakeEngineInspector{Info: &dockerclient.Info{Driver: "overlay2", OperatingSystem: "Ubuntu 16.04.3 LTS", Containers: 3}}
 
 	hosts := []*host.Host{
 		{
@@ -533,3 +586,22 @@ func TestOnErrorWithMultilineComment(t *testing.T) {
 
 	assert.Equal(t, itemInError.Error, "missing parameter: the request must contain the parameter InstanceId	status code: 400")
 }
+
+func TestMachineDocument(t *testing.T) {
+	document := machineDocument(HostListItem{
+		Name:         "master",
+		ActiveHost:   true,
+		DriverName:   "virtualbox",
+		State:        state.Running,
+		SwarmOptions: &swarm.Options{Master: true, Discovery: "token://abc"},
+		Swarm:        "master (master)",
+		ResponseTime: 1500 * time.Millisecond,
+	})
+
+	assert.Equal(t, 1, document.SchemaVersion)
+	assert.True(t, document.Active)
+	assert.Equal(t, "Running", document.State)
+	assert.Equal(t, "master", document.Swarm)
+	assert.True(t, document.SwarmMaster)
+	assert.Equal(t, int64(1500), document.ResponseTimeMillis)
+}
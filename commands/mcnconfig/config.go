@@ -0,0 +1,227 @@
+// Package mcnconfig reads the defaults of the create flags from the
+// config.toml file of the store, e.g.
+//
+//	driver = "virtualbox"
+//	tls-san = ["dev.example.com"]
+//
+//	[engine]
+//	storage-driver = "overlay2"
+//	registry-mirror = ["https://mirror.example.com"]
+//
+//	[swarm]
+//	image = "swarm:1.2.8"
+//
+//	[drivers.virtualbox]
+//	memory = 2048
+//	no-share = true
+//
+// Top-level keys are create flag names. The keys of the engine and swarm
+// sections are the names of the engine- and swarm- flags without the
+// prefix, the ones of a drivers section the names of the flags of that
+// driver without the driver name prefix. Only the subset of TOML made of
+// strings, integers, booleans and arrays of strings is supported.
+package mcnconfig
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const driversSectionPrefix = "drivers."
+
+// Config holds the default values of the create flags.
+type Config struct {
+	flags       map[string]interface{}
+	driverFlags map[string]map[string]interface{}
+}
+
+// Load reads the configuration file at the given path. A missing file is an
+// empty configuration.
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Parse("")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return config, nil
+}
+
+// Parse parses the content of a configuration file.
+func Parse(data string) (*Config, error) {
+	config := &Config{
+		flags:       map[string]interface{}{},
+		driverFlags: map[string]map[string]interface{}{},
+	}
+
+	section := ""
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %q", i+1, line)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if section != "engine" && section != "swarm" && !strings.HasPrefix(section, driversSectionPrefix) {
+				return nil, fmt.Errorf("line %d: unknown section %q", i+1, section)
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("line %d: expected key = value", i+1)
+		}
+
+		key := strings.Trim(strings.TrimSpace(parts[0]), `"`)
+		value, err := parseValue(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+
+		switch {
+		case section == "":
+			config.flags[key] = value
+		case strings.HasPrefix(section, driversSectionPrefix):
+			driverName := strings.TrimPrefix(section, driversSectionPrefix)
+			if config.driverFlags[driverName] == nil {
+				config.driverFlags[driverName] = map[string]interface{}{}
+			}
+			config.driverFlags[driverName][driverName+"-"+key] = value
+		default:
+			config.flags[section+"-"+key] = value
+		}
+	}
+
+	return config, nil
+}
+
+// Driver returns the default driver, if any.
+func (c *Config) Driver() string {
+	driverName, _ := c.flags["driver"].(string)
+	return driverName
+}
+
+// Flags returns the default values of the create flags, by flag name, for
+// machines created with the given driver.
+func (c *Config) Flags(driverName string) map[string]interface{} {
+	flags := map[string]interface{}{}
+	for name, value := range c.flags {
+		flags[name] = value
+	}
+	for name, value := range c.driverFlags[driverName] {
+		flags[name] = value
+	}
+	return flags
+}
+
+// stripComment removes the comment ending the line, if any.
+func stripComment(line string) string {
+	inString := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inString != 0 && c == '\\' && inString == '"':
+			i++
+		case inString != 0 && c == inString:
+			inString = 0
+		case inString == 0 && (c == '"' || c == '\''):
+			inString = c
+		case inString == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func parseValue(raw string) (interface{}, error) {
+	switch {
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case strings.HasPrefix(raw, "["):
+		return parseArray(raw)
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		return parseString(raw)
+	}
+
+	value, err := strconv.Atoi(strings.Replace(raw, "_", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %s", raw)
+	}
+	return value, nil
+}
+
+func parseString(raw string) (string, error) {
+	if len(raw) < 2 || raw[len(raw)-1] != raw[0] {
+		return "", fmt.Errorf("unterminated string %s", raw)
+	}
+
+	if raw[0] == '\'' {
+		return raw[1 : len(raw)-1], nil
+	}
+
+	value, err := strconv.Unquote(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", raw)
+	}
+	return value, nil
+}
+
+func parseArray(raw string) ([]string, error) {
+	if !strings.HasSuffix(raw, "]") {
+		return nil, fmt.Errorf("unterminated array %s", raw)
+	}
+
+	values := []string{}
+	rest := strings.TrimSpace(raw[1 : len(raw)-1])
+	for rest != "" {
+		end := closingQuote(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("arrays may only hold strings: %s", raw)
+		}
+
+		value, err := parseString(rest[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		rest = strings.TrimSpace(rest[end+1:])
+		rest = strings.TrimSpace(strings.TrimPrefix(rest, ","))
+	}
+
+	return values, nil
+}
+
+// closingQuote returns the index of the quote closing the string starting
+// the value, or -1.
+func closingQuote(value string) int {
+	if value == "" || (value[0] != '"' && value[0] != '\'') {
+		return -1
+	}
+
+	for i := 1; i < len(value); i++ {
+		switch {
+		case value[0] == '"' && value[i] == '\\':
+			i++
+		case value[i] == value[0]:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,68 @@
+package mcnconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `# Defaults of docker-machine create
+driver = "virtualbox"
+tls-san = ["dev.example.com", 'dev']
+
+[engine]
+storage-driver = "overlay2" # the default of recent engines
+label = ["env=#dev"]
+
+[swarm]
+image = 'swarm:1.2.8'
+
+[drivers.virtualbox]
+memory = 2_048
+no-share = true
+
+[drivers.amazonec2]
+region = "eu-west-1"
+`
+
+func TestParse(t *testing.T) {
+	config, err := Parse(testConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "virtualbox", config.Driver())
+	assert.Equal(t, map[string]interface{}{
+		"driver":                "virtualbox",
+		"tls-san":               []string{"dev.example.com", "dev"},
+		"engine-storage-driver": "overlay2",
+		"engine-label":          []string{"env=#dev"},
+		"swarm-image":           "swarm:1.2.8",
+		"virtualbox-memory":     2048,
+		"virtualbox-no-share":   true,
+	}, config.Flags("virtualbox"))
+	assert.Equal(t, "eu-west-1", config.Flags("amazonec2")["amazonec2-region"])
+	assert.NotContains(t, config.Flags("amazonec2"), "virtualbox-memory")
+}
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		"driver",
+		"[network]",
+		"[engine",
+		`driver = "virtualbox`,
+		"memory = 2GB",
+		"label = [1, 2]",
+	}
+
+	for _, data := range invalid {
+		_, err := Parse(data)
+		assert.Error(t, err, data)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	config, err := Load("/nonexistent/config.toml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", config.Driver())
+	assert.Empty(t, config.Flags("virtualbox"))
+}
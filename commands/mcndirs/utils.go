@@ -25,3 +25,15 @@ func GetMachineDir() string {
 func GetMachineCertDir() string {
 	return filepath.Join(GetBaseDir(), "certs")
 }
+
+// GetAssetCacheDir returns the directory of the cached install scripts,
+// package bundles and ISOs, see the assets package.
+func GetAssetCacheDir() string {
+	return filepath.Join(GetBaseDir(), "cache", "assets")
+}
+
+// GetConfigFilePath returns the path of the file holding the defaults of the
+// create flags, see the mcnconfig package.
+func GetConfigFilePath() string {
+	return filepath.Join(GetBaseDir(), "config.toml")
+}
@@ -128,6 +128,8 @@ func getInfoForSshfsArg(hostAndPath string, hostInfoLoader HostInfoLoader) (h Ho
 		args = append(args, "-o", fmt.Sprintf("IdentityFile=%s", h.GetSSHKeyPath()))
 	}
 
+	args = append(args, bastionArgs(h)...)
+
 	if user == "" {
 		user = h.GetSSHUsername()
 	}
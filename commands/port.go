@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+var errExpectedMachineAndPorts = errors.New("Error: Expected a machine name and one or more ports as arguments")
+
+func cmdPortOpen(c CommandLine, api libmachine.API) error {
+	return updatePorts(c, api, "opened", (*host.Host).OpenPort)
+}
+
+func cmdPortClose(c CommandLine, api libmachine.API) error {
+	return updatePorts(c, api, "closed", (*host.Host).ClosePort)
+}
+
+// updatePorts applies the update to every port given after the machine name
+// and saves the machine, even when one of the updates failed for the ports
+// updated before to be remembered.
+func updatePorts(c CommandLine, api libmachine.API, verb string, update func(*host.Host, drivers.FirewallRule) error) error {
+	if len(c.Args()) < 2 {
+		c.ShowHelp()
+		return errExpectedMachineAndPorts
+	}
+
+	rules := []drivers.FirewallRule{}
+	for _, port := range c.Args()[1:] {
+		rule, err := drivers.ParseFirewallRule(port)
+		if err != nil {
+			return err
+		}
+		rules = append(rules, rule)
+	}
+
+	name := c.Args().First()
+	h, err := api.Load(name)
+	if err != nil {
+		return err
+	}
+
+	var updateErr error
+	for _, rule := range rules {
+		if updateErr = update(h, rule); updateErr != nil {
+			break
+		}
+		log.Infof("Port %s of %s %s", rule, name, verb)
+	}
+
+	libmachine.RecordOperationDetails(api, name, persist.AuditConfigChange, fmt.Sprintf("ports %s", verb), updateErr)
+
+	if err := api.Save(h); err != nil {
+		return err
+	}
+
+	return updateErr
+}
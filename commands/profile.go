@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/secrets"
+)
+
+var (
+	errNoProfileStore = errors.New("The store does not keep profiles")
+	errNoProfileName  = errors.New("Error: No profile name specified")
+
+	// profileFlags are the create flags never saved in a profile.
+	profileFlags = map[string]bool{
+		"profile":      true,
+		"save-profile": true,
+	}
+)
+
+// profileCommandLine takes the value of the flags not set on the command line
+// from a profile.
+type profileCommandLine struct {
+	CommandLine
+	profile *persist.Profile
+}
+
+func (c *profileCommandLine) profileValue(name string) (interface{}, bool) {
+	if c.CommandLine.IsSet(name) {
+		return nil, false
+	}
+
+	value, ok := c.profile.Flags[name]
+	return value, ok
+}
+
+func (c *profileCommandLine) IsSet(name string) bool {
+	_, ok := c.profileValue(name)
+	return ok || c.CommandLine.IsSet(name)
+}
+
+func (c *profileCommandLine) Bool(name string) bool {
+	if value, ok := c.profileValue(name); ok {
+		if b, ok := value.(bool); ok {
+			return b
+		}
+	}
+	return c.CommandLine.Bool(name)
+}
+
+func (c *profileCommandLine) Int(name string) int {
+	if value, ok := c.profileValue(name); ok {
+		if i, ok := value.(int); ok {
+			return i
+		}
+	}
+	return c.CommandLine.Int(name)
+}
+
+func (c *profileCommandLine) String(name string) string {
+	if value, ok := c.profileValue(name); ok {
+		if s, ok := value.(string); ok {
+			return s
+		}
+	}
+	return c.CommandLine.String(name)
+}
+
+func (c *profileCommandLine) StringSlice(name string) []string {
+	if value, ok := c.profileValue(name); ok {
+		if values, ok := value.([]string); ok {
+			return values
+		}
+	}
+	return c.CommandLine.StringSlice(name)
+}
+
+func (c *profileCommandLine) Generic(name string) interface{} {
+	if value, ok := c.profileValue(name); ok {
+		return &profileFlagValue{value}
+	}
+	return c.CommandLine.Generic(name)
+}
+
+// profileFlagValue is the flag.Getter of a flag taken from a profile.
+type profileFlagValue struct {
+	value interface{}
+}
+
+func (v *profileFlagValue) Get() interface{} {
+	return v.value
+}
+
+func (v *profileFlagValue) String() string {
+	return fmt.Sprint(v.value)
+}
+
+func (v *profileFlagValue) Set(string) error {
+	return errors.New("flags of a profile are read-only")
+}
+
+func getProfileStore(api libmachine.API) (persist.ProfileStore, error) {
+	profiles, ok := api.(persist.ProfileStore)
+	if !ok {
+		return nil, errNoProfileStore
+	}
+	return profiles, nil
+}
+
+// withProfile returns the command line taking the flags it does not set
+// from the profile given with --profile, if any.
+func withProfile(c CommandLine, api libmachine.API) (CommandLine, error) {
+	name := c.String("profile")
+	if name == "" {
+		return c, nil
+	}
+
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := profiles.LoadProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &profileCommandLine{c, profile}, nil
+}
+
+// profileDriver returns the driver of the profile given on the command line,
+// before the create flags of the driver are known.
+func profileDriver(api libmachine.API) string {
+	name := flagHackLookup("--profile")
+	if name == "" {
+		return ""
+	}
+
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return ""
+	}
+
+	profile, err := profiles.LoadProfile(name)
+	if err != nil {
+		log.Debugf("Unable to load profile %q: %s", name, err)
+		return ""
+	}
+
+	driverName, _ := profile.Flags["driver"].(string)
+	return driverName
+}
+
+// profileFromFlags returns a profile of the flags set on the command line, or
+// taken from a profile, and of the driver.
+func profileFromFlags(c CommandLine, name string) *persist.Profile {
+	// The driver is saved even when it is the default one
+	flags := map[string]interface{}{
+		"driver": c.String("driver"),
+	}
+
+	for _, flagName := range c.FlagNames() {
+		if flagName == "driver" || !c.IsSet(flagName) || profileFlags[flagName] {
+			continue
+		}
+
+		if getter, ok := c.Generic(flagName).(flag.Getter); ok {
+			flags[flagName] = getter.Get()
+		} else {
+			flags[flagName] = c.StringSlice(flagName)
+		}
+	}
+
+	return &persist.Profile{
+		Name:  name,
+		Flags: flags,
+	}
+}
+
+func saveProfile(c CommandLine, api libmachine.API, name string) error {
+	if !host.ValidateHostName(name) {
+		return fmt.Errorf("Invalid profile name %q", name)
+	}
+
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return err
+	}
+
+	if err := profiles.SaveProfile(profileFromFlags(c, name)); err != nil {
+		return fmt.Errorf("Error saving profile %q: %s", name, err)
+	}
+
+	log.Infof("Saved the flags as profile %q", name)
+	return nil
+}
+
+func cmdProfileLs(c CommandLine, api libmachine.API) error {
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return err
+	}
+
+	names, err := profiles.ListProfiles()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func cmdProfileInspect(c CommandLine, api libmachine.API) error {
+	name := c.Args().First()
+	if name == "" {
+		return errNoProfileName
+	}
+
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return err
+	}
+
+	profile, err := profiles.LoadProfile(name)
+	if err != nil {
+		return err
+	}
+
+	flags := profile.Flags
+	if !c.Bool("show-secrets") {
+		flags = redactProfileFlags(flags)
+	}
+
+	prettyJSON, err := json.MarshalIndent(flags, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(prettyJSON))
+	return nil
+}
+
+func cmdProfileRm(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) == 0 {
+		return errNoProfileName
+	}
+
+	profiles, err := getProfileStore(api)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.Args() {
+		if err := profiles.RemoveProfile(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redactProfileFlags returns the flags of a profile with the values of the
+// ones setting secrets, e.g. digitalocean-access-token, redacted.
+func redactProfileFlags(flags map[string]interface{}) map[string]interface{} {
+	redacted := map[string]interface{}{}
+	for name, value := range flags {
+		if s, ok := value.(string); ok && s != "" && secrets.IsSensitiveFlag(name) {
+			value = secrets.Redacted
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
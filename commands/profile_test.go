@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/docker/machine/commands/commandstest"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileCommandLine(t *testing.T) {
+	c := &profileCommandLine{
+		CommandLine: &commandstest.FakeCommandLine{
+			LocalFlags: &commandstest.FakeFlagger{
+				Data: map[string]interface{}{
+					"virtualbox-memory": 2048,
+				},
+			},
+		},
+		profile: &persist.Profile{
+			Name: "dev",
+			Flags: map[string]interface{}{
+				"driver":            "virtualbox",
+				"virtualbox-memory": 4096,
+				"engine-label":      []string{"env=dev"},
+				"swarm":             true,
+			},
+		},
+	}
+
+	// The command line wins over the profile
+	assert.Equal(t, 2048, c.Int("virtualbox-memory"))
+
+	assert.Equal(t, "virtualbox", c.String("driver"))
+	assert.Equal(t, []string{"env=dev"}, c.StringSlice("engine-label"))
+	assert.True(t, c.Bool("swarm"))
+	assert.True(t, c.IsSet("swarm"))
+	assert.False(t, c.IsSet("swarm-master"))
+	assert.Equal(t, "", c.String("swarm-discovery"))
+}
+
+func TestProfileFromFlags(t *testing.T) {
+	c := &commandstest.FakeCommandLine{
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"driver":       "generic",
+				"engine-label": []string{"env=dev"},
+				"save-profile": "dev",
+			},
+		},
+	}
+
+	profile := profileFromFlags(c, "dev")
+
+	assert.Equal(t, "dev", profile.Name)
+	assert.Equal(t, map[string]interface{}{
+		"driver":       "generic",
+		"engine-label": []string{"env=dev"},
+	}, profile.Flags)
+}
@@ -0,0 +1,16 @@
+package commands
+
+import (
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+func cmdProtect(c CommandLine, api libmachine.API) error {
+	protected := !c.Bool("off")
+
+	return runHostAction(persist.AuditConfigChange, func(h *host.Host) error {
+		h.SetProtected(protected)
+		return nil
+	}, c, api)
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/libmachinetest"
 	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/stretchr/testify/assert"
 )
@@ -29,7 +30,7 @@ func TestCmdProvision(t *testing.T) {
 				Hosts: []*host.Host{
 					{
 						Name:   "foo",
-						Driver: &fakedriver.Driver{},
+						Driver: &fakedriver.Driver{MockState: state.Running},
 						HostOptions: &host.Options{
 							EngineOptions: &engine.Options{},
 							AuthOptions:   &auth.Options{},
@@ -38,7 +39,7 @@ func TestCmdProvision(t *testing.T) {
 					},
 					{
 						Name:   "bar",
-						Driver: &fakedriver.Driver{},
+						Driver: &fakedriver.Driver{MockState: state.Running},
 						HostOptions: &host.Options{
 							EngineOptions: &engine.Options{},
 							AuthOptions:   &auth.Options{},
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+func cmdPrune(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	remove := c.Bool("remove")
+	if remove && !c.Bool("y") {
+		ok, err := confirmInput("Remove from the store the machines the instance of which no longer exists?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	results, err := libmachine.Prune(api, remove)
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("Error pruning %s: %s", result.Name, result.Err))
+			continue
+		}
+
+		if result.Removed {
+			log.Infof("Removed %s, its instance no longer exists", result.Name)
+		} else {
+			log.Infof("The instance of %s no longer exists, run \"docker-machine rm %s\" to remove it", result.Name, result.Name)
+		}
+	}
+
+	if len(errs) > 0 {
+		return consolidateErrs(errs)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package commands
+
+import (
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+func cmdRegenerateSSHKey(c CommandLine, api libmachine.API) error {
+	return runHostAction(persist.AuditSSHKeyRotate, func(h *host.Host) error {
+		return h.RegenerateSSHKey()
+	}, c, api)
+}
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+var errExpectedOneArchive = errors.New("Error: Expected one archive path as an argument")
+
+func cmdDeregister(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) != 1 {
+		c.ShowHelp()
+		return ErrExpectedOneMachine
+	}
+
+	name := c.Args().First()
+	archivePath := c.String("archive")
+
+	if archivePath == "" && !c.Bool("y") {
+		ok, err := confirmInput(fmt.Sprintf("Remove %s from the store without an archive to register it back?", name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	var archive io.Writer
+	if archivePath != "" {
+		file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		archive = file
+	}
+
+	if err := libmachine.Deregister(api, name, archive); err != nil {
+		if archivePath != "" {
+			os.Remove(archivePath)
+		}
+		return err
+	}
+
+	if err := ssh.RemoveHostKey(libmachine.KnownHostsOf(api, name)); err != nil {
+		log.Warnf("Error removing the host key of %s: %s", name, err)
+	}
+
+	if archivePath != "" {
+		log.Infof("Deregistered %s, its instance was kept. Run \"docker-machine register %s\" to register it again", name, archivePath)
+	} else {
+		log.Infof("Deregistered %s, its instance was kept", name)
+	}
+
+	return nil
+}
+
+func cmdRegister(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) != 1 {
+		c.ShowHelp()
+		return errExpectedOneArchive
+	}
+
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	h, err := libmachine.Register(api, file)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Registered %s", h.Name)
+	return nil
+}
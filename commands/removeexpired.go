@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const defaultExpiryCheckInterval = 60
+
+func cmdRemoveExpired(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	if !c.Bool("watch") {
+		removed, err := libmachine.RemoveExpiredMachines(api)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range removed {
+			log.Infof("Removed expired machine %s", name)
+		}
+		return nil
+	}
+
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = defaultExpiryCheckInterval * time.Second
+	}
+
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(done)
+	}()
+
+	log.Infof("Removing machines older than their TTL, checking every %s...", interval)
+	libmachine.WatchExpiredMachines(api, interval, done)
+
+	return nil
+}
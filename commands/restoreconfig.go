@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+func cmdRestoreConfig(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) != 1 {
+		return ErrExpectedOneMachine
+	}
+
+	restorer, ok := api.(host.ConfigRestorer)
+	if !ok {
+		return errors.New("The store does not keep backups of the machine configurations")
+	}
+
+	name := c.Args().First()
+	h := &host.Host{Name: name}
+	err := h.RestoreConfigBackup(restorer)
+	libmachine.RecordOperationDetails(api, name, persist.AuditConfigChange, "restored from backup", err)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Restored the configuration of %s from its last backup", name)
+	return nil
+}
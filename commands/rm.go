@@ -9,6 +9,7 @@ import (
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
 )
 
 func cmdRm(c CommandLine, api libmachine.API) error {
@@ -22,20 +23,27 @@ func cmdRm(c CommandLine, api libmachine.API) error {
 
 	force := c.Bool("force")
 	confirm := c.Bool("y")
-	var errorOccurred []string
+	overrideProtection := c.Bool("override-protection")
+	var errorOccurred, refused []string
 
 	if !userConfirm(confirm, force) {
 		return nil
 	}
 
 	for _, hostName := range c.Args() {
-		err := removeRemoteMachine(hostName, api)
+		err := libmachine.RemoveInstance(api, hostName, overrideProtection)
+		if _, ok := err.(mcnerror.ErrHostProtected); ok {
+			// --force removes the local configuration of the machines
+			// which cannot be removed, not of the protected ones
+			refused = append(refused, err.Error())
+			continue
+		}
 		if err != nil {
 			errorOccurred = collectError(fmt.Sprintf("Error removing host %q: %s", hostName, err), force, errorOccurred)
 		}
 
 		if err == nil || force {
-			removeErr := removeLocalMachine(hostName, api)
+			removeErr := libmachine.RemoveMachine(api, hostName)
 			if removeErr != nil {
 				errorOccurred = collectError(fmt.Sprintf("Can't remove \"%s\"", hostName), force, errorOccurred)
 			} else {
@@ -44,8 +52,11 @@ func cmdRm(c CommandLine, api libmachine.API) error {
 		}
 	}
 
-	if len(errorOccurred) > 0 && !force {
-		return errors.New(strings.Join(errorOccurred, "\n"))
+	if !force {
+		refused = append(refused, errorOccurred...)
+	}
+	if len(refused) > 0 {
+		return errors.New(strings.Join(refused, "\n"))
 	}
 
 	return nil
@@ -64,23 +75,6 @@ func userConfirm(confirm bool, force bool) bool {
 	return sure
 }
 
-func removeRemoteMachine(hostName string, api libmachine.API) error {
-	currentHost, loaderr := api.Load(hostName)
-	if loaderr != nil {
-		return loaderr
-	}
-
-	return currentHost.Driver.Remove()
-}
-
-func removeLocalMachine(hostName string, api libmachine.API) error {
-	exist, _ := api.Exists(hostName)
-	if !exist {
-		return errors.New(hostName + " does not exist.")
-	}
-	return api.Remove(hostName)
-}
-
 func collectError(message string, force bool, errorOccurred []string) []string {
 	if force {
 		log.Error(message)
@@ -9,6 +9,7 @@ import (
 	"github.com/docker/machine/drivers/fakedriver"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/libmachinetest"
+	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -231,3 +232,60 @@ func TestDontRemoveMachineIsRemovalFailsAndNotForced(t *testing.T) {
 
 	assert.True(t, libmachinetest.Exists(api, "machineToRemove1"))
 }
+
+func TestCmdRmProtected(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"shared", "machineToRemove"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"y":     true,
+				"force": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:        "shared",
+				Driver:      &fakedriver.Driver{},
+				HostOptions: &host.Options{Protected: true},
+			},
+			{
+				Name:   "machineToRemove",
+				Driver: &fakedriver.Driver{},
+			},
+		},
+	}
+
+	err := cmdRm(commandLine, api)
+	assert.EqualError(t, err, mcnerror.ErrHostProtected{Name: "shared"}.Error())
+
+	assert.True(t, libmachinetest.Exists(api, "shared"))
+	assert.False(t, libmachinetest.Exists(api, "machineToRemove"))
+}
+
+func TestCmdRmOverrideProtection(t *testing.T) {
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"shared"},
+		LocalFlags: &commandstest.FakeFlagger{
+			Data: map[string]interface{}{
+				"y":                   true,
+				"override-protection": true,
+			},
+		},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name:        "shared",
+				Driver:      &fakedriver.Driver{},
+				HostOptions: &host.Options{Protected: true},
+			},
+		},
+	}
+
+	err := cmdRm(commandLine, api)
+	assert.NoError(t, err)
+
+	assert.False(t, libmachinetest.Exists(api, "shared"))
+}
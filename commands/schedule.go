@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/schedule"
+	"github.com/docker/machine/libmachine/state"
+)
+
+const defaultScheduleCheckInterval = 60
+
+var errNoSchedule = errors.New("Error: Expected --start, --stop or --clear")
+
+func cmdSchedule(c CommandLine, api libmachine.API) error {
+	newSchedule, err := scheduleFromFlags(c, "start", "stop")
+	if err != nil {
+		return err
+	}
+
+	clear := c.Bool("clear")
+	if newSchedule == nil && !clear {
+		return errNoSchedule
+	}
+
+	return runHostAction(persist.AuditConfigChange, func(h *host.Host) error {
+		if clear {
+			h.HostOptions.Schedule = nil
+		} else {
+			h.HostOptions.Schedule = newSchedule
+		}
+		return nil
+	}, c, api)
+}
+
+func cmdRunSchedules(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = defaultScheduleCheckInterval * time.Second
+	}
+
+	if !c.Bool("watch") {
+		now := time.Now()
+		results, err := libmachine.ApplySchedules(api, now.Add(-interval), now)
+		if err != nil {
+			return err
+		}
+
+		reportSchedules(results)
+		return nil
+	}
+
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(done)
+	}()
+
+	log.Infof("Starting and stopping machines on their schedule, checking every %s...", interval)
+	libmachine.WatchSchedules(api, interval, done, reportSchedules)
+
+	return nil
+}
+
+func reportSchedules(results []libmachine.ScheduleResult) {
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			log.Errorf("Error applying the schedule of %s: %s", result.Name, result.Err)
+		case result.State == state.Running:
+			log.Infof("Started %s on schedule", result.Name)
+		default:
+			log.Infof("Stopped %s on schedule", result.Name)
+		}
+	}
+}
+
+// scheduleFromFlags returns the schedule set by the flags of the start and
+// stop expressions, or nil if neither is set.
+func scheduleFromFlags(c CommandLine, startFlag, stopFlag string) (*schedule.Schedule, error) {
+	s := &schedule.Schedule{
+		Start: c.String(startFlag),
+		Stop:  c.String(stopFlag),
+	}
+	if s.IsEmpty() {
+		return nil, nil
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/persist"
 )
@@ -125,6 +126,12 @@ func getScpCmd(src, dest string, recursive bool, delta bool, hostInfoLoader Host
 	}
 
 	if delta {
+		// rsync splits the command on spaces, except within quotes
+		for i, arg := range sshArgs {
+			if strings.HasPrefix(arg, "ProxyCommand=") {
+				sshArgs[i] = fmt.Sprintf("%q", arg)
+			}
+		}
 		sshArgs = append([]string{"-e"}, "ssh "+strings.Join(sshArgs, " "))
 		if recursive {
 			sshArgs = append(sshArgs, "-r")
@@ -180,9 +187,27 @@ func getInfoForScpArg(hostAndPath string, hostInfoLoader HostInfoLoader) (h Host
 		args = append(args, "-o", fmt.Sprintf("IdentityFile=%q", h.GetSSHKeyPath()))
 	}
 
+	args = append(args, bastionArgs(h)...)
+
 	return
 }
 
+// bastionArgs returns the options of ssh reaching the machine through its
+// bastion, if it has one.
+func bastionArgs(h HostInfo) []string {
+	d, ok := h.(drivers.Driver)
+	if !ok {
+		return nil
+	}
+
+	bastion := drivers.ResolveSSHBastion(d)
+	if bastion == nil {
+		return nil
+	}
+
+	return []string{"-o", "ProxyCommand=" + bastion.ProxyCommand(drivers.KnownHostsOf(d).File)}
+}
+
 func generateLocationArg(hostInfo HostInfo, user, path string) (string, error) {
 	if hostInfo == nil {
 		return path, nil
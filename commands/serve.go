@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/docker/machine/commands/mcndirs"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/credentials"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/secrets"
+	"github.com/docker/machine/libmachine/server"
+)
+
+const (
+	serveDefaultAddr = "127.0.0.1:2380"
+)
+
+func cmdServe(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	addr := c.String("addr")
+	listenHost, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("Invalid listen address %q: %s", addr, err)
+	}
+
+	authOptions := &auth.Options{
+		CertDir:          mcndirs.GetMachineCertDir(),
+		CaCertPath:       tlsPath(c, "tls-ca-cert", "ca.pem"),
+		CaPrivateKeyPath: tlsPath(c, "tls-ca-key", "ca-key.pem"),
+		ClientCertPath:   tlsPath(c, "tls-client-cert", "cert.pem"),
+		ClientKeyPath:    tlsPath(c, "tls-client-key", "key.pem"),
+	}
+
+	if err := cert.BootstrapCertificates(authOptions); err != nil {
+		return fmt.Errorf("Error generating certificates: %s", err)
+	}
+
+	certPath := filepath.Join(mcndirs.GetMachineCertDir(), "serve.pem")
+	keyPath := filepath.Join(mcndirs.GetMachineCertDir(), "serve-key.pem")
+
+	hosts := c.StringSlice("tls-san")
+	if listenHost != "" {
+		hosts = append(hosts, listenHost)
+	}
+
+	if err := server.EnsureServerCertificate(authOptions, certPath, keyPath, hosts); err != nil {
+		return fmt.Errorf("Error generating the API server certificate: %s", err)
+	}
+
+	clientCerts := server.ClientCertificates{
+		CaCertPath:       filepath.Join(mcndirs.GetMachineCertDir(), "serve-ca.pem"),
+		CaPrivateKeyPath: filepath.Join(mcndirs.GetMachineCertDir(), "serve-ca-key.pem"),
+		CertPath:         filepath.Join(mcndirs.GetMachineCertDir(), "serve-client.pem"),
+		KeyPath:          filepath.Join(mcndirs.GetMachineCertDir(), "serve-client-key.pem"),
+	}
+	if err := server.EnsureClientCertificates(clientCerts); err != nil {
+		return fmt.Errorf("Error generating the API client certificates: %s", err)
+	}
+
+	tlsConfig, err := server.NewTLSConfig(clientCerts.CaCertPath, certPath, keyPath)
+	if err != nil {
+		return fmt.Errorf("Error reading TLS configuration: %s", err)
+	}
+
+	secretBox, err := secrets.DefaultBox()
+	if err != nil {
+		return err
+	}
+
+	useKeyring := secretBox == nil && c.GlobalBool("storage-keyring")
+
+	s := server.NewServer(func() libmachine.API {
+		client := libmachine.NewClient(mcndirs.GetBaseDir(), mcndirs.GetMachineCertDir())
+		client.Filestore.SecretBox = secretBox
+		if useKeyring {
+			client.Filestore.Keyring = credentials.OSKeyring{}
+		}
+		return client
+	})
+
+	log.Infof("Clients authenticate with %s and %s", clientCerts.CertPath, clientCerts.KeyPath)
+
+	return s.ListenAndServeTLS(addr, tlsConfig)
+}
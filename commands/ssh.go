@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 )
 
@@ -42,10 +43,13 @@ func cmdSSH(c CommandLine, api libmachine.API) error {
 		return errStateInvalidForSSH{host.Name}
 	}
 
-	client, err := host.CreateSSHClient()
-	if err != nil {
-		return err
+	err = host.Shell(c.Args().Tail()...)
+
+	// Exit with the status of the remote command, like ssh does
+	if status, ok := ssh.ExitStatus(err); ok {
+		osExit(status)
+		return nil
 	}
 
-	return client.Shell(c.Args().Tail()...)
+	return err
 }
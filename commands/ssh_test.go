@@ -1,6 +1,8 @@
 package commands
 
 import (
+	"os/exec"
+	"runtime"
 	"testing"
 
 	"github.com/docker/machine/commands/commandstest"
@@ -109,3 +111,39 @@ func TestCmdSSH(t *testing.T) {
 		}
 	}
 }
+
+func TestCmdSSHExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on windows")
+	}
+
+	defer func(saved func(int)) { osExit = saved }(osExit)
+	exitCode := 0
+	osExit = func(code int) { exitCode = code }
+
+	defer host.SetSSHClientCreator(nil)
+	host.SetSSHClientCreator(&FakeSSHClientCreator{
+		client: &sshtest.FakeClient{
+			ShellErr: exec.Command("sh", "-c", "exit 2").Run(),
+		},
+	})
+
+	commandLine := &commandstest.FakeCommandLine{
+		CliArgs: []string{"default", "false"},
+	}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{
+			{
+				Name: "default",
+				Driver: &fakedriver.Driver{
+					MockState: state.Running,
+				},
+			},
+		},
+	}
+
+	err := cmdSSH(commandLine, api)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, exitCode)
+}
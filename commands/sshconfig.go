@@ -0,0 +1,28 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+)
+
+func cmdSSHConfig(c CommandLine, api libmachine.API) error {
+	hosts, err := loadActionHosts(c, api)
+	if err != nil {
+		return err
+	}
+
+	for i, h := range hosts {
+		entry, err := h.SSHConfigEntry()
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(entry)
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
 )
 
 func cmdStatus(c CommandLine, api libmachine.API) error {
@@ -26,6 +27,23 @@ func cmdStatus(c CommandLine, api libmachine.API) error {
 		return fmt.Errorf("error getting state for host %s: %s", host.Name, err)
 	}
 
+	if currentState == state.Stopped {
+		preempted, err := host.CheckPreemption()
+		if err != nil {
+			return err
+		}
+
+		if preempted && host.HostOptions.RecreateOnPreemption {
+			if err := api.Save(host); err != nil {
+				return fmt.Errorf("Error saving host to store: %s", err)
+			}
+
+			if currentState, err = host.Driver.GetState(); err != nil {
+				return fmt.Errorf("error getting state for host %s: %s", host.Name, err)
+			}
+		}
+	}
+
 	log.Info(currentState)
 
 	return nil
@@ -1,7 +1,24 @@
 package commands
 
-import "github.com/docker/machine/libmachine"
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
 
 func cmdStop(c CommandLine, api libmachine.API) error {
-	return runAction("stop", c, api)
+	if !c.Bool("graceful") && c.Int("timeout") == 0 {
+		return runAction("stop", c, api)
+	}
+
+	opts := host.StopOptions{
+		Graceful: c.Bool("graceful"),
+		Timeout:  time.Duration(c.Int("timeout")) * time.Second,
+	}
+
+	return runHostAction(persist.AuditStop, func(h *host.Host) error {
+		return h.StopWithOptions(opts)
+	}, c, api)
 }
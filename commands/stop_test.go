@@ -90,6 +90,30 @@ func TestCmdStop(t *testing.T) {
 				"machine":        state.Running,
 			},
 		},
+		{
+			commandLine: &commandstest.FakeCommandLine{
+				CliArgs: []string{"machineToStop"},
+				LocalFlags: &commandstest.FakeFlagger{
+					Data: map[string]interface{}{
+						"timeout": 30,
+					},
+				},
+			},
+			api: &libmachinetest.FakeAPI{
+				Hosts: []*host.Host{
+					{
+						Name: "machineToStop",
+						Driver: &fakedriver.Driver{
+							MockState: state.Running,
+						},
+					},
+				},
+			},
+			expectedErr: nil,
+			expectedStates: map[string]state.State{
+				"machineToStop": state.Stopped,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
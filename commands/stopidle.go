@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const defaultIdleCheckInterval = 300
+
+func cmdStopIdle(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	if !c.Bool("watch") {
+		stopped, err := libmachine.StopIdleMachines(api)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range stopped {
+			log.Infof("Stopped idle machine %s", name)
+		}
+		return nil
+	}
+
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = defaultIdleCheckInterval * time.Second
+	}
+
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(done)
+	}()
+
+	log.Infof("Stopping machines idle for longer than their idle timeout, checking every %s...", interval)
+	libmachine.WatchIdleMachines(api, interval, done)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+)
+
+func cmdSync(c CommandLine, api libmachine.API) error {
+	args := c.Args()
+	if len(args) != 3 {
+		c.ShowHelp()
+		return errWrongNumberArguments
+	}
+
+	h, err := api.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := host.SyncOptions{
+		Method:  host.SyncAuto,
+		Exclude: c.StringSlice("exclude"),
+		Delete:  c.Bool("delete"),
+		Watch:   c.Bool("watch"),
+	}
+	if c.Bool("builtin") {
+		opts.Method = host.SyncBuiltin
+	}
+
+	if opts.Watch {
+		// Stop watching on Ctrl-C
+		interrupt := make(chan os.Signal, 1)
+		signal.Notify(interrupt, os.Interrupt)
+		defer signal.Stop(interrupt)
+
+		stop := make(chan struct{})
+		go func() {
+			<-interrupt
+			close(stop)
+		}()
+		opts.Stop = stop
+	}
+
+	return h.Sync(args[1], args[2], opts)
+}
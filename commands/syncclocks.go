@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const defaultClockCheckInterval = 60
+
+func cmdSyncClocks(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) > 0 {
+		return ErrTooManyArguments
+	}
+
+	if !c.Bool("watch") {
+		repaired, err := libmachine.RepairClockSkews(api)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range repaired {
+			log.Infof("Set the clock of machine %s", name)
+		}
+		return nil
+	}
+
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = defaultClockCheckInterval * time.Second
+	}
+
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(done)
+	}()
+
+	log.Infof("Setting the clocks of the local VMs drifting after the host sleeps, checking every %s...", interval)
+	libmachine.WatchClockSkews(api, interval, done)
+
+	return nil
+}
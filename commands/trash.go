@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/docker/machine/commands/formatter"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+const trashDefaultFormat = "table {{ .Name }}\t{{ .Removed }}\t{{ .Expires }}"
+
+var (
+	errNoTrash = errors.New("The store does not keep the removed machines")
+
+	trashHeader = map[string]string{
+		"Name":    "NAME",
+		"Removed": "REMOVED",
+		"Expires": "EXPIRES",
+	}
+)
+
+// trashItem is a removed machine as printed by the templates.
+type trashItem struct {
+	Name    string
+	Removed string
+	Expires string
+}
+
+func getTrash(api libmachine.API) (persist.Trash, error) {
+	trash, ok := api.(persist.Trash)
+	if !ok {
+		return nil, errNoTrash
+	}
+	return trash, nil
+}
+
+func cmdTrashLs(c CommandLine, api libmachine.API) error {
+	trash, err := getTrash(api)
+	if err != nil {
+		return err
+	}
+
+	entries, err := trash.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	format, err := formatter.Parse(c.String("format"), trashDefaultFormat)
+	if err != nil {
+		return err
+	}
+
+	w, err := format.Writer(os.Stdout, trashHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		item := trashItem{
+			Name:    entry.Name,
+			Removed: entry.Removed.Local().Format("2006-01-02 15:04:05"),
+			Expires: entry.Expires.Local().Format("2006-01-02 15:04:05"),
+		}
+		if err := w.Write(item, entry); err != nil {
+			return err
+		}
+	}
+
+	return w.Close()
+}
+
+func cmdTrashRestore(c CommandLine, api libmachine.API) error {
+	if len(c.Args()) == 0 {
+		c.ShowHelp()
+		return ErrNoMachineSpecified
+	}
+
+	trash, err := getTrash(api)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range c.Args() {
+		_, err := trash.Restore(name)
+		libmachine.RecordOperation(api, name, persist.AuditRestore, err)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Restored %s", name)
+	}
+
+	return nil
+}
+
+func cmdTrashPurge(c CommandLine, api libmachine.API) error {
+	trash, err := getTrash(api)
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("y") {
+		ok, err := confirmInput("Delete for good the removed machines, with their certificates and SSH keys?")
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	return trash.PurgeTrash(time.Now())
+}
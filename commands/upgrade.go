@@ -3,5 +3,28 @@ package commands
 import "github.com/docker/machine/libmachine"
 
 func cmdUpgrade(c CommandLine, api libmachine.API) error {
-	return runAction("upgrade", c, api)
+	version := c.String("version")
+	if version == "" {
+		return runAction("upgrade", c, api)
+	}
+
+	if len(c.Args()) > 1 {
+		return ErrExpectedOneMachine
+	}
+
+	target, err := targetHost(c, api)
+	if err != nil {
+		return err
+	}
+
+	h, err := api.Load(target)
+	if err != nil {
+		return err
+	}
+
+	if err := h.UpgradeTo(version, c.Bool("allow-downgrade")); err != nil {
+		return err
+	}
+
+	return api.Save(h)
 }
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const defaultHealthCheckInterval = 60
+
+func cmdWatchdog(c CommandLine, api libmachine.API) error {
+	names := c.Args()
+
+	if !c.Bool("watch") {
+		unhealthy, err := libmachine.CheckMachinesHealth(api, names...)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range unhealthy {
+			log.Infof("The engine of %s was not answering", name)
+		}
+		return nil
+	}
+
+	interval := time.Duration(c.Int("interval")) * time.Second
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval * time.Second
+	}
+
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	go func() {
+		<-signals
+		close(done)
+	}()
+
+	log.Infof("Watching the engines of the machines with a watchdog policy, checking every %s...", interval)
+	libmachine.WatchMachinesHealth(api, interval, done, names...)
+
+	return nil
+}
@@ -10,9 +10,9 @@ import (
 	"io/ioutil"
 	"net"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
@@ -25,6 +25,7 @@ import (
 	"github.com/docker/machine/libmachine/mcnutils"
 	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/throttle"
 )
 
 const (
@@ -42,6 +43,7 @@ const (
 	defaultSSHUser              = "ubuntu"
 	defaultSpotPrice            = "0.50"
 	defaultBlockDurationMinutes = 0
+	defaultSpotRequestTimeout   = 600
 )
 
 const (
@@ -99,6 +101,10 @@ type Driver struct {
 	RequestSpotInstance     bool
 	SpotPrice               string
 	BlockDurationMinutes    int64
+	SpotLaunchGroup         string
+	SpotPersistent          bool
+	SpotRequestTimeout      int
+	SpotInstanceRequestId   string
 	PrivateIPOnly           bool
 	UsePrivateIP            bool
 	UseEbsOptimizedInstance bool
@@ -108,6 +114,10 @@ type Driver struct {
 	Endpoint                string
 	DisableSSL              bool
 	UserDataFile            string
+
+	// ResourceTags are attached to the resources created for the machine,
+	// see SetResourceTags.
+	ResourceTags map[string]string `json:",omitempty"`
 }
 
 type clientFactory interface {
@@ -222,6 +232,19 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage: "AWS spot instance duration in minutes (60, 120, 180, 240, 300, or 360)",
 			Value: defaultBlockDurationMinutes,
 		},
+		mcnflag.StringFlag{
+			Name:  "amazonec2-spot-launch-group",
+			Usage: "AWS spot instance launch group, spot instances of a group are launched and terminated together",
+		},
+		mcnflag.BoolFlag{
+			Name:  "amazonec2-spot-persistent",
+			Usage: "Make the spot instance request persistent rather than one-time, so that an interrupted instance is relaunched",
+		},
+		mcnflag.IntFlag{
+			Name:  "amazonec2-spot-request-timeout",
+			Usage: "Seconds to wait for the spot instance request to be fulfilled",
+			Value: defaultSpotRequestTimeout,
+		},
 		mcnflag.BoolFlag{
 			Name:  "amazonec2-private-address-only",
 			Usage: "Only use a private IP address",
@@ -284,6 +307,7 @@ func NewDriver(hostName, storePath string) *Driver {
 		SecurityGroupNames:   []string{defaultSecurityGroup},
 		SpotPrice:            defaultSpotPrice,
 		BlockDurationMinutes: defaultBlockDurationMinutes,
+		SpotRequestTimeout:   defaultSpotRequestTimeout,
 		BaseDriver: &drivers.BaseDriver{
 			SSHUser:     defaultSSHUser,
 			MachineName: hostName,
@@ -305,6 +329,7 @@ func (d *Driver) buildClient() Ec2Client {
 	config = config.WithLogger(alogger)
 	config = config.WithLogLevel(aws.LogDebugWithHTTPBody)
 	config = config.WithMaxRetries(d.RetryCount)
+	config = config.WithHTTPClient(throttle.NewClient(driverName))
 	if d.Endpoint != "" {
 		config = config.WithEndpoint(d.Endpoint)
 		config = config.WithDisableSSL(d.DisableSSL)
@@ -341,6 +366,9 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.RequestSpotInstance = flags.Bool("amazonec2-request-spot-instance")
 	d.SpotPrice = flags.String("amazonec2-spot-price")
 	d.BlockDurationMinutes = int64(flags.Int("amazonec2-block-duration-minutes"))
+	d.SpotLaunchGroup = flags.String("amazonec2-spot-launch-group")
+	d.SpotPersistent = flags.Bool("amazonec2-spot-persistent")
+	d.SpotRequestTimeout = flags.Int("amazonec2-spot-request-timeout")
 	d.InstanceType = flags.String("amazonec2-instance-type")
 	d.VpcId = flags.String("amazonec2-vpc-id")
 	d.SubnetId = flags.String("amazonec2-subnet-id")
@@ -513,7 +541,11 @@ func (d *Driver) checkPrereqs() error {
 }
 
 func (d *Driver) PreCreateCheck() error {
-	return d.checkPrereqs()
+	if err := d.checkPrereqs(); err != nil {
+		return err
+	}
+
+	return d.checkProvider()
 }
 
 func (d *Driver) instanceIpAvailable() bool {
@@ -616,13 +648,11 @@ func (d *Driver) Create() error {
 				Placement: &ec2.SpotPlacement{
 					AvailabilityZone: &regionZone,
 				},
-				KeyName:           &d.KeyName,
-				InstanceType:      &d.InstanceType,
-				NetworkInterfaces: netSpecs,
-				Monitoring:        &ec2.RunInstancesMonitoringEnabled{Enabled: aws.Bool(d.Monitoring)},
-				IamInstanceProfile: &ec2.IamInstanceProfileSpecification{
-					Name: &d.IamInstanceProfile,
-				},
+				KeyName:             &d.KeyName,
+				InstanceType:        &d.InstanceType,
+				NetworkInterfaces:   netSpecs,
+				Monitoring:          &ec2.RunInstancesMonitoringEnabled{Enabled: aws.Bool(d.Monitoring)},
+				IamInstanceProfile:  d.iamInstanceProfileSpecification(),
 				EbsOptimized:        &d.UseEbsOptimizedInstance,
 				BlockDeviceMappings: []*ec2.BlockDeviceMapping{bdm},
 				UserData:            &userdata,
@@ -634,51 +664,17 @@ func (d *Driver) Create() error {
 			req.BlockDurationMinutes = &d.BlockDurationMinutes
 		}
 
-		spotInstanceRequest, err := d.getClient().RequestSpotInstances(&req)
-		if err != nil {
-			return fmt.Errorf("Error request spot instance: %s", err)
+		if d.SpotLaunchGroup != "" {
+			req.LaunchGroup = &d.SpotLaunchGroup
 		}
-
-		log.Info("Waiting for spot instance...")
-		err = d.getClient().WaitUntilSpotInstanceRequestFulfilled(&ec2.DescribeSpotInstanceRequestsInput{
-			SpotInstanceRequestIds: []*string{spotInstanceRequest.SpotInstanceRequests[0].SpotInstanceRequestId},
-		})
-		if err != nil {
-			return fmt.Errorf("Error fulfilling spot request: %v", err)
-		}
-		log.Info("Created spot instance request %v", *spotInstanceRequest.SpotInstanceRequests[0].SpotInstanceRequestId)
-		// resolve instance id
-		for i := 0; i < 3; i++ {
-			// Even though the waiter succeeded, eventual consistency means we could
-			// get a describe output that does not include this information. Try a
-			// few times just in case
-			var resolvedSpotInstance *ec2.DescribeSpotInstanceRequestsOutput
-			resolvedSpotInstance, err = d.getClient().DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
-				SpotInstanceRequestIds: []*string{spotInstanceRequest.SpotInstanceRequests[0].SpotInstanceRequestId},
-			})
-			if err != nil {
-				// Unexpected; no need to retry
-				return fmt.Errorf("Error describing previously made spot instance request: %v", err)
-			}
-			maybeInstanceId := resolvedSpotInstance.SpotInstanceRequests[0].InstanceId
-			if maybeInstanceId != nil {
-				var instances *ec2.DescribeInstancesOutput
-				instances, err = d.getClient().DescribeInstances(&ec2.DescribeInstancesInput{
-					InstanceIds: []*string{maybeInstanceId},
-				})
-				if err != nil {
-					// Retry if we get an id from spot instance but EC2 doesn't recognize it yet; see above, eventual consistency possible
-					continue
-				}
-				instance = instances.Reservations[0].Instances[0]
-				err = nil
-				break
-			}
-			time.Sleep(5 * time.Second)
+		if d.SpotPersistent {
+			req.Type = aws.String(ec2.SpotInstanceTypePersistent)
 		}
 
+		var err error
+		instance, err = d.requestSpotInstance(&req)
 		if err != nil {
-			return fmt.Errorf("Error resolving spot instance to real instance: %v", err)
+			return err
 		}
 	} else {
 		inst, err := d.getClient().RunInstances(&ec2.RunInstancesInput{
@@ -688,13 +684,11 @@ func (d *Driver) Create() error {
 			Placement: &ec2.Placement{
 				AvailabilityZone: &regionZone,
 			},
-			KeyName:           &d.KeyName,
-			InstanceType:      &d.InstanceType,
-			NetworkInterfaces: netSpecs,
-			Monitoring:        &ec2.RunInstancesMonitoringEnabled{Enabled: aws.Bool(d.Monitoring)},
-			IamInstanceProfile: &ec2.IamInstanceProfileSpecification{
-				Name: &d.IamInstanceProfile,
-			},
+			KeyName:             &d.KeyName,
+			InstanceType:        &d.InstanceType,
+			NetworkInterfaces:   netSpecs,
+			Monitoring:          &ec2.RunInstancesMonitoringEnabled{Enabled: aws.Bool(d.Monitoring)},
+			IamInstanceProfile:  d.iamInstanceProfileSpecification(),
 			EbsOptimized:        &d.UseEbsOptimizedInstance,
 			BlockDeviceMappings: []*ec2.BlockDeviceMapping{bdm},
 			UserData:            &userdata,
@@ -732,6 +726,10 @@ func (d *Driver) Create() error {
 		return fmt.Errorf("Unable to tag instance %s: %s", d.InstanceId, err)
 	}
 
+	if err := d.tagInstanceResources(); err != nil {
+		return fmt.Errorf("Unable to tag the resources of instance %s: %s", d.InstanceId, err)
+	}
+
 	return nil
 }
 
@@ -853,8 +851,19 @@ func (d *Driver) Remove() error {
 		Errs: []error{},
 	}
 
+	if err := d.checkInstanceTags(); err != nil {
+		return err
+	}
+
+	// A persistent spot request would relaunch the terminated instance
+	if err := d.cancelSpotInstanceRequest(); err != nil {
+		multierr.Errs = append(multierr.Errs, err)
+	}
+
 	if err := d.terminate(); err != nil {
 		multierr.Errs = append(multierr.Errs, err)
+	} else if err := d.closeOpenPorts(); err != nil {
+		log.Warnf("Unable to close the ports opened for the machine: %s", err)
 	}
 
 	if !d.ExistingKey {
@@ -880,6 +889,34 @@ func (d *Driver) getInstance() (*ec2.Instance, error) {
 	return instances.Reservations[0].Instances[0], nil
 }
 
+// InstanceExists tells whether the instance still exists. Terminated
+// instances, which EC2 lists for a while, do not.
+func (d *Driver) InstanceExists() (bool, error) {
+	if d.InstanceId == "" {
+		return false, nil
+	}
+
+	instances, err := d.getClient().DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: []*string{&d.InstanceId},
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "InvalidInstanceID.NotFound") {
+			return false, nil
+		}
+		return false, err
+	}
+
+	for _, reservation := range instances.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance.State == nil || instance.State.Name == nil || *instance.State.Name != ec2.InstanceStateNameTerminated {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 func (d *Driver) instanceIsRunning() bool {
 	st, err := d.GetState()
 	if err != nil {
@@ -985,6 +1022,60 @@ func (d *Driver) securityGroupAvailableFunc(id string) func() bool {
 	}
 }
 
+// SetInstanceOptions attaches the IAM instance profile and the tags given
+// at the machine level, rather than with the driver flags.
+func (d *Driver) SetInstanceOptions(opts drivers.InstanceOptions) error {
+	if opts.InstanceProfile != "" {
+		if d.IamInstanceProfile != "" && d.IamInstanceProfile != opts.InstanceProfile {
+			return fmt.Errorf("Conflicting IAM instance profiles %q and %q", d.IamInstanceProfile, opts.InstanceProfile)
+		}
+		d.IamInstanceProfile = opts.InstanceProfile
+	}
+
+	keys := []string{}
+	for key := range opts.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tagGroups := []string{}
+	if d.Tags != "" {
+		tagGroups = append(tagGroups, d.Tags)
+	}
+
+	for _, key := range keys {
+		value := opts.Tags[key]
+
+		if strings.HasPrefix(strings.ToLower(key), "aws:") {
+			return fmt.Errorf("Invalid tag %q, the aws: prefix is reserved", key)
+		}
+		// The tags are recorded as comma separated keys and values
+		if strings.Contains(key, ",") || strings.Contains(value, ",") {
+			return fmt.Errorf("Invalid tag %s=%s, tags cannot contain commas", key, value)
+		}
+
+		tagGroups = append(tagGroups, key, value)
+	}
+
+	d.Tags = strings.Join(tagGroups, ",")
+
+	return nil
+}
+
+// iamInstanceProfileSpecification accepts either the name or the ARN of the
+// instance profile.
+func (d *Driver) iamInstanceProfileSpecification() *ec2.IamInstanceProfileSpecification {
+	if strings.HasPrefix(d.IamInstanceProfile, "arn:") {
+		return &ec2.IamInstanceProfileSpecification{
+			Arn: &d.IamInstanceProfile,
+		}
+	}
+
+	return &ec2.IamInstanceProfileSpecification{
+		Name: &d.IamInstanceProfile,
+	}
+}
+
 func (d *Driver) configureTags(tagGroups string) error {
 
 	tags := []*ec2.Tag{}
@@ -1075,6 +1166,9 @@ func (d *Driver) configureSecurityGroups(groupNames []string) error {
 			if err := mcnutils.WaitFor(d.securityGroupAvailableFunc(*group.GroupId)); err != nil {
 				return err
 			}
+			if err := d.tagResources(group.GroupId); err != nil {
+				return fmt.Errorf("Unable to tag security group %s: %s", *group.GroupId, err)
+			}
 		}
 		d.SecurityGroupIds = append(d.SecurityGroupIds, *group.GroupId)
 
@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/docker/machine/commands/commandstest"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -524,3 +525,74 @@ func TestBase64UserDataIsCorrectWhenFileProvided(t *testing.T) {
 	assert.NoError(t, ud_err)
 	assert.Equal(t, contentBase64, userdata)
 }
+
+func TestSetInstanceOptions(t *testing.T) {
+	driver := NewTestDriver()
+	driver.Tags = "team,ci"
+
+	err := driver.SetInstanceOptions(drivers.InstanceOptions{
+		InstanceProfile: "builder",
+		Tags: map[string]string{
+			"env":   "test",
+			"owner": "alice",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "builder", driver.IamInstanceProfile)
+	assert.Equal(t, "team,ci,env,test,owner,alice", driver.Tags)
+}
+
+func TestSetInstanceOptionsConflictingProfile(t *testing.T) {
+	driver := NewTestDriver()
+	driver.IamInstanceProfile = "builder"
+
+	err := driver.SetInstanceOptions(drivers.InstanceOptions{
+		InstanceProfile: "deployer",
+	})
+
+	assert.Error(t, err)
+}
+
+func TestSetInstanceOptionsInvalidTags(t *testing.T) {
+	for _, tags := range []map[string]string{
+		{"aws:reserved": "value"},
+		{"key": "a,b"},
+	} {
+		driver := NewTestDriver()
+
+		err := driver.SetInstanceOptions(drivers.InstanceOptions{
+			Tags: tags,
+		})
+
+		assert.Error(t, err)
+	}
+}
+
+func TestIamInstanceProfileSpecification(t *testing.T) {
+	driver := NewTestDriver()
+
+	driver.IamInstanceProfile = "builder"
+	assert.Equal(t, "builder", *driver.iamInstanceProfileSpecification().Name)
+
+	driver.IamInstanceProfile = "arn:aws:iam::123456789012:instance-profile/builder"
+	assert.Equal(t, driver.IamInstanceProfile, *driver.iamInstanceProfileSpecification().Arn)
+}
+
+func TestGetConsoleOutput(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2Console{output: aws.String("Q2xvdWQtaW5pdCBmaW5pc2hlZA==")})
+	driver.InstanceId = "i-12345"
+
+	output, err := driver.GetConsoleOutput()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Cloud-init finished", output)
+}
+
+func TestGetConsoleOutputNotCreated(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2Console{})
+
+	_, err := driver.GetConsoleOutput()
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+package amazonec2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/drivers/driverstest"
+)
+
+// TestConformance replays the interactions with EC2 recorded in
+// testdata/conformance.json. Record them again with
+// MACHINE_CONFORMANCE_RECORD=1 and the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_VPC_ID of an account, which creates a t2.micro instance.
+func TestConformance(t *testing.T) {
+	driverstest.Run(t, driverstest.Options{
+		NewDriver: func(machineName, storePath string) drivers.Driver {
+			d := NewDriver(machineName, storePath)
+			d.AccessKey = envOr("AWS_ACCESS_KEY_ID", "AKIAREPLAYED")
+			d.SecretKey = envOr("AWS_SECRET_ACCESS_KEY", "replayed")
+			d.VpcId = envOr("AWS_VPC_ID", "vpc-replayed")
+			d.InstanceType = "t2.micro"
+			return d
+		},
+		Cassette: filepath.Join("testdata", "conformance.json"),
+		Redactions: map[string]string{
+			os.Getenv("AWS_ACCESS_KEY_ID"): "AKIAREPLAYED",
+			os.Getenv("AWS_VPC_ID"):        "vpc-replayed",
+		},
+	})
+}
+
+func envOr(name, defaultValue string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,36 @@
+package amazonec2
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+)
+
+// GetConsoleOutput returns the console output EC2 captured from the
+// instance. EC2 only updates it every few minutes, and keeps the last 64KB.
+func (d *Driver) GetConsoleOutput() (string, error) {
+	if d.InstanceId == "" {
+		return "", errors.New("The instance has not been created")
+	}
+
+	output, err := d.getClient().GetConsoleOutput(&ec2.GetConsoleOutputInput{
+		InstanceId: aws.String(d.InstanceId),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if output.Output == nil {
+		return "", nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(*output.Output)
+	if err != nil {
+		return "", fmt.Errorf("Error decoding the console output: %s", err)
+	}
+
+	return string(decoded), nil
+}
@@ -0,0 +1,131 @@
+package amazonec2
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+var errNoAdoptionKey = errors.New("The private key of the instance is needed to adopt it, set --amazonec2-ssh-keypath")
+
+// DiscoverInstances lists the instances of the region tagged as created by
+// docker-machine, except the terminated ones.
+func (d *Driver) DiscoverInstances() ([]drivers.DiscoveredInstance, error) {
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:" + drivers.CreatedByTag),
+				Values: []*string{aws.String(drivers.CreatedByTagValue)},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{
+					ec2.InstanceStateNamePending,
+					ec2.InstanceStateNameRunning,
+					ec2.InstanceStateNameStopping,
+					ec2.InstanceStateNameStopped,
+				}),
+			},
+		},
+	}
+
+	discovered := []drivers.DiscoveredInstance{}
+	for {
+		output, err := d.getClient().DescribeInstances(input)
+		if err != nil {
+			return nil, fmt.Errorf("Error listing the instances of %s: %s", d.Region, err)
+		}
+
+		for _, reservation := range output.Reservations {
+			for _, inst := range reservation.Instances {
+				discovered = append(discovered, d.discoveredInstance(inst))
+			}
+		}
+
+		if aws.StringValue(output.NextToken) == "" {
+			return discovered, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func (d *Driver) discoveredInstance(inst *ec2.Instance) drivers.DiscoveredInstance {
+	instance := drivers.DiscoveredInstance{
+		ID: aws.StringValue(inst.InstanceId),
+		IP: aws.StringValue(inst.PublicIpAddress),
+	}
+	if d.PrivateIPOnly || d.UsePrivateIP {
+		instance.IP = aws.StringValue(inst.PrivateIpAddress)
+	}
+	if inst.State != nil {
+		instance.State = aws.StringValue(inst.State.Name)
+	}
+	for _, tag := range inst.Tags {
+		if aws.StringValue(tag.Key) == drivers.MachineNameTag {
+			instance.MachineName = aws.StringValue(tag.Value)
+		}
+	}
+
+	return instance
+}
+
+// AdoptInstance records the instance, its addresses, network and key pair.
+// The key pair is kept when the machine is removed, as it may not have been
+// created for the instance. The private key is copied from SSHPrivateKeyPath
+// to the directory of the machine.
+func (d *Driver) AdoptInstance(instance drivers.DiscoveredInstance) error {
+	if d.SSHPrivateKeyPath == "" {
+		return errNoAdoptionKey
+	}
+
+	d.InstanceId = instance.ID
+	inst, err := d.getInstance()
+	if err != nil {
+		d.InstanceId = ""
+		return err
+	}
+
+	d.IPAddress = aws.StringValue(inst.PublicIpAddress)
+	d.PrivateIPAddress = aws.StringValue(inst.PrivateIpAddress)
+	d.InstanceType = aws.StringValue(inst.InstanceType)
+	d.AMI = aws.StringValue(inst.ImageId)
+	d.VpcId = aws.StringValue(inst.VpcId)
+	d.SubnetId = aws.StringValue(inst.SubnetId)
+	if inst.Placement != nil {
+		zone, err := zoneInRegion(aws.StringValue(inst.Placement.AvailabilityZone), d.Region)
+		if err != nil {
+			return err
+		}
+		d.Zone = zone
+	}
+
+	d.SecurityGroupIds = nil
+	d.SecurityGroupNames = nil
+	for _, group := range inst.SecurityGroups {
+		d.SecurityGroupIds = append(d.SecurityGroupIds, aws.StringValue(group.GroupId))
+		d.SecurityGroupNames = append(d.SecurityGroupNames, aws.StringValue(group.GroupName))
+	}
+
+	d.KeyName = aws.StringValue(inst.KeyName)
+	d.ExistingKey = true
+	d.ResourceTags = drivers.ResourceTags(instance.MachineName)
+
+	keyPath := d.GetSSHKeyPath()
+	if err := os.MkdirAll(filepath.Dir(keyPath), 0700); err != nil {
+		return err
+	}
+	if err := mcnutils.CopyFile(d.SSHPrivateKeyPath, keyPath); err != nil {
+		return err
+	}
+	if _, err := os.Stat(d.SSHPrivateKeyPath + ".pub"); err == nil {
+		return mcnutils.CopyFile(d.SSHPrivateKeyPath+".pub", keyPath+".pub")
+	}
+
+	return nil
+}
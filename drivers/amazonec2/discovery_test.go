@@ -0,0 +1,113 @@
+package amazonec2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2Discovery struct {
+	*fakeEC2
+	pages  [][]*ec2.Instance
+	inputs []*ec2.DescribeInstancesInput
+}
+
+func (f *fakeEC2Discovery) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	f.inputs = append(f.inputs, input)
+
+	page := len(f.inputs) - 1
+	if len(input.InstanceIds) > 0 {
+		page = 0
+	}
+
+	output := &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: f.pages[page]}},
+	}
+	if len(input.InstanceIds) == 0 && page < len(f.pages)-1 {
+		output.NextToken = aws.String("next")
+	}
+	return output, nil
+}
+
+func discoveryTestInstance(id, name, ip string) *ec2.Instance {
+	return &ec2.Instance{
+		InstanceId:       aws.String(id),
+		PublicIpAddress:  aws.String(ip),
+		PrivateIpAddress: aws.String("10.0.0.1"),
+		State:            &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+		KeyName:          aws.String("team-key"),
+		Placement:        &ec2.Placement{AvailabilityZone: aws.String("us-east-1b")},
+		SecurityGroups: []*ec2.GroupIdentifier{
+			{GroupId: aws.String("sg-1"), GroupName: aws.String("docker-machine")},
+		},
+		Tags: []*ec2.Tag{
+			{Key: aws.String(drivers.CreatedByTag), Value: aws.String(drivers.CreatedByTagValue)},
+			{Key: aws.String(drivers.MachineNameTag), Value: aws.String(name)},
+		},
+	}
+}
+
+func TestDiscoverInstances(t *testing.T) {
+	client := &fakeEC2Discovery{
+		pages: [][]*ec2.Instance{
+			{discoveryTestInstance("i-1", "build", "1.2.3.4")},
+			{discoveryTestInstance("i-2", "deploy", "1.2.3.5")},
+		},
+	}
+	driver := NewCustomTestDriver(client)
+
+	instances, err := driver.DiscoverInstances()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.DiscoveredInstance{
+		{MachineName: "build", ID: "i-1", IP: "1.2.3.4", State: "running"},
+		{MachineName: "deploy", ID: "i-2", IP: "1.2.3.5", State: "running"},
+	}, instances)
+	assert.Len(t, client.inputs, 2)
+	assert.Equal(t, "next", aws.StringValue(client.inputs[1].NextToken))
+}
+
+func TestAdoptInstance(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "amazonec2-adopt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(storePath)
+
+	keyPath := filepath.Join(storePath, "team-key")
+	assert.NoError(t, ioutil.WriteFile(keyPath, []byte("private key"), 0600))
+
+	client := &fakeEC2Discovery{
+		pages: [][]*ec2.Instance{{discoveryTestInstance("i-1", "build", "1.2.3.4")}},
+	}
+	driver := NewCustomTestDriver(client)
+	driver.MachineName = "build"
+	driver.StorePath = storePath
+	driver.SSHPrivateKeyPath = keyPath
+
+	err = driver.AdoptInstance(drivers.DiscoveredInstance{MachineName: "build", ID: "i-1"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "i-1", driver.InstanceId)
+	assert.Equal(t, "1.2.3.4", driver.IPAddress)
+	assert.Equal(t, "b", driver.Zone)
+	assert.Equal(t, []string{"sg-1"}, driver.SecurityGroupIds)
+	assert.Equal(t, "team-key", driver.KeyName)
+	assert.True(t, driver.ExistingKey)
+
+	key, err := ioutil.ReadFile(driver.GetSSHKeyPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "private key", string(key))
+}
+
+func TestAdoptInstanceWithoutKey(t *testing.T) {
+	driver := NewTestDriver()
+
+	err := driver.AdoptInstance(drivers.DiscoveredInstance{MachineName: "build", ID: "i-1"})
+
+	assert.Equal(t, errNoAdoptionKey, err)
+}
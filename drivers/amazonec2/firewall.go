@@ -0,0 +1,205 @@
+package amazonec2
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// SetFirewallRules adds the rules to the ports opened in the security groups
+// at creation. SSH and the engine port are always opened, see
+// configureSecurityGroupPermissions.
+func (d *Driver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	for _, rule := range rules {
+		if drivers.IsRequiredFirewallRule(rule, dockerPort) {
+			continue
+		}
+		d.addOpenPort(rule)
+	}
+	return nil
+}
+
+// OpenPort authorizes the port in the security groups of the machine.
+func (d *Driver) OpenPort(rule drivers.FirewallRule) error {
+	groups, err := d.describeSecurityGroups()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if hasPermission(group, rule) {
+			continue
+		}
+
+		log.Debugf("authorizing %s in security group %s", rule, *group.GroupId)
+		if _, err := d.getClient().AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+			GroupId:       group.GroupId,
+			IpPermissions: []*ec2.IpPermission{firewallPermission(rule)},
+		}); err != nil {
+			return err
+		}
+	}
+
+	if !drivers.IsRequiredFirewallRule(rule, dockerPort) {
+		d.addOpenPort(rule)
+	}
+
+	return nil
+}
+
+// ClosePort revokes the port in the security groups of the machine which no
+// other machine uses. The port stays open in the shared groups.
+func (d *Driver) ClosePort(rule drivers.FirewallRule) error {
+	if err := d.revokePorts([]drivers.FirewallRule{rule}); err != nil {
+		return err
+	}
+
+	kept := []string{}
+	for _, port := range d.OpenPorts {
+		if openPort, err := drivers.ParseFirewallRule(port); err != nil || openPort != rule {
+			kept = append(kept, port)
+		}
+	}
+	d.OpenPorts = kept
+
+	return nil
+}
+
+// closeOpenPorts revokes the extra ports of the machine once its instance is
+// terminated, from the groups no other machine uses.
+func (d *Driver) closeOpenPorts() error {
+	rules := []drivers.FirewallRule{}
+	for _, port := range d.OpenPorts {
+		rule, err := drivers.ParseFirewallRule(port)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 || len(d.securityGroupIds()) == 0 {
+		return nil
+	}
+
+	return d.revokePorts(rules)
+}
+
+func (d *Driver) revokePorts(rules []drivers.FirewallRule) error {
+	groups, err := d.describeSecurityGroups()
+	if err != nil {
+		return err
+	}
+
+	shared, err := d.sharedSecurityGroups()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		if shared[*group.GroupId] {
+			log.Debugf("keeping the rules of security group %s, used by other instances", *group.GroupId)
+			continue
+		}
+
+		perms := []*ec2.IpPermission{}
+		for _, rule := range rules {
+			if hasPermission(group, rule) {
+				perms = append(perms, firewallPermission(rule))
+			}
+		}
+		if len(perms) == 0 {
+			continue
+		}
+
+		log.Debugf("revoking %d permissions of security group %s", len(perms), *group.GroupId)
+		if _, err := d.getClient().RevokeSecurityGroupIngress(&ec2.RevokeSecurityGroupIngressInput{
+			GroupId:       group.GroupId,
+			IpPermissions: perms,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Driver) describeSecurityGroups() ([]*ec2.SecurityGroup, error) {
+	ids := d.securityGroupIds()
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("Machine %s has no security group", d.MachineName)
+	}
+
+	groups, err := d.getClient().DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: makePointerSlice(ids),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups.SecurityGroups, nil
+}
+
+// sharedSecurityGroups returns the IDs of the security groups of the machine
+// which other live instances use.
+func (d *Driver) sharedSecurityGroups() (map[string]bool, error) {
+	instances, err := d.getClient().DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("instance.group-id"),
+				Values: makePointerSlice(d.securityGroupIds()),
+			},
+			{
+				Name:   aws.String("instance-state-name"),
+				Values: makePointerSlice([]string{"pending", "running", "stopping", "stopped"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	shared := map[string]bool{}
+	for _, reservation := range instances.Reservations {
+		for _, instance := range reservation.Instances {
+			if instance.InstanceId == nil || *instance.InstanceId == d.InstanceId {
+				continue
+			}
+			for _, group := range instance.SecurityGroups {
+				if group.GroupId != nil {
+					shared[*group.GroupId] = true
+				}
+			}
+		}
+	}
+
+	return shared, nil
+}
+
+func (d *Driver) addOpenPort(rule drivers.FirewallRule) {
+	for _, port := range d.OpenPorts {
+		if openPort, err := drivers.ParseFirewallRule(port); err == nil && openPort == rule {
+			return
+		}
+	}
+	d.OpenPorts = append(d.OpenPorts, rule.String())
+}
+
+func hasPermission(group *ec2.SecurityGroup, rule drivers.FirewallRule) bool {
+	for _, p := range group.IpPermissions {
+		if p.FromPort != nil && p.IpProtocol != nil && *p.FromPort == int64(rule.Port) && *p.IpProtocol == rule.Protocol {
+			return true
+		}
+	}
+	return false
+}
+
+func firewallPermission(rule drivers.FirewallRule) *ec2.IpPermission {
+	return &ec2.IpPermission{
+		IpProtocol: aws.String(rule.Protocol),
+		FromPort:   aws.Int64(int64(rule.Port)),
+		ToPort:     aws.Int64(int64(rule.Port)),
+		IpRanges:   []*ec2.IpRange{{CidrIp: aws.String(ipRange)}},
+	}
+}
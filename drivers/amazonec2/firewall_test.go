@@ -0,0 +1,106 @@
+package amazonec2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2Firewall struct {
+	*fakeEC2
+	groups     []*ec2.SecurityGroup
+	instances  []*ec2.Instance
+	authorized []*ec2.AuthorizeSecurityGroupIngressInput
+	revoked    []*ec2.RevokeSecurityGroupIngressInput
+}
+
+func (f *fakeEC2Firewall) DescribeSecurityGroups(input *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: f.groups}, nil
+}
+
+func (f *fakeEC2Firewall) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: f.instances}},
+	}, nil
+}
+
+func (f *fakeEC2Firewall) AuthorizeSecurityGroupIngress(input *ec2.AuthorizeSecurityGroupIngressInput) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	f.authorized = append(f.authorized, input)
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+func (f *fakeEC2Firewall) RevokeSecurityGroupIngress(input *ec2.RevokeSecurityGroupIngressInput) (*ec2.RevokeSecurityGroupIngressOutput, error) {
+	if input.GroupId == nil {
+		return nil, errors.New("missing group")
+	}
+	f.revoked = append(f.revoked, input)
+	return &ec2.RevokeSecurityGroupIngressOutput{}, nil
+}
+
+func newFirewallTestDriver(client *fakeEC2Firewall) *Driver {
+	driver := NewCustomTestDriver(client)
+	driver.InstanceId = "i-machine"
+	driver.SecurityGroupIds = []string{"sg-machine"}
+	return driver
+}
+
+func TestSetFirewallRules(t *testing.T) {
+	driver := NewTestDriver()
+	driver.OpenPorts = []string{"8080"}
+
+	err := driver.SetFirewallRules(append(drivers.RequiredFirewallRules(dockerPort),
+		drivers.FirewallRule{Protocol: "tcp", Port: 8080},
+		drivers.FirewallRule{Protocol: "udp", Port: 53},
+	))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"8080", "53/udp"}, driver.OpenPorts)
+}
+
+func TestOpenPort(t *testing.T) {
+	client := &fakeEC2Firewall{
+		groups: []*ec2.SecurityGroup{
+			{GroupId: aws.String("sg-machine"), IpPermissions: []*ec2.IpPermission{ipPermission(testSSHPort)}},
+			{GroupId: aws.String("sg-web"), IpPermissions: []*ec2.IpPermission{ipPermission(80)}},
+		},
+	}
+	driver := newFirewallTestDriver(client)
+
+	err := driver.OpenPort(drivers.FirewallRule{Protocol: "tcp", Port: 80})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ec2.AuthorizeSecurityGroupIngressInput{{
+		GroupId:       aws.String("sg-machine"),
+		IpPermissions: []*ec2.IpPermission{ipPermission(80)},
+	}}, client.authorized)
+	assert.Equal(t, []string{"80/tcp"}, driver.OpenPorts)
+}
+
+func TestClosePortKeepsSharedGroups(t *testing.T) {
+	client := &fakeEC2Firewall{
+		groups: []*ec2.SecurityGroup{
+			{GroupId: aws.String("sg-machine"), IpPermissions: []*ec2.IpPermission{ipPermission(80)}},
+			{GroupId: aws.String("sg-shared"), IpPermissions: []*ec2.IpPermission{ipPermission(80)}},
+		},
+		instances: []*ec2.Instance{
+			{InstanceId: aws.String("i-machine"), SecurityGroups: []*ec2.GroupIdentifier{{GroupId: aws.String("sg-machine")}}},
+			{InstanceId: aws.String("i-other"), SecurityGroups: []*ec2.GroupIdentifier{{GroupId: aws.String("sg-shared")}}},
+		},
+	}
+	driver := newFirewallTestDriver(client)
+	driver.SecurityGroupIds = []string{"sg-machine", "sg-shared"}
+	driver.OpenPorts = []string{"80/tcp", "53/udp"}
+
+	err := driver.ClosePort(drivers.FirewallRule{Protocol: "tcp", Port: 80})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*ec2.RevokeSecurityGroupIngressInput{{
+		GroupId:       aws.String("sg-machine"),
+		IpPermissions: []*ec2.IpPermission{ipPermission(80)},
+	}}, client.revoked)
+	assert.Equal(t, []string{"53/udp"}, driver.OpenPorts)
+}
@@ -0,0 +1,68 @@
+package amazonec2
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+type gpuInstanceType struct {
+	count int
+	model string
+}
+
+// gpuInstanceTypes are the instance types with NVIDIA GPUs attached. The GPUs
+// of an instance come with its type and cannot be chosen otherwise.
+var gpuInstanceTypes = map[string]gpuInstanceType{
+	"p2.xlarge":   {1, "k80"},
+	"p2.8xlarge":  {8, "k80"},
+	"p2.16xlarge": {16, "k80"},
+	"p3.2xlarge":  {1, "v100"},
+	"p3.8xlarge":  {4, "v100"},
+	"p3.16xlarge": {8, "v100"},
+	"g2.2xlarge":  {1, "k520"},
+	"g2.8xlarge":  {4, "k520"},
+	"g3.4xlarge":  {1, "m60"},
+	"g3.8xlarge":  {2, "m60"},
+	"g3.16xlarge": {4, "m60"},
+}
+
+// SetGPUOptions checks that the instance type comes with the GPUs asked for.
+// The instance type is not picked on behalf of the user, as GPU instances
+// are expensive.
+func (d *Driver) SetGPUOptions(opts drivers.GPUOptions) error {
+	if len(opts.PCIDevices) > 0 {
+		return errors.New("PCI passthrough is not supported by EC2, pick a GPU instance type instead")
+	}
+
+	gpuType, ok := gpuInstanceTypes[d.InstanceType]
+	if !ok {
+		return fmt.Errorf("Instance type %s has no GPU, use one of %s", d.InstanceType, strings.Join(gpuInstanceTypeNames(opts.Type), ", "))
+	}
+
+	if opts.Type != "" && !strings.EqualFold(opts.Type, gpuType.model) {
+		return fmt.Errorf("Instance type %s has %s GPUs, not %s", d.InstanceType, strings.ToUpper(gpuType.model), opts.Type)
+	}
+
+	if opts.Count > gpuType.count {
+		return fmt.Errorf("Instance type %s has %d GPUs, %d asked for", d.InstanceType, gpuType.count, opts.Count)
+	}
+
+	return nil
+}
+
+// gpuInstanceTypeNames lists the GPU instance types, of the given GPU model
+// if any.
+func gpuInstanceTypeNames(model string) []string {
+	names := []string{}
+	for name, gpuType := range gpuInstanceTypes {
+		if model == "" || strings.EqualFold(model, gpuType.model) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,33 @@
+package amazonec2
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetGPUOptions(t *testing.T) {
+	driver := NewDriver("machineFoo", "path")
+	driver.InstanceType = "p3.8xlarge"
+
+	assert.NoError(t, driver.SetGPUOptions(drivers.GPUOptions{Count: 4, Type: "V100"}))
+	assert.NoError(t, driver.SetGPUOptions(drivers.GPUOptions{Count: 1}))
+}
+
+func TestSetGPUOptionsInvalid(t *testing.T) {
+	driver := NewDriver("machineFoo", "path")
+	driver.InstanceType = "g3.4xlarge"
+
+	assert.EqualError(t, driver.SetGPUOptions(drivers.GPUOptions{Count: 2}), "Instance type g3.4xlarge has 1 GPUs, 2 asked for")
+	assert.EqualError(t, driver.SetGPUOptions(drivers.GPUOptions{Type: "k80"}), "Instance type g3.4xlarge has M60 GPUs, not k80")
+	assert.Error(t, driver.SetGPUOptions(drivers.GPUOptions{PCIDevices: []string{"01:00.0"}}))
+}
+
+func TestSetGPUOptionsNoGPUInstanceType(t *testing.T) {
+	driver := NewDriver("machineFoo", "path")
+
+	err := driver.SetGPUOptions(drivers.GPUOptions{Count: 1, Type: "k80"})
+
+	assert.EqualError(t, err, "Instance type t2.micro has no GPU, use one of p2.16xlarge, p2.8xlarge, p2.xlarge")
+}
@@ -0,0 +1,52 @@
+package amazonec2
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// GetNameConstraints returns the rules of EC2 for the names of the key
+// pairs and the values of the tags, the machine name being used for both.
+func (d *Driver) GetNameConstraints() (drivers.NameConstraints, error) {
+	return drivers.NameConstraints{
+		MaxLength:          255,
+		Pattern:            `^[\x20-\x7e]+$`,
+		PatternDescription: "printable ASCII characters",
+	}, nil
+}
+
+// CheckNameAvailable checks that no instance of the region which is not
+// terminated is tagged with the name of the machine.
+func (d *Driver) CheckNameAvailable() error {
+	instances, err := d.getClient().DescribeInstances(&ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:Name"),
+				Values: []*string{aws.String(d.MachineName)},
+			},
+			{
+				Name: aws.String("instance-state-name"),
+				Values: aws.StringSlice([]string{
+					ec2.InstanceStateNamePending,
+					ec2.InstanceStateNameRunning,
+					ec2.InstanceStateNameStopping,
+					ec2.InstanceStateNameStopped,
+				}),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Error looking for instances named %q: %s", d.MachineName, err)
+	}
+
+	for _, reservation := range instances.Reservations {
+		for _, instance := range reservation.Instances {
+			return fmt.Errorf("instance %s of the region %s is already named %q", aws.StringValue(instance.InstanceId), d.Region, d.MachineName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package amazonec2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2Instances struct {
+	*fakeEC2
+	named map[string]string
+}
+
+func (f *fakeEC2Instances) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	output := &ec2.DescribeInstancesOutput{}
+	name := aws.StringValue(input.Filters[0].Values[0])
+	if id, ok := f.named[name]; ok {
+		output.Reservations = []*ec2.Reservation{{
+			Instances: []*ec2.Instance{{InstanceId: aws.String(id)}},
+		}}
+	}
+	return output, nil
+}
+
+func TestCheckNameAvailable(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2Instances{named: map[string]string{"taken": "i-0123"}})
+
+	driver.MachineName = "free"
+	assert.NoError(t, driver.CheckNameAvailable())
+
+	driver.MachineName = "taken"
+	assert.EqualError(t, driver.CheckNameAvailable(), `instance i-0123 of the region us-east-1 is already named "taken"`)
+}
+
+func TestNameConstraints(t *testing.T) {
+	constraints, err := NewTestDriver().GetNameConstraints()
+
+	assert.NoError(t, err)
+	assert.NoError(t, constraints.Check("amazonec2", "dev_1.example"))
+	assert.Error(t, constraints.Check("amazonec2", "dév"))
+}
@@ -0,0 +1,86 @@
+package amazonec2
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// SetPlacement sets the region and the availability zone. The zone is
+// either the full name of the zone, e.g. eu-west-1b, or its letter as the
+// --amazonec2-zone flag takes it. The default AMI follows the region. With
+// a custom endpoint, the zone is taken as is.
+func (d *Driver) SetPlacement(placement drivers.Placement) error {
+	if placement.Region != "" && placement.Region != d.Region {
+		region, err := validateAwsRegion(placement.Region)
+		if err != nil && d.Endpoint == "" {
+			return fmt.Errorf("Invalid region %q", placement.Region)
+		}
+		if current, ok := regionDetails[d.Region]; ok && err == nil && d.AMI == current.AmiId {
+			d.AMI = regionDetails[region].AmiId
+		}
+		d.Region = placement.Region
+	}
+
+	if placement.Zone != "" && d.Endpoint != "" {
+		d.Zone = placement.Zone
+	} else if placement.Zone != "" {
+		zone, err := zoneInRegion(placement.Zone, d.Region)
+		if err != nil {
+			return err
+		}
+		d.Zone = zone
+	}
+
+	return nil
+}
+
+// GetPlacement returns the region and the full name of the zone.
+func (d *Driver) GetPlacement() (drivers.Placement, error) {
+	return drivers.Placement{
+		Region: d.Region,
+		Zone:   d.getRegionZone(),
+	}, nil
+}
+
+// CheckPlacement checks that the zone is one of the region and available.
+func (d *Driver) CheckPlacement() error {
+	regionZone := d.getRegionZone()
+
+	zones, err := d.getClient().DescribeAvailabilityZones(&ec2.DescribeAvailabilityZonesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("zone-name"),
+			Values: []*string{aws.String(regionZone)},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading the availability zones of %s: %s", d.Region, err)
+	}
+
+	for _, zone := range zones.AvailabilityZones {
+		if aws.StringValue(zone.ZoneName) != regionZone {
+			continue
+		}
+		if state := aws.StringValue(zone.State); state != ec2.AvailabilityZoneStateAvailable {
+			return fmt.Errorf("The zone %s is %s", regionZone, state)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("The zone %s does not exist in the region %s", regionZone, d.Region)
+}
+
+// zoneInRegion returns the suffix of the zone appended to the region,
+// failing for the zones of other regions.
+func zoneInRegion(zone, region string) (string, error) {
+	if strings.HasPrefix(zone, region) {
+		return strings.TrimPrefix(zone, region), nil
+	}
+	if strings.Contains(zone, "-") {
+		return "", fmt.Errorf("The zone %s is not in the region %s", zone, region)
+	}
+	return zone, nil
+}
@@ -0,0 +1,67 @@
+package amazonec2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2Zones struct {
+	*fakeEC2
+	zones map[string]string
+}
+
+func (f *fakeEC2Zones) DescribeAvailabilityZones(input *ec2.DescribeAvailabilityZonesInput) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	output := &ec2.DescribeAvailabilityZonesOutput{}
+	name := aws.StringValue(input.Filters[0].Values[0])
+	if state, ok := f.zones[name]; ok {
+		output.AvailabilityZones = append(output.AvailabilityZones, &ec2.AvailabilityZone{
+			ZoneName: aws.String(name),
+			State:    aws.String(state),
+		})
+	}
+	return output, nil
+}
+
+func TestSetPlacement(t *testing.T) {
+	driver := NewTestDriver()
+	driver.AMI = regionDetails[defaultRegion].AmiId
+
+	assert.NoError(t, driver.SetPlacement(drivers.Placement{Region: "eu-west-1", Zone: "eu-west-1b"}))
+
+	placement, err := driver.GetPlacement()
+	assert.NoError(t, err)
+	assert.Equal(t, drivers.Placement{Region: "eu-west-1", Zone: "eu-west-1b"}, placement)
+	assert.Equal(t, "b", driver.Zone)
+	assert.Equal(t, regionDetails["eu-west-1"].AmiId, driver.AMI)
+
+	assert.NoError(t, driver.SetPlacement(drivers.Placement{Zone: "c"}))
+	assert.Equal(t, "eu-west-1c", driver.getRegionZone())
+}
+
+func TestSetPlacementInvalid(t *testing.T) {
+	driver := NewTestDriver()
+
+	assert.EqualError(t, driver.SetPlacement(drivers.Placement{Region: "mars-north-1"}), `Invalid region "mars-north-1"`)
+	assert.EqualError(t, driver.SetPlacement(drivers.Placement{Region: "eu-west-1", Zone: "us-east-1a"}), "The zone us-east-1a is not in the region eu-west-1")
+}
+
+func TestCheckPlacement(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2Zones{zones: map[string]string{
+		"eu-west-1a": ec2.AvailabilityZoneStateAvailable,
+		"eu-west-1b": ec2.AvailabilityZoneStateImpaired,
+	}})
+	driver.Region = "eu-west-1"
+
+	driver.Zone = "a"
+	assert.NoError(t, driver.CheckPlacement())
+
+	driver.Zone = "b"
+	assert.EqualError(t, driver.CheckPlacement(), "The zone eu-west-1b is impaired")
+
+	driver.Zone = "e"
+	assert.EqualError(t, driver.CheckPlacement(), "The zone eu-west-1e does not exist in the region eu-west-1")
+}
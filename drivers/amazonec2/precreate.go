@@ -0,0 +1,139 @@
+package amazonec2
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	dryRunOperationCode       = "DryRunOperation"
+	unauthorizedOperationCode = "UnauthorizedOperation"
+	maxInstancesAttribute     = "max-instances"
+	instanceStateNameFilter   = "instance-state-name"
+)
+
+// checkProvider checks with EC2, before anything is created, that the AMI
+// is available, that the account has room for another instance and, with
+// a dry run, that the credentials are allowed to launch the instance in the
+// subnet.
+func (d *Driver) checkProvider() error {
+	log.Info("Checking the AMI, the instance quota and the permissions...")
+
+	if err := d.checkImage(); err != nil {
+		return err
+	}
+
+	if err := d.checkInstanceQuota(); err != nil {
+		return err
+	}
+
+	// Spot instances are requested rather than launched, the request is
+	// checked when it is made.
+	if d.RequestSpotInstance {
+		return nil
+	}
+
+	return d.checkRunInstances()
+}
+
+// checkImage checks that the AMI exists in the region and is available.
+func (d *Driver) checkImage() error {
+	images, err := d.getClient().DescribeImages(&ec2.DescribeImagesInput{
+		ImageIds: []*string{aws.String(d.AMI)},
+	})
+	if err != nil {
+		return fmt.Errorf("Error reading the AMI %s: %s", d.AMI, err)
+	}
+
+	if len(images.Images) == 0 {
+		return fmt.Errorf("The AMI %s does not exist in the region %s", d.AMI, d.Region)
+	}
+	if state := aws.StringValue(images.Images[0].State); state != ec2.ImageStateAvailable {
+		return fmt.Errorf("The AMI %s is %s", d.AMI, state)
+	}
+
+	return nil
+}
+
+// checkInstanceQuota checks that the instances of the account in the region
+// leave room for one more. Accounts without the max-instances attribute,
+// e.g. with other EC2 compatible clouds, are not checked.
+func (d *Driver) checkInstanceQuota() error {
+	attributes, err := d.getClient().DescribeAccountAttributes(&ec2.DescribeAccountAttributesInput{
+		AttributeNames: []*string{aws.String(maxInstancesAttribute)},
+	})
+	if err != nil {
+		log.Debugf("Not checking the instance quota, the account attributes are not readable: %s", err)
+		return nil
+	}
+
+	limit := -1
+	for _, attribute := range attributes.AccountAttributes {
+		if aws.StringValue(attribute.AttributeName) != maxInstancesAttribute || len(attribute.AttributeValues) == 0 {
+			continue
+		}
+		if limit, err = strconv.Atoi(aws.StringValue(attribute.AttributeValues[0].AttributeValue)); err != nil {
+			return fmt.Errorf("Unexpected instance quota %q", aws.StringValue(attribute.AttributeValues[0].AttributeValue))
+		}
+	}
+	if limit < 0 {
+		return nil
+	}
+
+	usage := 0
+	input := &ec2.DescribeInstancesInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String(instanceStateNameFilter),
+			Values: []*string{aws.String(ec2.InstanceStateNamePending), aws.String(ec2.InstanceStateNameRunning)},
+		}},
+	}
+	for {
+		instances, err := d.getClient().DescribeInstances(input)
+		if err != nil {
+			return fmt.Errorf("Error counting the instances of the account: %s", err)
+		}
+
+		for _, reservation := range instances.Reservations {
+			usage += len(reservation.Instances)
+		}
+
+		if aws.StringValue(instances.NextToken) == "" {
+			break
+		}
+		input.NextToken = instances.NextToken
+	}
+
+	return drivers.CheckQuota("instances", float64(limit), float64(usage), 1)
+}
+
+// checkRunInstances launches the instance with DryRun, for EC2 to check the
+// permissions, the instance type and the subnet without launching it.
+func (d *Driver) checkRunInstances() error {
+	_, err := d.getClient().RunInstances(&ec2.RunInstancesInput{
+		DryRun:             aws.Bool(true),
+		ImageId:            aws.String(d.AMI),
+		InstanceType:       aws.String(d.InstanceType),
+		MinCount:           aws.Int64(1),
+		MaxCount:           aws.Int64(1),
+		SubnetId:           aws.String(d.SubnetId),
+		IamInstanceProfile: d.iamInstanceProfileSpecification(),
+	})
+
+	awsErr, ok := err.(awserr.Error)
+	switch {
+	case ok && awsErr.Code() == dryRunOperationCode:
+		return nil
+	case ok && awsErr.Code() == unauthorizedOperationCode:
+		return fmt.Errorf("The AWS credentials are not allowed to launch instances: %s", awsErr.Message())
+	case err != nil:
+		return fmt.Errorf("Launching the instance would fail: %s", err)
+	}
+
+	return nil
+}
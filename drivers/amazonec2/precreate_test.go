@@ -0,0 +1,119 @@
+package amazonec2
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2PreCreate struct {
+	*fakeEC2
+	imageState   string
+	maxInstances string
+	instances    int
+	runErr       error
+	dryRuns      int
+}
+
+func (f *fakeEC2PreCreate) DescribeImages(input *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	if f.imageState == "" {
+		return &ec2.DescribeImagesOutput{}, nil
+	}
+	return &ec2.DescribeImagesOutput{
+		Images: []*ec2.Image{{ImageId: input.ImageIds[0], State: aws.String(f.imageState)}},
+	}, nil
+}
+
+func (f *fakeEC2PreCreate) DescribeAccountAttributes(input *ec2.DescribeAccountAttributesInput) (*ec2.DescribeAccountAttributesOutput, error) {
+	return &ec2.DescribeAccountAttributesOutput{
+		AccountAttributes: []*ec2.AccountAttribute{{
+			AttributeName:   aws.String(maxInstancesAttribute),
+			AttributeValues: []*ec2.AccountAttributeValue{{AttributeValue: aws.String(f.maxInstances)}},
+		}},
+	}, nil
+}
+
+func (f *fakeEC2PreCreate) DescribeInstances(input *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	instances := []*ec2.Instance{}
+	for i := 0; i < f.instances; i++ {
+		instances = append(instances, &ec2.Instance{})
+	}
+	return &ec2.DescribeInstancesOutput{
+		Reservations: []*ec2.Reservation{{Instances: instances}},
+	}, nil
+}
+
+func (f *fakeEC2PreCreate) RunInstances(input *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	if !aws.BoolValue(input.DryRun) {
+		return nil, errors.New("not a dry run")
+	}
+	f.dryRuns++
+	return nil, f.runErr
+}
+
+func newFakeEC2PreCreate() *fakeEC2PreCreate {
+	return &fakeEC2PreCreate{
+		imageState:   ec2.ImageStateAvailable,
+		maxInstances: "20",
+		instances:    3,
+		runErr:       awserr.New(dryRunOperationCode, "Request would have succeeded", nil),
+	}
+}
+
+func TestCheckProvider(t *testing.T) {
+	client := newFakeEC2PreCreate()
+	driver := NewCustomTestDriver(client)
+
+	err := driver.checkProvider()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.dryRuns)
+}
+
+func TestCheckProviderMissingImage(t *testing.T) {
+	client := newFakeEC2PreCreate()
+	client.imageState = ""
+	driver := NewCustomTestDriver(client)
+	driver.AMI = "ami-missing"
+
+	err := driver.checkProvider()
+
+	assert.EqualError(t, err, "The AMI ami-missing does not exist in the region us-east-1")
+	assert.Equal(t, 0, client.dryRuns)
+}
+
+func TestCheckProviderInstanceQuota(t *testing.T) {
+	client := newFakeEC2PreCreate()
+	client.instances = 20
+	driver := NewCustomTestDriver(client)
+
+	err := driver.checkProvider()
+
+	assert.Equal(t, drivers.ErrQuotaExceeded{Quota: "instances", Limit: 20, Usage: 20, Needed: 1}, err)
+}
+
+func TestCheckProviderUnauthorized(t *testing.T) {
+	client := newFakeEC2PreCreate()
+	client.runErr = awserr.New(unauthorizedOperationCode, "You are not authorized to perform this operation.", nil)
+	driver := NewCustomTestDriver(client)
+
+	err := driver.checkProvider()
+
+	assert.EqualError(t, err, "The AWS credentials are not allowed to launch instances: You are not authorized to perform this operation.")
+}
+
+func TestCheckProviderSpotInstance(t *testing.T) {
+	client := newFakeEC2PreCreate()
+	driver := NewCustomTestDriver(client)
+	driver.RequestSpotInstance = true
+
+	err := driver.checkProvider()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, client.dryRuns)
+}
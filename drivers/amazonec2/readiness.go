@@ -0,0 +1,53 @@
+package amazonec2
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// InstanceReady tells whether the reachability checks of EC2 passed for the
+// instance, meaning that its network is up. It fails when they failed.
+func (d *Driver) InstanceReady() (bool, error) {
+	if d.InstanceId == "" {
+		return false, nil
+	}
+
+	statuses, err := d.getClient().DescribeInstanceStatus(&ec2.DescribeInstanceStatusInput{
+		InstanceIds: []*string{aws.String(d.InstanceId)},
+	})
+	if err != nil {
+		log.Debugf("Error reading the status of instance %s: %s", d.InstanceId, err)
+		return false, nil
+	}
+
+	for _, status := range statuses.InstanceStatuses {
+		for _, summary := range []*ec2.InstanceStatusSummary{status.SystemStatus, status.InstanceStatus} {
+			if summary == nil {
+				return false, nil
+			}
+
+			switch reachability(summary) {
+			case ec2.StatusTypeFailed:
+				return false, fmt.Errorf("The reachability check of instance %s failed, see its console output", d.InstanceId)
+			case ec2.StatusTypePassed:
+			default:
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func reachability(summary *ec2.InstanceStatusSummary) string {
+	for _, detail := range summary.Details {
+		if detail.Name != nil && *detail.Name == ec2.StatusNameReachability && detail.Status != nil {
+			return *detail.Status
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package amazonec2
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeEC2Status struct {
+	*fakeEC2
+	statuses []*ec2.InstanceStatus
+}
+
+func (f *fakeEC2Status) DescribeInstanceStatus(input *ec2.DescribeInstanceStatusInput) (*ec2.DescribeInstanceStatusOutput, error) {
+	return &ec2.DescribeInstanceStatusOutput{InstanceStatuses: f.statuses}, nil
+}
+
+func statusSummary(reachability string) *ec2.InstanceStatusSummary {
+	return &ec2.InstanceStatusSummary{
+		Details: []*ec2.InstanceStatusDetails{{
+			Name:   aws.String(ec2.StatusNameReachability),
+			Status: aws.String(reachability),
+		}},
+	}
+}
+
+func TestInstanceReady(t *testing.T) {
+	tests := []struct {
+		system, instance string
+		ready            bool
+		failed           bool
+	}{
+		{ec2.StatusTypePassed, ec2.StatusTypePassed, true, false},
+		{ec2.StatusTypePassed, ec2.StatusTypeInitializing, false, false},
+		{ec2.StatusTypeInitializing, ec2.StatusTypeInitializing, false, false},
+		{ec2.StatusTypePassed, ec2.StatusTypeFailed, false, true},
+	}
+
+	for _, test := range tests {
+		driver := NewCustomTestDriver(&fakeEC2Status{statuses: []*ec2.InstanceStatus{{
+			SystemStatus:   statusSummary(test.system),
+			InstanceStatus: statusSummary(test.instance),
+		}}})
+		driver.InstanceId = "i-1234"
+
+		ready, err := driver.InstanceReady()
+
+		assert.Equal(t, test.ready, ready)
+		assert.Equal(t, test.failed, err != nil)
+	}
+}
+
+func TestInstanceReadyWithoutStatus(t *testing.T) {
+	driver := NewCustomTestDriver(&fakeEC2Status{})
+	driver.InstanceId = "i-1234"
+
+	ready, err := driver.InstanceReady()
+
+	assert.NoError(t, err)
+	assert.False(t, ready)
+}
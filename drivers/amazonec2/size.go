@@ -0,0 +1,29 @@
+package amazonec2
+
+import (
+	"errors"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// SetSize sets the size of the root volume, rounded up to GB. The memory of
+// the instances comes with their instance type.
+func (d *Driver) SetSize(size drivers.Size) error {
+	if size.Memory != 0 {
+		return errors.New("The memory of EC2 instances is set by their instance type, see --amazonec2-instance-type")
+	}
+	if err := size.Check(drivers.Size{Disk: 1024}); err != nil {
+		return err
+	}
+
+	if size.Disk != 0 {
+		d.RootSize = int64(drivers.SizeGB(size.Disk))
+	}
+	return nil
+}
+
+// GetSize returns the size of the root volume, the memory of the instance
+// type being unknown to the driver.
+func (d *Driver) GetSize() (drivers.Size, error) {
+	return drivers.Size{Disk: int(d.RootSize) * 1024}, nil
+}
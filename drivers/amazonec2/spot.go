@@ -0,0 +1,142 @@
+package amazonec2
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+var (
+	spotRequestPollInterval = 5 * time.Second
+)
+
+// requestSpotInstance places a spot instance request and waits for it to be
+// fulfilled. The request is cancelled if it is not fulfilled in time.
+func (d *Driver) requestSpotInstance(req *ec2.RequestSpotInstancesInput) (*ec2.Instance, error) {
+	spotInstanceRequest, err := d.getClient().RequestSpotInstances(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error request spot instance: %s", err)
+	}
+
+	d.SpotInstanceRequestId = *spotInstanceRequest.SpotInstanceRequests[0].SpotInstanceRequestId
+	log.Infof("Created spot instance request %s, waiting for it to be fulfilled...", d.SpotInstanceRequestId)
+
+	instanceID, err := d.waitForSpotInstanceRequest()
+	if err != nil {
+		if cancelErr := d.cancelSpotInstanceRequest(); cancelErr != nil {
+			log.Warnf("Error cancelling spot instance request %s: %s", d.SpotInstanceRequestId, cancelErr)
+		}
+		return nil, err
+	}
+
+	// Even though the request is fulfilled, eventual consistency means EC2
+	// may not know about the instance yet. Try a few times just in case.
+	for i := 0; i < 3; i++ {
+		var instances *ec2.DescribeInstancesOutput
+		instances, err = d.getClient().DescribeInstances(&ec2.DescribeInstancesInput{
+			InstanceIds: []*string{&instanceID},
+		})
+		if err == nil {
+			return instances.Reservations[0].Instances[0], nil
+		}
+		time.Sleep(spotRequestPollInterval)
+	}
+
+	return nil, fmt.Errorf("Error resolving spot instance to real instance: %v", err)
+}
+
+// waitForSpotInstanceRequest polls the spot instance request until an
+// instance is launched for it, and returns the id of the instance.
+func (d *Driver) waitForSpotInstanceRequest() (string, error) {
+	var (
+		instanceID string
+		lastStatus string
+	)
+
+	maxAttempts := int(time.Duration(d.SpotRequestTimeout) * time.Second / spotRequestPollInterval)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	err := mcnutils.WaitForSpecificOrError(func() (bool, error) {
+		resp, err := d.getClient().DescribeSpotInstanceRequests(&ec2.DescribeSpotInstanceRequestsInput{
+			SpotInstanceRequestIds: []*string{&d.SpotInstanceRequestId},
+		})
+		if err != nil {
+			// The request may not be visible yet
+			if strings.HasPrefix(err.Error(), "InvalidSpotInstanceRequestID.NotFound") {
+				return false, nil
+			}
+			return false, fmt.Errorf("Error describing spot instance request: %s", err)
+		}
+
+		if len(resp.SpotInstanceRequests) == 0 {
+			return false, nil
+		}
+		spotRequest := resp.SpotInstanceRequests[0]
+
+		if spotRequest.Status != nil && spotRequest.Status.Code != nil && *spotRequest.Status.Code != lastStatus {
+			lastStatus = *spotRequest.Status.Code
+			log.Debugf("Spot instance request %s is %s", d.SpotInstanceRequestId, lastStatus)
+		}
+
+		switch aws.StringValue(spotRequest.State) {
+		case ec2.SpotInstanceStateActive:
+			if spotRequest.InstanceId == nil {
+				return false, nil
+			}
+			instanceID = *spotRequest.InstanceId
+			return true, nil
+		case ec2.SpotInstanceStateFailed, ec2.SpotInstanceStateCancelled, ec2.SpotInstanceStateClosed:
+			return false, fmt.Errorf("Spot instance request %s is %s: %s", d.SpotInstanceRequestId, *spotRequest.State, spotRequestMessage(spotRequest))
+		}
+
+		return false, nil
+	}, maxAttempts, spotRequestPollInterval)
+
+	if err != nil {
+		if lastStatus != "" {
+			return "", fmt.Errorf("Error fulfilling spot request (last status %s): %s", lastStatus, err)
+		}
+		return "", fmt.Errorf("Error fulfilling spot request: %s", err)
+	}
+
+	return instanceID, nil
+}
+
+// cancelSpotInstanceRequest cancels the spot instance request of the machine,
+// if any. It does not terminate the instance launched for it.
+func (d *Driver) cancelSpotInstanceRequest() error {
+	if d.SpotInstanceRequestId == "" {
+		return nil
+	}
+
+	log.Debugf("cancelling spot instance request: %s", d.SpotInstanceRequestId)
+	_, err := d.getClient().CancelSpotInstanceRequests(&ec2.CancelSpotInstanceRequestsInput{
+		SpotInstanceRequestIds: []*string{&d.SpotInstanceRequestId},
+	})
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "InvalidSpotInstanceRequestID.NotFound") {
+			log.Warn("Spot instance request does not exist, proceeding with removing local reference")
+			return nil
+		}
+		return fmt.Errorf("unable to cancel spot instance request: %s", err)
+	}
+
+	return nil
+}
+
+func spotRequestMessage(spotRequest *ec2.SpotInstanceRequest) string {
+	if spotRequest.Status != nil && spotRequest.Status.Message != nil {
+		return *spotRequest.Status.Message
+	}
+	if spotRequest.Fault != nil && spotRequest.Fault.Message != nil {
+		return *spotRequest.Fault.Message
+	}
+	return "no details"
+}
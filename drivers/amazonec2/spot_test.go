@@ -0,0 +1,73 @@
+package amazonec2
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/assert"
+)
+
+func withFastSpotPolling(f func()) {
+	defer func(saved time.Duration) { spotRequestPollInterval = saved }(spotRequestPollInterval)
+	spotRequestPollInterval = time.Millisecond
+
+	f()
+}
+
+func TestWaitForSpotInstanceRequestFulfilled(t *testing.T) {
+	client := &fakeEC2Spot{
+		states: []string{ec2.SpotInstanceStateOpen, ec2.SpotInstanceStateOpen, ec2.SpotInstanceStateActive},
+	}
+	driver := NewCustomTestDriver(client)
+	driver.SpotInstanceRequestId = "sir-12345"
+
+	withFastSpotPolling(func() {
+		instanceID, err := driver.waitForSpotInstanceRequest()
+
+		assert.NoError(t, err)
+		assert.Equal(t, "i-12345", instanceID)
+	})
+}
+
+func TestWaitForSpotInstanceRequestFailed(t *testing.T) {
+	client := &fakeEC2Spot{
+		states: []string{ec2.SpotInstanceStateOpen, ec2.SpotInstanceStateFailed},
+	}
+	driver := NewCustomTestDriver(client)
+	driver.SpotInstanceRequestId = "sir-12345"
+
+	withFastSpotPolling(func() {
+		_, err := driver.waitForSpotInstanceRequest()
+
+		assert.EqualError(t, err, "Error fulfilling spot request (last status pending-fulfillment): Spot instance request sir-12345 is failed: Your spot request is pending")
+	})
+}
+
+func TestWaitForSpotInstanceRequestTimeout(t *testing.T) {
+	client := &fakeEC2Spot{
+		states: []string{ec2.SpotInstanceStateOpen},
+	}
+	driver := NewCustomTestDriver(client)
+	driver.SpotInstanceRequestId = "sir-12345"
+	driver.SpotRequestTimeout = 0
+
+	withFastSpotPolling(func() {
+		_, err := driver.waitForSpotInstanceRequest()
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCancelSpotInstanceRequest(t *testing.T) {
+	client := &fakeEC2Spot{}
+	driver := NewCustomTestDriver(client)
+
+	assert.NoError(t, driver.cancelSpotInstanceRequest())
+	assert.Empty(t, client.cancelled)
+
+	driver.SpotInstanceRequestId = "sir-12345"
+
+	assert.NoError(t, driver.cancelSpotInstanceRequest())
+	assert.Equal(t, []string{"sir-12345"}, client.cancelled)
+}
@@ -3,6 +3,7 @@ package amazonec2
 import (
 	"errors"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/service/ec2"
 
@@ -147,3 +148,51 @@ func NewCustomTestDriver(ec2Client Ec2Client) *Driver {
 	}
 	return driver
 }
+
+type fakeEC2Spot struct {
+	*fakeEC2
+	states    []string
+	cancelled []string
+}
+
+func (f *fakeEC2Spot) DescribeSpotInstanceRequests(input *ec2.DescribeSpotInstanceRequestsInput) (*ec2.DescribeSpotInstanceRequestsOutput, error) {
+	state := f.states[0]
+	if len(f.states) > 1 {
+		f.states = f.states[1:]
+	}
+
+	spotRequest := &ec2.SpotInstanceRequest{
+		SpotInstanceRequestId: input.SpotInstanceRequestIds[0],
+		State:                 aws.String(state),
+		Status: &ec2.SpotInstanceStatus{
+			Code:    aws.String("pending-fulfillment"),
+			Message: aws.String("Your spot request is pending"),
+		},
+	}
+	if state == ec2.SpotInstanceStateActive {
+		spotRequest.InstanceId = aws.String("i-12345")
+	}
+
+	return &ec2.DescribeSpotInstanceRequestsOutput{
+		SpotInstanceRequests: []*ec2.SpotInstanceRequest{spotRequest},
+	}, nil
+}
+
+func (f *fakeEC2Spot) CancelSpotInstanceRequests(input *ec2.CancelSpotInstanceRequestsInput) (*ec2.CancelSpotInstanceRequestsOutput, error) {
+	for _, id := range input.SpotInstanceRequestIds {
+		f.cancelled = append(f.cancelled, *id)
+	}
+	return &ec2.CancelSpotInstanceRequestsOutput{}, nil
+}
+
+type fakeEC2Console struct {
+	*fakeEC2
+	output *string
+}
+
+func (f *fakeEC2Console) GetConsoleOutput(input *ec2.GetConsoleOutputInput) (*ec2.GetConsoleOutputOutput, error) {
+	return &ec2.GetConsoleOutputOutput{
+		InstanceId: input.InstanceId,
+		Output:     f.output,
+	}, nil
+}
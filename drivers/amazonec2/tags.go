@@ -0,0 +1,92 @@
+package amazonec2
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// SetResourceTags records the tags attached to the instance, its volumes and
+// the security groups created for it. EC2 cannot tag key pairs, the key pair
+// is only deleted when it was created for the machine, see ExistingKey.
+func (d *Driver) SetResourceTags(tags map[string]string) error {
+	d.ResourceTags = tags
+	return nil
+}
+
+// tagResources attaches the resource tags to the given resources.
+func (d *Driver) tagResources(ids ...*string) error {
+	if len(d.ResourceTags) == 0 || len(ids) == 0 {
+		return nil
+	}
+
+	keys := []string{}
+	for key := range d.ResourceTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tags := []*ec2.Tag{}
+	for _, key := range keys {
+		tags = append(tags, &ec2.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(d.ResourceTags[key]),
+		})
+	}
+
+	_, err := d.getClient().CreateTags(&ec2.CreateTagsInput{
+		Resources: ids,
+		Tags:      tags,
+	})
+	return err
+}
+
+// tagInstanceResources attaches the resource tags to the instance and to its
+// volumes.
+func (d *Driver) tagInstanceResources() error {
+	if len(d.ResourceTags) == 0 {
+		return nil
+	}
+
+	inst, err := d.getInstance()
+	if err != nil {
+		return err
+	}
+
+	ids := []*string{inst.InstanceId}
+	for _, mapping := range inst.BlockDeviceMappings {
+		if mapping.Ebs != nil && mapping.Ebs.VolumeId != nil {
+			ids = append(ids, mapping.Ebs.VolumeId)
+		}
+	}
+
+	return d.tagResources(ids...)
+}
+
+// checkInstanceTags returns an error unless the instance bears the resource
+// tags. Instances which no longer exist pass.
+func (d *Driver) checkInstanceTags() error {
+	if len(d.ResourceTags) == 0 || d.InstanceId == "" {
+		return nil
+	}
+
+	inst, err := d.getInstance()
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "InvalidInstanceID.NotFound") {
+			return nil
+		}
+		return err
+	}
+
+	actual := map[string]string{}
+	for _, tag := range inst.Tags {
+		if tag.Key != nil && tag.Value != nil {
+			actual[*tag.Key] = *tag.Value
+		}
+	}
+
+	return drivers.CheckResourceTags("instance "+d.InstanceId, d.ResourceTags, actual)
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/docker/machine/drivers/azure/azureutil"
 	"github.com/docker/machine/libmachine/drivers"
@@ -53,6 +54,7 @@ const (
 	flAzureNoPublicIP      = "azure-no-public-ip"
 	flAzureDNSLabel        = "azure-dns"
 	flAzureStorageType     = "azure-storage-type"
+	flAzureManagedDisks    = "azure-managed-disks"
 	flAzureCustomData      = "azure-custom-data"
 	flAzureClientID        = "azure-client-id"
 	flAzureClientSecret    = "azure-client-secret"
@@ -83,6 +85,7 @@ type Driver struct {
 	SubnetPrefix    string
 	AvailabilitySet string
 	StorageType     string
+	ManagedDisks    bool
 
 	OpenPorts      []string
 	PrivateIPAddr  string
@@ -202,6 +205,11 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			EnvVar: "AZURE_STORAGE_TYPE",
 			Value:  defaultStorageType,
 		},
+		mcnflag.BoolFlag{
+			Name:   flAzureManagedDisks,
+			Usage:  "Use a managed disk of the storage type for the OS disk instead of a VHD in a storage account",
+			EnvVar: "AZURE_MANAGED_DISKS",
+		},
 		mcnflag.BoolFlag{
 			Name:  flAzureUsePrivateIP,
 			Usage: "Use private IP address of the machine to connect",
@@ -276,6 +284,7 @@ func (d *Driver) SetConfigFromFlags(fl drivers.DriverOptions) error {
 	d.DockerPort = fl.Int(flAzureDockerPort)
 	d.DNSLabel = fl.String(flAzureDNSLabel)
 	d.CustomDataFile = fl.String(flAzureCustomData)
+	d.ManagedDisks = fl.Bool(flAzureManagedDisks)
 
 	d.ClientID = fl.String(flAzureClientID)
 	d.ClientSecret = fl.String(flAzureClientSecret)
@@ -299,6 +308,10 @@ func (d *Driver) PreCreateCheck() (err error) {
 		}
 	}
 
+	if d.ManagedDisks && !isManagedDiskStorageType(d.StorageType) {
+		return fmt.Errorf("Storage type %q cannot be used for managed disks, use one of: %s", d.StorageType, strings.Join(managedDiskStorageTypes, ", "))
+	}
+
 	c, err := d.newAzureClient()
 	if err != nil {
 		return err
@@ -360,8 +373,14 @@ func (d *Driver) Create() error {
 	if err := c.CreateResourceGroup(d.ResourceGroup, d.Location); err != nil {
 		return err
 	}
-	if err := c.CreateAvailabilitySetIfNotExists(d.ctx, d.ResourceGroup, d.AvailabilitySet, d.Location); err != nil {
-		return err
+	if d.ManagedDisks {
+		if err := c.CreateAlignedAvailabilitySetIfNotExists(d.ctx, d.ResourceGroup, d.AvailabilitySet, d.Location); err != nil {
+			return err
+		}
+	} else {
+		if err := c.CreateAvailabilitySetIfNotExists(d.ctx, d.ResourceGroup, d.AvailabilitySet, d.Location); err != nil {
+			return err
+		}
 	}
 	if err := c.CreateNetworkSecurityGroup(d.ctx, d.ResourceGroup, d.naming().NSG(), d.Location, d.ctx.FirewallRules); err != nil {
 		return err
@@ -384,10 +403,14 @@ func (d *Driver) Create() error {
 		d.ctx.PublicIPAddressID, d.ctx.SubnetID, d.ctx.NetworkSecurityGroupID, d.PrivateIPAddr); err != nil {
 		return err
 	}
-	if err := c.CreateStorageAccount(d.ctx, d.ResourceGroup, d.Location, storage.SkuName(d.StorageType)); err != nil {
+	if err := d.generateSSHKey(d.ctx); err != nil {
 		return err
 	}
-	if err := d.generateSSHKey(d.ctx); err != nil {
+	if d.ManagedDisks {
+		return c.CreateManagedDiskVirtualMachine(d.ResourceGroup, d.naming().VM(), d.Location, d.Size, d.ctx.AvailabilitySetID,
+			d.ctx.NetworkInterfaceID, d.BaseDriver.SSHUser, d.ctx.SSHPublicKey, d.Image, customData, d.StorageType)
+	}
+	if err := c.CreateStorageAccount(d.ctx, d.ResourceGroup, d.Location, storage.SkuName(d.StorageType)); err != nil {
 		return err
 	}
 	err = c.CreateVirtualMachine(d.ResourceGroup, d.naming().VM(), d.Location, d.Size, d.ctx.AvailabilitySetID,
@@ -428,11 +451,14 @@ func (d *Driver) Remove() error {
 	if err := c.CleanupAvailabilitySetIfExists(d.ResourceGroup, d.AvailabilitySet); err != nil {
 		return err
 	}
-	if err := c.CleanupSubnetIfExists(d.ResourceGroup, d.VirtualNetwork, d.SubnetName); err != nil {
+	vnetResourceGroup, vNetName := parseVirtualNetwork(d.VirtualNetwork, d.ResourceGroup)
+	if err := c.CleanupSubnetIfExists(vnetResourceGroup, vNetName, d.SubnetName); err != nil {
 		return err
 	}
-	err = c.CleanupVirtualNetworkIfExists(d.ResourceGroup, d.VirtualNetwork)
-	return err
+	if err := c.CleanupVirtualNetworkIfExists(vnetResourceGroup, vNetName); err != nil {
+		return err
+	}
+	return c.CleanupResourceGroupIfExists(d.ResourceGroup)
 }
 
 // GetIP returns public IP address or hostname of the machine instance.
@@ -26,6 +26,8 @@ const (
 	fmtOSDiskContainer       = "vhd-%s" // place vhds of VMs in separate containers for ease of cleanup
 	fmtOSDiskBlobName        = "%s-os-disk.vhd"
 	fmtOSDiskResourceName    = "%s-os-disk"
+	createdByTag             = "created-by" // marks the resource groups docker-machine may remove
+	createdByTagValue        = "docker-machine"
 	defaultStorageAPIVersion = blobstorage.DefaultAPIVersion
 )
 
@@ -104,10 +106,47 @@ func (a AzureClient) CreateResourceGroup(name, location string) error {
 	_, err := a.resourceGroupsClient().CreateOrUpdate(name,
 		resources.ResourceGroup{
 			Location: to.StringPtr(location),
+			Tags: &map[string]*string{
+				createdByTag: to.StringPtr(createdByTagValue),
+			},
 		})
 	return err
 }
 
+// CleanupResourceGroupIfExists removes a resource group created by
+// CreateResourceGroup once there are no resources left in it. Resource groups
+// which existed beforehand are left alone.
+func (a AzureClient) CleanupResourceGroupIfExists(name string) error {
+	f := logutil.Fields{"name": name}
+	log.Info("Attempting to clean up Resource Group resource...", f)
+
+	rg, err := a.resourceGroupsClient().Get(name)
+	if exists, err := checkResourceExistsFromError(err); err != nil {
+		return err
+	} else if !exists {
+		log.Debug("Resource Group resource does not exist. Skipping.", f)
+		return nil
+	}
+
+	if rg.Tags == nil || to.String((*rg.Tags)[createdByTag]) != createdByTagValue {
+		log.Info("Resource Group was not created by docker-machine, skipping removal.", f)
+		return nil
+	}
+
+	l, err := a.resourceGroupsClient().ListResources(name, "", "", to.Int32Ptr(1))
+	if err != nil {
+		return err
+	}
+	if l.Value != nil && len(*l.Value) > 0 {
+		log.Info("Resource Group still contains other resources, skipping removal.", f)
+		return nil
+	}
+
+	log.Info("Removing Resource Group resource...", f)
+	_, err = a.resourceGroupsClient().Delete(name, nil)
+	return err
+}
+
 func (a AzureClient) resourceGroupExists(name string) (bool, error) {
 	log.Info("Querying existing resource group.", logutil.Fields{"name": name})
 	_, err := a.resourceGroupsClient().Get(name)
@@ -429,11 +468,15 @@ func (a AzureClient) DeleteVirtualMachineIfExists(resourceGroup, name string) er
 	}
 
 	// Remove disk
-	if vmRef.Properties != nil {
-		vhdURL := to.String(vmRef.Properties.StorageProfile.OsDisk.Vhd.URI)
-		return a.removeOSDiskBlob(resourceGroup, name, vhdURL)
+	if vmRef.Properties == nil || vmRef.Properties.StorageProfile == nil || vmRef.Properties.StorageProfile.OsDisk == nil {
+		return nil
 	}
-	return nil
+	osDisk := vmRef.Properties.StorageProfile.OsDisk
+	if osDisk.Vhd == nil {
+		// Managed disks are resources of their own instead of blobs
+		return a.DeleteManagedDiskIfExists(resourceGroup, osDiskResourceName(name))
+	}
+	return a.removeOSDiskBlob(resourceGroup, name, to.String(osDisk.Vhd.URI))
 }
 
 func (a AzureClient) removeOSDiskBlob(resourceGroup, vmName, vhdURL string) error {
@@ -511,32 +554,8 @@ func (a AzureClient) CreateVirtualMachine(resourceGroup, name, location, size, a
 		return err
 	}
 
-	var (
-		osDiskBlobURL = osDiskStorageBlobURL(storageAccount, name)
-		sshKeyPath    = fmt.Sprintf("/home/%s/.ssh/authorized_keys", username)
-	)
+	osDiskBlobURL := osDiskStorageBlobURL(storageAccount, name)
 	log.Debugf("OS disk blob will be placed at: %s", osDiskBlobURL)
-	log.Debugf("SSH key will be placed at: %s", sshKeyPath)
-
-	var osProfile = &compute.OSProfile{
-		ComputerName:  to.StringPtr(name),
-		AdminUsername: to.StringPtr(username),
-		LinuxConfiguration: &compute.LinuxConfiguration{
-			DisablePasswordAuthentication: to.BoolPtr(true),
-			SSH: &compute.SSHConfiguration{
-				PublicKeys: &[]compute.SSHPublicKey{
-					{
-						Path:    to.StringPtr(sshKeyPath),
-						KeyData: to.StringPtr(sshPublicKey),
-					},
-				},
-			},
-		},
-	}
-
-	if customData != "" {
-		osProfile.CustomData = to.StringPtr(customData)
-	}
 
 	_, err = a.virtualMachinesClient().CreateOrUpdate(resourceGroup, name,
 		compute.VirtualMachine{
@@ -555,7 +574,7 @@ func (a AzureClient) CreateVirtualMachine(resourceGroup, name, location, size, a
 						},
 					},
 				},
-				OsProfile: osProfile,
+				OsProfile: linuxOSProfile(name, username, sshPublicKey, customData),
 				StorageProfile: &compute.StorageProfile{
 					ImageReference: &compute.ImageReference{
 						Publisher: to.StringPtr(img.publisher),
@@ -564,7 +583,7 @@ func (a AzureClient) CreateVirtualMachine(resourceGroup, name, location, size, a
 						Version:   to.StringPtr(img.version),
 					},
 					OsDisk: &compute.OSDisk{
-						Name:         to.StringPtr(fmt.Sprintf(fmtOSDiskResourceName, name)),
+						Name:         to.StringPtr(osDiskResourceName(name)),
 						Caching:      compute.ReadWrite,
 						CreateOption: compute.FromImage,
 						Vhd: &compute.VirtualHardDisk{
@@ -577,6 +596,35 @@ func (a AzureClient) CreateVirtualMachine(resourceGroup, name, location, size, a
 	return err
 }
 
+// linuxOSProfile gives the OS profile of a machine which only accepts SSH
+// logins with the given public key.
+func linuxOSProfile(name, username, sshPublicKey, customData string) *compute.OSProfile {
+	sshKeyPath := fmt.Sprintf("/home/%s/.ssh/authorized_keys", username)
+	log.Debugf("SSH key will be placed at: %s", sshKeyPath)
+
+	osProfile := &compute.OSProfile{
+		ComputerName:  to.StringPtr(name),
+		AdminUsername: to.StringPtr(username),
+		LinuxConfiguration: &compute.LinuxConfiguration{
+			DisablePasswordAuthentication: to.BoolPtr(true),
+			SSH: &compute.SSHConfiguration{
+				PublicKeys: &[]compute.SSHPublicKey{
+					{
+						Path:    to.StringPtr(sshKeyPath),
+						KeyData: to.StringPtr(sshPublicKey),
+					},
+				},
+			},
+		},
+	}
+
+	if customData != "" {
+		osProfile.CustomData = to.StringPtr(customData)
+	}
+
+	return osProfile
+}
+
 func (a AzureClient) GetVirtualMachinePowerState(resourceGroup, name string) (VMPowerState, error) {
 	log.Debug("Querying instance view for power state.")
 	vm, err := a.virtualMachinesClient().Get(resourceGroup, name, "instanceView")
@@ -778,6 +826,9 @@ func osDiskStorageBlobURL(account *storage.AccountProperties, vmName string) str
 	return containerURL + blobName
 }
 
+// osDiskResourceName returns the name of the OS disk of the VM.
+func osDiskResourceName(vm string) string { return fmt.Sprintf(fmtOSDiskResourceName, vm) }
+
 // osDiskStorageContainerName returns the container name the OS disk for the VM
 // should be saved.
 func osDiskStorageContainerName(vm string) string { return fmt.Sprintf(fmtOSDiskContainer, vm) }
@@ -0,0 +1,203 @@
+package azureutil
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/arm/compute"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
+	"github.com/Azure/go-autorest/autorest/to"
+	"github.com/docker/machine/drivers/azure/logutil"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	// managedDisksAPIVersion is the first stable Compute API version with
+	// managed disks. The vendored compute SDK predates it, hence the models
+	// and requests below.
+	managedDisksAPIVersion = "2017-03-30"
+
+	// Availability sets of machines with managed disks have to be aligned
+	// with the fault domains of the storage.
+	alignedAvailabilitySetSku           = "Aligned"
+	alignedAvailabilitySetFaultDomains  = 2
+	alignedAvailabilitySetUpdateDomains = 5
+)
+
+type managedDisk struct {
+	StorageAccountType string `json:"storageAccountType,omitempty"`
+}
+
+type managedOSDisk struct {
+	Name         *string                       `json:"name,omitempty"`
+	Caching      compute.CachingTypes          `json:"caching,omitempty"`
+	CreateOption compute.DiskCreateOptionTypes `json:"createOption,omitempty"`
+	ManagedDisk  *managedDisk                  `json:"managedDisk,omitempty"`
+}
+
+type managedStorageProfile struct {
+	ImageReference *compute.ImageReference `json:"imageReference,omitempty"`
+	OsDisk         *managedOSDisk          `json:"osDisk,omitempty"`
+}
+
+type managedVirtualMachineProperties struct {
+	AvailabilitySet *compute.SubResource     `json:"availabilitySet,omitempty"`
+	HardwareProfile *compute.HardwareProfile `json:"hardwareProfile,omitempty"`
+	NetworkProfile  *compute.NetworkProfile  `json:"networkProfile,omitempty"`
+	OsProfile       *compute.OSProfile       `json:"osProfile,omitempty"`
+	StorageProfile  *managedStorageProfile   `json:"storageProfile,omitempty"`
+}
+
+type managedVirtualMachine struct {
+	Location   *string                          `json:"location,omitempty"`
+	Properties *managedVirtualMachineProperties `json:"properties,omitempty"`
+}
+
+type alignedAvailabilitySet struct {
+	ID         *string                            `json:"id,omitempty"`
+	Location   *string                            `json:"location,omitempty"`
+	Sku        *compute.Sku                       `json:"sku,omitempty"`
+	Properties *compute.AvailabilitySetProperties `json:"properties,omitempty"`
+}
+
+// CreateAlignedAvailabilitySetIfNotExists is the counterpart of
+// CreateAvailabilitySetIfNotExists for machines with managed disks.
+func (a AzureClient) CreateAlignedAvailabilitySetIfNotExists(ctx *DeploymentContext, resourceGroup, name, location string) error {
+	f := logutil.Fields{"name": name}
+	log.Info("Configuring aligned availability set.", f)
+
+	var as alignedAvailabilitySet
+	err := a.computeRequest("PUT", resourceGroup, "availabilitySets", name,
+		alignedAvailabilitySet{
+			Location: to.StringPtr(location),
+			Sku:      &compute.Sku{Name: to.StringPtr(alignedAvailabilitySetSku)},
+			Properties: &compute.AvailabilitySetProperties{
+				PlatformFaultDomainCount:  to.Int32Ptr(alignedAvailabilitySetFaultDomains),
+				PlatformUpdateDomainCount: to.Int32Ptr(alignedAvailabilitySetUpdateDomains),
+			},
+		}, &as)
+	ctx.AvailabilitySetID = to.String(as.ID)
+	return err
+}
+
+// CreateManagedDiskVirtualMachine creates a virtual machine whose OS disk is
+// a managed disk of the given storage account type, e.g. Premium_LRS, rather
+// than a VHD blob in a storage account.
+func (a AzureClient) CreateManagedDiskVirtualMachine(resourceGroup, name, location, size, availabilitySetID, networkInterfaceID,
+	username, sshPublicKey, imageName, customData, storageType string) error {
+	log.Info("Creating virtual machine with a managed disk.", logutil.Fields{
+		"name":        name,
+		"location":    location,
+		"size":        size,
+		"username":    username,
+		"osImage":     imageName,
+		"storageType": storageType,
+	})
+
+	img, err := parseImageName(imageName)
+	if err != nil {
+		return err
+	}
+
+	return a.computeRequest("PUT", resourceGroup, "virtualMachines", name,
+		managedVirtualMachine{
+			Location: to.StringPtr(location),
+			Properties: &managedVirtualMachineProperties{
+				AvailabilitySet: &compute.SubResource{
+					ID: to.StringPtr(availabilitySetID),
+				},
+				HardwareProfile: &compute.HardwareProfile{
+					VMSize: compute.VirtualMachineSizeTypes(size),
+				},
+				NetworkProfile: &compute.NetworkProfile{
+					NetworkInterfaces: &[]compute.NetworkInterfaceReference{
+						{
+							ID: to.StringPtr(networkInterfaceID),
+						},
+					},
+				},
+				OsProfile: linuxOSProfile(name, username, sshPublicKey, customData),
+				StorageProfile: &managedStorageProfile{
+					ImageReference: &compute.ImageReference{
+						Publisher: to.StringPtr(img.publisher),
+						Offer:     to.StringPtr(img.offer),
+						Sku:       to.StringPtr(img.sku),
+						Version:   to.StringPtr(img.version),
+					},
+					OsDisk: &managedOSDisk{
+						Name:         to.StringPtr(osDiskResourceName(name)),
+						Caching:      compute.ReadWrite,
+						CreateOption: compute.FromImage,
+						ManagedDisk: &managedDisk{
+							StorageAccountType: storageType,
+						},
+					},
+				},
+			},
+		}, nil)
+}
+
+// DeleteManagedDiskIfExists deletes a managed disk. Azure keeps the disks of
+// a deleted virtual machine, so this has to be done once the machine is gone.
+func (a AzureClient) DeleteManagedDiskIfExists(resourceGroup, name string) error {
+	return deleteResourceIfExists("Managed Disk", name,
+		func() error { return a.computeRequest("GET", resourceGroup, "disks", name, nil, nil) },
+		func() (autorest.Response, error) {
+			return autorest.Response{}, a.computeRequest("DELETE", resourceGroup, "disks", name, nil, nil)
+		})
+}
+
+// computeRequest sends a request about a resource of the Compute provider
+// using managedDisksAPIVersion and waits for the operation to complete. The
+// body, if any, is sent as JSON and the response is decoded into result, if
+// not nil.
+func (a AzureClient) computeRequest(method, resourceGroup, resourceType, name string, body, result interface{}) error {
+	c := a.virtualMachinesClient()
+
+	pathParameters := map[string]interface{}{
+		"resourceGroupName": autorest.Encode("path", resourceGroup),
+		"subscriptionId":    autorest.Encode("path", a.subscriptionID),
+		"resourceType":      autorest.Encode("path", resourceType),
+		"name":              autorest.Encode("path", name),
+	}
+	queryParameters := map[string]interface{}{
+		"api-version": managedDisksAPIVersion,
+	}
+
+	decorators := []autorest.PrepareDecorator{
+		autorest.WithMethod(method),
+		autorest.WithBaseURL(c.BaseURI),
+		autorest.WithPathParameters("/subscriptions/{subscriptionId}/resourceGroups/{resourceGroupName}/providers/Microsoft.Compute/{resourceType}/{name}", pathParameters),
+		autorest.WithQueryParameters(queryParameters),
+	}
+	if body != nil {
+		decorators = append(decorators, autorest.AsJSON(), autorest.WithJSON(body))
+	}
+
+	operation := fmt.Sprintf("%s %s", method, resourceType)
+
+	req, err := autorest.Prepare(&http.Request{}, decorators...)
+	if err != nil {
+		return autorest.NewErrorWithError(err, "azureutil.AzureClient", operation, nil, "Failure preparing request")
+	}
+
+	resp, err := autorest.SendWithSender(c, req, azure.DoPollForAsynchronous(c.PollingDelay))
+	if err != nil {
+		return autorest.NewErrorWithError(err, "azureutil.AzureClient", operation, resp, "Failure sending request")
+	}
+
+	responders := []autorest.RespondDecorator{
+		c.ByInspecting(),
+		azure.WithErrorUnlessStatusCode(http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent),
+	}
+	if result != nil {
+		responders = append(responders, autorest.ByUnmarshallingJSON(result))
+	}
+	responders = append(responders, autorest.ByClosing())
+
+	if err := autorest.Respond(resp, responders...); err != nil {
+		return autorest.NewErrorWithError(err, "azureutil.AzureClient", operation, resp, "Failure responding to request")
+	}
+	return nil
+}
@@ -8,17 +8,26 @@ import (
 	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/Azure/azure-sdk-for-go/arm/storage"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/docker/machine/drivers/azure/azureutil"
 	"github.com/docker/machine/drivers/azure/logutil"
 	"github.com/docker/machine/drivers/driverutil"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 )
 
 var (
+	// managedDiskStorageTypes are the storage types available to managed
+	// disks, a subset of the storage account types.
+	managedDiskStorageTypes = []string{
+		string(storage.StandardLRS),
+		string(storage.PremiumLRS),
+	}
+
 	environments = map[string]azure.Environment{
 		azure.PublicCloud.Name:       azure.PublicCloud,
 		azure.USGovernmentCloud.Name: azure.USGovernmentCloud,
@@ -89,6 +98,33 @@ func (d *Driver) generateSSHKey(ctx *azureutil.DeploymentContext) error {
 	return err
 }
 
+// SetFirewallRules has the network security group of the machine open the
+// engine port, as the docker port, and the other rules on top of the open
+// ports. The RequiredFirewallRules come first: SSH, whose port the driver
+// opens itself, then the engine port.
+func (d *Driver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	if len(rules) < 2 {
+		return fmt.Errorf("Expected the SSH and engine ports first, got %v", rules)
+	}
+
+	d.DockerPort = rules[1].Port
+	for _, rule := range rules[2:] {
+		d.OpenPorts = append(d.OpenPorts, rule.String())
+	}
+
+	return nil
+}
+
+// OpenPort is not supported, the ports are only opened at creation.
+func (d *Driver) OpenPort(rule drivers.FirewallRule) error {
+	return drivers.ErrNotImplemented
+}
+
+// ClosePort is not supported, the ports are only opened at creation.
+func (d *Driver) ClosePort(rule drivers.FirewallRule) error {
+	return drivers.ErrNotImplemented
+}
+
 // getSecurityRules creates network security group rules based on driver
 // configuration such as SSH port, docker port and swarm port.
 func (d *Driver) getSecurityRules(extraPorts []string) (*[]network.SecurityRule, error) {
@@ -228,3 +264,14 @@ func parseSecurityRuleProtocol(proto string) (network.SecurityRuleProtocol, erro
 		return "", fmt.Errorf("invalid protocol %s", proto)
 	}
 }
+
+// isManagedDiskStorageType tells if a storage type can be used for managed
+// disks.
+func isManagedDiskStorageType(storageType string) bool {
+	for _, t := range managedDiskStorageTypes {
+		if t == storageType {
+			return true
+		}
+	}
+	return false
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/arm/network"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,3 +30,22 @@ func TestParseSecurityRuleProtocol(t *testing.T) {
 		}
 	}
 }
+
+func TestIsManagedDiskStorageType(t *testing.T) {
+	assert.True(t, isManagedDiskStorageType("Standard_LRS"))
+	assert.True(t, isManagedDiskStorageType("Premium_LRS"))
+	assert.False(t, isManagedDiskStorageType("Standard_GRS"))
+	assert.False(t, isManagedDiskStorageType(""))
+}
+
+func TestSetFirewallRules(t *testing.T) {
+	d := NewDriver("default", "path").(*Driver)
+	d.DockerPort = defaultDockerPort
+	d.OpenPorts = []string{"80"}
+
+	err := d.SetFirewallRules(append(drivers.RequiredFirewallRules(2377), drivers.FirewallRule{Protocol: "udp", Port: 53}))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2377, d.DockerPort)
+	assert.Equal(t, []string{"80", "53/udp"}, d.OpenPorts)
+}
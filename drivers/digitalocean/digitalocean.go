@@ -7,16 +7,20 @@ import (
 	"net"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/digitalocean/godo"
+	"github.com/docker/machine/libmachine/credentials"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
 	"github.com/docker/machine/libmachine/mcnutils"
 	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/throttle"
 	"golang.org/x/oauth2"
 )
 
@@ -32,10 +36,16 @@ type Driver struct {
 	SSHKey            string
 	Size              string
 	IPv6              bool
+	IPv6Address       string
 	Backups           bool
 	PrivateNetworking bool
+	PrivateIPAddress  string
 	UserDataFile      string
 	Tags              string
+
+	// ResourceTags are attached to the droplet as key:value tags, see
+	// SetResourceTags.
+	ResourceTags map[string]string `json:",omitempty"`
 }
 
 const (
@@ -46,6 +56,8 @@ const (
 	defaultSize    = "512mb"
 )
 
+var invalidTagChars = regexp.MustCompile(`[^a-zA-Z0-9:_-]`)
+
 // GetCreateFlags registers the flags this driver adds to
 // "docker hosts create"
 func (d *Driver) GetCreateFlags() []mcnflag.Flag {
@@ -161,8 +173,8 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 
 	d.SetSwarmConfigFromFlags(flags)
 
-	if d.AccessToken == "" {
-		return fmt.Errorf("digitalocean driver requires the --digitalocean-access-token option")
+	if _, err := d.getAccessToken(); err != nil {
+		return fmt.Errorf("digitalocean driver requires the --digitalocean-access-token option or an access-token credential: %s", err)
 	}
 
 	return nil
@@ -190,13 +202,58 @@ func (d *Driver) PreCreateCheck() error {
 	if err != nil {
 		return err
 	}
+	validRegion := false
 	for _, region := range regions {
 		if region.Slug == d.Region {
-			return nil
+			validRegion = true
+			break
 		}
 	}
+	if !validRegion {
+		return fmt.Errorf("digitalocean requires a valid region")
+	}
+
+	if _, _, err := client.Images.GetBySlug(context.TODO(), d.Image); err != nil {
+		return fmt.Errorf("image %q not found: %s", d.Image, err)
+	}
 
-	return fmt.Errorf("digitalocean requires a valid region")
+	return d.checkDropletLimit(client)
+}
+
+// checkDropletLimit checks that the account is active and has room for
+// another droplet.
+func (d *Driver) checkDropletLimit(client *godo.Client) error {
+	account, _, err := client.Account.Get(context.TODO())
+	if err != nil {
+		return err
+	}
+	if account.Status == "locked" {
+		return fmt.Errorf("the digitalocean account is locked: %s", account.StatusMessage)
+	}
+	if account.DropletLimit <= 0 {
+		return nil
+	}
+
+	droplets := 0
+	opt := &godo.ListOptions{PerPage: 200}
+	for {
+		page, resp, err := client.Droplets.List(context.TODO(), opt)
+		if err != nil {
+			return err
+		}
+		droplets += len(page)
+
+		if resp.Links == nil || resp.Links.IsLastPage() {
+			break
+		}
+		current, err := resp.Links.CurrentPage()
+		if err != nil {
+			return err
+		}
+		opt.Page = current + 1
+	}
+
+	return drivers.CheckQuota("droplets", float64(account.DropletLimit), float64(droplets), 1)
 }
 
 func (d *Driver) Create() error {
@@ -249,8 +306,16 @@ func (d *Driver) Create() error {
 			return err
 		}
 		for _, network := range newDroplet.Networks.V4 {
-			if network.Type == "public" {
+			switch network.Type {
+			case "public":
 				d.IPAddress = network.IPAddress
+			case "private":
+				d.PrivateIPAddress = network.IPAddress
+			}
+		}
+		for _, network := range newDroplet.Networks.V6 {
+			if network.Type == "public" {
+				d.IPv6Address = network.IPAddress
 			}
 		}
 
@@ -323,6 +388,38 @@ func (d *Driver) GetURL() (string, error) {
 	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, "2376")), nil
 }
 
+// GetIPs returns the public IPv4 address of the droplet, followed by its
+// public IPv6 address when IPv6 is enabled.
+func (d *Driver) GetIPs() ([]string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return nil, err
+	}
+
+	ips := []string{ip}
+	if d.IPv6Address != "" {
+		ips = append(ips, d.IPv6Address)
+	}
+
+	return ips, nil
+}
+
+// GetPrivateIP returns the address of the droplet on the private network of
+// its region, or an empty string when private networking is not enabled.
+func (d *Driver) GetPrivateIP() (string, error) {
+	if !d.PrivateNetworking || d.PrivateIPAddress != "" {
+		return d.PrivateIPAddress, nil
+	}
+
+	// Droplets created before the address was recorded
+	droplet, _, err := d.getClient().Droplets.Get(context.TODO(), d.DropletID)
+	if err != nil {
+		return "", err
+	}
+
+	return droplet.PrivateIPv4()
+}
+
 func (d *Driver) GetState() (state.State, error) {
 	droplet, _, err := d.getClient().Droplets.Get(context.TODO(), d.DropletID)
 	if err != nil {
@@ -339,6 +436,23 @@ func (d *Driver) GetState() (state.State, error) {
 	return state.None, nil
 }
 
+// InstanceExists tells whether the droplet still exists.
+func (d *Driver) InstanceExists() (bool, error) {
+	if d.DropletID == 0 {
+		return false, nil
+	}
+
+	_, resp, err := d.getClient().Droplets.Get(context.TODO(), d.DropletID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (d *Driver) Start() error {
 	_, _, err := d.getClient().DropletActions.PowerOn(context.TODO(), d.DropletID)
 	return err
@@ -360,6 +474,10 @@ func (d *Driver) Kill() error {
 }
 
 func (d *Driver) Remove() error {
+	if err := d.checkDropletTags(); err != nil {
+		return err
+	}
+
 	client := d.getClient()
 	if d.SSHKeyFingerprint == "" {
 		if resp, err := client.Keys.DeleteByID(context.TODO(), d.SSHKeyID); err != nil {
@@ -380,10 +498,23 @@ func (d *Driver) Remove() error {
 	return nil
 }
 
+// getAccessToken returns the token given on the command line or, failing
+// that, the one found in a credential source. The latter is never stored on
+// the driver so that it does not end up in config.json.
+func (d *Driver) getAccessToken() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "access-token", d.AccessToken)
+}
+
 func (d *Driver) getClient() *godo.Client {
-	token := &oauth2.Token{AccessToken: d.AccessToken}
+	accessToken, err := d.getAccessToken()
+	if err != nil {
+		log.Warnf("Unable to find a Digital Ocean access token: %s", err)
+	}
+
+	token := &oauth2.Token{AccessToken: accessToken}
 	tokenSource := oauth2.StaticTokenSource(token)
-	client := oauth2.NewClient(oauth2.NoContext, tokenSource)
+	ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, throttle.NewClient("digitalocean"))
+	client := oauth2.NewClient(ctx, tokenSource)
 
 	return godo.NewClient(client)
 }
@@ -398,9 +529,64 @@ func (d *Driver) getTags() []string {
 		}
 	}
 
+	return append(tagList, d.resourceTagList()...)
+}
+
+// SetResourceTags records the tags attached to the droplet. DigitalOcean
+// cannot tag SSH keys, the key is only deleted when it was created for the
+// machine.
+func (d *Driver) SetResourceTags(tags map[string]string) error {
+	d.ResourceTags = tags
+	return nil
+}
+
+// resourceTagList returns the resource tags as droplet tags, which only
+// allow letters, digits, colons, dashes and underscores.
+func (d *Driver) resourceTagList() []string {
+	keys := []string{}
+	for key := range d.ResourceTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	tagList := []string{}
+	for _, key := range keys {
+		tagList = append(tagList, invalidTagChars.ReplaceAllString(key+":"+d.ResourceTags[key], "_"))
+	}
+
 	return tagList
 }
 
+// checkDropletTags returns an error unless the droplet bears the resource
+// tags. Droplets which no longer exist pass.
+func (d *Driver) checkDropletTags() error {
+	expected := d.resourceTagList()
+	if len(expected) == 0 {
+		return nil
+	}
+
+	droplet, resp, err := d.getClient().Droplets.Get(context.TODO(), d.DropletID)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil
+		}
+		return err
+	}
+
+	actual := map[string]bool{}
+	for _, tag := range droplet.Tags {
+		actual[tag] = true
+	}
+
+	for _, tag := range expected {
+		if !actual[tag] {
+			return fmt.Errorf("Refusing to delete droplet %d: it is not tagged %s, it may not have been created for this machine", d.DropletID, tag)
+		}
+	}
+
+	return nil
+}
+
 func (d *Driver) GetSSHKeyPath() string {
 	if d.SSHKey != "" {
 		d.SSHKeyPath = d.ResolveStorePath(path.Base(d.SSHKey))
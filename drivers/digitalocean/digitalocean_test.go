@@ -1,6 +1,7 @@
 package digitalocean
 
 import (
+	"os"
 	"testing"
 
 	"github.com/docker/machine/libmachine/drivers"
@@ -113,3 +114,44 @@ func TestTagsEmpty(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, driver.getTags())
 }
+
+func TestAccessTokenFromCredentialSourceIsNotPersisted(t *testing.T) {
+	defer os.Unsetenv("DIGITALOCEAN_ACCESS_TOKEN")
+	os.Setenv("DIGITALOCEAN_ACCESS_TOKEN", "TOKEN")
+
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+	assert.NoError(t, err)
+
+	assert.Empty(t, driver.AccessToken)
+
+	token, err := driver.getAccessToken()
+	assert.NoError(t, err)
+	assert.Equal(t, "TOKEN", token)
+}
+
+func TestGetPrivateIPWithoutPrivateNetworking(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	ip, err := driver.GetPrivateIP()
+
+	assert.NoError(t, err)
+	assert.Empty(t, ip)
+}
+
+func TestGetPrivateIPRecorded(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.PrivateNetworking = true
+	driver.PrivateIPAddress = "10.132.0.2"
+
+	ip, err := driver.GetPrivateIP()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.132.0.2", ip)
+}
@@ -1,15 +1,19 @@
 package exoscale
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
+	"net/url"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/docker/machine/libmachine/credentials"
 	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
 	"github.com/docker/machine/libmachine/mcnutils"
@@ -165,13 +169,39 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	if d.URL == "" {
 		d.URL = "https://api.exoscale.ch/compute"
 	}
-	if d.APIKey == "" || d.APISecretKey == "" {
+	if _, _, err := d.getAPIKeys(); err != nil {
 		return errors.New("missing an API key (--exoscale-api-key) or API secret key (--exoscale-api-secret-key)")
 	}
 
 	return nil
 }
 
+// getAPIKeys returns the API keys given on the command line or, failing that,
+// the ones found in a credential source. The latter are never stored on the
+// driver so that they do not end up in config.json.
+func (d *Driver) getAPIKeys() (string, string, error) {
+	apiKey, err := credentials.Lookup(d.StorePath, d.DriverName(), "api-key", d.APIKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	apiSecretKey, err := credentials.Lookup(d.StorePath, d.DriverName(), "api-secret-key", d.APISecretKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return apiKey, apiSecretKey, nil
+}
+
+func (d *Driver) client() *egoscale.Client {
+	apiKey, apiSecretKey, err := d.getAPIKeys()
+	if err != nil {
+		log.Warnf("Unable to find the Exoscale API keys: %s", err)
+	}
+
+	return egoscale.NewClient(d.URL, apiKey, apiSecretKey)
+}
+
 func (d *Driver) PreCreateCheck() error {
 	if d.UserDataFile != "" {
 		if _, err := os.Stat(d.UserDataFile); os.IsNotExist(err) {
@@ -196,7 +226,7 @@ func (d *Driver) GetURL() (string, error) {
 }
 
 func (d *Driver) GetState() (state.State, error) {
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 	vm, err := client.GetVirtualMachine(d.ID)
 	if err != nil {
 		return state.Error, err
@@ -226,6 +256,29 @@ func (d *Driver) GetState() (state.State, error) {
 	return state.None, nil
 }
 
+// SetFirewallRules checks that the rules are the ones of the security groups
+// the driver creates, as it does not change the existing groups: SSH and the
+// default engine port.
+func (d *Driver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	for _, rule := range rules {
+		if !drivers.IsRequiredFirewallRule(rule, engine.DefaultPort) {
+			return fmt.Errorf("The exoscale driver cannot open the port %s, only 22/tcp and %d/tcp", rule, engine.DefaultPort)
+		}
+	}
+
+	return nil
+}
+
+// OpenPort is not supported, the security groups are left as they are.
+func (d *Driver) OpenPort(rule drivers.FirewallRule) error {
+	return drivers.ErrNotImplemented
+}
+
+// ClosePort is not supported, the security groups are left as they are.
+func (d *Driver) ClosePort(rule drivers.FirewallRule) error {
+	return drivers.ErrNotImplemented
+}
+
 func (d *Driver) createDefaultSecurityGroup(client *egoscale.Client, group string) (string, error) {
 	rules := []egoscale.SecurityGroupRule{
 		{
@@ -294,7 +347,7 @@ func (d *Driver) Create() error {
 	}
 
 	log.Infof("Querying exoscale for the requested parameters...")
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 	topology, err := client.GetTopology()
 	if err != nil {
 		return err
@@ -405,7 +458,7 @@ func (d *Driver) Create() error {
 }
 
 func (d *Driver) Start() error {
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 
 	svmresp, err := client.StartVirtualMachine(d.ID)
 	if err != nil {
@@ -416,7 +469,7 @@ func (d *Driver) Start() error {
 }
 
 func (d *Driver) Stop() error {
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 
 	svmresp, err := client.StopVirtualMachine(d.ID)
 	if err != nil {
@@ -427,7 +480,7 @@ func (d *Driver) Stop() error {
 }
 
 func (d *Driver) Restart() error {
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 
 	svmresp, err := client.RebootVirtualMachine(d.ID)
 	if err != nil {
@@ -437,12 +490,30 @@ func (d *Driver) Restart() error {
 	return d.waitForJob(client, svmresp)
 }
 
+// Kill forces the virtual machine to stop without waiting for the operating
+// system to shut down.
 func (d *Driver) Kill() error {
-	return d.Stop()
+	client := d.client()
+
+	params := url.Values{}
+	params.Set("id", d.ID)
+	params.Set("forced", "true")
+
+	resp, err := client.Request("stopVirtualMachine", params)
+	if err != nil {
+		return err
+	}
+
+	var svmresp egoscale.StopVirtualMachineResponse
+	if err := json.Unmarshal(resp, &svmresp); err != nil {
+		return err
+	}
+
+	return d.waitForJob(client, svmresp.JobID)
 }
 
 func (d *Driver) Remove() error {
-	client := egoscale.NewClient(d.URL, d.APIKey, d.APISecretKey)
+	client := d.client()
 
 	// Destroy the SSH key
 	if _, err := client.DeleteKeypair(d.KeyPair); err != nil {
@@ -23,3 +23,10 @@ func TestSetConfigFromFlags(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, checkFlags.InvalidFlags)
 }
+
+func TestSetFirewallRules(t *testing.T) {
+	driver := NewDriver("default", "path").(*Driver)
+
+	assert.NoError(t, driver.SetFirewallRules(drivers.RequiredFirewallRules(2376)))
+	assert.EqualError(t, driver.SetFirewallRules(drivers.RequiredFirewallRules(2377)), "The exoscale driver cannot open the port 2377/tcp, only 22/tcp and 2376/tcp")
+}
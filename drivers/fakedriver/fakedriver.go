@@ -1,7 +1,15 @@
+// Package fakedriver is a driver which runs no machine, for the programs
+// embedding libmachine to test how they handle the lifecycle of machines
+// without creating real ones. The state of the machine is scripted with
+// MockState and MockStates, the calls are recorded, and each method can be
+// made slow with MockDelays or fail with MockErrors.
 package fakedriver
 
 import (
 	"fmt"
+	"net"
+	"sync"
+	"time"
 
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/mcnflag"
@@ -12,7 +20,89 @@ type Driver struct {
 	*drivers.BaseDriver
 	MockState state.State
 	MockIP    string
+	MockIPs   []string
 	MockName  string
+
+	MockPreempted bool
+	MockPrivateIP string
+
+	MockInstanceMissing bool
+
+	// MockStates are the states GetState returns next, one per call, before
+	// it keeps returning the last one. It is how a machine which takes a
+	// while to boot, or goes down, is simulated.
+	MockStates []state.State
+
+	// MockErrors are the errors the methods return by method name, e.g.
+	// "Start". A failing method leaves the state of the machine unchanged.
+	MockErrors map[string]error
+
+	// MockDelays are how long the methods take by method name, e.g. to test
+	// timeouts.
+	MockDelays map[string]time.Duration
+
+	// MockInstances are the instances DiscoverInstances finds.
+	MockInstances []drivers.DiscoveredInstance
+
+	// Calls are the names of the methods called so far, in order.
+	Calls []string
+
+	mutex sync.Mutex
+}
+
+// NewDriver returns a driver of a running machine.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+		MockName:  hostName,
+		MockState: state.Running,
+	}
+}
+
+// call records the call of the method, waits for its delay and returns its
+// error, if any.
+func (d *Driver) call(method string) error {
+	d.mutex.Lock()
+	d.Calls = append(d.Calls, method)
+	delay := d.MockDelays[method]
+	err := d.MockErrors[method]
+	d.mutex.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	return err
+}
+
+// setState moves the machine to the state, unless the method fails.
+func (d *Driver) setState(method string, st state.State) error {
+	if err := d.call(method); err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	d.MockState = st
+	d.mutex.Unlock()
+
+	return nil
+}
+
+// Called returns how many times the method was called.
+func (d *Driver) Called(method string) int {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	count := 0
+	for _, call := range d.Calls {
+		if call == method {
+			count++
+		}
+	}
+	return count
 }
 
 func (d *Driver) GetCreateFlags() []mcnflag.Flag {
@@ -36,7 +126,7 @@ func (d *Driver) GetURL() (string, error) {
 	if ip == "" {
 		return "", nil
 	}
-	return fmt.Sprintf("tcp://%s:2376", ip), nil
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, "2376")), nil
 }
 
 func (d *Driver) GetMachineName() string {
@@ -44,6 +134,9 @@ func (d *Driver) GetMachineName() string {
 }
 
 func (d *Driver) GetIP() (string, error) {
+	if err := d.call("GetIP"); err != nil {
+		return "", err
+	}
 	if d.MockState == state.Error {
 		return "", fmt.Errorf("Unable to get ip")
 	}
@@ -56,6 +149,24 @@ func (d *Driver) GetIP() (string, error) {
 	return d.MockIP, nil
 }
 
+func (d *Driver) GetIPs() ([]string, error) {
+	if len(d.MockIPs) == 0 {
+		return nil, drivers.ErrNotImplemented
+	}
+	if _, err := d.GetIP(); err != nil {
+		return nil, err
+	}
+	return d.MockIPs, nil
+}
+
+func (d *Driver) InstanceExists() (bool, error) {
+	return !d.MockInstanceMissing, nil
+}
+
+func (d *Driver) GetPrivateIP() (string, error) {
+	return d.MockPrivateIP, nil
+}
+
 func (d *Driver) GetSSHHostname() (string, error) {
 	return "", nil
 }
@@ -73,37 +184,71 @@ func (d *Driver) GetSSHUsername() string {
 }
 
 func (d *Driver) GetState() (state.State, error) {
+	if err := d.call("GetState"); err != nil {
+		return state.Error, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if len(d.MockStates) > 0 {
+		d.MockState = d.MockStates[0]
+		d.MockStates = d.MockStates[1:]
+	}
 	return d.MockState, nil
 }
 
+func (d *Driver) Preempted() (bool, error) {
+	return d.MockPreempted && d.MockState == state.Stopped, nil
+}
+
 func (d *Driver) Create() error {
-	return nil
+	return d.call("Create")
 }
 
 func (d *Driver) Start() error {
-	d.MockState = state.Running
-	return nil
+	return d.setState("Start", state.Running)
 }
 
 func (d *Driver) Stop() error {
-	d.MockState = state.Stopped
-	return nil
+	return d.setState("Stop", state.Stopped)
 }
 
 func (d *Driver) Restart() error {
-	d.MockState = state.Running
-	return nil
+	return d.setState("Restart", state.Running)
 }
 
 func (d *Driver) Kill() error {
-	d.MockState = state.Stopped
-	return nil
+	return d.setState("Kill", state.Stopped)
 }
 
 func (d *Driver) Remove() error {
-	return nil
+	return d.call("Remove")
 }
 
 func (d *Driver) Upgrade() error {
+	return d.call("Upgrade")
+}
+
+// DiscoverInstances returns the MockInstances.
+func (d *Driver) DiscoverInstances() ([]drivers.DiscoveredInstance, error) {
+	if err := d.call("DiscoverInstances"); err != nil {
+		return nil, err
+	}
+
+	return d.MockInstances, nil
+}
+
+// AdoptInstance takes the address of the instance, of a running machine.
+func (d *Driver) AdoptInstance(instance drivers.DiscoveredInstance) error {
+	if err := d.call("AdoptInstance"); err != nil {
+		return err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.MockIP = instance.IP
+	d.MockState = state.Running
 	return nil
 }
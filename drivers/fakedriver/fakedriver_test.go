@@ -0,0 +1,57 @@
+package fakedriver
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptedStates(t *testing.T) {
+	d := NewDriver("test", "")
+	d.MockStates = []state.State{state.Stopped, state.Starting, state.Running}
+
+	for _, expected := range []state.State{state.Stopped, state.Starting, state.Running, state.Running} {
+		st, err := d.GetState()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, st)
+	}
+}
+
+func TestMockErrors(t *testing.T) {
+	d := NewDriver("test", "")
+	d.MockState = state.Stopped
+	d.MockErrors = map[string]error{"Start": errors.New("quota exceeded")}
+
+	assert.EqualError(t, d.Start(), "quota exceeded")
+	assert.Equal(t, state.Stopped, d.MockState)
+
+	delete(d.MockErrors, "Start")
+
+	assert.NoError(t, d.Start())
+	assert.Equal(t, state.Running, d.MockState)
+	assert.Equal(t, 2, d.Called("Start"))
+}
+
+func TestMockDelays(t *testing.T) {
+	d := NewDriver("test", "")
+	d.MockDelays = map[string]time.Duration{"Stop": 50 * time.Millisecond}
+
+	begin := time.Now()
+	assert.NoError(t, d.Stop())
+
+	assert.True(t, time.Since(begin) >= 50*time.Millisecond)
+}
+
+func TestCalls(t *testing.T) {
+	d := NewDriver("test", "")
+
+	assert.True(t, drivers.MachineInState(d, state.Running)())
+	assert.NoError(t, d.Stop())
+	assert.NoError(t, d.Remove())
+
+	assert.Equal(t, []string{"GetState", "Stop", "Remove"}, d.Calls)
+}
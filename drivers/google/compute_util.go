@@ -5,15 +5,19 @@ import (
 	"io/ioutil"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/docker/machine/drivers/driverutil"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/throttle"
 	raw "google.golang.org/api/compute/v1"
 
 	"errors"
 
+	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/googleapi"
@@ -38,6 +42,7 @@ type ComputeUtil struct {
 	SwarmMaster       bool
 	SwarmHost         string
 	openPorts         []string
+	resourceTags      map[string]string
 }
 
 const (
@@ -49,7 +54,8 @@ const (
 
 // NewComputeUtil creates and initializes a ComputeUtil.
 func newComputeUtil(driver *Driver) (*ComputeUtil, error) {
-	client, err := google.DefaultClient(oauth2.NoContext, raw.ComputeScope)
+	ctx := context.WithValue(oauth2.NoContext, oauth2.HTTPClient, throttle.NewClient("google"))
+	client, err := google.DefaultClient(ctx, raw.ComputeScope)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +83,7 @@ func newComputeUtil(driver *Driver) (*ComputeUtil, error) {
 		SwarmMaster:       driver.SwarmMaster,
 		SwarmHost:         driver.SwarmHost,
 		openPorts:         driver.OpenPorts,
+		resourceTags:      driver.ResourceTags,
 	}, nil
 }
 
@@ -132,7 +139,7 @@ func (c *ComputeUtil) staticAddress() (string, error) {
 }
 
 func (c *ComputeUtil) region() string {
-	return c.zone[:len(c.zone)-2]
+	return zoneRegion(c.zone)
 }
 
 func (c *ComputeUtil) firewallRule() (*raw.Firewall, error) {
@@ -255,6 +262,9 @@ func (c *ComputeUtil) createInstance(d *Driver) error {
 		Tags: &raw.Tags{
 			Items: parseTags(d),
 		},
+		Metadata: &raw.Metadata{
+			Items: c.resourceMetadata(),
+		},
 		ServiceAccounts: []*raw.ServiceAccount{
 			{
 				Email:  "default",
@@ -365,19 +375,70 @@ func (c *ComputeUtil) uploadSSHKey(instance *raw.Instance, sshKeyPath string) er
 
 	metaDataValue := fmt.Sprintf("%s:%s %s\n", c.userName, strings.TrimSpace(string(sshKey)), c.userName)
 
+	// The metadata is replaced as a whole, the resource tags are kept
+	items := append(c.resourceMetadata(), &raw.MetadataItems{
+		Key:   "sshKeys",
+		Value: &metaDataValue,
+	})
+
 	op, err := c.service.Instances.SetMetadata(c.project, c.zone, c.instanceName, &raw.Metadata{
 		Fingerprint: instance.Metadata.Fingerprint,
-		Items: []*raw.MetadataItems{
-			{
-				Key:   "sshKeys",
-				Value: &metaDataValue,
-			},
-		},
+		Items:       items,
 	}).Do()
+	if err != nil {
+		return err
+	}
 
 	return c.waitForRegionalOp(op.Name)
 }
 
+// resourceMetadata returns the resource tags as metadata items.
+func (c *ComputeUtil) resourceMetadata() []*raw.MetadataItems {
+	keys := []string{}
+	for key := range c.resourceTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	items := []*raw.MetadataItems{}
+	for _, key := range keys {
+		value := c.resourceTags[key]
+		items = append(items, &raw.MetadataItems{
+			Key:   key,
+			Value: &value,
+		})
+	}
+
+	return items
+}
+
+// checkInstanceTags returns an error unless the metadata of the instance
+// holds the resource tags. Instances which no longer exist pass.
+func (c *ComputeUtil) checkInstanceTags() error {
+	if len(c.resourceTags) == 0 {
+		return nil
+	}
+
+	instance, err := c.instance()
+	if err != nil {
+		if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == 404 {
+			return nil
+		}
+		return unwrapGoogleError(err)
+	}
+
+	actual := map[string]string{}
+	if instance.Metadata != nil {
+		for _, item := range instance.Metadata.Items {
+			if item.Value != nil {
+				actual[item.Key] = *item.Value
+			}
+		}
+	}
+
+	return drivers.CheckResourceTags("instance "+c.instanceName, c.resourceTags, actual)
+}
+
 // parseTags computes the tags for the instance.
 func parseTags(d *Driver) []string {
 	tags := []string{firewallTargetTag}
@@ -401,6 +462,48 @@ func (c *ComputeUtil) deleteInstance() error {
 	return c.waitForRegionalOp(op.Name)
 }
 
+// preempted tells whether the instance is stopped because it was preempted,
+// rather than stopped by a user.
+func (c *ComputeUtil) preempted() (bool, error) {
+	instance, err := c.instance()
+	if err != nil {
+		return false, unwrapGoogleError(err)
+	}
+
+	if instance.Status != "TERMINATED" || instance.Scheduling == nil || !instance.Scheduling.Preemptible {
+		return false, nil
+	}
+
+	ops, err := c.service.ZoneOperations.List(c.project, c.zone).Filter(fmt.Sprintf("targetId eq %d", instance.Id)).Do()
+	if err != nil {
+		return false, unwrapGoogleError(err)
+	}
+
+	// The instance was preempted if that is what last happened to it
+	var last *raw.Operation
+	for _, op := range ops.Items {
+		switch op.OperationType {
+		case "compute.instances.preempted", "stop", "start", "insert":
+			if last == nil || op.InsertTime > last.InsertTime {
+				last = op
+			}
+		}
+	}
+
+	return last != nil && last.OperationType == "compute.instances.preempted", nil
+}
+
+// serialPortOutput returns what the instance printed on its first serial
+// port, where GCE images write their console.
+func (c *ComputeUtil) serialPortOutput() (string, error) {
+	output, err := c.service.Instances.GetSerialPortOutput(c.project, c.zone, c.instanceName).Do()
+	if err != nil {
+		return "", unwrapGoogleError(err)
+	}
+
+	return output.Contents, nil
+}
+
 // stopInstance stops the instance.
 func (c *ComputeUtil) stopInstance() error {
 	op, err := c.service.Instances.Stop(c.project, c.zone, c.instanceName).Do()
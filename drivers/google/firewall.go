@@ -0,0 +1,58 @@
+package google
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/docker/machine/drivers/driverutil"
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// SetFirewallRules adds the rules to the ports opened at creation. SSH is
+// open on the default network and the engine port is always opened, see
+// portsUsed.
+func (d *Driver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	enginePort, _ := strconv.Atoi(dockerPort)
+	for _, rule := range rules {
+		if drivers.IsRequiredFirewallRule(rule, enginePort) {
+			continue
+		}
+		d.addOpenPort(rule)
+	}
+	return nil
+}
+
+// OpenPort opens the port in the firewall rule of the machines.
+func (d *Driver) OpenPort(rule drivers.FirewallRule) error {
+	previous := d.OpenPorts
+	d.addOpenPort(rule)
+
+	c, err := newComputeUtil(d)
+	if err != nil {
+		d.OpenPorts = previous
+		return err
+	}
+
+	if err := c.openFirewallPorts(d); err != nil {
+		d.OpenPorts = previous
+		return err
+	}
+
+	return nil
+}
+
+// ClosePort fails: the firewall rule is shared by all the machines of the
+// project, closing a port would close it for all of them.
+func (d *Driver) ClosePort(rule drivers.FirewallRule) error {
+	return fmt.Errorf("The %q firewall rule is shared by all the machines of project %s, remove port %s from it to close the port for all of them", firewallRule, d.Project, rule)
+}
+
+func (d *Driver) addOpenPort(rule drivers.FirewallRule) {
+	for _, p := range d.OpenPorts {
+		port, proto := driverutil.SplitPortProto(p)
+		if port == strconv.Itoa(rule.Port) && proto == rule.Protocol {
+			return
+		}
+	}
+	d.OpenPorts = append(d.OpenPorts, rule.String())
+}
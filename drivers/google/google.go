@@ -35,6 +35,10 @@ type Driver struct {
 	Tags              string
 	UseExisting       bool
 	OpenPorts         []string
+
+	// ResourceTags are set as metadata of the instance, see
+	// SetResourceTags.
+	ResourceTags map[string]string `json:",omitempty"`
 }
 
 const (
@@ -65,6 +69,16 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Value:  defaultMachineType,
 			EnvVar: "GOOGLE_MACHINE_TYPE",
 		},
+		mcnflag.IntFlag{
+			Name:   "google-cpus",
+			Usage:  "Number of vCPUs of a custom machine type, replaces --google-machine-type",
+			EnvVar: "GOOGLE_CPUS",
+		},
+		mcnflag.IntFlag{
+			Name:   "google-memory",
+			Usage:  "Memory in MB of a custom machine type, replaces --google-machine-type",
+			EnvVar: "GOOGLE_MEMORY",
+		},
 		mcnflag.StringFlag{
 			Name:   "google-machine-image",
 			Usage:  "GCE Machine Image Absolute URL",
@@ -198,6 +212,15 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.UseExisting = flags.Bool("google-use-existing")
 	if !d.UseExisting {
 		d.MachineType = flags.String("google-machine-type")
+		if cpus, memory := flags.Int("google-cpus"), flags.Int("google-memory"); cpus != 0 || memory != 0 {
+			if cpus == 0 || memory == 0 {
+				return errors.New("both --google-cpus and --google-memory are needed for a custom machine type")
+			}
+			d.MachineType = customMachineType(cpus, memory)
+		}
+		if err := validateMachineType(d.MachineType); err != nil {
+			return err
+		}
 		d.MachineImage = flags.String("google-machine-image")
 		d.MachineImage = strings.TrimPrefix(d.MachineImage, "https://www.googleapis.com/compute/v1/projects/")
 		d.DiskSize = flags.Int("google-disk-size")
@@ -247,6 +270,8 @@ func (d *Driver) PreCreateCheck() error {
 		if instance != nil {
 			return fmt.Errorf("instance %q already exists in zone %q", d.MachineName, d.Zone)
 		}
+
+		return c.checkProvider(d)
 	}
 
 	return nil
@@ -305,6 +330,24 @@ func (d *Driver) GetIP() (string, error) {
 	return ip, nil
 }
 
+// InstanceExists tells whether the instance still exists. An instance
+// deleted while keeping its disk does not.
+func (d *Driver) InstanceExists() (bool, error) {
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := c.instance(); err != nil {
+		if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == 404 {
+			return false, nil
+		}
+		return false, unwrapGoogleError(err)
+	}
+
+	return true, nil
+}
+
 // GetState returns a docker.hosts.state.State value representing the current state of the host.
 func (d *Driver) GetState() (state.State, error) {
 	c, err := newComputeUtil(d)
@@ -391,6 +434,58 @@ func (d *Driver) Kill() error {
 	return d.Stop()
 }
 
+// Preempted tells whether GCE stopped the instance to reclaim its resources.
+func (d *Driver) Preempted() (bool, error) {
+	if !d.Preemptible {
+		return false, nil
+	}
+
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return false, err
+	}
+
+	return c.preempted()
+}
+
+// GetConsoleOutput returns the output of the serial port of the instance.
+func (d *Driver) GetConsoleOutput() (string, error) {
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return "", err
+	}
+
+	return c.serialPortOutput()
+}
+
+// InstallSSHKey replaces the key of the instance metadata, which the guest
+// agent then authorizes in place of the previous one.
+func (d *Driver) InstallSSHKey(keyPath string) error {
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return err
+	}
+
+	instance, err := c.instance()
+	if err != nil {
+		return err
+	}
+
+	return c.uploadSSHKey(instance, keyPath)
+}
+
+// SetResourceTags records the tags set as metadata of the instance. GCE
+// cannot attach metadata to disks, the disk is named after the instance. An
+// existing instance, see UseExisting, is not tagged.
+func (d *Driver) SetResourceTags(tags map[string]string) error {
+	if d.UseExisting {
+		return nil
+	}
+
+	d.ResourceTags = tags
+	return nil
+}
+
 // Remove deletes the GCE instance and the disk.
 func (d *Driver) Remove() error {
 	c, err := newComputeUtil(d)
@@ -398,6 +493,10 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
+	if err := c.checkInstanceTags(); err != nil {
+		return err
+	}
+
 	if err := c.deleteInstance(); err != nil {
 		googleErr, ok := err.(*googleapi.Error)
 		if !ok {
@@ -1,6 +1,7 @@
 package google
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/docker/machine/libmachine/drivers"
@@ -22,3 +23,80 @@ func TestSetConfigFromFlags(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, checkFlags.InvalidFlags)
 }
+
+func TestSetConfigFromFlagsCustomMachineType(t *testing.T) {
+	driver := NewDriver("", "")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"google-project": "PROJECT",
+			"google-cpus":    2,
+			"google-memory":  4096,
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-2-4096", driver.MachineType)
+}
+
+func TestSetConfigFromFlagsIncompleteCustomMachineType(t *testing.T) {
+	driver := NewDriver("", "")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"google-project": "PROJECT",
+			"google-cpus":    2,
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.Error(t, err)
+}
+
+func TestSetPlacement(t *testing.T) {
+	driver := NewDriver("", "")
+
+	assert.NoError(t, driver.SetPlacement(drivers.Placement{Region: "europe-west1", Zone: "europe-west1-b"}))
+	placement, err := driver.GetPlacement()
+	assert.NoError(t, err)
+	assert.Equal(t, drivers.Placement{Region: "europe-west1", Zone: "europe-west1-b"}, placement)
+
+	assert.NoError(t, driver.SetPlacement(drivers.Placement{Region: "europe-west1"}))
+	assert.Equal(t, "europe-west1-b", driver.Zone)
+
+	assert.EqualError(t, driver.SetPlacement(drivers.Placement{Region: "us-east1"}), "A zone of the region us-east1 is needed, e.g. us-east1-b")
+	assert.EqualError(t, driver.SetPlacement(drivers.Placement{Region: "us-east1", Zone: "europe-west1-c"}), "The zone europe-west1-c is not in the region us-east1")
+}
+
+func TestNameConstraints(t *testing.T) {
+	driver := NewDriver("", "")
+
+	constraints, err := driver.GetNameConstraints()
+	assert.NoError(t, err)
+
+	assert.NoError(t, constraints.Check("google", "dev-1"))
+	assert.Error(t, constraints.Check("google", "Dev"))
+	assert.Error(t, constraints.Check("google", "dev.example.com"))
+	assert.Error(t, constraints.Check("google", "dev-"))
+	assert.Error(t, constraints.Check("google", "1dev"))
+	assert.EqualError(t, constraints.Check("google", strings.Repeat("d", 59)), `The name "`+strings.Repeat("d", 59)+`" is too long for the google driver, which accepts at most 58 characters`)
+}
+
+func TestSetSize(t *testing.T) {
+	driver := NewDriver("", "")
+
+	assert.NoError(t, driver.SetSize(drivers.Size{Disk: 20000}))
+	assert.Equal(t, 20, driver.DiskSize, "the size is rounded up to GB")
+
+	size, err := driver.GetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, drivers.Size{Disk: 20 * 1024}, size)
+
+	assert.Error(t, driver.SetSize(drivers.Size{Memory: 4096}))
+	assert.Error(t, driver.SetSize(drivers.Size{Disk: 1024}))
+}
@@ -0,0 +1,57 @@
+package google
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+const (
+	customMachineTypeMemoryStep      = 256
+	customMachineTypeMaxCPUs         = 96
+	customMachineTypeMinMemoryPerCPU = 922  // 0.9 GB
+	customMachineTypeMaxMemoryPerCPU = 6656 // 6.5 GB
+)
+
+var (
+	customMachineTypePattern = regexp.MustCompile(`^custom-(\d+)-(\d+)(-ext)?$`)
+)
+
+// customMachineType returns the name of the custom machine type with the
+// given number of vCPUs and memory in MB.
+func customMachineType(cpus, memory int) string {
+	return fmt.Sprintf("custom-%d-%d", cpus, memory)
+}
+
+// validateMachineType checks that custom machine types follow the GCE rules,
+// so that a wrong type fails before anything is created. Predefined types
+// are left for GCE to check.
+func validateMachineType(machineType string) error {
+	matches := customMachineTypePattern.FindStringSubmatch(machineType)
+	if matches == nil {
+		return nil
+	}
+
+	cpus, _ := strconv.Atoi(matches[1])
+	memory, _ := strconv.Atoi(matches[2])
+	extended := matches[3] != ""
+
+	if cpus < 1 || cpus > customMachineTypeMaxCPUs || (cpus > 1 && cpus%2 != 0) {
+		return fmt.Errorf("invalid custom machine type %q: the number of vCPUs must be 1 or an even number up to %d", machineType, customMachineTypeMaxCPUs)
+	}
+
+	if memory%customMachineTypeMemoryStep != 0 {
+		return fmt.Errorf("invalid custom machine type %q: the memory must be a multiple of %d MB", machineType, customMachineTypeMemoryStep)
+	}
+
+	if memory < cpus*customMachineTypeMinMemoryPerCPU {
+		return fmt.Errorf("invalid custom machine type %q: at least %d MB of memory are needed for %d vCPUs", machineType, cpus*customMachineTypeMinMemoryPerCPU, cpus)
+	}
+
+	// Extended memory lifts the upper limit
+	if !extended && memory > cpus*customMachineTypeMaxMemoryPerCPU {
+		return fmt.Errorf("invalid custom machine type %q: at most %d MB of memory are allowed for %d vCPUs, append -ext for extended memory", machineType, cpus*customMachineTypeMaxMemoryPerCPU, cpus)
+	}
+
+	return nil
+}
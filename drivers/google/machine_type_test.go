@@ -0,0 +1,34 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomMachineType(t *testing.T) {
+	assert.Equal(t, "custom-4-8192", customMachineType(4, 8192))
+}
+
+func TestValidateMachineType(t *testing.T) {
+	valid := []string{
+		"n1-standard-1",
+		"custom-1-1024",
+		"custom-4-8192",
+		"custom-2-16384-ext",
+	}
+	for _, machineType := range valid {
+		assert.NoError(t, validateMachineType(machineType), machineType)
+	}
+
+	invalid := []string{
+		"custom-3-6144",
+		"custom-128-131072",
+		"custom-2-2000",
+		"custom-4-2048",
+		"custom-2-16384",
+	}
+	for _, machineType := range invalid {
+		assert.Error(t, validateMachineType(machineType), machineType)
+	}
+}
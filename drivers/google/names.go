@@ -0,0 +1,40 @@
+package google
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// GetNameConstraints returns the rules of GCE for the names of resources.
+// The name of the disk, the longest resource named after the machine, must
+// fit in 63 characters.
+func (d *Driver) GetNameConstraints() (drivers.NameConstraints, error) {
+	return drivers.NameConstraints{
+		MaxLength:          63 - len("-disk"),
+		Pattern:            `^[a-z]([-a-z0-9]*[a-z0-9])?$`,
+		PatternDescription: "lowercase letters, digits and dashes, starting with a letter and not ending with a dash",
+	}, nil
+}
+
+// CheckNameAvailable checks that no instance or disk of the zone has the
+// name of the machine, unless an existing instance is to be used.
+func (d *Driver) CheckNameAvailable() error {
+	if d.UseExisting {
+		return nil
+	}
+
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return err
+	}
+
+	if instance, _ := c.instance(); instance != nil {
+		return fmt.Errorf("instance %q already exists in zone %q", d.MachineName, d.Zone)
+	}
+	if disk, _ := c.disk(); disk != nil {
+		return fmt.Errorf("disk %q already exists in zone %q", c.diskName(), d.Zone)
+	}
+
+	return nil
+}
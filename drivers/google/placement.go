@@ -0,0 +1,67 @@
+package google
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"google.golang.org/api/googleapi"
+)
+
+// SetPlacement sets the zone of the instance. GCE instances are placed by
+// zone alone, a region must come with one of its zones.
+func (d *Driver) SetPlacement(placement drivers.Placement) error {
+	zone := placement.Zone
+	if zone == "" {
+		zone = d.Zone
+	}
+
+	if placement.Region != "" && zoneRegion(zone) != placement.Region {
+		if placement.Zone == "" {
+			return fmt.Errorf("A zone of the region %s is needed, e.g. %s-b", placement.Region, placement.Region)
+		}
+		return fmt.Errorf("The zone %s is not in the region %s", placement.Zone, placement.Region)
+	}
+
+	d.Zone = zone
+	return nil
+}
+
+// GetPlacement returns the zone of the instance and its region.
+func (d *Driver) GetPlacement() (drivers.Placement, error) {
+	return drivers.Placement{
+		Region: zoneRegion(d.Zone),
+		Zone:   d.Zone,
+	}, nil
+}
+
+// CheckPlacement checks that the zone exists and is up.
+func (d *Driver) CheckPlacement() error {
+	c, err := newComputeUtil(d)
+	if err != nil {
+		return err
+	}
+
+	zone, err := c.service.Zones.Get(d.Project, d.Zone).Do()
+	if googleErr, ok := err.(*googleapi.Error); ok && googleErr.Code == 404 {
+		return fmt.Errorf("The zone %s does not exist", d.Zone)
+	}
+	if err != nil {
+		return fmt.Errorf("Error reading the zone %s: %s", d.Zone, err)
+	}
+
+	if zone.Status != "UP" {
+		return fmt.Errorf("The zone %s is %s", d.Zone, strings.ToLower(zone.Status))
+	}
+
+	return nil
+}
+
+// zoneRegion returns the region of a zone, e.g. europe-west1 for
+// europe-west1-b.
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
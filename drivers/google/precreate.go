@@ -0,0 +1,97 @@
+package google
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	raw "google.golang.org/api/compute/v1"
+)
+
+// checkProvider checks with GCE, before anything is created, that the image
+// and the network exist and that the quotas of the region leave room for
+// the instance.
+func (c *ComputeUtil) checkProvider(d *Driver) error {
+	log.Infof("Check the image, the network and the quotas of the region")
+
+	project, name, family, err := parseImage(d.MachineImage)
+	if err != nil {
+		return err
+	}
+	if family {
+		_, err = c.service.Images.GetFromFamily(project, name).Do()
+	} else {
+		_, err = c.service.Images.Get(project, name).Do()
+	}
+	if err != nil {
+		return fmt.Errorf("Image %q not found: %v", d.MachineImage, err)
+	}
+
+	if _, err := c.service.Networks.Get(c.project, c.network).Do(); err != nil {
+		return fmt.Errorf("Network %q not found: %v", c.network, err)
+	}
+	if c.subnetwork != "" {
+		if _, err := c.service.Subnetworks.Get(c.project, c.region(), c.subnetwork).Do(); err != nil {
+			return fmt.Errorf("Subnetwork %q not found in the region %s: %v", c.subnetwork, c.region(), err)
+		}
+	}
+
+	machineType, err := c.service.MachineTypes.Get(c.project, c.zone, d.MachineType).Do()
+	if err != nil {
+		return fmt.Errorf("Machine type %q not found in the zone %s: %v", d.MachineType, c.zone, err)
+	}
+
+	region, err := c.service.Regions.Get(c.project, c.region()).Do()
+	if err != nil {
+		return fmt.Errorf("Error reading the quotas of the region %s: %v", c.region(), err)
+	}
+
+	return checkQuotas(region.Quotas, quotaNeeds(machineType.GuestCpus, d.DiskType, d.DiskSize))
+}
+
+// parseImage returns the project and the name of the image or of the image
+// family of a --google-machine-image, e.g.
+// ubuntu-os-cloud/global/images/family/ubuntu-1604-lts.
+func parseImage(image string) (project, name string, family bool, err error) {
+	parts := strings.Split(image, "/")
+	switch {
+	case len(parts) == 4 && parts[1] == "global" && parts[2] == "images":
+		return parts[0], parts[3], false, nil
+	case len(parts) == 5 && parts[1] == "global" && parts[2] == "images" && parts[3] == "family":
+		return parts[0], parts[4], true, nil
+	}
+
+	return "", "", false, fmt.Errorf("Invalid machine image %q, expected <project>/global/images/<image> or <project>/global/images/family/<family>", image)
+}
+
+// quotaNeeds returns how much of each quota of the region an instance
+// takes, by the name of the metric of the quota.
+func quotaNeeds(cpus int64, diskType string, diskSize int) map[string]float64 {
+	diskQuota := "DISKS_TOTAL_GB"
+	if diskType == "pd-ssd" {
+		diskQuota = "SSD_TOTAL_GB"
+	}
+
+	return map[string]float64{
+		"INSTANCES": 1,
+		"CPUS":      float64(cpus),
+		diskQuota:   float64(diskSize),
+	}
+}
+
+// checkQuotas checks that the needs fit in the quotas of the region.
+func checkQuotas(quotas []*raw.Quota, needs map[string]float64) error {
+	for _, quota := range quotas {
+		needed, ok := needs[quota.Metric]
+		if !ok {
+			continue
+		}
+
+		if err := drivers.CheckQuota(quota.Metric, quota.Limit, quota.Usage, needed); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+	raw "google.golang.org/api/compute/v1"
+)
+
+func TestParseImage(t *testing.T) {
+	project, name, family, err := parseImage(defaultImageName)
+	assert.NoError(t, err)
+	assert.Equal(t, "ubuntu-os-cloud", project)
+	assert.Equal(t, "ubuntu-1604-xenial-v20170721", name)
+	assert.False(t, family)
+
+	project, name, family, err = parseImage("cos-cloud/global/images/family/cos-stable")
+	assert.NoError(t, err)
+	assert.Equal(t, "cos-cloud", project)
+	assert.Equal(t, "cos-stable", name)
+	assert.True(t, family)
+
+	_, _, _, err = parseImage("ubuntu-1604-lts")
+	assert.Error(t, err)
+}
+
+func TestCheckQuotas(t *testing.T) {
+	quotas := []*raw.Quota{
+		{Metric: "CPUS", Limit: 24, Usage: 20},
+		{Metric: "INSTANCES", Limit: 24, Usage: 10},
+		{Metric: "SSD_TOTAL_GB", Limit: 500, Usage: 480},
+		{Metric: "DISKS_TOTAL_GB", Limit: 2048, Usage: 100},
+	}
+
+	assert.NoError(t, checkQuotas(quotas, quotaNeeds(4, "pd-standard", 100)))
+	assert.Equal(t, drivers.ErrQuotaExceeded{Quota: "CPUS", Limit: 24, Usage: 20, Needed: 8}, checkQuotas(quotas, quotaNeeds(8, "pd-standard", 100)))
+	assert.Equal(t, drivers.ErrQuotaExceeded{Quota: "SSD_TOTAL_GB", Limit: 500, Usage: 480, Needed: 100}, checkQuotas(quotas, quotaNeeds(1, "pd-ssd", 100)))
+}
@@ -0,0 +1,30 @@
+package google
+
+import (
+	"regexp"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// sshStartedPattern matches the lines the init systems of the usual images
+// print on the serial console once the SSH server started.
+var sshStartedPattern = regexp.MustCompile(`Started (OpenBSD Secure Shell server|OpenSSH server daemon|OpenSSH Daemon)|Starting OpenBSD Secure Shell server.*\[ OK \]| login: `)
+
+// InstanceReady tells whether the serial console of the instance shows that
+// its SSH server started. The guest attributes of GCE would tell more
+// reliably, the version of the compute API used here does not expose them.
+func (d *Driver) InstanceReady() (bool, error) {
+	c, err := newComputeUtil(d)
+	if err != nil {
+		log.Debugf("Error reading the serial console of %s: %s", d.MachineName, err)
+		return false, nil
+	}
+
+	output, err := c.serialPortOutput()
+	if err != nil {
+		log.Debugf("Error reading the serial console of %s: %s", d.MachineName, err)
+		return false, nil
+	}
+
+	return sshStartedPattern.MatchString(output), nil
+}
@@ -0,0 +1,20 @@
+package google
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHStartedPattern(t *testing.T) {
+	started := []string{
+		"[  OK  ] Started OpenBSD Secure Shell server.",
+		"Oct  2 09:30:00 instance systemd[1]: Started OpenSSH server daemon.",
+		"Ubuntu 16.04.3 LTS machine ttyS0\n\nmachine login: ",
+	}
+	for _, output := range started {
+		assert.True(t, sshStartedPattern.MatchString(output), output)
+	}
+
+	assert.False(t, sshStartedPattern.MatchString("[  OK  ] Started Journal Service.\nStarting OpenBSD Secure Shell server..."))
+}
@@ -0,0 +1,32 @@
+package google
+
+import (
+	"errors"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// minDiskSize is the smallest disk in GB GCE creates from the images.
+const minDiskSize = 10
+
+// SetSize sets the size of the disk, rounded up to GB. The memory of the
+// instances comes with their machine type.
+func (d *Driver) SetSize(size drivers.Size) error {
+	if size.Memory != 0 {
+		return errors.New("The memory of GCE instances is set by their machine type, see --google-machine-type")
+	}
+	if err := size.Check(drivers.Size{Disk: minDiskSize * 1024}); err != nil {
+		return err
+	}
+
+	if size.Disk != 0 {
+		d.DiskSize = drivers.SizeGB(size.Disk)
+	}
+	return nil
+}
+
+// GetSize returns the size of the disk, the memory of the machine type
+// being unknown to the driver.
+func (d *Driver) GetSize() (drivers.Size, error) {
+	return drivers.Size{Disk: d.DiskSize * 1024}, nil
+}
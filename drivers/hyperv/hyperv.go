@@ -23,21 +23,32 @@ type Driver struct {
 	CPU            int
 	MacAddr        string
 	VLanID         int
+	Generation     int
+	ExternalNIC    string
+	DynamicMemory  bool
+	MinMemSize     int
+	MaxMemSize     int
 }
 
 const (
-	defaultDiskSize = 20000
-	defaultMemory   = 1024
-	defaultCPU      = 1
-	defaultVLanID   = 0
+	defaultDiskSize   = 20000
+	defaultMemory     = 1024
+	defaultCPU        = 1
+	defaultVLanID     = 0
+	defaultGeneration = 1
+
+	// defaultExternalSwitch is the name of the external switch created when
+	// there is no switch to connect the machine to.
+	defaultExternalSwitch = "DockerMachineExternal"
 )
 
 // NewDriver creates a new Hyper-v driver with default settings.
 func NewDriver(hostName, storePath string) *Driver {
 	return &Driver{
-		DiskSize: defaultDiskSize,
-		MemSize:  defaultMemory,
-		CPU:      defaultCPU,
+		DiskSize:   defaultDiskSize,
+		MemSize:    defaultMemory,
+		CPU:        defaultCPU,
+		Generation: defaultGeneration,
 		BaseDriver: &drivers.BaseDriver{
 			MachineName: hostName,
 			StorePath:   storePath,
@@ -56,9 +67,14 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		},
 		mcnflag.StringFlag{
 			Name:   "hyperv-virtual-switch",
-			Usage:  "Virtual switch name. Defaults to first found.",
+			Usage:  "Virtual switch name. Defaults to the first external switch found, then to the first switch found.",
 			EnvVar: "HYPERV_VIRTUAL_SWITCH",
 		},
+		mcnflag.StringFlag{
+			Name:   "hyperv-external-adapter",
+			Usage:  "Physical network adapter to bind the external switch to when it has to be created. Defaults to the first one up.",
+			EnvVar: "HYPERV_EXTERNAL_ADAPTER",
+		},
 		mcnflag.IntFlag{
 			Name:   "hyperv-disk-size",
 			Usage:  "Maximum size of dynamically expanding disk in MB.",
@@ -88,6 +104,27 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Value:  defaultVLanID,
 			EnvVar: "HYPERV_VLAN_ID",
 		},
+		mcnflag.IntFlag{
+			Name:   "hyperv-generation",
+			Usage:  "Hyper-V generation of the machine, 1 or 2. Secure boot is disabled on generation 2 machines.",
+			Value:  defaultGeneration,
+			EnvVar: "HYPERV_GENERATION",
+		},
+		mcnflag.BoolFlag{
+			Name:   "hyperv-dynamic-memory",
+			Usage:  "Let Hyper-V adjust the memory of the machine, starting with --hyperv-memory.",
+			EnvVar: "HYPERV_DYNAMIC_MEMORY",
+		},
+		mcnflag.IntFlag{
+			Name:   "hyperv-memory-minimum",
+			Usage:  "Minimum memory size in MB when dynamic memory is enabled. Defaults to the Hyper-V default.",
+			EnvVar: "HYPERV_MEMORY_MINIMUM",
+		},
+		mcnflag.IntFlag{
+			Name:   "hyperv-memory-maximum",
+			Usage:  "Maximum memory size in MB when dynamic memory is enabled. Defaults to the Hyper-V default.",
+			EnvVar: "HYPERV_MEMORY_MAXIMUM",
+		},
 	}
 }
 
@@ -99,9 +136,28 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.CPU = flags.Int("hyperv-cpu-count")
 	d.MacAddr = flags.String("hyperv-static-macaddress")
 	d.VLanID = flags.Int("hyperv-vlan-id")
+	d.ExternalNIC = flags.String("hyperv-external-adapter")
+	d.Generation = flags.Int("hyperv-generation")
+	d.DynamicMemory = flags.Bool("hyperv-dynamic-memory")
+	d.MinMemSize = flags.Int("hyperv-memory-minimum")
+	d.MaxMemSize = flags.Int("hyperv-memory-maximum")
 	d.SSHUser = "docker"
 	d.SetSwarmConfigFromFlags(flags)
 
+	if d.Generation != 1 && d.Generation != 2 {
+		return fmt.Errorf("Invalid generation %d, must be 1 or 2", d.Generation)
+	}
+
+	if !d.DynamicMemory && (d.MinMemSize > 0 || d.MaxMemSize > 0) {
+		return fmt.Errorf("The memory limits require --hyperv-dynamic-memory")
+	}
+	if d.MinMemSize > 0 && d.MinMemSize > d.MemSize {
+		return fmt.Errorf("The minimum memory size (%d MB) is larger than the memory size (%d MB)", d.MinMemSize, d.MemSize)
+	}
+	if d.MaxMemSize > 0 && d.MaxMemSize < d.MemSize {
+		return fmt.Errorf("The maximum memory size (%d MB) is smaller than the memory size (%d MB)", d.MaxMemSize, d.MemSize)
+	}
+
 	return nil
 }
 
@@ -169,10 +225,17 @@ func (d *Driver) PreCreateCheck() error {
 		return ErrNotAdministrator
 	}
 
-	// Check that there is a virtual switch already configured
-	if _, err := d.chooseVirtualSwitch(); err != nil {
+	// Check that there is a virtual switch already configured, or a network
+	// adapter to create one
+	_, create, err := d.chooseVirtualSwitch()
+	if err != nil {
 		return err
 	}
+	if create {
+		if _, err := d.chooseExternalAdapter(); err != nil {
+			return err
+		}
+	}
 
 	// Downloading boot2docker to cache should be done here to make sure
 	// that a download failure will not leave a machine half created.
@@ -193,11 +256,17 @@ func (d *Driver) Create() error {
 	}
 
 	log.Infof("Creating VM...")
-	virtualSwitch, err := d.chooseVirtualSwitch()
+	virtualSwitch, create, err := d.chooseVirtualSwitch()
 	if err != nil {
 		return err
 	}
 
+	if create {
+		if err := d.createExternalSwitch(virtualSwitch); err != nil {
+			return err
+		}
+	}
+
 	log.Infof("Using switch %q", virtualSwitch)
 
 	diskImage, err := d.generateDiskImage()
@@ -209,10 +278,26 @@ func (d *Driver) Create() error {
 		d.MachineName,
 		"-Path", fmt.Sprintf("'%s'", d.ResolveStorePath(".")),
 		"-SwitchName", quote(virtualSwitch),
+		"-Generation", fmt.Sprintf("%d", d.Generation),
 		"-MemoryStartupBytes", toMb(d.MemSize)); err != nil {
 		return err
 	}
 
+	if d.DynamicMemory {
+		args := []string{"Set-VMMemory",
+			"-VMName", d.MachineName,
+			"-DynamicMemoryEnabled", "$true"}
+		if d.MinMemSize > 0 {
+			args = append(args, "-MinimumBytes", toMb(d.MinMemSize))
+		}
+		if d.MaxMemSize > 0 {
+			args = append(args, "-MaximumBytes", toMb(d.MaxMemSize))
+		}
+		if err := cmd(args...); err != nil {
+			return err
+		}
+	}
+
 	if d.CPU > 1 {
 		if err := cmd("Set-VMProcessor",
 			d.MachineName,
@@ -238,7 +323,12 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	if err := cmd("Set-VMDvdDrive",
+	// Generation 2 machines have no DVD drive by default
+	dvdDrive := "Set-VMDvdDrive"
+	if d.Generation == 2 {
+		dvdDrive = "Add-VMDvdDrive"
+	}
+	if err := cmd(dvdDrive,
 		"-VMName", d.MachineName,
 		"-Path", quote(d.ResolveStorePath("boot2docker.iso"))); err != nil {
 		return err
@@ -250,39 +340,106 @@ func (d *Driver) Create() error {
 		return err
 	}
 
+	// boot2docker is not signed for secure boot and generation 2 machines
+	// boot from the network first
+	if d.Generation == 2 {
+		if err := cmd("Set-VMFirmware",
+			"-VMName", d.MachineName,
+			"-EnableSecureBoot", "Off",
+			"-FirstBootDevice", "(Get-VMDvdDrive", "-VMName", d.MachineName, ")"); err != nil {
+			return err
+		}
+	}
+
 	log.Infof("Starting VM...")
 	return d.Start()
 }
 
-func (d *Driver) chooseVirtualSwitch() (string, error) {
+// chooseVirtualSwitch returns the name of the switch to connect the machine
+// to and whether it has to be created as an external switch first.
+func (d *Driver) chooseVirtualSwitch() (string, bool, error) {
 	stdout, err := cmdOut("(Get-VMSwitch).Name")
+	if err != nil {
+		return "", false, err
+	}
+	switches := parseLines(stdout)
+
+	stdout, err = cmdOut("(Get-VMSwitch", "-SwitchType", "External).Name")
+	if err != nil {
+		return "", false, err
+	}
+	externalSwitches := parseLines(stdout)
+
+	return pickVirtualSwitch(d.VSwitch, d.ExternalNIC, externalSwitches, switches)
+}
+
+// pickVirtualSwitch picks the requested switch or, when none is, the first
+// external switch and then the first switch. A switch is to be created when
+// the requested one is missing but an adapter to bind it to is given, or
+// when there is no switch at all.
+func pickVirtualSwitch(requested, adapter string, externalSwitches, switches []string) (string, bool, error) {
+	if requested == "" {
+		if len(externalSwitches) > 0 {
+			return externalSwitches[0], false, nil
+		}
+		if len(switches) > 0 {
+			return switches[0], false, nil
+		}
+		return defaultExternalSwitch, true, nil
+	}
+
+	for _, name := range switches {
+		if name == requested {
+			return requested, false, nil
+		}
+	}
+
+	if adapter != "" {
+		return requested, true, nil
+	}
+
+	return "", false, fmt.Errorf("vswitch %q not found", requested)
+}
+
+// chooseExternalAdapter returns the physical network adapter to bind a new
+// external switch to.
+func (d *Driver) chooseExternalAdapter() (string, error) {
+	stdout, err := cmdOut("(Get-NetAdapter", "-Physical", "|", "Where-Object", "Status", "-eq", "Up).Name")
 	if err != nil {
 		return "", err
 	}
 
-	switches := parseLines(stdout)
+	adapters := parseLines(stdout)
 
-	if d.VSwitch == "" {
-		if len(switches) < 1 {
-			return "", fmt.Errorf("no vswitch found. A valid vswitch must be available for this command to run. Check https://docs.docker.com/machine/drivers/hyper-v/")
+	if d.ExternalNIC == "" {
+		if len(adapters) < 1 {
+			return "", fmt.Errorf("no vswitch found and no network adapter up to create one. Check https://docs.docker.com/machine/drivers/hyper-v/")
 		}
 
-		return switches[0], nil
+		return adapters[0], nil
 	}
 
-	found := false
-	for _, name := range switches {
-		if name == d.VSwitch {
-			found = true
-			break
+	for _, name := range adapters {
+		if name == d.ExternalNIC {
+			return name, nil
 		}
 	}
 
-	if !found {
-		return "", fmt.Errorf("vswitch %q not found", d.VSwitch)
+	return "", fmt.Errorf("network adapter %q not found or not up", d.ExternalNIC)
+}
+
+// createExternalSwitch creates an external switch shared with the host.
+func (d *Driver) createExternalSwitch(name string) error {
+	adapter, err := d.chooseExternalAdapter()
+	if err != nil {
+		return err
 	}
 
-	return d.VSwitch, nil
+	log.Infof("Creating external switch %q on adapter %q...", name, adapter)
+	return cmd("New-VMSwitch",
+		"-Name", quote(name),
+		"-NetAdapterName", quote(adapter),
+		"-AllowManagementOS", "$true")
 }
 
 // waitForIP waits until the host has a valid IP
@@ -417,7 +574,11 @@ func (d *Driver) publicSSHKeyPath() string {
 
 // generateDiskImage creates a small fixed vhd, put the tar in, convert to dynamic, then resize
 func (d *Driver) generateDiskImage() (string, error) {
+	// Generation 2 machines only boot from VHDX disks
 	diskImage := d.ResolveStorePath("disk.vhd")
+	if d.Generation == 2 {
+		diskImage = d.ResolveStorePath("disk.vhdx")
+	}
 	fixed := d.ResolveStorePath("fixed.vhd")
 
 	// Resizing vhds requires administrator privileges
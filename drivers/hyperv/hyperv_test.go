@@ -30,6 +30,8 @@ func TestSetConfigFromDefaultFlags(t *testing.T) {
 	assert.Equal(t, defaultCPU, driver.CPU)
 	assert.Equal(t, "", driver.MacAddr)
 	assert.Equal(t, defaultVLanID, driver.VLanID)
+	assert.Equal(t, defaultGeneration, driver.Generation)
+	assert.False(t, driver.DynamicMemory)
 	assert.Equal(t, "docker", driver.GetSSHUsername())
 }
 
@@ -45,6 +47,11 @@ func TestSetConfigFromCustomFlags(t *testing.T) {
 			"hyperv-cpu-count":         4,
 			"hyperv-static-macaddress": "00:0a:95:9d:68:16",
 			"hyperv-vlan-id":           2,
+			"hyperv-external-adapter":  "Ethernet",
+			"hyperv-generation":        2,
+			"hyperv-dynamic-memory":    true,
+			"hyperv-memory-minimum":    512,
+			"hyperv-memory-maximum":    8192,
 		},
 		CreateFlags: driver.GetCreateFlags(),
 	}
@@ -64,5 +71,67 @@ func TestSetConfigFromCustomFlags(t *testing.T) {
 	assert.Equal(t, 4, driver.CPU)
 	assert.Equal(t, "00:0a:95:9d:68:16", driver.MacAddr)
 	assert.Equal(t, 2, driver.VLanID)
+	assert.Equal(t, "Ethernet", driver.ExternalNIC)
+	assert.Equal(t, 2, driver.Generation)
+	assert.True(t, driver.DynamicMemory)
+	assert.Equal(t, 512, driver.MinMemSize)
+	assert.Equal(t, 8192, driver.MaxMemSize)
 	assert.Equal(t, "docker", driver.GetSSHUsername())
 }
+
+func TestSetConfigFromInvalidFlags(t *testing.T) {
+	for _, flags := range []map[string]interface{}{
+		{"hyperv-generation": 3},
+		{"hyperv-memory-maximum": 2048},
+		{"hyperv-dynamic-memory": true, "hyperv-memory-minimum": 2048},
+		{"hyperv-dynamic-memory": true, "hyperv-memory-maximum": 512},
+	} {
+		driver := NewDriver("default", "path")
+
+		checkFlags := &drivers.CheckDriverOptions{
+			FlagsValues: flags,
+			CreateFlags: driver.GetCreateFlags(),
+		}
+
+		assert.Error(t, driver.SetConfigFromFlags(checkFlags), "%v", flags)
+	}
+}
+
+func TestPickVirtualSwitch(t *testing.T) {
+	var tests = []struct {
+		requested        string
+		adapter          string
+		externalSwitches []string
+		switches         []string
+		expectedSwitch   string
+		expectedCreate   bool
+		expectedErr      bool
+	}{
+		{"", "", []string{"External"}, []string{"Internal", "External"}, "External", false, false},
+		{"", "", nil, []string{"Internal"}, "Internal", false, false},
+		{"", "", nil, nil, defaultExternalSwitch, true, false},
+		{"Internal", "", []string{"External"}, []string{"Internal", "External"}, "Internal", false, false},
+		{"Missing", "Ethernet", nil, []string{"Internal"}, "Missing", true, false},
+		{"Missing", "", nil, []string{"Internal"}, "", false, true},
+	}
+
+	for _, test := range tests {
+		name, create, err := pickVirtualSwitch(test.requested, test.adapter, test.externalSwitches, test.switches)
+
+		assert.Equal(t, test.expectedSwitch, name)
+		assert.Equal(t, test.expectedCreate, create)
+		assert.Equal(t, test.expectedErr, err != nil)
+	}
+}
+
+func TestSetSize(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	assert.NoError(t, driver.SetSize(drivers.Size{Memory: 4096}))
+	size, err := driver.GetSize()
+	assert.NoError(t, err)
+	assert.Equal(t, drivers.Size{Memory: 4096, Disk: defaultDiskSize}, size)
+
+	assert.EqualError(t, driver.SetSize(drivers.Size{Memory: 2049}), "The memory of Hyper-V VMs must be a multiple of 2 MB, not 2049 MB")
+	assert.EqualError(t, driver.SetSize(drivers.Size{Disk: 100}), "The disk of 100 MB is below the minimum of 1024 MB")
+}
@@ -0,0 +1,34 @@
+package hyperv
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// minSize is the smallest VM boot2docker runs docker in.
+var minSize = drivers.Size{Memory: 512, Disk: 1024}
+
+// SetSize sets the startup memory and the size of the disk of the VM.
+// Hyper-V only takes memory sizes in multiples of 2 MB.
+func (d *Driver) SetSize(size drivers.Size) error {
+	if err := size.Check(minSize); err != nil {
+		return err
+	}
+	if size.Memory%2 != 0 {
+		return fmt.Errorf("The memory of Hyper-V VMs must be a multiple of 2 MB, not %d MB", size.Memory)
+	}
+
+	if size.Memory != 0 {
+		d.MemSize = size.Memory
+	}
+	if size.Disk != 0 {
+		d.DiskSize = size.Disk
+	}
+	return nil
+}
+
+// GetSize returns the startup memory and the size of the disk of the VM.
+func (d *Driver) GetSize() (drivers.Size, error) {
+	return drivers.Size{Memory: d.MemSize, Disk: d.DiskSize}, nil
+}
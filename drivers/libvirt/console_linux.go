@@ -0,0 +1,89 @@
+package libvirt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"syscall"
+	"unsafe"
+)
+
+func (v *VirshCmd) console(domain string, match *regexp.Regexp, stop <-chan struct{}) (string, error) {
+	// virsh refuses to attach to a console without a terminal
+	master, slave, err := openPty()
+	if err != nil {
+		return "", err
+	}
+	defer master.Close()
+
+	cmd := exec.Command("virsh", "--connect", v.connectionURI, "console", domain)
+	cmd.Stdin = slave
+	cmd.Stdout = slave
+	cmd.Stderr = slave
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+
+	err = cmd.Start()
+	slave.Close()
+	if err != nil {
+		if ee, ok := err.(*exec.Error); ok && ee.Err == exec.ErrNotFound {
+			return "", ErrVirshNotFound
+		}
+		return "", err
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	found := make(chan string, 1)
+	go func() {
+		found <- scanConsole(master, match)
+	}()
+
+	select {
+	case output := <-found:
+		if output == "" {
+			return "", errors.New("the console was closed")
+		}
+		return output, nil
+	case <-stop:
+		return "", nil
+	}
+}
+
+// openPty opens a pseudo terminal and returns its master and slave sides.
+func openPty() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock int32
+	if err := ioctl(master.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	var n uint32
+	if err := ioctl(master.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	return master, slave, nil
+}
+
+func ioctl(fd, request, arg uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, arg); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package libvirt
+
+import (
+	"bufio"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenPty(t *testing.T) {
+	master, slave, err := openPty()
+	if err != nil {
+		t.Skipf("No pseudo terminal available: %s", err)
+	}
+	defer master.Close()
+	defer slave.Close()
+
+	_, err = slave.Write([]byte("console\n"))
+	assert.NoError(t, err)
+
+	line, err := bufio.NewReader(master).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "console\r\n", line)
+}
@@ -0,0 +1,12 @@
+// +build !linux
+
+package libvirt
+
+import (
+	"errors"
+	"regexp"
+)
+
+func (v *VirshCmd) console(domain string, match *regexp.Regexp, stop <-chan struct{}) (string, error) {
+	return "", errors.New("reading the console of a machine is only supported on Linux")
+}
@@ -0,0 +1,66 @@
+package libvirt
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const domainXML = `<domain type='kvm'>
+  <name>{{.MachineName}}</name>
+  <memory unit='MiB'>{{.Memory}}</memory>
+  <vcpu>{{.CPU}}</vcpu>
+  <features>
+    <acpi/>
+    <apic/>
+    <pae/>
+  </features>
+  <cpu mode='host-passthrough'/>
+  <os>
+    <type>hvm</type>
+    <boot dev='cdrom'/>
+    <boot dev='hd'/>
+    <bootmenu enable='no'/>
+  </os>
+  <devices>
+    <disk type='volume' device='cdrom'>
+      <source pool='{{.StoragePool}}' volume='{{.ISOVolumeName}}'/>
+      <target dev='hdc' bus='ide'/>
+      <readonly/>
+    </disk>
+    <disk type='volume' device='disk'>
+      <driver name='qemu' type='raw' cache='default' io='threads'/>
+      <source pool='{{.StoragePool}}' volume='{{.VolumeName}}'/>
+      <target dev='vda' bus='virtio'/>
+    </disk>
+    <interface type='network'>
+      <source network='{{.Network}}'/>
+      <model type='virtio'/>
+    </interface>
+    <serial type='pty'>
+      <target port='0'/>
+    </serial>
+    <console type='pty'>
+      <target type='serial' port='0'/>
+    </console>
+    <rng model='virtio'>
+      <backend model='random'>/dev/random</backend>
+    </rng>
+  </devices>
+</domain>`
+
+var domainTemplate = template.Must(template.New("domain").Parse(domainXML))
+
+// domainDefinition returns the libvirt XML definition of the machine.
+func (d *Driver) domainDefinition() (string, error) {
+	var buf bytes.Buffer
+	err := domainTemplate.Execute(&buf, map[string]interface{}{
+		"MachineName":   d.MachineName,
+		"Memory":        d.Memory,
+		"CPU":           d.CPU,
+		"StoragePool":   d.StoragePool,
+		"ISOVolumeName": d.isoVolumeName(),
+		"VolumeName":    d.volumeName(),
+		"Network":       d.Network,
+	})
+	return buf.String(), err
+}
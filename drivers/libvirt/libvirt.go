@@ -0,0 +1,464 @@
+package libvirt
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+)
+
+const (
+	isoFilename = "boot2docker.iso"
+
+	defaultConnectionURI = "qemu:///system"
+	defaultCPU           = 1
+	defaultMemory        = 1024
+	defaultDiskSize      = 20000
+	defaultStoragePool   = "default"
+	defaultNetwork       = "default"
+	defaultSSHUser       = "docker"
+
+	defaultIPTimeout = 2 * time.Minute
+)
+
+// Driver creates machines on a local or remote libvirt daemon using KVM.
+type Driver struct {
+	*drivers.BaseDriver
+	ConnectionURI  string
+	Boot2DockerURL string
+	CPU            int
+	Memory         int
+	DiskSize       int
+	StoragePool    string
+	Network        string
+	// UploadedISO identifies the version of the ISO of the machine
+	// directory last uploaded to the storage pool, see uploadISO.
+	UploadedISO string
+
+	vsh Virsh
+}
+
+// NewDriver creates a new libvirt driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		ConnectionURI: defaultConnectionURI,
+		CPU:           defaultCPU,
+		Memory:        defaultMemory,
+		DiskSize:      defaultDiskSize,
+		StoragePool:   defaultStoragePool,
+		Network:       defaultNetwork,
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// GetCreateFlags registers the flags this driver adds to
+// "docker hosts create"
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return []mcnflag.Flag{
+		mcnflag.StringFlag{
+			Name:   "libvirt-connection-uri",
+			Usage:  "URI of the libvirt daemon to create the machine on",
+			Value:  defaultConnectionURI,
+			EnvVar: "LIBVIRT_CONNECTION_URI",
+		},
+		mcnflag.StringFlag{
+			Name:   "libvirt-boot2docker-url",
+			Usage:  "URL of the boot2docker ISO. Defaults to the latest available version.",
+			EnvVar: "LIBVIRT_BOOT2DOCKER_URL",
+		},
+		mcnflag.IntFlag{
+			Name:   "libvirt-cpu-count",
+			Usage:  "Number of CPUs for the machine",
+			Value:  defaultCPU,
+			EnvVar: "LIBVIRT_CPU_COUNT",
+		},
+		mcnflag.IntFlag{
+			Name:   "libvirt-memory",
+			Usage:  "Memory size for the machine in MB",
+			Value:  defaultMemory,
+			EnvVar: "LIBVIRT_MEMORY",
+		},
+		mcnflag.IntFlag{
+			Name:   "libvirt-disk-size",
+			Usage:  "Size of the disk for the machine in MB",
+			Value:  defaultDiskSize,
+			EnvVar: "LIBVIRT_DISK_SIZE",
+		},
+		mcnflag.StringFlag{
+			Name:   "libvirt-storage-pool",
+			Usage:  "Libvirt storage pool the disk of the machine is created in",
+			Value:  defaultStoragePool,
+			EnvVar: "LIBVIRT_STORAGE_POOL",
+		},
+		mcnflag.StringFlag{
+			Name:   "libvirt-network",
+			Usage:  "Libvirt network the machine is attached to",
+			Value:  defaultNetwork,
+			EnvVar: "LIBVIRT_NETWORK",
+		},
+	}
+}
+
+func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
+	d.ConnectionURI = flags.String("libvirt-connection-uri")
+	d.Boot2DockerURL = flags.String("libvirt-boot2docker-url")
+	d.CPU = flags.Int("libvirt-cpu-count")
+	d.Memory = flags.Int("libvirt-memory")
+	d.DiskSize = flags.Int("libvirt-disk-size")
+	d.StoragePool = flags.String("libvirt-storage-pool")
+	d.Network = flags.String("libvirt-network")
+	d.SSHUser = defaultSSHUser
+	d.SSHPort = 22
+	d.SetSwarmConfigFromFlags(flags)
+
+	return nil
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return "libvirt"
+}
+
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+
+	if ip == "" {
+		return "", nil
+	}
+
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, "2376")), nil
+}
+
+func (d *Driver) virsh() Virsh {
+	if d.vsh == nil {
+		d.vsh = NewVirsh(d.ConnectionURI)
+	}
+	return d.vsh
+}
+
+func (d *Driver) volumeName() string {
+	return fmt.Sprintf("%s.img", d.MachineName)
+}
+
+func (d *Driver) isoVolumeName() string {
+	return fmt.Sprintf("%s.iso", d.MachineName)
+}
+
+// PreCreateCheck checks that the machine creation process can be started safely.
+func (d *Driver) PreCreateCheck() error {
+	// Check that libvirt answers and has what the machine needs
+	if err := d.virsh().virsh("version"); err != nil {
+		return err
+	}
+	if err := d.virsh().virsh("pool-info", d.StoragePool); err != nil {
+		return fmt.Errorf("storage pool %q not found: %s", d.StoragePool, err)
+	}
+	if err := d.virsh().virsh("net-info", d.Network); err != nil {
+		return fmt.Errorf("network %q not found: %s", d.Network, err)
+	}
+
+	// Downloading boot2docker to cache should be done here to make sure
+	// that a download failure will not leave a machine half created.
+	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
+	return b2dutils.UpdateISOCache(d.Boot2DockerURL)
+}
+
+func (d *Driver) Create() error {
+	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+		return err
+	}
+
+	log.Infof("Creating SSH key...")
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return err
+	}
+
+	log.Infof("Creating volume %s in pool %s...", d.volumeName(), d.StoragePool)
+	if err := d.createVolume(); err != nil {
+		return err
+	}
+
+	log.Infof("Creating VM...")
+	definition, err := d.domainDefinition()
+	if err != nil {
+		return err
+	}
+
+	domainFile := d.ResolveStorePath("domain.xml")
+	if err := ioutil.WriteFile(domainFile, []byte(definition), 0644); err != nil {
+		return err
+	}
+
+	if err := d.virsh().virsh("define", domainFile); err != nil {
+		return err
+	}
+
+	log.Infof("Starting VM...")
+	return d.Start()
+}
+
+// createVolume creates the disk of the machine in the storage pool. The disk
+// starts with a tar of the SSH key which boot2docker picks up and formats the
+// disk around.
+func (d *Driver) createVolume() error {
+	if err := d.virsh().virsh("vol-create-as", d.StoragePool, d.volumeName(), fmt.Sprintf("%dM", d.DiskSize), "--format", "raw"); err != nil {
+		return err
+	}
+
+	tarBuf, err := mcnutils.MakeDiskImage(d.GetSSHKeyPath() + ".pub")
+	if err != nil {
+		return err
+	}
+
+	diskImage := d.ResolveStorePath("userdata.tar")
+	if err := ioutil.WriteFile(diskImage, tarBuf.Bytes(), 0644); err != nil {
+		return err
+	}
+	defer os.Remove(diskImage)
+
+	return d.virsh().virsh("vol-upload", "--pool", d.StoragePool, d.volumeName(), diskImage)
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	stdout, err := d.virsh().virshOut("domstate", d.MachineName)
+	if err != nil {
+		return state.Error, err
+	}
+
+	switch strings.TrimSpace(stdout) {
+	case "running", "in shutdown":
+		return state.Running, nil
+	case "paused", "pmsuspended":
+		return state.Paused, nil
+	case "shut off", "crashed":
+		return state.Stopped, nil
+	}
+	return state.None, nil
+}
+
+// GetIP returns the address leased to the machine by the libvirt network,
+// or else the one the host has seen it use, or else the one read from its
+// console when it was started.
+func (d *Driver) GetIP() (string, error) {
+	s, err := d.GetState()
+	if err != nil {
+		return "", err
+	}
+	if s != state.Running {
+		return "", drivers.ErrHostIsNotRunning
+	}
+
+	for _, source := range []string{"lease", "arp"} {
+		stdout, err := d.virsh().virshOut("domifaddr", d.MachineName, "--source", source)
+		if err != nil {
+			log.Debugf("Unable to get the address of %s from the %s: %s", d.MachineName, source, err)
+			continue
+		}
+
+		if ip := parseIPv4Address(stdout); ip != "" {
+			return ip, nil
+		}
+	}
+
+	if d.IPAddress != "" {
+		return d.IPAddress, nil
+	}
+
+	return "", fmt.Errorf("IP not found")
+}
+
+// waitForIP waits until the machine has an IP, known to libvirt or read from
+// its console
+func (d *Driver) waitForIP(consoleIP <-chan string) (string, error) {
+	log.Infof("Waiting for an IP...")
+
+	var ip string
+	err := mcnutils.WaitForSpecific(func() bool {
+		select {
+		case ip = <-consoleIP:
+			if ip != "" {
+				return true
+			}
+		default:
+		}
+
+		ip, _ = d.GetIP()
+		return ip != ""
+	}, int(defaultIPTimeout/time.Second), time.Second)
+	if err != nil {
+		return "", fmt.Errorf("Machine didn't get an IP. Check its console with `virsh --connect %s console %s`", d.ConnectionURI, d.MachineName)
+	}
+
+	return ip, nil
+}
+
+// waitStopped waits until the machine is stopped
+func (d *Driver) waitStopped() error {
+	return mcnutils.WaitFor(func() bool {
+		s, err := d.GetState()
+		return err == nil && s != state.Running
+	})
+}
+
+// uploadISO uploads the ISO of the machine directory to a volume of the
+// storage pool, for the machine to boot from it wherever the libvirt daemon
+// runs. It does nothing when the ISO was not changed, e.g. by an upgrade,
+// since the last upload.
+func (d *Driver) uploadISO() error {
+	isoPath := d.ResolveStorePath(isoFilename)
+	fi, err := os.Stat(isoPath)
+	if err != nil {
+		return err
+	}
+
+	version := fmt.Sprintf("%d-%d", fi.Size(), fi.ModTime().UnixNano())
+	if version == d.UploadedISO {
+		return nil
+	}
+
+	log.Infof("Uploading %s to pool %s...", isoFilename, d.StoragePool)
+	if err := d.removeVolume(d.isoVolumeName()); err != nil {
+		return err
+	}
+	if err := d.virsh().virsh("vol-create-as", d.StoragePool, d.isoVolumeName(), fmt.Sprintf("%d", fi.Size()), "--format", "raw"); err != nil {
+		return err
+	}
+	if err := d.virsh().virsh("vol-upload", "--pool", d.StoragePool, d.isoVolumeName(), isoPath); err != nil {
+		return err
+	}
+
+	d.UploadedISO = version
+
+	return nil
+}
+
+// Start starts the machine
+func (d *Driver) Start() error {
+	if err := d.uploadISO(); err != nil {
+		return err
+	}
+
+	if err := d.virsh().virsh("start", d.MachineName); err != nil {
+		return err
+	}
+
+	// The console is watched from the start for the address the machine
+	// leases, for the networks the addresses of which libvirt does not
+	// know, e.g. bridged ones.
+	stop := make(chan struct{})
+	defer close(stop)
+	consoleIP := make(chan string, 1)
+	go func() {
+		output, err := d.virsh().console(d.MachineName, reConsoleLease, stop)
+		if err != nil {
+			log.Debugf("Unable to read the address of %s from its console: %s", d.MachineName, err)
+		}
+		consoleIP <- output
+	}()
+
+	ip, err := d.waitForIP(consoleIP)
+	if err != nil {
+		return err
+	}
+
+	d.IPAddress = ip
+
+	return nil
+}
+
+// Stop gracefully stops the machine
+func (d *Driver) Stop() error {
+	if err := d.virsh().virsh("shutdown", d.MachineName); err != nil {
+		return err
+	}
+
+	if err := d.waitStopped(); err != nil {
+		return err
+	}
+
+	d.IPAddress = ""
+
+	return nil
+}
+
+// Restart stops and starts the machine
+func (d *Driver) Restart() error {
+	if err := d.Stop(); err != nil {
+		return err
+	}
+
+	return d.Start()
+}
+
+// Kill forcefully stops the machine
+func (d *Driver) Kill() error {
+	if err := d.virsh().virsh("destroy", d.MachineName); err != nil {
+		return err
+	}
+
+	d.IPAddress = ""
+
+	return nil
+}
+
+// Remove deletes the machine and its disk
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err == ErrMachineNotExist {
+		log.Infof("machine does not exist, assuming it has been removed already")
+		return d.removeVolumes()
+	}
+	if err != nil {
+		return err
+	}
+
+	if s == state.Running || s == state.Paused {
+		if err := d.Kill(); err != nil {
+			return err
+		}
+	}
+
+	if err := d.virsh().virsh("undefine", d.MachineName); err != nil {
+		return err
+	}
+
+	return d.removeVolumes()
+}
+
+func (d *Driver) removeVolumes() error {
+	if err := d.removeVolume(d.volumeName()); err != nil {
+		return err
+	}
+
+	return d.removeVolume(d.isoVolumeName())
+}
+
+func (d *Driver) removeVolume(name string) error {
+	if err := d.virsh().virsh("vol-info", "--pool", d.StoragePool, name); err != nil {
+		log.Debugf("Volume %s not found, nothing to delete: %s", name, err)
+		return nil
+	}
+
+	return d.virsh().virsh("vol-delete", "--pool", d.StoragePool, name)
+}
@@ -0,0 +1,170 @@
+package libvirt
+
+import (
+	"io/ioutil"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type virshMock struct {
+	outputs   map[string]string
+	calls     []string
+	consoleIP string
+}
+
+func (v *virshMock) virsh(args ...string) error {
+	_, err := v.virshOut(args...)
+	return err
+}
+
+func (v *virshMock) virshOut(args ...string) (string, error) {
+	v.calls = append(v.calls, args[0])
+	if output, ok := v.outputs[args[0]]; ok {
+		return output, nil
+	}
+	return "", ErrMachineNotExist
+}
+
+func (v *virshMock) console(domain string, match *regexp.Regexp, stop <-chan struct{}) (string, error) {
+	if v.consoleIP == "" {
+		<-stop
+		return "", nil
+	}
+	return v.consoleIP, nil
+}
+
+func TestSetConfigFromFlags(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"libvirt-memory":       2048,
+			"libvirt-storage-pool": "images",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Empty(t, checkFlags.InvalidFlags)
+	assert.Equal(t, defaultConnectionURI, driver.ConnectionURI)
+	assert.Equal(t, 2048, driver.Memory)
+	assert.Equal(t, "images", driver.StoragePool)
+	assert.Equal(t, defaultNetwork, driver.Network)
+	assert.Equal(t, "docker", driver.GetSSHUsername())
+}
+
+func TestGetState(t *testing.T) {
+	var tests = []struct {
+		domstate string
+		expected state.State
+	}{
+		{"running\n", state.Running},
+		{"paused\n", state.Paused},
+		{"shut off\n", state.Stopped},
+		{"idle\n", state.None},
+	}
+
+	for _, test := range tests {
+		driver := NewDriver("default", "path")
+		driver.vsh = &virshMock{outputs: map[string]string{"domstate": test.domstate}}
+
+		s, err := driver.GetState()
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, s)
+	}
+}
+
+func TestGetIPFallsBackToARP(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.vsh = &virshMock{outputs: map[string]string{
+		"domstate":  "running",
+		"domifaddr": " vnet0      52:54:00:aa:bb:cc    ipv4         192.168.122.45/24\n",
+	}}
+
+	ip, err := driver.GetIP()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.122.45", ip)
+}
+
+func TestRemoveMissingMachine(t *testing.T) {
+	mock := &virshMock{outputs: map[string]string{}}
+	driver := NewDriver("default", "path")
+	driver.vsh = mock
+
+	err := driver.Remove()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"domstate", "vol-info", "vol-info"}, mock.calls)
+}
+
+func TestDomainDefinition(t *testing.T) {
+	driver := NewDriver("default", "/store")
+
+	definition, err := driver.domainDefinition()
+
+	assert.NoError(t, err)
+	assert.Contains(t, definition, "<name>default</name>")
+	assert.Contains(t, definition, "<source pool='default' volume='default.img'/>")
+	assert.Contains(t, definition, "<source pool='default' volume='default.iso'/>")
+	assert.Contains(t, definition, "<model type='virtio'/>")
+}
+
+func TestUploadISO(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "libvirt-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(storePath)
+
+	driver := NewDriver("default", storePath)
+	assert.NoError(t, os.MkdirAll(driver.ResolveStorePath("."), 0700))
+	assert.NoError(t, ioutil.WriteFile(driver.ResolveStorePath(isoFilename), []byte("iso"), 0644))
+
+	mock := &virshMock{outputs: map[string]string{"vol-create-as": "", "vol-upload": ""}}
+	driver.vsh = mock
+
+	assert.NoError(t, driver.uploadISO())
+	assert.Equal(t, []string{"vol-info", "vol-create-as", "vol-upload"}, mock.calls)
+	assert.NotEmpty(t, driver.UploadedISO)
+
+	mock.calls = nil
+	assert.NoError(t, driver.uploadISO())
+	assert.Empty(t, mock.calls)
+}
+
+func TestWaitForIPFromConsole(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.vsh = &virshMock{outputs: map[string]string{
+		"domstate":  "running",
+		"domifaddr": "",
+	}}
+
+	consoleIP := make(chan string, 1)
+	consoleIP <- "192.168.1.20"
+
+	ip, err := driver.waitForIP(consoleIP)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.1.20", ip)
+}
+
+func TestGetIPFallsBackToConsoleAddress(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.IPAddress = "192.168.1.20"
+	driver.vsh = &virshMock{outputs: map[string]string{
+		"domstate":  "running",
+		"domifaddr": "",
+	}}
+
+	ip, err := driver.GetIP()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.1.20", ip)
+}
@@ -0,0 +1,26 @@
+package libvirt
+
+import "github.com/docker/machine/libmachine/drivers"
+
+// minSize is the smallest domain boot2docker runs docker in.
+var minSize = drivers.Size{Memory: 512, Disk: 1024}
+
+// SetSize sets the memory of the domain and the size of its volume.
+func (d *Driver) SetSize(size drivers.Size) error {
+	if err := size.Check(minSize); err != nil {
+		return err
+	}
+
+	if size.Memory != 0 {
+		d.Memory = size.Memory
+	}
+	if size.Disk != 0 {
+		d.DiskSize = size.Disk
+	}
+	return nil
+}
+
+// GetSize returns the memory of the domain and the size of its volume.
+func (d *Driver) GetSize() (drivers.Size, error) {
+	return drivers.Size{Memory: d.Memory, Disk: d.DiskSize}, nil
+}
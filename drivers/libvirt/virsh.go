@@ -0,0 +1,106 @@
+package libvirt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+var (
+	reDomainNotFound = regexp.MustCompile(`(?i)failed to get domain|domain not found`)
+	reIPv4Address    = regexp.MustCompile(`ipv4\s+(\d+\.\d+\.\d+\.\d+)/\d+`)
+	reConsoleLease   = regexp.MustCompile(`lease of (\d+\.\d+\.\d+\.\d+) obtained`)
+
+	ErrVirshNotFound   = errors.New("virsh not found. Make sure libvirt is installed and virsh is in the path")
+	ErrMachineNotExist = errors.New("machine does not exist")
+)
+
+// Virsh defines the interface to communicate with libvirt.
+type Virsh interface {
+	virsh(args ...string) error
+
+	virshOut(args ...string) (string, error)
+
+	// console reads the console of the domain until a line matches or stop
+	// is closed, and returns the first submatch.
+	console(domain string, match *regexp.Regexp, stop <-chan struct{}) (string, error)
+}
+
+// VirshCmd communicates with libvirt through the commandline using `virsh`.
+type VirshCmd struct {
+	connectionURI string
+	runCmd        func(cmd *exec.Cmd) error
+}
+
+// NewVirsh creates a Virsh instance connected to the given libvirt URI.
+func NewVirsh(connectionURI string) *VirshCmd {
+	return &VirshCmd{
+		connectionURI: connectionURI,
+		runCmd:        func(cmd *exec.Cmd) error { return cmd.Run() },
+	}
+}
+
+func (v *VirshCmd) virsh(args ...string) error {
+	_, err := v.virshOut(args...)
+	return err
+}
+
+func (v *VirshCmd) virshOut(args ...string) (string, error) {
+	args = append([]string{"--quiet", "--connect", v.connectionURI}, args...)
+	cmd := exec.Command("virsh", args...)
+	log.Debugf("COMMAND: virsh %v", strings.Join(args, " "))
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := v.runCmd(cmd)
+	stderrStr := stderr.String()
+	log.Debugf("STDOUT:\n{\n%v}", stdout.String())
+	log.Debugf("STDERR:\n{\n%v}", stderrStr)
+
+	if err != nil {
+		if ee, ok := err.(*exec.Error); ok && ee.Err == exec.ErrNotFound {
+			return "", ErrVirshNotFound
+		}
+		if reDomainNotFound.MatchString(stderrStr) {
+			return "", ErrMachineNotExist
+		}
+		return "", fmt.Errorf("virsh %v failed:\n%v", strings.Join(args, " "), stderrStr)
+	}
+
+	return stdout.String(), nil
+}
+
+// parseIPv4Address returns the first IPv4 address in the output of
+// `virsh domifaddr`.
+func parseIPv4Address(domifaddr string) string {
+	s := bufio.NewScanner(strings.NewReader(domifaddr))
+	for s.Scan() {
+		if matches := reIPv4Address.FindStringSubmatch(s.Text()); matches != nil {
+			return matches[1]
+		}
+	}
+
+	return ""
+}
+
+// scanConsole returns the first submatch of the first line of the console
+// output which matches.
+func scanConsole(r io.Reader, match *regexp.Regexp) string {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		if matches := match.FindStringSubmatch(s.Text()); matches != nil {
+			return matches[1]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,54 @@
+package libvirt
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVirshOut(t *testing.T) {
+	var cmdRun *exec.Cmd
+	v := NewVirsh("qemu:///system")
+	v.runCmd = func(cmd *exec.Cmd) error {
+		cmdRun = cmd
+		fmt.Fprint(cmd.Stdout, "running")
+		return nil
+	}
+
+	stdout, err := v.virshOut("domstate", "default")
+
+	assert.Equal(t, "running", stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"virsh", "--quiet", "--connect", "qemu:///system", "domstate", "default"}, cmdRun.Args)
+}
+
+func TestVirshOutDomainNotFound(t *testing.T) {
+	v := NewVirsh("qemu:///system")
+	v.runCmd = func(cmd *exec.Cmd) error {
+		fmt.Fprint(cmd.Stderr, "error: failed to get domain 'default'")
+		return fmt.Errorf("exit status 1")
+	}
+
+	_, err := v.virshOut("domstate", "default")
+
+	assert.Equal(t, ErrMachineNotExist, err)
+}
+
+func TestParseIPv4Address(t *testing.T) {
+	domifaddr := ` vnet0      52:54:00:aa:bb:cc    ipv6         fe80::5054:ff:feaa:bbcc/64
+ vnet0      52:54:00:aa:bb:cc    ipv4         192.168.122.45/24
+`
+
+	assert.Equal(t, "192.168.122.45", parseIPv4Address(domifaddr))
+	assert.Equal(t, "", parseIPv4Address(""))
+}
+
+func TestScanConsole(t *testing.T) {
+	console := "Booting Linux\r\nudhcpc: sending discover\r\nudhcpc: lease of 192.168.1.20 obtained, lease time 86400\r\n"
+
+	assert.Equal(t, "192.168.1.20", scanConsole(strings.NewReader(console), reConsoleLease))
+	assert.Equal(t, "", scanConsole(strings.NewReader("Booting Linux\r\n"), reConsoleLease))
+}
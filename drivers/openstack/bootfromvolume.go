@@ -0,0 +1,51 @@
+package openstack
+
+import (
+	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+)
+
+// The vendored gophercloud has no block device mapping extension, hence the
+// options below.
+
+type blockDeviceMapping struct {
+	BootIndex           int    `json:"boot_index"`
+	UUID                string `json:"uuid"`
+	SourceType          string `json:"source_type"`
+	DestinationType     string `json:"destination_type"`
+	VolumeSize          int    `json:"volume_size"`
+	DeleteOnTermination bool   `json:"delete_on_termination"`
+}
+
+// bootFromVolumeOptsExt makes the server boot from a new volume holding the
+// image rather than from an ephemeral disk.
+type bootFromVolumeOptsExt struct {
+	servers.CreateOptsBuilder
+	ImageID             string
+	VolumeSize          int
+	DeleteOnTermination bool
+}
+
+// ToServerCreateMap adds the block device mapping of the boot volume to the
+// base server creation options.
+func (opts bootFromVolumeOptsExt) ToServerCreateMap() (map[string]interface{}, error) {
+	base, err := opts.CreateOptsBuilder.ToServerCreateMap()
+	if err != nil {
+		return nil, err
+	}
+
+	serverMap := base["server"].(map[string]interface{})
+	// The image is the source of the volume, not of the server
+	serverMap["imageRef"] = ""
+	serverMap["block_device_mapping_v2"] = []blockDeviceMapping{
+		{
+			BootIndex:           0,
+			UUID:                opts.ImageID,
+			SourceType:          "image",
+			DestinationType:     "volume",
+			VolumeSize:          opts.VolumeSize,
+			DeleteOnTermination: opts.DeleteOnTermination,
+		},
+	}
+
+	return base, nil
+}
@@ -0,0 +1,61 @@
+package openstack
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/rackspace/gophercloud/openstack/compute/v2/servers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootFromVolumeOptsExt(t *testing.T) {
+	opts := bootFromVolumeOptsExt{
+		CreateOptsBuilder: servers.CreateOpts{
+			Name:      "default",
+			FlavorRef: "flavor",
+			ImageRef:  "image",
+		},
+		ImageID:             "image",
+		VolumeSize:          20,
+		DeleteOnTermination: true,
+	}
+
+	createMap, err := opts.ToServerCreateMap()
+
+	assert.NoError(t, err)
+	server := createMap["server"].(map[string]interface{})
+	assert.Equal(t, "", server["imageRef"])
+	assert.Equal(t, []blockDeviceMapping{
+		{
+			BootIndex:           0,
+			UUID:                "image",
+			SourceType:          "image",
+			DestinationType:     "volume",
+			VolumeSize:          20,
+			DeleteOnTermination: true,
+		},
+	}, server["block_device_mapping_v2"])
+}
+
+func TestBootFromVolumeRequiresSize(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"openstack-auth-url":         "http://url",
+			"openstack-username":         "user",
+			"openstack-password":         "pwd",
+			"openstack-tenant-id":        "ID",
+			"openstack-flavor-id":        "ID",
+			"openstack-image-id":         "ID",
+			"openstack-boot-from-volume": true,
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	assert.EqualError(t, driver.SetConfigFromFlags(checkFlags), errorVolumeSize)
+
+	checkFlags.FlagsValues["openstack-volume-size"] = 20
+
+	assert.NoError(t, driver.SetConfigFromFlags(checkFlags))
+}
@@ -38,6 +38,7 @@ type Client interface {
 	StopInstance(d *Driver) error
 	RestartInstance(d *Driver) error
 	DeleteInstance(d *Driver) error
+	GetInstanceMetadata(d *Driver) (map[string]string, error)
 	WaitForInstanceStatus(d *Driver, status string) error
 	GetInstanceIPAddresses(d *Driver) ([]IPAddress, error)
 	GetPublicKey(keyPairName string) ([]byte, error)
@@ -68,6 +69,7 @@ func (c *GenericClient) CreateInstance(d *Driver) (string, error) {
 		UserData:         d.UserData,
 		SecurityGroups:   d.SecurityGroups,
 		AvailabilityZone: d.AvailabilityZone,
+		Metadata:         d.ResourceTags,
 	}
 	if d.NetworkId != "" {
 		serverOpts.Networks = []servers.Network{
@@ -79,10 +81,20 @@ func (c *GenericClient) CreateInstance(d *Driver) (string, error) {
 
 	log.Info("Creating machine...")
 
-	server, err := servers.Create(c.Compute, keypairs.CreateOptsExt{
+	var createOpts servers.CreateOptsBuilder = keypairs.CreateOptsExt{
 		serverOpts,
 		d.KeyPairName,
-	}).Extract()
+	}
+	if d.BootFromVolume {
+		createOpts = bootFromVolumeOptsExt{
+			CreateOptsBuilder:   createOpts,
+			ImageID:             d.ImageId,
+			VolumeSize:          d.VolumeSize,
+			DeleteOnTermination: d.VolumeDeleteOnTermination,
+		}
+	}
+
+	server, err := servers.Create(c.Compute, createOpts).Extract()
 	if err != nil {
 		return "", err
 	}
@@ -147,6 +159,19 @@ func (c *GenericClient) DeleteInstance(d *Driver) error {
 	return nil
 }
 
+// GetInstanceMetadata returns the metadata of the server, or the resource
+// tags of the machine if the server no longer exists, for it to be removed.
+func (c *GenericClient) GetInstanceMetadata(d *Driver) (map[string]string, error) {
+	metadata, err := servers.Metadata(c.Compute, d.MachineId).Extract()
+	if err != nil {
+		if respErr, ok := err.(*gophercloud.UnexpectedResponseCodeError); ok && respErr.Actual == 404 {
+			return d.ResourceTags, nil
+		}
+		return nil, err
+	}
+	return metadata, nil
+}
+
 func (c *GenericClient) WaitForInstanceStatus(d *Driver, status string) error {
 	return mcnutils.WaitForSpecificOrError(func() (bool, error) {
 		current, err := servers.Get(c.Compute, d.MachineId).Extract()
@@ -532,22 +557,31 @@ func (c *GenericClient) Authenticate(d *Driver) error {
 	}
 
 	log.Debug("Authenticating...", map[string]interface{}{
-		"AuthUrl":    d.AuthUrl,
-		"Insecure":   d.Insecure,
-		"CaCert":     d.CaCert,
-		"DomainID":   d.DomainID,
-		"DomainName": d.DomainName,
-		"Username":   d.Username,
-		"TenantName": d.TenantName,
-		"TenantID":   d.TenantId,
+		"AuthUrl":                 d.AuthUrl,
+		"Insecure":                d.Insecure,
+		"CaCert":                  d.CaCert,
+		"DomainID":                d.DomainID,
+		"DomainName":              d.DomainName,
+		"UserDomainID":            d.UserDomainID,
+		"UserDomainName":          d.UserDomainName,
+		"ProjectDomainID":         d.ProjectDomainID,
+		"ProjectDomainName":       d.ProjectDomainName,
+		"Username":                d.Username,
+		"TenantName":              d.TenantName,
+		"TenantID":                d.TenantId,
+		"ApplicationCredentialID": d.ApplicationCredentialID,
+		"IdentityAPIVersion":      d.IdentityAPIVersion,
 	})
 
+	// No password is needed with an application credential
+	password, _ := d.getPassword()
+
 	opts := gophercloud.AuthOptions{
 		IdentityEndpoint: d.AuthUrl,
 		DomainID:         d.DomainID,
 		DomainName:       d.DomainName,
 		Username:         d.Username,
-		Password:         d.Password,
+		Password:         password,
 		TenantName:       d.TenantName,
 		TenantID:         d.TenantId,
 		AllowReauth:      true,
@@ -567,7 +601,14 @@ func (c *GenericClient) Authenticate(d *Driver) error {
 		return err
 	}
 
-	err = openstack.Authenticate(c.Provider, opts)
+	switch {
+	case d.usesIdentityV3():
+		err = c.authenticateV3(d)
+	case d.IdentityAPIVersion == identityAPIVersion2:
+		err = openstack.AuthenticateV2(c.Provider, opts)
+	default:
+		err = openstack.Authenticate(c.Provider, opts)
+	}
 	if err != nil {
 		return err
 	}
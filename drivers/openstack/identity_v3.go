@@ -0,0 +1,250 @@
+package openstack
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/mitchellh/mapstructure"
+	"github.com/rackspace/gophercloud"
+	"github.com/rackspace/gophercloud/openstack"
+	tokens3 "github.com/rackspace/gophercloud/openstack/identity/v3/tokens"
+)
+
+// The Keystone v3 support of the vendored gophercloud cannot tell the domain
+// of the user from the domain of the project and knows nothing about
+// application credentials, hence the token request built here.
+
+const (
+	identityAPIVersion2 = "2"
+	identityAPIVersion3 = "3"
+
+	// defaultDomainID is the ID of the domain Keystone creates on install,
+	// used when the domain of the user is not given.
+	defaultDomainID = "default"
+)
+
+type v3Domain struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type v3User struct {
+	ID       string    `json:"id,omitempty"`
+	Name     string    `json:"name,omitempty"`
+	Password string    `json:"password,omitempty"`
+	Domain   *v3Domain `json:"domain,omitempty"`
+}
+
+type v3Password struct {
+	User v3User `json:"user"`
+}
+
+type v3ApplicationCredential struct {
+	ID     string  `json:"id,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Secret string  `json:"secret"`
+	User   *v3User `json:"user,omitempty"`
+}
+
+type v3Identity struct {
+	Methods               []string                 `json:"methods"`
+	Password              *v3Password              `json:"password,omitempty"`
+	ApplicationCredential *v3ApplicationCredential `json:"application_credential,omitempty"`
+}
+
+type v3Project struct {
+	ID     string    `json:"id,omitempty"`
+	Name   string    `json:"name,omitempty"`
+	Domain *v3Domain `json:"domain,omitempty"`
+}
+
+type v3Scope struct {
+	Project *v3Project `json:"project,omitempty"`
+	Domain  *v3Domain  `json:"domain,omitempty"`
+}
+
+type v3Auth struct {
+	Identity v3Identity `json:"identity"`
+	Scope    *v3Scope   `json:"scope,omitempty"`
+}
+
+type v3AuthRequest struct {
+	Auth v3Auth `json:"auth"`
+}
+
+// usesIdentityV3 tells whether the driver authenticates with Keystone v3
+// rather than with the identity version picked by gophercloud.
+func (d *Driver) usesIdentityV3() bool {
+	return d.IdentityAPIVersion == identityAPIVersion3 ||
+		d.ApplicationCredentialID != "" || d.ApplicationCredentialName != "" ||
+		d.UserDomainID != "" || d.UserDomainName != "" ||
+		d.ProjectDomainID != "" || d.ProjectDomainName != ""
+}
+
+// usesApplicationCredential tells whether the driver authenticates with an
+// application credential instead of a user name and password.
+func (d *Driver) usesApplicationCredential() bool {
+	return d.ApplicationCredentialID != "" || d.ApplicationCredentialName != ""
+}
+
+// userDomain returns the domain of the user, falling back on the domain
+// options and then on the default domain.
+func (d *Driver) userDomain() *v3Domain {
+	switch {
+	case d.UserDomainID != "":
+		return &v3Domain{ID: d.UserDomainID}
+	case d.UserDomainName != "":
+		return &v3Domain{Name: d.UserDomainName}
+	case d.DomainID != "":
+		return &v3Domain{ID: d.DomainID}
+	case d.DomainName != "":
+		return &v3Domain{Name: d.DomainName}
+	}
+	return &v3Domain{ID: defaultDomainID}
+}
+
+// projectDomain returns the domain of the project, falling back on the
+// domain options and then on the domain of the user.
+func (d *Driver) projectDomain() *v3Domain {
+	switch {
+	case d.ProjectDomainID != "":
+		return &v3Domain{ID: d.ProjectDomainID}
+	case d.ProjectDomainName != "":
+		return &v3Domain{Name: d.ProjectDomainName}
+	}
+	return d.userDomain()
+}
+
+// newV3AuthRequest builds the body of a Keystone v3 token request. Tokens
+// are scoped to the project, or to the domain when no project is given.
+// Application credentials are bound to a project and cannot be scoped.
+func (d *Driver) newV3AuthRequest() (*v3AuthRequest, error) {
+	req := &v3AuthRequest{}
+
+	if d.usesApplicationCredential() {
+		secret, err := d.getApplicationCredentialSecret()
+		if err != nil {
+			return nil, fmt.Errorf(errorMandatoryEnvOrOption, "Application credential secret", "OS_APPLICATION_CREDENTIAL_SECRET", "--openstack-application-credential-secret")
+		}
+
+		credential := &v3ApplicationCredential{
+			ID:     d.ApplicationCredentialID,
+			Secret: secret,
+		}
+		if d.ApplicationCredentialID == "" {
+			// Names are only unique per user
+			if d.Username == "" {
+				return nil, fmt.Errorf(errorMandatoryEnvOrOption, "Username", "OS_USERNAME", "--openstack-username")
+			}
+			credential.Name = d.ApplicationCredentialName
+			credential.User = &v3User{
+				Name:   d.Username,
+				Domain: d.userDomain(),
+			}
+		}
+
+		req.Auth.Identity = v3Identity{
+			Methods:               []string{"application_credential"},
+			ApplicationCredential: credential,
+		}
+		return req, nil
+	}
+
+	password, err := d.getPassword()
+	if err != nil {
+		return nil, fmt.Errorf(errorMandatoryEnvOrOption, "Password", "OS_PASSWORD", "--openstack-password")
+	}
+
+	req.Auth.Identity = v3Identity{
+		Methods: []string{"password"},
+		Password: &v3Password{
+			User: v3User{
+				Name:     d.Username,
+				Password: password,
+				Domain:   d.userDomain(),
+			},
+		},
+	}
+
+	switch {
+	case d.TenantId != "":
+		req.Auth.Scope = &v3Scope{Project: &v3Project{ID: d.TenantId}}
+	case d.TenantName != "":
+		req.Auth.Scope = &v3Scope{Project: &v3Project{Name: d.TenantName, Domain: d.projectDomain()}}
+	case d.DomainID != "":
+		req.Auth.Scope = &v3Scope{Domain: &v3Domain{ID: d.DomainID}}
+	case d.DomainName != "":
+		req.Auth.Scope = &v3Scope{Domain: &v3Domain{Name: d.DomainName}}
+	}
+
+	return req, nil
+}
+
+// identityV3Endpoint returns the URL of the Keystone v3 API, which is the
+// authentication URL itself when it already points to it.
+func identityV3Endpoint(provider *gophercloud.ProviderClient) string {
+	if strings.HasSuffix(provider.IdentityEndpoint, "/v3/") {
+		return provider.IdentityEndpoint
+	}
+	return provider.IdentityBase + "v3/"
+}
+
+// authenticateV3 gets a token from Keystone v3 and sets the provider up to
+// locate the services of the catalog returned along with it. The project of
+// the token is recorded on the driver when only its name was given.
+func (c *GenericClient) authenticateV3(d *Driver) error {
+	req, err := d.newV3AuthRequest()
+	if err != nil {
+		return err
+	}
+
+	// A failed authentication must not trigger another one
+	c.Provider.ReauthFunc = nil
+	c.Provider.TokenID = ""
+
+	var result tokens3.CreateResult
+	resp, err := c.Provider.Post(identityV3Endpoint(c.Provider)+"auth/tokens", req, &result.Body, nil)
+	if err != nil {
+		return err
+	}
+	result.Header = resp.Header
+
+	token, err := result.ExtractToken()
+	if err != nil {
+		return err
+	}
+
+	catalog, err := result.ExtractServiceCatalog()
+	if err != nil {
+		return err
+	}
+
+	var body struct {
+		Token struct {
+			Project struct {
+				ID string `mapstructure:"id"`
+			} `mapstructure:"project"`
+		} `mapstructure:"token"`
+	}
+	if err := mapstructure.Decode(result.Body, &body); err != nil {
+		return err
+	}
+	if d.TenantId == "" && body.Token.Project.ID != "" {
+		d.TenantId = body.Token.Project.ID
+		log.Debug("Found tenant id in the token", map[string]string{
+			"Name": d.TenantName,
+			"ID":   d.TenantId,
+		})
+	}
+
+	c.Provider.TokenID = token.ID
+	c.Provider.ReauthFunc = func() error {
+		return c.authenticateV3(d)
+	}
+	c.Provider.EndpointLocator = func(opts gophercloud.EndpointOpts) (string, error) {
+		return openstack.V3EndpointURL(catalog, opts)
+	}
+
+	return nil
+}
@@ -0,0 +1,164 @@
+package openstack
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/rackspace/gophercloud"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetConfigFromFlagsWithApplicationCredential(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"openstack-auth-url":                      "http://url",
+			"openstack-application-credential-id":     "ID",
+			"openstack-application-credential-secret": "secret",
+			"openstack-flavor-id":                     "ID",
+			"openstack-image-id":                      "ID",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Empty(t, checkFlags.InvalidFlags)
+	assert.True(t, driver.(*Driver).usesIdentityV3())
+}
+
+func TestSetConfigFromFlagsWithApplicationCredentialWithoutSecret(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"openstack-auth-url":                  "http://url",
+			"openstack-application-credential-id": "ID",
+			"openstack-flavor-id":                 "ID",
+			"openstack-image-id":                  "ID",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.Error(t, err)
+}
+
+func TestSetConfigFromFlagsWithProjectName(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"openstack-auth-url":            "http://url",
+			"openstack-username":            "user",
+			"openstack-password":            "pwd",
+			"openstack-project-name":        "project",
+			"openstack-project-domain-name": "projects",
+			"openstack-flavor-id":           "ID",
+			"openstack-image-id":            "ID",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "project", driver.(*Driver).TenantName)
+	assert.True(t, driver.(*Driver).usesIdentityV3())
+}
+
+func TestV3AuthRequestScopesProjectInItsDomain(t *testing.T) {
+	d := NewDerivedDriver("default", "path")
+	d.Username = "user"
+	d.Password = "pwd"
+	d.UserDomainName = "users"
+	d.TenantName = "project"
+	d.ProjectDomainID = "projects-id"
+
+	req, err := d.newV3AuthRequest()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"password"}, req.Auth.Identity.Methods)
+	assert.Equal(t, &v3Domain{Name: "users"}, req.Auth.Identity.Password.User.Domain)
+	assert.Equal(t, &v3Project{Name: "project", Domain: &v3Domain{ID: "projects-id"}}, req.Auth.Scope.Project)
+}
+
+func TestV3AuthRequestDefaultsToDefaultDomain(t *testing.T) {
+	d := NewDerivedDriver("default", "path")
+	d.Username = "user"
+	d.Password = "pwd"
+	d.TenantName = "project"
+
+	req, err := d.newV3AuthRequest()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &v3Domain{ID: "default"}, req.Auth.Identity.Password.User.Domain)
+	assert.Equal(t, &v3Domain{ID: "default"}, req.Auth.Scope.Project.Domain)
+}
+
+func TestV3AuthRequestWithApplicationCredentialName(t *testing.T) {
+	d := NewDerivedDriver("default", "path")
+	d.Username = "user"
+	d.DomainName = "users"
+	d.ApplicationCredentialName = "machine"
+	d.ApplicationCredentialSecret = "secret"
+
+	req, err := d.newV3AuthRequest()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"application_credential"}, req.Auth.Identity.Methods)
+	assert.Equal(t, &v3ApplicationCredential{
+		Name:   "machine",
+		Secret: "secret",
+		User:   &v3User{Name: "user", Domain: &v3Domain{Name: "users"}},
+	}, req.Auth.Identity.ApplicationCredential)
+	assert.Nil(t, req.Auth.Scope)
+}
+
+func TestAuthenticateV3(t *testing.T) {
+	var received v3AuthRequest
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/identity/v3/auth/tokens", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+
+		w.Header().Set("X-Subject-Token", "token")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": {
+			"expires_at": "2030-01-01T00:00:00.000000Z",
+			"project": {"id": "project-id", "name": "project"},
+			"catalog": [{"type": "compute", "name": "nova", "endpoints": [
+				{"interface": "public", "region": "RegionOne", "url": "%s/compute/v2.1"}
+			]}]
+		}}`, server.URL)
+	})
+
+	d := NewDerivedDriver("default", "path")
+	d.AuthUrl = server.URL + "/identity/v3"
+	d.IdentityAPIVersion = "3"
+	d.Username = "user"
+	d.Password = "pwd"
+	d.TenantName = "project"
+
+	c := &GenericClient{}
+	err := c.Authenticate(d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "token", c.Provider.TokenID)
+	assert.Equal(t, "project-id", d.TenantId)
+	assert.Equal(t, "project", received.Auth.Scope.Project.Name)
+
+	url, err := c.Provider.EndpointLocator(gophercloud.EndpointOpts{Type: "compute", Availability: gophercloud.AvailabilityPublic})
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/compute/v2.1/", url)
+}
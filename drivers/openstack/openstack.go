@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/machine/libmachine/credentials"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
@@ -46,6 +47,24 @@ type Driver struct {
 	FloatingIpPoolId string
 	IpVersion        int
 	client           Client
+
+	// Keystone v3 only
+	IdentityAPIVersion          string
+	UserDomainID                string
+	UserDomainName              string
+	ProjectDomainID             string
+	ProjectDomainName           string
+	ApplicationCredentialID     string
+	ApplicationCredentialName   string
+	ApplicationCredentialSecret string
+
+	// Boot from volume only
+	BootFromVolume            bool
+	VolumeSize                int
+	VolumeDeleteOnTermination bool
+
+	// ResourceTags are set as metadata of the server, see SetResourceTags.
+	ResourceTags map[string]string `json:",omitempty"`
 }
 
 const (
@@ -85,6 +104,54 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "OpenStack domain name (identity v3 only)",
 			Value:  "",
 		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_USER_DOMAIN_ID",
+			Name:   "openstack-user-domain-id",
+			Usage:  "OpenStack domain ID of the user (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_USER_DOMAIN_NAME",
+			Name:   "openstack-user-domain-name",
+			Usage:  "OpenStack domain name of the user (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_PROJECT_DOMAIN_ID",
+			Name:   "openstack-project-domain-id",
+			Usage:  "OpenStack domain ID of the project (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_PROJECT_DOMAIN_NAME",
+			Name:   "openstack-project-domain-name",
+			Usage:  "OpenStack domain name of the project (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_IDENTITY_API_VERSION",
+			Name:   "openstack-identity-api-version",
+			Usage:  "OpenStack identity API version (2 or 3, detected when not set)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_APPLICATION_CREDENTIAL_ID",
+			Name:   "openstack-application-credential-id",
+			Usage:  "OpenStack application credential id (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_APPLICATION_CREDENTIAL_NAME",
+			Name:   "openstack-application-credential-name",
+			Usage:  "OpenStack application credential name (identity v3 only)",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_APPLICATION_CREDENTIAL_SECRET",
+			Name:   "openstack-application-credential-secret",
+			Usage:  "OpenStack application credential secret (identity v3 only)",
+			Value:  "",
+		},
 		mcnflag.StringFlag{
 			EnvVar: "OS_USERNAME",
 			Name:   "openstack-username",
@@ -109,6 +176,18 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "OpenStack tenant id",
 			Value:  "",
 		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_PROJECT_NAME",
+			Name:   "openstack-project-name",
+			Usage:  "OpenStack project name, same as the tenant name",
+			Value:  "",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "OS_PROJECT_ID",
+			Name:   "openstack-project-id",
+			Usage:  "OpenStack project id, same as the tenant id",
+			Value:  "",
+		},
 		mcnflag.StringFlag{
 			EnvVar: "OS_REGION_NAME",
 			Name:   "openstack-region",
@@ -222,6 +301,21 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "OpenStack active timeout",
 			Value:  defaultActiveTimeout,
 		},
+		mcnflag.BoolFlag{
+			EnvVar: "OS_BOOT_FROM_VOLUME",
+			Name:   "openstack-boot-from-volume",
+			Usage:  "Boot the machine from a volume created from the image, for flavors with no ephemeral disk",
+		},
+		mcnflag.IntFlag{
+			EnvVar: "OS_VOLUME_SIZE",
+			Name:   "openstack-volume-size",
+			Usage:  "OpenStack size of the boot volume in GB",
+		},
+		mcnflag.BoolFlag{
+			EnvVar: "OS_VOLUME_DELETE_ON_TERMINATION",
+			Name:   "openstack-volume-delete-on-termination",
+			Usage:  "Delete the boot volume along with the machine",
+		},
 	}
 }
 
@@ -266,6 +360,20 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.Password = flags.String("openstack-password")
 	d.TenantName = flags.String("openstack-tenant-name")
 	d.TenantId = flags.String("openstack-tenant-id")
+	if d.TenantName == "" {
+		d.TenantName = flags.String("openstack-project-name")
+	}
+	if d.TenantId == "" {
+		d.TenantId = flags.String("openstack-project-id")
+	}
+	d.IdentityAPIVersion = flags.String("openstack-identity-api-version")
+	d.UserDomainID = flags.String("openstack-user-domain-id")
+	d.UserDomainName = flags.String("openstack-user-domain-name")
+	d.ProjectDomainID = flags.String("openstack-project-domain-id")
+	d.ProjectDomainName = flags.String("openstack-project-domain-name")
+	d.ApplicationCredentialID = flags.String("openstack-application-credential-id")
+	d.ApplicationCredentialName = flags.String("openstack-application-credential-name")
+	d.ApplicationCredentialSecret = flags.String("openstack-application-credential-secret")
 	d.Region = flags.String("openstack-region")
 	d.AvailabilityZone = flags.String("openstack-availability-zone")
 	d.EndpointType = flags.String("openstack-endpoint-type")
@@ -285,6 +393,9 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.SSHPort = flags.Int("openstack-ssh-port")
 	d.KeyPairName = flags.String("openstack-keypair-name")
 	d.PrivateKeyFile = flags.String("openstack-private-key-file")
+	d.BootFromVolume = flags.Bool("openstack-boot-from-volume")
+	d.VolumeSize = flags.Int("openstack-volume-size")
+	d.VolumeDeleteOnTermination = flags.Bool("openstack-volume-delete-on-termination")
 
 	if flags.String("openstack-user-data-file") != "" {
 		userData, err := ioutil.ReadFile(flags.String("openstack-user-data-file"))
@@ -440,12 +551,28 @@ func (d *Driver) Kill() error {
 	return d.Stop()
 }
 
+// SetResourceTags records the tags set as metadata of the server. OpenStack
+// cannot attach metadata to key pairs.
+func (d *Driver) SetResourceTags(tags map[string]string) error {
+	d.ResourceTags = tags
+	return nil
+}
+
 func (d *Driver) Remove() error {
 	log.Debug("deleting instance...", map[string]string{"MachineId": d.MachineId})
 	log.Info("Deleting OpenStack instance...")
 	if err := d.initCompute(); err != nil {
 		return err
 	}
+	if len(d.ResourceTags) > 0 {
+		metadata, err := d.client.GetInstanceMetadata(d)
+		if err != nil {
+			return err
+		}
+		if err := drivers.CheckResourceTags("server "+d.MachineId, d.ResourceTags, metadata); err != nil {
+			return err
+		}
+	}
 	if err := d.client.DeleteInstance(d); err != nil {
 		return err
 	}
@@ -463,25 +590,65 @@ const (
 	errorExclusiveOptions        string = "Either %s or %s must be specified, not both"
 	errorBothOptions             string = "Both %s and %s must be specified"
 	errorMandatoryTenantNameOrID string = "Tenant id or name must be provided either using one of the environment variables OS_TENANT_ID and OS_TENANT_NAME or one of the CLI options --openstack-tenant-id and --openstack-tenant-name"
+	errorWrongIdentityAPIVersion string = "Identity API version must be '2' or '3'"
+	errorApplicationCredentialV2 string = "Application credentials require the identity API version 3"
 	errorWrongEndpointType       string = "Endpoint type must be 'publicURL', 'adminURL' or 'internalURL'"
 	errorUnknownFlavorName       string = "Unable to find flavor named %s"
 	errorUnknownImageName        string = "Unable to find image named %s"
 	errorUnknownNetworkName      string = "Unable to find network named %s"
 	errorUnknownTenantName       string = "Unable to find tenant named %s"
+	errorVolumeSize              string = "A volume size in GB must be specified to boot from a volume"
 )
 
+// getPassword returns the password given on the command line or, failing that,
+// the one found in a credential source. The latter is never stored on the
+// driver so that it does not end up in config.json.
+func (d *Driver) getPassword() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "password", d.Password)
+}
+
+// getApplicationCredentialSecret is getPassword for the secret of the
+// application credential.
+func (d *Driver) getApplicationCredentialSecret() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "application-credential-secret", d.ApplicationCredentialSecret)
+}
+
 func (d *Driver) checkConfig() error {
 	if d.AuthUrl == "" {
 		return fmt.Errorf(errorMandatoryEnvOrOption, "Authentication URL", "OS_AUTH_URL", "--openstack-auth-url")
 	}
-	if d.Username == "" {
-		return fmt.Errorf(errorMandatoryEnvOrOption, "Username", "OS_USERNAME", "--openstack-username")
+	if d.IdentityAPIVersion != "" && d.IdentityAPIVersion != identityAPIVersion2 && d.IdentityAPIVersion != identityAPIVersion3 {
+		return fmt.Errorf(errorWrongIdentityAPIVersion)
+	}
+	if d.usesApplicationCredential() {
+		if d.IdentityAPIVersion == identityAPIVersion2 {
+			return fmt.Errorf(errorApplicationCredentialV2)
+		}
+		if d.ApplicationCredentialID != "" && d.ApplicationCredentialName != "" {
+			return fmt.Errorf(errorExclusiveOptions, "Application credential id", "Application credential name")
+		}
+		if _, err := d.getApplicationCredentialSecret(); err != nil {
+			return fmt.Errorf(errorMandatoryEnvOrOption, "Application credential secret", "OS_APPLICATION_CREDENTIAL_SECRET", "--openstack-application-credential-secret")
+		}
+		if d.ApplicationCredentialName != "" && d.Username == "" {
+			return fmt.Errorf(errorMandatoryEnvOrOption, "Username", "OS_USERNAME", "--openstack-username")
+		}
+	} else {
+		if d.Username == "" {
+			return fmt.Errorf(errorMandatoryEnvOrOption, "Username", "OS_USERNAME", "--openstack-username")
+		}
+		if _, err := d.getPassword(); err != nil {
+			return fmt.Errorf(errorMandatoryEnvOrOption, "Password", "OS_PASSWORD", "--openstack-password")
+		}
+		if d.TenantName == "" && d.TenantId == "" {
+			return fmt.Errorf(errorMandatoryTenantNameOrID)
+		}
 	}
-	if d.Password == "" {
-		return fmt.Errorf(errorMandatoryEnvOrOption, "Password", "OS_PASSWORD", "--openstack-password")
+	if d.UserDomainID != "" && d.UserDomainName != "" {
+		return fmt.Errorf(errorExclusiveOptions, "User domain id", "User domain name")
 	}
-	if d.TenantName == "" && d.TenantId == "" {
-		return fmt.Errorf(errorMandatoryTenantNameOrID)
+	if d.ProjectDomainID != "" && d.ProjectDomainName != "" {
+		return fmt.Errorf(errorExclusiveOptions, "Project domain id", "Project domain name")
 	}
 
 	if d.FlavorName == "" && d.FlavorId == "" {
@@ -507,6 +674,9 @@ func (d *Driver) checkConfig() error {
 	if (d.KeyPairName != "" && d.PrivateKeyFile == "") || (d.KeyPairName == "" && d.PrivateKeyFile != "") {
 		return fmt.Errorf(errorBothOptions, "KeyPairName", "PrivateKeyFile")
 	}
+	if d.BootFromVolume && d.VolumeSize <= 0 {
+		return fmt.Errorf(errorVolumeSize)
+	}
 	return nil
 }
 
@@ -596,6 +766,13 @@ func (d *Driver) resolveIds() error {
 		})
 	}
 
+	// Keystone v3 tokens carry the id of their project
+	if d.TenantName != "" && d.TenantId == "" && d.usesIdentityV3() {
+		if err := d.client.Authenticate(d); err != nil {
+			return err
+		}
+	}
+
 	if d.TenantName != "" && d.TenantId == "" {
 		if err := d.initIdentity(); err != nil {
 			return err
@@ -30,7 +30,11 @@ func (c *Client) Authenticate(d *openstack.Driver) error {
 		"Username": d.Username,
 	})
 
-	apiKey := c.driver.APIKey
+	apiKey, err := c.driver.getAPIKey()
+	if err != nil {
+		return missingEnvOrOption("API key", "OS_API_KEY", "--rackspace-api-key")
+	}
+
 	opts := gophercloud.AuthOptions{
 		Username: d.Username,
 		APIKey:   apiKey,
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/docker/machine/drivers/openstack"
+	"github.com/docker/machine/libmachine/credentials"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
@@ -88,6 +89,21 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Usage:  "Rackspace active timeout",
 			Value:  defaultActiveTimeout,
 		},
+		mcnflag.BoolFlag{
+			EnvVar: "OS_BOOT_FROM_VOLUME",
+			Name:   "rackspace-boot-from-volume",
+			Usage:  "Boot the machine from a volume created from the image, for flavors with no ephemeral disk",
+		},
+		mcnflag.IntFlag{
+			EnvVar: "OS_VOLUME_SIZE",
+			Name:   "rackspace-volume-size",
+			Usage:  "Rackspace size of the boot volume in GB",
+		},
+		mcnflag.BoolFlag{
+			EnvVar: "OS_VOLUME_DELETE_ON_TERMINATION",
+			Name:   "rackspace-volume-delete-on-termination",
+			Usage:  "Delete the boot volume along with the machine",
+		},
 	}
 }
 
@@ -131,6 +147,9 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.FlavorId = flags.String("rackspace-flavor-id")
 	d.SSHUser = flags.String("rackspace-ssh-user")
 	d.SSHPort = flags.Int("rackspace-ssh-port")
+	d.BootFromVolume = flags.Bool("rackspace-boot-from-volume")
+	d.VolumeSize = flags.Int("rackspace-volume-size")
+	d.VolumeDeleteOnTermination = flags.Bool("rackspace-volume-delete-on-termination")
 	d.SetSwarmConfigFromFlags(flags)
 
 	if d.Region == "" {
@@ -139,7 +158,7 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	if d.Username == "" {
 		return missingEnvOrOption("Username", "OS_USERNAME", "--rackspace-username")
 	}
-	if d.APIKey == "" {
+	if _, err := d.getAPIKey(); err != nil {
 		return missingEnvOrOption("API key", "OS_API_KEY", "--rackspace-api-key")
 	}
 
@@ -154,5 +173,16 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 		return fmt.Errorf("invalid endpoint type %q (must be publicURL, adminURL or internalURL)", d.EndpointType)
 	}
 
+	if d.BootFromVolume && d.VolumeSize <= 0 {
+		return missingEnvOrOption("Volume size", "OS_VOLUME_SIZE", "--rackspace-volume-size")
+	}
+
 	return nil
 }
+
+// getAPIKey returns the API key given on the command line or, failing that,
+// the one found in a credential source. The latter is never stored on the
+// driver so that it does not end up in config.json.
+func (d *Driver) getAPIKey() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "api-key", d.APIKey)
+}
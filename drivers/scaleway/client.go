@@ -0,0 +1,172 @@
+package scaleway
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	computeURLFmt = "https://cp-%s.scaleway.com"
+)
+
+var (
+	errNotFound = errors.New("not found")
+)
+
+// client calls the compute API of Scaleway.
+type client struct {
+	computeURL string
+	token      string
+	httpClient *http.Client
+}
+
+func newClient(region, token string) *client {
+	return &client{
+		computeURL: fmt.Sprintf(computeURLFmt, region),
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+type serverRequest struct {
+	Organization      string   `json:"organization"`
+	Name              string   `json:"name"`
+	Image             string   `json:"image"`
+	CommercialType    string   `json:"commercial_type"`
+	Tags              []string `json:"tags,omitempty"`
+	DynamicIPRequired bool     `json:"dynamic_ip_required"`
+	EnableIPv6        bool     `json:"enable_ipv6"`
+}
+
+type server struct {
+	ID        string             `json:"id"`
+	Name      string             `json:"name"`
+	State     string             `json:"state"`
+	Arch      string             `json:"arch"`
+	PublicIP  *serverIP          `json:"public_ip"`
+	PrivateIP string             `json:"private_ip"`
+	IPv6      *serverIP          `json:"ipv6"`
+	Volumes   map[string]*volume `json:"volumes"`
+}
+
+type serverIP struct {
+	Address string `json:"address"`
+}
+
+type volume struct {
+	ID string `json:"id"`
+}
+
+type image struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Arch string `json:"arch"`
+}
+
+// do sends a request to the API and decodes the JSON response into out,
+// unless it is nil.
+func (c *client) do(method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		buf, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Auth-Token", c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	log.Debugf("%s %s", method, url)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s failed with status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(content)))
+	}
+
+	if out == nil || len(content) == 0 {
+		return nil
+	}
+	return json.Unmarshal(content, out)
+}
+
+func (c *client) createServer(request *serverRequest) (*server, error) {
+	var resp struct {
+		Server *server `json:"server"`
+	}
+	if err := c.do("POST", c.computeURL+"/servers", request, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+func (c *client) getServer(id string) (*server, error) {
+	var resp struct {
+		Server *server `json:"server"`
+	}
+	if err := c.do("GET", c.computeURL+"/servers/"+id, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Server, nil
+}
+
+// serverAction runs one of poweron, poweroff, reboot or terminate on the
+// server.
+func (c *client) serverAction(id, action string) error {
+	return c.do("POST", c.computeURL+"/servers/"+id+"/action", map[string]string{"action": action}, nil)
+}
+
+func (c *client) deleteServer(id string) error {
+	return c.do("DELETE", c.computeURL+"/servers/"+id, nil, nil)
+}
+
+func (c *client) deleteVolume(id string) error {
+	return c.do("DELETE", c.computeURL+"/volumes/"+id, nil, nil)
+}
+
+// findImage returns the ID of the image with the given name or ID built for
+// the given architecture.
+func (c *client) findImage(nameOrID, arch string) (string, error) {
+	var resp struct {
+		Images []image `json:"images"`
+	}
+	if err := c.do("GET", c.computeURL+"/images?per_page=100", nil, &resp); err != nil {
+		return "", err
+	}
+
+	for _, img := range resp.Images {
+		if img.Arch != arch {
+			continue
+		}
+		if img.ID == nameOrID || strings.EqualFold(img.Name, nameOrID) {
+			return img.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("no %s image named %q found", arch, nameOrID)
+}
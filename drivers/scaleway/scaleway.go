@@ -0,0 +1,371 @@
+package scaleway
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/credentials"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+)
+
+const (
+	defaultSSHPort        = 22
+	defaultSSHUser        = "root"
+	defaultRegion         = "par1"
+	defaultCommercialType = "C1"
+	defaultImage          = "Ubuntu Xenial"
+
+	defaultStartTimeout = 10 * time.Minute
+)
+
+// Driver creates machines on Scaleway, whose bare metal servers are ARM
+// based.
+type Driver struct {
+	*drivers.BaseDriver
+	Organization   string
+	Token          string
+	Region         string
+	CommercialType string
+	Image          string
+	IPv6           bool
+	ServerID       string
+	IPv6Address    string
+	PrivateAddress string
+
+	client *client
+}
+
+// NewDriver creates a new Scaleway driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		Region:         defaultRegion,
+		CommercialType: defaultCommercialType,
+		Image:          defaultImage,
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			SSHPort:     defaultSSHPort,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// GetCreateFlags registers the flags this driver adds to
+// "docker hosts create"
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return []mcnflag.Flag{
+		mcnflag.StringFlag{
+			EnvVar:   "SCALEWAY_ORGANIZATION",
+			Name:     "scaleway-organization",
+			Usage:    "Scaleway organization ID",
+			Required: true,
+		},
+		mcnflag.StringFlag{
+			EnvVar: "SCALEWAY_TOKEN",
+			Name:   "scaleway-token",
+			Usage:  "Scaleway API token",
+		},
+		mcnflag.StringFlag{
+			EnvVar:  "SCALEWAY_REGION",
+			Name:    "scaleway-region",
+			Usage:   "Scaleway region",
+			Value:   defaultRegion,
+			Choices: []string{"par1", "ams1"},
+		},
+		mcnflag.StringFlag{
+			EnvVar: "SCALEWAY_COMMERCIAL_TYPE",
+			Name:   "scaleway-commercial-type",
+			Usage:  "Scaleway server type, e.g. C1 or ARM64-2GB for ARM servers, VC1S for x86_64 ones",
+			Value:  defaultCommercialType,
+		},
+		mcnflag.StringFlag{
+			EnvVar: "SCALEWAY_IMAGE",
+			Name:   "scaleway-image",
+			Usage:  "Name or ID of the Scaleway image, for the architecture of the server type",
+			Value:  defaultImage,
+		},
+		mcnflag.BoolFlag{
+			EnvVar: "SCALEWAY_IPV6",
+			Name:   "scaleway-ipv6",
+			Usage:  "Enable IPv6 for the server",
+		},
+		mcnflag.StringFlag{
+			EnvVar: "SCALEWAY_SSH_USER",
+			Name:   "scaleway-ssh-user",
+			Usage:  "SSH username",
+			Value:  defaultSSHUser,
+		},
+		mcnflag.IntFlag{
+			EnvVar: "SCALEWAY_SSH_PORT",
+			Name:   "scaleway-ssh-port",
+			Usage:  "SSH port",
+			Value:  defaultSSHPort,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return "scaleway"
+}
+
+func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
+	if err := drivers.ValidateFlags(d.GetCreateFlags(), flags); err != nil {
+		return err
+	}
+
+	d.Organization = flags.String("scaleway-organization")
+	d.Token = flags.String("scaleway-token")
+	d.Region = flags.String("scaleway-region")
+	d.CommercialType = flags.String("scaleway-commercial-type")
+	d.Image = flags.String("scaleway-image")
+	d.IPv6 = flags.Bool("scaleway-ipv6")
+	d.SSHUser = flags.String("scaleway-ssh-user")
+	d.SSHPort = flags.Int("scaleway-ssh-port")
+	d.SetSwarmConfigFromFlags(flags)
+
+	if _, err := d.getToken(); err != nil {
+		return fmt.Errorf("scaleway driver requires the --scaleway-token option or a token credential: %s", err)
+	}
+
+	return nil
+}
+
+// getToken returns the token given on the command line or, failing that, the
+// one found in a credential source. The latter is never stored on the driver
+// so that it does not end up in config.json.
+func (d *Driver) getToken() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "token", d.Token)
+}
+
+func (d *Driver) getClient() *client {
+	if d.client == nil {
+		token, err := d.getToken()
+		if err != nil {
+			log.Warnf("Unable to find a Scaleway token: %s", err)
+		}
+		d.client = newClient(d.Region, token)
+	}
+	return d.client
+}
+
+// architecture returns the architecture of the servers of the commercial
+// type, as Scaleway names it for its images.
+func architecture(commercialType string) string {
+	commercialType = strings.ToUpper(commercialType)
+	switch {
+	case commercialType == "C1":
+		return "arm"
+	case strings.HasPrefix(commercialType, "ARM64"):
+		return "arm64"
+	}
+	return "x86_64"
+}
+
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+func (d *Driver) GetURL() (string, error) {
+	if err := drivers.MustBeRunning(d); err != nil {
+		return "", err
+	}
+
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, "2376")), nil
+}
+
+// GetPrivateIP returns the address of the server on the private network of
+// its region.
+func (d *Driver) GetPrivateIP() (string, error) {
+	return d.PrivateAddress, nil
+}
+
+// PreCreateCheck checks that the machine creation process can be started safely.
+func (d *Driver) PreCreateCheck() error {
+	_, err := d.getClient().findImage(d.Image, architecture(d.CommercialType))
+	return err
+}
+
+func (d *Driver) Create() error {
+	log.Infof("Creating SSH key...")
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return err
+	}
+
+	publicKey, err := ioutil.ReadFile(d.publicSSHKeyPath())
+	if err != nil {
+		return err
+	}
+
+	imageID, err := d.getClient().findImage(d.Image, architecture(d.CommercialType))
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Creating Scaleway server...")
+	server, err := d.getClient().createServer(&serverRequest{
+		Organization:      d.Organization,
+		Name:              d.MachineName,
+		Image:             imageID,
+		CommercialType:    d.CommercialType,
+		Tags:              []string{"docker-machine", authorizedKeyTag(string(publicKey))},
+		DynamicIPRequired: true,
+		EnableIPv6:        d.IPv6,
+	})
+	if err != nil {
+		return err
+	}
+
+	d.ServerID = server.ID
+
+	return d.Start()
+}
+
+// updateAddresses records the addresses of the server, which change each
+// time it is powered on.
+func (d *Driver) updateAddresses(server *server) {
+	if server.PublicIP != nil {
+		d.IPAddress = server.PublicIP.Address
+	}
+	if server.IPv6 != nil {
+		d.IPv6Address = server.IPv6.Address
+	}
+	d.PrivateAddress = server.PrivateIP
+}
+
+// waitForState waits until the server reaches the state, which takes a few
+// minutes when a bare metal server is powered on.
+func (d *Driver) waitForState(desired state.State) error {
+	var lastErr error
+	err := mcnutils.WaitForSpecific(func() bool {
+		var s state.State
+		s, lastErr = d.GetState()
+		return lastErr == nil && s == desired
+	}, int(defaultStartTimeout/(5*time.Second)), 5*time.Second)
+	if lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	server, err := d.getClient().getServer(d.ServerID)
+	if err != nil {
+		return state.Error, err
+	}
+
+	switch server.State {
+	case "starting":
+		return state.Starting, nil
+	case "running":
+		return state.Running, nil
+	case "stopping":
+		return state.Stopping, nil
+	case "stopped", "stopped in place":
+		return state.Stopped, nil
+	}
+	return state.None, nil
+}
+
+// Start powers the server on and waits for it to run
+func (d *Driver) Start() error {
+	log.Infof("Powering the server on, this may take a few minutes...")
+	if err := d.getClient().serverAction(d.ServerID, "poweron"); err != nil {
+		return err
+	}
+
+	if err := d.waitForState(state.Running); err != nil {
+		return err
+	}
+
+	server, err := d.getClient().getServer(d.ServerID)
+	if err != nil {
+		return err
+	}
+
+	d.updateAddresses(server)
+	log.Debugf("Server %s is running with IP address %s", d.ServerID, d.IPAddress)
+
+	return nil
+}
+
+// Stop powers the server off. Its disks are archived until it starts again.
+func (d *Driver) Stop() error {
+	return d.getClient().serverAction(d.ServerID, "poweroff")
+}
+
+// Restart reboots the server
+func (d *Driver) Restart() error {
+	return d.getClient().serverAction(d.ServerID, "reboot")
+}
+
+// Kill powers the server off, as Scaleway has no way to force it
+func (d *Driver) Kill() error {
+	return d.Stop()
+}
+
+// Remove deletes the server with its volumes and its IP
+func (d *Driver) Remove() error {
+	return d.removeServer()
+}
+
+func (d *Driver) removeServer() error {
+	if d.ServerID == "" {
+		return nil
+	}
+
+	server, err := d.getClient().getServer(d.ServerID)
+	if err == errNotFound {
+		log.Infof("Scaleway server doesn't exist, assuming it is already deleted")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only a running server can be terminated, which deletes its volumes and
+	// its IP with it
+	if server.State == "running" {
+		return d.getClient().serverAction(d.ServerID, "terminate")
+	}
+
+	if err := d.getClient().deleteServer(d.ServerID); err != nil {
+		return err
+	}
+	for _, v := range server.Volumes {
+		if err := d.getClient().deleteVolume(v.ID); err != nil && err != errNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizedKeyTag returns the tag the Scaleway images read a key to install
+// on the server from when it boots, for the key of the machine not to be
+// added to the keys of the whole account. The tag is the type and the body
+// of the key joined by an underscore, since tags can't hold spaces.
+func authorizedKeyTag(publicKey string) string {
+	fields := strings.Fields(publicKey)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+
+	return "AUTHORIZED_KEY=" + strings.Join(fields, "_")
+}
+
+func (d *Driver) publicSSHKeyPath() string {
+	return d.GetSSHKeyPath() + ".pub"
+}
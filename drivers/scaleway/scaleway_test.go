@@ -0,0 +1,110 @@
+package scaleway
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetConfigFromFlags(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"scaleway-organization": "ORGANIZATION",
+			"scaleway-token":        "TOKEN",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Empty(t, checkFlags.InvalidFlags)
+	assert.Equal(t, "C1", driver.CommercialType)
+	assert.Equal(t, "root", driver.GetSSHUsername())
+}
+
+func TestSetConfigFromFlagsRequiresOrganization(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"scaleway-token": "TOKEN",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.EqualError(t, err, "--scaleway-organization is required")
+}
+
+func TestArchitecture(t *testing.T) {
+	assert.Equal(t, "arm", architecture("C1"))
+	assert.Equal(t, "arm64", architecture("ARM64-2GB"))
+	assert.Equal(t, "x86_64", architecture("VC1S"))
+}
+
+// newTestDriver returns a driver talking to a fake API answering with the
+// given handler.
+func newTestDriver(handler http.HandlerFunc) (*Driver, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	driver := NewDriver("default", "path")
+	driver.client = &client{
+		computeURL: server.URL,
+		token:      "TOKEN",
+		httpClient: http.DefaultClient,
+	}
+
+	return driver, server
+}
+
+func TestFindImage(t *testing.T) {
+	driver, server := newTestDriver(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "TOKEN", r.Header.Get("X-Auth-Token"))
+		fmt.Fprint(w, `{"images": [
+			{"id": "x86", "name": "Ubuntu Xenial", "arch": "x86_64"},
+			{"id": "armhf", "name": "Ubuntu Xenial", "arch": "arm"}
+		]}`)
+	})
+	defer server.Close()
+
+	id, err := driver.getClient().findImage("ubuntu xenial", "arm")
+	assert.NoError(t, err)
+	assert.Equal(t, "armhf", id)
+
+	_, err = driver.getClient().findImage("Ubuntu Xenial", "arm64")
+	assert.Error(t, err)
+}
+
+func TestGetState(t *testing.T) {
+	driver, server := newTestDriver(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/servers/SERVER" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"server": {"id": "SERVER", "state": "stopped in place"}}`)
+	})
+	defer server.Close()
+
+	driver.ServerID = "SERVER"
+	s, err := driver.GetState()
+	assert.NoError(t, err)
+	assert.Equal(t, state.Stopped, s)
+
+	driver.ServerID = "MISSING"
+	_, err = driver.GetState()
+	assert.Equal(t, errNotFound, err)
+}
+
+func TestAuthorizedKeyTag(t *testing.T) {
+	assert.Equal(t, "AUTHORIZED_KEY=ssh-rsa_AAAAB3NzaC1yc2E", authorizedKeyTag("ssh-rsa AAAAB3NzaC1yc2E\n"))
+	assert.Equal(t, "AUTHORIZED_KEY=ssh-rsa_AAAAB3NzaC1yc2E", authorizedKeyTag("ssh-rsa AAAAB3NzaC1yc2E user@host\n"))
+}
@@ -522,7 +522,7 @@ func (d *Driver) Start() error {
 }
 
 func (d *Driver) Stop() error {
-	return d.getClient().VirtualGuest().PowerOff(d.Id)
+	return d.getClient().VirtualGuest().PowerOffSoft(d.Id)
 }
 
 func (d *Driver) Restart() error {
@@ -530,5 +530,5 @@ func (d *Driver) Restart() error {
 }
 
 func (d *Driver) Kill() error {
-	return d.Stop()
+	return d.getClient().VirtualGuest().PowerOff(d.Id)
 }
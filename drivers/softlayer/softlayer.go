@@ -303,6 +303,19 @@ func (c *VirtualGuest) PowerOff(id int) error {
 	return nil
 }
 
+func (c *VirtualGuest) PowerOffSoft(id int) error {
+	var (
+		method = "GET"
+		uri    = fmt.Sprintf("%s/%v/powerOffSoft.json", c.namespace(), id)
+	)
+
+	_, err := c.newRequest(method, uri, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *VirtualGuest) Pause(id int) error {
 	var (
 		method = "GET"
@@ -0,0 +1,290 @@
+package vagrant
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/state"
+)
+
+const (
+	defaultProvider = "virtualbox"
+	defaultCPU      = 1
+	defaultMemory   = 1024
+)
+
+// Driver creates machines from Vagrant boxes, using Vagrant to drive the
+// provider of the box. The Docker engine is installed over SSH like with
+// the generic driver.
+type Driver struct {
+	*drivers.BaseDriver
+	Box        string
+	BoxVersion string
+	Provider   string
+	CPU        int
+	Memory     int
+	PrivateIP  string
+	EnginePort int
+
+	vgt Vagrant
+}
+
+// NewDriver creates a new Vagrant driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		Provider:   defaultProvider,
+		CPU:        defaultCPU,
+		Memory:     defaultMemory,
+		EnginePort: engine.DefaultPort,
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// GetCreateFlags registers the flags this driver adds to
+// "docker hosts create"
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return []mcnflag.Flag{
+		mcnflag.StringFlag{
+			Name:     "vagrant-box",
+			Usage:    "Vagrant box to create the machine from, e.g. ubuntu/xenial64",
+			EnvVar:   "VAGRANT_BOX",
+			Required: true,
+		},
+		mcnflag.StringFlag{
+			Name:   "vagrant-box-version",
+			Usage:  "Version constraint of the Vagrant box",
+			EnvVar: "VAGRANT_BOX_VERSION",
+		},
+		mcnflag.StringFlag{
+			Name:   "vagrant-provider",
+			Usage:  "Vagrant provider running the box",
+			Value:  defaultProvider,
+			EnvVar: "VAGRANT_DEFAULT_PROVIDER",
+		},
+		mcnflag.IntFlag{
+			Name:   "vagrant-cpu-count",
+			Usage:  "Number of CPUs for the machine",
+			Value:  defaultCPU,
+			EnvVar: "VAGRANT_CPU_COUNT",
+		},
+		mcnflag.IntFlag{
+			Name:   "vagrant-memory",
+			Usage:  "Memory size for the machine in MB",
+			Value:  defaultMemory,
+			EnvVar: "VAGRANT_MEMORY",
+		},
+		mcnflag.StringFlag{
+			Name:     "vagrant-private-ip",
+			Usage:    "Address of the machine on a private network. Defaults to forwarding the engine port to localhost.",
+			EnvVar:   "VAGRANT_PRIVATE_IP",
+			Validate: validateIP,
+		},
+	}
+}
+
+func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
+	if err := drivers.ValidateFlags(d.GetCreateFlags(), flags); err != nil {
+		return err
+	}
+
+	d.Box = flags.String("vagrant-box")
+	d.BoxVersion = flags.String("vagrant-box-version")
+	d.Provider = flags.String("vagrant-provider")
+	d.CPU = flags.Int("vagrant-cpu-count")
+	d.Memory = flags.Int("vagrant-memory")
+	d.PrivateIP = flags.String("vagrant-private-ip")
+	d.SetSwarmConfigFromFlags(flags)
+
+	return nil
+}
+
+func validateIP(ip string) error {
+	if net.ParseIP(ip) == nil {
+		return errors.New("not an IP address")
+	}
+	return nil
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return "vagrant"
+}
+
+func (d *Driver) vagrant() Vagrant {
+	if d.vgt == nil {
+		d.vgt = NewVagrant(d.ResolveStorePath("."))
+	}
+	return d.vgt
+}
+
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+func (d *Driver) GetIP() (string, error) {
+	if d.PrivateIP != "" {
+		return d.PrivateIP, nil
+	}
+	if d.IPAddress == "" {
+		return "", errors.New("IP address is not set")
+	}
+	return d.IPAddress, nil
+}
+
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, strconv.Itoa(d.EnginePort))), nil
+}
+
+// PreCreateCheck checks that the machine creation process can be started safely.
+func (d *Driver) PreCreateCheck() error {
+	return d.vagrant().vagrant("--version")
+}
+
+func (d *Driver) Create() error {
+	if d.PrivateIP == "" {
+		// The engine listens on the same port in the machine and on the
+		// host, as it is told the port of the URL.
+		port, err := freeLocalPort()
+		if err != nil {
+			return err
+		}
+		d.EnginePort = port
+	}
+
+	content, err := d.vagrantfileContent()
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(d.ResolveStorePath("Vagrantfile"), []byte(content), 0644); err != nil {
+		return err
+	}
+
+	log.Infof("Bringing up %s with the %s provider...", d.Box, d.Provider)
+	if err := d.vagrant().vagrant("up", "--provider", d.Provider); err != nil {
+		return err
+	}
+
+	return d.updateSSHConfig(true)
+}
+
+// updateSSHConfig records how to reach the machine with SSH, which may
+// change each time it boots. The key Vagrant uses is copied to the store on
+// creation.
+func (d *Driver) updateSSHConfig(copyKey bool) error {
+	output, err := d.vagrant().vagrantOut("ssh-config")
+	if err != nil {
+		return err
+	}
+
+	config := parseSSHConfig(output)
+	if config.HostName == "" {
+		return errors.New("unable to find the SSH address of the machine")
+	}
+
+	d.IPAddress = config.HostName
+	d.SSHUser = config.User
+	if port, err := strconv.Atoi(config.Port); err == nil {
+		d.SSHPort = port
+	}
+
+	if copyKey && config.IdentityFile != "" {
+		if err := mcnutils.CopyFile(config.IdentityFile, d.GetSSHKeyPath()); err != nil {
+			return err
+		}
+		return os.Chmod(d.GetSSHKeyPath(), 0600)
+	}
+
+	return nil
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	output, err := d.vagrant().vagrantOut("status", "--machine-readable")
+	if err != nil {
+		return state.Error, err
+	}
+
+	switch parseMachineState(output) {
+	case "running":
+		return state.Running, nil
+	case "paused":
+		return state.Paused, nil
+	case "saved", "suspended":
+		return state.Saved, nil
+	case "poweroff", "shutoff", "stopped", "aborted", "off":
+		return state.Stopped, nil
+	case "not_created":
+		return state.Error, errors.New("machine does not exist")
+	}
+	return state.None, nil
+}
+
+// Start boots the machine, or resumes it when it was suspended
+func (d *Driver) Start() error {
+	if err := d.vagrant().vagrant("up", "--provider", d.Provider); err != nil {
+		return err
+	}
+
+	return d.updateSSHConfig(false)
+}
+
+// Stop gracefully stops the machine
+func (d *Driver) Stop() error {
+	return d.vagrant().vagrant("halt")
+}
+
+// Restart stops and starts the machine
+func (d *Driver) Restart() error {
+	if err := d.vagrant().vagrant("reload"); err != nil {
+		return err
+	}
+
+	return d.updateSSHConfig(false)
+}
+
+// Kill forcefully stops the machine
+func (d *Driver) Kill() error {
+	return d.vagrant().vagrant("halt", "--force")
+}
+
+// Remove destroys the machine. The box stays in the catalog of Vagrant.
+func (d *Driver) Remove() error {
+	if _, err := os.Stat(d.ResolveStorePath("Vagrantfile")); os.IsNotExist(err) {
+		log.Infof("No Vagrantfile found, assuming the machine has been removed already")
+		return nil
+	}
+
+	return d.vagrant().vagrant("destroy", "--force")
+}
+
+// freeLocalPort returns a TCP port nothing listens on, on localhost.
+func freeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(port)
+}
@@ -0,0 +1,119 @@
+package vagrant
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type vagrantMock struct {
+	outputs map[string]string
+}
+
+func (v *vagrantMock) vagrant(args ...string) error {
+	_, err := v.vagrantOut(args...)
+	return err
+}
+
+func (v *vagrantMock) vagrantOut(args ...string) (string, error) {
+	return v.outputs[args[0]], nil
+}
+
+func TestSetConfigFromFlags(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"vagrant-box":      "ubuntu/xenial64",
+			"vagrant-provider": "libvirt",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.Empty(t, checkFlags.InvalidFlags)
+	assert.Equal(t, "ubuntu/xenial64", driver.Box)
+	assert.Equal(t, "libvirt", driver.Provider)
+	assert.Equal(t, defaultMemory, driver.Memory)
+}
+
+func TestSetConfigFromFlagsRequiresBox(t *testing.T) {
+	driver := NewDriver("default", "path")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	assert.Error(t, driver.SetConfigFromFlags(checkFlags))
+}
+
+func TestVagrantfileContent(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.Box = "ubuntu/xenial64"
+	driver.EnginePort = 32768
+
+	content, err := driver.vagrantfileContent()
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, `config.vm.box = "ubuntu/xenial64"`)
+	assert.Contains(t, content, `config.vm.network "forwarded_port", guest: 32768, host: 32768, host_ip: "127.0.0.1"`)
+	assert.Contains(t, content, `config.vm.provider "virtualbox" do |provider|`)
+	assert.NotContains(t, content, "box_version")
+
+	driver.PrivateIP = "192.168.33.10"
+	content, err = driver.vagrantfileContent()
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, `config.vm.network "private_network", ip: "192.168.33.10"`)
+	assert.NotContains(t, content, "forwarded_port")
+}
+
+func TestUpdateSSHConfig(t *testing.T) {
+	driver := NewDriver("default", "path")
+	driver.vgt = &vagrantMock{outputs: map[string]string{
+		"ssh-config": `Host default
+  HostName 127.0.0.1
+  User vagrant
+  Port 2222
+  UserKnownHostsFile /dev/null
+  IdentityFile "/home/user/.docker/machine/machines/default/.vagrant/machines/default/virtualbox/private_key"
+`,
+	}}
+
+	err := driver.updateSSHConfig(false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", driver.IPAddress)
+	assert.Equal(t, "vagrant", driver.SSHUser)
+	assert.Equal(t, 2222, driver.SSHPort)
+
+	url, err := driver.GetURL()
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp://127.0.0.1:2376", url)
+}
+
+func TestGetState(t *testing.T) {
+	var tests = []struct {
+		status   string
+		expected state.State
+	}{
+		{"1500000000,default,provider-name,virtualbox\n1500000000,default,state,running\n", state.Running},
+		{"1500000000,default,state,poweroff\n", state.Stopped},
+		{"1500000000,default,state,saved\n", state.Saved},
+	}
+
+	for _, test := range tests {
+		driver := NewDriver("default", "path")
+		driver.vgt = &vagrantMock{outputs: map[string]string{"status": test.status}}
+
+		s, err := driver.GetState()
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, s)
+	}
+}
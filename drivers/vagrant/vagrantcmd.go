@@ -0,0 +1,118 @@
+package vagrant
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+var (
+	ErrVagrantNotFound = errors.New("vagrant not found. Make sure Vagrant is installed and vagrant is in the path")
+)
+
+// Vagrant defines the interface to run vagrant commands on the machine.
+type Vagrant interface {
+	vagrant(args ...string) error
+
+	vagrantOut(args ...string) (string, error)
+}
+
+// VagrantCmd runs vagrant from the directory of the Vagrantfile.
+type VagrantCmd struct {
+	dir    string
+	runCmd func(cmd *exec.Cmd) error
+}
+
+// NewVagrant creates a Vagrant instance working on the Vagrantfile of the
+// given directory.
+func NewVagrant(dir string) *VagrantCmd {
+	return &VagrantCmd{
+		dir:    dir,
+		runCmd: func(cmd *exec.Cmd) error { return cmd.Run() },
+	}
+}
+
+func (v *VagrantCmd) vagrant(args ...string) error {
+	_, err := v.vagrantOut(args...)
+	return err
+}
+
+func (v *VagrantCmd) vagrantOut(args ...string) (string, error) {
+	cmd := exec.Command("vagrant", args...)
+	cmd.Dir = v.dir
+	log.Debugf("COMMAND: vagrant %v", strings.Join(args, " "))
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := v.runCmd(cmd)
+	log.Debugf("STDOUT:\n{\n%v}", stdout.String())
+	log.Debugf("STDERR:\n{\n%v}", stderr.String())
+
+	if err != nil {
+		if ee, ok := err.(*exec.Error); ok && ee.Err == exec.ErrNotFound {
+			return "", ErrVagrantNotFound
+		}
+		return "", fmt.Errorf("vagrant %v failed:\n%v", strings.Join(args, " "), stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+// sshConfig is what matters to docker-machine in the output of
+// `vagrant ssh-config`.
+type sshConfig struct {
+	HostName     string
+	User         string
+	Port         string
+	IdentityFile string
+}
+
+func parseSSHConfig(output string) sshConfig {
+	config := sshConfig{}
+
+	s := bufio.NewScanner(strings.NewReader(output))
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		value := strings.Trim(strings.Join(fields[1:], " "), `"`)
+		switch fields[0] {
+		case "HostName":
+			config.HostName = value
+		case "User":
+			config.User = value
+		case "Port":
+			config.Port = value
+		case "IdentityFile":
+			// Vagrant lists the insecure key after the key of the machine
+			if config.IdentityFile == "" {
+				config.IdentityFile = value
+			}
+		}
+	}
+
+	return config
+}
+
+// parseMachineState returns the state in the output of
+// `vagrant status --machine-readable`, e.g. running or poweroff.
+func parseMachineState(output string) string {
+	s := bufio.NewScanner(strings.NewReader(output))
+	for s.Scan() {
+		fields := strings.Split(s.Text(), ",")
+		if len(fields) >= 4 && fields[2] == "state" {
+			return fields[3]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,35 @@
+package vagrant
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const vagrantfile = `# Generated by docker-machine, changes are lost when the machine is recreated.
+Vagrant.configure("2") do |config|
+  config.vm.box = "{{.Box}}"
+{{- if .BoxVersion}}
+  config.vm.box_version = "{{.BoxVersion}}"
+{{- end}}
+  config.vm.synced_folder ".", "/vagrant", disabled: true
+{{- if .PrivateIP}}
+  config.vm.network "private_network", ip: "{{.PrivateIP}}"
+{{- else}}
+  config.vm.network "forwarded_port", guest: {{.EnginePort}}, host: {{.EnginePort}}, host_ip: "127.0.0.1"
+{{- end}}
+
+  config.vm.provider "{{.Provider}}" do |provider|
+    provider.cpus = {{.CPU}}
+    provider.memory = {{.Memory}}
+  end
+end
+`
+
+var vagrantfileTemplate = template.Must(template.New("Vagrantfile").Parse(vagrantfile))
+
+// vagrantfileContent returns the Vagrantfile of the machine.
+func (d *Driver) vagrantfileContent() (string, error) {
+	var buf bytes.Buffer
+	err := vagrantfileTemplate.Execute(&buf, d)
+	return buf.String(), err
+}
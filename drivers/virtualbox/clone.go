@@ -0,0 +1,52 @@
+package virtualbox
+
+import (
+	"encoding/json"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// CreateClone creates the VM with a copy of the disk of the machine whose
+// configuration is given, with its images and volumes. The clone starts with
+// the SSH key of the source, which the disk authorizes, and gets its own
+// certificates when it is provisioned. The disk of a running VM can't be
+// copied, in which case drivers.ErrNotImplemented is returned for the clone
+// to be created from scratch.
+func (d *Driver) CreateClone(sourceConfig []byte) error {
+	source := NewDriver("", "")
+	if err := json.Unmarshal(sourceConfig, source); err != nil {
+		return err
+	}
+	source.VBoxManager = d.VBoxManager
+
+	if s, err := source.GetState(); err != nil || s != state.Stopped {
+		log.Infof("%s must be stopped for its disk to be copied", source.MachineName)
+		return drivers.ErrNotImplemented
+	}
+
+	d.cloneSource = source
+	defer func() {
+		d.cloneSource = nil
+	}()
+
+	return d.Create()
+}
+
+// copyCloneSource copies the disk and the SSH key of the machine the VM is
+// cloned from.
+func (d *Driver) copyCloneSource() error {
+	log.Debugf("Copying the disk of %s...", d.cloneSource.MachineName)
+	if err := d.vbm("clonehd", d.cloneSource.diskPath(), d.diskPath()); err != nil {
+		return err
+	}
+
+	log.Debugf("Copying the SSH key of %s...", d.cloneSource.MachineName)
+	if err := mcnutils.CopyFile(d.cloneSource.GetSSHKeyPath(), d.GetSSHKeyPath()); err != nil {
+		return err
+	}
+
+	return mcnutils.CopyFile(d.cloneSource.publicSSHKeyPath(), d.publicSSHKeyPath())
+}
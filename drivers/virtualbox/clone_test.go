@@ -0,0 +1,53 @@
+package virtualbox
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCloneOfRunningMachine(t *testing.T) {
+	source, err := json.Marshal(NewDriver("source", "path"))
+	assert.NoError(t, err)
+
+	driver := NewDriver("default", "path")
+	mockCalls(t, driver, []Call{
+		{"vbm showvminfo source --machinereadable", `VMState="running"`, nil},
+	})
+
+	err = driver.CreateClone(source)
+
+	assert.Equal(t, drivers.ErrNotImplemented, err)
+	assert.Nil(t, driver.cloneSource)
+}
+
+func TestCopyCloneSource(t *testing.T) {
+	storePath, err := ioutil.TempDir("", "virtualbox")
+	assert.NoError(t, err)
+	defer os.RemoveAll(storePath)
+
+	source := NewDriver("source", storePath)
+	assert.NoError(t, os.MkdirAll(source.ResolveStorePath("."), 0700))
+	assert.NoError(t, ioutil.WriteFile(source.GetSSHKeyPath(), []byte("private"), 0600))
+	assert.NoError(t, ioutil.WriteFile(source.publicSSHKeyPath(), []byte("public"), 0644))
+
+	driver := NewDriver("default", storePath)
+	assert.NoError(t, os.MkdirAll(driver.ResolveStorePath("."), 0700))
+	mockCalls(t, driver, []Call{
+		{"vbm clonehd " + filepath.Join(storePath, "machines", "source", "disk.vmdk") + " " + filepath.Join(storePath, "machines", "default", "disk.vmdk"), "", nil},
+	})
+	driver.cloneSource = source
+
+	err = driver.copyCloneSource()
+
+	assert.NoError(t, err)
+	privateKey, _ := ioutil.ReadFile(driver.GetSSHKeyPath())
+	assert.Equal(t, "private", string(privateKey))
+	publicKey, _ := ioutil.ReadFile(driver.publicSSHKeyPath())
+	assert.Equal(t, "public", string(publicKey))
+}
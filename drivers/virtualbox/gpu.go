@@ -0,0 +1,55 @@
+package virtualbox
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"runtime"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+var pciAddressPattern = regexp.MustCompile(`^[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`)
+
+// SetGPUOptions records the GPUs of the host to pass through to the VM.
+// VirtualBox only supports PCI passthrough on Linux hosts, with the
+// extension pack installed.
+func (d *Driver) SetGPUOptions(opts drivers.GPUOptions) error {
+	if len(opts.PCIDevices) == 0 {
+		return errors.New("VirtualBox can only give GPUs of the host to the VM, give their PCI addresses")
+	}
+
+	if runtime.GOOS != "linux" {
+		return errors.New("VirtualBox only supports PCI passthrough on Linux hosts")
+	}
+
+	if opts.Count != 0 && opts.Count != len(opts.PCIDevices) {
+		return fmt.Errorf("%d GPUs asked for but %d PCI devices given", opts.Count, len(opts.PCIDevices))
+	}
+
+	for _, device := range opts.PCIDevices {
+		if !pciAddressPattern.MatchString(device) {
+			return fmt.Errorf("Invalid PCI address %q, expected bus:device.function, e.g. 01:00.0", device)
+		}
+	}
+
+	d.PCIDevices = opts.PCIDevices
+
+	return nil
+}
+
+// attachPCIDevices passes the PCI devices through to the VM. PCI passthrough
+// requires the ICH9 chipset. The devices are attached to the VM from slot 5
+// of its first bus on, clear of the devices VirtualBox emulates.
+func (d *Driver) attachPCIDevices() error {
+	if len(d.PCIDevices) == 0 {
+		return nil
+	}
+
+	args := []string{"modifyvm", d.MachineName, "--chipset", "ich9"}
+	for i, device := range d.PCIDevices {
+		args = append(args, "--pciattach", fmt.Sprintf("%s@01:%02x.0", device, 5+i))
+	}
+
+	return d.vbm(args...)
+}
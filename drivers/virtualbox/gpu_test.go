@@ -0,0 +1,42 @@
+package virtualbox
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetGPUOptions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("PCI passthrough is only supported on Linux hosts")
+	}
+
+	driver := newTestDriver("default")
+
+	assert.NoError(t, driver.SetGPUOptions(drivers.GPUOptions{PCIDevices: []string{"01:00.0"}}))
+	assert.Equal(t, []string{"01:00.0"}, driver.PCIDevices)
+}
+
+func TestSetGPUOptionsInvalid(t *testing.T) {
+	cases := []drivers.GPUOptions{
+		{Count: 1},
+		{Count: 2, PCIDevices: []string{"01:00.0"}},
+		{PCIDevices: []string{"0000:01:00"}},
+	}
+
+	for _, opts := range cases {
+		assert.Error(t, newTestDriver("default").SetGPUOptions(opts))
+	}
+}
+
+func TestAttachPCIDevices(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.PCIDevices = []string{"01:00.0", "02:00.0"}
+	driver.VBoxManager = &VBoxManagerMock{
+		args: "modifyvm default --chipset ich9 --pciattach 01:00.0@01:05.0 --pciattach 02:00.0@01:06.0",
+	}
+
+	assert.NoError(t, driver.attachPCIDevices())
+}
@@ -0,0 +1,242 @@
+package virtualbox
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	networkScriptPath  = "/var/lib/boot2docker/network.sh"
+	bootSyncScriptPath = "/var/lib/boot2docker/bootsync.sh"
+)
+
+// SetNetworkOptions pins the host-only network and the address of the VM.
+// The static IP and DNS servers are set by a script run by boot2docker on
+// every boot.
+func (d *Driver) SetNetworkOptions(opts drivers.NetworkOptions) error {
+	if opts.Subnet != "" {
+		if opts.HostOnlyNetwork != "" {
+			return fmt.Errorf("A subnet cannot be given along with an existing host-only network")
+		}
+
+		hostOnlyCIDR, err := hostOnlyCIDRFromSubnet(opts.Subnet)
+		if err != nil {
+			return err
+		}
+		d.HostOnlyCIDR = hostOnlyCIDR
+	}
+
+	if opts.StaticIP != "" {
+		ip := net.ParseIP(opts.StaticIP)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("Invalid static IP %q, an IPv4 address is expected", opts.StaticIP)
+		}
+
+		// The subnet of a named network is only known when the VM starts
+		if opts.HostOnlyNetwork == "" {
+			hostIP, network, err := net.ParseCIDR(d.HostOnlyCIDR)
+			if err != nil {
+				return err
+			}
+			if !network.Contains(ip) {
+				return fmt.Errorf("Static IP %s is not in the host-only network %s", ip, network)
+			}
+			if ip.Equal(hostIP) {
+				return fmt.Errorf("Static IP %s is the address of the host on the host-only network", ip)
+			}
+
+			// The DHCP server of a network smaller than a /24 gets a
+			// random address, the widest range it may lease is assumed
+			if !d.HostOnlyNoDHCP {
+				lowerIP, upperIP := getDHCPAddressRange(net.IPv4(0, 0, 0, 1), network)
+				if ipInRange(ip, lowerIP, upperIP) {
+					return fmt.Errorf("Static IP %s is in the range %s - %s leased by the DHCP server of the host-only network, "+
+						"pick another address or disable the DHCP server with --virtualbox-hostonly-no-dhcp", ip, lowerIP, upperIP)
+				}
+			}
+		}
+	}
+
+	for _, server := range opts.DNSServers {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("Invalid DNS server %q", server)
+		}
+	}
+
+	guestPorts := map[string]bool{}
+	for _, portForward := range opts.PortForwards {
+		if portForward.Protocol == "tcp" && portForward.GuestPort == 22 {
+			return fmt.Errorf("Port 22 of the VM is already forwarded for SSH")
+		}
+		name := portForwardName(portForward)
+		if guestPorts[name] {
+			return fmt.Errorf("Port %d/%s of the VM is forwarded more than once", portForward.GuestPort, portForward.Protocol)
+		}
+		guestPorts[name] = true
+	}
+
+	d.StaticIP = opts.StaticIP
+	d.DNSServers = opts.DNSServers
+	d.HostOnlyNetwork = opts.HostOnlyNetwork
+	d.PortForwards = opts.PortForwards
+
+	return nil
+}
+
+// checkStaticIPOutsideDHCPRange makes sure that the DHCP server of the
+// existing host-only network the VM is attached to does not lease the static
+// IP of the VM to another VM.
+func (d *Driver) checkStaticIPOutsideDHCPRange() error {
+	if d.StaticIP == "" || d.HostOnlyNetwork == "" {
+		return nil
+	}
+
+	dhcps, err := listDHCPServers(d.VBoxManager)
+	if err != nil {
+		return err
+	}
+
+	dhcp, present := dhcps[dhcpPrefix+d.HostOnlyNetwork]
+	if !present || !dhcp.Enabled {
+		return nil
+	}
+
+	if ipInRange(net.ParseIP(d.StaticIP), dhcp.LowerIP, dhcp.UpperIP) {
+		return fmt.Errorf("Static IP %s is in the range %s - %s leased by the DHCP server of the host-only network %q",
+			d.StaticIP, dhcp.LowerIP, dhcp.UpperIP, d.HostOnlyNetwork)
+	}
+
+	return nil
+}
+
+func ipInRange(ip, lowerIP, upperIP net.IP) bool {
+	ip = ip.To4()
+	return bytes.Compare(ip, lowerIP.To4()) >= 0 && bytes.Compare(ip, upperIP.To4()) <= 0
+}
+
+// portForwardName names the NAT rule of a port forwarding after the port of
+// the VM, which may only be forwarded once.
+func portForwardName(portForward drivers.PortForward) string {
+	return fmt.Sprintf("%s-%d", portForward.Protocol, portForward.GuestPort)
+}
+
+// setExtraPortForwarding creates the NAT rules of the port forwardings. A
+// host port already in use is replaced with a free one, which is recorded.
+func (d *Driver) setExtraPortForwarding() error {
+	for i, portForward := range d.PortForwards {
+		hostPort, err := setPortForwarding(d, 1, portForwardName(portForward), portForward.Protocol, portForward.GuestPort, portForward.HostPort)
+		if err != nil {
+			return err
+		}
+
+		if hostPort != portForward.HostPort {
+			log.Warnf("Host port %d is not available, port %d/%s of the VM is forwarded from port %d instead", portForward.HostPort, portForward.GuestPort, portForward.Protocol, hostPort)
+			d.PortForwards[i].HostPort = hostPort
+		}
+	}
+
+	return nil
+}
+
+// hostOnlyCIDRFromSubnet turns a network address, e.g. 192.168.50.0/24, into
+// the address of the host on that network, e.g. 192.168.50.1/24.
+func hostOnlyCIDRFromSubnet(subnet string) (string, error) {
+	ip, network, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return "", err
+	}
+
+	networkIP := network.IP.To4()
+	if networkIP == nil || !ip.Equal(networkIP) {
+		return "", fmt.Errorf("Invalid subnet %q, an IPv4 network address is expected", subnet)
+	}
+
+	hostIP := net.IPv4(networkIP[0], networkIP[1], networkIP[2], networkIP[3]+1)
+	ones, _ := network.Mask.Size()
+
+	return fmt.Sprintf("%s/%d", hostIP, ones), nil
+}
+
+// configureNetwork applies the static IP and DNS servers, if any, and makes
+// boot2docker apply them again on every boot.
+func (d *Driver) configureNetwork() error {
+	if d.StaticIP == "" && len(d.DNSServers) == 0 {
+		return nil
+	}
+
+	script, err := d.networkScript()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Configuring the network of the VM with:\n%s", script)
+
+	cmd := fmt.Sprintf("printf '%%s' '%s' | sudo tee %s >/dev/null && sudo chmod +x %s && "+
+		"(grep -qs %s %s || echo %s | sudo tee -a %s >/dev/null) && sudo %s",
+		script, networkScriptPath, networkScriptPath,
+		networkScriptPath, bootSyncScriptPath, networkScriptPath, bootSyncScriptPath,
+		networkScriptPath)
+
+	if _, err := drivers.RunSSHCommandFromDriver(d, cmd); err != nil {
+		return fmt.Errorf("Error configuring the network of the VM: %s", err)
+	}
+
+	return nil
+}
+
+func (d *Driver) networkScript() (string, error) {
+	var script bytes.Buffer
+
+	script.WriteString("#!/bin/sh\n")
+
+	if d.StaticIP != "" {
+		mask, err := d.hostOnlyMask()
+		if err != nil {
+			return "", err
+		}
+		ones, _ := mask.Size()
+
+		// Stop the DHCP client of the host-only interface so that it does
+		// not replace the static address
+		script.WriteString("pkill -f \"udhcpc.*eth1\"\n")
+		script.WriteString("ip addr flush dev eth1\n")
+		fmt.Fprintf(&script, "ip addr add %s/%d dev eth1\n", d.StaticIP, ones)
+		script.WriteString("ip link set eth1 up\n")
+	}
+
+	for i, server := range d.DNSServers {
+		redirect := ">>"
+		if i == 0 {
+			redirect = ">"
+		}
+		fmt.Fprintf(&script, "echo nameserver %s %s /etc/resolv.conf\n", server, redirect)
+	}
+
+	return script.String(), nil
+}
+
+func (d *Driver) hostOnlyMask() (net.IPMask, error) {
+	if d.HostOnlyNetwork == "" {
+		_, network, err := net.ParseCIDR(d.HostOnlyCIDR)
+		if err != nil {
+			return nil, err
+		}
+		return network.Mask, nil
+	}
+
+	nets, err := listHostOnlyAdapters(d.VBoxManager)
+	if err != nil {
+		return nil, err
+	}
+
+	hostOnlyAdapter, present := nets[d.HostOnlyNetwork]
+	if !present {
+		return nil, fmt.Errorf("Host-only network %q does not exist", d.HostOnlyNetwork)
+	}
+
+	return hostOnlyAdapter.IPv4.Mask, nil
+}
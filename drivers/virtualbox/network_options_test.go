@@ -0,0 +1,137 @@
+package virtualbox
+
+import (
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostOnlyCIDRFromSubnet(t *testing.T) {
+	cidr, err := hostOnlyCIDRFromSubnet("192.168.50.0/24")
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.50.1/24", cidr)
+
+	_, err = hostOnlyCIDRFromSubnet("192.168.50.1/24")
+	assert.Error(t, err)
+
+	_, err = hostOnlyCIDRFromSubnet("fd00::/64")
+	assert.Error(t, err)
+}
+
+func TestSetNetworkOptions(t *testing.T) {
+	driver := newTestDriver("default")
+
+	err := driver.SetNetworkOptions(drivers.NetworkOptions{
+		StaticIP:   "192.168.50.10",
+		Subnet:     "192.168.50.0/24",
+		DNSServers: []string{"8.8.8.8"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "192.168.50.1/24", driver.HostOnlyCIDR)
+	assert.Equal(t, "192.168.50.10", driver.StaticIP)
+	assert.Equal(t, []string{"8.8.8.8"}, driver.DNSServers)
+}
+
+func TestSetNetworkOptionsInvalid(t *testing.T) {
+	cases := []drivers.NetworkOptions{
+		{StaticIP: "192.168.50.10"},
+		{StaticIP: "192.168.99.1"},
+		{StaticIP: "not-an-ip"},
+		{DNSServers: []string{"dns.example.com"}},
+		{Subnet: "192.168.50.0/24", HostOnlyNetwork: "vboxnet0"},
+	}
+
+	for _, opts := range cases {
+		assert.Error(t, newTestDriver("default").SetNetworkOptions(opts))
+	}
+}
+
+func TestSetNetworkOptionsStaticIPInDHCPRange(t *testing.T) {
+	driver := newTestDriver("default")
+
+	err := driver.SetNetworkOptions(drivers.NetworkOptions{StaticIP: "192.168.99.150"})
+
+	assert.EqualError(t, err, "Static IP 192.168.99.150 is in the range 192.168.99.100 - 192.168.99.254 leased by the DHCP server of the host-only network, "+
+		"pick another address or disable the DHCP server with --virtualbox-hostonly-no-dhcp")
+
+	driver.HostOnlyNoDHCP = true
+
+	assert.NoError(t, driver.SetNetworkOptions(drivers.NetworkOptions{StaticIP: "192.168.99.150"}))
+}
+
+func TestCheckStaticIPOutsideDHCPRange(t *testing.T) {
+	dhcpServers := `NetworkName:    HostInterfaceNetworking-vboxnet1
+IP:             192.168.56.2
+NetworkMask:    255.255.255.0
+lowerIPAddress: 192.168.56.100
+upperIPAddress: 192.168.56.199
+Enabled:        Yes
+`
+
+	driver := newTestDriver("default")
+	driver.HostOnlyNetwork = "vboxnet1"
+	driver.StaticIP = "192.168.56.150"
+	driver.VBoxManager = &VBoxManagerMock{args: "list dhcpservers", stdOut: dhcpServers}
+
+	assert.EqualError(t, driver.checkStaticIPOutsideDHCPRange(), `Static IP 192.168.56.150 is in the range 192.168.56.100 - 192.168.56.199 leased by the DHCP server of the host-only network "vboxnet1"`)
+
+	driver.StaticIP = "192.168.56.200"
+
+	assert.NoError(t, driver.checkStaticIPOutsideDHCPRange())
+}
+
+func TestNetworkScript(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.StaticIP = "192.168.99.50"
+	driver.DNSServers = []string{"8.8.8.8", "8.8.4.4"}
+
+	script, err := driver.networkScript()
+
+	assert.NoError(t, err)
+	assert.Contains(t, script, "ip addr add 192.168.99.50/24 dev eth1\n")
+	assert.Contains(t, script, "echo nameserver 8.8.8.8 > /etc/resolv.conf\n")
+	assert.Contains(t, script, "echo nameserver 8.8.4.4 >> /etc/resolv.conf\n")
+}
+
+func TestSetNetworkOptionsPortForwards(t *testing.T) {
+	driver := newTestDriver("default")
+	portForwards := []drivers.PortForward{
+		{Protocol: "tcp", HostPort: 8080, GuestPort: 80},
+		{Protocol: "udp", HostPort: 5353, GuestPort: 53},
+	}
+
+	err := driver.SetNetworkOptions(drivers.NetworkOptions{PortForwards: portForwards})
+
+	assert.NoError(t, err)
+	assert.Equal(t, portForwards, driver.PortForwards)
+
+	for _, invalid := range [][]drivers.PortForward{
+		{{Protocol: "tcp", HostPort: 2222, GuestPort: 22}},
+		{{Protocol: "tcp", HostPort: 8080, GuestPort: 80}, {Protocol: "tcp", HostPort: 8081, GuestPort: 80}},
+	} {
+		assert.Error(t, newTestDriver("default").SetNetworkOptions(drivers.NetworkOptions{PortForwards: invalid}))
+	}
+}
+
+func TestSetExtraPortForwarding(t *testing.T) {
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.NoError(t, err)
+	hostPort := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	driver := newTestDriver("default")
+	driver.PortForwards = []drivers.PortForward{{Protocol: "tcp", HostPort: hostPort, GuestPort: 80}}
+	mockCalls(t, driver, []Call{
+		{"vbm modifyvm default --natpf1 delete tcp-80", "", nil},
+		{fmt.Sprintf("vbm modifyvm default --natpf1 tcp-80,tcp,127.0.0.1,%d,,80", hostPort), "", nil},
+	})
+
+	err = driver.setExtraPortForwarding()
+
+	assert.NoError(t, err)
+	assert.Equal(t, hostPort, driver.PortForwards[0].HostPort)
+}
@@ -70,6 +70,14 @@ type Driver struct {
 	DNSProxy            bool
 	NoVTXCheck          bool
 	ShareFolder         string
+	StaticIP            string
+	DNSServers          []string
+	HostOnlyNetwork     string
+	PortForwards        []drivers.PortForward
+	PCIDevices          []string
+
+	// cloneSource is the machine the VM is created from, see CreateClone
+	cloneSource *Driver
 }
 
 // NewDriver creates a new VirtualBox driver with default settings.
@@ -138,7 +146,7 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		},
 		mcnflag.BoolFlag{
 			Name:   "virtualbox-host-dns-resolver",
-			Usage:  "Use the host DNS resolver",
+			Usage:  "Use the host DNS resolver instead of proxying DNS requests. Fixes name resolution when the host is on a VPN.",
 			EnvVar: "VIRTUALBOX_HOST_DNS_RESOLVER",
 		},
 		mcnflag.StringFlag{
@@ -149,7 +157,7 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 		},
 		mcnflag.StringFlag{
 			Name:   "virtualbox-hostonly-cidr",
-			Usage:  "Specify the Host Only CIDR",
+			Usage:  "Specify the Host Only CIDR, e.g. to avoid clashing with a network routed by a VPN",
 			Value:  defaultHostOnlyCIDR,
 			EnvVar: "VIRTUALBOX_HOSTONLY_CIDR",
 		},
@@ -160,16 +168,18 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			EnvVar: "VIRTUALBOX_HOSTONLY_NIC_TYPE",
 		},
 		mcnflag.StringFlag{
-			Name:   "virtualbox-hostonly-nicpromisc",
-			Usage:  "Specify the Host Only Network Adapter Promiscuous Mode",
-			Value:  defaultHostOnlyPromiscMode,
-			EnvVar: "VIRTUALBOX_HOSTONLY_NIC_PROMISC",
+			Name:    "virtualbox-hostonly-nicpromisc",
+			Usage:   "Specify the Host Only Network Adapter Promiscuous Mode",
+			Value:   defaultHostOnlyPromiscMode,
+			Choices: []string{"deny", "allow-vms", "allow-all"},
+			EnvVar:  "VIRTUALBOX_HOSTONLY_NIC_PROMISC",
 		},
 		mcnflag.StringFlag{
-			Name:   "virtualbox-ui-type",
-			Usage:  "Specify the UI Type: (gui|sdl|headless|separate)",
-			Value:  defaultUIType,
-			EnvVar: "VIRTUALBOX_UI_TYPE",
+			Name:    "virtualbox-ui-type",
+			Usage:   "Specify the UI Type",
+			Value:   defaultUIType,
+			Choices: []string{"gui", "sdl", "headless", "separate"},
+			EnvVar:  "VIRTUALBOX_UI_TYPE",
 		},
 		mcnflag.BoolFlag{
 			Name:   "virtualbox-hostonly-no-dhcp",
@@ -224,10 +234,14 @@ func (d *Driver) GetURL() (string, error) {
 	if ip == "" {
 		return "", nil
 	}
-	return fmt.Sprintf("tcp://%s:2376", ip), nil
+	return fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, "2376")), nil
 }
 
 func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
+	if err := drivers.ValidateFlags(d.GetCreateFlags(), flags); err != nil {
+		return err
+	}
+
 	d.CPU = flags.Int("virtualbox-cpu-count")
 	d.Memory = flags.Int("virtualbox-memory")
 	d.DiskSize = flags.Int("virtualbox-disk-size")
@@ -247,6 +261,17 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.NoVTXCheck = flags.Bool("virtualbox-no-vtx-check")
 	d.ShareFolder = flags.String("virtualbox-share-folder")
 
+	// Catch a bad host-only network now rather than when the VM first starts
+	if _, _, err := parseAndValidateCIDR(d.HostOnlyCIDR); err != nil {
+		return err
+	}
+
+	// The host resolver answers the DNS requests itself, so the proxy would
+	// only forward to servers a VPN may have made unreachable.
+	if d.HostDNSResolver {
+		d.DNSProxy = false
+	}
+
 	return nil
 }
 
@@ -284,7 +309,7 @@ func (d *Driver) PreCreateCheck() error {
 		return err
 	}
 
-	return nil
+	return d.checkStaticIPOutsideDHCPRange()
 }
 
 func (d *Driver) Create() error {
@@ -303,8 +328,12 @@ func (d *Driver) CreateVM() error {
 
 	log.Info("Creating VirtualBox VM...")
 
-	// import b2d VM if requested
-	if d.Boot2DockerImportVM != "" {
+	// copy the machine cloned from, or import b2d VM if requested
+	if d.cloneSource != nil {
+		if err := d.copyCloneSource(); err != nil {
+			return err
+		}
+	} else if d.Boot2DockerImportVM != "" {
 		name := d.Boot2DockerImportVM
 
 		// make sure vm is stopped
@@ -410,6 +439,10 @@ func (d *Driver) CreateVM() error {
 		return err
 	}
 
+	if err := d.attachPCIDevices(); err != nil {
+		return err
+	}
+
 	if err := d.vbm("modifyvm", d.MachineName,
 		"--nic1", "nat",
 		"--nictype1", d.NatNicType,
@@ -527,6 +560,10 @@ func (d *Driver) Start() error {
 			return err
 		}
 
+		if err := d.setExtraPortForwarding(); err != nil {
+			return err
+		}
+
 		if err := d.vbm("startvm", d.MachineName, "--type", d.UIType); err != nil {
 			if lines, readErr := d.readVBoxLog(); readErr == nil && len(lines) > 0 {
 				return fmt.Errorf("Unable to start the VM: %s\nDetails: %s", err, lines[len(lines)-1])
@@ -559,7 +596,12 @@ func (d *Driver) Start() error {
 		return err
 	}
 
-	if hostOnlyAdapter == nil {
+	if err := d.configureNetwork(); err != nil {
+		return err
+	}
+
+	// A host-only network picked by the user is left as is
+	if hostOnlyAdapter == nil || d.HostOnlyNetwork != "" {
 		return nil
 	}
 
@@ -608,7 +650,11 @@ func (d *Driver) Start() error {
 	}
 
 	log.Infof("Waiting for an IP...")
-	return d.ipWaiter.Wait(d)
+	if err := d.ipWaiter.Wait(d); err != nil {
+		return err
+	}
+
+	return d.configureNetwork()
 }
 
 func (d *Driver) Stop() error {
@@ -663,6 +709,17 @@ func (d *Driver) Kill() error {
 	return d.vbm("controlvm", d.MachineName, "poweroff")
 }
 
+// SetAutostart has VirtualBox start the VM on boot, and shut it down cleanly
+// when the host stops. The autostart service of VirtualBox must be set up on
+// the host for the VM to actually start.
+func (d *Driver) SetAutostart(enabled bool) error {
+	if !enabled {
+		return d.vbm("modifyvm", d.MachineName, "--autostart-enabled", "off", "--autostop-type", "disabled")
+	}
+
+	return d.vbm("modifyvm", d.MachineName, "--autostart-enabled", "on", "--autostop-type", "acpishutdown")
+}
+
 func (d *Driver) Remove() error {
 	s, err := d.GetState()
 	if err == ErrMachineNotExist {
@@ -681,6 +738,16 @@ func (d *Driver) Remove() error {
 	return d.vbm("unregistervm", "--delete", d.MachineName)
 }
 
+// InstanceExists tells whether the VM is still registered in VirtualBox.
+func (d *Driver) InstanceExists() (bool, error) {
+	_, err := d.GetState()
+	if err == ErrMachineNotExist {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
 func (d *Driver) GetState() (state.State, error) {
 	stdout, stderr, err := d.vbmOutErr("showvminfo", d.MachineName, "--machinereadable")
 	if err != nil {
@@ -810,6 +877,10 @@ func (d *Driver) diskPath() string {
 }
 
 func (d *Driver) setupHostOnlyNetwork(machineName string) (*hostOnlyNetwork, error) {
+	if d.HostOnlyNetwork != "" {
+		return d.setupNamedHostOnlyNetwork(machineName)
+	}
+
 	hostOnlyCIDR := d.HostOnlyCIDR
 
 	// This is to assist in migrating from version 0.2 to 0.3 format
@@ -862,18 +933,42 @@ func (d *Driver) setupHostOnlyNetwork(machineName string) (*hostOnlyNetwork, err
 		return nil, err
 	}
 
-	if err := d.vbm("modifyvm", machineName,
-		"--nic2", "hostonly",
-		"--nictype2", d.HostOnlyNicType,
-		"--nicpromisc2", d.HostOnlyPromiscMode,
-		"--hostonlyadapter2", hostOnlyAdapter.Name,
-		"--cableconnected2", "on"); err != nil {
+	if err := d.attachHostOnlyAdapter(machineName, hostOnlyAdapter); err != nil {
 		return nil, err
 	}
 
 	return hostOnlyAdapter, nil
 }
 
+// setupNamedHostOnlyNetwork attaches the VM to the existing host-only
+// network chosen by the user, whose DHCP configuration is left untouched.
+func (d *Driver) setupNamedHostOnlyNetwork(machineName string) (*hostOnlyNetwork, error) {
+	nets, err := listHostOnlyAdapters(d.VBoxManager)
+	if err != nil {
+		return nil, err
+	}
+
+	hostOnlyAdapter, present := nets[d.HostOnlyNetwork]
+	if !present {
+		return nil, fmt.Errorf("Host-only network %q does not exist", d.HostOnlyNetwork)
+	}
+
+	if err := d.attachHostOnlyAdapter(machineName, hostOnlyAdapter); err != nil {
+		return nil, err
+	}
+
+	return hostOnlyAdapter, nil
+}
+
+func (d *Driver) attachHostOnlyAdapter(machineName string, hostOnlyAdapter *hostOnlyNetwork) error {
+	return d.vbm("modifyvm", machineName,
+		"--nic2", "hostonly",
+		"--nictype2", d.HostOnlyNicType,
+		"--nicpromisc2", d.HostOnlyPromiscMode,
+		"--hostonlyadapter2", hostOnlyAdapter.Name,
+		"--cableconnected2", "on")
+}
+
 func getDHCPAddressRange(dhcpAddr net.IP, network *net.IPNet) (lowerIP net.IP, upperIP net.IP) {
 	nAddr := network.IP.To4()
 	ones, bits := network.Mask.Size()
@@ -1013,6 +1108,17 @@ func detectVBoxManageCmdInPath() string {
 	return cmd
 }
 
+// GetConsoleOutput returns the log VirtualBox keeps of the last run of the
+// VM, which tells why it did not boot or reach the network.
+func (d *Driver) GetConsoleOutput() (string, error) {
+	lines, err := d.readVBoxLog()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
 func (d *Driver) readVBoxLog() ([]string, error) {
 	logPath := filepath.Join(d.ResolveStorePath(d.MachineName), "Logs", "VBox.log")
 	log.Debugf("Checking vm logs: %s", logPath)
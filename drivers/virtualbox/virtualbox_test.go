@@ -109,6 +109,16 @@ func TestState(t *testing.T) {
 	}
 }
 
+func TestSetAutostart(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.VBoxManager = &VBoxManagerMock{
+		args: "modifyvm default --autostart-enabled on --autostop-type acpishutdown",
+	}
+
+	assert.NoError(t, driver.SetAutostart(true))
+	assert.Error(t, driver.SetAutostart(false))
+}
+
 func TestStateErrors(t *testing.T) {
 	var tests = []struct {
 		stdErr   string
@@ -394,6 +404,39 @@ func TestSetConfigFromFlags(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Empty(t, checkFlags.InvalidFlags)
+	assert.True(t, driver.DNSProxy)
+}
+
+func TestSetConfigFromFlagsHostDNSResolver(t *testing.T) {
+	driver := newTestDriver("default")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"virtualbox-host-dns-resolver": true,
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.NoError(t, err)
+	assert.True(t, driver.HostDNSResolver)
+	assert.False(t, driver.DNSProxy)
+}
+
+func TestSetConfigFromFlagsInvalidHostOnlyCIDR(t *testing.T) {
+	driver := newTestDriver("default")
+
+	checkFlags := &drivers.CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"virtualbox-hostonly-cidr": "192.168.99.0/24",
+		},
+		CreateFlags: driver.GetCreateFlags(),
+	}
+
+	err := driver.SetConfigFromFlags(checkFlags)
+
+	assert.Equal(t, ErrNetworkAddrCidr, err)
 }
 
 type MockCreateOperations struct {
@@ -730,3 +773,15 @@ func TestRemovePaused(t *testing.T) {
 
 	assert.NoError(t, err)
 }
+
+func TestGetConsoleOutput(t *testing.T) {
+	driver := newTestDriver("default")
+	driver.logsReader = &MockLogsReader{
+		content: []string{"VirtualBox VM 5.1.30 r118389", "Guest Additions not found"},
+	}
+
+	output, err := driver.GetConsoleOutput()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "VirtualBox VM 5.1.30 r118389\nGuest Additions not found", output)
+}
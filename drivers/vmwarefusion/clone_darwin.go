@@ -0,0 +1,66 @@
+package vmwarefusion
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// templateSnapshot is the snapshot of the template linked clones are created
+// from. It is taken on the first clone when the template does not have it.
+const templateSnapshot = "docker-machine-base"
+
+// cloneTemplate creates the machine as a clone of the template. Linked clones
+// share the disks of the template snapshot instead of copying them.
+func (d *Driver) cloneTemplate() error {
+	cloneType := "full"
+	args := []string{}
+
+	if !d.FullClone {
+		cloneType = "linked"
+
+		stdout, _, err := vmrun("listSnapshots", d.Template)
+		if err != nil {
+			return fmt.Errorf("Unable to list the snapshots of the template %s: %s", d.Template, err)
+		}
+		if !hasSnapshot(stdout, templateSnapshot) {
+			log.Infof("Taking snapshot %s of the template...", templateSnapshot)
+			if _, stderr, err := vmrun("snapshot", d.Template, templateSnapshot); err != nil {
+				return fmt.Errorf("Unable to take a snapshot of the template %s: %s", d.Template, strings.TrimSpace(stderr))
+			}
+		}
+
+		args = append(args, "-snapshot="+templateSnapshot)
+	}
+
+	log.Infof("Creating %s clone of %s...", cloneType, d.Template)
+	args = append([]string{"clone", d.Template, d.vmxPath(), cloneType}, args...)
+	args = append(args, "-cloneName="+d.MachineName)
+	if stdout, _, err := vmrun(args...); err != nil {
+		return fmt.Errorf("Unable to clone the template %s: %s", d.Template, strings.TrimSpace(stdout))
+	}
+
+	content, err := ioutil.ReadFile(d.vmxPath())
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(d.vmxPath(), []byte(setVmxOptions(string(content), map[string]string{
+		"displayName": d.MachineName,
+		"memsize":     strconv.Itoa(d.Memory),
+		"numvcpus":    strconv.Itoa(d.CPU),
+	})), 0644)
+}
+
+// hasSnapshot tells whether the output of vmrun listSnapshots has the snapshot.
+func hasSnapshot(listSnapshots, name string) bool {
+	for _, line := range strings.Split(listSnapshots, "\n") {
+		if strings.TrimSpace(line) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -47,6 +47,8 @@ type Driver struct {
 	ConfigDriveISO string
 	ConfigDriveURL string
 	NoShare        bool
+	Template       string
+	FullClone      bool
 }
 
 const (
@@ -108,6 +110,16 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Name:   "vmwarefusion-no-share",
 			Usage:  "Disable the mount of your home directory",
 		},
+		mcnflag.StringFlag{
+			EnvVar: "FUSION_TEMPLATE",
+			Name:   "vmwarefusion-template",
+			Usage:  "Path to the .vmx file of a virtual machine to clone the machine from",
+		},
+		mcnflag.BoolFlag{
+			EnvVar: "FUSION_FULL_CLONE",
+			Name:   "vmwarefusion-full-clone",
+			Usage:  "Copy the disks of the template instead of creating a linked clone",
+		},
 	}
 }
 
@@ -155,6 +167,8 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.SSHPassword = flags.String("vmwarefusion-ssh-password")
 	d.SSHPort = 22
 	d.NoShare = flags.Bool("vmwarefusion-no-share")
+	d.Template = flags.String("vmwarefusion-template")
+	d.FullClone = flags.Bool("vmwarefusion-full-clone")
 
 	// We support a maximum of 16 cpu to be consistent with Virtual Hardware 10
 	// specs.
@@ -222,6 +236,13 @@ func (d *Driver) GetState() (state.State, error) {
 
 // PreCreateCheck checks that the machine creation process can be started safely.
 func (d *Driver) PreCreateCheck() error {
+	if d.Template != "" {
+		if _, err := os.Stat(d.Template); err != nil {
+			return fmt.Errorf("Unable to find the template %s: %s", d.Template, err)
+		}
+		return nil
+	}
+
 	// Downloading boot2docker to cache should be done here to make sure
 	// that a download failure will not leave a machine half created.
 	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
@@ -234,8 +255,10 @@ func (d *Driver) PreCreateCheck() error {
 
 func (d *Driver) Create() error {
 	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
-		return err
+	if d.Template == "" {
+		if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+			return err
+		}
 	}
 
 	// download cloud-init config drive
@@ -259,30 +282,37 @@ func (d *Driver) Create() error {
 		return ErrMachineExist
 	}
 
-	// Generate vmx config file from template
-	vmxt := template.Must(template.New("vmx").Parse(vmx))
-	vmxfile, err := os.Create(d.vmxPath())
-	if err != nil {
-		return err
-	}
-	vmxt.Execute(vmxfile, d)
-
-	// Generate vmdk file
-	diskImg := d.ResolveStorePath(fmt.Sprintf("%s.vmdk", d.MachineName))
-	if _, err := os.Stat(diskImg); err != nil {
-		if !os.IsNotExist(err) {
+	if d.Template != "" {
+		if err := d.cloneTemplate(); err != nil {
 			return err
 		}
-
-		if err := vdiskmanager(diskImg, d.DiskSize); err != nil {
+	} else {
+		// Generate vmx config file from template
+		vmxt := template.Must(template.New("vmx").Parse(vmx))
+		vmxfile, err := os.Create(d.vmxPath())
+		if err != nil {
 			return err
 		}
+		vmxt.Execute(vmxfile, d)
+
+		// Generate vmdk file
+		diskImg := d.ResolveStorePath(fmt.Sprintf("%s.vmdk", d.MachineName))
+		if _, err := os.Stat(diskImg); err != nil {
+			if !os.IsNotExist(err) {
+				return err
+			}
+
+			if err := vdiskmanager(diskImg, d.DiskSize); err != nil {
+				return err
+			}
+		}
 	}
 
 	log.Infof("Starting %s...", d.MachineName)
 	vmrun("start", d.vmxPath(), "nogui")
 
 	var ip string
+	var err error
 
 	log.Infof("Waiting for VM to come online...")
 	for i := 1; i <= 60; i++ {
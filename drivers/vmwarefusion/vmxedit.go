@@ -0,0 +1,39 @@
+package vmwarefusion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// setVmxOptions sets the given options in the content of a vmx file,
+// replacing the lines of the options already there and appending the others.
+func setVmxOptions(vmx string, options map[string]string) string {
+	lines := strings.Split(strings.TrimRight(vmx, "\n"), "\n")
+	set := map[string]bool{}
+
+	for i, line := range lines {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		if value, ok := options[key]; ok {
+			lines[i] = fmt.Sprintf("%s = %q", key, value)
+			set[key] = true
+		}
+	}
+
+	keys := []string{}
+	for key := range options {
+		if !set[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s = %q", key, options[key]))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,26 @@
+package vmwarefusion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetVmxOptions(t *testing.T) {
+	vmx := `.encoding = "UTF-8"
+displayName = "base"
+memsize = "1024"
+`
+
+	result := setVmxOptions(vmx, map[string]string{
+		"displayName": "default",
+		"memsize":     "2048",
+		"numvcpus":    "2",
+	})
+
+	assert.Equal(t, `.encoding = "UTF-8"
+displayName = "default"
+memsize = "2048"
+numvcpus = "2"
+`, result)
+}
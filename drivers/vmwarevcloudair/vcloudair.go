@@ -149,6 +149,12 @@ func (d *Driver) DriverName() string {
 	return "vmwarevcloudair"
 }
 
+// GetSensitiveFields returns the password of the vCloud Air user, for it to
+// be redacted.
+func (d *Driver) GetSensitiveFields() ([]string, error) {
+	return []string{"UserPassword"}, nil
+}
+
 func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 
 	d.UserName = flags.String("vmwarevcloudair-username")
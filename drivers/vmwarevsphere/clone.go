@@ -0,0 +1,84 @@
+package vmwarevsphere
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/net/context"
+)
+
+// cloneTemplate creates the virtual machine from the template. Unless a full
+// clone is asked for, the disks of the clone are children of the disks of the
+// current snapshot of the template, which takes seconds rather than copying
+// them.
+func (d *Driver) cloneTemplate(ctx context.Context, f *find.Finder, folder *object.Folder,
+	rp *object.ResourcePool, hs *object.HostSystem, dss *object.Datastore) (*object.VirtualMachine, error) {
+	template, err := f.VirtualMachine(ctx, d.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot *types.ManagedObjectReference
+	if !d.FullClone {
+		var mvm mo.VirtualMachine
+		if err := template.Properties(ctx, template.Reference(), []string{"snapshot"}, &mvm); err != nil {
+			return nil, err
+		}
+		if mvm.Snapshot == nil || mvm.Snapshot.CurrentSnapshot == nil {
+			return nil, fmt.Errorf("template %q has no snapshot to create a linked clone from, take one or use --vmwarevsphere-full-clone", d.Template)
+		}
+		snapshot = mvm.Snapshot.CurrentSnapshot
+	}
+
+	rpRef := rp.Reference()
+	dsRef := dss.Reference()
+	var hsRef *types.ManagedObjectReference
+	if hs != nil {
+		ref := hs.Reference()
+		hsRef = &ref
+	}
+
+	if d.FullClone {
+		log.Infof("Cloning VM from %s...", d.Template)
+	} else {
+		log.Infof("Creating linked clone of %s...", d.Template)
+	}
+	task, err := template.Clone(ctx, folder, d.MachineName, d.newCloneSpec(snapshot, &rpRef, hsRef, &dsRef))
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.NewVirtualMachine(template.Client(), info.Result.(types.ManagedObjectReference)), nil
+}
+
+// newCloneSpec returns the specification of a clone sized after the driver
+// options. The clone is linked to the given snapshot when there is one.
+func (d *Driver) newCloneSpec(snapshot, pool, host, datastore *types.ManagedObjectReference) types.VirtualMachineCloneSpec {
+	diskMoveType := types.VirtualMachineRelocateDiskMoveOptionsMoveAllDiskBackingsAndDisallowSharing
+	if snapshot != nil {
+		diskMoveType = types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking
+	}
+
+	return types.VirtualMachineCloneSpec{
+		Location: types.VirtualMachineRelocateSpec{
+			Pool:         pool,
+			Host:         host,
+			Datastore:    datastore,
+			DiskMoveType: string(diskMoveType),
+		},
+		Snapshot: snapshot,
+		Config: &types.VirtualMachineConfigSpec{
+			NumCPUs:  int32(d.CPU),
+			MemoryMB: int64(d.Memory),
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package vmwarevsphere
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestNewCloneSpecLinked(t *testing.T) {
+	driver := &Driver{CPU: 2, Memory: 4096}
+	snapshot := &types.ManagedObjectReference{Type: "VirtualMachineSnapshot", Value: "snapshot-1"}
+
+	spec := driver.newCloneSpec(snapshot, nil, nil, nil)
+
+	assert.Equal(t, snapshot, spec.Snapshot)
+	assert.Equal(t, string(types.VirtualMachineRelocateDiskMoveOptionsCreateNewChildDiskBacking), spec.Location.DiskMoveType)
+	assert.Equal(t, int32(2), spec.Config.NumCPUs)
+	assert.Equal(t, int64(4096), spec.Config.MemoryMB)
+}
+
+func TestNewCloneSpecFull(t *testing.T) {
+	driver := &Driver{CPU: 1, Memory: 1024}
+
+	spec := driver.newCloneSpec(nil, nil, nil, nil)
+
+	assert.Nil(t, spec.Snapshot)
+	assert.Equal(t, string(types.VirtualMachineRelocateDiskMoveOptionsMoveAllDiskBackingsAndDisallowSharing), spec.Location.DiskMoveType)
+}
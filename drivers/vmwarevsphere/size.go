@@ -0,0 +1,26 @@
+package vmwarevsphere
+
+import "github.com/docker/machine/libmachine/drivers"
+
+// minSize is the smallest VM boot2docker runs docker in.
+var minSize = drivers.Size{Memory: 512, Disk: 1024}
+
+// SetSize sets the memory and the size of the disk of the VM.
+func (d *Driver) SetSize(size drivers.Size) error {
+	if err := size.Check(minSize); err != nil {
+		return err
+	}
+
+	if size.Memory != 0 {
+		d.Memory = size.Memory
+	}
+	if size.Disk != 0 {
+		d.DiskSize = size.Disk
+	}
+	return nil
+}
+
+// GetSize returns the memory and the size of the disk of the VM.
+func (d *Driver) GetSize() (drivers.Size, error) {
+	return drivers.Size{Memory: d.Memory, Disk: d.DiskSize}, nil
+}
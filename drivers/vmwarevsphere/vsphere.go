@@ -15,6 +15,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/machine/libmachine/credentials"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
@@ -66,6 +67,8 @@ type Driver struct {
 	HostSystem string
 	CfgParams  []string
 	CloudInit  string
+	Template   string
+	FullClone  bool
 
 	SSHPassword string
 }
@@ -167,6 +170,16 @@ func (d *Driver) GetCreateFlags() []mcnflag.Flag {
 			Name:   "vmwarevsphere-cloudinit",
 			Usage:  "vSphere cloud-init file or url to set in the guestinfo",
 		},
+		mcnflag.StringFlag{
+			EnvVar: "VSPHERE_TEMPLATE",
+			Name:   "vmwarevsphere-template",
+			Usage:  "vSphere template or virtual machine to clone the docker VM from, using its current snapshot",
+		},
+		mcnflag.BoolFlag{
+			EnvVar: "VSPHERE_FULL_CLONE",
+			Name:   "vmwarevsphere-full-clone",
+			Usage:  "vSphere copy the disks of the template instead of creating a linked clone",
+		},
 	}
 }
 
@@ -222,6 +235,8 @@ func (d *Driver) SetConfigFromFlags(flags drivers.DriverOptions) error {
 	d.HostSystem = flags.String("vmwarevsphere-hostsystem")
 	d.CfgParams = flags.StringSlice("vmwarevsphere-cfgparam")
 	d.CloudInit = flags.String("vmwarevsphere-cloudinit")
+	d.Template = flags.String("vmwarevsphere-template")
+	d.FullClone = flags.Bool("vmwarevsphere-full-clone")
 	d.SetSwarmConfigFromFlags(flags)
 
 	d.ISO = d.ResolveStorePath(isoFilename)
@@ -412,16 +427,10 @@ func (d *Driver) PreCreateCheck() error {
 }
 
 // Create has the following implementation:
-// 1. check whether the docker directory contains the boot2docker ISO
-// 2. generate an SSH keypair and bundle it in a tar.
-// 3. create a virtual machine with the boot2docker ISO mounted;
-// 4. reconfigure the virtual machine network and disk size;
+// 1. generate an SSH keypair and bundle it in a tar.
+// 2. create a virtual machine with the boot2docker ISO mounted, or clone it;
+// 3. reconfigure the virtual machine network and disk size;
 func (d *Driver) Create() error {
-	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
-		return err
-	}
-
 	log.Infof("Generating SSH Keypair...")
 	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
 		return err
@@ -452,15 +461,6 @@ func (d *Driver) Create() error {
 		return err
 	}
 
-	networks := make(map[string]object.NetworkReference)
-	for _, netName := range d.Networks {
-		net, err := f.NetworkOrDefault(ctx, netName)
-		if err != nil {
-			return err
-		}
-		networks[netName] = net
-	}
-
 	var hs *object.HostSystem
 	if d.HostSystem != "" {
 		var err error
@@ -491,26 +491,10 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	spec := types.VirtualMachineConfigSpec{
-		Name:     d.MachineName,
-		GuestId:  "otherLinux64Guest",
-		Files:    &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", dss.Name())},
-		NumCPUs:  int32(d.CPU),
-		MemoryMB: int64(d.Memory),
-	}
-
-	scsi, err := object.SCSIControllerTypes().CreateSCSIController("pvscsi")
+	folders, err := dc.Folders(ctx)
 	if err != nil {
 		return err
 	}
-
-	spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
-		Operation: types.VirtualDeviceConfigSpecOperationAdd,
-		Device:    scsi,
-	})
-
-	log.Infof("Creating VM...")
-	folders, err := dc.Folders(ctx)
 	folder := folders.VmFolder
 	if d.Folder != "" {
 		folder, err = f.Folder(ctx, fmt.Sprintf("%s/%s", folders.VmFolder.InventoryPath, d.Folder))
@@ -518,80 +502,17 @@ func (d *Driver) Create() error {
 			return err
 		}
 	}
-	task, err := folder.CreateVM(ctx, spec, rp, hs)
-	if err != nil {
-		return err
-	}
-
-	info, err := task.WaitForResult(ctx, nil)
-	if err != nil {
-		return err
-	}
-
-	log.Infof("Uploading Boot2docker ISO ...")
-	dsurl, err := dss.URL(ctx, dc, fmt.Sprintf("%s/%s", d.MachineName, isoFilename))
-	if err != nil {
-		return err
-	}
-	p := soap.DefaultUpload
-	if err = c.Client.UploadFile(d.ISO, dsurl, &p); err != nil {
-		return err
-	}
-
-	// Retrieve the new VM
-	vm := object.NewVirtualMachine(c.Client, info.Result.(types.ManagedObjectReference))
-
-	devices, err := vm.Device(ctx)
-	if err != nil {
-		return err
-	}
-
-	var add []types.BaseVirtualDevice
-
-	controller, err := devices.FindDiskController("scsi")
-	if err != nil {
-		return err
-	}
 
-	disk := devices.CreateDisk(controller, dss.Reference(),
-		dss.Path(fmt.Sprintf("%s/%s.vmdk", d.MachineName, d.MachineName)))
-
-	// Convert MB to KB
-	disk.CapacityInKB = int64(d.DiskSize) * 1024
-
-	add = append(add, disk)
-	ide, err := devices.FindIDEController("")
-	if err != nil {
-		return err
+	var vm *object.VirtualMachine
+	if d.Template != "" {
+		vm, err = d.cloneTemplate(ctx, f, folder, rp, hs, dss)
+	} else {
+		vm, err = d.createFromISO(ctx, c, dc, f, folder, rp, hs, dss)
 	}
-
-	cdrom, err := devices.CreateCdrom(ide)
 	if err != nil {
 		return err
 	}
 
-	add = append(add, devices.InsertIso(cdrom, dss.Path(fmt.Sprintf("%s/%s", d.MachineName, isoFilename))))
-
-	for _, netName := range d.Networks {
-		backing, err := networks[netName].EthernetCardBackingInfo(ctx)
-		if err != nil {
-			return err
-		}
-
-		netdev, err := object.EthernetCardTypes().CreateEthernetCard("vmxnet3", backing)
-		if err != nil {
-			return err
-		}
-
-		log.Infof("adding network: %s", netName)
-		add = append(add, netdev)
-	}
-
-	log.Infof("Reconfiguring VM")
-	if vm.AddDevice(ctx, add...); err != nil {
-		return err
-	}
-
 	// Adding some guestinfo data
 	var opts []types.BaseOptionValue
 	for _, param := range d.CfgParams {
@@ -632,7 +553,7 @@ func (d *Driver) Create() error {
 		}
 	}
 
-	task, err = vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
+	task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{
 		ExtraConfig: opts,
 	})
 	if err != nil {
@@ -716,6 +637,121 @@ func (d *Driver) Create() error {
 	return nil
 }
 
+// createFromISO creates an empty virtual machine booting the boot2docker ISO,
+// uploaded to its folder on the datastore, with a new disk of DiskSize.
+func (d *Driver) createFromISO(ctx context.Context, c *govmomi.Client, dc *object.Datacenter, f *find.Finder,
+	folder *object.Folder, rp *object.ResourcePool, hs *object.HostSystem, dss *object.Datastore) (*object.VirtualMachine, error) {
+	b2dutils := mcnutils.NewB2dUtils(d.StorePath)
+	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, d.MachineName); err != nil {
+		return nil, err
+	}
+
+	networks := make(map[string]object.NetworkReference)
+	for _, netName := range d.Networks {
+		net, err := f.NetworkOrDefault(ctx, netName)
+		if err != nil {
+			return nil, err
+		}
+		networks[netName] = net
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		Name:     d.MachineName,
+		GuestId:  "otherLinux64Guest",
+		Files:    &types.VirtualMachineFileInfo{VmPathName: fmt.Sprintf("[%s]", dss.Name())},
+		NumCPUs:  int32(d.CPU),
+		MemoryMB: int64(d.Memory),
+	}
+
+	scsi, err := object.SCSIControllerTypes().CreateSCSIController("pvscsi")
+	if err != nil {
+		return nil, err
+	}
+
+	spec.DeviceChange = append(spec.DeviceChange, &types.VirtualDeviceConfigSpec{
+		Operation: types.VirtualDeviceConfigSpecOperationAdd,
+		Device:    scsi,
+	})
+
+	log.Infof("Creating VM...")
+	task, err := folder.CreateVM(ctx, spec, rp, hs)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := task.WaitForResult(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Uploading Boot2docker ISO ...")
+	dsurl, err := dss.URL(ctx, dc, fmt.Sprintf("%s/%s", d.MachineName, isoFilename))
+	if err != nil {
+		return nil, err
+	}
+	p := soap.DefaultUpload
+	if err = c.Client.UploadFile(d.ISO, dsurl, &p); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the new VM
+	vm := object.NewVirtualMachine(c.Client, info.Result.(types.ManagedObjectReference))
+
+	devices, err := vm.Device(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var add []types.BaseVirtualDevice
+
+	controller, err := devices.FindDiskController("scsi")
+	if err != nil {
+		return nil, err
+	}
+
+	disk := devices.CreateDisk(controller, dss.Reference(),
+		dss.Path(fmt.Sprintf("%s/%s.vmdk", d.MachineName, d.MachineName)))
+
+	// Convert MB to KB
+	disk.CapacityInKB = int64(d.DiskSize) * 1024
+
+	add = append(add, disk)
+	ide, err := devices.FindIDEController("")
+	if err != nil {
+		return nil, err
+	}
+
+	cdrom, err := devices.CreateCdrom(ide)
+	if err != nil {
+		return nil, err
+	}
+
+	add = append(add, devices.InsertIso(cdrom, dss.Path(fmt.Sprintf("%s/%s", d.MachineName, isoFilename))))
+
+	for _, netName := range d.Networks {
+		backing, err := networks[netName].EthernetCardBackingInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		netdev, err := object.EthernetCardTypes().CreateEthernetCard("vmxnet3", backing)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("adding network: %s", netName)
+		add = append(add, netdev)
+	}
+
+	log.Infof("Reconfiguring VM")
+	if vm.AddDevice(ctx, add...); err != nil {
+		return nil, err
+	}
+
+	return vm, nil
+
+}
+
 func (d *Driver) Start() error {
 	machineState, err := d.GetState()
 	if err != nil {
@@ -881,19 +917,16 @@ func (d *Driver) Remove() error {
 		return err
 	}
 
-	// Remove B2D Iso from VM folder
+	// Remove B2D Iso from VM folder. Machines cloned from a template have
+	// none, which must not prevent destroying them.
 	m := object.NewFileManager(c.Client)
 	task, err := m.DeleteDatastoreFile(ctx, dss.Path(fmt.Sprintf("%s/%s", d.MachineName, isoFilename)), dc)
 	if err != nil {
 		return err
 	}
 
-	err = task.Wait(ctx)
-	if err != nil {
-		if types.IsFileNotFound(err) {
-			// Ignore error
-			return nil
-		}
+	if err := task.Wait(ctx); err != nil && !types.IsFileNotFound(err) {
+		log.Warnf("Unable to remove the boot2docker ISO: %s", err)
 	}
 
 	vm, err := d.fetchVM(ctx, c, d.MachineName)
@@ -972,6 +1005,13 @@ func (d *Driver) generateKeyBundle() error {
 	return err
 }
 
+// getPassword returns the password given on the command line or, failing that,
+// the one found in a credential source. The latter is never stored on the
+// driver so that it does not end up in config.json.
+func (d *Driver) getPassword() (string, error) {
+	return credentials.Lookup(d.StorePath, d.DriverName(), "password", d.Password)
+}
+
 func (d *Driver) vsphereLogin(ctx context.Context) (*govmomi.Client, error) {
 
 	// Parse URL from string
@@ -979,8 +1019,13 @@ func (d *Driver) vsphereLogin(ctx context.Context) (*govmomi.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	password, err := d.getPassword()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to find the vSphere password (--vmwarevsphere-password): %s", err)
+	}
+
 	// set username and password for the URL
-	u.User = url.UserPassword(d.Username, d.Password)
+	u.User = url.UserPassword(d.Username, password)
 
 	// Connect and log in to ESX or vCenter
 	c, err := govmomi.NewClient(ctx, u, true)
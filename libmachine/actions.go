@@ -0,0 +1,126 @@
+package libmachine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/docker/machine/libmachine/autostart"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+// actionOperations are the operations recorded in the audit log for the
+// actions changing the machines.
+var actionOperations = map[string]string{
+	"configureAuth": persist.AuditCertRotate,
+	"start":         persist.AuditStart,
+	"stop":          persist.AuditStop,
+	"restart":       persist.AuditRestart,
+	"kill":          persist.AuditKill,
+	"upgrade":       persist.AuditUpgrade,
+	"provision":     persist.AuditProvision,
+}
+
+func hostActions(h *host.Host) map[string]func() error {
+	return map[string]func() error{
+		"configureAuth": h.ConfigureAuth,
+		"start":         h.Start,
+		"stop":          h.Stop,
+		"restart":       h.Restart,
+		"kill":          h.Kill,
+		"upgrade":       h.Upgrade,
+		"provision":     h.Reprovision,
+	}
+}
+
+// RunAction runs an action changing a machine, e.g. "start", and records it
+// in the audit log. Whoever changes the machines, e.g. the CLI or the server,
+// goes through it for the audit log to be complete.
+func RunAction(api API, h *host.Host, action string) error {
+	run, ok := hostActions(h)[action]
+	if !ok {
+		return fmt.Errorf("Unknown action %q", action)
+	}
+
+	return RunOperation(api, h.Name, actionOperations[action], run)
+}
+
+// RunOperation runs an operation on a machine, and records it in the audit
+// log unless the operation is empty.
+func RunOperation(api API, name, operation string, run func() error) error {
+	err := run()
+	if operation != "" {
+		RecordOperation(api, name, operation, err)
+	}
+
+	return err
+}
+
+// RemoveInstance removes the instance of a machine at its provider. A
+// protected machine is refused, unless overrideProtection is set.
+func RemoveInstance(api API, name string, overrideProtection bool) error {
+	h, err := api.Load(name)
+	if err != nil {
+		return err
+	}
+
+	return removeInstance(api, h, overrideProtection)
+}
+
+// removeInstance removes the instance of a loaded machine, see
+// RemoveInstance.
+func removeInstance(api API, h *host.Host, overrideProtection bool) error {
+	name := h.Name
+
+	if err := h.CheckRemovable(overrideProtection); err != nil {
+		return err
+	}
+	if h.IsProtected() {
+		log.Warnf("Overriding the protection of %s", name)
+	}
+
+	if h.HostOptions != nil && h.HostOptions.Autostart {
+		if err := autostart.Disable(name, storePath(api)); err != nil {
+			log.Warnf("Error unregistering %s from the autostart of the OS: %s", name, err)
+		}
+	}
+
+	if h.InstanceMissing {
+		log.Infof("The instance of %s no longer exists, removing the local reference only", name)
+		return nil
+	}
+
+	return h.Driver.Remove()
+}
+
+// storePath returns the path of the store of the API, the one the autostart
+// of its machines runs docker-machine with.
+func storePath(api API) string {
+	return filepath.Dir(api.GetMachinesDir())
+}
+
+// RemoveMachine removes a machine, usually after its instance, from the
+// store, forgets its host key and records the removal in the audit log.
+func RemoveMachine(api API, name string) error {
+	return removeMachine(api, name, "")
+}
+
+// removeMachine is RemoveMachine recording why the machine is removed in the
+// audit log, e.g. because it expired.
+func removeMachine(api API, name, details string) error {
+	exists, _ := api.Exists(name)
+	if !exists {
+		return mcnerror.ErrHostDoesNotExist{Name: name}
+	}
+
+	if err := ssh.RemoveHostKey(KnownHostsOf(api, name)); err != nil {
+		log.Warnf("Error removing the host key of %s: %s", name, err)
+	}
+
+	err := api.Remove(name)
+	RecordOperationDetails(api, name, persist.AuditRemove, details, err)
+	return err
+}
@@ -0,0 +1,35 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAction(t *testing.T) {
+	h := &host.Host{Name: "foo", Driver: &fakedriver.Driver{MockState: state.Running}}
+	api := &pruneTestAPI{&persisttest.FakeStore{Hosts: []*host.Host{h}}}
+
+	assert.NoError(t, RunAction(api, h, "stop"))
+	assert.Equal(t, state.Stopped, h.Driver.(*fakedriver.Driver).MockState)
+
+	assert.EqualError(t, RunAction(api, h, "explode"), `Unknown action "explode"`)
+}
+
+func TestRemoveInstanceRefusesProtected(t *testing.T) {
+	driver := &removeCountingDriver{Driver: &fakedriver.Driver{}}
+	h := &host.Host{Name: "shared", Driver: driver, HostOptions: &host.Options{Protected: true}}
+	api := &pruneTestAPI{&persisttest.FakeStore{Hosts: []*host.Host{h}}}
+
+	err := RemoveInstance(api, "shared", false)
+	assert.Equal(t, mcnerror.ErrHostProtected{Name: "shared"}, err)
+	assert.Equal(t, 0, driver.removed)
+
+	assert.NoError(t, RemoveInstance(api, "shared", true))
+	assert.Equal(t, 1, driver.removed)
+}
@@ -0,0 +1,126 @@
+package libmachine
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/state"
+)
+
+var (
+	// ErrNoActiveHost is returned when no machine is active.
+	ErrNoActiveHost = errors.New("No active host found")
+
+	errNoActiveStore = errors.New("The store does not keep the active machine")
+)
+
+// IsActive reports whether DOCKER_HOST points at the engine of the machine,
+// or at the swarm it manages. The machine must be running.
+func IsActive(h *host.Host) bool {
+	dockerHost := os.Getenv("DOCKER_HOST")
+	if dockerHost == "" {
+		return false
+	}
+
+	if currentState, err := h.Driver.GetState(); err != nil || currentState != state.Running {
+		return false
+	}
+
+	hostURL, err := h.URL()
+	if err != nil || hostURL == "" {
+		return false
+	}
+
+	if hostURL == dockerHost {
+		return true
+	}
+
+	if h.HostOptions == nil || h.HostOptions.SwarmOptions == nil || !h.HostOptions.SwarmOptions.Master {
+		return false
+	}
+
+	return swarmURL(hostURL, h.HostOptions.SwarmOptions.Host) == dockerHost
+}
+
+// swarmURL returns the URL of the engine with the port of the swarm manager.
+func swarmURL(hostURL, swarmHost string) string {
+	engineURL, err := url.Parse(hostURL)
+	if err != nil {
+		return ""
+	}
+
+	managerURL, err := url.Parse(swarmHost)
+	if err != nil {
+		return ""
+	}
+
+	engineURL.Host = net.JoinHostPort(engineURL.Hostname(), managerURL.Port())
+	return engineURL.String()
+}
+
+// GetActiveHost returns the machine DOCKER_HOST points at when it is set, the
+// machine selected with SetActiveHost otherwise.
+func GetActiveHost(api API) (*host.Host, error) {
+	if os.Getenv("DOCKER_HOST") != "" {
+		names, err := api.List()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			h, err := api.Load(name)
+			if err != nil {
+				log.Debugf("Error loading machine %q: %s", name, err)
+				continue
+			}
+
+			if IsActive(h) {
+				return h, nil
+			}
+		}
+
+		return nil, ErrNoActiveHost
+	}
+
+	activeStore, ok := api.(persist.ActiveStore)
+	if !ok {
+		return nil, ErrNoActiveHost
+	}
+
+	name, err := activeStore.GetActive()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, ErrNoActiveHost
+	}
+
+	return api.Load(name)
+}
+
+// SetActiveHost selects the active machine for when DOCKER_HOST is not set,
+// or clears the selection if name is "".
+func SetActiveHost(api API, name string) error {
+	activeStore, ok := api.(persist.ActiveStore)
+	if !ok {
+		return errNoActiveStore
+	}
+
+	if name != "" {
+		exists, err := api.Exists(name)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return mcnerror.ErrHostDoesNotExist{Name: name}
+		}
+	}
+
+	return activeStore.SetActive(name)
+}
@@ -0,0 +1,85 @@
+package libmachine
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/libmachine/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func newActiveTestHost(currentState state.State, master bool) *host.Host {
+	return &host.Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			BaseDriver: &drivers.BaseDriver{},
+			MockState:  currentState,
+			MockIP:     "192.168.99.100",
+		},
+		HostOptions: &host.Options{
+			SwarmOptions: &swarm.Options{
+				Master: master,
+				Host:   "tcp://0.0.0.0:3376",
+			},
+		},
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+
+	os.Setenv("DOCKER_HOST", "")
+	assert.False(t, IsActive(newActiveTestHost(state.Running, false)))
+
+	os.Setenv("DOCKER_HOST", "tcp://192.168.99.100:2376")
+	assert.True(t, IsActive(newActiveTestHost(state.Running, false)))
+	assert.False(t, IsActive(newActiveTestHost(state.Stopped, false)))
+
+	os.Setenv("DOCKER_HOST", "tcp://192.168.99.100:3376")
+	assert.True(t, IsActive(newActiveTestHost(state.Running, true)))
+	assert.False(t, IsActive(newActiveTestHost(state.Running, false)))
+}
+
+func TestSetActiveHost(t *testing.T) {
+	defer os.Setenv("DOCKER_HOST", os.Getenv("DOCKER_HOST"))
+	os.Setenv("DOCKER_HOST", "")
+
+	storePath, err := ioutil.TempDir("", "machine-active")
+	assert.NoError(t, err)
+	defer os.RemoveAll(storePath)
+
+	api := NewClient(storePath, storePath)
+	assert.NoError(t, api.Save(&host.Host{
+		ConfigVersion: version.ConfigVersion,
+		Name:          "dev",
+		DriverName:    "none",
+		Driver:        none.NewDriver("dev", storePath),
+		HostOptions:   &host.Options{},
+	}))
+
+	_, err = GetActiveHost(api)
+	assert.Equal(t, ErrNoActiveHost, err)
+
+	assert.Error(t, SetActiveHost(api, "unknown"))
+
+	assert.NoError(t, SetActiveHost(api, "dev"))
+	h, err := GetActiveHost(api)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", h.Name)
+
+	assert.NoError(t, SetActiveHost(api, ""))
+	_, err = GetActiveHost(api)
+	assert.Equal(t, ErrNoActiveHost, err)
+
+	assert.NoError(t, SetActiveHost(api, "dev"))
+	assert.NoError(t, api.Remove("dev"))
+	_, err = GetActiveHost(api)
+	assert.Equal(t, ErrNoActiveHost, err)
+}
@@ -0,0 +1,111 @@
+package libmachine
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// waitForSSH checks that the adopted instance is reachable over SSH.
+var waitForSSH = drivers.WaitForSSH
+
+// Discover lists the instances the driver finds tagged as created by
+// docker-machine, see drivers.ResourceTags, which the store has no machine
+// of, e.g. after the store was lost or for the machines created from another
+// computer.
+func Discover(api API, d drivers.Driver) ([]drivers.DiscoveredInstance, error) {
+	instances, err := drivers.DiscoverInstances(d)
+	if err == drivers.ErrNotImplemented {
+		return nil, fmt.Errorf("The %s driver cannot discover instances", d.DriverName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []drivers.DiscoveredInstance{}
+	for _, instance := range instances {
+		if !host.ValidateHostName(instance.MachineName) {
+			log.Debugf("Ignoring the instance %s, tagged with the invalid machine name %q", instance.ID, instance.MachineName)
+			continue
+		}
+
+		exists, err := api.Exists(instance.MachineName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			missing = append(missing, instance)
+		}
+	}
+
+	return missing, nil
+}
+
+// Adopt makes a machine of a discovered instance. The driver of the host,
+// named after the instance and configured with the SSH key of the instance,
+// is set up to manage the instance, which must be running. Once SSH is
+// checked, the certificates of the engine are regenerated, the ones of the
+// instance having been signed by another CA, and the machine is saved.
+func Adopt(api API, h *host.Host, instance drivers.DiscoveredInstance) (err error) {
+	if h.Name != instance.MachineName {
+		return fmt.Errorf("The machine adopting the instance %s must be named %q", instance.ID, instance.MachineName)
+	}
+
+	exists, err := api.Exists(h.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return mcnerror.ErrHostAlreadyExists{Name: h.Name}
+	}
+
+	defer func() {
+		RecordOperationDetails(api, h.Name, persist.AuditAdopt, instance.ID, err)
+	}()
+
+	if err := drivers.AdoptInstance(h.Driver, instance); err != nil {
+		if err == drivers.ErrNotImplemented {
+			return fmt.Errorf("The %s driver cannot adopt instances", h.DriverName)
+		}
+		return fmt.Errorf("Error adopting the instance %s: %s", instance.ID, err)
+	}
+
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+	if currentState != state.Running {
+		return fmt.Errorf("The instance %s is %s, start it to adopt it", instance.ID, currentState)
+	}
+
+	log.Infof("Checking the SSH access to %s...", instance.ID)
+	if err := waitForSSH(h.Driver); err != nil {
+		return fmt.Errorf("Error connecting to %s over SSH, check its SSH key: %s", instance.ID, err)
+	}
+
+	if err := cert.BootstrapCertificates(h.AuthOptions()); err != nil {
+		return fmt.Errorf("Error generating certificates: %s", err)
+	}
+
+	log.Info("Regenerating the certificates of the engine...")
+	if err := h.ConfigureAuth(); err != nil {
+		return err
+	}
+
+	h.CreatePhase = host.CreatePhaseProvisioned
+	if err := api.Save(h); err != nil {
+		return err
+	}
+
+	if client, ok := api.(*Client); ok {
+		client.updateManagedSSHConfig(h.Name, h)
+	}
+
+	return nil
+}
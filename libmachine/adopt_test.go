@@ -0,0 +1,60 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverSkipsStoredMachines(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{newPruneTestHost("stored", false)},
+	}
+	driver := &fakedriver.Driver{
+		MockInstances: []drivers.DiscoveredInstance{
+			{MachineName: "stored", ID: "i-1"},
+			{MachineName: "lost", ID: "i-2"},
+			{MachineName: "not a name", ID: "i-3"},
+		},
+	}
+
+	instances, err := Discover(&pruneTestAPI{store}, driver)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []drivers.DiscoveredInstance{{MachineName: "lost", ID: "i-2"}}, instances)
+}
+
+func TestDiscoverNotImplemented(t *testing.T) {
+	driver := drivers.NewDriverNotSupported("unsupported", "lost", "")
+
+	_, err := Discover(&pruneTestAPI{&persisttest.FakeStore{}}, driver)
+
+	assert.EqualError(t, err, "The unsupported driver cannot discover instances")
+}
+
+func TestAdoptChecksName(t *testing.T) {
+	store := &persisttest.FakeStore{}
+	h := newPruneTestHost("other", false)
+
+	err := Adopt(&pruneTestAPI{store}, h, drivers.DiscoveredInstance{MachineName: "lost", ID: "i-2"})
+
+	assert.EqualError(t, err, `The machine adopting the instance i-2 must be named "lost"`)
+	assert.Empty(t, store.Hosts)
+}
+
+func TestAdoptRefusesStoredMachines(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{newPruneTestHost("stored", false)},
+	}
+	h := newPruneTestHost("stored", false)
+
+	err := Adopt(&pruneTestAPI{store}, h, drivers.DiscoveredInstance{MachineName: "stored", ID: "i-1"})
+
+	assert.Equal(t, mcnerror.ErrHostAlreadyExists{Name: "stored"}, err)
+	assert.Empty(t, h.Driver.(*fakedriver.Driver).Calls)
+}
@@ -0,0 +1,225 @@
+// Package assets keeps a local cache of the files machines are created
+// from, e.g. engine install scripts, package bundles and ISOs, so that
+// creates are repeatable and work without network access once the cache is
+// filled.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	indexFileName = "index.json"
+
+	// checksumFragment pins the checksum of an asset in its URL, e.g.
+	// https://get.docker.com/#sha256=<checksum>
+	checksumFragment = "sha256="
+)
+
+// Entry is an asset of the cache.
+type Entry struct {
+	URL    string
+	File   string
+	SHA256 string
+}
+
+// Cache is a directory of downloaded assets, indexed by their URL. The
+// checksum of an asset is pinned when it is first downloaded: an asset that
+// changes upstream is refused rather than silently replaced.
+type Cache struct {
+	Dir string
+}
+
+// NewCache returns the cache of the given directory.
+func NewCache(dir string) *Cache {
+	return &Cache{
+		Dir: dir,
+	}
+}
+
+// IsRemote reports whether the URL is an HTTP(S) URL, which the cache can
+// fetch.
+func IsRemote(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://")
+}
+
+// IsPinned reports whether the URL pins the checksum of the asset.
+func IsPinned(rawURL string) bool {
+	_, checksum := splitChecksum(rawURL)
+	return checksum != ""
+}
+
+// Fetch returns the path of the cached copy of the asset, downloading it
+// first if it is not cached yet. A #sha256=<checksum> fragment in the URL
+// pins the expected checksum, and replaces the one of an asset already
+// cached with a different content.
+func (c *Cache) Fetch(rawURL string) (string, error) {
+	assetURL, expected := splitChecksum(rawURL)
+
+	index, err := c.readIndex()
+	if err != nil {
+		return "", err
+	}
+
+	entry, cached := index[assetURL]
+	if cached && (expected == "" || expected == entry.SHA256) {
+		filePath := filepath.Join(c.Dir, entry.File)
+		sum, err := fileChecksum(filePath)
+		if err == nil && sum == entry.SHA256 {
+			log.Debugf("Using the cached %s", filePath)
+			return filePath, nil
+		}
+		log.Warnf("The cached copy of %s is missing or corrupted, downloading it again", assetURL)
+	}
+
+	if expected == "" && cached {
+		expected = entry.SHA256
+	}
+
+	entry, err = c.download(assetURL, expected)
+	if err != nil {
+		return "", err
+	}
+
+	index[assetURL] = entry
+	if err := c.writeIndex(index); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(c.Dir, entry.File), nil
+}
+
+func (c *Cache) download(assetURL, expected string) (*Entry, error) {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	log.Infof("Downloading %s to the asset cache...", assetURL)
+
+	resp, err := http.Get(assetURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error downloading %s: %s", assetURL, resp.Status)
+	}
+
+	// Download to a temp file first then rename it to avoid partial download.
+	f, err := ioutil.TempFile(c.Dir, "download")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(f, hash), resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error downloading %s: %s", assetURL, err)
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if expected != "" && sum != expected {
+		return nil, fmt.Errorf("The checksum of %s is %s but %s is pinned, pin the new checksum with #%s<checksum> to accept it", assetURL, sum, expected, checksumFragment)
+	}
+
+	entry := &Entry{
+		URL:    assetURL,
+		File:   fileName(assetURL),
+		SHA256: sum,
+	}
+
+	if err := os.Rename(f.Name(), filepath.Join(c.Dir, entry.File)); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (c *Cache) readIndex() (map[string]*Entry, error) {
+	index := map[string]*Entry{}
+
+	data, err := ioutil.ReadFile(filepath.Join(c.Dir, indexFileName))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("Error reading the asset cache index: %s", err)
+	}
+
+	return index, nil
+}
+
+func (c *Cache) writeIndex(index map[string]*Entry) error {
+	data, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(c.Dir, indexFileName), data, 0600)
+}
+
+// splitChecksum splits the checksum pinned in the fragment of the URL off.
+func splitChecksum(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !strings.HasPrefix(u.Fragment, checksumFragment) {
+		return rawURL, ""
+	}
+
+	checksum := strings.ToLower(strings.TrimPrefix(u.Fragment, checksumFragment))
+	u.Fragment = ""
+
+	return u.String(), checksum
+}
+
+// fileName names the cached copy after the URL, keeping the base name of the
+// asset readable.
+func fileName(assetURL string) string {
+	hash := sha256.Sum256([]byte(assetURL))
+	prefix := hex.EncodeToString(hash[:])[:12]
+
+	base := "asset"
+	if u, err := url.Parse(assetURL); err == nil {
+		if b := path.Base(u.Path); b != "." && b != "/" {
+			base = b
+		}
+	}
+
+	return prefix + "-" + base
+}
+
+func fileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
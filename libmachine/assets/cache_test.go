@@ -0,0 +1,118 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type assetServer struct {
+	*httptest.Server
+	content  string
+	requests int
+}
+
+func newAssetServer(content string) *assetServer {
+	s := &assetServer{content: content}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests++
+		fmt.Fprint(w, s.content)
+	}))
+	return s
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestCache(t *testing.T) *Cache {
+	dir, err := ioutil.TempDir("", "assets-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCache(dir)
+}
+
+func TestFetchCachesAssets(t *testing.T) {
+	server := newAssetServer("#!/bin/sh\n")
+	defer server.Close()
+
+	cache := newTestCache(t)
+	defer os.RemoveAll(cache.Dir)
+
+	first, err := cache.Fetch(server.URL + "/install.sh")
+	assert.NoError(t, err)
+
+	second, err := cache.Fetch(server.URL + "/install.sh")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, server.requests)
+
+	content, _ := ioutil.ReadFile(first)
+	assert.Equal(t, "#!/bin/sh\n", string(content))
+}
+
+func TestFetchPinnedChecksum(t *testing.T) {
+	server := newAssetServer("bundle")
+	defer server.Close()
+
+	cache := newTestCache(t)
+	defer os.RemoveAll(cache.Dir)
+
+	_, err := cache.Fetch(server.URL + "/bundle.tgz#sha256=" + checksum("other"))
+	assert.Error(t, err)
+
+	_, err = cache.Fetch(server.URL + "/bundle.tgz#sha256=" + checksum("bundle"))
+	assert.NoError(t, err)
+}
+
+func TestFetchRefusesChangedAsset(t *testing.T) {
+	server := newAssetServer("v1")
+	defer server.Close()
+
+	cache := newTestCache(t)
+	defer os.RemoveAll(cache.Dir)
+
+	filePath, err := cache.Fetch(server.URL + "/boot2docker.iso")
+	assert.NoError(t, err)
+
+	// Corrupt the cached copy so that it is downloaded again
+	server.content = "v2"
+	assert.NoError(t, ioutil.WriteFile(filePath, []byte("corrupted"), 0600))
+
+	_, err = cache.Fetch(server.URL + "/boot2docker.iso")
+	assert.Error(t, err)
+
+	_, err = cache.Fetch(server.URL + "/boot2docker.iso#sha256=" + checksum("v2"))
+	assert.NoError(t, err)
+}
+
+func TestSplitChecksum(t *testing.T) {
+	assetURL, sum := splitChecksum("https://get.docker.com/#sha256=ABC")
+	assert.Equal(t, "https://get.docker.com/", assetURL)
+	assert.Equal(t, "abc", sum)
+
+	assetURL, sum = splitChecksum("https://get.docker.com")
+	assert.Equal(t, "https://get.docker.com", assetURL)
+	assert.Equal(t, "", sum)
+}
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("https://get.docker.com"))
+	assert.False(t, IsRemote("file:///tmp/install.sh"))
+	assert.False(t, IsRemote("/tmp/bundle.tgz"))
+}
+
+func TestIsPinned(t *testing.T) {
+	assert.True(t, IsPinned("https://example.com/boot2docker.iso#sha256=abc"))
+	assert.False(t, IsPinned("https://example.com/boot2docker.iso"))
+}
@@ -0,0 +1,46 @@
+package libmachine
+
+import (
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// RecordOperation records an operation on a machine in the audit log of the
+// store, if the store keeps one. Failing to record is logged rather than
+// failing the operation.
+func RecordOperation(api API, machine, operation string, opErr error) {
+	RecordOperationDetails(api, machine, operation, "", opErr)
+}
+
+// RecordOperationDetails is RecordOperation with a description of the
+// operation, e.g. the changed setting.
+func RecordOperationDetails(api API, machine, operation, details string, opErr error) {
+	auditLog, ok := api.(persist.AuditLog)
+	if !ok {
+		return
+	}
+
+	entry := persist.AuditEntry{
+		Machine:   machine,
+		Operation: operation,
+		Details:   details,
+	}
+	if opErr != nil {
+		entry.Error = opErr.Error()
+	}
+
+	if err := auditLog.RecordAudit(entry); err != nil && err != persist.ErrReadOnlyStore {
+		log.Warnf("Error recording %s of %q in the audit log: %s", operation, machine, err)
+	}
+}
+
+// QueryAudit returns the operations of the audit log of the store selected by
+// the filter, oldest first.
+func QueryAudit(api API, filter persist.AuditFilter) ([]persist.AuditEntry, error) {
+	auditLog, ok := api.(persist.AuditLog)
+	if !ok {
+		return []persist.AuditEntry{}, nil
+	}
+
+	return auditLog.QueryAudit(filter)
+}
@@ -1,5 +1,7 @@
 package auth
 
+import "time"
+
 type Options struct {
 	CertDir              string
 	CaCertPath           string
@@ -12,6 +14,29 @@ type Options struct {
 	ServerKeyRemotePath  string
 	ClientCertPath       string
 	ServerCertSANs       []string
+	// CaCertPEM and CaPrivateKeyPEM are an existing CA, written to
+	// CaCertPath and CaPrivateKeyPath instead of generating one. The key
+	// may be left out when the server and client certificates are issued
+	// externally.
+	CaCertPEM       []byte `json:"-"`
+	CaPrivateKeyPEM []byte `json:"-"`
+	// ServerCertSource and ServerKeySource are an externally issued server
+	// certificate, installed on the machine instead of a certificate
+	// generated with the key of the CA.
+	ServerCertSource string
+	ServerKeySource  string
+	// KeyType, KeyBits and KeyCurve are the keys of the generated
+	// certificates, see cert.Options. The CA gets them when it is first
+	// generated.
+	KeyType  string
+	KeyBits  int
+	KeyCurve string
+	// CertValidity is how long the generated certificates are valid, 1080
+	// days by default.
+	CertValidity time.Duration
+	// CertOrganization is the organization of the generated certificates,
+	// the name of the user by default.
+	CertOrganization string
 	// StorePath is left in for historical reasons, but not really meant to
 	// be used directly.
 	StorePath string
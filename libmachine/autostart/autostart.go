@@ -0,0 +1,111 @@
+// Package autostart registers machines with the service manager of the host
+// OS, so that docker-machine starts them when the user logs in.
+package autostart
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"text/template"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+var (
+	runCmd = func(cmd *exec.Cmd) error {
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %s: %s", cmd.Args[0], err, bytes.TrimSpace(output))
+		}
+		return nil
+	}
+
+	executable = os.Executable
+)
+
+// Enable registers the machine of the store to start automatically.
+func Enable(name, storePath string) error {
+	binary, err := executable()
+	if err != nil {
+		return fmt.Errorf("Error finding the docker-machine binary: %s", err)
+	}
+
+	return enable(service{
+		Name:      name,
+		Binary:    binary,
+		StorePath: storePath,
+	})
+}
+
+// Disable unregisters the machine of the store. It is not an error if the
+// machine was not registered.
+func Disable(name, storePath string) error {
+	return disable(service{
+		Name:      name,
+		StorePath: storePath,
+	})
+}
+
+// service is what the service manager needs to start a machine.
+type service struct {
+	Name      string
+	Binary    string
+	StorePath string
+}
+
+const systemdUnit = `[Unit]
+Description=Docker machine {{.Name}}
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart="{{.Binary}}" --storage-path "{{.StorePath}}" start {{.Name}}
+ExecStop="{{.Binary}}" --storage-path "{{.StorePath}}" stop {{.Name}}
+
+[Install]
+WantedBy=default.target
+`
+
+const launchdAgent = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+  <key>Label</key>
+  <string>{{label .Name .StorePath}}</string>
+  <key>ProgramArguments</key>
+  <array>
+    <string>{{.Binary}}</string>
+    <string>--storage-path</string>
+    <string>{{.StorePath}}</string>
+    <string>start</string>
+    <string>{{.Name}}</string>
+  </array>
+  <key>RunAtLoad</key>
+  <true/>
+</dict>
+</plist>
+`
+
+// unitName returns the name the machine of the store is registered under
+// with systemd or the task scheduler, followed by the ID of the store for the
+// machines of the same name in other stores not to replace it.
+func unitName(name, storePath string) string {
+	return "docker-machine-" + name + "-" + mcnutils.PathID(storePath)
+}
+
+// label returns the name the machine of the store is registered under with
+// launchd.
+func label(name, storePath string) string {
+	return "com.docker.machine." + name + "." + mcnutils.PathID(storePath)
+}
+
+func render(content string, s service) (string, error) {
+	tmpl, err := template.New("service").Funcs(template.FuncMap{"label": label}).Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, s)
+	return buf.String(), err
+}
@@ -0,0 +1,35 @@
+package autostart
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+// agentPath returns the path of the launchd agent of the machine, which
+// launchd runs when the user logs in.
+func agentPath(s service) string {
+	return filepath.Join(mcnutils.GetHomeDir(), "Library", "LaunchAgents", label(s.Name, s.StorePath)+".plist")
+}
+
+func enable(s service) error {
+	content, err := render(launchdAgent, s)
+	if err != nil {
+		return err
+	}
+
+	path := agentPath(s)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
+
+func disable(s service) error {
+	if err := os.Remove(agentPath(s)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package autostart
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+// unitPath returns the path of the systemd user unit of the machine. User
+// units start when the user logs in, or at boot once lingering is enabled
+// with `loginctl enable-linger`.
+func unitPath(s service) string {
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		configDir = filepath.Join(mcnutils.GetHomeDir(), ".config")
+	}
+
+	return filepath.Join(configDir, "systemd", "user", unitName(s.Name, s.StorePath)+".service")
+}
+
+func enable(s service) error {
+	content, err := render(systemdUnit, s)
+	if err != nil {
+		return err
+	}
+
+	path := unitPath(s)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	if err := runCmd(exec.Command("systemctl", "--user", "daemon-reload")); err != nil {
+		return err
+	}
+	return runCmd(exec.Command("systemctl", "--user", "enable", unitName(s.Name, s.StorePath)+".service"))
+}
+
+func disable(s service) error {
+	path := unitPath(s)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	if err := runCmd(exec.Command("systemctl", "--user", "disable", unitName(s.Name, s.StorePath)+".service")); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return runCmd(exec.Command("systemctl", "--user", "daemon-reload"))
+}
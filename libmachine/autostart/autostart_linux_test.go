@@ -0,0 +1,56 @@
+package autostart
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableDisable(t *testing.T) {
+	configDir, err := ioutil.TempDir("", "autostart")
+	assert.NoError(t, err)
+	defer os.RemoveAll(configDir)
+
+	defer func(value string) { os.Setenv("XDG_CONFIG_HOME", value) }(os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", configDir)
+
+	defer func(f func(*exec.Cmd) error) { runCmd = f }(runCmd)
+	commands := []string{}
+	runCmd = func(cmd *exec.Cmd) error {
+		commands = append(commands, strings.Join(cmd.Args, " "))
+		return nil
+	}
+
+	defer func(f func() (string, error)) { executable = f }(executable)
+	executable = func() (string, error) { return "/usr/local/bin/docker-machine", nil }
+
+	service := "docker-machine-dev-" + mcnutils.PathID("/store") + ".service"
+	unit := filepath.Join(configDir, "systemd", "user", service)
+
+	assert.NoError(t, Enable("dev", "/store"))
+	assert.Equal(t, []string{
+		"systemctl --user daemon-reload",
+		"systemctl --user enable " + service,
+	}, commands)
+	_, err = os.Stat(unit)
+	assert.NoError(t, err)
+
+	commands = []string{}
+	assert.NoError(t, Disable("dev", "/store"))
+	assert.Equal(t, []string{
+		"systemctl --user disable " + service,
+		"systemctl --user daemon-reload",
+	}, commands)
+	_, err = os.Stat(unit)
+	assert.True(t, os.IsNotExist(err))
+
+	commands = []string{}
+	assert.NoError(t, Disable("dev", "/store"))
+	assert.Empty(t, commands)
+}
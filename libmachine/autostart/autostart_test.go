@@ -0,0 +1,41 @@
+package autostart
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/stretchr/testify/assert"
+)
+
+var testService = service{
+	Name:      "dev",
+	Binary:    "/usr/local/bin/docker-machine",
+	StorePath: "/home/user/.docker/machine",
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	content, err := render(systemdUnit, testService)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, `ExecStart="/usr/local/bin/docker-machine" --storage-path "/home/user/.docker/machine" start dev`)
+	assert.Contains(t, content, `ExecStop="/usr/local/bin/docker-machine" --storage-path "/home/user/.docker/machine" stop dev`)
+	assert.Contains(t, content, "WantedBy=default.target")
+}
+
+func TestRenderLaunchdAgent(t *testing.T) {
+	content, err := render(launchdAgent, testService)
+
+	assert.NoError(t, err)
+	assert.Contains(t, content, "<string>com.docker.machine.dev."+mcnutils.PathID(testService.StorePath)+"</string>")
+	assert.Contains(t, content, `<string>/usr/local/bin/docker-machine</string>
+    <string>--storage-path</string>
+    <string>/home/user/.docker/machine</string>
+    <string>start</string>
+    <string>dev</string>`)
+}
+
+func TestUnitNamesPerStore(t *testing.T) {
+	assert.Equal(t, unitName("dev", "/store"), unitName("dev", "/store/"))
+	assert.NotEqual(t, unitName("dev", "/store"), unitName("dev", "/other"))
+	assert.NotEqual(t, label("dev", "/store"), label("dev", "/other"))
+}
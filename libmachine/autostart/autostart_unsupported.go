@@ -0,0 +1,15 @@
+// +build !linux,!darwin,!windows
+
+package autostart
+
+import "errors"
+
+var errNotSupported = errors.New("Starting machines automatically is not supported on this OS")
+
+func enable(s service) error {
+	return errNotSupported
+}
+
+func disable(s service) error {
+	return nil
+}
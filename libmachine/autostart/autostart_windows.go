@@ -0,0 +1,23 @@
+package autostart
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// The machine is registered as a task of the task scheduler running when the
+// user logs on.
+func enable(s service) error {
+	command := fmt.Sprintf(`"%s" --storage-path "%s" start %s`, s.Binary, s.StorePath, s.Name)
+
+	return runCmd(exec.Command("schtasks", "/Create", "/F", "/SC", "ONLOGON", "/TN", unitName(s.Name, s.StorePath), "/TR", command))
+}
+
+func disable(s service) error {
+	if err := runCmd(exec.Command("schtasks", "/Query", "/TN", unitName(s.Name, s.StorePath))); err != nil {
+		// The task does not exist
+		return nil
+	}
+
+	return runCmd(exec.Command("schtasks", "/Delete", "/F", "/TN", unitName(s.Name, s.StorePath)))
+}
@@ -0,0 +1,191 @@
+package libmachine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+const (
+	defaultBatchParallelism = 5
+)
+
+var (
+	ErrInvalidBatchCount = errors.New("The number of machines to create must be at least 1")
+)
+
+// HostTemplate describes the machines created by CreateBatch.
+type HostTemplate struct {
+	DriverName string
+
+	// DriverOptions holds the values of the driver create flags, keyed by
+	// flag name, e.g. "virtualbox-memory". Flags which are not set take
+	// the driver defaults.
+	DriverOptions map[string]interface{}
+
+	// EngineOptions and SwarmOptions default to the NewHost defaults when
+	// nil. Each machine gets its own copy.
+	EngineOptions *engine.Options
+	SwarmOptions  *swarm.Options
+
+	// Parallelism caps the number of machines created at the same time.
+	// It defaults to 5.
+	Parallelism int
+}
+
+// BatchResult is the outcome of the creation of one machine of a batch.
+type BatchResult struct {
+	Name string
+	Host *host.Host
+	Err  error
+}
+
+// CreateBatch creates count machines named baseName-1 to baseName-<count>
+// from the template, running the creations concurrently. A failure to
+// create one machine does not stop the others: the results, in name order,
+// tell which machines were created.
+func (api *Client) CreateBatch(template *HostTemplate, baseName string, count int) ([]BatchResult, error) {
+	if count < 1 {
+		return nil, ErrInvalidBatchCount
+	}
+
+	parallelism := template.Parallelism
+	if parallelism < 1 {
+		parallelism = defaultBatchParallelism
+	}
+
+	// The machines share the CA and client certificates, which are created
+	// once here rather than concurrently by each creation.
+	if !api.ReadOnly {
+		if err := cert.BootstrapCertificates(&auth.Options{
+			CertDir:          api.certsDir,
+			CaCertPath:       filepath.Join(api.certsDir, "ca.pem"),
+			CaPrivateKeyPath: filepath.Join(api.certsDir, "ca-key.pem"),
+			ClientCertPath:   filepath.Join(api.certsDir, "cert.pem"),
+			ClientKeyPath:    filepath.Join(api.certsDir, "key.pem"),
+		}); err != nil {
+			return nil, fmt.Errorf("Error generating certificates: %s", err)
+		}
+	}
+
+	names := batchNames(baseName, count)
+	results := make([]BatchResult, len(names))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallelism)
+
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			h, err := api.createFromTemplate(template, name)
+			if err != nil {
+				log.Errorf("Error creating machine %q: %s", name, err)
+			}
+
+			results[i] = BatchResult{
+				Name: name,
+				Host: h,
+				Err:  err,
+			}
+		}(i, name)
+	}
+
+	wg.Wait()
+
+	return results, nil
+}
+
+func (api *Client) createFromTemplate(template *HostTemplate, name string) (*host.Host, error) {
+	if !host.ValidateHostName(name) {
+		return nil, mcnerror.ErrInvalidHostname
+	}
+
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, fmt.Errorf("Error checking if host exists: %s", err)
+	}
+	if exists {
+		return nil, mcnerror.ErrHostAlreadyExists{
+			Name: name,
+		}
+	}
+
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: name,
+		StorePath:   filepath.Dir(api.GetMachinesDir()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+	}
+
+	h, err := api.NewHost(template.DriverName, rawDriver)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting new host: %s", err)
+	}
+
+	if template.DriverName == "virtualbox" {
+		h.Driver = drivers.NewSerialDriver(h.Driver)
+	}
+
+	if template.EngineOptions != nil {
+		h.HostOptions.EngineOptions = &engine.Options{}
+		if err := copyOptions(template.EngineOptions, h.HostOptions.EngineOptions); err != nil {
+			return nil, err
+		}
+	}
+	if template.SwarmOptions != nil {
+		h.HostOptions.SwarmOptions = &swarm.Options{}
+		if err := copyOptions(template.SwarmOptions, h.HostOptions.SwarmOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	driverOpts := DriverOptionsFromValues(h.Driver.GetCreateFlags(), template.DriverOptions, h.HostOptions.SwarmOptions)
+	if err := h.Driver.SetConfigFromFlags(driverOpts); err != nil {
+		return nil, fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
+	}
+
+	if err := api.Create(h); err != nil {
+		return nil, err
+	}
+
+	if err := api.Save(h); err != nil {
+		return nil, fmt.Errorf("Error attempting to save store: %s", err)
+	}
+
+	return h, nil
+}
+
+func batchNames(baseName string, count int) []string {
+	names := []string{}
+	for i := 1; i <= count; i++ {
+		names = append(names, fmt.Sprintf("%s-%d", baseName, i))
+	}
+	return names
+}
+
+// copyOptions deep copies engine or swarm options so that the provisioning
+// of one machine cannot alter the options of the others.
+func copyOptions(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("Error copying options: %s", err)
+	}
+	return json.Unmarshal(data, dst)
+}
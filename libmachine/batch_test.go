@@ -0,0 +1,36 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchNames(t *testing.T) {
+	assert.Equal(t, []string{"worker-1", "worker-2", "worker-3"}, batchNames("worker", 3))
+}
+
+func TestCreateBatchInvalidCount(t *testing.T) {
+	api := NewClient("", "")
+
+	results, err := api.CreateBatch(&HostTemplate{DriverName: "none"}, "worker", 0)
+
+	assert.Nil(t, results)
+	assert.Equal(t, ErrInvalidBatchCount, err)
+}
+
+func TestCopyOptions(t *testing.T) {
+	src := &engine.Options{
+		Labels:        []string{"role=worker"},
+		StorageDriver: "overlay",
+	}
+
+	dst := &engine.Options{}
+	assert.NoError(t, copyOptions(src, dst))
+
+	assert.Equal(t, src, dst)
+
+	dst.Labels[0] = "role=manager"
+	assert.Equal(t, "role=worker", src.Labels[0])
+}
@@ -20,11 +20,12 @@ func BootstrapCertificates(authOptions *auth.Options) error {
 	// TODO: I'm not super happy about this use of "org", the user should
 	// have to specify it explicitly instead of implicitly basing it on
 	// $USER.
-	caOrg := mcnutils.GetUsername()
+	caOrg := authOptions.CertOrganization
+	if caOrg == "" {
+		caOrg = mcnutils.GetUsername()
+	}
 	org := caOrg + ".<bootstrap>"
 
-	bits := 2048
-
 	if _, err := os.Stat(certDir); err != nil {
 		if os.IsNotExist(err) {
 			if err := os.MkdirAll(certDir, 0700); err != nil {
@@ -35,6 +36,13 @@ func BootstrapCertificates(authOptions *auth.Options) error {
 		}
 	}
 
+	if err := writeExisting(caCertPath, authOptions.CaCertPEM, 0644); err != nil {
+		return fmt.Errorf("Writing the CA certificate failed: %s", err)
+	}
+	if err := writeExisting(caPrivateKeyPath, authOptions.CaPrivateKeyPEM, 0600); err != nil {
+		return fmt.Errorf("Writing the CA key failed: %s", err)
+	}
+
 	if _, err := os.Stat(caCertPath); os.IsNotExist(err) {
 		log.Infof("Creating CA: %s", caCertPath)
 
@@ -43,7 +51,16 @@ func BootstrapCertificates(authOptions *auth.Options) error {
 			return errors.New("certificate authority key already exists")
 		}
 
-		if err := GenerateCACertificate(caCertPath, caPrivateKeyPath, caOrg, bits); err != nil {
+		err := GenerateCA(&Options{
+			CertFile: caCertPath,
+			KeyFile:  caPrivateKeyPath,
+			Org:      caOrg,
+			Bits:     authOptions.KeyBits,
+			KeyType:  authOptions.KeyType,
+			Curve:    authOptions.KeyCurve,
+			Validity: authOptions.CertValidity,
+		})
+		if err != nil {
 			return fmt.Errorf("Generating CA certificate failed: %s", err)
 		}
 	}
@@ -66,6 +83,10 @@ func BootstrapCertificates(authOptions *auth.Options) error {
 			return errors.New("client key already exists")
 		}
 
+		if _, err := os.Stat(caPrivateKeyPath); os.IsNotExist(err) {
+			return fmt.Errorf("The client certificate %s cannot be generated without the key of the CA, give an externally issued client certificate", clientCertPath)
+		}
+
 		// Used to generate the client certificate.
 		certOptions := &Options{
 			Hosts:       []string{""},
@@ -74,8 +95,11 @@ func BootstrapCertificates(authOptions *auth.Options) error {
 			CAFile:      caCertPath,
 			CAKeyFile:   caPrivateKeyPath,
 			Org:         org,
-			Bits:        bits,
+			Bits:        authOptions.KeyBits,
 			SwarmMaster: false,
+			KeyType:     authOptions.KeyType,
+			Curve:       authOptions.KeyCurve,
+			Validity:    authOptions.CertValidity,
 		}
 
 		if err := GenerateCert(certOptions); err != nil {
@@ -1,16 +1,21 @@
 package cert
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"net"
 	"os"
+	"strings"
 	"time"
 
 	"errors"
@@ -21,15 +26,30 @@ import (
 
 var defaultGenerator = NewX509CertGenerator()
 
+const (
+	defaultBits     = 2048
+	defaultValidity = 24 * 1080 * time.Hour
+)
+
 type Options struct {
 	Hosts                                     []string
 	CertFile, KeyFile, CAFile, CAKeyFile, Org string
 	Bits                                      int
 	SwarmMaster                               bool
+	// ClientAuth has the server certificate authenticate clients too, e.g.
+	// the docker client on a machine whose engine has no unix socket
+	ClientAuth bool
+	// KeyType is rsa, the default, or ecdsa
+	KeyType string
+	// Curve is the ECDSA curve, P256 by default
+	Curve string
+	// Validity defaults to 1080 days
+	Validity time.Duration
 }
 
 type Generator interface {
 	GenerateCACertificate(certFile, keyFile, org string, bits int) error
+	GenerateCA(opts *Options) error
 	GenerateCert(opts *Options) error
 	ReadTLSConfig(addr string, authOptions *auth.Options) (*tls.Config, error)
 	ValidateCertificate(addr string, authOptions *auth.Options) (bool, error)
@@ -45,6 +65,12 @@ func GenerateCACertificate(certFile, keyFile, org string, bits int) error {
 	return defaultGenerator.GenerateCACertificate(certFile, keyFile, org, bits)
 }
 
+// GenerateCA generates a new certificate authority into opts.CertFile and
+// opts.KeyFile, with the organization, key and validity of the options.
+func GenerateCA(opts *Options) error {
+	return defaultGenerator.GenerateCA(opts)
+}
+
 func GenerateCert(opts *Options) error {
 	return defaultGenerator.GenerateCert(opts)
 }
@@ -82,12 +108,16 @@ func (xcg *X509CertGenerator) getTLSConfig(caCert, cert, key []byte, allowInsecu
 	return &tlsConfig, nil
 }
 
-func (xcg *X509CertGenerator) newCertificate(org string) (*x509.Certificate, error) {
+func (xcg *X509CertGenerator) newCertificate(org string, validity time.Duration) (*x509.Certificate, error) {
+	if validity <= 0 {
+		validity = defaultValidity
+	}
+
 	now := time.Now()
 	// need to set notBefore slightly in the past to account for time
 	// skew in the VMs otherwise the certs sometimes are not yet valid
 	notBefore := time.Date(now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute()-5, 0, 0, time.Local)
-	notAfter := notBefore.Add(time.Hour * 24 * 1080)
+	notAfter := notBefore.Add(validity)
 
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
@@ -113,7 +143,19 @@ func (xcg *X509CertGenerator) newCertificate(org string) (*x509.Certificate, err
 // and bit size and stores the resulting certificate and key file
 // in the arguments.
 func (xcg *X509CertGenerator) GenerateCACertificate(certFile, keyFile, org string, bits int) error {
-	template, err := xcg.newCertificate(org)
+	return xcg.GenerateCA(&Options{
+		CertFile: certFile,
+		KeyFile:  keyFile,
+		Org:      org,
+		Bits:     bits,
+	})
+}
+
+// GenerateCA generates a new certificate authority with the organization,
+// key type and size and validity of the options, and stores the resulting
+// certificate and key in opts.CertFile and opts.KeyFile.
+func (xcg *X509CertGenerator) GenerateCA(opts *Options) error {
+	template, err := xcg.newCertificate(opts.Org, opts.Validity)
 	if err != nil {
 		return err
 	}
@@ -123,34 +165,20 @@ func (xcg *X509CertGenerator) GenerateCACertificate(certFile, keyFile, org strin
 	template.KeyUsage |= x509.KeyUsageKeyEncipherment
 	template.KeyUsage |= x509.KeyUsageKeyAgreement
 
-	priv, err := rsa.GenerateKey(rand.Reader, bits)
-	if err != nil {
-		return err
-	}
-
-	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	priv, keyBlock, err := generateKey(opts)
 	if err != nil {
 		return err
 	}
-
-	certOut, err := os.Create(certFile)
-	if err != nil {
-		return err
+	if opts.KeyType == "ecdsa" {
+		template.KeyUsage &^= x509.KeyUsageKeyEncipherment
 	}
 
-	pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
-	certOut.Close()
-
-	keyOut, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, template, priv.Public(), priv)
 	if err != nil {
 		return err
-
 	}
 
-	pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
-	keyOut.Close()
-
-	return nil
+	return writeCertAndKey(opts.CertFile, opts.KeyFile, derBytes, keyBlock)
 }
 
 // GenerateCert generates a new certificate signed using the provided
@@ -158,7 +186,7 @@ func (xcg *X509CertGenerator) GenerateCACertificate(certFile, keyFile, org strin
 // file and key provided.  The provided host names are set to the
 // appropriate certificate fields.
 func (xcg *X509CertGenerator) GenerateCert(opts *Options) error {
-	template, err := xcg.newCertificate(opts.Org)
+	template, err := xcg.newCertificate(opts.Org, opts.Validity)
 	if err != nil {
 		return err
 	}
@@ -168,13 +196,15 @@ func (xcg *X509CertGenerator) GenerateCert(opts *Options) error {
 		template.KeyUsage = x509.KeyUsageDigitalSignature
 	} else { // server
 		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
-		if opts.SwarmMaster {
+		if opts.SwarmMaster || opts.ClientAuth {
 			// Extend the Swarm master's server certificate
 			// permissions to also be able to connect to downstream
 			// nodes as a client.
 			template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
 		}
 		for _, h := range opts.Hosts {
+			// IPv6 addresses may come bracketed, as in URLs
+			h = strings.TrimSuffix(strings.TrimPrefix(h, "["), "]")
 			if ip := net.ParseIP(h); ip != nil {
 				template.IPAddresses = append(template.IPAddresses, ip)
 			} else {
@@ -188,22 +218,86 @@ func (xcg *X509CertGenerator) GenerateCert(opts *Options) error {
 		return err
 	}
 
-	priv, err := rsa.GenerateKey(rand.Reader, opts.Bits)
+	priv, keyBlock, err := generateKey(opts)
 	if err != nil {
 		return err
 	}
+	if opts.KeyType == "ecdsa" {
+		template.KeyUsage &^= x509.KeyUsageKeyEncipherment
+	}
 
 	x509Cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
 	if err != nil {
 		return err
 	}
 
-	derBytes, err := x509.CreateCertificate(rand.Reader, template, x509Cert, &priv.PublicKey, tlsCert.PrivateKey)
+	derBytes, err := x509.CreateCertificate(rand.Reader, template, x509Cert, priv.Public(), tlsCert.PrivateKey)
 	if err != nil {
 		return err
 	}
 
-	certOut, err := os.Create(opts.CertFile)
+	return writeCertAndKey(opts.CertFile, opts.KeyFile, derBytes, keyBlock)
+}
+
+// ValidateKeyOptions checks the type, size and curve of the keys to
+// generate.
+func ValidateKeyOptions(keyType string, bits int, curve string) error {
+	switch keyType {
+	case "", "rsa":
+		if bits != 0 && bits < 2048 {
+			return fmt.Errorf("RSA keys must have at least 2048 bits, not %d", bits)
+		}
+	case "ecdsa":
+		if _, ok := curves[curve]; !ok {
+			return fmt.Errorf("Unsupported ECDSA curve %q, must be one of P256, P384 or P521", curve)
+		}
+	default:
+		return fmt.Errorf("Unsupported key type %q, must be rsa or ecdsa", keyType)
+	}
+
+	return nil
+}
+
+// generateKey generates the private key of a certificate and returns it
+// along with its PEM block.
+func generateKey(opts *Options) (crypto.Signer, *pem.Block, error) {
+	if err := ValidateKeyOptions(opts.KeyType, opts.Bits, opts.Curve); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.KeyType == "ecdsa" {
+		priv, err := ecdsa.GenerateKey(curves[opts.Curve], rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			return nil, nil, err
+		}
+		return priv, &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+	}
+
+	bits := opts.Bits
+	if bits == 0 {
+		bits = defaultBits
+	}
+
+	priv, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, nil, err
+	}
+	return priv, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}, nil
+}
+
+var curves = map[string]elliptic.Curve{
+	"":     elliptic.P256(),
+	"P256": elliptic.P256(),
+	"P384": elliptic.P384(),
+	"P521": elliptic.P521(),
+}
+
+func writeCertAndKey(certFile, keyFile string, derBytes []byte, keyBlock *pem.Block) error {
+	certOut, err := os.Create(certFile)
 	if err != nil {
 		return err
 	}
@@ -211,12 +305,12 @@ func (xcg *X509CertGenerator) GenerateCert(opts *Options) error {
 	pem.Encode(certOut, &pem.Block{Type: "CERTIFICATE", Bytes: derBytes})
 	certOut.Close()
 
-	keyOut, err := os.OpenFile(opts.KeyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	keyOut, err := os.OpenFile(keyFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
 
-	pem.Encode(keyOut, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)})
+	pem.Encode(keyOut, keyBlock)
 	keyOut.Close()
 
 	return nil
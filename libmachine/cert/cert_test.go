@@ -1,10 +1,15 @@
 package cert
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGenerateCACertificate(t *testing.T) {
@@ -79,3 +84,147 @@ func TestGenerateCert(t *testing.T) {
 		t.Fatalf("key not created at %s", keyPath)
 	}
 }
+
+func TestGenerateCertIPv6SANs(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath := filepath.Join(tmpDir, "ca.pem")
+	caKeyPath := filepath.Join(tmpDir, "key.pem")
+	certPath := filepath.Join(tmpDir, "cert.pem")
+	keyPath := filepath.Join(tmpDir, "cert-key.pem")
+	if err := GenerateCACertificate(caCertPath, caKeyPath, "test-org", 2048); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &Options{
+		Hosts:     []string{"2001:db8::1", "[2001:db8::2]", "localhost"},
+		CertFile:  certPath,
+		CAKeyFile: caKeyPath,
+		CAFile:    caCertPath,
+		KeyFile:   keyPath,
+		Org:       "test-org",
+		Bits:      2048,
+	}
+
+	if err := GenerateCert(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	pemBytes, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(pemBytes)
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(certificate.IPAddresses) != 2 {
+		t.Fatalf("Expected 2 IP SANs, got %v", certificate.IPAddresses)
+	}
+	if !certificate.IPAddresses[1].Equal(net.ParseIP("2001:db8::2")) {
+		t.Fatalf("Expected the bracketed address as IP SAN, got %s", certificate.IPAddresses[1])
+	}
+	if len(certificate.DNSNames) != 1 || certificate.DNSNames[0] != "localhost" {
+		t.Fatalf("Expected localhost as only DNS SAN, got %v", certificate.DNSNames)
+	}
+}
+
+func TestGenerateECDSACertificates(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath := filepath.Join(tmpDir, "ca.pem")
+	caKeyPath := filepath.Join(tmpDir, "ca-key.pem")
+	certPath := filepath.Join(tmpDir, "cert.pem")
+	keyPath := filepath.Join(tmpDir, "cert-key.pem")
+
+	err = GenerateCA(&Options{
+		CertFile: caCertPath,
+		KeyFile:  caKeyPath,
+		Org:      "corp",
+		KeyType:  "ecdsa",
+		Curve:    "P384",
+		Validity: 30 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = GenerateCert(&Options{
+		Hosts:     []string{"lb.example.com", "10.0.0.2"},
+		CertFile:  certPath,
+		KeyFile:   keyPath,
+		CAFile:    caCertPath,
+		CAKeyFile: caKeyPath,
+		Org:       "corp.machine",
+		KeyType:   "ecdsa",
+		Validity:  30 * 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if serverCert.PublicKeyAlgorithm != x509.ECDSA {
+		t.Fatalf("Expected an ECDSA key, got %s", serverCert.PublicKeyAlgorithm)
+	}
+	if validity := serverCert.NotAfter.Sub(serverCert.NotBefore); validity != 30*24*time.Hour {
+		t.Fatalf("Expected the certificate to be valid 30 days, got %s", validity)
+	}
+	if len(serverCert.Subject.Organization) != 1 || serverCert.Subject.Organization[0] != "corp.machine" {
+		t.Fatalf("Unexpected organization %v", serverCert.Subject.Organization)
+	}
+	if len(serverCert.DNSNames) != 1 || serverCert.DNSNames[0] != "lb.example.com" {
+		t.Fatalf("Unexpected DNS names %v", serverCert.DNSNames)
+	}
+}
+
+func TestValidateKeyOptions(t *testing.T) {
+	valid := []struct {
+		keyType string
+		bits    int
+		curve   string
+	}{
+		{"", 0, ""},
+		{"rsa", 4096, ""},
+		{"ecdsa", 0, "P521"},
+	}
+	for _, options := range valid {
+		if err := ValidateKeyOptions(options.keyType, options.bits, options.curve); err != nil {
+			t.Fatalf("Expected %v to be valid, got %s", options, err)
+		}
+	}
+
+	invalid := []struct {
+		keyType string
+		bits    int
+		curve   string
+	}{
+		{"rsa", 1024, ""},
+		{"ecdsa", 0, "P224"},
+		{"dsa", 0, ""},
+	}
+	for _, options := range invalid {
+		if err := ValidateKeyOptions(options.keyType, options.bits, options.curve); err == nil {
+			t.Fatalf("Expected %v to be invalid", options)
+		}
+	}
+}
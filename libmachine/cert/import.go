@@ -0,0 +1,104 @@
+package cert
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// ImportCert installs an externally issued certificate and its key as
+// certFile and keyFile, after checking that they form a key pair and that
+// the certificate is issued by the CA of caFile.
+func ImportCert(srcCertFile, srcKeyFile, caFile, certFile, keyFile string) error {
+	certPEM, err := ioutil.ReadFile(srcCertFile)
+	if err != nil {
+		return err
+	}
+
+	keyPEM, err := ioutil.ReadFile(srcKeyFile)
+	if err != nil {
+		return err
+	}
+
+	caPEM, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Errorf("%s and %s are not a certificate and its key: %s", srcCertFile, srcKeyFile, err)
+	}
+
+	if err := CheckIssuedBy(certPEM, caPEM); err != nil {
+		return fmt.Errorf("%s: %s", srcCertFile, err)
+	}
+
+	if err := ioutil.WriteFile(certFile, certPEM, 0644); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(keyFile, keyPEM, 0600)
+}
+
+// CheckIssuedBy checks that the PEM encoded certificate is issued by one of
+// the PEM encoded CA certificates, possibly through the intermediate
+// certificates following it.
+func CheckIssuedBy(certPEM, caPEM []byte) error {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		return errors.New("no CA certificate found")
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(certPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return errors.New("no certificate found")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate not issued by the CA: %s", err)
+	}
+
+	return nil
+}
+
+// writeExisting writes the PEM data given instead of a file to generate,
+// unless the file exists already.
+func writeExisting(path string, data []byte, perm os.FileMode) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if block, _ := pem.Decode(data); block == nil {
+		return fmt.Errorf("no PEM data to write to %s", path)
+	}
+
+	return ioutil.WriteFile(path, data, perm)
+}
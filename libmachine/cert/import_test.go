@@ -0,0 +1,101 @@
+package cert
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateTestCert(t *testing.T, dir, name string) (string, string, string) {
+	caCertPath := filepath.Join(dir, name+"-ca.pem")
+	caKeyPath := filepath.Join(dir, name+"-ca-key.pem")
+	certPath := filepath.Join(dir, name+".pem")
+	keyPath := filepath.Join(dir, name+"-key.pem")
+
+	if err := GenerateCACertificate(caCertPath, caKeyPath, "test-org", 2048); err != nil {
+		t.Fatal(err)
+	}
+
+	err := GenerateCert(&Options{
+		Hosts:     []string{"localhost"},
+		CertFile:  certPath,
+		KeyFile:   keyPath,
+		CAFile:    caCertPath,
+		CAKeyFile: caKeyPath,
+		Org:       "test-org",
+		Bits:      2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return caCertPath, certPath, keyPath
+}
+
+func TestImportCert(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	caCertPath, certPath, keyPath := generateTestCert(t, tmpDir, "corp")
+
+	serverCertPath := filepath.Join(tmpDir, "server.pem")
+	serverKeyPath := filepath.Join(tmpDir, "server-key.pem")
+
+	assert.NoError(t, ImportCert(certPath, keyPath, caCertPath, serverCertPath, serverKeyPath))
+
+	imported, _ := ioutil.ReadFile(serverCertPath)
+	original, _ := ioutil.ReadFile(certPath)
+	assert.Equal(t, original, imported)
+}
+
+func TestImportCertOtherCA(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	_, certPath, keyPath := generateTestCert(t, tmpDir, "corp")
+	otherCACertPath, _, _ := generateTestCert(t, tmpDir, "other")
+
+	err = ImportCert(certPath, keyPath, otherCACertPath, filepath.Join(tmpDir, "server.pem"), filepath.Join(tmpDir, "server-key.pem"))
+
+	assert.Error(t, err)
+	_, statErr := os.Stat(filepath.Join(tmpDir, "server.pem"))
+	assert.True(t, os.IsNotExist(statErr))
+}
+
+func TestBootstrapCertificatesWithExistingCA(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	corpCACertPath, _, _ := generateTestCert(t, tmpDir, "corp")
+	caPEM, _ := ioutil.ReadFile(corpCACertPath)
+
+	certDir := filepath.Join(tmpDir, "certs")
+	authOptions := &auth.Options{
+		CertDir:          certDir,
+		CaCertPath:       filepath.Join(certDir, "ca.pem"),
+		CaPrivateKeyPath: filepath.Join(certDir, "ca-key.pem"),
+		ClientCertPath:   filepath.Join(certDir, "cert.pem"),
+		ClientKeyPath:    filepath.Join(certDir, "key.pem"),
+		CaCertPEM:        caPEM,
+	}
+
+	err = BootstrapCertificates(authOptions)
+
+	// Without the key of the CA, the client certificate cannot be generated
+	assert.Error(t, err)
+	written, _ := ioutil.ReadFile(authOptions.CaCertPath)
+	assert.Equal(t, caPEM, written)
+}
@@ -24,6 +24,10 @@ func (fcg FakeCertGenerator) GenerateCACertificate(certFile, keyFile, org string
 	return nil
 }
 
+func (fcg FakeCertGenerator) GenerateCA(opts *cert.Options) error {
+	return nil
+}
+
 func (fcg FakeCertGenerator) GenerateCert(opts *cert.Options) error {
 	return nil
 }
@@ -0,0 +1,57 @@
+package libmachine
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// RepairClockSkews sets the clocks of the running local VMs of the store
+// which lag behind after the host slept, and returns the names of the
+// machines the clock of which was set. Errors with a machine are logged and
+// do not prevent repairing the other machines.
+func RepairClockSkews(api API) ([]string, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	repaired := []string{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		ok, err := h.RepairClockSkewIfRunning()
+		if err != nil {
+			log.Warnf("Error repairing the clock of machine %q: %s", name, err)
+			continue
+		}
+		if ok {
+			repaired = append(repaired, name)
+		}
+	}
+
+	return repaired, nil
+}
+
+// WatchClockSkews runs RepairClockSkews every interval until done is
+// closed, keeping the clocks right across the sleeps of the host.
+func WatchClockSkews(api API, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := RepairClockSkews(api); err != nil {
+			log.Warnf("Error repairing clock skews: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
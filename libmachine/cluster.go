@@ -0,0 +1,480 @@
+package libmachine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+const (
+	defaultClusterDiscoveryStore = "consul"
+)
+
+var (
+	ErrInvalidWorkerCount = errors.New("The number of workers of a cluster cannot be negative")
+	ErrClusterNotFound    = errors.New("Cluster not found")
+)
+
+// ClusterSpec describes a swarm cluster of one manager and Workers workers,
+// all created from the same template.
+type ClusterSpec struct {
+	// Name of the cluster. The machines are named <Name>-manager and
+	// <Name>-worker-1 to <Name>-worker-<Workers>.
+	Name string
+
+	Template HostTemplate
+	Workers  int
+
+	// Stagger delays the creation of each worker after the previous one,
+	// not to hit the rate limits of the provider.
+	Stagger time.Duration
+}
+
+// Cluster is a swarm manager and its workers.
+type Cluster struct {
+	Name    string
+	Manager *host.Host
+	Workers []*host.Host
+
+	api  *Client
+	spec *ClusterSpec
+}
+
+// ClusterStatus is the state of each machine of a cluster, keyed by machine
+// name. Machines the state of which could not be read are in Errors.
+type ClusterStatus struct {
+	Name   string
+	States map[string]state.State
+	Errors map[string]error
+}
+
+// Running returns the number of running machines.
+func (s *ClusterStatus) Running() int {
+	running := 0
+	for _, st := range s.States {
+		if st == state.Running {
+			running++
+		}
+	}
+	return running
+}
+
+// Healthy tells whether all the machines of the cluster run.
+func (s *ClusterStatus) Healthy() bool {
+	return len(s.Errors) == 0 && s.Running() == len(s.States)
+}
+
+func clusterManagerName(name string) string {
+	return name + "-manager"
+}
+
+func clusterWorkerName(name string, index int) string {
+	return fmt.Sprintf("%s-worker-%d", name, index)
+}
+
+// clusterWorkerIndex returns the index of a worker of the cluster from its
+// machine name, or 0 when the machine is not a worker of the cluster.
+func clusterWorkerIndex(cluster, name string) int {
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(cluster) + `-worker-([0-9]+)$`)
+	match := re.FindStringSubmatch(name)
+	if match == nil {
+		return 0
+	}
+	index, _ := strconv.Atoi(match[1])
+	return index
+}
+
+// CreateCluster creates the manager of the cluster, then its workers. When
+// the template has no swarm discovery, the manager runs a discovery store
+// the workers join the swarm with. The creation of a worker failing does not
+// stop the others: the cluster is returned along with an error listing the
+// failed workers.
+func (api *Client) CreateCluster(spec ClusterSpec) (*Cluster, error) {
+	if spec.Workers < 0 {
+		return nil, ErrInvalidWorkerCount
+	}
+
+	swarmOptions := &swarm.Options{}
+	if spec.Template.SwarmOptions != nil {
+		if err := copyOptions(spec.Template.SwarmOptions, swarmOptions); err != nil {
+			return nil, err
+		}
+	} else {
+		swarmOptions = defaultSwarmOptions()
+	}
+	swarmOptions.IsSwarm = true
+	swarmOptions.Agent = true
+
+	managerTemplate := spec.Template
+	managerOptions := *swarmOptions
+	managerOptions.Master = true
+	if managerOptions.Discovery == "" && managerOptions.DiscoveryStore == "" {
+		managerOptions.DiscoveryStore = defaultClusterDiscoveryStore
+	}
+	managerTemplate.SwarmOptions = &managerOptions
+
+	log.Infof("Creating the manager of cluster %q...", spec.Name)
+	manager, err := api.createFromTemplate(&managerTemplate, clusterManagerName(spec.Name))
+	if err != nil {
+		return nil, fmt.Errorf("Error creating the manager of cluster %q: %s", spec.Name, err)
+	}
+
+	if manager.HostOptions.SwarmOptions.Discovery == "" {
+		ip, err := manager.Driver.GetIP()
+		if err != nil {
+			return nil, err
+		}
+		discovery, err := swarm.KVStoreDiscovery(managerOptions.DiscoveryStore, ip)
+		if err != nil {
+			return nil, err
+		}
+		manager.HostOptions.SwarmOptions.Discovery = discovery.String()
+		if err := api.Save(manager); err != nil {
+			return nil, err
+		}
+	}
+
+	workerOptions := *swarmOptions
+	workerOptions.Master = false
+	workerOptions.DiscoveryStore = ""
+	workerOptions.Discovery = manager.HostOptions.SwarmOptions.Discovery
+
+	cluster := &Cluster{
+		Name:    spec.Name,
+		Manager: manager,
+		api:     api,
+		spec:    &spec,
+	}
+	cluster.spec.Template.SwarmOptions = &workerOptions
+
+	indexes := []int{}
+	for i := 1; i <= spec.Workers; i++ {
+		indexes = append(indexes, i)
+	}
+
+	return cluster, cluster.addWorkers(indexes, func(name string) (*host.Host, error) {
+		return api.createFromTemplate(&cluster.spec.Template, name)
+	})
+}
+
+// LoadCluster loads the machines of the cluster from the store.
+func (api *Client) LoadCluster(name string) (*Cluster, error) {
+	manager, err := api.Load(clusterManagerName(name))
+	if err != nil {
+		if _, ok := err.(mcnerror.ErrHostDoesNotExist); ok {
+			return nil, ErrClusterNotFound
+		}
+		return nil, err
+	}
+
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &Cluster{
+		Name:    name,
+		Manager: manager,
+		api:     api,
+	}
+
+	for _, machineName := range names {
+		if clusterWorkerIndex(name, machineName) == 0 {
+			continue
+		}
+
+		worker, err := api.Load(machineName)
+		if err != nil {
+			return nil, err
+		}
+		cluster.Workers = append(cluster.Workers, worker)
+	}
+	cluster.sortWorkers()
+
+	return cluster, nil
+}
+
+// Hosts returns the manager and the workers of the cluster.
+func (c *Cluster) Hosts() []*host.Host {
+	return append([]*host.Host{c.Manager}, c.Workers...)
+}
+
+func (c *Cluster) sortWorkers() {
+	sort.Sort(workersByIndex{c.Name, c.Workers})
+}
+
+type workersByIndex struct {
+	cluster string
+	workers []*host.Host
+}
+
+func (w workersByIndex) Len() int {
+	return len(w.workers)
+}
+
+func (w workersByIndex) Swap(i, j int) {
+	w.workers[i], w.workers[j] = w.workers[j], w.workers[i]
+}
+
+func (w workersByIndex) Less(i, j int) bool {
+	return clusterWorkerIndex(w.cluster, w.workers[i].Name) < clusterWorkerIndex(w.cluster, w.workers[j].Name)
+}
+
+// addWorkers creates the workers of the given indexes, the creation of each
+// starting Stagger after the previous one.
+func (c *Cluster) addWorkers(indexes []int, create func(name string) (*host.Host, error)) error {
+	var stagger time.Duration
+	if c.spec != nil {
+		stagger = c.spec.Stagger
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		failed []string
+	)
+
+	for i, index := range indexes {
+		wg.Add(1)
+		go func(delay time.Duration, name string) {
+			defer wg.Done()
+
+			time.Sleep(delay)
+
+			log.Infof("Creating worker %q...", name)
+			h, err := create(name)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if err != nil {
+				log.Errorf("Error creating worker %q: %s", name, err)
+				failed = append(failed, name)
+				return
+			}
+			c.Workers = append(c.Workers, h)
+		}(time.Duration(i)*stagger, clusterWorkerName(c.Name, index))
+	}
+
+	wg.Wait()
+	c.sortWorkers()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("Error creating the workers %s of cluster %q", strings.Join(failed, ", "), c.Name)
+	}
+
+	return nil
+}
+
+// Scale adds or removes workers for the cluster to have the given number of
+// workers. New workers are cloned from an existing worker, or created from
+// the spec of the cluster when it was created in this session. The workers
+// with the highest indexes are removed first.
+func (c *Cluster) Scale(workers int) error {
+	if workers < 0 {
+		return ErrInvalidWorkerCount
+	}
+
+	if workers < len(c.Workers) {
+		removed := c.Workers[workers:]
+		if err := checkRemovable(removed); err != nil {
+			return err
+		}
+		return c.removeHosts(removed)
+	}
+
+	if workers == len(c.Workers) {
+		return nil
+	}
+
+	var create func(name string) (*host.Host, error)
+	switch {
+	case len(c.Workers) > 0:
+		template := c.Workers[len(c.Workers)-1]
+		create = func(name string) (*host.Host, error) {
+			return c.api.Clone(template, name)
+		}
+	case c.spec != nil:
+		create = func(name string) (*host.Host, error) {
+			return c.api.createFromTemplate(&c.spec.Template, name)
+		}
+	default:
+		return fmt.Errorf("Cluster %q has no worker to create new workers from", c.Name)
+	}
+
+	next := 1
+	if len(c.Workers) > 0 {
+		next = clusterWorkerIndex(c.Name, c.Workers[len(c.Workers)-1].Name) + 1
+	}
+
+	indexes := []int{}
+	for i := len(c.Workers); i < workers; i++ {
+		indexes = append(indexes, next)
+		next++
+	}
+
+	return c.addWorkers(indexes, create)
+}
+
+// Start starts the manager, then the workers.
+func (c *Cluster) Start() error {
+	if err := c.runAction([]*host.Host{c.Manager}, "start"); err != nil {
+		return err
+	}
+	return c.runAction(c.Workers, "start")
+}
+
+// Stop stops the workers, then the manager.
+func (c *Cluster) Stop() error {
+	if err := c.runAction(c.Workers, "stop"); err != nil {
+		return err
+	}
+	return c.runAction([]*host.Host{c.Manager}, "stop")
+}
+
+// Remove removes all the machines of the cluster. It fails without removing
+// any when one of them is protected.
+func (c *Cluster) Remove() error {
+	hosts := c.Hosts()
+	if err := checkRemovable(hosts); err != nil {
+		return err
+	}
+	return c.removeHosts(hosts)
+}
+
+// Status returns the state of each machine of the cluster.
+func (c *Cluster) Status() *ClusterStatus {
+	status := &ClusterStatus{
+		Name:   c.Name,
+		States: map[string]state.State{},
+		Errors: map[string]error{},
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+
+	for _, h := range c.Hosts() {
+		wg.Add(1)
+		go func(h *host.Host) {
+			defer wg.Done()
+
+			st, err := h.Driver.GetState()
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			status.States[h.Name] = st
+			if err != nil {
+				status.Errors[h.Name] = err
+			}
+		}(h)
+	}
+
+	wg.Wait()
+
+	return status
+}
+
+// runAction runs the action, e.g. "start", on the hosts concurrently and
+// saves them. Hosts already in the state the action leads to are not errors.
+func (c *Cluster) runAction(hosts []*host.Host, action string) error {
+	return c.forEach(hosts, func(h *host.Host) error {
+		err := RunAction(c.api, h, action)
+		if _, ok := err.(mcnerror.ErrHostAlreadyInState); ok {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return c.api.Save(h)
+	})
+}
+
+// checkRemovable fails when one of the machines is protected.
+func checkRemovable(hosts []*host.Host) error {
+	for _, h := range hosts {
+		if err := h.CheckRemovable(false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeHosts removes the hosts concurrently. The workers are only dropped
+// from the cluster once removed, for those which could not be removed to
+// still be tracked.
+func (c *Cluster) removeHosts(hosts []*host.Host) error {
+	var (
+		mutex   sync.Mutex
+		removed = map[string]bool{}
+	)
+
+	err := c.forEach(hosts, func(h *host.Host) error {
+		if err := removeInstance(c.api, h, false); err != nil {
+			return err
+		}
+		if err := RemoveMachine(c.api, h.Name); err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		removed[h.Name] = true
+		mutex.Unlock()
+
+		return nil
+	})
+
+	workers := []*host.Host{}
+	for _, h := range c.Workers {
+		if !removed[h.Name] {
+			workers = append(workers, h)
+		}
+	}
+	c.Workers = workers
+
+	return err
+}
+
+func (c *Cluster) forEach(hosts []*host.Host, f func(*host.Host) error) error {
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		failed []string
+	)
+
+	for _, h := range hosts {
+		wg.Add(1)
+		go func(h *host.Host) {
+			defer wg.Done()
+
+			if err := f(h); err != nil {
+				mutex.Lock()
+				failed = append(failed, fmt.Sprintf("%s: %s", h.Name, err))
+				mutex.Unlock()
+			}
+		}(h)
+	}
+
+	wg.Wait()
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("Error with cluster %q:\n%s", c.Name, strings.Join(failed, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package libmachine
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCluster(t *testing.T, workers int) (*Cluster, func()) {
+	storePath, err := ioutil.TempDir("", "machine-cluster")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	api := NewClient(storePath, storePath)
+
+	newHost := func(name string) *host.Host {
+		h := &host.Host{
+			Name:        name,
+			DriverName:  "fakedriver",
+			Driver:      &fakedriver.Driver{BaseDriver: &drivers.BaseDriver{MachineName: name}, MockState: state.Running},
+			HostOptions: &host.Options{},
+		}
+		if err := api.Save(h); err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+
+	cluster := &Cluster{
+		Name:    "test",
+		Manager: newHost(clusterManagerName("test")),
+		api:     api,
+	}
+	for i := 1; i <= workers; i++ {
+		cluster.Workers = append(cluster.Workers, newHost(clusterWorkerName("test", i)))
+	}
+
+	return cluster, func() { os.RemoveAll(storePath) }
+}
+
+func TestClusterWorkerIndex(t *testing.T) {
+	assert.Equal(t, 2, clusterWorkerIndex("test", "test-worker-2"))
+	assert.Equal(t, 0, clusterWorkerIndex("test", "test-manager"))
+	assert.Equal(t, 0, clusterWorkerIndex("test", "other-worker-1"))
+	assert.Equal(t, 0, clusterWorkerIndex("test", "test-worker-1-backup"))
+}
+
+func TestClusterSortWorkers(t *testing.T) {
+	cluster := &Cluster{
+		Name: "test",
+		Workers: []*host.Host{
+			{Name: "test-worker-10"},
+			{Name: "test-worker-2"},
+			{Name: "test-worker-1"},
+		},
+	}
+
+	cluster.sortWorkers()
+
+	assert.Equal(t, "test-worker-1", cluster.Workers[0].Name)
+	assert.Equal(t, "test-worker-2", cluster.Workers[1].Name)
+	assert.Equal(t, "test-worker-10", cluster.Workers[2].Name)
+}
+
+func TestClusterStatus(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 2)
+	defer cleanup()
+
+	cluster.Workers[1].Driver.(*fakedriver.Driver).MockState = state.Stopped
+
+	status := cluster.Status()
+
+	assert.Equal(t, 3, len(status.States))
+	assert.Equal(t, state.Stopped, status.States["test-worker-2"])
+	assert.Equal(t, 2, status.Running())
+	assert.False(t, status.Healthy())
+}
+
+func TestClusterStop(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 2)
+	defer cleanup()
+
+	cluster.Workers[0].Driver.(*fakedriver.Driver).MockState = state.Stopped
+
+	assert.NoError(t, cluster.Stop())
+
+	for _, h := range cluster.Hosts() {
+		st, _ := h.Driver.GetState()
+		assert.Equal(t, state.Stopped, st)
+	}
+}
+
+func TestClusterScaleDown(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 3)
+	defer cleanup()
+
+	assert.NoError(t, cluster.Scale(1))
+
+	assert.Equal(t, 1, len(cluster.Workers))
+	assert.Equal(t, "test-worker-1", cluster.Workers[0].Name)
+
+	exists, _ := cluster.api.Exists("test-worker-3")
+	assert.False(t, exists)
+	exists, _ = cluster.api.Exists("test-worker-1")
+	assert.True(t, exists)
+}
+
+func TestClusterScaleDownKeepsWorkersNotRemoved(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 3)
+	defer cleanup()
+
+	cluster.Workers[2].Driver.(*fakedriver.Driver).MockErrors = map[string]error{"Remove": errors.New("API unavailable")}
+
+	assert.EqualError(t, cluster.Scale(1), "Error with cluster \"test\":\ntest-worker-3: API unavailable")
+
+	assert.Equal(t, 2, len(cluster.Workers))
+	assert.Equal(t, "test-worker-3", cluster.Workers[1].Name)
+
+	exists, _ := cluster.api.Exists("test-worker-2")
+	assert.False(t, exists)
+	exists, _ = cluster.api.Exists("test-worker-3")
+	assert.True(t, exists)
+}
+
+func TestClusterScaleUpWithoutWorkers(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 0)
+	defer cleanup()
+
+	assert.Error(t, cluster.Scale(2))
+	assert.Equal(t, ErrInvalidWorkerCount, cluster.Scale(-1))
+}
+
+func TestClusterRemove(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 2)
+	defer cleanup()
+
+	assert.NoError(t, cluster.Remove())
+
+	names, err := cluster.api.List()
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestClusterRemoveProtected(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 2)
+	defer cleanup()
+
+	cluster.Manager.SetProtected(true)
+
+	assert.EqualError(t, cluster.Remove(), `Machine "test-manager" is protected against removal, unprotect it first or override the protection`)
+	assert.Equal(t, 2, len(cluster.Workers))
+
+	names, err := cluster.api.List()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(names))
+}
+
+func TestClusterScaleDownProtected(t *testing.T) {
+	cluster, cleanup := newTestCluster(t, 2)
+	defer cleanup()
+
+	cluster.Workers[1].SetProtected(true)
+
+	assert.Error(t, cluster.Scale(1))
+	assert.Equal(t, 2, len(cluster.Workers))
+}
@@ -0,0 +1,176 @@
+// Package completion exposes the names of the machines and of the drivers,
+// and the schemas of the create flags of the drivers, for shell completion
+// scripts and editor plugins to be generated from rather than maintained by
+// hand.
+package completion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// Flag types.
+const (
+	FlagTypeString      = "string"
+	FlagTypeStringSlice = "stringSlice"
+	FlagTypeInt         = "int"
+	FlagTypeBool        = "bool"
+)
+
+// Flag is the schema of a create flag of a driver.
+type Flag struct {
+	Name     string      `json:"Name"`
+	Type     string      `json:"Type"`
+	Usage    string      `json:"Usage"`
+	EnvVar   string      `json:"EnvVar,omitempty"`
+	Default  interface{} `json:"Default,omitempty"`
+	Choices  []string    `json:"Choices,omitempty"`
+	Required bool        `json:"Required,omitempty"`
+}
+
+// Driver is the schema of the create flags of a driver.
+type Driver struct {
+	Name  string `json:"Name"`
+	Flags []Flag `json:"Flags"`
+}
+
+// MachineNames returns the names of the machines of the store, sorted.
+func MachineNames(store persist.Store) ([]string, error) {
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// DriverNames returns the names of the core drivers and of the driver
+// plugins found in the PATH, sorted.
+func DriverNames() []string {
+	seen := map[string]bool{}
+	for _, name := range localbinary.CoreDrivers {
+		seen[name] = true
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, file := range files {
+			if name := pluginDriverName(file); name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// pluginDriverName returns the name of the driver of a plugin binary, or ""
+// if the file is not one.
+func pluginDriverName(file os.FileInfo) string {
+	if file.IsDir() || !strings.HasPrefix(file.Name(), localbinary.PluginBinaryPrefix) {
+		return ""
+	}
+
+	name := strings.TrimPrefix(file.Name(), localbinary.PluginBinaryPrefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, ".exe")
+	} else if file.Mode()&0111 == 0 {
+		return ""
+	}
+
+	return name
+}
+
+// DriverSchema returns the schema of the create flags of a driver, which is
+// started for its flags to be read.
+func DriverSchema(api libmachine.API, driverName string) (*Driver, error) {
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: "completion",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := api.NewHost(driverName, rawDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Driver{
+		Name:  driverName,
+		Flags: Flags(h.Driver.GetCreateFlags()),
+	}, nil
+}
+
+// Flags returns the schemas of create flags.
+func Flags(mcnFlags []mcnflag.Flag) []Flag {
+	flags := []Flag{}
+	for _, f := range mcnFlags {
+		switch f := f.(type) {
+		case *mcnflag.StringFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeString, Usage: f.Usage, EnvVar: f.EnvVar, Default: emptyAsNil(f.Value), Choices: f.Choices, Required: f.Required})
+		case mcnflag.StringFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeString, Usage: f.Usage, EnvVar: f.EnvVar, Default: emptyAsNil(f.Value), Choices: f.Choices, Required: f.Required})
+		case *mcnflag.StringSliceFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeStringSlice, Usage: f.Usage, EnvVar: f.EnvVar, Default: sliceDefault(f.Value), Required: f.Required})
+		case mcnflag.StringSliceFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeStringSlice, Usage: f.Usage, EnvVar: f.EnvVar, Default: sliceDefault(f.Value), Required: f.Required})
+		case *mcnflag.IntFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeInt, Usage: f.Usage, EnvVar: f.EnvVar, Default: f.Value})
+		case mcnflag.IntFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeInt, Usage: f.Usage, EnvVar: f.EnvVar, Default: f.Value})
+		case *mcnflag.BoolFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeBool, Usage: f.Usage, EnvVar: f.EnvVar})
+		case mcnflag.BoolFlag:
+			flags = append(flags, Flag{Name: f.Name, Type: FlagTypeBool, Usage: f.Usage, EnvVar: f.EnvVar})
+		default:
+			flags = append(flags, Flag{Name: f.String(), Type: fmt.Sprintf("%T", f)})
+		}
+	}
+
+	sort.Sort(flagsByName(flags))
+	return flags
+}
+
+func emptyAsNil(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func sliceDefault(values []string) interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+type flagsByName []Flag
+
+func (f flagsByName) Len() int           { return len(f) }
+func (f flagsByName) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f flagsByName) Less(i, j int) bool { return f[i].Name < f[j].Name }
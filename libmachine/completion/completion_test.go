@@ -0,0 +1,67 @@
+package completion
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlags(t *testing.T) {
+	flags := Flags([]mcnflag.Flag{
+		mcnflag.StringFlag{
+			Name:     "test-region",
+			Usage:    "Region",
+			EnvVar:   "TEST_REGION",
+			Value:    "us-east-1",
+			Choices:  []string{"us-east-1", "eu-west-1"},
+			Required: true,
+		},
+		mcnflag.IntFlag{
+			Name:  "test-memory",
+			Usage: "Memory",
+			Value: 1024,
+		},
+		mcnflag.BoolFlag{
+			Name:  "test-debug",
+			Usage: "Debug",
+		},
+		mcnflag.StringSliceFlag{
+			Name:  "test-tags",
+			Usage: "Tags",
+		},
+	})
+
+	assert.Equal(t, []Flag{
+		{Name: "test-debug", Type: FlagTypeBool, Usage: "Debug"},
+		{Name: "test-memory", Type: FlagTypeInt, Usage: "Memory", Default: 1024},
+		{Name: "test-region", Type: FlagTypeString, Usage: "Region", EnvVar: "TEST_REGION", Default: "us-east-1", Choices: []string{"us-east-1", "eu-west-1"}, Required: true},
+		{Name: "test-tags", Type: FlagTypeStringSlice, Usage: "Tags"},
+	}, flags)
+}
+
+func TestDriverNames(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are found by their executable bit")
+	}
+
+	dir, err := ioutil.TempDir("", "machine-completion")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-machine-driver-custom"), []byte{}, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "docker-machine-driver-notexecutable"), []byte{}, 0644))
+
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+
+	names := DriverNames()
+
+	assert.Contains(t, names, "custom")
+	assert.Contains(t, names, "virtualbox")
+	assert.NotContains(t, names, "notexecutable")
+}
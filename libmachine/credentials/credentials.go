@@ -0,0 +1,175 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	// DefaultProfile is the profile read from the credentials file when
+	// MACHINE_CREDENTIALS_PROFILE is not set.
+	DefaultProfile = "default"
+
+	// KeyringService is the service name under which credentials are
+	// stored in the OS keychain.
+	KeyringService = "docker-machine"
+
+	credentialsFileName = "credentials.json"
+)
+
+var (
+	ErrNotFound = errors.New("Credential not found")
+)
+
+// Source is a place provider credentials can be looked up from.
+type Source interface {
+	fmt.Stringer
+
+	// Get returns the value of the credential named key for the given
+	// provider (the driver name), or ErrNotFound.
+	Get(provider, key string) (string, error)
+}
+
+// EnvSource looks credentials up in environment variables named after the
+// provider and the key, e.g. DIGITALOCEAN_ACCESS_TOKEN.
+type EnvSource struct{}
+
+func (s EnvSource) String() string {
+	return "environment"
+}
+
+func (s EnvSource) Get(provider, key string) (string, error) {
+	if value := os.Getenv(EnvVarName(provider, key)); value != "" {
+		return value, nil
+	}
+
+	return "", ErrNotFound
+}
+
+// EnvVarName returns the environment variable EnvSource reads a credential
+// from.
+func EnvVarName(provider, key string) string {
+	name := strings.ToUpper(provider + "_" + key)
+	return strings.Replace(name, "-", "_", -1)
+}
+
+// FileSource reads credentials from a JSON file holding one set of
+// credentials per profile and per provider:
+//
+//	{
+//	    "default": {
+//	        "digitalocean": {
+//	            "access-token": "..."
+//	        }
+//	    }
+//	}
+type FileSource struct {
+	Path    string
+	Profile string
+}
+
+func (s *FileSource) String() string {
+	return fmt.Sprintf("file %s (profile %s)", s.Path, s.Profile)
+}
+
+func (s *FileSource) Get(provider, key string) (string, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+
+	profiles := map[string]map[string]map[string]string{}
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return "", fmt.Errorf("Error parsing credentials file %s: %s", s.Path, err)
+	}
+
+	if value := profiles[s.Profile][provider][key]; value != "" {
+		return value, nil
+	}
+
+	return "", ErrNotFound
+}
+
+// KeyringSource reads credentials from the OS keychain, where they are stored
+// under the KeyringService service with an account named "<provider>-<key>".
+type KeyringSource struct{}
+
+func (s KeyringSource) String() string {
+	return "keyring"
+}
+
+func (s KeyringSource) Get(provider, key string) (string, error) {
+	value, err := keyringGet(KeyringService, provider+"-"+key)
+	if err != nil {
+		log.Debugf("Keyring lookup of %s-%s failed: %s", provider, key, err)
+		return "", ErrNotFound
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", ErrNotFound
+	}
+
+	return value, nil
+}
+
+// Chain tries each of its sources in order.
+type Chain []Source
+
+// Get returns the first value found for the credential, along with the
+// source it was found in.
+func (c Chain) Get(provider, key string) (string, Source, error) {
+	for _, source := range c {
+		value, err := source.Get(provider, key)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		log.Debugf("Found %s credential %q in %s", provider, key, source)
+		return value, source, nil
+	}
+
+	return "", nil, ErrNotFound
+}
+
+// NewDefaultChain returns the lookup order shared by the drivers: the
+// environment, then the credentials file of the store, then the OS keychain.
+func NewDefaultChain(storePath string) Chain {
+	profile := os.Getenv("MACHINE_CREDENTIALS_PROFILE")
+	if profile == "" {
+		profile = DefaultProfile
+	}
+
+	return Chain{
+		EnvSource{},
+		&FileSource{
+			Path:    filepath.Join(storePath, credentialsFileName),
+			Profile: profile,
+		},
+		KeyringSource{},
+	}
+}
+
+// Lookup resolves a credential for a driver. An explicit value, typically
+// given through a flag, always wins; otherwise the default chain is searched.
+func Lookup(storePath, provider, key, explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+
+	value, _, err := NewDefaultChain(storePath).Get(provider, key)
+	return value, err
+}
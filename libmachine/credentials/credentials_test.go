@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSource struct {
+	values map[string]string
+}
+
+func (s *fakeSource) String() string {
+	return "fake"
+}
+
+func (s *fakeSource) Get(provider, key string) (string, error) {
+	if value, ok := s.values[provider+"/"+key]; ok {
+		return value, nil
+	}
+	return "", ErrNotFound
+}
+
+func TestEnvVarName(t *testing.T) {
+	assert.Equal(t, "DIGITALOCEAN_ACCESS_TOKEN", EnvVarName("digitalocean", "access-token"))
+}
+
+func TestEnvSource(t *testing.T) {
+	defer os.Unsetenv("TESTPROVIDER_API_KEY")
+	os.Setenv("TESTPROVIDER_API_KEY", "secret")
+
+	value, err := EnvSource{}.Get("testprovider", "api-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = EnvSource{}.Get("testprovider", "other-key")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestFileSource(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-credentials-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "credentials.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{
+		"default": {"digitalocean": {"access-token": "default-token"}},
+		"work": {"digitalocean": {"access-token": "work-token"}}
+	}`), 0600))
+
+	value, err := (&FileSource{Path: path, Profile: "default"}).Get("digitalocean", "access-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "default-token", value)
+
+	value, err = (&FileSource{Path: path, Profile: "work"}).Get("digitalocean", "access-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "work-token", value)
+
+	_, err = (&FileSource{Path: path, Profile: "default"}).Get("exoscale", "api-key")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = (&FileSource{Path: filepath.Join(tmpDir, "missing.json"), Profile: "default"}).Get("digitalocean", "access-token")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestChain(t *testing.T) {
+	first := &fakeSource{values: map[string]string{"p/a": "first-a"}}
+	second := &fakeSource{values: map[string]string{"p/a": "second-a", "p/b": "second-b"}}
+	chain := Chain{first, second}
+
+	value, source, err := chain.Get("p", "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "first-a", value)
+	assert.Equal(t, first, source)
+
+	value, source, err = chain.Get("p", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "second-b", value)
+	assert.Equal(t, second, source)
+
+	_, _, err = chain.Get("p", "c")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestLookupPrefersExplicitValue(t *testing.T) {
+	defer os.Unsetenv("TESTPROVIDER_TOKEN")
+	os.Setenv("TESTPROVIDER_TOKEN", "from-env")
+
+	value, err := Lookup("", "testprovider", "token", "explicit")
+	assert.NoError(t, err)
+	assert.Equal(t, "explicit", value)
+
+	value, err = Lookup("", "testprovider", "token", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+}
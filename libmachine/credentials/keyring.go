@@ -0,0 +1,33 @@
+package credentials
+
+import "strings"
+
+// Keyring stores secrets in the OS keychain, under the KeyringService
+// service and an account of the caller's choosing.
+type Keyring interface {
+	Get(account string) (string, error)
+	Set(account, value string) error
+	Delete(account string) error
+}
+
+// OSKeyring is the keychain of the OS: the login keychain on OS X, the
+// Secret Service (through secret-tool) on Linux and FreeBSD, and the
+// Credential Manager on Windows.
+type OSKeyring struct{}
+
+func (OSKeyring) Get(account string) (string, error) {
+	value, err := keyringGet(KeyringService, account)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(value, "\n"), nil
+}
+
+func (OSKeyring) Set(account, value string) error {
+	return keyringSet(KeyringService, account, value)
+}
+
+func (OSKeyring) Delete(account string) error {
+	return keyringDelete(KeyringService, account)
+}
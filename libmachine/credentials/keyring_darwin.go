@@ -0,0 +1,17 @@
+package credentials
+
+import "os/exec"
+
+func keyringGet(service, account string) (string, error) {
+	output, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	return string(output), err
+}
+
+func keyringSet(service, account, value string) error {
+	// security only reads the password from its arguments or a terminal.
+	return exec.Command("security", "add-generic-password", "-U", "-s", service, "-a", account, "-w", value).Run()
+}
+
+func keyringDelete(service, account string) error {
+	return exec.Command("security", "delete-generic-password", "-s", service, "-a", account).Run()
+}
@@ -0,0 +1,21 @@
+package credentials
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func keyringGet(service, account string) (string, error) {
+	output, err := exec.Command("secret-tool", "lookup", "service", service, "account", account).Output()
+	return string(output), err
+}
+
+func keyringSet(service, account, value string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", service+" "+account, "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(value)
+	return cmd.Run()
+}
+
+func keyringDelete(service, account string) error {
+	return exec.Command("secret-tool", "clear", "service", service, "account", account).Run()
+}
@@ -0,0 +1,63 @@
+package libmachine
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/drivers/rpc"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+// DriverOptionsFromValues converts the values of the driver create flags,
+// keyed by flag name, into the types the driver expects for each flag. The
+// values may be typed, or loosely typed as decoded from JSON. The flags which
+// are not set take the driver defaults.
+func DriverOptionsFromValues(mcnFlags []mcnflag.Flag, values map[string]interface{}, swarmOptions *swarm.Options) drivers.DriverOptions {
+	driverOpts := rpcdriver.RPCFlags{
+		Values: map[string]interface{}{
+			"swarm-master":    swarmOptions.Master,
+			"swarm-host":      swarmOptions.Host,
+			"swarm-discovery": swarmOptions.Discovery,
+		},
+	}
+
+	for _, f := range mcnFlags {
+		name := f.String()
+		value, present := values[name]
+
+		switch f.(type) {
+		case *mcnflag.BoolFlag, mcnflag.BoolFlag:
+			// Bool flags have no default value, which gob cannot encode.
+			b, _ := value.(bool)
+			driverOpts.Values[name] = b
+		case *mcnflag.IntFlag, mcnflag.IntFlag:
+			driverOpts.Values[name] = f.Default()
+			switch n := value.(type) {
+			case int:
+				driverOpts.Values[name] = n
+			case float64:
+				driverOpts.Values[name] = int(n)
+			}
+		case *mcnflag.StringSliceFlag, mcnflag.StringSliceFlag:
+			driverOpts.Values[name] = f.Default()
+			switch items := value.(type) {
+			case []string:
+				driverOpts.Values[name] = items
+			case []interface{}:
+				slice := []string{}
+				for _, item := range items {
+					slice = append(slice, fmt.Sprint(item))
+				}
+				driverOpts.Values[name] = slice
+			}
+		default:
+			driverOpts.Values[name] = f.Default()
+			if present {
+				driverOpts.Values[name] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	return driverOpts
+}
@@ -0,0 +1,57 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriverOptionsFromValues(t *testing.T) {
+	mcnFlags := []mcnflag.Flag{
+		&mcnflag.StringFlag{Name: "string", Value: "default"},
+		&mcnflag.IntFlag{Name: "int", Value: 1024},
+		&mcnflag.BoolFlag{Name: "bool"},
+		&mcnflag.StringSliceFlag{Name: "slice"},
+		&mcnflag.IntFlag{Name: "unset", Value: 42},
+		mcnflag.StringFlag{Name: "unset-string", Value: "default"},
+		mcnflag.BoolFlag{Name: "unset-bool"},
+	}
+
+	values := map[string]interface{}{
+		"string": "value",
+		"int":    2048,
+		"bool":   true,
+		"slice":  []string{"a", "b"},
+	}
+
+	opts := DriverOptionsFromValues(mcnFlags, values, &swarm.Options{Master: true, Discovery: "token://abc"})
+
+	assert.Equal(t, "value", opts.String("string"))
+	assert.Equal(t, 2048, opts.Int("int"))
+	assert.True(t, opts.Bool("bool"))
+	assert.Equal(t, []string{"a", "b"}, opts.StringSlice("slice"))
+	assert.Equal(t, 42, opts.Int("unset"))
+	assert.Equal(t, "default", opts.String("unset-string"))
+	assert.False(t, opts.Bool("unset-bool"))
+	assert.True(t, opts.Bool("swarm-master"))
+	assert.Equal(t, "token://abc", opts.String("swarm-discovery"))
+}
+
+func TestDriverOptionsFromJSONValues(t *testing.T) {
+	mcnFlags := []mcnflag.Flag{
+		&mcnflag.IntFlag{Name: "int", Value: 1024},
+		&mcnflag.StringSliceFlag{Name: "slice"},
+	}
+
+	values := map[string]interface{}{
+		"int":   float64(2048),
+		"slice": []interface{}{"a", "b"},
+	}
+
+	opts := DriverOptionsFromValues(mcnFlags, values, &swarm.Options{})
+
+	assert.Equal(t, 2048, opts.Int("int"))
+	assert.Equal(t, []string{"a", "b"}, opts.StringSlice("slice"))
+}
@@ -0,0 +1,104 @@
+package drivers
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AddressPreference tells which address to use among the addresses of a
+// machine reachable over both IPv4 and IPv6.
+type AddressPreference string
+
+const (
+	// PreferDriver uses the address returned by GetIP.
+	PreferDriver AddressPreference = ""
+	PreferIPv4   AddressPreference = "ipv4"
+	PreferIPv6   AddressPreference = "ipv6"
+	IPv4Only     AddressPreference = "ipv4-only"
+	IPv6Only     AddressPreference = "ipv6-only"
+)
+
+var addressPreferences = []AddressPreference{PreferIPv4, PreferIPv6, IPv4Only, IPv6Only}
+
+// ParseAddressPreference validates an address preference given by a user.
+func ParseAddressPreference(value string) (AddressPreference, error) {
+	if value == "" {
+		return PreferDriver, nil
+	}
+
+	for _, preference := range addressPreferences {
+		if string(preference) == value {
+			return preference, nil
+		}
+	}
+
+	valid := []string{}
+	for _, preference := range addressPreferences {
+		valid = append(valid, string(preference))
+	}
+
+	return PreferDriver, fmt.Errorf("Invalid address preference %q, must be one of %s", value, strings.Join(valid, ", "))
+}
+
+// SelectIP picks the address to use among the given ones. Hostnames, which
+// cannot be told apart, are treated as IPv4 addresses.
+func SelectIP(ips []string, preference AddressPreference) (string, error) {
+	if len(ips) == 0 {
+		return "", fmt.Errorf("No address to pick from")
+	}
+
+	if preference == PreferDriver {
+		return ips[0], nil
+	}
+
+	var ipv4, ipv6 []string
+	for _, ip := range ips {
+		if isIPv6(ip) {
+			ipv6 = append(ipv6, ip)
+		} else {
+			ipv4 = append(ipv4, ip)
+		}
+	}
+
+	switch preference {
+	case PreferIPv4:
+		if len(ipv4) > 0 {
+			return ipv4[0], nil
+		}
+		return ipv6[0], nil
+	case PreferIPv6:
+		if len(ipv6) > 0 {
+			return ipv6[0], nil
+		}
+		return ipv4[0], nil
+	case IPv4Only:
+		if len(ipv4) > 0 {
+			return ipv4[0], nil
+		}
+		return "", fmt.Errorf("The machine has no IPv4 address")
+	case IPv6Only:
+		if len(ipv6) > 0 {
+			return ipv6[0], nil
+		}
+		return "", fmt.Errorf("The machine has no IPv6 address")
+	}
+
+	return "", fmt.Errorf("Unknown address preference %q", preference)
+}
+
+// GetPreferredIP returns the address of the machine matching the
+// preference.
+func GetPreferredIP(d Driver, preference AddressPreference) (string, error) {
+	ips, err := GetIPs(d)
+	if err != nil {
+		return "", err
+	}
+
+	return SelectIP(ips, preference)
+}
+
+func isIPv6(address string) bool {
+	ip := net.ParseIP(strings.Trim(address, "[]"))
+	return ip != nil && ip.To4() == nil
+}
@@ -0,0 +1,48 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectIP(t *testing.T) {
+	dualStack := []string{"1.2.3.4", "2001:db8::1"}
+
+	cases := []struct {
+		ips        []string
+		preference AddressPreference
+		expectedIP string
+		expectErr  bool
+	}{
+		{dualStack, PreferDriver, "1.2.3.4", false},
+		{dualStack, PreferIPv4, "1.2.3.4", false},
+		{dualStack, PreferIPv6, "2001:db8::1", false},
+		{dualStack, IPv6Only, "2001:db8::1", false},
+		{[]string{"2001:db8::1"}, PreferIPv4, "2001:db8::1", false},
+		{[]string{"1.2.3.4"}, PreferIPv6, "1.2.3.4", false},
+		{[]string{"1.2.3.4"}, IPv6Only, "", true},
+		{[]string{"[2001:db8::1]"}, IPv4Only, "", true},
+		{[]string{"host.example.com"}, IPv4Only, "host.example.com", false},
+		{[]string{}, PreferIPv4, "", true},
+	}
+
+	for _, c := range cases {
+		ip, err := SelectIP(c.ips, c.preference)
+		assert.Equal(t, c.expectedIP, ip)
+		assert.Equal(t, c.expectErr, err != nil)
+	}
+}
+
+func TestParseAddressPreference(t *testing.T) {
+	preference, err := ParseAddressPreference("ipv6")
+	assert.NoError(t, err)
+	assert.Equal(t, PreferIPv6, preference)
+
+	preference, err = ParseAddressPreference("")
+	assert.NoError(t, err)
+	assert.Equal(t, PreferDriver, preference)
+
+	_, err = ParseAddressPreference("ipv5")
+	assert.Error(t, err)
+}
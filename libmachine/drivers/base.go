@@ -3,6 +3,8 @@ package drivers
 import (
 	"errors"
 	"path/filepath"
+
+	"github.com/docker/machine/libmachine/ssh"
 )
 
 const (
@@ -23,6 +25,10 @@ type BaseDriver struct {
 	SwarmMaster    bool
 	SwarmHost      string
 	SwarmDiscovery string
+
+	// SSHBastion is the host SSH connections to the machine go through,
+	// nil to connect directly.
+	SSHBastion *ssh.Bastion `json:",omitempty"`
 }
 
 // DriverName returns the name of the driver
@@ -68,6 +74,24 @@ func (d *BaseDriver) GetSSHUsername() string {
 	return d.SSHUser
 }
 
+// SetSSHBastion records the bastion SSH connections go through
+func (d *BaseDriver) SetSSHBastion(bastion ssh.Bastion) error {
+	d.SSHBastion = nil
+	if bastion.Host != "" {
+		d.SSHBastion = &bastion
+	}
+	return nil
+}
+
+// GetSSHBastion returns the bastion SSH connections go through, empty if
+// none, or for drivers without a BaseDriver
+func (d *BaseDriver) GetSSHBastion() (ssh.Bastion, error) {
+	if d == nil || d.SSHBastion == nil {
+		return ssh.Bastion{}, nil
+	}
+	return *d.SSHBastion, nil
+}
+
 // PreCreateCheck is called to enforce pre-creation steps
 func (d *BaseDriver) PreCreateCheck() error {
 	return nil
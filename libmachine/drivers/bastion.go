@@ -0,0 +1,60 @@
+package drivers
+
+import (
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+// Bastioner is implemented by the drivers the machines of which may be
+// reached over SSH through a bastion host. BaseDriver implements it.
+type Bastioner interface {
+	// SetSSHBastion records the bastion of the machine, an empty one to
+	// connect directly.
+	SetSSHBastion(bastion ssh.Bastion) error
+
+	// GetSSHBastion returns the bastion of the machine, empty when it is
+	// reached directly.
+	GetSSHBastion() (ssh.Bastion, error)
+}
+
+// SetSSHBastion records the bastion of the machine if the driver supports
+// it, or returns ErrNotImplemented.
+func SetSSHBastion(d Driver, bastion ssh.Bastion) error {
+	if b, ok := d.(Bastioner); ok {
+		return b.SetSSHBastion(bastion)
+	}
+
+	return ErrNotImplemented
+}
+
+// GetSSHBastion returns the bastion of the machine if the driver supports
+// it, or returns ErrNotImplemented.
+func GetSSHBastion(d Driver) (ssh.Bastion, error) {
+	if b, ok := d.(Bastioner); ok {
+		return b.GetSSHBastion()
+	}
+
+	return ssh.Bastion{}, ErrNotImplemented
+}
+
+// ResolveSSHBastion returns the bastion to reach the machine through, with
+// the key of the machine unless the bastion has its own, or nil to reach
+// it directly. The machines of the drivers which cannot tell, e.g. plugins
+// built against older versions, are reached directly.
+func ResolveSSHBastion(d Driver) *ssh.Bastion {
+	bastion, err := GetSSHBastion(d)
+	if err != nil {
+		if err != ErrNotImplemented {
+			log.Debugf("Error getting the SSH bastion of %s: %s", d.GetMachineName(), err)
+		}
+		return nil
+	}
+	if bastion.Host == "" {
+		return nil
+	}
+
+	if bastion.KeyPath == "" {
+		bastion.KeyPath = d.GetSSHKeyPath()
+	}
+	return &bastion
+}
@@ -0,0 +1,53 @@
+package drivers
+
+// DiscoveredInstance is an instance of a provider tagged as created by
+// docker-machine, see ResourceTags.
+type DiscoveredInstance struct {
+	// MachineName is the name of the machine the instance was created
+	// for, from its MachineNameTag tag.
+	MachineName string
+
+	// ID is the ID of the instance at the provider.
+	ID string
+
+	// IP is the address of the instance the driver connects to, empty
+	// when it has none, e.g. while stopped.
+	IP string
+
+	// State is the state of the instance as the provider names it.
+	State string
+}
+
+// Discoverer is implemented by the drivers of providers which can list the
+// instances tagged as created by docker-machine, for the machines missing
+// from the store, e.g. after it was lost, to be adopted.
+type Discoverer interface {
+	// DiscoverInstances lists the tagged instances the configuration of
+	// the driver, e.g. its credentials and region, gives access to.
+	DiscoverInstances() ([]DiscoveredInstance, error)
+
+	// AdoptInstance sets the driver up to manage the instance, as if it
+	// had created it. The SSH key of the instance is to be given in the
+	// configuration of the driver.
+	AdoptInstance(instance DiscoveredInstance) error
+}
+
+// DiscoverInstances lists the tagged instances if the driver supports it,
+// or returns ErrNotImplemented.
+func DiscoverInstances(d Driver) ([]DiscoveredInstance, error) {
+	if discoverer, ok := d.(Discoverer); ok {
+		return discoverer.DiscoverInstances()
+	}
+
+	return nil, ErrNotImplemented
+}
+
+// AdoptInstance sets the driver up to manage the instance if the driver
+// supports it, or returns ErrNotImplemented.
+func AdoptInstance(d Driver, instance DiscoveredInstance) error {
+	if discoverer, ok := d.(Discoverer); ok {
+		return discoverer.AdoptInstance(instance)
+	}
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,227 @@
+package driverstest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// RecordEnvVar is the environment variable which has the conformance
+// suites record the interactions with the providers in their cassettes,
+// rather than replay them.
+const RecordEnvVar = "MACHINE_CONFORMANCE_RECORD"
+
+// Interaction is an HTTP request to the API of a provider and its response.
+// The headers of the requests, which hold the credentials, are not recorded.
+type Interaction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	RequestBody string `json:"request_body,omitempty"`
+
+	Status         int         `json:"status"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body"`
+}
+
+// Cassette is the file the interactions of a run are recorded to and
+// replayed from.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Recorder is an http.RoundTripper recording the interactions with the API
+// of a provider in a cassette, or replaying them from it. A replayed run
+// must send the requests a recorded run sent, in the same order, which the
+// drivers do as the responses they get are the same.
+type Recorder struct {
+	// Path is the file of the cassette.
+	Path string
+
+	// Recording is set to record the interactions, through Transport, rather
+	// than replay them.
+	Recording bool
+
+	// Transport sends the requests when recording, http.DefaultTransport
+	// when nil.
+	Transport http.RoundTripper
+
+	// Redactions are replaced in the recorded URLs and bodies, e.g. to keep
+	// an account ID out of a cassette: the keys by their values. The same
+	// replacements are made on the replayed requests before they are
+	// compared to the recorded ones.
+	Redactions map[string]string
+
+	cassette *Cassette
+	next     int
+	mutex    sync.Mutex
+}
+
+// NewRecorder returns a recorder of the cassette at path, recording when
+// MACHINE_CONFORMANCE_RECORD is set and replaying otherwise. It fails to
+// replay a cassette which does not exist.
+func NewRecorder(path string) (*Recorder, error) {
+	r := &Recorder{
+		Path:      path,
+		Recording: os.Getenv(RecordEnvVar) != "",
+		cassette:  &Cassette{},
+	}
+
+	if r.Recording {
+		return r, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r.cassette); err != nil {
+		return nil, fmt.Errorf("Error reading the cassette %s: %s", path, err)
+	}
+
+	return r, nil
+}
+
+// Install has the recorder handle the requests sent with
+// http.DefaultTransport, which most SDKs of the providers end up using. It
+// returns the function putting the previous transport back.
+func (r *Recorder) Install() func() {
+	transport := http.DefaultTransport
+	if r.Transport == nil {
+		r.Transport = transport
+	}
+
+	http.DefaultTransport = r
+	return func() { http.DefaultTransport = transport }
+}
+
+func (r *Recorder) redact(s string) string {
+	for value, replacement := range r.Redactions {
+		if value != "" {
+			s = strings.Replace(s, value, replacement, -1)
+		}
+	}
+	return s
+}
+
+// RoundTrip records or replays the request.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.Recording {
+		return r.record(req, body)
+	}
+	return r.replay(req)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := http.Header{}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{
+		Method:         req.Method,
+		URL:            r.redact(req.URL.String()),
+		RequestBody:    r.redact(string(body)),
+		Status:         resp.StatusCode,
+		ResponseHeader: header,
+		ResponseBody:   r.redact(string(respBody)),
+	})
+
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	url := r.redact(req.URL.String())
+	if r.next >= len(r.cassette.Interactions) {
+		return nil, fmt.Errorf("No more interactions recorded in %s, got %s %s", r.Path, req.Method, url)
+	}
+
+	interaction := r.cassette.Interactions[r.next]
+	if interaction.Method != req.Method || interaction.URL != url {
+		return nil, fmt.Errorf("Interaction %d recorded in %s is %s %s, got %s %s", r.next, r.Path, interaction.Method, interaction.URL, req.Method, url)
+	}
+	r.next++
+
+	header := interaction.ResponseHeader
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(interaction.ResponseBody)),
+		ContentLength: int64(len(interaction.ResponseBody)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions to the cassette. It does nothing
+// when replaying.
+func (r *Recorder) Save() error {
+	if !r.Recording {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.Path, data, 0600)
+}
+
+// Remaining returns how many recorded interactions were not replayed.
+func (r *Recorder) Remaining() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Recording {
+		return 0
+	}
+	return len(r.cassette.Interactions) - r.next
+}
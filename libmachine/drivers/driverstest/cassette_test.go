@@ -0,0 +1,87 @@
+package driverstest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, url string) string {
+	resp, err := http.Get(url)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("instance of account-1234 at " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	os.Setenv(RecordEnvVar, "1")
+	recorder, err := NewRecorder(path)
+	os.Unsetenv(RecordEnvVar)
+	assert.NoError(t, err)
+	assert.True(t, recorder.Recording)
+
+	recorder.Redactions = map[string]string{"account-1234": "ACCOUNT"}
+	restore := recorder.Install()
+	assert.Equal(t, "instance of account-1234 at /instances/1", get(t, server.URL+"/instances/1"))
+	restore()
+	assert.NoError(t, recorder.Save())
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "account-1234"))
+
+	server.Close()
+
+	replayer, err := NewRecorder(path)
+	assert.NoError(t, err)
+	assert.False(t, replayer.Recording)
+
+	defer replayer.Install()()
+	assert.Equal(t, "instance of ACCOUNT at /instances/1", get(t, server.URL+"/instances/1"))
+	assert.Equal(t, 0, replayer.Remaining())
+
+	_, err = http.Get(server.URL + "/instances/2")
+	assert.Error(t, err)
+}
+
+func TestReplayUnexpectedRequest(t *testing.T) {
+	recorder := &Recorder{
+		Path: "cassette.json",
+		cassette: &Cassette{
+			Interactions: []*Interaction{{Method: "GET", URL: "https://api.example.com/zones", Status: 200}},
+		},
+	}
+
+	req, err := http.NewRequest("DELETE", "https://api.example.com/instances/1", nil)
+	assert.NoError(t, err)
+
+	_, err = recorder.RoundTrip(req)
+	assert.EqualError(t, err, "Interaction 0 recorded in cassette.json is GET https://api.example.com/zones, got DELETE https://api.example.com/instances/1")
+}
+
+func TestNewRecorderMissingCassette(t *testing.T) {
+	_, err := NewRecorder(filepath.Join("testdata", "missing.json"))
+
+	assert.True(t, os.IsNotExist(err))
+}
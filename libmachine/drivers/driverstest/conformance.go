@@ -0,0 +1,231 @@
+// Package driverstest has the conformance suite of the drivers: any driver
+// can run it against the API of its provider, or against interactions with
+// the API recorded in a cassette, to check that it creates, reaches,
+// provisions, stops, starts and removes machines the way libmachine expects.
+package driverstest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/docker/machine/libmachine/version"
+)
+
+// Options configure a run of the conformance suite.
+type Options struct {
+	// NewDriver returns the driver of the machine to create, configured as
+	// SetConfigFromFlags would.
+	NewDriver func(machineName, storePath string) drivers.Driver
+
+	// MachineName is the name of the machine, conformance by default.
+	MachineName string
+
+	// Cassette is the file the interactions with the API of the provider
+	// are recorded to, or replayed from. Without a cassette, the suite runs
+	// against the provider. See NewRecorder.
+	Cassette string
+
+	// Redactions are the secrets kept out of the cassette, see
+	// Recorder.Redactions.
+	Redactions map[string]string
+
+	// SkipSSH skips the steps needing to reach the machine, SSH and
+	// Provision, for the drivers of machines without SSH. They are always
+	// skipped when replaying a cassette, which records no SSH traffic.
+	SkipSSH bool
+
+	// StateTimeout is how long to wait for the machine to reach a state
+	// after Create, Stop and Start, three minutes by default.
+	StateTimeout time.Duration
+}
+
+// conformance is a run of the suite.
+type conformance struct {
+	Options
+	driver    drivers.Driver
+	storePath string
+	replaying bool
+	created   bool
+	removed   bool
+}
+
+// Run runs the conformance suite: Create, SSH, Provision, Stop, Start and
+// Remove, as subtests. The machine is removed even when a step fails. When
+// the cassette to replay does not exist, the suite is skipped.
+func Run(t *testing.T, options Options) {
+	c := &conformance{Options: options}
+	if c.MachineName == "" {
+		c.MachineName = "conformance"
+	}
+	if c.StateTimeout == 0 {
+		c.StateTimeout = 3 * time.Minute
+	}
+
+	if c.Cassette != "" {
+		recorder, err := NewRecorder(c.Cassette)
+		if os.IsNotExist(err) {
+			t.Skipf("No interactions recorded in %s, set %s to record them against the provider", c.Cassette, RecordEnvVar)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder.Redactions = c.Redactions
+		defer recorder.Install()()
+		defer func() {
+			if err := recorder.Save(); err != nil {
+				t.Errorf("Error saving the cassette %s: %s", c.Cassette, err)
+			}
+		}()
+		c.replaying = !recorder.Recording
+	}
+
+	storePath, err := newStorePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(storePath)
+	c.storePath = storePath
+
+	c.driver = c.NewDriver(c.MachineName, storePath)
+	defer c.cleanup(t)
+
+	for _, step := range []struct {
+		name    string
+		run     func() error
+		needSSH bool
+	}{
+		{"Create", c.create, false},
+		{"SSH", c.ssh, true},
+		{"Provision", c.provision, true},
+		{"Stop", c.stop, false},
+		{"Start", c.start, false},
+		{"Remove", c.remove, false},
+	} {
+		step := step
+		passed := t.Run(step.name, func(t *testing.T) {
+			if step.needSSH && (c.SkipSSH || c.replaying) {
+				t.Skip("The machine is not reachable")
+			}
+			if err := step.run(); err != nil {
+				t.Fatal(err)
+			}
+		})
+		if !passed {
+			return
+		}
+	}
+}
+
+func newStorePath() (string, error) {
+	storePath, err := ioutil.TempDir("", "machine-conformance")
+	if err != nil {
+		return "", err
+	}
+	return storePath, os.MkdirAll(filepath.Join(storePath, "machines"), 0700)
+}
+
+func (c *conformance) waitForState(desired state.State) error {
+	attempts := int(c.StateTimeout / time.Second)
+	return mcnutils.WaitForSpecific(drivers.MachineInState(c.driver, desired), attempts, time.Second)
+}
+
+func (c *conformance) create() error {
+	if err := c.driver.PreCreateCheck(); err != nil {
+		return err
+	}
+
+	c.created = true
+	if err := c.driver.Create(); err != nil {
+		return err
+	}
+
+	return c.waitForState(state.Running)
+}
+
+func (c *conformance) ssh() error {
+	if err := drivers.WaitForSSH(c.driver); err != nil {
+		return err
+	}
+
+	_, err := drivers.RunSSHCommandFromDriver(c.driver, "uname -a")
+	return err
+}
+
+func (c *conformance) provision() error {
+	machineDir := filepath.Join(c.storePath, "machines", c.MachineName)
+	certsDir := filepath.Join(c.storePath, "certs")
+
+	h := &host.Host{
+		ConfigVersion: version.ConfigVersion,
+		Name:          c.MachineName,
+		Driver:        c.driver,
+		DriverName:    c.driver.DriverName(),
+		HostOptions: &host.Options{
+			EngineOptions: &engine.Options{
+				InstallURL: drivers.DefaultEngineInstallURL,
+				TLSVerify:  true,
+			},
+			SwarmOptions: &swarm.Options{},
+			AuthOptions: &auth.Options{
+				CertDir:          certsDir,
+				CaCertPath:       filepath.Join(certsDir, "ca.pem"),
+				CaPrivateKeyPath: filepath.Join(certsDir, "ca-key.pem"),
+				ClientCertPath:   filepath.Join(certsDir, "cert.pem"),
+				ClientKeyPath:    filepath.Join(certsDir, "key.pem"),
+				ServerCertPath:   filepath.Join(machineDir, "server.pem"),
+				ServerKeyPath:    filepath.Join(machineDir, "server-key.pem"),
+				StorePath:        machineDir,
+			},
+		},
+	}
+
+	if err := cert.BootstrapCertificates(h.AuthOptions()); err != nil {
+		return err
+	}
+
+	return h.Provision()
+}
+
+func (c *conformance) stop() error {
+	if err := c.driver.Stop(); err != nil {
+		return err
+	}
+
+	return c.waitForState(state.Stopped)
+}
+
+func (c *conformance) start() error {
+	if err := c.driver.Start(); err != nil {
+		return err
+	}
+
+	return c.waitForState(state.Running)
+}
+
+func (c *conformance) remove() error {
+	c.removed = true
+	return c.driver.Remove()
+}
+
+// cleanup removes the machine a failed step left behind, not to leave
+// instances running, and paid for, at the provider.
+func (c *conformance) cleanup(t *testing.T) {
+	if !c.created || c.removed {
+		return
+	}
+
+	if err := c.driver.Remove(); err != nil {
+		t.Errorf("Error removing the machine %s, remove it by hand: %s", c.MachineName, err)
+	}
+}
@@ -0,0 +1,43 @@
+package driverstest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunFakeDriver(t *testing.T) {
+	var driver *fakedriver.Driver
+
+	Run(t, Options{
+		NewDriver: func(machineName, storePath string) drivers.Driver {
+			driver = fakedriver.NewDriver(machineName, storePath)
+			return driver
+		},
+		SkipSSH: true,
+	})
+
+	assert.Equal(t, 1, driver.Called("Create"))
+	assert.Equal(t, 1, driver.Called("Stop"))
+	assert.Equal(t, 1, driver.Called("Start"))
+	assert.Equal(t, 1, driver.Called("Remove"))
+}
+
+func TestRunWithoutCassette(t *testing.T) {
+	created := false
+
+	t.Run("Conformance", func(t *testing.T) {
+		Run(t, Options{
+			NewDriver: func(machineName, storePath string) drivers.Driver {
+				created = true
+				return fakedriver.NewDriver(machineName, storePath)
+			},
+			Cassette: filepath.Join("testdata", "missing.json"),
+		})
+	})
+
+	assert.False(t, created)
+}
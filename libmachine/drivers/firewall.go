@@ -0,0 +1,101 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FirewallRule lets traffic reach a port of the machine from the outside,
+// e.g. through a security group of a cloud provider.
+type FirewallRule struct {
+	// Protocol is either tcp or udp.
+	Protocol string
+	Port     int
+}
+
+func (r FirewallRule) String() string {
+	return fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+}
+
+// ParseFirewallRule parses a firewall rule in the port[/protocol] format,
+// e.g. 8080 or 53/udp. The protocol defaults to tcp.
+func ParseFirewallRule(rule string) (FirewallRule, error) {
+	port, protocol := rule, "tcp"
+	if i := strings.LastIndex(rule, "/"); i >= 0 {
+		port, protocol = rule[:i], strings.ToLower(rule[i+1:])
+	}
+
+	p, err := parsePort(port)
+	if err != nil || (protocol != "tcp" && protocol != "udp") {
+		return FirewallRule{}, fmt.Errorf("Invalid port %q, the port[/protocol] format is expected", rule)
+	}
+
+	return FirewallRule{Protocol: protocol, Port: p}, nil
+}
+
+// RequiredFirewallRules returns the rules every machine needs to be managed:
+// SSH and the engine port.
+func RequiredFirewallRules(enginePort int) []FirewallRule {
+	return []FirewallRule{
+		{Protocol: "tcp", Port: 22},
+		{Protocol: "tcp", Port: enginePort},
+	}
+}
+
+// IsRequiredFirewallRule tells whether the rule is one of the
+// RequiredFirewallRules, which must not be closed.
+func IsRequiredFirewallRule(rule FirewallRule, enginePort int) bool {
+	for _, required := range RequiredFirewallRules(enginePort) {
+		if rule == required {
+			return true
+		}
+	}
+	return false
+}
+
+// Firewaller is implemented by drivers of providers filtering the traffic
+// reaching the machines, e.g. with security groups. Drivers remove the rules
+// they added for a machine when it is removed, except those of firewalls
+// shared with other machines.
+type Firewaller interface {
+	// SetFirewallRules records the rules to open when the machine is
+	// created. It is called before the machine is created, with the
+	// RequiredFirewallRules first.
+	SetFirewallRules(rules []FirewallRule) error
+
+	// OpenPort opens a port of an existing machine.
+	OpenPort(rule FirewallRule) error
+
+	// ClosePort closes a port opened with OpenPort or at creation.
+	ClosePort(rule FirewallRule) error
+}
+
+// SetFirewallRules records the rules to open at creation if the driver
+// manages a firewall, or returns ErrNotImplemented.
+func SetFirewallRules(d Driver, rules []FirewallRule) error {
+	if f, ok := d.(Firewaller); ok {
+		return f.SetFirewallRules(rules)
+	}
+
+	return ErrNotImplemented
+}
+
+// OpenPort opens a port of the machine if the driver manages a firewall, or
+// returns ErrNotImplemented.
+func OpenPort(d Driver, rule FirewallRule) error {
+	if f, ok := d.(Firewaller); ok {
+		return f.OpenPort(rule)
+	}
+
+	return ErrNotImplemented
+}
+
+// ClosePort closes a port of the machine if the driver manages a firewall,
+// or returns ErrNotImplemented.
+func ClosePort(d Driver, rule FirewallRule) error {
+	if f, ok := d.(Firewaller); ok {
+		return f.ClosePort(rule)
+	}
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,30 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFirewallRule(t *testing.T) {
+	rule, err := ParseFirewallRule("8080")
+	assert.NoError(t, err)
+	assert.Equal(t, FirewallRule{Protocol: "tcp", Port: 8080}, rule)
+
+	rule, err = ParseFirewallRule("53/UDP")
+	assert.NoError(t, err)
+	assert.Equal(t, FirewallRule{Protocol: "udp", Port: 53}, rule)
+	assert.Equal(t, "53/udp", rule.String())
+
+	for _, invalid := range []string{"", "http", "0", "70000/tcp", "80/icmp", "80:8080"} {
+		_, err := ParseFirewallRule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestIsRequiredFirewallRule(t *testing.T) {
+	assert.True(t, IsRequiredFirewallRule(FirewallRule{Protocol: "tcp", Port: 22}, 2376))
+	assert.True(t, IsRequiredFirewallRule(FirewallRule{Protocol: "tcp", Port: 2376}, 2376))
+	assert.False(t, IsRequiredFirewallRule(FirewallRule{Protocol: "udp", Port: 22}, 2376))
+	assert.False(t, IsRequiredFirewallRule(FirewallRule{Protocol: "tcp", Port: 80}, 2376))
+}
@@ -0,0 +1,21 @@
+package drivers
+
+import "github.com/docker/machine/libmachine/ssh"
+
+// KnownHostsKeeper is implemented by the drivers which know where the host
+// key of their machine is recorded, e.g. the RPC client drivers, which the
+// libmachine client tells about its known_hosts file.
+type KnownHostsKeeper interface {
+	KnownHosts() ssh.KnownHosts
+}
+
+// KnownHostsOf returns where the host key of the machine is recorded. The
+// host keys of the machines of the drivers which cannot tell are not
+// checked.
+func KnownHostsOf(d Driver) ssh.KnownHosts {
+	if k, ok := d.(KnownHostsKeeper); ok {
+		return k.KnownHosts()
+	}
+
+	return ssh.KnownHosts{Alias: d.GetMachineName()}
+}
@@ -0,0 +1,92 @@
+package drivers
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// NameConstraints are the restrictions a provider puts on the names of the
+// machines, on top of the ones of the store.
+type NameConstraints struct {
+	// MaxLength is the maximum length of the names, zero for no limit.
+	MaxLength int
+
+	// Pattern is the regular expression the names must match, empty for
+	// no restriction, and PatternDescription how to explain it to users,
+	// e.g. "lowercase letters, digits and dashes".
+	Pattern            string
+	PatternDescription string
+}
+
+// Check returns an error telling why the name breaks the constraints, if
+// it does.
+func (c NameConstraints) Check(driverName, name string) error {
+	if c.MaxLength > 0 && len(name) > c.MaxLength {
+		return fmt.Errorf("The name %q is too long for the %s driver, which accepts at most %d characters", name, driverName, c.MaxLength)
+	}
+
+	if c.Pattern == "" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return fmt.Errorf("Invalid name pattern of the %s driver: %s", driverName, err)
+	}
+	if !pattern.MatchString(name) {
+		return fmt.Errorf("The name %q is not valid for the %s driver, which accepts %s", name, driverName, c.PatternDescription)
+	}
+
+	return nil
+}
+
+// NameChecker is implemented by the drivers of providers which restrict the
+// names of the machines, or reject names in use.
+type NameChecker interface {
+	// GetNameConstraints returns the restrictions on the names.
+	GetNameConstraints() (NameConstraints, error)
+
+	// CheckNameAvailable checks that the provider has no resource of the
+	// name of the machine yet, which creating the machine would conflict
+	// with.
+	CheckNameAvailable() error
+}
+
+// GetNameConstraints returns the restrictions on the names if the driver
+// has some, or returns ErrNotImplemented.
+func GetNameConstraints(d Driver) (NameConstraints, error) {
+	if c, ok := d.(NameChecker); ok {
+		return c.GetNameConstraints()
+	}
+
+	return NameConstraints{}, ErrNotImplemented
+}
+
+// CheckNameAvailable checks with the provider that the name of the machine
+// is not in use if the driver supports it, or returns ErrNotImplemented.
+func CheckNameAvailable(d Driver) error {
+	if c, ok := d.(NameChecker); ok {
+		return c.CheckNameAvailable()
+	}
+
+	return ErrNotImplemented
+}
+
+// CheckMachineName checks the name of the machine against the constraints
+// of the driver, then with the provider that it is not in use. The drivers
+// which do not implement NameChecker accept any name.
+func CheckMachineName(d Driver) error {
+	constraints, err := GetNameConstraints(d)
+	if err != nil && err != ErrNotImplemented {
+		return err
+	}
+	if err := constraints.Check(d.DriverName(), d.GetMachineName()); err != nil {
+		return err
+	}
+
+	if err := CheckNameAvailable(d); err != nil && err != ErrNotImplemented {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nameCheckerDriver struct {
+	*MockDriver
+	constraints NameConstraints
+	taken       bool
+}
+
+func (d *nameCheckerDriver) GetNameConstraints() (NameConstraints, error) {
+	return d.constraints, nil
+}
+
+func (d *nameCheckerDriver) CheckNameAvailable() error {
+	if d.taken {
+		return errors.New("name in use")
+	}
+	return nil
+}
+
+func TestNameConstraintsCheck(t *testing.T) {
+	constraints := NameConstraints{
+		MaxLength:          8,
+		Pattern:            `^[a-z]+$`,
+		PatternDescription: "lowercase letters",
+	}
+
+	assert.NoError(t, constraints.Check("test", "dev"))
+	assert.EqualError(t, constraints.Check("test", "development"), `The name "development" is too long for the test driver, which accepts at most 8 characters`)
+	assert.EqualError(t, constraints.Check("test", "dev-1"), `The name "dev-1" is not valid for the test driver, which accepts lowercase letters`)
+	assert.NoError(t, NameConstraints{}.Check("test", "Any_Name.1"))
+}
+
+func TestCheckMachineName(t *testing.T) {
+	d := &nameCheckerDriver{
+		MockDriver:  &MockDriver{calls: &CallRecorder{}, driverName: "checker", machineName: "dev"},
+		constraints: NameConstraints{MaxLength: 2},
+	}
+	assert.Error(t, CheckMachineName(d))
+
+	d.constraints = NameConstraints{}
+	assert.NoError(t, CheckMachineName(d))
+
+	d.taken = true
+	assert.EqualError(t, CheckMachineName(d), "name in use")
+}
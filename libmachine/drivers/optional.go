@@ -0,0 +1,358 @@
+package drivers
+
+import "errors"
+
+// ErrNotImplemented is returned when a driver does not implement one of the
+// optional interfaces below. Since drivers live behind an RPC boundary, the
+// helpers of this file must be used rather than type assertions: the client
+// side of the RPC always implements the interfaces and reports
+// ErrNotImplemented on behalf of the actual driver.
+var ErrNotImplemented = errors.New("Not implemented by this driver")
+
+// Cloner is implemented by drivers able to create a machine as a copy of an
+// existing one, e.g. from a provider snapshot, instead of from scratch.
+type Cloner interface {
+	// CreateClone creates the machine from the machine whose driver
+	// configuration is given.
+	CreateClone(sourceConfig []byte) error
+}
+
+// CreateClone creates the machine from the given source machine if the
+// driver supports it, or returns ErrNotImplemented.
+func CreateClone(d Driver, sourceConfig []byte) error {
+	if c, ok := d.(Cloner); ok {
+		return c.CreateClone(sourceConfig)
+	}
+
+	return ErrNotImplemented
+}
+
+// MultiAddresser is implemented by drivers of machines reachable on more
+// than one address, e.g. on both an IPv4 and an IPv6 address.
+type MultiAddresser interface {
+	// GetIPs returns all the addresses of the machine, the one returned
+	// by GetIP first.
+	GetIPs() ([]string, error)
+}
+
+// GetIPs returns all the addresses of the machine if the driver knows
+// them, or the single address returned by GetIP otherwise.
+func GetIPs(d Driver) ([]string, error) {
+	if m, ok := d.(MultiAddresser); ok {
+		ips, err := m.GetIPs()
+		if err != ErrNotImplemented {
+			return ips, err
+		}
+	}
+
+	ip, err := d.GetIP()
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{ip}, nil
+}
+
+// NetworkOptions pins the network configuration of a machine, so that its
+// address does not change between reboots.
+type NetworkOptions struct {
+	// StaticIP is the address of the machine on its private network.
+	StaticIP string
+	// Subnet is the CIDR of the private network, e.g. 192.168.99.0/24.
+	Subnet     string
+	DNSServers []string
+	// HostOnlyNetwork is the name of an existing host-only network to
+	// attach the machine to, instead of one picked by the driver.
+	HostOnlyNetwork string
+	// PortForwards make services of the machine reachable from the host.
+	PortForwards []PortForward
+}
+
+// IsEmpty tells whether no network option is set.
+func (o *NetworkOptions) IsEmpty() bool {
+	return o == nil || (o.StaticIP == "" && o.Subnet == "" && len(o.DNSServers) == 0 && o.HostOnlyNetwork == "" && len(o.PortForwards) == 0)
+}
+
+// NetworkConfigurer is implemented by drivers able to honor NetworkOptions,
+// typically drivers of local virtualization solutions.
+type NetworkConfigurer interface {
+	// SetNetworkOptions validates and records the network options. It is
+	// called before the machine is created.
+	SetNetworkOptions(opts NetworkOptions) error
+}
+
+// SetNetworkOptions records the network options if the driver supports
+// them, or returns ErrNotImplemented.
+func SetNetworkOptions(d Driver, opts NetworkOptions) error {
+	if c, ok := d.(NetworkConfigurer); ok {
+		return c.SetNetworkOptions(opts)
+	}
+
+	return ErrNotImplemented
+}
+
+// InstanceOptions are settings of cloud instances common to several
+// providers.
+type InstanceOptions struct {
+	// InstanceProfile is the identity the instance runs with, e.g. an AWS
+	// IAM instance profile, letting it reach the provider APIs without
+	// credentials baked in the machine.
+	InstanceProfile string
+	// Tags are attached to the instance.
+	Tags map[string]string
+}
+
+// IsEmpty tells whether no instance option is set.
+func (o *InstanceOptions) IsEmpty() bool {
+	return o == nil || (o.InstanceProfile == "" && len(o.Tags) == 0)
+}
+
+// InstanceConfigurer is implemented by drivers able to honor
+// InstanceOptions, typically drivers of cloud providers.
+type InstanceConfigurer interface {
+	// SetInstanceOptions validates and records the instance options. It is
+	// called before the machine is created.
+	SetInstanceOptions(opts InstanceOptions) error
+}
+
+// SetInstanceOptions records the instance options if the driver supports
+// them, or returns ErrNotImplemented.
+func SetInstanceOptions(d Driver, opts InstanceOptions) error {
+	if c, ok := d.(InstanceConfigurer); ok {
+		return c.SetInstanceOptions(opts)
+	}
+
+	return ErrNotImplemented
+}
+
+// PreemptionDetector is implemented by drivers of instances the provider may
+// reclaim at any time, e.g. GCE preemptible VMs.
+type PreemptionDetector interface {
+	// Preempted tells whether the machine is stopped because the provider
+	// reclaimed it.
+	Preempted() (bool, error)
+}
+
+// Preempted tells whether the machine was reclaimed by the provider if the
+// driver knows, or returns ErrNotImplemented.
+func Preempted(d Driver) (bool, error) {
+	if p, ok := d.(PreemptionDetector); ok {
+		return p.Preempted()
+	}
+
+	return false, ErrNotImplemented
+}
+
+// PrivateAddresser is implemented by drivers of machines which may also be
+// reachable on a private network of the provider.
+type PrivateAddresser interface {
+	// GetPrivateIP returns the address of the machine on the private
+	// network, or an empty string when it is not attached to one.
+	GetPrivateIP() (string, error)
+}
+
+// GetPrivateIP returns the private address of the machine if the driver
+// knows it, or returns ErrNotImplemented.
+func GetPrivateIP(d Driver) (string, error) {
+	if p, ok := d.(PrivateAddresser); ok {
+		return p.GetPrivateIP()
+	}
+
+	return "", ErrNotImplemented
+}
+
+// Autostarter is implemented by drivers of hypervisors able to start the
+// machine themselves when the host OS boots, e.g. VirtualBox autostart.
+type Autostarter interface {
+	// SetAutostart enables or disables starting the machine on boot.
+	SetAutostart(enabled bool) error
+}
+
+// SetAutostart has the hypervisor start the machine on boot if the driver
+// supports it, or returns ErrNotImplemented.
+func SetAutostart(d Driver, enabled bool) error {
+	if a, ok := d.(Autostarter); ok {
+		return a.SetAutostart(enabled)
+	}
+
+	return ErrNotImplemented
+}
+
+// SSHKeyInstaller is implemented by drivers of providers managing the keys
+// authorized on the machine, e.g. through the GCE instance metadata.
+type SSHKeyInstaller interface {
+	// InstallSSHKey authorizes the public key keyPath.pub on the machine in
+	// place of its current key.
+	InstallSSHKey(keyPath string) error
+}
+
+// InstallSSHKey authorizes a new key on the machine through the provider if
+// the driver supports it, or returns ErrNotImplemented.
+func InstallSSHKey(d Driver, keyPath string) error {
+	if i, ok := d.(SSHKeyInstaller); ok {
+		return i.InstallSSHKey(keyPath)
+	}
+
+	return ErrNotImplemented
+}
+
+// Stats is the resource usage of a machine. Sizes are in bytes.
+type Stats struct {
+	CPUs int
+	// CPUPercent is the share of the CPU time of all the CPUs spent busy.
+	CPUPercent float64
+
+	MemoryTotal uint64
+	MemoryUsed  uint64
+
+	// DiskTotal and DiskUsed are the size and usage of the disk the
+	// engine stores its images and containers on.
+	DiskTotal uint64
+	DiskUsed  uint64
+}
+
+// MemoryPercent returns the share of the memory in use.
+func (s *Stats) MemoryPercent() float64 {
+	return percent(s.MemoryUsed, s.MemoryTotal)
+}
+
+// DiskPercent returns the share of the disk in use.
+func (s *Stats) DiskPercent() float64 {
+	return percent(s.DiskUsed, s.DiskTotal)
+}
+
+func percent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(used) / float64(total)
+}
+
+// StatsReporter is implemented by drivers of providers exposing the resource
+// usage of the machines through their API, e.g. cloud monitoring services.
+type StatsReporter interface {
+	// GetStats returns the current resource usage of the machine.
+	GetStats() (*Stats, error)
+}
+
+// GetStats returns the resource usage of the machine as reported by the
+// provider if the driver supports it, or returns ErrNotImplemented.
+func GetStats(d Driver) (*Stats, error) {
+	if r, ok := d.(StatsReporter); ok {
+		return r.GetStats()
+	}
+
+	return nil, ErrNotImplemented
+}
+
+// GPUOptions give a machine GPUs, either through the instance type of a
+// cloud provider or by passing devices of the host through to a local VM.
+type GPUOptions struct {
+	// Count is the number of GPUs the machine needs.
+	Count int
+	// Type is the model of GPU, for providers offering several.
+	Type string
+	// PCIDevices are the host PCI addresses of the GPUs to pass through to
+	// the VM, e.g. 01:00.0.
+	PCIDevices []string
+}
+
+// IsEmpty tells whether no GPU option is set.
+func (o *GPUOptions) IsEmpty() bool {
+	return o == nil || (o.Count == 0 && o.Type == "" && len(o.PCIDevices) == 0)
+}
+
+// GPUConfigurer is implemented by drivers able to give machines GPUs.
+type GPUConfigurer interface {
+	// SetGPUOptions validates and records the GPU options. It is called
+	// before the machine is created.
+	SetGPUOptions(opts GPUOptions) error
+}
+
+// SetGPUOptions records the GPU options if the driver supports them, or
+// returns ErrNotImplemented.
+func SetGPUOptions(d Driver, opts GPUOptions) error {
+	if c, ok := d.(GPUConfigurer); ok {
+		return c.SetGPUOptions(opts)
+	}
+
+	return ErrNotImplemented
+}
+
+// ConsoleReader is implemented by drivers able to read what the machine
+// printed on its console, e.g. through the provider API or the logs of the
+// hypervisor. It helps finding out why a machine never became reachable
+// over SSH.
+type ConsoleReader interface {
+	// GetConsoleOutput returns the boot console output of the machine.
+	GetConsoleOutput() (string, error)
+}
+
+// GetConsoleOutput returns the console output of the machine if the driver
+// can read it, or returns ErrNotImplemented.
+func GetConsoleOutput(d Driver) (string, error) {
+	if r, ok := d.(ConsoleReader); ok {
+		return r.GetConsoleOutput()
+	}
+
+	return "", ErrNotImplemented
+}
+
+// InstanceChecker is implemented by drivers able to tell whether the
+// instance of the machine still exists at the provider, e.g. to detect
+// instances deleted from the console of the provider.
+type InstanceChecker interface {
+	// InstanceExists tells whether the instance of the machine exists. It
+	// returns an error only when the provider cannot be asked.
+	InstanceExists() (bool, error)
+}
+
+// InstanceExists tells whether the instance of the machine exists if the
+// driver can tell, or returns ErrNotImplemented.
+func InstanceExists(d Driver) (bool, error) {
+	if c, ok := d.(InstanceChecker); ok {
+		return c.InstanceExists()
+	}
+
+	return false, ErrNotImplemented
+}
+
+// ResourceTagger is implemented by drivers able to tag the cloud resources
+// they create, e.g. with ResourceTags, and to check the tags before deleting
+// the resources.
+type ResourceTagger interface {
+	// SetResourceTags records the tags to attach to the resources created
+	// for the machine. It is called before the machine is created.
+	SetResourceTags(tags map[string]string) error
+}
+
+// SetResourceTags records the tags of the resources if the driver supports
+// them, or returns ErrNotImplemented.
+func SetResourceTags(d Driver, tags map[string]string) error {
+	if t, ok := d.(ResourceTagger); ok {
+		return t.SetResourceTags(tags)
+	}
+
+	return ErrNotImplemented
+}
+
+// ReadinessReporter is implemented by drivers of providers reporting when an
+// instance finished booting, e.g. with the EC2 status checks, so that SSH is
+// only dialed once the machine can answer.
+type ReadinessReporter interface {
+	// InstanceReady tells whether the provider reports the instance
+	// booted. It returns an error only when the provider reports that the
+	// instance failed to boot: failing to ask the provider is reported as
+	// not ready, for the caller to ask again.
+	InstanceReady() (bool, error)
+}
+
+// InstanceReady tells whether the provider reports the instance booted if
+// the driver can tell, or returns ErrNotImplemented.
+func InstanceReady(d Driver) (bool, error) {
+	if r, ok := d.(ReadinessReporter); ok {
+		return r.InstanceReady()
+	}
+
+	return false, ErrNotImplemented
+}
@@ -0,0 +1,73 @@
+package drivers
+
+// Placement is where a cloud provider runs a machine.
+type Placement struct {
+	// Region is the region of the provider, e.g. eu-west-1.
+	Region string
+	// Zone is the availability zone in the region, e.g. eu-west-1a.
+	Zone string
+}
+
+// IsEmpty tells whether neither the region nor the zone is set.
+func (p *Placement) IsEmpty() bool {
+	return p == nil || (p.Region == "" && p.Zone == "")
+}
+
+// String returns the placement in the region/zone format, or the region or
+// the zone alone when the other is not set.
+func (p Placement) String() string {
+	switch {
+	case p.Region == "":
+		return p.Zone
+	case p.Zone == "":
+		return p.Region
+	}
+	return p.Region + "/" + p.Zone
+}
+
+// Placer is implemented by the drivers of providers with regions and zones,
+// for the placement of machines to be set the same way with all of them.
+type Placer interface {
+	// SetPlacement validates and records the region and zone of the
+	// machine, the ones left empty keeping the defaults of the driver. It
+	// is called before the machine is created.
+	SetPlacement(placement Placement) error
+
+	// GetPlacement returns the region and zone the machine is, or is to be,
+	// created in.
+	GetPlacement() (Placement, error)
+
+	// CheckPlacement checks with the provider that the zone exists in the
+	// region and is available.
+	CheckPlacement() error
+}
+
+// SetPlacement records the placement of the machine if the driver supports
+// it, or returns ErrNotImplemented.
+func SetPlacement(d Driver, placement Placement) error {
+	if p, ok := d.(Placer); ok {
+		return p.SetPlacement(placement)
+	}
+
+	return ErrNotImplemented
+}
+
+// GetPlacement returns the placement of the machine if the driver supports
+// it, or returns ErrNotImplemented.
+func GetPlacement(d Driver) (Placement, error) {
+	if p, ok := d.(Placer); ok {
+		return p.GetPlacement()
+	}
+
+	return Placement{}, ErrNotImplemented
+}
+
+// CheckPlacement checks the placement of the machine with the provider if
+// the driver supports it, or returns ErrNotImplemented.
+func CheckPlacement(d Driver) error {
+	if p, ok := d.(Placer); ok {
+		return p.CheckPlacement()
+	}
+
+	return ErrNotImplemented
+}
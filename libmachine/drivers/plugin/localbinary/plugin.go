@@ -18,8 +18,8 @@ var (
 	defaultTimeout               = 10 * time.Second
 	CurrentBinaryIsDockerMachine = false
 	CoreDrivers                  = []string{"amazonec2", "azure", "digitalocean",
-		"exoscale", "generic", "google", "hyperv", "none", "openstack",
-		"rackspace", "softlayer", "virtualbox", "vmwarefusion",
+		"exoscale", "generic", "google", "hyperv", "libvirt", "none", "openstack",
+		"rackspace", "scaleway", "softlayer", "vagrant", "virtualbox", "vmwarefusion",
 		"vmwarevcloudair", "vmwarevsphere"}
 )
 
@@ -29,6 +29,10 @@ const (
 	PluginEnvKey        = "MACHINE_PLUGIN_TOKEN"
 	PluginEnvVal        = "42"
 	PluginEnvDriverName = "MACHINE_PLUGIN_DRIVER_NAME"
+
+	// PluginBinaryPrefix prefixes the name of the driver in the name of the
+	// binary of a driver which is not a core driver.
+	PluginBinaryPrefix = "docker-machine-driver-"
 )
 
 type PluginStreamer interface {
@@ -108,7 +112,7 @@ func driverPath(driverName string) string {
 		}
 	}
 
-	return fmt.Sprintf("docker-machine-driver-%s", driverName)
+	return PluginBinaryPrefix + driverName
 }
 
 func NewPlugin(driverName string) (*Plugin, error) {
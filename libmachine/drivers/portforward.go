@@ -0,0 +1,69 @@
+package drivers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortForward makes a port of the machine reachable on a port of the host,
+// e.g. through the NAT of a local virtualization solution.
+type PortForward struct {
+	// Protocol is either tcp or udp.
+	Protocol  string
+	HostPort  int
+	GuestPort int
+}
+
+func (p PortForward) String() string {
+	return fmt.Sprintf("%d:%d/%s", p.HostPort, p.GuestPort, p.Protocol)
+}
+
+// ParsePortForward parses a port forwarding rule in the
+// hostPort[:guestPort][/protocol] format, e.g. 8080:80 or 5353/udp. The guest
+// port defaults to the host port and the protocol to tcp.
+func ParsePortForward(rule string) (PortForward, error) {
+	invalid := fmt.Errorf("Invalid port forwarding rule %q, the hostPort[:guestPort][/protocol] format is expected", rule)
+
+	ports, protocol := rule, "tcp"
+	if i := strings.LastIndex(rule, "/"); i >= 0 {
+		ports, protocol = rule[:i], strings.ToLower(rule[i+1:])
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return PortForward{}, invalid
+	}
+
+	parts := strings.Split(ports, ":")
+	if len(parts) > 2 {
+		return PortForward{}, invalid
+	}
+
+	hostPort, err := parsePort(parts[0])
+	if err != nil {
+		return PortForward{}, invalid
+	}
+
+	guestPort := hostPort
+	if len(parts) == 2 {
+		if guestPort, err = parsePort(parts[1]); err != nil {
+			return PortForward{}, invalid
+		}
+	}
+
+	return PortForward{
+		Protocol:  protocol,
+		HostPort:  hostPort,
+		GuestPort: guestPort,
+	}, nil
+}
+
+func parsePort(port string) (int, error) {
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return 0, err
+	}
+	if p < 1 || p > 65535 {
+		return 0, fmt.Errorf("port %d out of range", p)
+	}
+	return p, nil
+}
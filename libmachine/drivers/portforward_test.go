@@ -0,0 +1,38 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePortForward(t *testing.T) {
+	var tests = []struct {
+		rule     string
+		expected PortForward
+	}{
+		{"8080", PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 8080}},
+		{"8080:80", PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 80}},
+		{"5353:53/udp", PortForward{Protocol: "udp", HostPort: 5353, GuestPort: 53}},
+		{"5000/TCP", PortForward{Protocol: "tcp", HostPort: 5000, GuestPort: 5000}},
+	}
+
+	for _, test := range tests {
+		portForward, err := ParsePortForward(test.rule)
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, portForward)
+	}
+}
+
+func TestParsePortForwardInvalid(t *testing.T) {
+	for _, rule := range []string{"", "http", "8080:80:80", "0", "70000", "8080/sctp", "8080:"} {
+		_, err := ParsePortForward(rule)
+
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestPortForwardString(t *testing.T) {
+	assert.Equal(t, "8080:80/tcp", PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 80}.String())
+}
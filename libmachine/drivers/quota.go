@@ -0,0 +1,32 @@
+package drivers
+
+import "fmt"
+
+// ErrQuotaExceeded is returned by PreCreateCheck when the machine does not
+// fit in a quota of the provider, e.g. the number of instances or vCPUs of
+// the account.
+type ErrQuotaExceeded struct {
+	Quota  string
+	Limit  float64
+	Usage  float64
+	Needed float64
+}
+
+func (e ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("The machine needs %g %s, only %g of the quota of %g are left", e.Needed, e.Quota, e.Limit-e.Usage, e.Limit)
+}
+
+// CheckQuota returns an ErrQuotaExceeded if needed more of the quota do not
+// fit within its limit. A limit below zero stands for no limit.
+func CheckQuota(quota string, limit, usage, needed float64) error {
+	if limit < 0 || usage+needed <= limit {
+		return nil
+	}
+
+	return ErrQuotaExceeded{
+		Quota:  quota,
+		Limit:  limit,
+		Usage:  usage,
+		Needed: needed,
+	}
+}
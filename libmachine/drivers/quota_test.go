@@ -0,0 +1,17 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuota(t *testing.T) {
+	assert.NoError(t, CheckQuota("instances", 20, 19, 1))
+	assert.NoError(t, CheckQuota("instances", -1, 100, 1))
+
+	err := CheckQuota("vCPUs", 24, 22, 4)
+
+	assert.Equal(t, ErrQuotaExceeded{Quota: "vCPUs", Limit: 24, Usage: 22, Needed: 4}, err)
+	assert.EqualError(t, err, "The machine needs 4 vCPUs, only 2 of the quota of 24 are left")
+}
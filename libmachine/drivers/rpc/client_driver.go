@@ -3,6 +3,7 @@ package rpcdriver
 import (
 	"fmt"
 	"net/rpc"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/docker/machine/libmachine/drivers/plugin/localbinary"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/version"
 )
@@ -41,6 +43,7 @@ type RPCClientDriver struct {
 	plugin          localbinary.DriverPlugin
 	heartbeatDoneCh chan bool
 	Client          *InternalClient
+	knownHosts      ssh.KnownHosts
 }
 
 type RPCCall struct {
@@ -53,6 +56,14 @@ type InternalClient struct {
 	MachineName    string
 	RPCClient      *rpc.Client
 	rpcServiceName string
+	driverName     string
+	timeouts       drivers.CallTimeouts
+
+	// timedOut is closed once the call which last timed out returns. The
+	// plugin still runs it, the next calls wait for it rather than run
+	// alongside it, e.g. through a SerialDriver.
+	timedOut     chan struct{}
+	timedOutLock sync.Mutex
 }
 
 const (
@@ -83,13 +94,104 @@ const (
 	RestartMethod            = `.Restart`
 	KillMethod               = `.Kill`
 	UpgradeMethod            = `.Upgrade`
+	CreateCloneMethod        = `.CreateClone`
+	GetIPsMethod             = `.GetIPs`
+	SetNetworkOptionsMethod  = `.SetNetworkOptions`
+	SetInstanceOptionsMethod = `.SetInstanceOptions`
+	PreemptedMethod          = `.Preempted`
+	GetPrivateIPMethod       = `.GetPrivateIP`
+	SetAutostartMethod       = `.SetAutostart`
+	InstallSSHKeyMethod      = `.InstallSSHKey`
+	GetStatsMethod           = `.GetStats`
+	SetGPUOptionsMethod      = `.SetGPUOptions`
+	GetConsoleOutputMethod   = `.GetConsoleOutput`
+	InstanceExistsMethod     = `.InstanceExists`
+	SetResourceTagsMethod    = `.SetResourceTags`
+	SetFirewallRulesMethod   = `.SetFirewallRules`
+	OpenPortMethod           = `.OpenPort`
+	ClosePortMethod          = `.ClosePort`
+	InstanceReadyMethod      = `.InstanceReady`
+	SetPlacementMethod       = `.SetPlacement`
+	GetPlacementMethod       = `.GetPlacement`
+	CheckPlacementMethod     = `.CheckPlacement`
+	GetNameConstraintsMethod = `.GetNameConstraints`
+	CheckNameAvailableMethod = `.CheckNameAvailable`
+	SetSizeMethod            = `.SetSize`
+	GetSizeMethod            = `.GetSize`
+	GetSensitiveFieldsMethod = `.GetSensitiveFields`
+	DiscoverInstancesMethod  = `.DiscoverInstances`
+	AdoptInstanceMethod      = `.AdoptInstance`
+	SetSSHBastionMethod      = `.SetSSHBastion`
+	GetSSHBastionMethod      = `.GetSSHBastion`
 )
 
 func (ic *InternalClient) Call(serviceMethod string, args interface{}, reply interface{}) error {
 	if serviceMethod != HeartbeatMethod {
 		log.Debugf("(%s) Calling %+v", ic.MachineName, serviceMethod)
 	}
-	return ic.RPCClient.Call(ic.rpcServiceName+serviceMethod, args, reply)
+
+	timeout := ic.timeouts[strings.TrimPrefix(serviceMethod, ".")]
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	// The heartbeats and the closing of the plugin do not run the driver,
+	// they need not wait.
+	if serviceMethod != HeartbeatMethod && serviceMethod != CloseMethod {
+		select {
+		case <-ic.timedOutCall():
+		case <-deadline:
+			return ic.timeoutError(serviceMethod, timeout)
+		}
+	}
+
+	if timeout <= 0 {
+		return ic.RPCClient.Call(ic.rpcServiceName+serviceMethod, args, reply)
+	}
+
+	// The call goes on in the plugin when it times out, its reply is then
+	// discarded.
+	call := ic.RPCClient.Go(ic.rpcServiceName+serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-deadline:
+		done := make(chan struct{})
+		go func() {
+			<-call.Done
+			close(done)
+		}()
+
+		ic.timedOutLock.Lock()
+		ic.timedOut = done
+		ic.timedOutLock.Unlock()
+
+		return ic.timeoutError(serviceMethod, timeout)
+	}
+}
+
+// timedOutCall returns a channel closed once the call which last timed out
+// returns, closed already when no call timed out.
+func (ic *InternalClient) timedOutCall() <-chan struct{} {
+	ic.timedOutLock.Lock()
+	defer ic.timedOutLock.Unlock()
+
+	if ic.timedOut == nil {
+		ic.timedOut = make(chan struct{})
+		close(ic.timedOut)
+	}
+	return ic.timedOut
+}
+
+func (ic *InternalClient) timeoutError(serviceMethod string, timeout time.Duration) error {
+	return drivers.ErrCallTimeout{
+		Driver:  ic.driverName,
+		Call:    strings.TrimPrefix(serviceMethod, "."),
+		Timeout: timeout,
+	}
 }
 
 func (ic *InternalClient) switchToV0() {
@@ -148,6 +250,7 @@ func (f *DefaultRPCClientDriverFactory) NewRPCClientDriver(driverName string, ra
 		Client:          NewInternalClient(rpcclient),
 		heartbeatDoneCh: make(chan bool),
 	}
+	c.Client.driverName = driverName
 
 	f.openedDriversLock.Lock()
 	f.openedDrivers = append(f.openedDrivers, c)
@@ -157,7 +260,7 @@ func (f *DefaultRPCClientDriverFactory) NewRPCClientDriver(driverName string, ra
 	if err := c.Client.Call(GetVersionMethod, struct{}{}, &serverVersion); err != nil {
 		// this is the first call we make to the server. We try to play nice with old pre 0.5.1 client,
 		// by gracefully trying old RPCServiceName, we do this only once, and keep the result for future calls.
-		log.Debugf(err.Error())
+		log.Debug(err)
 		log.Debugf("Client (%s) with %s does not work, re-attempting with %s", c.Client.MachineName, RPCServiceNameV1, RPCServiceNameV0)
 		c.Client.switchToV0()
 		if err := c.Client.Call(GetVersionMethod, struct{}{}, &serverVersion); err != nil {
@@ -198,6 +301,28 @@ func (f *DefaultRPCClientDriverFactory) NewRPCClientDriver(driverName string, ra
 	return c, nil
 }
 
+// SetCallTimeouts sets how long to wait for the driver to answer its calls.
+// A call which times out returns a drivers.ErrCallTimeout, the next calls
+// waiting for the plugin to finish it.
+func (c *RPCClientDriver) SetCallTimeouts(timeouts drivers.CallTimeouts) {
+	c.Client.timeouts = timeouts
+}
+
+// SetKnownHosts sets where the host key of the machine is recorded, in the
+// known_hosts file of the store the driver was loaded from.
+func (c *RPCClientDriver) SetKnownHosts(knownHosts ssh.KnownHosts) {
+	c.knownHosts = knownHosts
+}
+
+// KnownHosts returns where the host key of the machine is recorded, nowhere
+// until SetKnownHosts is called.
+func (c *RPCClientDriver) KnownHosts() ssh.KnownHosts {
+	if c.knownHosts.Alias == "" {
+		return ssh.KnownHosts{Alias: c.GetMachineName()}
+	}
+	return c.knownHosts
+}
+
 func (c *RPCClientDriver) MarshalJSON() ([]byte, error) {
 	return c.GetConfigRaw()
 }
@@ -365,3 +490,207 @@ func (c *RPCClientDriver) Kill() error {
 func (c *RPCClientDriver) Upgrade() error {
 	return c.Client.Call(UpgradeMethod, struct{}{}, nil)
 }
+
+// notImplementedOr turns the error the server reports for a driver lacking an
+// optional interface back into drivers.ErrNotImplemented.
+func notImplementedOr(err error) error {
+	if err != nil && err.Error() == drivers.ErrNotImplemented.Error() {
+		return drivers.ErrNotImplemented
+	}
+
+	return err
+}
+
+func (c *RPCClientDriver) CreateClone(sourceConfig []byte) error {
+	return notImplementedOr(c.Client.Call(CreateCloneMethod, sourceConfig, nil))
+}
+
+func (c *RPCClientDriver) GetIPs() ([]string, error) {
+	var ips []string
+
+	if err := c.Client.Call(GetIPsMethod, struct{}{}, &ips); err != nil {
+		return nil, notImplementedOr(err)
+	}
+
+	return ips, nil
+}
+
+func (c *RPCClientDriver) SetNetworkOptions(opts drivers.NetworkOptions) error {
+	return notImplementedOr(c.Client.Call(SetNetworkOptionsMethod, opts, nil))
+}
+
+func (c *RPCClientDriver) SetInstanceOptions(opts drivers.InstanceOptions) error {
+	return notImplementedOr(c.Client.Call(SetInstanceOptionsMethod, opts, nil))
+}
+
+func (c *RPCClientDriver) Preempted() (bool, error) {
+	var preempted bool
+
+	if err := c.Client.Call(PreemptedMethod, struct{}{}, &preempted); err != nil {
+		return false, notImplementedOr(err)
+	}
+
+	return preempted, nil
+}
+
+func (c *RPCClientDriver) GetPrivateIP() (string, error) {
+	var ip string
+
+	if err := c.Client.Call(GetPrivateIPMethod, struct{}{}, &ip); err != nil {
+		return "", notImplementedOr(err)
+	}
+
+	return ip, nil
+}
+
+func (c *RPCClientDriver) SetAutostart(enabled bool) error {
+	return notImplementedOr(c.Client.Call(SetAutostartMethod, enabled, nil))
+}
+
+func (c *RPCClientDriver) InstallSSHKey(keyPath string) error {
+	return notImplementedOr(c.Client.Call(InstallSSHKeyMethod, keyPath, nil))
+}
+
+func (c *RPCClientDriver) GetStats() (*drivers.Stats, error) {
+	var stats drivers.Stats
+
+	if err := c.Client.Call(GetStatsMethod, struct{}{}, &stats); err != nil {
+		return nil, notImplementedOr(err)
+	}
+
+	return &stats, nil
+}
+
+func (c *RPCClientDriver) SetGPUOptions(opts drivers.GPUOptions) error {
+	return notImplementedOr(c.Client.Call(SetGPUOptionsMethod, opts, nil))
+}
+
+func (c *RPCClientDriver) GetConsoleOutput() (string, error) {
+	var output string
+
+	if err := c.Client.Call(GetConsoleOutputMethod, struct{}{}, &output); err != nil {
+		return "", notImplementedOr(err)
+	}
+
+	return output, nil
+}
+
+func (c *RPCClientDriver) InstanceExists() (bool, error) {
+	var exists bool
+
+	if err := c.Client.Call(InstanceExistsMethod, struct{}{}, &exists); err != nil {
+		return false, notImplementedOr(err)
+	}
+
+	return exists, nil
+}
+
+func (c *RPCClientDriver) SetResourceTags(tags map[string]string) error {
+	return notImplementedOr(c.Client.Call(SetResourceTagsMethod, tags, nil))
+}
+
+func (c *RPCClientDriver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	return notImplementedOr(c.Client.Call(SetFirewallRulesMethod, rules, nil))
+}
+
+func (c *RPCClientDriver) OpenPort(rule drivers.FirewallRule) error {
+	return notImplementedOr(c.Client.Call(OpenPortMethod, rule, nil))
+}
+
+func (c *RPCClientDriver) ClosePort(rule drivers.FirewallRule) error {
+	return notImplementedOr(c.Client.Call(ClosePortMethod, rule, nil))
+}
+
+func (c *RPCClientDriver) InstanceReady() (bool, error) {
+	var ready bool
+
+	if err := c.Client.Call(InstanceReadyMethod, struct{}{}, &ready); err != nil {
+		return false, notImplementedOr(err)
+	}
+
+	return ready, nil
+}
+
+func (c *RPCClientDriver) SetPlacement(placement drivers.Placement) error {
+	return notImplementedOr(c.Client.Call(SetPlacementMethod, placement, nil))
+}
+
+func (c *RPCClientDriver) GetPlacement() (drivers.Placement, error) {
+	var placement drivers.Placement
+
+	if err := c.Client.Call(GetPlacementMethod, struct{}{}, &placement); err != nil {
+		return drivers.Placement{}, notImplementedOr(err)
+	}
+
+	return placement, nil
+}
+
+func (c *RPCClientDriver) CheckPlacement() error {
+	return notImplementedOr(c.Client.Call(CheckPlacementMethod, struct{}{}, nil))
+}
+
+func (c *RPCClientDriver) GetNameConstraints() (drivers.NameConstraints, error) {
+	var constraints drivers.NameConstraints
+
+	if err := c.Client.Call(GetNameConstraintsMethod, struct{}{}, &constraints); err != nil {
+		return drivers.NameConstraints{}, notImplementedOr(err)
+	}
+
+	return constraints, nil
+}
+
+func (c *RPCClientDriver) CheckNameAvailable() error {
+	return notImplementedOr(c.Client.Call(CheckNameAvailableMethod, struct{}{}, nil))
+}
+
+func (c *RPCClientDriver) SetSize(size drivers.Size) error {
+	return notImplementedOr(c.Client.Call(SetSizeMethod, size, nil))
+}
+
+func (c *RPCClientDriver) GetSize() (drivers.Size, error) {
+	var size drivers.Size
+
+	if err := c.Client.Call(GetSizeMethod, struct{}{}, &size); err != nil {
+		return drivers.Size{}, notImplementedOr(err)
+	}
+
+	return size, nil
+}
+
+func (c *RPCClientDriver) GetSensitiveFields() ([]string, error) {
+	var fields []string
+
+	if err := c.Client.Call(GetSensitiveFieldsMethod, struct{}{}, &fields); err != nil {
+		return nil, notImplementedOr(err)
+	}
+
+	return fields, nil
+}
+
+func (c *RPCClientDriver) DiscoverInstances() ([]drivers.DiscoveredInstance, error) {
+	var instances []drivers.DiscoveredInstance
+
+	if err := c.Client.Call(DiscoverInstancesMethod, struct{}{}, &instances); err != nil {
+		return nil, notImplementedOr(err)
+	}
+
+	return instances, nil
+}
+
+func (c *RPCClientDriver) AdoptInstance(instance drivers.DiscoveredInstance) error {
+	return notImplementedOr(c.Client.Call(AdoptInstanceMethod, instance, nil))
+}
+
+func (c *RPCClientDriver) SetSSHBastion(bastion ssh.Bastion) error {
+	return notImplementedOr(c.Client.Call(SetSSHBastionMethod, bastion, nil))
+}
+
+func (c *RPCClientDriver) GetSSHBastion() (ssh.Bastion, error) {
+	var bastion ssh.Bastion
+
+	if err := c.Client.Call(GetSSHBastionMethod, struct{}{}, &bastion); err != nil {
+		return ssh.Bastion{}, notImplementedOr(err)
+	}
+
+	return bastion, nil
+}
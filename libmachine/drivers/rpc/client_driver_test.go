@@ -0,0 +1,95 @@
+package rpcdriver
+
+import (
+	"net"
+	"net/rpc"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type slowServerDriver struct {
+	delay time.Duration
+
+	running    int32
+	maxRunning int32
+}
+
+func (s *slowServerDriver) GetState(_ *struct{}, reply *state.State) error {
+	running := atomic.AddInt32(&s.running, 1)
+	defer atomic.AddInt32(&s.running, -1)
+	if running > atomic.LoadInt32(&s.maxRunning) {
+		atomic.StoreInt32(&s.maxRunning, running)
+	}
+
+	time.Sleep(s.delay)
+	*reply = state.Running
+	return nil
+}
+
+func newSlowClientDriver(t *testing.T, delay time.Duration) *RPCClientDriver {
+	d, _ := newSlowDrivers(t, delay)
+	return d
+}
+
+func newSlowDrivers(t *testing.T, delay time.Duration) (*RPCClientDriver, *slowServerDriver) {
+	serverDriver := &slowServerDriver{delay: delay}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(RPCServiceNameV1, serverDriver); err != nil {
+		t.Fatal(err)
+	}
+
+	serverConn, clientConn := net.Pipe()
+	go server.ServeConn(serverConn)
+
+	client := NewInternalClient(rpc.NewClient(clientConn))
+	client.driverName = "slow"
+
+	return &RPCClientDriver{Client: client}, serverDriver
+}
+
+func TestCallWithinTimeout(t *testing.T) {
+	d := newSlowClientDriver(t, 0)
+	d.SetCallTimeouts(drivers.CallTimeouts{"GetState": time.Second})
+
+	s, err := d.GetState()
+
+	assert.NoError(t, err)
+	assert.Equal(t, state.Running, s)
+}
+
+func TestCallTimeout(t *testing.T) {
+	d := newSlowClientDriver(t, time.Second)
+	d.SetCallTimeouts(drivers.CallTimeouts{"GetState": 10 * time.Millisecond})
+
+	_, err := d.GetState()
+
+	assert.Equal(t, drivers.ErrCallTimeout{
+		Driver:  "slow",
+		Call:    "GetState",
+		Timeout: 10 * time.Millisecond,
+	}, err)
+}
+
+func TestCallWaitsForTimedOutCall(t *testing.T) {
+	d, serverDriver := newSlowDrivers(t, 200*time.Millisecond)
+	d.SetCallTimeouts(drivers.CallTimeouts{"GetState": 10 * time.Millisecond})
+
+	_, err := d.GetState()
+	assert.IsType(t, drivers.ErrCallTimeout{}, err)
+
+	_, err = d.GetState()
+	assert.IsType(t, drivers.ErrCallTimeout{}, err, "the call still running is waited for")
+
+	d.SetCallTimeouts(drivers.CallTimeouts{"GetState": time.Second})
+	s, err := d.GetState()
+
+	assert.NoError(t, err)
+	assert.Equal(t, state.Running, s)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&serverDriver.maxRunning))
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/version"
 )
@@ -210,6 +211,12 @@ func (r *RPCServerDriver) Restart(_ *struct{}, _ *struct{}) error {
 }
 
 func (r *RPCServerDriver) SetConfigFromFlags(flags *drivers.DriverOptions, _ *struct{}) error {
+	// The flags of the driver are only complete, with their validation
+	// functions, in the process of the driver
+	if err := drivers.ValidateFlags(r.ActualDriver.GetCreateFlags(), *flags); err != nil {
+		return err
+	}
+
 	return r.ActualDriver.SetConfigFromFlags(*flags)
 }
 
@@ -225,3 +232,210 @@ func (r *RPCServerDriver) Heartbeat(_ *struct{}, _ *struct{}) error {
 	r.HeartbeatCh <- true
 	return nil
 }
+
+func (r *RPCServerDriver) CreateClone(sourceConfig []byte, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.CreateClone(r.ActualDriver, sourceConfig)
+}
+
+func (r *RPCServerDriver) GetIPs(_ *struct{}, reply *[]string) (err error) {
+	defer trapPanic(&err)
+
+	m, ok := r.ActualDriver.(drivers.MultiAddresser)
+	if !ok {
+		return drivers.ErrNotImplemented
+	}
+
+	ips, err := m.GetIPs()
+	*reply = ips
+	return err
+}
+
+func (r *RPCServerDriver) SetNetworkOptions(opts drivers.NetworkOptions, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetNetworkOptions(r.ActualDriver, opts)
+}
+
+func (r *RPCServerDriver) SetInstanceOptions(opts drivers.InstanceOptions, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetInstanceOptions(r.ActualDriver, opts)
+}
+
+func (r *RPCServerDriver) Preempted(_ *struct{}, reply *bool) (err error) {
+	defer trapPanic(&err)
+
+	preempted, err := drivers.Preempted(r.ActualDriver)
+	*reply = preempted
+	return err
+}
+
+func (r *RPCServerDriver) GetPrivateIP(_ *struct{}, reply *string) (err error) {
+	defer trapPanic(&err)
+
+	ip, err := drivers.GetPrivateIP(r.ActualDriver)
+	*reply = ip
+	return err
+}
+
+func (r *RPCServerDriver) SetAutostart(enabled bool, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetAutostart(r.ActualDriver, enabled)
+}
+
+func (r *RPCServerDriver) InstallSSHKey(keyPath string, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.InstallSSHKey(r.ActualDriver, keyPath)
+}
+
+func (r *RPCServerDriver) GetStats(_ *struct{}, reply *drivers.Stats) (err error) {
+	defer trapPanic(&err)
+
+	stats, err := drivers.GetStats(r.ActualDriver)
+	if stats != nil {
+		*reply = *stats
+	}
+	return err
+}
+
+func (r *RPCServerDriver) SetGPUOptions(opts drivers.GPUOptions, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetGPUOptions(r.ActualDriver, opts)
+}
+
+func (r *RPCServerDriver) GetConsoleOutput(_ *struct{}, reply *string) (err error) {
+	defer trapPanic(&err)
+
+	output, err := drivers.GetConsoleOutput(r.ActualDriver)
+	*reply = output
+	return err
+}
+
+func (r *RPCServerDriver) InstanceExists(_ *struct{}, reply *bool) (err error) {
+	defer trapPanic(&err)
+
+	exists, err := drivers.InstanceExists(r.ActualDriver)
+	*reply = exists
+	return err
+}
+
+func (r *RPCServerDriver) SetResourceTags(tags map[string]string, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetResourceTags(r.ActualDriver, tags)
+}
+
+func (r *RPCServerDriver) SetFirewallRules(rules []drivers.FirewallRule, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetFirewallRules(r.ActualDriver, rules)
+}
+
+func (r *RPCServerDriver) OpenPort(rule drivers.FirewallRule, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.OpenPort(r.ActualDriver, rule)
+}
+
+func (r *RPCServerDriver) ClosePort(rule drivers.FirewallRule, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.ClosePort(r.ActualDriver, rule)
+}
+
+func (r *RPCServerDriver) InstanceReady(_ *struct{}, reply *bool) (err error) {
+	defer trapPanic(&err)
+
+	ready, err := drivers.InstanceReady(r.ActualDriver)
+	*reply = ready
+	return err
+}
+
+func (r *RPCServerDriver) SetPlacement(placement drivers.Placement, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetPlacement(r.ActualDriver, placement)
+}
+
+func (r *RPCServerDriver) GetPlacement(_ *struct{}, reply *drivers.Placement) (err error) {
+	defer trapPanic(&err)
+
+	placement, err := drivers.GetPlacement(r.ActualDriver)
+	*reply = placement
+	return err
+}
+
+func (r *RPCServerDriver) CheckPlacement(_ *struct{}, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.CheckPlacement(r.ActualDriver)
+}
+
+func (r *RPCServerDriver) GetNameConstraints(_ *struct{}, reply *drivers.NameConstraints) (err error) {
+	defer trapPanic(&err)
+
+	constraints, err := drivers.GetNameConstraints(r.ActualDriver)
+	*reply = constraints
+	return err
+}
+
+func (r *RPCServerDriver) CheckNameAvailable(_ *struct{}, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.CheckNameAvailable(r.ActualDriver)
+}
+
+func (r *RPCServerDriver) SetSize(size drivers.Size, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetSize(r.ActualDriver, size)
+}
+
+func (r *RPCServerDriver) GetSize(_ *struct{}, reply *drivers.Size) (err error) {
+	defer trapPanic(&err)
+
+	size, err := drivers.GetSize(r.ActualDriver)
+	*reply = size
+	return err
+}
+
+func (r *RPCServerDriver) GetSensitiveFields(_ *struct{}, reply *[]string) (err error) {
+	defer trapPanic(&err)
+
+	fields, err := drivers.GetSensitiveFields(r.ActualDriver)
+	*reply = fields
+	return err
+}
+
+func (r *RPCServerDriver) DiscoverInstances(_ *struct{}, reply *[]drivers.DiscoveredInstance) (err error) {
+	defer trapPanic(&err)
+
+	instances, err := drivers.DiscoverInstances(r.ActualDriver)
+	*reply = instances
+	return err
+}
+
+func (r *RPCServerDriver) AdoptInstance(instance drivers.DiscoveredInstance, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.AdoptInstance(r.ActualDriver, instance)
+}
+
+func (r *RPCServerDriver) SetSSHBastion(bastion ssh.Bastion, _ *struct{}) (err error) {
+	defer trapPanic(&err)
+
+	return drivers.SetSSHBastion(r.ActualDriver, bastion)
+}
+
+func (r *RPCServerDriver) GetSSHBastion(_ *struct{}, reply *ssh.Bastion) (err error) {
+	defer trapPanic(&err)
+
+	bastion, err := drivers.GetSSHBastion(r.ActualDriver)
+	*reply = bastion
+	return err
+}
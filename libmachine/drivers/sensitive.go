@@ -0,0 +1,21 @@
+package drivers
+
+// SensitiveFielder is implemented by the drivers with secrets, e.g. API
+// tokens or passwords, in configuration fields other than the well known
+// ones of secrets.SensitiveFields, for them to be redacted from the output
+// of inspect and from the logs too.
+type SensitiveFielder interface {
+	// GetSensitiveFields returns the names of the fields of the serialized
+	// driver holding secrets.
+	GetSensitiveFields() ([]string, error)
+}
+
+// GetSensitiveFields returns the fields holding secrets the driver declares,
+// or returns ErrNotImplemented.
+func GetSensitiveFields(d Driver) ([]string, error) {
+	if s, ok := d.(SensitiveFielder); ok {
+		return s.GetSensitiveFields()
+	}
+
+	return nil, ErrNotImplemented
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 
 	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 )
 
@@ -170,3 +171,214 @@ func (d *SerialDriver) Stop() error {
 func (d *SerialDriver) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Driver)
 }
+
+// CreateClone creates the machine as a copy of another one, if supported
+func (d *SerialDriver) CreateClone(sourceConfig []byte) error {
+	d.Lock()
+	defer d.Unlock()
+	return CreateClone(d.Driver, sourceConfig)
+}
+
+// GetIPs returns all the addresses of the machine
+func (d *SerialDriver) GetIPs() ([]string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetIPs(d.Driver)
+}
+
+// SetNetworkOptions records the network options of the machine, if supported
+func (d *SerialDriver) SetNetworkOptions(opts NetworkOptions) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetNetworkOptions(d.Driver, opts)
+}
+
+// SetInstanceOptions records the instance options of the machine, if supported
+func (d *SerialDriver) SetInstanceOptions(opts InstanceOptions) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetInstanceOptions(d.Driver, opts)
+}
+
+// Preempted tells whether the machine was reclaimed by the provider, if known
+func (d *SerialDriver) Preempted() (bool, error) {
+	d.Lock()
+	defer d.Unlock()
+	return Preempted(d.Driver)
+}
+
+// GetPrivateIP returns the private address of the machine, if supported
+func (d *SerialDriver) GetPrivateIP() (string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetPrivateIP(d.Driver)
+}
+
+// SetAutostart has the hypervisor start the machine on boot, if supported
+func (d *SerialDriver) SetAutostart(enabled bool) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetAutostart(d.Driver, enabled)
+}
+
+// InstallSSHKey authorizes a new key through the provider, if supported
+func (d *SerialDriver) InstallSSHKey(keyPath string) error {
+	d.Lock()
+	defer d.Unlock()
+	return InstallSSHKey(d.Driver, keyPath)
+}
+
+// GetStats returns the resource usage reported by the provider, if supported
+func (d *SerialDriver) GetStats() (*Stats, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetStats(d.Driver)
+}
+
+// SetGPUOptions records the GPU options of the machine, if supported
+func (d *SerialDriver) SetGPUOptions(opts GPUOptions) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetGPUOptions(d.Driver, opts)
+}
+
+// GetConsoleOutput returns the console output of the machine, if supported
+func (d *SerialDriver) GetConsoleOutput() (string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetConsoleOutput(d.Driver)
+}
+
+// InstanceExists tells whether the instance of the machine exists, if known
+func (d *SerialDriver) InstanceExists() (bool, error) {
+	d.Lock()
+	defer d.Unlock()
+	return InstanceExists(d.Driver)
+}
+
+// SetResourceTags records the tags of the resources of the machine, if supported
+func (d *SerialDriver) SetResourceTags(tags map[string]string) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetResourceTags(d.Driver, tags)
+}
+
+// SetFirewallRules records the rules to open at creation, if supported
+func (d *SerialDriver) SetFirewallRules(rules []FirewallRule) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetFirewallRules(d.Driver, rules)
+}
+
+// OpenPort opens a port of the machine, if supported
+func (d *SerialDriver) OpenPort(rule FirewallRule) error {
+	d.Lock()
+	defer d.Unlock()
+	return OpenPort(d.Driver, rule)
+}
+
+// ClosePort closes a port of the machine, if supported
+func (d *SerialDriver) ClosePort(rule FirewallRule) error {
+	d.Lock()
+	defer d.Unlock()
+	return ClosePort(d.Driver, rule)
+}
+
+// InstanceReady tells whether the provider reports the instance booted, if
+// supported
+func (d *SerialDriver) InstanceReady() (bool, error) {
+	d.Lock()
+	defer d.Unlock()
+	return InstanceReady(d.Driver)
+}
+
+// SetPlacement records the region and zone of the machine, if supported
+func (d *SerialDriver) SetPlacement(placement Placement) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetPlacement(d.Driver, placement)
+}
+
+// GetPlacement returns the region and zone of the machine, if supported
+func (d *SerialDriver) GetPlacement() (Placement, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetPlacement(d.Driver)
+}
+
+// CheckPlacement checks the region and zone with the provider, if supported
+func (d *SerialDriver) CheckPlacement() error {
+	d.Lock()
+	defer d.Unlock()
+	return CheckPlacement(d.Driver)
+}
+
+// GetNameConstraints returns the restrictions on the machine names, if any
+func (d *SerialDriver) GetNameConstraints() (NameConstraints, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetNameConstraints(d.Driver)
+}
+
+// CheckNameAvailable checks that the name is not in use, if supported
+func (d *SerialDriver) CheckNameAvailable() error {
+	d.Lock()
+	defer d.Unlock()
+	return CheckNameAvailable(d.Driver)
+}
+
+// SetSize records the memory and disk of the machine, if supported
+func (d *SerialDriver) SetSize(size Size) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetSize(d.Driver, size)
+}
+
+// GetSize returns the memory and disk of the machine, if supported
+func (d *SerialDriver) GetSize() (Size, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetSize(d.Driver)
+}
+
+// GetSensitiveFields returns the fields holding secrets, if declared
+func (d *SerialDriver) GetSensitiveFields() ([]string, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetSensitiveFields(d.Driver)
+}
+
+// DiscoverInstances lists the instances tagged as docker hosts, if supported
+func (d *SerialDriver) DiscoverInstances() ([]DiscoveredInstance, error) {
+	d.Lock()
+	defer d.Unlock()
+	return DiscoverInstances(d.Driver)
+}
+
+// AdoptInstance sets the driver up to manage the instance, if supported
+func (d *SerialDriver) AdoptInstance(instance DiscoveredInstance) error {
+	d.Lock()
+	defer d.Unlock()
+	return AdoptInstance(d.Driver, instance)
+}
+
+// SetSSHBastion records the bastion SSH connections go through, if supported
+func (d *SerialDriver) SetSSHBastion(bastion ssh.Bastion) error {
+	d.Lock()
+	defer d.Unlock()
+	return SetSSHBastion(d.Driver, bastion)
+}
+
+// GetSSHBastion returns the bastion SSH connections go through, if supported
+func (d *SerialDriver) GetSSHBastion() (ssh.Bastion, error) {
+	d.Lock()
+	defer d.Unlock()
+	return GetSSHBastion(d.Driver)
+}
+
+// KnownHosts returns where the host key of the machine is recorded
+func (d *SerialDriver) KnownHosts() ssh.KnownHosts {
+	d.Lock()
+	defer d.Unlock()
+	return KnownHostsOf(d.Driver)
+}
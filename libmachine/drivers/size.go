@@ -0,0 +1,69 @@
+package drivers
+
+import "fmt"
+
+// Size is the memory and the disk of a machine, in MB. The values left at
+// zero keep the defaults of the driver.
+type Size struct {
+	Memory int
+	Disk   int
+}
+
+// IsEmpty tells whether neither the memory nor the disk is set.
+func (s *Size) IsEmpty() bool {
+	return s == nil || (s.Memory == 0 && s.Disk == 0)
+}
+
+// Check returns an error if a value is set below its minimum, a minimum of
+// zero meaning no minimum.
+func (s Size) Check(min Size) error {
+	if s.Memory < 0 || s.Disk < 0 {
+		return fmt.Errorf("Invalid size: memory %d MB, disk %d MB", s.Memory, s.Disk)
+	}
+	if s.Memory != 0 && s.Memory < min.Memory {
+		return fmt.Errorf("The memory of %d MB is below the minimum of %d MB", s.Memory, min.Memory)
+	}
+	if s.Disk != 0 && s.Disk < min.Disk {
+		return fmt.Errorf("The disk of %d MB is below the minimum of %d MB", s.Disk, min.Disk)
+	}
+	return nil
+}
+
+// SizeGB converts a size in MB to GB, rounding up, for the providers which
+// size disks in GB.
+func SizeGB(mb int) int {
+	return (mb + 1023) / 1024
+}
+
+// Sizer is implemented by the drivers able to size the machines, for the
+// memory and disk to be set the same way with all of them. The drivers
+// translate the size to their own settings, e.g. to GB.
+type Sizer interface {
+	// SetSize validates and records the memory and disk of the machine. It
+	// is called before the machine is created.
+	SetSize(size Size) error
+
+	// GetSize returns the memory and disk the machine has, or is to be
+	// created with, zero for the ones the driver does not know.
+	GetSize() (Size, error)
+}
+
+// SetSize records the size of the machine if the driver supports it, or
+// returns ErrNotImplemented.
+func SetSize(d Driver, size Size) error {
+	if s, ok := d.(Sizer); ok {
+		return s.SetSize(size)
+	}
+
+	return ErrNotImplemented
+}
+
+// GetSize returns the size of the machine if the driver supports it, or
+// returns ErrNotImplemented.
+func GetSize(d Driver) (Size, error) {
+	if s, ok := d.(Sizer); ok {
+		return s.GetSize()
+	}
+
+	return Size{}, ErrNotImplemented
+}
@@ -0,0 +1,24 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeCheck(t *testing.T) {
+	min := Size{Memory: 512, Disk: 1024}
+
+	assert.NoError(t, Size{}.Check(min))
+	assert.NoError(t, Size{Memory: 2048}.Check(min))
+	assert.EqualError(t, Size{Memory: 256}.Check(min), "The memory of 256 MB is below the minimum of 512 MB")
+	assert.EqualError(t, Size{Disk: 512}.Check(min), "The disk of 512 MB is below the minimum of 1024 MB")
+	assert.Error(t, Size{Disk: -1}.Check(Size{}))
+}
+
+func TestSizeGB(t *testing.T) {
+	assert.Equal(t, 0, SizeGB(0))
+	assert.Equal(t, 1, SizeGB(1))
+	assert.Equal(t, 20, SizeGB(20480))
+	assert.Equal(t, 20, SizeGB(20000))
+}
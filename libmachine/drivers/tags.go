@@ -0,0 +1,37 @@
+package drivers
+
+import "fmt"
+
+const (
+	// CreatedByTag marks the cloud resources created by docker-machine,
+	// with the CreatedByTagValue value.
+	CreatedByTag      = "created-by"
+	CreatedByTagValue = "docker-machine"
+
+	// MachineNameTag holds the name of the machine a cloud resource was
+	// created for.
+	MachineNameTag = "docker-machine-name"
+)
+
+// ResourceTags returns the tags marking the cloud resources created for a
+// machine.
+func ResourceTags(machineName string) map[string]string {
+	return map[string]string{
+		CreatedByTag:   CreatedByTagValue,
+		MachineNameTag: machineName,
+	}
+}
+
+// CheckResourceTags returns an error unless the tags of a resource include
+// the expected ones, so that removing a machine never deletes a resource
+// which was not created for it, e.g. after its ID was reused or the
+// configuration copied from another machine.
+func CheckResourceTags(resource string, expected, actual map[string]string) error {
+	for key, value := range expected {
+		if actual[key] != value {
+			return fmt.Errorf("Refusing to delete %s: it is not tagged %s=%s, it may not have been created for this machine", resource, key, value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceTags(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"created-by":          "docker-machine",
+		"docker-machine-name": "default",
+	}, ResourceTags("default"))
+}
+
+func TestCheckResourceTags(t *testing.T) {
+	expected := ResourceTags("default")
+
+	assert.NoError(t, CheckResourceTags("instance", expected, map[string]string{
+		"created-by":          "docker-machine",
+		"docker-machine-name": "default",
+		"team":                "infra",
+	}))
+	assert.NoError(t, CheckResourceTags("instance", nil, nil))
+
+	err := CheckResourceTags("instance i-1234", expected, map[string]string{
+		"created-by":          "docker-machine",
+		"docker-machine-name": "other",
+	})
+	assert.EqualError(t, err, "Refusing to delete instance i-1234: it is not tagged docker-machine-name=default, it may not have been created for this machine")
+	assert.Error(t, CheckResourceTags("instance", expected, nil))
+}
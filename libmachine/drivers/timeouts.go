@@ -0,0 +1,127 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultQueryTimeout = time.Minute
+
+// CallTimeouts are how long to wait for a driver to answer its calls, keyed
+// by the name of the method, e.g. "GetState". Calls without a timeout wait
+// as long as the driver takes.
+type CallTimeouts map[string]time.Duration
+
+// ErrCallTimeout is returned when a driver did not answer a call in time.
+type ErrCallTimeout struct {
+	Driver  string
+	Call    string
+	Timeout time.Duration
+}
+
+func (e ErrCallTimeout) Error() string {
+	return fmt.Sprintf("The %s driver did not answer %s within %s", e.Driver, e.Call, e.Timeout)
+}
+
+// DefaultCallTimeouts bound the calls which query the provider about a
+// machine, so that an unresponsive provider does not block the operations
+// on the machine forever. Creating, starting or removing a machine may take
+// a long time and is not bounded.
+var DefaultCallTimeouts = CallTimeouts{
+	"GetState":           defaultQueryTimeout,
+	"GetURL":             defaultQueryTimeout,
+	"GetIP":              defaultQueryTimeout,
+	"GetIPs":             defaultQueryTimeout,
+	"GetPrivateIP":       defaultQueryTimeout,
+	"GetSSHHostname":     defaultQueryTimeout,
+	"Preempted":          defaultQueryTimeout,
+	"GetStats":           defaultQueryTimeout,
+	"GetConsoleOutput":   defaultQueryTimeout,
+	"InstanceExists":     defaultQueryTimeout,
+	"InstanceReady":      defaultQueryTimeout,
+	"GetPlacement":       defaultQueryTimeout,
+	"CheckPlacement":     defaultQueryTimeout,
+	"CheckNameAvailable": defaultQueryTimeout,
+}
+
+// driverCallTimeouts are the defaults of the drivers the provider of which
+// is known to answer slowly, guarded by driverCallTimeoutsLock.
+var driverCallTimeouts = map[string]CallTimeouts{
+	"azure": {
+		"GetState": 3 * time.Minute,
+		"GetIP":    3 * time.Minute,
+		"GetURL":   3 * time.Minute,
+	},
+}
+
+var driverCallTimeoutsLock sync.RWMutex
+
+// SetDriverCallTimeouts sets the default timeouts of the calls to the given
+// driver, on top of DefaultCallTimeouts.
+func SetDriverCallTimeouts(driverName string, timeouts CallTimeouts) {
+	copied := CallTimeouts{}
+	for call, timeout := range timeouts {
+		copied[call] = timeout
+	}
+
+	driverCallTimeoutsLock.Lock()
+	defer driverCallTimeoutsLock.Unlock()
+
+	driverCallTimeouts[driverName] = copied
+}
+
+// CallTimeoutsFor returns the timeouts of the calls to the given driver:
+// DefaultCallTimeouts, overridden by the defaults of the driver, overridden
+// by the given overrides. A zero override removes the timeout of the call.
+func CallTimeoutsFor(driverName string, overrides CallTimeouts) CallTimeouts {
+	driverCallTimeoutsLock.RLock()
+	driverTimeouts := driverCallTimeouts[driverName]
+	driverCallTimeoutsLock.RUnlock()
+
+	timeouts := CallTimeouts{}
+	for _, layer := range []CallTimeouts{DefaultCallTimeouts, driverTimeouts, overrides} {
+		for call, timeout := range layer {
+			timeouts[call] = timeout
+		}
+	}
+
+	for call, timeout := range timeouts {
+		if timeout <= 0 {
+			delete(timeouts, call)
+		}
+	}
+
+	return timeouts
+}
+
+// ParseCallTimeouts parses timeouts given as <call>=<duration>, e.g.
+// GetState=30s, or as a bare duration setting the timeout of all the calls
+// of DefaultCallTimeouts.
+func ParseCallTimeouts(values []string) (CallTimeouts, error) {
+	timeouts := CallTimeouts{}
+
+	for _, value := range values {
+		call, duration := "", value
+		if i := strings.Index(value, "="); i >= 0 {
+			call, duration = value[:i], value[i+1:]
+		}
+
+		timeout, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid driver call timeout %q: %s", value, err)
+		}
+
+		if call != "" {
+			timeouts[call] = timeout
+			continue
+		}
+
+		for call := range DefaultCallTimeouts {
+			timeouts[call] = timeout
+		}
+	}
+
+	return timeouts, nil
+}
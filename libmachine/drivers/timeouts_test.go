@@ -0,0 +1,45 @@
+package drivers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallTimeoutsFor(t *testing.T) {
+	defer func() {
+		driverCallTimeoutsLock.Lock()
+		delete(driverCallTimeouts, "slow")
+		driverCallTimeoutsLock.Unlock()
+	}()
+	SetDriverCallTimeouts("slow", CallTimeouts{
+		"GetState": 5 * time.Minute,
+		"Create":   time.Hour,
+	})
+
+	timeouts := CallTimeoutsFor("slow", CallTimeouts{
+		"Create": 2 * time.Hour,
+		"GetIP":  0,
+	})
+
+	assert.Equal(t, 5*time.Minute, timeouts["GetState"])
+	assert.Equal(t, 2*time.Hour, timeouts["Create"])
+	assert.Equal(t, defaultQueryTimeout, timeouts["GetURL"])
+	assert.NotContains(t, timeouts, "GetIP")
+}
+
+func TestParseCallTimeouts(t *testing.T) {
+	timeouts, err := ParseCallTimeouts([]string{"10s", "GetState=30s"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeouts["GetState"])
+	assert.Equal(t, 10*time.Second, timeouts["GetURL"])
+	assert.NotContains(t, timeouts, "Create")
+}
+
+func TestParseCallTimeoutsInvalid(t *testing.T) {
+	_, err := ParseCallTimeouts([]string{"GetState=soon"})
+
+	assert.Error(t, err)
+}
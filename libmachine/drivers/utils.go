@@ -2,6 +2,7 @@ package drivers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnutils"
@@ -19,13 +20,12 @@ func GetSSHClientFromDriver(d Driver) (ssh.Client, error) {
 		return nil, err
 	}
 
-	var auth *ssh.Auth
-	if d.GetSSHKeyPath() == "" {
-		auth = &ssh.Auth{}
-	} else {
-		auth = &ssh.Auth{
-			Keys: []string{d.GetSSHKeyPath()},
-		}
+	auth := &ssh.Auth{
+		KnownHosts: KnownHostsOf(d),
+		Bastion:    ResolveSSHBastion(d),
+	}
+	if d.GetSSHKeyPath() != "" {
+		auth.Keys = []string{d.GetSSHKeyPath()}
 	}
 
 	client, err := ssh.NewClient(d.GetSSHUsername(), address, port, auth)
@@ -64,10 +64,56 @@ func sshAvailableFunc(d Driver) func() bool {
 	}
 }
 
+var (
+	// instanceReadyAttempts and instanceReadyInterval bound the wait for the
+	// provider to report the instance booted, after which SSH is dialed
+	// anyway.
+	instanceReadyAttempts = 200
+	instanceReadyInterval = 3 * time.Second
+)
+
+// WaitForSSH waits for commands to run over SSH. When the provider reports
+// the boot of the instances, see ReadinessReporter, SSH is only dialed once
+// the instance booted: a slow boot does not exhaust the retries, and SSH
+// failing afterwards points at a firewall rather than at the boot.
 func WaitForSSH(d Driver) error {
+	providerReady, err := waitForInstanceReady(d)
+	if err != nil {
+		return err
+	}
+
 	// Try to dial SSH for 30 seconds before timing out.
 	if err := mcnutils.WaitFor(sshAvailableFunc(d)); err != nil {
+		if providerReady {
+			return fmt.Errorf("Too many retries waiting for SSH to be available although the provider reports the instance booted, check that the firewall lets port 22 through.  Last error: %s", err)
+		}
 		return fmt.Errorf("Too many retries waiting for SSH to be available.  Last error: %s", err)
 	}
 	return nil
 }
+
+// waitForInstanceReady waits for the provider to report the instance booted
+// and tells whether it did. It returns an error only when the provider
+// reports that the boot failed.
+func waitForInstanceReady(d Driver) (bool, error) {
+	for i := 0; i < instanceReadyAttempts; i++ {
+		ready, err := InstanceReady(d)
+		if err == ErrNotImplemented {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if ready {
+			return true, nil
+		}
+
+		if i == 0 {
+			log.Info("Waiting for the provider to report the instance booted...")
+		}
+		time.Sleep(instanceReadyInterval)
+	}
+
+	log.Debugf("The provider did not report the instance booted after %d attempts, dialing SSH anyway", instanceReadyAttempts)
+	return false, nil
+}
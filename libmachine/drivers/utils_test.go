@@ -0,0 +1,78 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type readinessDriver struct {
+	*MockDriver
+	answers []bool
+	err     error
+	asked   int
+}
+
+func (d *readinessDriver) InstanceReady() (bool, error) {
+	d.asked++
+	if d.err != nil {
+		return false, d.err
+	}
+	if d.asked > len(d.answers) {
+		return false, nil
+	}
+	return d.answers[d.asked-1], nil
+}
+
+func withInstanceReadyAttempts(attempts int, f func()) {
+	defer func(attempts int, interval time.Duration) {
+		instanceReadyAttempts, instanceReadyInterval = attempts, interval
+	}(instanceReadyAttempts, instanceReadyInterval)
+
+	instanceReadyAttempts, instanceReadyInterval = attempts, time.Millisecond
+	f()
+}
+
+func TestWaitForInstanceReady(t *testing.T) {
+	withInstanceReadyAttempts(5, func() {
+		driver := &readinessDriver{MockDriver: &MockDriver{}, answers: []bool{false, false, true}}
+
+		ready, err := waitForInstanceReady(driver)
+
+		assert.NoError(t, err)
+		assert.True(t, ready)
+		assert.Equal(t, 3, driver.asked)
+	})
+}
+
+func TestWaitForInstanceReadyGivesUp(t *testing.T) {
+	withInstanceReadyAttempts(3, func() {
+		driver := &readinessDriver{MockDriver: &MockDriver{}}
+
+		ready, err := waitForInstanceReady(driver)
+
+		assert.NoError(t, err)
+		assert.False(t, ready)
+		assert.Equal(t, 3, driver.asked)
+	})
+}
+
+func TestWaitForInstanceReadyBootFailed(t *testing.T) {
+	withInstanceReadyAttempts(3, func() {
+		driver := &readinessDriver{MockDriver: &MockDriver{}, err: errors.New("boot failed")}
+
+		_, err := waitForInstanceReady(driver)
+
+		assert.EqualError(t, err, "boot failed")
+		assert.Equal(t, 1, driver.asked)
+	})
+}
+
+func TestWaitForInstanceReadyNotImplemented(t *testing.T) {
+	ready, err := waitForInstanceReady(&MockDriver{})
+
+	assert.NoError(t, err)
+	assert.False(t, ready)
+}
@@ -0,0 +1,62 @@
+package drivers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/mcnflag"
+)
+
+// InvalidFlagsError lists the create flags whose values do not satisfy the
+// constraints the driver declares on them.
+type InvalidFlagsError struct {
+	Errors []error
+}
+
+func (e *InvalidFlagsError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, "  "+err.Error())
+	}
+	return fmt.Sprintf("Invalid driver options:\n%s", strings.Join(msgs, "\n"))
+}
+
+// ValidateFlags checks the values of the create flags against the
+// constraints they declare, so that the driver is only configured, and a
+// machine only created, with valid options.
+func ValidateFlags(flags []mcnflag.Flag, opts DriverOptions) error {
+	errs := []error{}
+
+	for _, flag := range flags {
+		checker, ok := flag.(mcnflag.Checker)
+		if !ok {
+			continue
+		}
+
+		var value interface{}
+		switch flag.Default().(type) {
+		case string:
+			value = opts.String(flag.String())
+		case []string:
+			value = opts.StringSlice(flag.String())
+		case int:
+			value = opts.Int(flag.String())
+		default:
+			continue
+		}
+
+		if err := checker.Check(value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &InvalidFlagsError{Errors: errs}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package drivers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/stretchr/testify/assert"
+)
+
+var validatedFlags = []mcnflag.Flag{
+	mcnflag.StringFlag{
+		Name:     "token",
+		Required: true,
+	},
+	mcnflag.StringFlag{
+		Name:    "region",
+		Value:   "par1",
+		Choices: []string{"par1", "ams1"},
+	},
+	mcnflag.StringFlag{
+		Name: "image",
+		Validate: func(value string) error {
+			if value == "windows" {
+				return errors.New("not supported")
+			}
+			return nil
+		},
+	},
+	mcnflag.IntFlag{
+		Name:     "cpu-count",
+		Value:    1,
+		Validate: mcnflag.IntRange(1, 32),
+	},
+	mcnflag.StringSliceFlag{
+		Name:     "tags",
+		Required: true,
+	},
+	mcnflag.BoolFlag{
+		Name: "ipv6",
+	},
+}
+
+func TestValidateFlags(t *testing.T) {
+	err := ValidateFlags(validatedFlags, &CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"token": "TOKEN",
+			"tags":  []string{"docker"},
+		},
+		CreateFlags: validatedFlags,
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateFlagsRequired(t *testing.T) {
+	err := ValidateFlags(validatedFlags, &CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"tags": []string{"docker"},
+		},
+		CreateFlags: validatedFlags,
+	})
+
+	assert.EqualError(t, err, "--token is required")
+}
+
+func TestValidateFlagsReportsAllErrors(t *testing.T) {
+	err := ValidateFlags(validatedFlags, &CheckDriverOptions{
+		FlagsValues: map[string]interface{}{
+			"token":     "TOKEN",
+			"region":    "nyc1",
+			"image":     "windows",
+			"cpu-count": 64,
+		},
+		CreateFlags: validatedFlags,
+	})
+
+	assert.EqualError(t, err, `Invalid driver options:
+  invalid value "nyc1" for --region, must be one of par1, ams1
+  invalid value "windows" for --image: not supported
+  invalid value 64 for --cpu-count: must be between 1 and 32
+  --tags is required`)
+}
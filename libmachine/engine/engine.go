@@ -5,17 +5,50 @@ const (
 )
 
 type Options struct {
-	ArbitraryFlags   []string
-	DNS              []string `json:"Dns"`
-	GraphDir         string
-	Env              []string
-	Ipv6             bool
-	InsecureRegistry []string
-	Labels           []string
-	LogLevel         string
-	StorageDriver    string
-	SelinuxEnabled   bool
-	TLSVerify        bool `json:"TlsVerify"`
-	RegistryMirror   []string
-	InstallURL       string
+	ArbitraryFlags       []string
+	DNS                  []string `json:"Dns"`
+	GraphDir             string
+	Env                  []string
+	Ipv6                 bool
+	InsecureRegistry     []string
+	Labels               []string
+	LogLevel             string
+	StorageDriver        string
+	StorageDevice        string
+	SelinuxEnabled       bool
+	TLSVerify            bool `json:"TlsVerify"`
+	RegistryMirror       []string
+	InstallURL           string
+	InstallVersion       string
+	InstallBundle        string
+	AssetCacheDir        string
+	HTTPProxy            string
+	HTTPSProxy           string
+	NoProxy              string
+	ClusterStore         string
+	ClusterAdvertise     string
+	ClusterStoreOpts     []string
+	AuthorizationPlugins []string
+	LogDriver            string
+	LogOpts              []string
+	LiveRestore          bool
+	CgroupDriver         string
+	GPURuntime           bool
+
+	// Port is the TCP port the daemon listens on with TLS, DefaultPort
+	// when zero.
+	Port int `json:",omitempty"`
+
+	// DisableUnixSocket has the daemon listen on the TCP port only, not on
+	// /var/run/docker.sock too. Boot2Docker and RancherOS always keep the
+	// socket.
+	DisableUnixSocket bool `json:",omitempty"`
+}
+
+// DaemonPort returns the TCP port the daemon listens on.
+func (o *Options) DaemonPort() int {
+	if o == nil || o.Port == 0 {
+		return DefaultPort
+	}
+	return o.Port
 }
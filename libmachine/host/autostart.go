@@ -0,0 +1,31 @@
+package host
+
+import (
+	"github.com/docker/machine/libmachine/autostart"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// SetAutostart enables or disables starting the machine when the host OS
+// boots. The hypervisor starts the machine when its driver supports it, and
+// the service manager of the host OS runs docker-machine start otherwise. The
+// latter needs the path of the store the machine is saved in. The host must
+// be saved afterwards to remember the setting.
+func (h *Host) SetAutostart(enabled bool, storePath string) error {
+	err := drivers.SetAutostart(h.Driver, enabled)
+	if err == drivers.ErrNotImplemented {
+		log.Debugf("The %s driver cannot autostart machines, registering %q with the OS", h.DriverName, h.Name)
+		if enabled {
+			err = autostart.Enable(h.Name, storePath)
+		} else {
+			err = autostart.Disable(h.Name, storePath)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	h.HostOptions.Autostart = enabled
+
+	return nil
+}
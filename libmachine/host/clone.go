@@ -0,0 +1,70 @@
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+)
+
+// CloneDriverConfig returns the driver configuration of the host adapted for
+// a new machine named newName. The IP addresses are reset and SSH keys kept
+// in the machine directory are dropped so that the driver generates a new
+// pair for the clone.
+func (h *Host) CloneDriverConfig(newName string) ([]byte, error) {
+	data, err := json.Marshal(h.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers are kept as they are, large integers such as project IDs do
+	// not survive a float64
+	config := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return nil, err
+	}
+
+	storePath, _ := config["StorePath"].(string)
+	sourceDir := filepath.Join(storePath, "machines", h.Name)
+
+	if keyPath, ok := config["SSHKeyPath"].(string); ok && strings.HasPrefix(keyPath, sourceDir) {
+		config["SSHKeyPath"] = ""
+	}
+
+	config["MachineName"] = newName
+	config["IPAddress"] = ""
+	if _, ok := config["StaticIP"]; ok {
+		config["StaticIP"] = ""
+	}
+
+	return json.Marshal(config)
+}
+
+// CloneOptions returns a deep copy of the host options, with the paths of
+// the per-machine certificates pointing to machineDir. A static IP is not
+// copied since two machines cannot share it.
+func (h *Host) CloneOptions(machineDir string) (*Options, error) {
+	data, err := json.Marshal(h.HostOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &Options{}
+	if err := json.Unmarshal(data, options); err != nil {
+		return nil, err
+	}
+
+	if options.AuthOptions != nil {
+		options.AuthOptions.ServerCertPath = filepath.Join(machineDir, "server.pem")
+		options.AuthOptions.ServerKeyPath = filepath.Join(machineDir, "server-key.pem")
+		options.AuthOptions.StorePath = machineDir
+	}
+
+	if options.NetworkOptions != nil {
+		options.NetworkOptions.StaticIP = ""
+	}
+
+	return options, nil
+}
@@ -0,0 +1,110 @@
+package host
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloneDriverConfig(t *testing.T) {
+	h := &Host{
+		Name: "source",
+		Driver: &fakedriver.Driver{
+			BaseDriver: &drivers.BaseDriver{
+				MachineName: "source",
+				StorePath:   "/store",
+				IPAddress:   "1.2.3.4",
+				SSHKeyPath:  "/store/machines/source/id_rsa",
+				SSHUser:     "docker",
+			},
+		},
+	}
+
+	data, err := h.CloneDriverConfig("clone")
+	assert.NoError(t, err)
+
+	config := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &config))
+
+	assert.Equal(t, "clone", config["MachineName"])
+	assert.Equal(t, "/store", config["StorePath"])
+	assert.Equal(t, "", config["IPAddress"])
+	assert.Equal(t, "", config["SSHKeyPath"])
+	assert.Equal(t, "docker", config["SSHUser"])
+}
+
+type projectDriver struct {
+	*fakedriver.Driver
+	ProjectID int64
+}
+
+func TestCloneDriverConfigKeepsIntegers(t *testing.T) {
+	h := &Host{
+		Name: "source",
+		Driver: &projectDriver{
+			Driver:    &fakedriver.Driver{BaseDriver: &drivers.BaseDriver{MachineName: "source"}},
+			ProjectID: 123456789012345678,
+		},
+	}
+
+	data, err := h.CloneDriverConfig("clone")
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"ProjectID":123456789012345678`)
+}
+
+func TestCloneDriverConfigKeepsExternalKey(t *testing.T) {
+	h := &Host{
+		Name: "source",
+		Driver: &fakedriver.Driver{
+			BaseDriver: &drivers.BaseDriver{
+				MachineName: "source",
+				StorePath:   "/store",
+				SSHKeyPath:  "/home/user/.ssh/id_rsa",
+			},
+		},
+	}
+
+	data, err := h.CloneDriverConfig("clone")
+	assert.NoError(t, err)
+
+	config := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &config))
+
+	assert.Equal(t, "/home/user/.ssh/id_rsa", config["SSHKeyPath"])
+}
+
+func TestCloneOptions(t *testing.T) {
+	h := &Host{
+		Name: "source",
+		HostOptions: &Options{
+			EngineOptions: &engine.Options{
+				Labels: []string{"env=dev"},
+			},
+			AuthOptions: &auth.Options{
+				CaCertPath:     "/store/certs/ca.pem",
+				ServerCertPath: "/store/machines/source/server.pem",
+				ServerKeyPath:  "/store/machines/source/server-key.pem",
+				StorePath:      "/store/machines/source",
+			},
+		},
+	}
+
+	options, err := h.CloneOptions("/store/machines/clone")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/store/certs/ca.pem", options.AuthOptions.CaCertPath)
+	assert.Equal(t, "/store/machines/clone/server.pem", options.AuthOptions.ServerCertPath)
+	assert.Equal(t, "/store/machines/clone/server-key.pem", options.AuthOptions.ServerKeyPath)
+	assert.Equal(t, "/store/machines/clone", options.AuthOptions.StorePath)
+	assert.Equal(t, []string{"env=dev"}, options.EngineOptions.Labels)
+
+	options.EngineOptions.Labels[0] = "env=prod"
+	assert.Equal(t, "env=dev", h.HostOptions.EngineOptions.Labels[0])
+	assert.Equal(t, "/store/machines/source/server.pem", h.HostOptions.AuthOptions.ServerCertPath)
+}
@@ -0,0 +1,22 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// ConsoleOutput returns what the machine printed on its console while
+// booting, as read by the driver from the provider or the hypervisor. It
+// works when the machine cannot be reached over SSH.
+func (h *Host) ConsoleOutput() (string, error) {
+	output, err := drivers.GetConsoleOutput(h.Driver)
+	if err == drivers.ErrNotImplemented {
+		return "", fmt.Errorf("The %s driver cannot read the console output of %q", h.DriverName, h.Name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error reading the console output of %q: %s", h.Name, err)
+	}
+
+	return output, nil
+}
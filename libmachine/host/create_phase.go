@@ -0,0 +1,48 @@
+package host
+
+// CreatePhase is the last completed phase of the creation of a machine. It
+// is saved as the creation goes, so that an interrupted creation resumes
+// from there rather than creating the instance again.
+type CreatePhase string
+
+const (
+	// CreatePhasePending is set when the machine is first saved, before the
+	// driver creates the instance.
+	CreatePhasePending CreatePhase = "pending"
+
+	// CreatePhaseInstanceCreated is set once the driver created the
+	// instance.
+	CreatePhaseInstanceCreated CreatePhase = "instance-created"
+
+	// CreatePhaseSSHReady is set once the instance is running and reachable
+	// over SSH.
+	CreatePhaseSSHReady CreatePhase = "ssh-ready"
+
+	// CreatePhaseProvisioned is set once the engine is provisioned and
+	// the machine is ready to use.
+	CreatePhaseProvisioned CreatePhase = "provisioned"
+)
+
+var createPhaseOrder = map[CreatePhase]int{
+	CreatePhasePending:         1,
+	CreatePhaseInstanceCreated: 2,
+	CreatePhaseSSHReady:        3,
+	CreatePhaseProvisioned:     4,
+}
+
+// CreateReached reports whether the creation of the machine completed the
+// given phase. Machines created before the phases were recorded have none,
+// and have completed them all.
+func (h *Host) CreateReached(phase CreatePhase) bool {
+	if h.CreatePhase == "" {
+		return true
+	}
+
+	return createPhaseOrder[h.CreatePhase] >= createPhaseOrder[phase]
+}
+
+// CreateIncomplete reports whether the creation of the machine was
+// interrupted before it was provisioned.
+func (h *Host) CreateIncomplete() bool {
+	return !h.CreateReached(CreatePhaseProvisioned)
+}
@@ -0,0 +1,23 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReached(t *testing.T) {
+	h := &Host{CreatePhase: CreatePhaseInstanceCreated}
+
+	assert.True(t, h.CreateReached(CreatePhasePending))
+	assert.True(t, h.CreateReached(CreatePhaseInstanceCreated))
+	assert.False(t, h.CreateReached(CreatePhaseSSHReady))
+	assert.True(t, h.CreateIncomplete())
+}
+
+func TestCreateReachedWithoutPhase(t *testing.T) {
+	h := &Host{}
+
+	assert.True(t, h.CreateReached(CreatePhaseProvisioned))
+	assert.False(t, h.CreateIncomplete())
+}
@@ -0,0 +1,26 @@
+package host
+
+import (
+	"github.com/docker/machine/libmachine/autostart"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// Registry is the part of a store Deregister needs.
+type Registry interface {
+	// Remove removes a machine from the store
+	Remove(name string) error
+}
+
+// Deregister removes the machine from the store without removing its
+// instance, e.g. to hand it off to another tool or person. The OS no longer
+// starts a machine docker-machine does not manage on boot, which needs the
+// path of the store, as with SetAutostart.
+func (h *Host) Deregister(registry Registry, storePath string) error {
+	if h.HostOptions != nil && h.HostOptions.Autostart {
+		if err := autostart.Disable(h.Name, storePath); err != nil {
+			log.Warnf("Error unregistering %s from the autostart of the OS: %s", h.Name, err)
+		}
+	}
+
+	return registry.Remove(h.Name)
+}
@@ -0,0 +1,247 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// CheckStatus is the outcome of a check of Diagnose.
+type CheckStatus string
+
+const (
+	// CheckPassed is the status of a check which found nothing wrong.
+	CheckPassed CheckStatus = "passed"
+	// CheckFailed is the status of a check which found the machine drifting
+	// from its configuration.
+	CheckFailed CheckStatus = "failed"
+	// CheckSkipped is the status of a check which could not run because an
+	// earlier check failed.
+	CheckSkipped CheckStatus = "skipped"
+)
+
+// Check is the result of checking one aspect of a machine.
+type Check struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+
+	// RepairAction describes what Repair does to fix a failed check, empty
+	// when the check cannot be repaired automatically.
+	RepairAction string
+	repair       func() error
+}
+
+// Diagnosis reports how a machine drifted from what the store says of it.
+type Diagnosis struct {
+	Host   string
+	Checks []*Check
+}
+
+// Healthy tells whether all the checks passed.
+func (d *Diagnosis) Healthy() bool {
+	for _, check := range d.Checks {
+		if check.Status != CheckPassed {
+			return false
+		}
+	}
+	return true
+}
+
+// Repair runs the repair actions of the failed checks, stopping at the first
+// one which fails. Diagnose should be run again afterwards, as checks skipped
+// the first time may fail once the others are repaired.
+func (d *Diagnosis) Repair() error {
+	for _, check := range d.Checks {
+		if check.Status != CheckFailed || check.repair == nil {
+			continue
+		}
+
+		log.Infof("Repairing %s of %q: %s...", check.Name, d.Host, check.RepairAction)
+		if err := check.repair(); err != nil {
+			return fmt.Errorf("Error repairing %s of %q: %s", check.Name, d.Host, err)
+		}
+	}
+	return nil
+}
+
+// diagnosticChecks run in order. Each check is skipped when a previous one
+// failed, since they each rely on the previous ones.
+var diagnosticChecks = []struct {
+	name  string
+	check func(h *Host) *Check
+}{
+	{"instance", checkInstance},
+	{"certificates", checkCertificates},
+	{"engine options", checkEngineOptions},
+	{"swarm", checkSwarm},
+}
+
+// Diagnose checks for drift between the store and reality: that the
+// instance still exists at the provider and runs, that the certificates
+// match its address, that the daemon runs with the engine options and that
+// the swarm containers run.
+func (h *Host) Diagnose() *Diagnosis {
+	diagnosis := &Diagnosis{Host: h.Name}
+
+	failed := false
+	for _, c := range diagnosticChecks {
+		if failed {
+			diagnosis.Checks = append(diagnosis.Checks, &Check{
+				Name:   c.name,
+				Status: CheckSkipped,
+			})
+			continue
+		}
+
+		check := c.check(h)
+		check.Name = c.name
+		diagnosis.Checks = append(diagnosis.Checks, check)
+		failed = check.Status == CheckFailed
+	}
+
+	return diagnosis
+}
+
+func passed(format string, args ...interface{}) *Check {
+	return &Check{
+		Status:  CheckPassed,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func failed(message string, repairAction string, repair func() error) *Check {
+	return &Check{
+		Status:       CheckFailed,
+		Message:      message,
+		RepairAction: repairAction,
+		repair:       repair,
+	}
+}
+
+func checkInstance(h *Host) *Check {
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return failed(fmt.Sprintf("Unable to find the machine at the provider: %s", err), "", nil)
+	}
+
+	if s != state.Running {
+		return failed(fmt.Sprintf("The machine is %s", strings.ToLower(s.String())), "start the machine", h.Start)
+	}
+
+	return passed("The machine is running")
+}
+
+func checkCertificates(h *Host) *Check {
+	endpoint, err := h.Endpoint()
+	if err != nil {
+		return failed(fmt.Sprintf("Unable to get the URL of the machine: %s", err), "", nil)
+	}
+
+	if valid, err := cert.ValidateCertificate(endpoint.Address(), h.AuthOptions()); !valid || err != nil {
+		return failed(fmt.Sprintf("The certificates are not valid for %s: %v", endpoint.Address(), err), "regenerate the certificates", h.ConfigureAuth)
+	}
+
+	return passed("The certificates are valid for %s", endpoint.Address())
+}
+
+// dockerInfo is what matters to checkEngineOptions in `docker info`.
+type dockerInfo struct {
+	Driver         string
+	Labels         []string
+	RegistryConfig struct {
+		InsecureRegistryCIDRs []string
+		IndexConfigs          map[string]struct {
+			Secure bool
+		}
+		Mirrors []string
+	}
+}
+
+func checkEngineOptions(h *Host) *Check {
+	if h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return passed("No engine options to check")
+	}
+
+	command, err := h.dockerCommand("info --format '{{json .}}'")
+	if err != nil {
+		return failed(fmt.Sprintf("Unable to get the configuration of the daemon: %s", err), "", nil)
+	}
+
+	output, err := h.RunSSHCommand(command)
+	if err != nil {
+		return failed(fmt.Sprintf("Unable to get the configuration of the daemon: %s", err), "", nil)
+	}
+
+	info := dockerInfo{}
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		return failed(fmt.Sprintf("Unable to parse the configuration of the daemon: %s", err), "", nil)
+	}
+
+	if drift := engineOptionsDrift(h.HostOptions.EngineOptions, info); len(drift) > 0 {
+		return failed(strings.Join(drift, ", "), "reconfigure the daemon", h.ConfigureAuth)
+	}
+
+	return passed("The daemon runs with the engine options")
+}
+
+// engineOptionsDrift lists the engine options the daemon does not run with.
+func engineOptionsDrift(opts *engine.Options, info dockerInfo) []string {
+	drift := []string{}
+
+	if opts.StorageDriver != "" && opts.StorageDriver != info.Driver {
+		drift = append(drift, fmt.Sprintf("storage driver is %s instead of %s", info.Driver, opts.StorageDriver))
+	}
+
+	for _, label := range opts.Labels {
+		if !containsString(info.Labels, label) {
+			drift = append(drift, fmt.Sprintf("label %s is missing", label))
+		}
+	}
+
+	mirrors := []string{}
+	for _, mirror := range info.RegistryConfig.Mirrors {
+		mirrors = append(mirrors, strings.TrimSuffix(mirror, "/"))
+	}
+	for _, mirror := range opts.RegistryMirror {
+		if !containsString(mirrors, strings.TrimSuffix(mirror, "/")) {
+			drift = append(drift, fmt.Sprintf("registry mirror %s is missing", mirror))
+		}
+	}
+
+	for _, registry := range opts.InsecureRegistry {
+		index, ok := info.RegistryConfig.IndexConfigs[registry]
+		insecure := (ok && !index.Secure) || containsString(info.RegistryConfig.InsecureRegistryCIDRs, registry)
+		if !insecure {
+			drift = append(drift, fmt.Sprintf("insecure registry %s is missing", registry))
+		}
+	}
+
+	return drift
+}
+
+func checkSwarm(h *Host) *Check {
+	if h.HostOptions == nil || h.HostOptions.SwarmOptions == nil || !h.HostOptions.SwarmOptions.IsSwarm {
+		return passed("The machine is not part of a swarm")
+	}
+
+	if err := probeSwarm(h); err != nil {
+		return failed(fmt.Sprintf("The swarm is not running: %s", err), "provision the machine again", h.Provision)
+	}
+
+	return passed("The swarm containers are running")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
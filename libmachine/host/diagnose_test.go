@@ -0,0 +1,74 @@
+package host
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineOptionsDrift(t *testing.T) {
+	info := dockerInfo{}
+	err := json.Unmarshal([]byte(`{
+		"Driver": "overlay2",
+		"Labels": ["env=test"],
+		"RegistryConfig": {
+			"InsecureRegistryCIDRs": ["127.0.0.0/8"],
+			"IndexConfigs": {
+				"docker.io": {"Secure": true},
+				"registry.local:5000": {"Secure": false}
+			},
+			"Mirrors": ["https://mirror.local/"]
+		}
+	}`), &info)
+	assert.NoError(t, err)
+
+	opts := &engine.Options{
+		StorageDriver:    "overlay2",
+		Labels:           []string{"env=test"},
+		RegistryMirror:   []string{"https://mirror.local"},
+		InsecureRegistry: []string{"registry.local:5000", "127.0.0.0/8"},
+	}
+	assert.Empty(t, engineOptionsDrift(opts, info))
+
+	opts = &engine.Options{
+		StorageDriver:    "aufs",
+		Labels:           []string{"env=prod"},
+		InsecureRegistry: []string{"docker.io"},
+	}
+	assert.Equal(t, []string{
+		"storage driver is overlay2 instead of aufs",
+		"label env=prod is missing",
+		"insecure registry docker.io is missing",
+	}, engineOptionsDrift(opts, info))
+}
+
+func TestDiagnoseStoppedMachine(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provision.NewNetstatProvisioner(),
+	})
+
+	host := &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Stopped,
+		},
+	}
+
+	diagnosis := host.Diagnose()
+
+	assert.False(t, diagnosis.Healthy())
+	assert.Equal(t, CheckFailed, diagnosis.Checks[0].Status)
+	assert.Equal(t, "The machine is stopped", diagnosis.Checks[0].Message)
+	for _, check := range diagnosis.Checks[1:] {
+		assert.Equal(t, CheckSkipped, check.Status)
+	}
+
+	assert.NoError(t, diagnosis.Repair())
+	assert.Equal(t, state.Running, host.Driver.(*fakedriver.Driver).MockState)
+}
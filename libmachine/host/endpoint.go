@@ -0,0 +1,119 @@
+package host
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"strconv"
+)
+
+// Endpoint is the URL of the engine of a machine split in its parts, for
+// consumers to build the addresses they need without parsing the URL.
+type Endpoint struct {
+	// Scheme is the scheme of the URL, tcp for all the drivers.
+	Scheme string
+
+	// Host is the IP address or the hostname of the machine.
+	Host string
+
+	// Port is the port the engine listens on.
+	Port int
+
+	// TLS is set when the engine requires the client certificates.
+	TLS bool
+
+	// CertDir is the directory holding the CA certificate and the client
+	// certificate and key of the machine, named as DOCKER_CERT_PATH expects.
+	CertDir string
+}
+
+// ParseEndpoint splits a URL returned by URL.
+func ParseEndpoint(rawURL string, tls bool, certDir string) (*Endpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing URL %q: %s", rawURL, err)
+	}
+
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return nil, fmt.Errorf("The URL %q has no valid port", rawURL)
+	}
+
+	return &Endpoint{
+		Scheme:  u.Scheme,
+		Host:    u.Hostname(),
+		Port:    port,
+		TLS:     tls,
+		CertDir: certDir,
+	}, nil
+}
+
+// Endpoint returns the parts of the URL of the engine of the machine.
+func (h *Host) Endpoint() (*Endpoint, error) {
+	dockerURL, err := h.URL()
+	if err != nil {
+		return nil, err
+	}
+	if dockerURL == "" {
+		return nil, fmt.Errorf("%q has no URL, is it running?", h.Name)
+	}
+
+	tls, certDir := false, ""
+	if authOptions := h.AuthOptions(); authOptions != nil {
+		tls, certDir = true, authOptions.StorePath
+	}
+
+	return ParseEndpoint(dockerURL, tls, certDir)
+}
+
+// Address returns the host:port address of the engine.
+func (e *Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+// DockerHost returns the value of DOCKER_HOST for the engine.
+func (e *Endpoint) DockerHost() string {
+	return fmt.Sprintf("%s://%s", e.Scheme, e.Address())
+}
+
+// Env returns the environment variables pointing the Docker client at the
+// engine, in the name=value format.
+func (e *Endpoint) Env() []string {
+	env := []string{"DOCKER_HOST=" + e.DockerHost()}
+	if e.TLS {
+		env = append(env, "DOCKER_TLS_VERIFY=1", "DOCKER_CERT_PATH="+e.CertDir)
+	}
+	return env
+}
+
+// HTTPURL returns the URL of a path of the API of the engine, e.g.
+// /v1.30/info, for HTTP clients.
+func (e *Endpoint) HTTPURL(path string) string {
+	scheme := "http"
+	if e.TLS {
+		scheme = "https"
+	}
+
+	u := url.URL{
+		Scheme: scheme,
+		Host:   e.Address(),
+		Path:   path,
+	}
+	return u.String()
+}
+
+// CurlArgs returns the arguments of curl to request a path of the API of
+// the engine, with the certificates of the machine.
+func (e *Endpoint) CurlArgs(path string) []string {
+	if !e.TLS {
+		return []string{e.HTTPURL(path)}
+	}
+
+	return []string{
+		"--cacert", filepath.Join(e.CertDir, "ca.pem"),
+		"--cert", filepath.Join(e.CertDir, "cert.pem"),
+		"--key", filepath.Join(e.CertDir, "key.pem"),
+		e.HTTPURL(path),
+	}
+}
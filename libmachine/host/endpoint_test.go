@@ -0,0 +1,68 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpoint(t *testing.T) {
+	host := &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "192.168.99.100",
+		},
+		HostOptions: &Options{
+			AuthOptions: &auth.Options{StorePath: "/store/machines/test"},
+		},
+	}
+
+	endpoint, err := host.Endpoint()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Endpoint{
+		Scheme:  "tcp",
+		Host:    "192.168.99.100",
+		Port:    2376,
+		TLS:     true,
+		CertDir: "/store/machines/test",
+	}, endpoint)
+	assert.Equal(t, "tcp://192.168.99.100:2376", endpoint.DockerHost())
+	assert.Equal(t, []string{
+		"DOCKER_HOST=tcp://192.168.99.100:2376",
+		"DOCKER_TLS_VERIFY=1",
+		"DOCKER_CERT_PATH=/store/machines/test",
+	}, endpoint.Env())
+	assert.Equal(t, []string{
+		"--cacert", "/store/machines/test/ca.pem",
+		"--cert", "/store/machines/test/cert.pem",
+		"--key", "/store/machines/test/key.pem",
+		"https://192.168.99.100:2376/v1.30/info",
+	}, endpoint.CurlArgs("/v1.30/info"))
+}
+
+func TestEndpointIPv6WithoutTLS(t *testing.T) {
+	endpoint, err := ParseEndpoint("tcp://[fd00::2]:2375", false, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "fd00::2", endpoint.Host)
+	assert.Equal(t, "tcp://[fd00::2]:2375", endpoint.DockerHost())
+	assert.Equal(t, []string{"DOCKER_HOST=tcp://[fd00::2]:2375"}, endpoint.Env())
+	assert.Equal(t, []string{"http://[fd00::2]:2375/_ping"}, endpoint.CurlArgs("/_ping"))
+}
+
+func TestEndpointErrors(t *testing.T) {
+	_, err := ParseEndpoint("tcp://192.168.99.100", true, "")
+	assert.EqualError(t, err, `The URL "tcp://192.168.99.100" has no valid port`)
+
+	host := &Host{
+		Name:   "test",
+		Driver: &fakedriver.Driver{MockState: state.Running},
+	}
+	_, err = host.Endpoint()
+	assert.EqualError(t, err, `"test" has no URL, is it running?`)
+}
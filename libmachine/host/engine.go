@@ -0,0 +1,145 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/mcndockerclient"
+)
+
+// EngineVersion is what the engine of a machine reports of its version.
+type EngineVersion struct {
+	// Version is the version of the engine, e.g. 17.09.0-ce.
+	Version string
+
+	// APIVersion is the newest version of the remote API the engine
+	// serves, e.g. 1.32.
+	APIVersion string
+
+	GitCommit string
+	GoVersion string
+
+	// Os and Arch are the platform the engine runs on, e.g. linux and
+	// amd64, and KernelVersion the kernel of the machine.
+	Os            string
+	Arch          string
+	KernelVersion string
+}
+
+// EngineInfo is what the engine of a machine reports of its state, as
+// docker info prints it.
+type EngineInfo struct {
+	// ID and Name identify the engine, Name being the hostname of the
+	// machine.
+	ID   string
+	Name string
+
+	// Containers and Images are the numbers of containers and images the
+	// engine holds.
+	Containers int64
+	Images     int64
+
+	// StorageDriver is the storage driver the engine runs with, e.g.
+	// overlay2, and DockerRootDir where it keeps its data.
+	StorageDriver string
+	DockerRootDir string
+
+	// OperatingSystem is the distribution of the machine, e.g. Ubuntu
+	// 16.04.3 LTS, and KernelVersion its kernel.
+	OperatingSystem string
+	KernelVersion   string
+
+	// NCPU is the number of CPUs and MemTotal the memory of the machine,
+	// in bytes.
+	NCPU     int64
+	MemTotal int64
+
+	// Labels are the labels of the engine, in the key=value format.
+	Labels []string
+}
+
+// engineHost returns the engine of the machine for mcndockerclient, failing
+// when the machine has no URL.
+func (h *Host) engineHost() (*mcndockerclient.RemoteDocker, error) {
+	dockerURL, err := h.URL()
+	if err != nil {
+		return nil, err
+	}
+	if dockerURL == "" {
+		return nil, fmt.Errorf("%q has no URL, is it running?", h.Name)
+	}
+
+	return &mcndockerclient.RemoteDocker{
+		HostURL:    dockerURL,
+		AuthOption: h.AuthOptions(),
+	}, nil
+}
+
+// EngineVersion queries the version of the engine over the Docker remote
+// API with the TLS certificates of the store. The version is recorded as
+// the last known one of the machine.
+func (h *Host) EngineVersion() (*EngineVersion, error) {
+	dockerHost, err := h.engineHost()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := mcndockerclient.EngineVersion(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	h.RecordEngineVersion(version.Version)
+
+	return &EngineVersion{
+		Version:       version.Version,
+		APIVersion:    version.ApiVersion,
+		GitCommit:     version.GitCommit,
+		GoVersion:     version.GoVersion,
+		Os:            version.Os,
+		Arch:          version.Arch,
+		KernelVersion: version.KernelVersion,
+	}, nil
+}
+
+// EngineInfo queries the state of the engine over the Docker remote API
+// with the TLS certificates of the store.
+func (h *Host) EngineInfo() (*EngineInfo, error) {
+	dockerHost, err := h.engineHost()
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := mcndockerclient.EngineInfo(dockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EngineInfo{
+		ID:              info.ID,
+		Name:            info.Name,
+		Containers:      info.Containers,
+		Images:          info.Images,
+		StorageDriver:   info.Driver,
+		DockerRootDir:   info.DockerRootDir,
+		OperatingSystem: info.OperatingSystem,
+		KernelVersion:   info.KernelVersion,
+		NCPU:            info.NCPU,
+		MemTotal:        info.MemTotal,
+		Labels:          info.Labels,
+	}, nil
+}
+
+// upgradableEngineVersion returns the version of the engine, failing for
+// the engines the provisioners cannot upgrade.
+func (h *Host) upgradableEngineVersion() (string, error) {
+	version, err := h.EngineVersion()
+	if err != nil {
+		return "", err
+	}
+
+	if version.Os != "" && version.Os != "linux" {
+		return "", fmt.Errorf("The engine of %q runs on %s, only Linux engines can be upgraded", h.Name, version.Os)
+	}
+
+	return version.Version, nil
+}
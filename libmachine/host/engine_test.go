@@ -0,0 +1,161 @@
+package host
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/mcndockerclient"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/samalba/dockerclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func withEngineInspector(inspector mcndockerclient.EngineInspector) func() {
+	previous := mcndockerclient.CurrentEngineInspector
+	mcndockerclient.CurrentEngineInspector = inspector
+	return func() { mcndockerclient.CurrentEngineInspector = previous }
+}
+
+func runningHost() *Host {
+	return &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "1.2.3.4",
+		},
+	}
+}
+
+func TestEngineVersion(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Version: &dockerclient.Version{Version: "17.09.0-ce", ApiVersion: "1.32", Os: "linux", Arch: "amd64"},
+	})()
+
+	host := runningHost()
+	version, err := host.EngineVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &EngineVersion{Version: "17.09.0-ce", APIVersion: "1.32", Os: "linux", Arch: "amd64"}, version)
+	assert.Equal(t, "17.09.0-ce", host.LastKnown.EngineVersion)
+}
+
+func TestEngineVersionError(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{Err: errors.New("connection refused")})()
+
+	host := runningHost()
+	_, err := host.EngineVersion()
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Nil(t, host.LastKnown)
+}
+
+func TestEngineVersionWithoutURL(t *testing.T) {
+	host := &Host{
+		Name:   "test",
+		Driver: &fakedriver.Driver{MockState: state.Running},
+	}
+
+	_, err := host.EngineVersion()
+
+	assert.EqualError(t, err, `"test" has no URL, is it running?`)
+}
+
+func TestEngineInfo(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Info: &dockerclient.Info{
+			Name:            "test",
+			Containers:      2,
+			Images:          5,
+			Driver:          "overlay2",
+			OperatingSystem: "Ubuntu 16.04.3 LTS",
+			NCPU:            2,
+			MemTotal:        2097152,
+			Labels:          []string{"provider=generic"},
+		},
+	})()
+
+	info, err := runningHost().EngineInfo()
+
+	assert.NoError(t, err)
+	assert.Equal(t, &EngineInfo{
+		Name:            "test",
+		Containers:      2,
+		Images:          5,
+		StorageDriver:   "overlay2",
+		OperatingSystem: "Ubuntu 16.04.3 LTS",
+		NCPU:            2,
+		MemTotal:        2097152,
+		Labels:          []string{"provider=generic"},
+	}, info)
+}
+
+func TestUpgradableEngineVersion(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Version: &dockerclient.Version{Version: "17.09.0-ce", Os: "linux"},
+	})()
+
+	version, err := runningHost().upgradableEngineVersion()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "17.09.0-ce", version)
+}
+
+func TestUpgradableEngineVersionWindows(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Version: &dockerclient.Version{Version: "17.06.2-ee-5", Os: "windows"},
+	})()
+
+	_, err := runningHost().upgradableEngineVersion()
+
+	assert.EqualError(t, err, `The engine of "test" runs on windows, only Linux engines can be upgraded`)
+}
+
+type engineInstaller struct {
+	*provision.FakeProvisioner
+	err error
+}
+
+func (p *engineInstaller) InstallEngineVersion(version string) error {
+	return p.err
+}
+
+func TestUpgradeTo(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Version: &dockerclient.Version{Version: "17.09.0-ce", Os: "linux"},
+	})()
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &engineInstaller{FakeProvisioner: &provision.FakeProvisioner{}},
+	})
+
+	host := runningHost()
+	host.HostOptions = &Options{EngineOptions: &engine.Options{}}
+
+	assert.NoError(t, host.UpgradeTo("17.12.0-ce", false))
+	assert.Equal(t, "17.12.0-ce", host.HostOptions.EngineOptions.InstallVersion)
+}
+
+func TestUpgradeToInstallFails(t *testing.T) {
+	defer withEngineInspector(&mcndockerclient.FakeEngineInspector{
+		Version: &dockerclient.Version{Version: "17.09.0-ce", Os: "linux"},
+	})()
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &engineInstaller{FakeProvisioner: &provision.FakeProvisioner{}, err: errors.New("no such version")},
+	})
+
+	host := runningHost()
+	host.HostOptions = &Options{EngineOptions: &engine.Options{InstallVersion: "17.09.0-ce"}}
+
+	assert.EqualError(t, host.UpgradeTo("17.13.0-ce", false), "no such version")
+	assert.Equal(t, "17.09.0-ce", host.HostOptions.EngineOptions.InstallVersion)
+}
+
+func TestUpgradeToWithoutEngineOptions(t *testing.T) {
+	err := runningHost().UpgradeTo("17.12.0-ce", false)
+
+	assert.EqualError(t, err, `Machine "test" has no engine options`)
+}
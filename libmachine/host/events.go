@@ -0,0 +1,78 @@
+package host
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType tells what happened to a machine.
+type EventType string
+
+const (
+	// EventPreempted is sent when the provider reclaimed the machine.
+	EventPreempted EventType = "preempted"
+	// EventRecreated is sent when a preempted machine was brought back.
+	EventRecreated EventType = "recreated"
+	// EventRecreateFailed is sent when a preempted machine could not be
+	// brought back.
+	EventRecreateFailed EventType = "recreate-failed"
+	// EventIdleStopped is sent when a machine was stopped for being idle.
+	EventIdleStopped EventType = "idle-stopped"
+	// EventScheduleStarted and EventScheduleStopped are sent when a machine
+	// was started or stopped by its schedule.
+	EventScheduleStarted EventType = "schedule-started"
+	EventScheduleStopped EventType = "schedule-stopped"
+	// EventExpired is sent when a machine was removed for outliving its
+	// TTL.
+	EventExpired EventType = "expired"
+	// EventUnhealthy is sent when the engine of a machine watched by the
+	// watchdog stopped answering.
+	EventUnhealthy EventType = "unhealthy"
+	// EventWatchdogRestarted and EventWatchdogFailed are sent when the
+	// watchdog brought the engine back by restarting it or the machine, or
+	// failed to.
+	EventWatchdogRestarted EventType = "watchdog-restarted"
+	EventWatchdogFailed    EventType = "watchdog-failed"
+)
+
+// Event is something that happened to a machine outside of the actions
+// requested by the user.
+type Event struct {
+	Type    EventType
+	Host    string
+	Time    time.Time
+	Message string
+}
+
+// EventHandler receives the events of all the machines.
+type EventHandler func(event Event)
+
+var (
+	eventHandlers      []EventHandler
+	eventHandlersMutex sync.RWMutex
+)
+
+// AddEventHandler registers a handler called with every event. Handlers are
+// called synchronously and must not block.
+func AddEventHandler(handler EventHandler) {
+	eventHandlersMutex.Lock()
+	defer eventHandlersMutex.Unlock()
+
+	eventHandlers = append(eventHandlers, handler)
+}
+
+func (h *Host) emit(eventType EventType, message string) {
+	event := Event{
+		Type:    eventType,
+		Host:    h.Name,
+		Time:    time.Now(),
+		Message: message,
+	}
+
+	eventHandlersMutex.RLock()
+	defer eventHandlersMutex.RUnlock()
+
+	for _, handler := range eventHandlers {
+		handler(event)
+	}
+}
@@ -0,0 +1,57 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+)
+
+// OpenPort opens a port of the machine in the firewall of the provider. The
+// host options keep the port: the host has to be saved afterwards.
+func (h *Host) OpenPort(rule drivers.FirewallRule) error {
+	if err := drivers.OpenPort(h.Driver, rule); err != nil {
+		if err == drivers.ErrNotImplemented {
+			return fmt.Errorf("The %s driver cannot open ports", h.DriverName)
+		}
+		return err
+	}
+
+	if drivers.IsRequiredFirewallRule(rule, h.enginePort()) {
+		return nil
+	}
+
+	for _, opened := range h.HostOptions.OpenPorts {
+		if opened == rule {
+			return nil
+		}
+	}
+	h.HostOptions.OpenPorts = append(h.HostOptions.OpenPorts, rule)
+
+	return nil
+}
+
+// ClosePort closes a port of the machine opened with OpenPort or at
+// creation, see OpenPort. The ports needed to manage the machine cannot be
+// closed.
+func (h *Host) ClosePort(rule drivers.FirewallRule) error {
+	if drivers.IsRequiredFirewallRule(rule, h.enginePort()) {
+		return fmt.Errorf("Port %s is needed to manage %q and cannot be closed", rule, h.Name)
+	}
+
+	if err := drivers.ClosePort(h.Driver, rule); err != nil {
+		if err == drivers.ErrNotImplemented {
+			return fmt.Errorf("The %s driver cannot close ports", h.DriverName)
+		}
+		return err
+	}
+
+	kept := []drivers.FirewallRule{}
+	for _, opened := range h.HostOptions.OpenPorts {
+		if opened != rule {
+			kept = append(kept, opened)
+		}
+	}
+	h.HostOptions.OpenPorts = kept
+
+	return nil
+}
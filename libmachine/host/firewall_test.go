@@ -0,0 +1,78 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+type firewallDriver struct {
+	*fakedriver.Driver
+	opened []drivers.FirewallRule
+	closed []drivers.FirewallRule
+}
+
+func (d *firewallDriver) SetFirewallRules(rules []drivers.FirewallRule) error {
+	d.opened = append(d.opened, rules...)
+	return nil
+}
+
+func (d *firewallDriver) OpenPort(rule drivers.FirewallRule) error {
+	d.opened = append(d.opened, rule)
+	return nil
+}
+
+func (d *firewallDriver) ClosePort(rule drivers.FirewallRule) error {
+	d.closed = append(d.closed, rule)
+	return nil
+}
+
+func TestOpenClosePort(t *testing.T) {
+	driver := &firewallDriver{Driver: &fakedriver.Driver{}}
+	host := &Host{
+		Name:        "test",
+		Driver:      driver,
+		HostOptions: &Options{},
+	}
+	web := drivers.FirewallRule{Protocol: "tcp", Port: 80}
+	dns := drivers.FirewallRule{Protocol: "udp", Port: 53}
+
+	assert.NoError(t, host.OpenPort(web))
+	assert.NoError(t, host.OpenPort(dns))
+	assert.NoError(t, host.OpenPort(web))
+	assert.Equal(t, []drivers.FirewallRule{web, dns}, host.HostOptions.OpenPorts)
+
+	assert.NoError(t, host.ClosePort(web))
+	assert.Equal(t, []drivers.FirewallRule{web}, driver.closed)
+	assert.Equal(t, []drivers.FirewallRule{dns}, host.HostOptions.OpenPorts)
+}
+
+func TestClosePortRequired(t *testing.T) {
+	driver := &firewallDriver{Driver: &fakedriver.Driver{}}
+	host := &Host{
+		Name:        "test",
+		Driver:      driver,
+		HostOptions: &Options{},
+	}
+
+	err := host.ClosePort(drivers.FirewallRule{Protocol: "tcp", Port: 22})
+
+	assert.EqualError(t, err, `Port 22/tcp is needed to manage "test" and cannot be closed`)
+	assert.Empty(t, driver.closed)
+}
+
+func TestOpenPortNotSupported(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		DriverName:  "fakedriver",
+		Driver:      &fakedriver.Driver{},
+		HostOptions: &Options{},
+	}
+
+	err := host.OpenPort(drivers.FirewallRule{Protocol: "tcp", Port: 80})
+
+	assert.EqualError(t, err, "The fakedriver driver cannot open ports")
+	assert.Empty(t, host.HostOptions.OpenPorts)
+}
@@ -1,7 +1,14 @@
 package host
 
 import (
+	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/drivers"
@@ -10,16 +17,21 @@ import (
 	"github.com/docker/machine/libmachine/mcndockerclient"
 	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/metrics"
 	"github.com/docker/machine/libmachine/provision"
 	"github.com/docker/machine/libmachine/provision/pkgaction"
 	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/schedule"
 	"github.com/docker/machine/libmachine/ssh"
 	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/libmachine/versioncmp"
+	"github.com/samalba/dockerclient"
 )
 
 var (
+	stopPollInterval = 3 * time.Second
+
 	validHostNamePattern                  = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-\.]*$`)
 	stdSSHClientCreator  SSHClientCreator = &StandardSSHClientCreator{}
 )
@@ -42,16 +54,114 @@ type Host struct {
 	DriverName    string
 	HostOptions   *Options
 	Name          string
-	RawDriver     []byte `json:"-"`
+	RawDriver     []byte     `json:"-"`
+	LastKnown     *LastKnown `json:",omitempty"`
+
+	// DetectedProvisioner caches the provisioner detected on the machine,
+	// see Provisioner.
+	DetectedProvisioner *provision.Detected `json:",omitempty"`
+
+	// CreatePhase is the last completed phase of the creation of the
+	// machine, see CreateReached.
+	CreatePhase CreatePhase `json:",omitempty"`
+
+	// InstanceMissing is set when the instance of the machine was found
+	// deleted at the provider, e.g. from its console, see ErrInstanceMissing.
+	InstanceMissing bool `json:",omitempty"`
+}
+
+// ErrInstanceMissing is returned when operating a machine the instance of
+// which no longer exists at the provider.
+type ErrInstanceMissing struct {
+	Name string
+}
+
+func (e ErrInstanceMissing) Error() string {
+	return fmt.Sprintf("The instance of %q no longer exists at the provider, run \"docker-machine rm %s\" to remove it from the store", e.Name, e.Name)
 }
 
 type Options struct {
-	Driver        string
-	Memory        int
-	Disk          int
-	EngineOptions *engine.Options
-	SwarmOptions  *swarm.Options
-	AuthOptions   *auth.Options
+	Driver string
+
+	// Memory and Disk are the size of the machine in MB, set through
+	// drivers.SetSize for the drivers supporting it. Create records the
+	// defaults of the driver when they are not set.
+	Memory int
+	Disk   int
+
+	AddressPreference drivers.AddressPreference
+	NetworkOptions    *drivers.NetworkOptions
+	InstanceOptions   *drivers.InstanceOptions
+	GPUOptions        *drivers.GPUOptions `json:",omitempty"`
+	EngineOptions     *engine.Options
+	SwarmOptions      *swarm.Options
+	AuthOptions       *auth.Options
+
+	// Provisioner forces the provisioner registered with that name, for
+	// distributions which are not detected, e.g. derivatives of a supported
+	// distribution.
+	Provisioner string `json:",omitempty"`
+
+	// RecreateOnPreemption brings the machine back when CheckPreemption
+	// finds that the provider reclaimed it.
+	RecreateOnPreemption bool
+
+	// Autostart is set when the machine starts when the host OS boots, see
+	// SetAutostart.
+	Autostart bool
+
+	// Protected has Remove refused for the machine unless the protection is
+	// overridden, see CheckRemovable.
+	Protected bool `json:",omitempty"`
+
+	// IdleTimeout is how long the engine of the machine may go unused
+	// before StopIfIdle stops the machine, or zero to never stop it.
+	IdleTimeout time.Duration
+
+	// TTL is how long the machine lives before RemoveIfExpired removes it,
+	// zero for ever. ExpiresAt is when it expires, set on creation, see
+	// SetTTL.
+	TTL       time.Duration `json:",omitempty"`
+	ExpiresAt time.Time
+
+	// Watchdog tells what CheckHealth does when the engine stops
+	// answering, nil to not watch the machine.
+	Watchdog *WatchdogPolicy `json:",omitempty"`
+
+	// SSHBastion is the host the SSH connections to the machine go through,
+	// for the machines without a public address, nil to connect directly.
+	SSHBastion *ssh.Bastion `json:",omitempty"`
+
+	// TagResources has the driver tag the cloud resources it creates with
+	// drivers.ResourceTags, and check the tags before deleting them.
+	TagResources bool `json:",omitempty"`
+
+	// OpenPorts are the ports opened in the firewall of the provider on top
+	// of drivers.RequiredFirewallRules, see OpenPort.
+	OpenPorts []drivers.FirewallRule `json:",omitempty"`
+
+	// Schedule tells when ApplySchedule starts and stops the machine.
+	Schedule *schedule.Schedule `json:",omitempty"`
+
+	// Placement is the region and zone of the machine, for the drivers of
+	// providers which have them. Create records the defaults of the driver
+	// when they are not set.
+	Placement *drivers.Placement `json:",omitempty"`
+
+	// Users are the additional SSH users created when provisioning.
+	Users []provision.User `json:",omitempty"`
+
+	// Sysctls are the kernel parameters set when provisioning, e.g.
+	// vm.max_map_count for the containers of some databases.
+	Sysctls map[string]string `json:",omitempty"`
+
+	// Swap is the swap set up when provisioning, nil to leave the swap of
+	// the machine as it is.
+	Swap *provision.Swap `json:",omitempty"`
+
+	// PreloadImages are the images pulled, or the local archives loaded,
+	// into the engine when the machine is created.
+	PreloadImages []string `json:",omitempty"`
 }
 
 type Metadata struct {
@@ -65,7 +175,98 @@ func ValidateHostName(name string) bool {
 }
 
 func (h *Host) RunSSHCommand(command string) (string, error) {
-	return drivers.RunSSHCommandFromDriver(h.Driver, command)
+	output, err := drivers.RunSSHCommandFromDriver(h.Driver, command)
+	if err == nil {
+		h.recordSSH()
+	}
+	return output, err
+}
+
+// dockerCommand returns the command running the docker client as root on the
+// machine with the arguments. The provisioner is only needed when the engine
+// does not listen on its unix socket.
+func (h *Host) dockerCommand(args string) (string, error) {
+	if h.HostOptions == nil || h.HostOptions.EngineOptions == nil || !h.HostOptions.EngineOptions.DisableUnixSocket {
+		return "sudo docker " + args, nil
+	}
+
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		return "", err
+	}
+
+	return provision.DockerCommand(provisioner, h.HostOptions.EngineOptions, args), nil
+}
+
+// RunSSHCommandWithStatus runs a command over SSH and returns its standard
+// output, its standard error and its exit status. Unlike RunSSHCommand, a non
+// zero exit status is not reported as an error.
+func (h *Host) RunSSHCommandWithStatus(command string) (string, string, int, error) {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return "", "", -1, err
+	}
+
+	log.Debugf("About to run SSH command:\n%s", command)
+
+	stdout, stderr, status, err := ssh.Run(client, command)
+	if err == nil {
+		h.recordSSH()
+	}
+	return stdout, stderr, status, err
+}
+
+// StreamSSHCommand runs a command over SSH, copying its output to stdout and
+// stderr as it is produced, and returns its exit status.
+func (h *Host) StreamSSHCommand(command string, stdout, stderr io.Writer) (int, error) {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return -1, err
+	}
+
+	log.Debugf("About to stream SSH command:\n%s", command)
+
+	status, err := ssh.RunStream(client, command, stdout, stderr)
+	if err == nil {
+		h.recordSSH()
+	}
+	return status, err
+}
+
+// Shell opens an interactive SSH session on the machine, running the given
+// command or a login shell if none is given. The size of the local terminal
+// is kept in sync with the remote one. When the remote command exits with a
+// non zero status, ssh.ExitStatus tells that status from the returned error.
+func (h *Host) Shell(args ...string) error {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return err
+	}
+
+	return client.Shell(args...)
+}
+
+// RecordHostKey records the current host key of the machine in the
+// known_hosts file, replacing the previous one. It is done when the machine
+// is provisioned, and must be done again when the machine is recreated.
+func (h *Host) RecordHostKey() error {
+	addr, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return err
+	}
+
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return err
+	}
+
+	return ssh.RecordHostKey(drivers.KnownHostsOf(h.Driver), addr, port, drivers.ResolveSSHBastion(h.Driver))
+}
+
+// ResetHostKey forgets the recorded host key of the machine, so that the
+// next connections are not checked until a new key is recorded.
+func (h *Host) ResetHostKey() error {
+	return ssh.RemoveHostKey(drivers.KnownHostsOf(h.Driver))
 }
 
 func (h *Host) CreateSSHClient() (ssh.Client, error) {
@@ -83,7 +284,10 @@ func (creator *StandardSSHClientCreator) CreateSSHClient(d drivers.Driver) (ssh.
 		return &ssh.ExternalClient{}, err
 	}
 
-	auth := &ssh.Auth{}
+	auth := &ssh.Auth{
+		KnownHosts: drivers.KnownHostsOf(d),
+		Bastion:    drivers.ResolveSSHBastion(d),
+	}
 	if d.GetSSHKeyPath() != "" {
 		auth.Keys = []string{d.GetSSHKeyPath()}
 	}
@@ -103,30 +307,48 @@ func (h *Host) runActionForState(action func() error, desiredState state.State)
 		return err
 	}
 
-	return mcnutils.WaitFor(drivers.MachineInState(h.Driver, desiredState))
+	if err := mcnutils.WaitFor(drivers.MachineInState(h.Driver, desiredState)); err != nil {
+		return err
+	}
+
+	h.RecordState(desiredState)
+	return nil
 }
 
 func (h *Host) WaitForDocker() error {
-	provisioner, err := provision.DetectProvisioner(h.Driver)
+	provisioner, err := h.Provisioner()
 	if err != nil {
 		return err
 	}
 
-	return provision.WaitForDocker(provisioner, engine.DefaultPort)
+	return provision.WaitForDocker(provisioner, h.enginePort())
 }
 
-func (h *Host) Start() error {
+func (h *Host) Start() (err error) {
+	defer metrics.Observe("start", h.Name, h.DriverName, time.Now(), &err)
+
 	log.Infof("Starting %q...", h.Name)
 	if err := h.runActionForState(h.Driver.Start, state.Running); err != nil {
+		// A machine started again is often a VM the host resumed
+		if _, ok := err.(mcnerror.ErrHostAlreadyInState); ok {
+			h.repairClockSkewOnResume()
+		}
 		return err
 	}
 
 	log.Infof("Machine %q was started.", h.Name)
 
-	return h.WaitForDocker()
+	if err := h.WaitForDocker(); err != nil {
+		return err
+	}
+
+	h.repairClockSkewOnResume()
+	return nil
 }
 
-func (h *Host) Stop() error {
+func (h *Host) Stop() (err error) {
+	defer metrics.Observe("stop", h.Name, h.DriverName, time.Now(), &err)
+
 	log.Infof("Stopping %q...", h.Name)
 	if err := h.runActionForState(h.Driver.Stop, state.Stopped); err != nil {
 		return err
@@ -136,6 +358,105 @@ func (h *Host) Stop() error {
 	return nil
 }
 
+// StopOptions tells how StopWithOptions shuts a machine down.
+type StopOptions struct {
+	// Graceful stops the Docker engine over SSH before powering the
+	// machine down, so that containers are stopped cleanly.
+	Graceful bool
+
+	// Timeout is how long to wait for the machine to stop before killing
+	// it. A zero timeout waits as long as Stop does and never kills.
+	Timeout time.Duration
+}
+
+// StopWithOptions stops the machine, shutting the engine down first and
+// killing the machine if it does not stop in time as asked by the options.
+func (h *Host) StopWithOptions(opts StopOptions) (err error) {
+	defer metrics.Observe("stop", h.Name, h.DriverName, time.Now(), &err)
+
+	killed := false
+	stop := func() error {
+		if opts.Graceful {
+			h.stopEngine()
+		}
+
+		if opts.Timeout == 0 {
+			return h.Driver.Stop()
+		}
+		if h.stopWithin(opts.Timeout) {
+			return nil
+		}
+		log.Warnf("%q did not stop within %s, killing it", h.Name, opts.Timeout)
+
+		killed = true
+		return h.Driver.Kill()
+	}
+
+	log.Infof("Stopping %q...", h.Name)
+	if err := h.runActionForState(stop, state.Stopped); err != nil {
+		return err
+	}
+
+	if killed {
+		log.Infof("Machine %q was killed.", h.Name)
+	} else {
+		log.Infof("Machine %q was stopped.", h.Name)
+	}
+	return nil
+}
+
+// stopWithin stops the machine and tells whether it stopped within the
+// timeout. Driver.Stop runs in the background, some drivers block until
+// the machine is off, and is left behind when the time is up.
+func (h *Host) stopWithin(timeout time.Duration) bool {
+	deadline := time.After(timeout)
+
+	result := make(chan error, 1)
+	go func() {
+		result <- h.Driver.Stop()
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			log.Warnf("Error stopping %q: %s", h.Name, err)
+			return false
+		}
+	case <-deadline:
+		return false
+	}
+
+	stopped := drivers.MachineInState(h.Driver, state.Stopped)
+	ticker := time.NewTicker(stopPollInterval)
+	defer ticker.Stop()
+
+	for !stopped() {
+		select {
+		case <-deadline:
+			return false
+		case <-ticker.C:
+		}
+	}
+
+	return true
+}
+
+// stopEngine stops the Docker engine over SSH. The machine is stopped
+// anyway when this fails, so errors are only logged.
+func (h *Host) stopEngine() {
+	log.Infof("Stopping the Docker engine of %q...", h.Name)
+
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		log.Warnf("Error detecting the OS of %q, not stopping the engine: %s", h.Name, err)
+		return
+	}
+
+	if err := provisioner.Service("docker", serviceaction.Stop); err != nil {
+		log.Warnf("Error stopping the Docker engine of %q: %s", h.Name, err)
+	}
+}
+
 func (h *Host) Kill() error {
 	log.Infof("Killing %q...", h.Name)
 	if err := h.runActionForState(h.Driver.Kill, state.Stopped); err != nil {
@@ -161,11 +482,16 @@ func (h *Host) Restart() error {
 		}
 	}
 
-	return h.WaitForDocker()
+	if err := h.WaitForDocker(); err != nil {
+		return err
+	}
+
+	h.repairClockSkewOnResume()
+	return nil
 }
 
 func (h *Host) DockerVersion() (string, error) {
-	url, err := h.Driver.GetURL()
+	url, err := h.URL()
 	if err != nil {
 		return "", err
 	}
@@ -179,9 +505,22 @@ func (h *Host) DockerVersion() (string, error) {
 		return "", err
 	}
 
+	h.RecordEngineVersion(dockerVersion)
 	return dockerVersion, nil
 }
 
+// DockerClient returns a client of the Docker API of the machine, set up with
+// its URL and the TLS certificates of the store, so that programs embedding
+// libmachine can talk to the engine right after Create.
+func (h *Host) DockerClient() (*dockerclient.DockerClient, error) {
+	dockerHost, err := h.engineHost()
+	if err != nil {
+		return nil, err
+	}
+
+	return mcndockerclient.DockerClient(dockerHost)
+}
+
 func (h *Host) Upgrade() error {
 	machineState, err := h.Driver.GetState()
 	if err != nil {
@@ -195,16 +534,21 @@ func (h *Host) Upgrade() error {
 		}
 	}
 
-	provisioner, err := provision.DetectProvisioner(h.Driver)
+	provisioner, err := h.Provisioner()
 	if err != nil {
 		return err
 	}
 
-	dockerVersion, err := h.DockerVersion()
+	dockerVersion, err := h.upgradableEngineVersion()
 	if err != nil {
 		return err
 	}
 
+	// Upgrading to the latest version drops the pinned version, if any
+	if h.HostOptions != nil && h.HostOptions.EngineOptions != nil {
+		h.HostOptions.EngineOptions.InstallVersion = ""
+	}
+
 	// If we're upgrading from a pre-CE (e.g., 1.13.1) release to a CE
 	// release (e.g., 17.03.0-ce), we should simply uninstall and
 	// re-install from scratch, since the official package names will
@@ -242,8 +586,129 @@ func (h *Host) Upgrade() error {
 	return provisioner.Service("docker", serviceaction.Restart)
 }
 
+// UpgradeTo moves the engine of the machine to the given version, which then
+// sticks for later provisionings. Moving to an older version is refused
+// unless allowDowngrade is set.
+func (h *Host) UpgradeTo(version string, allowDowngrade bool) error {
+	if h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return fmt.Errorf("Machine %q has no engine options", h.Name)
+	}
+	engineOptions := h.HostOptions.EngineOptions
+
+	machineState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	if machineState != state.Running {
+		log.Info("Starting machine so machine can be upgraded...")
+		if err := h.Start(); err != nil {
+			return err
+		}
+	}
+
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		return err
+	}
+
+	dockerVersion, err := h.upgradableEngineVersion()
+	if err != nil {
+		return err
+	}
+
+	if dockerVersion == version {
+		log.Infof("Machine %q already runs Docker %s", h.Name, version)
+		return nil
+	}
+
+	if versioncmp.LessThan(version, dockerVersion) && !allowDowngrade {
+		return mcnerror.ErrEngineDowngrade{
+			Name:    h.Name,
+			Current: dockerVersion,
+			Target:  version,
+		}
+	}
+
+	if installer, ok := provisioner.(provision.EngineVersionInstaller); ok {
+		log.Infof("Switching docker from %s to %s...", dockerVersion, version)
+		if err := installer.InstallEngineVersion(version); err != nil {
+			return err
+		}
+
+		engineOptions.InstallVersion = version
+		return nil
+	}
+
+	// Package based distributions get the engine removed, then installed
+	// again in the given version by the provisioning. Images and
+	// containers are preserved in /var/lib/docker.
+	packageName := "docker"
+	if versioncmp.LessThanOrEqualTo(dockerVersion, provision.LastReleaseBeforeCEVersioning) {
+		packageName = "docker-engine"
+	}
+
+	log.Infof("Removing docker %s...", dockerVersion)
+	if err := provisioner.Package(packageName, pkgaction.Purge); err != nil {
+		return err
+	}
+
+	// The provisioning installs the version of the engine options, which
+	// only sticks once installed.
+	log.Infof("Installing docker %s...", version)
+	previousVersion := engineOptions.InstallVersion
+	engineOptions.InstallVersion = version
+	if err := h.Provision(); err != nil {
+		engineOptions.InstallVersion = previousVersion
+		return err
+	}
+
+	return nil
+}
+
+// URL returns the URL of the Docker engine, on the port of the engine
+// options when set. When the machine has several addresses, the one matching
+// the address preference of the host is used.
 func (h *Host) URL() (string, error) {
-	return h.Driver.GetURL()
+	driverURL, err := h.Driver.GetURL()
+	if err != nil || driverURL == "" || h.HostOptions == nil {
+		return driverURL, err
+	}
+
+	enginePort := 0
+	if h.HostOptions.EngineOptions != nil {
+		enginePort = h.HostOptions.EngineOptions.Port
+	}
+	if h.HostOptions.AddressPreference == drivers.PreferDriver && enginePort == 0 {
+		return driverURL, nil
+	}
+
+	u, err := url.Parse(driverURL)
+	if err != nil {
+		return "", fmt.Errorf("Error parsing URL %q: %s", driverURL, err)
+	}
+
+	host, port := u.Hostname(), u.Port()
+	if h.HostOptions.AddressPreference != drivers.PreferDriver {
+		if host, err = drivers.GetPreferredIP(h.Driver, h.HostOptions.AddressPreference); err != nil {
+			return "", err
+		}
+	}
+	if enginePort != 0 {
+		port = strconv.Itoa(enginePort)
+	}
+
+	u.Host = net.JoinHostPort(host, port)
+
+	return u.String(), nil
+}
+
+// enginePort returns the TCP port the engine listens on.
+func (h *Host) enginePort() int {
+	if h.HostOptions == nil {
+		return engine.DefaultPort
+	}
+	return h.HostOptions.EngineOptions.DaemonPort()
 }
 
 func (h *Host) AuthOptions() *auth.Options {
@@ -254,7 +719,7 @@ func (h *Host) AuthOptions() *auth.Options {
 }
 
 func (h *Host) ConfigureAuth() error {
-	provisioner, err := provision.DetectProvisioner(h.Driver)
+	provisioner, err := h.Provisioner()
 	if err != nil {
 		return err
 	}
@@ -267,11 +732,110 @@ func (h *Host) ConfigureAuth() error {
 	return provisioner.Provision(swarm.Options{}, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions)
 }
 
-func (h *Host) Provision() error {
-	provisioner, err := provision.DetectProvisioner(h.Driver)
+func (h *Host) Provision() (err error) {
+	defer metrics.Observe("provision", h.Name, h.DriverName, time.Now(), &err)
+
+	provisioner, err := h.Provisioner()
 	if err != nil {
 		return err
 	}
 
-	return provisioner.Provision(*h.HostOptions.SwarmOptions, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions)
+	if err := provisioner.Provision(*h.HostOptions.SwarmOptions, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := h.ConfigureSystem(provisioner); err != nil {
+		return err
+	}
+
+	// Provisioning completes a creation interrupted after the instance was
+	// created
+	if h.CreateIncomplete() {
+		h.CreatePhase = CreatePhaseProvisioned
+	}
+
+	return nil
+}
+
+// ConfigureSystem applies the system options of the machine once its engine
+// is provisioned: the time sync, the swap, the sysctls and the users. Create
+// and Provision both run it.
+func (h *Host) ConfigureSystem(provisioner provision.Provisioner) error {
+	if err := provision.ConfigureTimeSync(provisioner); err != nil {
+		log.Warnf("The clock of the machine may drift: %s", err)
+	}
+
+	if err := provision.ConfigureSwap(provisioner, h.HostOptions.Swap); err != nil {
+		return err
+	}
+
+	if err := provision.ConfigureSysctls(provisioner, h.HostOptions.Sysctls); err != nil {
+		return err
+	}
+
+	if err := provision.ConfigureUsers(provisioner, h.HostOptions.Users); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reprovision provisions an existing machine again without recreating it:
+// the engine configuration is generated anew from the options of the
+// machine, the certificates are laid again and the daemon is restarted. It
+// recovers machines the daemon configuration of which was modified by hand.
+func (h *Host) Reprovision() error {
+	if h.HostOptions == nil || h.HostOptions.AuthOptions == nil || h.HostOptions.EngineOptions == nil || h.HostOptions.SwarmOptions == nil {
+		return fmt.Errorf("Machine %q has no provisioning options", h.Name)
+	}
+
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+	if currentState != state.Running {
+		return fmt.Errorf("Machine %q is %s, start it to provision it again", h.Name, strings.ToLower(currentState.String()))
+	}
+
+	log.Infof("Provisioning %q again...", h.Name)
+	if err := h.Provision(); err != nil {
+		return fmt.Errorf("Error provisioning %q again: %s", h.Name, err)
+	}
+
+	log.Infof("Machine %q was provisioned again.", h.Name)
+	return nil
+}
+
+// EngineLogs copies the logs of the Docker daemon of the machine to w, read
+// where the init system of its OS keeps them. Only the last tail lines are
+// copied when tail is positive. When follow is set, new lines are copied as
+// they are logged until the SSH session ends.
+func (h *Host) EngineLogs(tail int, follow bool, w io.Writer) error {
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+	if currentState != state.Running {
+		return fmt.Errorf("Machine %q is %s, start it to read the engine logs", h.Name, strings.ToLower(currentState.String()))
+	}
+
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		return err
+	}
+
+	command, err := provision.EngineLogsCommand(provisioner, tail, follow)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.StreamSSHCommand(command, w, w)
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return fmt.Errorf("Error reading the engine logs of %q: %q exited with status %d", h.Name, command, status)
+	}
+
+	return nil
 }
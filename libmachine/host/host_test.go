@@ -1,12 +1,23 @@
 package host
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/docker/machine/drivers/fakedriver"
 	_ "github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/provision"
 	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestValidateHostnameValid(t *testing.T) {
@@ -55,3 +66,225 @@ func TestStart(t *testing.T) {
 		t.Fatalf("Expected no error but got one: %s", err)
 	}
 }
+
+type provisionRecorder struct {
+	*provision.FakeProvisioner
+	provisioned bool
+}
+
+func (p *provisionRecorder) Provision(swarmOptions swarm.Options, authOptions auth.Options, engineOptions engine.Options) error {
+	p.provisioned = true
+	return nil
+}
+
+func newReprovisionTestHost(machineState state.State) *Host {
+	return &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: machineState,
+		},
+		HostOptions: &Options{
+			AuthOptions:   &auth.Options{},
+			EngineOptions: &engine.Options{},
+			SwarmOptions:  &swarm.Options{},
+		},
+	}
+}
+
+func TestReprovision(t *testing.T) {
+	provisioner := &provisionRecorder{FakeProvisioner: &provision.FakeProvisioner{}}
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provisioner,
+	})
+
+	host := newReprovisionTestHost(state.Running)
+
+	assert.NoError(t, host.Reprovision())
+	assert.True(t, provisioner.provisioned)
+}
+
+func TestReprovisionStoppedMachine(t *testing.T) {
+	provisioner := &provisionRecorder{FakeProvisioner: &provision.FakeProvisioner{}}
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provisioner,
+	})
+
+	host := newReprovisionTestHost(state.Stopped)
+
+	err := host.Reprovision()
+
+	assert.EqualError(t, err, `Machine "test" is stopped, start it to provision it again`)
+	assert.False(t, provisioner.provisioned)
+}
+
+func TestEngineLogsStoppedMachine(t *testing.T) {
+	host := newReprovisionTestHost(state.Stopped)
+
+	err := host.EngineLogs(10, false, ioutil.Discard)
+
+	assert.EqualError(t, err, `Machine "test" is stopped, start it to read the engine logs`)
+}
+
+func TestEngineLogsNotSupported(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &provision.FakeProvisioner{},
+	})
+
+	host := newReprovisionTestHost(state.Running)
+
+	assert.Error(t, host.EngineLogs(10, false, ioutil.Discard))
+}
+
+func TestURLWithAddressPreference(t *testing.T) {
+	host := &Host{
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "1.2.3.4",
+			MockIPs:   []string{"1.2.3.4", "2001:db8::1"},
+		},
+		HostOptions: &Options{},
+	}
+
+	url, err := host.URL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "tcp://1.2.3.4:2376" {
+		t.Fatalf("Expected the driver URL but got %s", url)
+	}
+
+	host.HostOptions.AddressPreference = drivers.PreferIPv6
+
+	url, err = host.URL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "tcp://[2001:db8::1]:2376" {
+		t.Fatalf("Expected the IPv6 URL but got %s", url)
+	}
+}
+
+func TestURLWithEnginePort(t *testing.T) {
+	host := &Host{
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "1.2.3.4",
+			MockIPs:   []string{"1.2.3.4", "2001:db8::1"},
+		},
+		HostOptions: &Options{
+			EngineOptions: &engine.Options{Port: 12376},
+		},
+	}
+
+	url, err := host.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp://1.2.3.4:12376", url)
+
+	host.HostOptions.AddressPreference = drivers.PreferIPv6
+
+	url, err = host.URL()
+	assert.NoError(t, err)
+	assert.Equal(t, "tcp://[2001:db8::1]:12376", url)
+}
+
+func TestDockerClient(t *testing.T) {
+	certDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(certDir)
+
+	authOptions := &auth.Options{
+		CertDir:          certDir,
+		CaCertPath:       filepath.Join(certDir, "ca.pem"),
+		CaPrivateKeyPath: filepath.Join(certDir, "ca-key.pem"),
+		ClientCertPath:   filepath.Join(certDir, "cert.pem"),
+		ClientKeyPath:    filepath.Join(certDir, "key.pem"),
+	}
+	if err := cert.BootstrapCertificates(authOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	host := &Host{
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "1.2.3.4",
+		},
+		HostOptions: &Options{
+			AuthOptions: authOptions,
+		},
+	}
+
+	client, err := host.DockerClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.URL.Host != "1.2.3.4:2376" {
+		t.Fatalf("Expected the client to connect to 1.2.3.4:2376 but got %s", client.URL.Host)
+	}
+	if client.TLSConfig == nil || len(client.TLSConfig.Certificates) != 1 {
+		t.Fatal("Expected the client to use the client certificate of the store")
+	}
+}
+
+func TestConsoleOutputNotSupported(t *testing.T) {
+	host := &Host{
+		Name:       "test",
+		DriverName: "fakedriver",
+		Driver:     &fakedriver.Driver{},
+	}
+
+	_, err := host.ConsoleOutput()
+
+	assert.EqualError(t, err, `The fakedriver driver cannot read the console output of "test"`)
+}
+
+// blockingStopDriver never returns from Stop until released, like drivers
+// waiting for a machine which does not shut down.
+type blockingStopDriver struct {
+	*fakedriver.Driver
+	release chan struct{}
+}
+
+func (d *blockingStopDriver) Stop() error {
+	<-d.release
+	return nil
+}
+
+func TestStopWithOptionsKillsWhenStopBlocks(t *testing.T) {
+	driver := &blockingStopDriver{
+		Driver:  &fakedriver.Driver{MockState: state.Running},
+		release: make(chan struct{}),
+	}
+	defer close(driver.release)
+
+	host := &Host{
+		Name:   "test",
+		Driver: driver,
+	}
+
+	defer func(interval time.Duration) { stopPollInterval = interval }(stopPollInterval)
+	stopPollInterval = 10 * time.Millisecond
+
+	err := host.StopWithOptions(StopOptions{Timeout: 50 * time.Millisecond})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, driver.Called("Kill"))
+	assert.Equal(t, state.Stopped, driver.MockState)
+}
+
+func TestStopWithOptionsRecordsState(t *testing.T) {
+	host := &Host{
+		Name:   "test",
+		Driver: &fakedriver.Driver{MockState: state.Running},
+	}
+
+	assert.NoError(t, host.StopWithOptions(StopOptions{}))
+	assert.Equal(t, state.Stopped, host.LastKnown.State)
+
+	err := host.StopWithOptions(StopOptions{})
+	assert.Equal(t, mcnerror.ErrHostAlreadyInState{Name: "test", State: state.Stopped}, err)
+}
@@ -0,0 +1,132 @@
+package host
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// activityProbe is swapped in tests.
+var activityProbe = probeActivity
+
+// LastActivity returns when the Docker engine of the machine was last used,
+// judging from its running containers and the events of the engine, or when
+// the machine booted if the engine was not used since. The machine must be
+// running.
+func (h *Host) LastActivity() (time.Time, error) {
+	return activityProbe(h)
+}
+
+// IsIdle tells whether the machine has not been used for longer than its
+// IdleTimeout. Machines without an IdleTimeout, or which are not running, are
+// never idle.
+func (h *Host) IsIdle() (bool, error) {
+	if h.HostOptions == nil || h.HostOptions.IdleTimeout <= 0 {
+		return false, nil
+	}
+
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return false, err
+	}
+	if s != state.Running {
+		return false, nil
+	}
+
+	last, err := h.LastActivity()
+	if err != nil {
+		return false, err
+	}
+
+	return time.Since(last) > h.HostOptions.IdleTimeout, nil
+}
+
+// StopIfIdle stops the machine when it is idle, see IsIdle, sending an
+// EventIdleStopped. It tells whether the machine was stopped.
+func (h *Host) StopIfIdle() (bool, error) {
+	idle, err := h.IsIdle()
+	if err != nil || !idle {
+		return false, err
+	}
+
+	log.Infof("Machine %q has been idle for more than %s", h.Name, h.HostOptions.IdleTimeout)
+	if err := h.Stop(); err != nil {
+		return false, err
+	}
+
+	h.emit(EventIdleStopped, fmt.Sprintf("The machine was stopped after being idle for more than %s", h.HostOptions.IdleTimeout))
+
+	return true, nil
+}
+
+// probeActivity asks the machine over SSH for its clock, uptime, running
+// containers and last engine event. The times are measured with the clock of
+// the machine and converted to the local clock, which may differ.
+func probeActivity(h *Host) (time.Time, error) {
+	now, err := h.runInt("date +%s")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	uptime, err := h.runInt("awk '{print int($1)}' /proc/uptime")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	command, err := h.dockerCommand("ps -q")
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	containers, err := h.RunSSHCommand(command)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error listing the containers of %q: %s", h.Name, err)
+	}
+
+	lastEvent := int64(0)
+	if strings.TrimSpace(containers) == "" {
+		command, err := h.dockerCommand(fmt.Sprintf("events --since %d --until %d --format '{{.Time}}'", now-uptime, now))
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		events, err := h.RunSSHCommand(command + " | tail -n 1")
+		if err != nil {
+			return time.Time{}, fmt.Errorf("Error listing the events of %q: %s", h.Name, err)
+		}
+		lastEvent, _ = strconv.ParseInt(strings.TrimSpace(events), 10, 64)
+	}
+
+	return lastActivity(time.Now(), now, uptime, containers, lastEvent), nil
+}
+
+// lastActivity returns the local time of the last activity of a machine,
+// given the times measured on the machine, in seconds since the epoch.
+func lastActivity(localNow time.Time, now, uptime int64, containers string, lastEvent int64) time.Time {
+	if strings.TrimSpace(containers) != "" {
+		return localNow
+	}
+
+	last := now - uptime
+	if lastEvent > last {
+		last = lastEvent
+	}
+	if last > now {
+		last = now
+	}
+
+	return localNow.Add(-time.Duration(now-last) * time.Second)
+}
+
+func (h *Host) runInt(command string) (int64, error) {
+	output, err := h.RunSSHCommand(command)
+	if err != nil {
+		return 0, fmt.Errorf("Error running %q on %q: %s", command, h.Name, err)
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+}
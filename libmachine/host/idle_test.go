@@ -0,0 +1,93 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func withActivityProbe(probe func(h *Host) (time.Time, error), f func()) {
+	defer func(saved func(h *Host) (time.Time, error)) {
+		activityProbe = saved
+	}(activityProbe)
+
+	activityProbe = probe
+
+	f()
+}
+
+func TestLastActivity(t *testing.T) {
+	localNow := time.Date(2017, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Running containers keep the machine busy
+	assert.Equal(t, localNow, lastActivity(localNow, 10000, 3600, "f2d9e3a2b1c0\n", 0))
+
+	// The machine is used from when it booted
+	assert.Equal(t, localNow.Add(-time.Hour), lastActivity(localNow, 10000, 3600, "", 0))
+
+	// The clock of the machine is an hour behind, an event ten minutes ago
+	assert.Equal(t, localNow.Add(-10*time.Minute), lastActivity(localNow, 10000, 3600, "", 9400))
+}
+
+func TestStopIfIdle(t *testing.T) {
+	events := recordEvents()
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{IdleTimeout: time.Hour},
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+
+	var stopped bool
+	var err error
+	withActivityProbe(func(h *Host) (time.Time, error) {
+		return time.Now().Add(-2 * time.Hour), nil
+	}, func() {
+		stopped, err = host.StopIfIdle()
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, stopped)
+	assert.Equal(t, state.Stopped, host.Driver.(*fakedriver.Driver).MockState)
+	assert.Contains(t, *events, EventIdleStopped)
+}
+
+func TestStopIfIdleRecentlyUsed(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{IdleTimeout: time.Hour},
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+
+	var stopped bool
+	var err error
+	withActivityProbe(func(h *Host) (time.Time, error) {
+		return time.Now().Add(-time.Minute), nil
+	}, func() {
+		stopped, err = host.StopIfIdle()
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, stopped)
+}
+
+func TestIsIdleWithoutTimeout(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{},
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+
+	idle, err := host.IsIdle()
+
+	assert.NoError(t, err)
+	assert.False(t, idle)
+}
@@ -0,0 +1,51 @@
+package host
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+// LastKnown is what was last learnt about a machine. It is saved with the
+// machine so that listings can show something useful when the provider
+// cannot be reached.
+type LastKnown struct {
+	State         state.State
+	StateTime     time.Time
+	SSHTime       time.Time
+	EngineVersion string
+}
+
+// RecordState records the state the machine was seen in. It returns whether
+// the state differs from the last known one.
+func (h *Host) RecordState(s state.State) bool {
+	last := h.lastKnown()
+	changed := last.StateTime.IsZero() || last.State != s
+
+	last.State = s
+	last.StateTime = time.Now()
+
+	return changed
+}
+
+// RecordEngineVersion records the version of the engine of the machine. It
+// returns whether the version differs from the last known one.
+func (h *Host) RecordEngineVersion(version string) bool {
+	last := h.lastKnown()
+	changed := last.EngineVersion != version
+
+	last.EngineVersion = version
+
+	return changed
+}
+
+func (h *Host) recordSSH() {
+	h.lastKnown().SSHTime = time.Now()
+}
+
+func (h *Host) lastKnown() *LastKnown {
+	if h.LastKnown == nil {
+		h.LastKnown = &LastKnown{}
+	}
+	return h.LastKnown
+}
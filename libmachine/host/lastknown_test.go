@@ -0,0 +1,39 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordState(t *testing.T) {
+	host := &Host{}
+
+	assert.True(t, host.RecordState(state.Running))
+	assert.False(t, host.RecordState(state.Running))
+	assert.True(t, host.RecordState(state.Stopped))
+	assert.Equal(t, state.Stopped, host.LastKnown.State)
+	assert.False(t, host.LastKnown.StateTime.IsZero())
+}
+
+func TestRecordEngineVersion(t *testing.T) {
+	host := &Host{}
+
+	assert.True(t, host.RecordEngineVersion("1.13.1"))
+	assert.False(t, host.RecordEngineVersion("1.13.1"))
+	assert.Equal(t, "1.13.1", host.LastKnown.EngineVersion)
+}
+
+func TestStopRecordsState(t *testing.T) {
+	host := &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+
+	assert.NoError(t, host.Stop())
+	assert.Equal(t, state.Stopped, host.LastKnown.State)
+}
@@ -0,0 +1,43 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// CheckPreemption tells whether the provider reclaimed the machine, sending
+// an EventPreempted if so. When RecreateOnPreemption is set, the machine is
+// brought back on new hardware, keeping its disk, and an EventRecreated or
+// EventRecreateFailed is sent. Machines of drivers unable to tell are never
+// reported as preempted.
+func (h *Host) CheckPreemption() (bool, error) {
+	preempted, err := drivers.Preempted(h.Driver)
+	if err == drivers.ErrNotImplemented {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !preempted {
+		return false, nil
+	}
+
+	log.Warnf("Machine %q was preempted by the provider", h.Name)
+	h.emit(EventPreempted, "The machine was reclaimed by the provider")
+
+	if h.HostOptions == nil || !h.HostOptions.RecreateOnPreemption {
+		return true, nil
+	}
+
+	if err := h.Start(); err != nil {
+		h.emit(EventRecreateFailed, err.Error())
+		return true, fmt.Errorf("Error recreating preempted machine %q: %s", h.Name, err)
+	}
+
+	h.emit(EventRecreated, "The machine was recreated after being preempted")
+
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func recordEvents() *[]EventType {
+	events := []EventType{}
+	AddEventHandler(func(event Event) {
+		events = append(events, event.Type)
+	})
+	return &events
+}
+
+func TestCheckPreemptionNotPreempted(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{},
+		Driver: &fakedriver.Driver{
+			MockState: state.Stopped,
+		},
+	}
+
+	preempted, err := host.CheckPreemption()
+
+	assert.NoError(t, err)
+	assert.False(t, preempted)
+}
+
+func TestCheckPreemption(t *testing.T) {
+	events := recordEvents()
+	driver := &fakedriver.Driver{
+		MockState:     state.Stopped,
+		MockPreempted: true,
+	}
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{},
+		Driver:      driver,
+	}
+
+	preempted, err := host.CheckPreemption()
+
+	assert.NoError(t, err)
+	assert.True(t, preempted)
+	assert.Equal(t, state.Stopped, driver.MockState)
+	assert.Contains(t, *events, EventPreempted)
+	assert.NotContains(t, *events, EventRecreated)
+}
+
+func TestCheckPreemptionRecreate(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provision.NewNetstatProvisioner(),
+	})
+
+	events := recordEvents()
+	driver := &fakedriver.Driver{
+		MockState:     state.Stopped,
+		MockPreempted: true,
+	}
+	host := &Host{
+		Name: "test",
+		HostOptions: &Options{
+			RecreateOnPreemption: true,
+		},
+		Driver: driver,
+	}
+
+	preempted, err := host.CheckPreemption()
+
+	assert.NoError(t, err)
+	assert.True(t, preempted)
+	assert.Equal(t, state.Running, driver.MockState)
+	assert.Contains(t, *events, EventRecreated)
+}
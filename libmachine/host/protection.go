@@ -0,0 +1,25 @@
+package host
+
+import "github.com/docker/machine/libmachine/mcnerror"
+
+// SetProtected protects the machine against removal, or lifts the
+// protection.
+func (h *Host) SetProtected(protected bool) {
+	h.HostOptions.Protected = protected
+}
+
+// IsProtected tells whether the machine is protected against removal.
+func (h *Host) IsProtected() bool {
+	return h.HostOptions != nil && h.HostOptions.Protected
+}
+
+// CheckRemovable returns ErrHostProtected for a protected machine, unless
+// overrideProtection is set. The long-lived machines shared by a team are
+// protected for them not to be removed by mistake.
+func (h *Host) CheckRemovable(overrideProtection bool) error {
+	if h.IsProtected() && !overrideProtection {
+		return mcnerror.ErrHostProtected{Name: h.Name}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRemovable(t *testing.T) {
+	host := &Host{Name: "shared", HostOptions: &Options{}}
+
+	assert.NoError(t, host.CheckRemovable(false))
+
+	host.SetProtected(true)
+
+	assert.True(t, host.IsProtected())
+	assert.Equal(t, mcnerror.ErrHostProtected{Name: "shared"}, host.CheckRemovable(false))
+	assert.NoError(t, host.CheckRemovable(true))
+
+	host.SetProtected(false)
+
+	assert.NoError(t, host.CheckRemovable(false))
+}
+
+func TestCheckRemovableWithoutOptions(t *testing.T) {
+	host := &Host{Name: "legacy"}
+
+	assert.False(t, host.IsProtected())
+	assert.NoError(t, host.CheckRemovable(false))
+}
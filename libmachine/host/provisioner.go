@@ -0,0 +1,44 @@
+package host
+
+import (
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision"
+)
+
+// Provisioner returns the provisioner of the machine. It is detected over
+// SSH the first time, then created again from DetectedProvisioner, which the
+// caller saves along with the machine. The provisioner named in the options
+// is used instead of the detected one when set.
+func (h *Host) Provisioner() (provision.Provisioner, error) {
+	forced := ""
+	if h.HostOptions != nil {
+		forced = h.HostOptions.Provisioner
+	}
+
+	if cached := h.DetectedProvisioner; cached != nil && (forced == "" || strings.EqualFold(forced, cached.Name)) {
+		provisioner, err := provision.NewProvisioner(cached, h.Driver)
+		if err == nil {
+			return provisioner, nil
+		}
+		log.Debugf("Error creating the %s provisioner of %q again, detecting it: %s", cached.Name, h.Name, err)
+	}
+
+	detected, provisioner, err := provision.Detect(h.Driver, forced)
+	if err != nil {
+		return nil, err
+	}
+
+	if detected != nil {
+		h.DetectedProvisioner = detected
+	}
+
+	return provisioner, nil
+}
+
+// ForgetProvisioner drops the cached provisioner, for it to be detected
+// again the next time, e.g. after the OS of the machine was replaced.
+func (h *Host) ForgetProvisioner() {
+	h.DetectedProvisioner = nil
+}
@@ -0,0 +1,73 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvisionerFromCache(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &provision.FakeProvisioner{},
+	})
+
+	host := &Host{
+		Name:        "test",
+		Driver:      &fakedriver.Driver{},
+		HostOptions: &Options{},
+		DetectedProvisioner: &provision.Detected{
+			Name:      "Ubuntu-SystemD",
+			OsRelease: &provision.OsRelease{ID: "ubuntu", VersionID: "16.04"},
+		},
+	}
+
+	provisioner, err := host.Provisioner()
+
+	assert.NoError(t, err)
+	assert.IsType(t, &provision.UbuntuSystemdProvisioner{}, provisioner)
+
+	info, err := provisioner.GetOsReleaseInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "16.04", info.VersionID)
+}
+
+func TestProvisionerForcedOverridesCache(t *testing.T) {
+	fake := &provision.FakeProvisioner{}
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: fake,
+	})
+
+	host := &Host{
+		Name:        "test",
+		Driver:      &fakedriver.Driver{},
+		HostOptions: &Options{Provisioner: "debian"},
+		DetectedProvisioner: &provision.Detected{
+			Name: "Ubuntu-SystemD",
+		},
+	}
+
+	provisioner, err := host.Provisioner()
+
+	assert.NoError(t, err)
+	assert.Equal(t, fake, provisioner)
+}
+
+func TestProvisionerForcedMatchesCache(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		Driver:      &fakedriver.Driver{},
+		HostOptions: &Options{Provisioner: "debian"},
+		DetectedProvisioner: &provision.Detected{
+			Name: "Debian",
+		},
+	}
+
+	provisioner, err := host.Provisioner()
+
+	assert.NoError(t, err)
+	assert.IsType(t, &provision.DebianProvisioner{}, provisioner)
+}
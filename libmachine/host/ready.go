@@ -0,0 +1,161 @@
+package host
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcndockerclient"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// ReadyLevel tells how far a machine must be up for WaitUntil to return.
+// Each level implies the levels before it.
+type ReadyLevel int
+
+const (
+	// InstanceRunning is reached when the driver reports the machine running.
+	InstanceRunning ReadyLevel = iota
+	// SSHReady is reached when commands can be run over SSH.
+	SSHReady
+	// EngineReady is reached when the Docker API answers over TLS.
+	EngineReady
+	// SwarmReady is reached when the swarm containers are running. Machines
+	// not part of a swarm reach it along with EngineReady.
+	SwarmReady
+)
+
+var (
+	readyLevelNames = map[ReadyLevel]string{
+		InstanceRunning: "InstanceRunning",
+		SSHReady:        "SSHReady",
+		EngineReady:     "EngineReady",
+		SwarmReady:      "SwarmReady",
+	}
+
+	readinessProbes = map[ReadyLevel]func(h *Host) error{
+		InstanceRunning: probeInstanceRunning,
+		SSHReady:        probeSSH,
+		EngineReady:     probeEngine,
+		SwarmReady:      probeSwarm,
+	}
+
+	readyPollInterval = 3 * time.Second
+)
+
+func (l ReadyLevel) String() string {
+	if name, ok := readyLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("ReadyLevel(%d)", int(l))
+}
+
+// ErrNotReady is returned when a machine does not reach a readiness level
+// in time.
+type ErrNotReady struct {
+	Name  string
+	Level ReadyLevel
+	Cause error
+}
+
+func (e ErrNotReady) Error() string {
+	return fmt.Sprintf("Machine %q is not %s: %s", e.Name, e.Level, e.Cause)
+}
+
+// WaitUntil waits for the machine to reach the given readiness level, going
+// through each lower level first. The timeout covers all the levels.
+func (h *Host) WaitUntil(ready ReadyLevel, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for level := InstanceRunning; level <= ready; level++ {
+		probe, ok := readinessProbes[level]
+		if !ok {
+			return fmt.Errorf("Unknown readiness level %s", level)
+		}
+
+		log.Debugf("Waiting for %q to be %s...", h.Name, level)
+
+		for {
+			err := probe(h)
+			if err == nil {
+				break
+			}
+
+			if time.Now().Add(readyPollInterval).After(deadline) {
+				return ErrNotReady{
+					Name:  h.Name,
+					Level: level,
+					Cause: err,
+				}
+			}
+
+			log.Debugf("%q is not %s yet: %s", h.Name, level, err)
+			time.Sleep(readyPollInterval)
+		}
+	}
+
+	return nil
+}
+
+func probeInstanceRunning(h *Host) error {
+	currentState, err := h.Driver.GetState()
+	if err != nil {
+		return err
+	}
+
+	if currentState != state.Running {
+		return fmt.Errorf("machine is %s", strings.ToLower(currentState.String()))
+	}
+
+	return nil
+}
+
+func probeSSH(h *Host) error {
+	_, err := h.RunSSHCommand("exit 0")
+	return err
+}
+
+func probeEngine(h *Host) error {
+	url, err := h.URL()
+	if err != nil {
+		return err
+	}
+
+	_, err = mcndockerclient.DockerVersion(&mcndockerclient.RemoteDocker{
+		HostURL:    url,
+		AuthOption: h.AuthOptions(),
+	})
+	return err
+}
+
+func probeSwarm(h *Host) error {
+	if h.HostOptions == nil || h.HostOptions.SwarmOptions == nil || !h.HostOptions.SwarmOptions.IsSwarm {
+		return nil
+	}
+
+	containers := []string{}
+	if h.HostOptions.SwarmOptions.Master {
+		containers = append(containers, "swarm-agent-master")
+	}
+	if h.HostOptions.SwarmOptions.Agent {
+		containers = append(containers, "swarm-agent")
+	}
+
+	for _, container := range containers {
+		command, err := h.dockerCommand(fmt.Sprintf("inspect -f '{{.State.Running}}' %s", container))
+		if err != nil {
+			return err
+		}
+
+		output, err := h.RunSSHCommand(command)
+		if err != nil {
+			return fmt.Errorf("container %s not found: %s", container, err)
+		}
+		if strings.TrimSpace(output) != "true" {
+			return fmt.Errorf("container %s is not running", container)
+		}
+	}
+
+	return nil
+}
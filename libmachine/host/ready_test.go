@@ -0,0 +1,126 @@
+package host
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+)
+
+func withReadinessProbes(probes map[ReadyLevel]func(h *Host) error, f func()) {
+	defer func(saved map[ReadyLevel]func(h *Host) error, interval time.Duration) {
+		readinessProbes = saved
+		readyPollInterval = interval
+	}(readinessProbes, readyPollInterval)
+
+	readinessProbes = probes
+	readyPollInterval = time.Millisecond
+
+	f()
+}
+
+func TestWaitUntilInstanceRunning(t *testing.T) {
+	host := &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+
+	if err := host.WaitUntil(InstanceRunning, time.Second); err != nil {
+		t.Fatalf("Expected no error but got one: %s", err)
+	}
+}
+
+func TestWaitUntilChecksLowerLevels(t *testing.T) {
+	probed := []ReadyLevel{}
+	probe := func(level ReadyLevel) func(h *Host) error {
+		return func(h *Host) error {
+			probed = append(probed, level)
+			return nil
+		}
+	}
+
+	withReadinessProbes(map[ReadyLevel]func(h *Host) error{
+		InstanceRunning: probe(InstanceRunning),
+		SSHReady:        probe(SSHReady),
+		EngineReady:     probe(EngineReady),
+		SwarmReady:      probe(SwarmReady),
+	}, func() {
+		host := &Host{Name: "test"}
+
+		if err := host.WaitUntil(EngineReady, time.Second); err != nil {
+			t.Fatalf("Expected no error but got one: %s", err)
+		}
+	})
+
+	expected := []ReadyLevel{InstanceRunning, SSHReady, EngineReady}
+	if len(probed) != len(expected) {
+		t.Fatalf("Expected levels %v to be probed, got %v", expected, probed)
+	}
+	for i := range expected {
+		if probed[i] != expected[i] {
+			t.Fatalf("Expected levels %v to be probed, got %v", expected, probed)
+		}
+	}
+}
+
+func TestWaitUntilRetries(t *testing.T) {
+	attempts := 0
+
+	withReadinessProbes(map[ReadyLevel]func(h *Host) error{
+		InstanceRunning: func(h *Host) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("machine is starting")
+			}
+			return nil
+		},
+	}, func() {
+		host := &Host{Name: "test"}
+
+		if err := host.WaitUntil(InstanceRunning, time.Second); err != nil {
+			t.Fatalf("Expected no error but got one: %s", err)
+		}
+	})
+
+	if attempts != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestWaitUntilTimeout(t *testing.T) {
+	host := &Host{
+		Name: "test",
+		Driver: &fakedriver.Driver{
+			MockState: state.Stopped,
+		},
+	}
+
+	withReadinessProbes(map[ReadyLevel]func(h *Host) error{
+		InstanceRunning: probeInstanceRunning,
+	}, func() {
+		err := host.WaitUntil(SSHReady, 10*time.Millisecond)
+
+		notReady, ok := err.(ErrNotReady)
+		if !ok {
+			t.Fatalf("Expected ErrNotReady, got %v", err)
+		}
+		if notReady.Level != InstanceRunning {
+			t.Fatalf("Expected the machine not to be %s, got %s", InstanceRunning, notReady.Level)
+		}
+	})
+}
+
+func TestProbeSwarmWithoutSwarm(t *testing.T) {
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{},
+	}
+
+	if err := probeSwarm(host); err != nil {
+		t.Fatalf("Expected no error but got one: %s", err)
+	}
+}
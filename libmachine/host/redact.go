@@ -0,0 +1,61 @@
+package host
+
+import (
+	"encoding/json"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/secrets"
+)
+
+// SensitiveFields returns the fields of the driver configuration holding
+// secrets the driver declares, on top of the ones of secrets.SensitiveFields.
+func (h *Host) SensitiveFields() []string {
+	if h.Driver == nil {
+		return nil
+	}
+
+	fields, err := drivers.GetSensitiveFields(h.Driver)
+	if err != nil && err != drivers.ErrNotImplemented {
+		log.Debugf("Error getting the sensitive fields of the %s driver: %s", h.DriverName, err)
+	}
+	return fields
+}
+
+// MarshalRedacted returns the configuration of the machine as JSON, with the
+// secrets of its driver replaced by <REDACTED>, for it to be shown or shared.
+func MarshalRedacted(h *Host) ([]byte, error) {
+	data, err := json.Marshal(h)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets.RedactHost(data, h.SensitiveFields())
+}
+
+// RedactSecretsInLogs has the secrets of the driver replaced by <REDACTED>
+// wherever they appear in the logs, e.g. in the debug output of the
+// provider APIs.
+func (h *Host) RedactSecretsInLogs() {
+	config := h.RawDriver
+	if config == nil && h.Driver != nil {
+		var err error
+		if config, err = json.Marshal(h.Driver); err != nil {
+			log.Debugf("Error reading the configuration of %s to redact its secrets: %s", h.Name, err)
+			return
+		}
+	}
+	if config == nil {
+		return
+	}
+
+	values, err := secrets.SensitiveValues(config, h.SensitiveFields())
+	if err != nil {
+		log.Debugf("Error reading the configuration of %s to redact its secrets: %s", h.Name, err)
+		return
+	}
+
+	for _, value := range values {
+		log.RedactValue(value)
+	}
+}
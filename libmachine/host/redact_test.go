@@ -0,0 +1,53 @@
+package host
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/secrets"
+	"github.com/stretchr/testify/assert"
+)
+
+type sensitiveDriver struct {
+	*fakedriver.Driver
+	APIToken string
+}
+
+func (d *sensitiveDriver) GetSensitiveFields() ([]string, error) {
+	return []string{"APIToken"}, nil
+}
+
+func TestMarshalRedacted(t *testing.T) {
+	h := &Host{
+		Name:       "dev",
+		DriverName: "fakedriver",
+		Driver: &sensitiveDriver{
+			Driver:   &fakedriver.Driver{BaseDriver: &drivers.BaseDriver{MachineName: "dev"}},
+			APIToken: "t0ken-of-dev",
+		},
+	}
+
+	data, err := MarshalRedacted(h)
+	assert.NoError(t, err)
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &obj))
+	assert.Equal(t, secrets.Redacted, obj["Driver"].(map[string]interface{})["APIToken"])
+	assert.False(t, strings.Contains(string(data), "t0ken-of-dev"))
+}
+
+func TestRedactSecretsInLogs(t *testing.T) {
+	h := &Host{
+		Name:      "dev",
+		RawDriver: []byte(`{"AccessToken": "t0ken-in-logs"}`),
+	}
+
+	h.RedactSecretsInLogs()
+	log.Debugf("Using %s", "t0ken-in-logs")
+
+	assert.Contains(t, log.History(), "Using <REDACTED>")
+}
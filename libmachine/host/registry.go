@@ -0,0 +1,89 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/engine"
+)
+
+// AddRegistryMirror adds a registry mirror to the engine of the machine.
+// The machine is provisioned again for the engine to use it, and the engine
+// options keep it: the host has to be saved afterwards.
+func (h *Host) AddRegistryMirror(mirror string) error {
+	return h.updateEngineOptions(func(o *engine.Options) bool {
+		return addString(&o.RegistryMirror, mirror)
+	})
+}
+
+// RemoveRegistryMirror removes a registry mirror from the engine of the
+// machine, see AddRegistryMirror.
+func (h *Host) RemoveRegistryMirror(mirror string) error {
+	return h.updateEngineOptions(func(o *engine.Options) bool {
+		return removeString(&o.RegistryMirror, mirror)
+	})
+}
+
+// AddInsecureRegistry lets the engine of the machine pull from a registry
+// without TLS verification, see AddRegistryMirror.
+func (h *Host) AddInsecureRegistry(registry string) error {
+	return h.updateEngineOptions(func(o *engine.Options) bool {
+		return addString(&o.InsecureRegistry, registry)
+	})
+}
+
+// RemoveInsecureRegistry removes an insecure registry from the engine of the
+// machine, see AddRegistryMirror.
+func (h *Host) RemoveInsecureRegistry(registry string) error {
+	return h.updateEngineOptions(func(o *engine.Options) bool {
+		return removeString(&o.InsecureRegistry, registry)
+	})
+}
+
+// updateEngineOptions applies the update to the engine options and
+// provisions the machine again when they changed. The options are left as
+// they were when provisioning fails.
+func (h *Host) updateEngineOptions(update func(*engine.Options) bool) error {
+	if h.HostOptions == nil || h.HostOptions.EngineOptions == nil {
+		return fmt.Errorf("Machine %q has no engine options", h.Name)
+	}
+
+	previous := *h.HostOptions.EngineOptions
+	if !update(h.HostOptions.EngineOptions) {
+		return nil
+	}
+
+	if err := h.Reprovision(); err != nil {
+		*h.HostOptions.EngineOptions = previous
+		return err
+	}
+
+	return nil
+}
+
+// addString appends the value to the list unless it is there already, and
+// returns whether it was added. The list gets a new backing array.
+func addString(list *[]string, value string) bool {
+	if containsString(*list, value) {
+		return false
+	}
+
+	*list = append(append([]string{}, *list...), value)
+	return true
+}
+
+// removeString removes the value from the list and returns whether it was
+// there.
+func removeString(list *[]string, value string) bool {
+	if !containsString(*list, value) {
+		return false
+	}
+
+	kept := []string{}
+	for _, v := range *list {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	*list = kept
+	return true
+}
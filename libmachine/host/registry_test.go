@@ -0,0 +1,72 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRegistryMirror(t *testing.T) {
+	provisioner := &provisionRecorder{FakeProvisioner: &provision.FakeProvisioner{}}
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provisioner,
+	})
+
+	host := newReprovisionTestHost(state.Running)
+	host.HostOptions.EngineOptions = &engine.Options{
+		RegistryMirror: []string{"https://mirror-1.example.com"},
+	}
+
+	assert.NoError(t, host.AddRegistryMirror("https://mirror-2.example.com"))
+
+	assert.True(t, provisioner.provisioned)
+	assert.Equal(t, []string{"https://mirror-1.example.com", "https://mirror-2.example.com"}, host.HostOptions.EngineOptions.RegistryMirror)
+}
+
+func TestAddInsecureRegistryAlreadyThere(t *testing.T) {
+	provisioner := &provisionRecorder{FakeProvisioner: &provision.FakeProvisioner{}}
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: provisioner,
+	})
+
+	host := newReprovisionTestHost(state.Running)
+	host.HostOptions.EngineOptions = &engine.Options{
+		InsecureRegistry: []string{"registry.local:5000"},
+	}
+
+	assert.NoError(t, host.AddInsecureRegistry("registry.local:5000"))
+
+	assert.False(t, provisioner.provisioned)
+}
+
+func TestRemoveInsecureRegistry(t *testing.T) {
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &provisionRecorder{FakeProvisioner: &provision.FakeProvisioner{}},
+	})
+
+	host := newReprovisionTestHost(state.Running)
+	host.HostOptions.EngineOptions = &engine.Options{
+		InsecureRegistry: []string{"registry.local:5000", "other.local:5000"},
+	}
+
+	assert.NoError(t, host.RemoveInsecureRegistry("registry.local:5000"))
+
+	assert.Equal(t, []string{"other.local:5000"}, host.HostOptions.EngineOptions.InsecureRegistry)
+}
+
+func TestRemoveRegistryMirrorStoppedMachine(t *testing.T) {
+	host := newReprovisionTestHost(state.Stopped)
+	host.HostOptions.EngineOptions = &engine.Options{
+		RegistryMirror: []string{"https://mirror-1.example.com"},
+	}
+
+	assert.Error(t, host.RemoveRegistryMirror("https://mirror-1.example.com"))
+
+	assert.Equal(t, []string{"https://mirror-1.example.com"}, host.HostOptions.EngineOptions.RegistryMirror)
+}
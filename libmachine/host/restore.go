@@ -0,0 +1,24 @@
+package host
+
+// ConfigRestorer is implemented by the stores keeping backups of the
+// configuration of the machines.
+type ConfigRestorer interface {
+	// RestoreConfigBackup replaces the configuration of a machine with its
+	// most recent valid backup
+	RestoreConfigBackup(name string) (*Host, error)
+}
+
+// RestoreConfigBackup replaces the configuration of the machine with its most
+// recent valid backup, for when config.json was damaged, e.g. truncated by an
+// interrupted write. The host is reloaded from the backup. As a damaged
+// configuration cannot be loaded, only the name of the host must be set.
+func (h *Host) RestoreConfigBackup(restorer ConfigRestorer) error {
+	restored, err := restorer.RestoreConfigBackup(h.Name)
+	if err != nil {
+		return err
+	}
+
+	*h = *restored
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package host
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConfigRestorer struct {
+	backups map[string]*Host
+}
+
+func (r *fakeConfigRestorer) RestoreConfigBackup(name string) (*Host, error) {
+	backup, ok := r.backups[name]
+	if !ok {
+		return nil, errors.New("no backup")
+	}
+	return backup, nil
+}
+
+func TestRestoreConfigBackup(t *testing.T) {
+	restorer := &fakeConfigRestorer{
+		backups: map[string]*Host{
+			"default": {Name: "default", DriverName: "virtualbox"},
+		},
+	}
+
+	host := &Host{Name: "default"}
+	err := host.RestoreConfigBackup(restorer)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "virtualbox", host.DriverName)
+
+	host = &Host{Name: "other"}
+	err = host.RestoreConfigBackup(restorer)
+
+	assert.EqualError(t, err, "no backup")
+	assert.Empty(t, host.DriverName)
+}
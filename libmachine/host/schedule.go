@@ -0,0 +1,47 @@
+package host
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// ApplySchedule starts or stops the machine when a start or stop time of its
+// Schedule is in (since, until], sending an EventScheduleStarted or an
+// EventScheduleStopped. It returns the state the machine was put in, or
+// state.None when it was left alone.
+func (h *Host) ApplySchedule(since, until time.Time) (state.State, error) {
+	if h.HostOptions == nil || h.HostOptions.Schedule.IsEmpty() {
+		return state.None, nil
+	}
+
+	due, err := h.HostOptions.Schedule.Due(since, until)
+	if err != nil || due == state.None {
+		return state.None, err
+	}
+
+	current, err := h.Driver.GetState()
+	if err != nil {
+		return state.None, err
+	}
+
+	switch {
+	case due == state.Running && current != state.Running:
+		log.Infof("Starting %q on schedule", h.Name)
+		if err := h.Start(); err != nil {
+			return state.None, err
+		}
+		h.emit(EventScheduleStarted, "The machine was started by its schedule")
+		return state.Running, nil
+	case due == state.Stopped && current == state.Running:
+		log.Infof("Stopping %q on schedule", h.Name)
+		if err := h.Stop(); err != nil {
+			return state.None, err
+		}
+		h.emit(EventScheduleStopped, "The machine was stopped by its schedule")
+		return state.Stopped, nil
+	}
+
+	return state.None, nil
+}
@@ -0,0 +1,71 @@
+package host
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+// unverifiedHostKeyOptions are the options of the machines the host key of
+// which is not recorded, the same as the ones of the external SSH client.
+var unverifiedHostKeyOptions = []string{
+	"StrictHostKeyChecking=no",
+	"UserKnownHostsFile=/dev/null",
+	"LogLevel=quiet",
+}
+
+// SSHConfigEntry returns the Host block of an ssh_config file for the
+// machine, for ssh to reach it by its name, e.g. ssh default. The host key
+// recorded when provisioning is checked, as with docker-machine ssh. The
+// machine must be running for its address to be known.
+func (h *Host) SSHConfigEntry() (string, error) {
+	hostname, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return "", fmt.Errorf("Error getting the SSH address of %s: %s", h.Name, err)
+	}
+
+	port, err := h.Driver.GetSSHPort()
+	if err != nil {
+		return "", fmt.Errorf("Error getting the SSH port of %s: %s", h.Name, err)
+	}
+
+	options := []string{
+		"HostName=" + hostname,
+		fmt.Sprintf("Port=%d", port),
+		"User=" + h.Driver.GetSSHUsername(),
+	}
+	if keyPath := h.Driver.GetSSHKeyPath(); keyPath != "" {
+		options = append(options, "IdentityFile="+keyPath, "IdentitiesOnly=yes")
+	}
+
+	knownHosts := drivers.KnownHostsOf(h.Driver)
+	hostKeyOptions := ssh.HostKeyOptions(knownHosts)
+	if len(hostKeyOptions) == 0 {
+		hostKeyOptions = unverifiedHostKeyOptions
+	}
+	options = append(options, hostKeyOptions...)
+
+	lines := []string{"Host " + h.Name}
+	for _, option := range options {
+		parts := strings.SplitN(option, "=", 2)
+		lines = append(lines, fmt.Sprintf("    %s %s", parts[0], sshConfigValue(parts[1])))
+	}
+
+	// The command is the rest of the line, it is not to be quoted
+	if bastion := drivers.ResolveSSHBastion(h.Driver); bastion != nil {
+		lines = append(lines, "    ProxyCommand "+bastion.ProxyCommand(knownHosts.File))
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// sshConfigValue quotes the values with spaces, e.g. the paths of the key
+// and of the known_hosts file in the home of a Windows user.
+func sshConfigValue(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
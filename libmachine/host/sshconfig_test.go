@@ -0,0 +1,109 @@
+package host
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+type sshConfigDriver struct {
+	*fakedriver.Driver
+	keyPath    string
+	knownHosts ssh.KnownHosts
+}
+
+func (d *sshConfigDriver) KnownHosts() ssh.KnownHosts {
+	return d.knownHosts
+}
+
+func (d *sshConfigDriver) GetSSHHostname() (string, error) {
+	return "192.168.99.100", nil
+}
+
+func (d *sshConfigDriver) GetSSHPort() (int, error) {
+	return 22, nil
+}
+
+func (d *sshConfigDriver) GetSSHUsername() string {
+	return "docker"
+}
+
+func (d *sshConfigDriver) GetSSHKeyPath() string {
+	return d.keyPath
+}
+
+func TestSSHConfigEntry(t *testing.T) {
+	host := &Host{
+		Name:   "dev",
+		Driver: &sshConfigDriver{Driver: &fakedriver.Driver{}, keyPath: "/Users/Jane Doe/.docker/machine/machines/dev/id_rsa"},
+	}
+
+	entry, err := host.SSHConfigEntry()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `Host dev
+    HostName 192.168.99.100
+    Port 22
+    User docker
+    IdentityFile "/Users/Jane Doe/.docker/machine/machines/dev/id_rsa"
+    IdentitiesOnly yes
+    StrictHostKeyChecking no
+    UserKnownHostsFile /dev/null
+    LogLevel quiet
+`, entry)
+}
+
+func TestSSHConfigEntryRecordedHostKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-ssh-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	knownHosts := filepath.Join(dir, "known_hosts")
+	hostKey := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHJz0kdy0xOwGQ9mLE3sCmYMoAUc07b+bz3MbpZsK+hc"
+	assert.NoError(t, ioutil.WriteFile(knownHosts, []byte("dev.1a2b3c4d "+hostKey+"\n"), 0600))
+
+	host := &Host{
+		Name: "dev",
+		Driver: &sshConfigDriver{
+			Driver:     &fakedriver.Driver{},
+			knownHosts: ssh.KnownHosts{File: knownHosts, Alias: "dev.1a2b3c4d"},
+		},
+	}
+
+	entry, err := host.SSHConfigEntry()
+
+	assert.NoError(t, err)
+	assert.Equal(t, `Host dev
+    HostName 192.168.99.100
+    Port 22
+    User docker
+    StrictHostKeyChecking yes
+    UserKnownHostsFile `+knownHosts+`
+    HostKeyAlias dev.1a2b3c4d
+    CheckHostIP no
+`, entry)
+}
+
+func TestSSHConfigEntryThroughBastion(t *testing.T) {
+	driver := &sshConfigDriver{
+		Driver:  &fakedriver.Driver{BaseDriver: &drivers.BaseDriver{}},
+		keyPath: "/machines/dev/id_rsa",
+	}
+	driver.SetSSHBastion(ssh.Bastion{User: "ubuntu", Host: "bastion.example.com", Port: 22})
+	host := &Host{
+		Name:   "dev",
+		Driver: driver,
+	}
+
+	entry, err := host.SSHConfigEntry()
+
+	assert.NoError(t, err)
+	assert.Contains(t, entry, "\n    ProxyCommand ssh -F /dev/null ")
+	assert.Contains(t, entry, " -i /machines/dev/id_rsa -p 22 -W %h:%p -l ubuntu bastion.example.com\n")
+}
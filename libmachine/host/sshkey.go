@@ -0,0 +1,182 @@
+package host
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+)
+
+var sshKeyPollInterval = 2 * time.Second
+
+// sshKeyDriver connects to the machine of a driver with another key than the
+// key of the driver.
+type sshKeyDriver struct {
+	drivers.Driver
+	keyPath string
+}
+
+func (d *sshKeyDriver) GetSSHKeyPath() string {
+	return d.keyPath
+}
+
+// RegenerateSSHKey replaces the SSH key of the machine with a new key pair,
+// e.g. when the key may have leaked. The new key is authorized on the machine
+// by the provider if the driver supports it, or else over SSH with the
+// current key which is then revoked. The machine is restarted to check that
+// the new key survives it, and the current key is kept until then. The key
+// keeps its path in the store.
+func (h *Host) RegenerateSSHKey() error {
+	keyPath := h.Driver.GetSSHKeyPath()
+	if keyPath == "" {
+		return fmt.Errorf("%q has no SSH key of its own to regenerate", h.Name)
+	}
+
+	oldPublicKey, err := ssh.PublicKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	// Remove what an interrupted regeneration may have left
+	newKeyPath := keyPath + ".new"
+	removeSSHKey(newKeyPath)
+	defer removeSSHKey(newKeyPath)
+
+	log.Infof("Generating a new SSH key for %q...", h.Name)
+	if err := ssh.GenerateSSHKey(newKeyPath); err != nil {
+		return err
+	}
+
+	byProvider := true
+	err = drivers.InstallSSHKey(h.Driver, newKeyPath)
+	if err == drivers.ErrNotImplemented {
+		byProvider = false
+		err = h.authorizeSSHKey(newKeyPath)
+	}
+	if err != nil {
+		return fmt.Errorf("Error authorizing the new SSH key on %q: %s", h.Name, err)
+	}
+
+	if err := h.waitForSSHKey(newKeyPath); err != nil {
+		return err
+	}
+
+	log.Infof("Restarting %q to check the new SSH key...", h.Name)
+	if err := h.Driver.Restart(); err != nil {
+		return err
+	}
+	if err := mcnutils.WaitFor(drivers.MachineInState(h.Driver, state.Running)); err != nil {
+		return err
+	}
+	if err := h.waitForSSHKey(newKeyPath); err != nil {
+		return fmt.Errorf("%s after a restart, keeping the previous key", err)
+	}
+
+	if err := os.Rename(newKeyPath+".pub", keyPath+".pub"); err != nil {
+		return err
+	}
+	if err := os.Rename(newKeyPath, keyPath); err != nil {
+		return err
+	}
+
+	if byProvider {
+		return nil
+	}
+
+	log.Infof("Revoking the previous SSH key of %q...", h.Name)
+	return h.revokeSSHKey(oldPublicKey)
+}
+
+func (h *Host) authorizeSSHKey(keyPath string) error {
+	publicKey, err := ssh.PublicKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Output(authorizeSSHKeyCommand(publicKey)); err != nil {
+		return err
+	}
+
+	return h.persistSSHKeys(client)
+}
+
+// persistSSHKeys saves the authorized keys where they survive a restart. The
+// home directory of boot2docker is restored at boot from its userdata.tar,
+// which is updated, while it is persistent on the other OS.
+func (h *Host) persistSSHKeys(client ssh.Client) error {
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		return err
+	}
+
+	if provisioner.String() != "boot2docker" {
+		return nil
+	}
+
+	_, err = client.Output(persistBoot2DockerSSHKeysCommand)
+	return err
+}
+
+// waitForSSHKey waits until the machine accepts the given key.
+func (h *Host) waitForSSHKey(keyPath string) error {
+	var lastErr error
+	if err := mcnutils.WaitForSpecific(func() bool {
+		client, err := stdSSHClientCreator.CreateSSHClient(&sshKeyDriver{h.Driver, keyPath})
+		if err == nil {
+			_, err = client.Output("exit 0")
+		}
+		lastErr = err
+		return err == nil
+	}, 30, sshKeyPollInterval); err != nil {
+		return fmt.Errorf("%q does not accept the new SSH key: %v", h.Name, lastErr)
+	}
+
+	return nil
+}
+
+func (h *Host) revokeSSHKey(publicKey []byte) error {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return err
+	}
+
+	if _, err := client.Output(revokeSSHKeyCommand(publicKey)); err != nil {
+		return err
+	}
+
+	return h.persistSSHKeys(client)
+}
+
+func authorizeSSHKeyCommand(publicKey []byte) string {
+	return fmt.Sprintf("mkdir -p ~/.ssh && chmod 700 ~/.ssh && printf '%%s\\n' '%s' >> ~/.ssh/authorized_keys", strings.TrimSpace(string(publicKey)))
+}
+
+// revokeSSHKeyCommand removes the lines with the given key from the
+// authorized keys, keeping the permissions of the files. Lines are matched on
+// the base64 key alone, as their options and comments may differ. The
+// boot2docker images also authorize the key in authorized_keys2.
+func revokeSSHKeyCommand(publicKey []byte) string {
+	key := strings.Fields(string(publicKey))[1]
+
+	return fmt.Sprintf("for f in ~/.ssh/authorized_keys ~/.ssh/authorized_keys2; do [ -f $f ] || continue; grep -vF '%s' $f > $f.new; cat $f.new > $f && rm $f.new; done", key)
+}
+
+// persistBoot2DockerSSHKeysCommand copies the authorized keys into the
+// userdata.tar of boot2docker, keeping the other files of the archive.
+const persistBoot2DockerSSHKeysCommand = "sudo sh -c 'tmp=$(mktemp -d) && mkdir -p $tmp/.ssh && { [ ! -f /var/lib/boot2docker/userdata.tar ] || tar xf /var/lib/boot2docker/userdata.tar -C $tmp; } && cp /home/docker/.ssh/authorized_keys* $tmp/.ssh/ && tar cf /var/lib/boot2docker/userdata.tar -C $tmp .; status=$?; rm -rf $tmp; exit $status'"
+
+func removeSSHKey(keyPath string) {
+	os.Remove(keyPath)
+	os.Remove(keyPath + ".pub")
+}
@@ -0,0 +1,152 @@
+package host
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/provision"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/ssh/sshtest"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingSSHClient struct {
+	*sshtest.FakeClient
+	commands *[]string
+}
+
+func (c *recordingSSHClient) Output(command string) (string, error) {
+	*c.commands = append(*c.commands, command)
+	return "", nil
+}
+
+// recordingSSHClientCreator records the keys used to connect and the commands
+// run on the machine. The keys for which reject returns true are refused.
+type recordingSSHClientCreator struct {
+	keys     []string
+	commands []string
+	reject   func(keyPath string) bool
+}
+
+func (c *recordingSSHClientCreator) CreateSSHClient(d drivers.Driver) (ssh.Client, error) {
+	c.keys = append(c.keys, d.GetSSHKeyPath())
+	if c.reject != nil && c.reject(d.GetSSHKeyPath()) {
+		return nil, errors.New("permission denied")
+	}
+	return &recordingSSHClient{&sshtest.FakeClient{}, &c.commands}, nil
+}
+
+// withSSHKeyTest runs f with a machine using a new SSH key in a temporary
+// directory, connected to with the creator.
+func withSSHKeyTest(t *testing.T, creator *recordingSSHClientCreator, detected *provision.Detected, f func(host *Host, keyPath string)) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	keyPath := filepath.Join(tmpDir, "id_rsa")
+	assert.NoError(t, ssh.GenerateSSHKey(keyPath))
+
+	defer SetSSHClientCreator(&StandardSSHClientCreator{})
+	SetSSHClientCreator(creator)
+
+	defer provision.SetDetector(&provision.StandardDetector{})
+	provision.SetDetector(&provision.FakeDetector{
+		Provisioner: &provision.FakeProvisioner{},
+	})
+
+	f(&Host{
+		Name:                "test",
+		HostOptions:         &Options{},
+		DetectedProvisioner: detected,
+		Driver: &sshKeyDriver{
+			Driver:  &fakedriver.Driver{MockState: state.Running},
+			keyPath: keyPath,
+		},
+	}, keyPath)
+}
+
+func TestRegenerateSSHKey(t *testing.T) {
+	creator := &recordingSSHClientCreator{}
+	withSSHKeyTest(t, creator, nil, func(host *Host, keyPath string) {
+		oldPublicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+
+		assert.NoError(t, host.RegenerateSSHKey())
+
+		newPublicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+		assert.NotEqual(t, oldPublicKey, newPublicKey)
+		publicKey, err := ssh.PublicKey(keyPath)
+		assert.NoError(t, err)
+		assert.Equal(t, newPublicKey, publicKey)
+
+		_, err = os.Stat(keyPath + ".new")
+		assert.True(t, os.IsNotExist(err))
+
+		assert.Equal(t, 1, host.Driver.(*sshKeyDriver).Driver.(*fakedriver.Driver).Called("Restart"))
+		assert.Equal(t, []string{keyPath, keyPath + ".new", keyPath + ".new", keyPath}, creator.keys)
+		assert.Equal(t, []string{
+			authorizeSSHKeyCommand(newPublicKey),
+			"exit 0",
+			"exit 0",
+			revokeSSHKeyCommand(oldPublicKey),
+		}, creator.commands)
+	})
+}
+
+func TestRegenerateSSHKeyPersistsOnBoot2Docker(t *testing.T) {
+	creator := &recordingSSHClientCreator{}
+	detected := &provision.Detected{
+		Name:      "boot2docker",
+		OsRelease: &provision.OsRelease{ID: "boot2docker"},
+	}
+	withSSHKeyTest(t, creator, detected, func(host *Host, keyPath string) {
+		oldPublicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+
+		assert.NoError(t, host.RegenerateSSHKey())
+
+		newPublicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+		assert.Equal(t, []string{
+			authorizeSSHKeyCommand(newPublicKey),
+			persistBoot2DockerSSHKeysCommand,
+			"exit 0",
+			"exit 0",
+			revokeSSHKeyCommand(oldPublicKey),
+			persistBoot2DockerSSHKeysCommand,
+		}, creator.commands)
+	})
+}
+
+func TestRegenerateSSHKeyKeepsKeyLostOnRestart(t *testing.T) {
+	creator := &recordingSSHClientCreator{}
+	withSSHKeyTest(t, creator, nil, func(host *Host, keyPath string) {
+		driver := host.Driver.(*sshKeyDriver).Driver.(*fakedriver.Driver)
+		creator.reject = func(path string) bool {
+			return path != keyPath && driver.Called("Restart") > 0
+		}
+		oldPublicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+
+		defer func(interval time.Duration) { sshKeyPollInterval = interval }(sshKeyPollInterval)
+		sshKeyPollInterval = time.Millisecond
+
+		err := host.RegenerateSSHKey()
+
+		assert.Error(t, err)
+		publicKey, _ := ioutil.ReadFile(keyPath + ".pub")
+		assert.Equal(t, oldPublicKey, publicKey)
+		_, err = os.Stat(keyPath + ".new")
+		assert.True(t, os.IsNotExist(err))
+		assert.NotContains(t, creator.commands, revokeSSHKeyCommand(oldPublicKey))
+	})
+}
+
+func TestRevokeSSHKeyCommand(t *testing.T) {
+	assert.Equal(t,
+		"for f in ~/.ssh/authorized_keys ~/.ssh/authorized_keys2; do [ -f $f ] || continue; grep -vF 'AAAAB3NzaC1yc2E' $f > $f.new; cat $f.new > $f && rm $f.new; done",
+		revokeSSHKeyCommand([]byte("ssh-rsa AAAAB3NzaC1yc2E\n")))
+}
@@ -0,0 +1,134 @@
+package host
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// statsCommand prints the number of CPUs, two samples of the CPU times a
+// second apart, the memory figures of the kernel and the usage of the disk
+// of the engine, falling back to the root disk when the engine has not
+// created its directory yet.
+const statsCommand = `grep -c ^processor /proc/cpuinfo; ` +
+	`head -n1 /proc/stat; sleep 1; head -n1 /proc/stat; ` +
+	`grep -E '^(MemTotal|MemFree|MemAvailable):' /proc/meminfo; ` +
+	`{ df -Pk /var/lib/docker 2>/dev/null || df -Pk /; } | tail -n1`
+
+// Stats returns the resource usage of the machine, as reported by the
+// provider when the driver supports it, or as probed over SSH otherwise.
+func (h *Host) Stats() (*drivers.Stats, error) {
+	stats, err := drivers.GetStats(h.Driver)
+	if err != drivers.ErrNotImplemented {
+		return stats, err
+	}
+
+	log.Debugf("The %s driver does not report stats, probing %q over SSH", h.DriverName, h.Name)
+
+	output, err := h.RunSSHCommand(statsCommand)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the stats of %q: %s", h.Name, err)
+	}
+
+	return parseStats(output)
+}
+
+func parseStats(output string) (*drivers.Stats, error) {
+	stats := &drivers.Stats{}
+
+	var (
+		cpuSamples [][]uint64
+		memory     = map[string]uint64{}
+		diskFound  bool
+	)
+
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch {
+		case i == 0:
+			cpus, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid CPU count %q", line)
+			}
+			stats.CPUs = cpus
+		case fields[0] == "cpu":
+			times, err := parseUints(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid CPU times %q", line)
+			}
+			cpuSamples = append(cpuSamples, times)
+		case strings.HasPrefix(fields[0], "Mem") && len(fields) >= 2:
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid memory figure %q", line)
+			}
+			memory[strings.TrimSuffix(fields[0], ":")] = kb * 1024
+		case len(fields) >= 6:
+			sizes, err := parseUints(fields[1:3])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid disk usage %q", line)
+			}
+			stats.DiskTotal = sizes[0] * 1024
+			stats.DiskUsed = sizes[1] * 1024
+			diskFound = true
+		}
+	}
+
+	if len(cpuSamples) != 2 || memory["MemTotal"] == 0 || !diskFound {
+		return nil, fmt.Errorf("Unexpected stats output:\n%s", output)
+	}
+
+	stats.CPUPercent = cpuPercent(cpuSamples[0], cpuSamples[1])
+
+	available, ok := memory["MemAvailable"]
+	if !ok {
+		available = memory["MemFree"]
+	}
+	stats.MemoryTotal = memory["MemTotal"]
+	if available < stats.MemoryTotal {
+		stats.MemoryUsed = stats.MemoryTotal - available
+	}
+
+	return stats, nil
+}
+
+// cpuPercent returns the share of the CPU time spent busy between two
+// samples of the cpu line of /proc/stat, the fourth and fifth times of which
+// are the idle and iowait times.
+func cpuPercent(before, after []uint64) float64 {
+	var total, idle uint64
+	for i := range after {
+		if i >= len(before) || after[i] < before[i] {
+			continue
+		}
+		delta := after[i] - before[i]
+		total += delta
+		if i == 3 || i == 4 {
+			idle += delta
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(total-idle) / float64(total)
+}
+
+func parseUints(fields []string) ([]uint64, error) {
+	values := make([]uint64, len(fields))
+	for i, field := range fields {
+		value, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
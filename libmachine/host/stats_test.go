@@ -0,0 +1,74 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+const statsOutput = `2
+cpu  100 0 100 700 100 0 0 0 0 0
+cpu  130 0 120 740 110 0 0 0 0 0
+MemTotal:        2048000 kB
+MemFree:          100000 kB
+MemAvailable:     512000 kB
+/dev/sda1         10000000   7500000   2500000  75% /mnt/sda1
+`
+
+func TestParseStats(t *testing.T) {
+	stats, err := parseStats(statsOutput)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.CPUs)
+	assert.Equal(t, 50.0, stats.CPUPercent)
+	assert.Equal(t, uint64(2048000*1024), stats.MemoryTotal)
+	assert.Equal(t, uint64(1536000*1024), stats.MemoryUsed)
+	assert.Equal(t, 75.0, stats.MemoryPercent())
+	assert.Equal(t, uint64(10000000*1024), stats.DiskTotal)
+	assert.Equal(t, 75.0, stats.DiskPercent())
+}
+
+func TestParseStatsWithoutMemAvailable(t *testing.T) {
+	stats, err := parseStats(`1
+cpu  0 0 0 100 0
+cpu  0 0 0 200 0
+MemTotal:        1000 kB
+MemFree:          250 kB
+/dev/vda1 100 10 90 10% /
+`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, stats.CPUPercent)
+	assert.Equal(t, uint64(750*1024), stats.MemoryUsed)
+}
+
+func TestParseStatsIncomplete(t *testing.T) {
+	_, err := parseStats("2\ncpu  100 0 100 700 100\n")
+
+	assert.Error(t, err)
+}
+
+type statsDriver struct {
+	*fakedriver.Driver
+	stats *drivers.Stats
+}
+
+func (d *statsDriver) GetStats() (*drivers.Stats, error) {
+	return d.stats, nil
+}
+
+func TestStatsFromDriver(t *testing.T) {
+	expected := &drivers.Stats{CPUs: 4, DiskTotal: 100, DiskUsed: 95}
+	host := &Host{
+		Name:   "test",
+		Driver: &statsDriver{Driver: &fakedriver.Driver{}, stats: expected},
+	}
+
+	stats, err := host.Stats()
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, stats)
+	assert.Equal(t, 95.0, stats.DiskPercent())
+}
@@ -0,0 +1,430 @@
+package host
+
+import (
+	"archive/tar"
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+// SyncMethod is how Sync copies the files to the machine.
+type SyncMethod string
+
+const (
+	// SyncAuto uses rsync when it is installed locally and on the machine,
+	// and the built-in implementation otherwise.
+	SyncAuto SyncMethod = ""
+
+	// SyncRsync runs rsync over SSH.
+	SyncRsync SyncMethod = "rsync"
+
+	// SyncBuiltin compares the checksums of the local and remote files and
+	// copies the files which differ in a tar stream, which only requires
+	// find, md5sum and tar on the machine.
+	SyncBuiltin SyncMethod = "builtin"
+
+	defaultSyncInterval = time.Second
+)
+
+// SyncOptions are the options of Sync.
+type SyncOptions struct {
+	Method SyncMethod
+
+	// Exclude lists the patterns of the files not to copy, matched against
+	// the base name and the slash separated path relative to the synced
+	// directory. Excluding a directory excludes its content.
+	Exclude []string
+
+	// Delete removes the remote files which do not exist locally.
+	Delete bool
+
+	// Watch keeps syncing the files as they change, until Stop is closed.
+	// The local directory is checked every Interval, every second by
+	// default.
+	Watch    bool
+	Interval time.Duration
+	Stop     <-chan struct{}
+}
+
+// Sync copies the files of localDir which differ on the machine to
+// remoteDir, created if needed.
+func (h *Host) Sync(localDir, remoteDir string, opts SyncOptions) error {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", localDir)
+	}
+
+	method, err := h.syncMethod(opts.Method)
+	if err != nil {
+		return err
+	}
+
+	sync := func() error {
+		if method == SyncRsync {
+			return h.syncRsync(localDir, remoteDir, opts)
+		}
+		return h.syncBuiltin(localDir, remoteDir, opts)
+	}
+
+	log.Infof("Syncing %s to %s:%s with %s...", localDir, h.Name, remoteDir, method)
+	if err := sync(); err != nil {
+		return err
+	}
+
+	if !opts.Watch {
+		return nil
+	}
+
+	return watchDir(localDir, opts, func() {
+		log.Infof("Syncing the changes of %s...", localDir)
+		if err := sync(); err != nil {
+			log.Warnf("Error syncing %s to %s: %s", localDir, h.Name, err)
+		}
+	})
+}
+
+func (h *Host) syncMethod(method SyncMethod) (SyncMethod, error) {
+	if method == SyncBuiltin {
+		return method, nil
+	}
+
+	_, lookErr := exec.LookPath("rsync")
+	_, remoteErr := h.RunSSHCommand("type rsync")
+
+	switch {
+	case method == SyncAuto && (lookErr != nil || remoteErr != nil):
+		return SyncBuiltin, nil
+	case method == SyncAuto || method == SyncRsync:
+		if lookErr != nil {
+			return "", errors.New("You must have a copy of the rsync binary locally to sync with rsync")
+		}
+		if remoteErr != nil {
+			return "", fmt.Errorf("rsync is not installed on %q", h.Name)
+		}
+		return SyncRsync, nil
+	}
+
+	return "", fmt.Errorf("Unknown sync method %q", method)
+}
+
+func (h *Host) syncRsync(localDir, remoteDir string, opts SyncOptions) error {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return err
+	}
+
+	external, ok := client.(*ssh.ExternalClient)
+	if !ok {
+		return errors.New("rsync requires the ssh binary, use the built-in sync with the native SSH client")
+	}
+
+	if _, err := h.RunSSHCommand(fmt.Sprintf("mkdir -p %s", shellQuote(remoteDir))); err != nil {
+		return err
+	}
+
+	hostname, err := h.Driver.GetSSHHostname()
+	if err != nil {
+		return err
+	}
+	destination := fmt.Sprintf("%s@%s", h.Driver.GetSSHUsername(), hostname)
+
+	args := rsyncArgs(external, destination, localDir, remoteDir, opts)
+	log.Debugf("Running rsync %s", strings.Join(args, " "))
+
+	if output, err := exec.Command("rsync", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("Error running rsync: %s\n%s", err, output)
+	}
+
+	return nil
+}
+
+// rsyncArgs returns the arguments of rsync, the remote shell of which is the
+// ssh command of the client without its destination, which rsync adds.
+func rsyncArgs(client *ssh.ExternalClient, destination, localDir, remoteDir string, opts SyncOptions) []string {
+	remoteShell := []string{shellQuote(client.BinaryPath)}
+	for _, arg := range client.BaseArgs {
+		if arg != destination {
+			remoteShell = append(remoteShell, shellQuote(arg))
+		}
+	}
+
+	args := []string{"-rlptz", "-e", strings.Join(remoteShell, " ")}
+	if opts.Delete {
+		args = append(args, "--delete")
+	}
+	for _, pattern := range opts.Exclude {
+		args = append(args, "--exclude="+pattern)
+	}
+
+	return append(args,
+		strings.TrimSuffix(localDir, string(filepath.Separator))+string(filepath.Separator),
+		fmt.Sprintf("%s:%s/", destination, strings.TrimSuffix(remoteDir, "/")))
+}
+
+func (h *Host) syncBuiltin(localDir, remoteDir string, opts SyncOptions) error {
+	local, err := localChecksums(localDir, opts.Exclude)
+	if err != nil {
+		return err
+	}
+
+	output, err := h.RunSSHCommand(fmt.Sprintf("mkdir -p %s && cd %s && find . -type f -exec md5sum {} +", shellQuote(remoteDir), shellQuote(remoteDir)))
+	if err != nil {
+		return err
+	}
+	remote := parseChecksums(output)
+
+	changed := []string{}
+	for name, sum := range local {
+		if remote[name] != sum {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+
+	deleted := []string{}
+	if opts.Delete {
+		for name := range remote {
+			if _, ok := local[name]; !ok && !excluded(name, opts.Exclude) {
+				deleted = append(deleted, shellQuote(name))
+			}
+		}
+		sort.Strings(deleted)
+	}
+
+	log.Debugf("Copying %d changed files, deleting %d files", len(changed), len(deleted))
+
+	if len(changed) > 0 {
+		if err := h.uploadTar(localDir, remoteDir, changed); err != nil {
+			return err
+		}
+	}
+
+	if len(deleted) > 0 {
+		if _, err := h.RunSSHCommand(fmt.Sprintf("cd %s && rm -f -- %s", shellQuote(remoteDir), strings.Join(deleted, " "))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uploadTar streams the files to the machine in a tar archive, extracted in
+// remoteDir.
+func (h *Host) uploadTar(localDir, remoteDir string, names []string) error {
+	client, err := h.CreateSSHClient()
+	if err != nil {
+		return err
+	}
+
+	uploader, ok := client.(ssh.InputClient)
+	if !ok {
+		return errors.New("The SSH client cannot copy files to the machine")
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTar(writer, localDir, names))
+	}()
+
+	if output, err := uploader.OutputWithInput(fmt.Sprintf("tar -xf - -C %s", shellQuote(remoteDir)), reader); err != nil {
+		reader.CloseWithError(err)
+		return fmt.Errorf("Error copying files to %q: %s (%s)", h.Name, err, output)
+	}
+
+	return nil
+}
+
+func writeTar(w io.Writer, localDir string, names []string) error {
+	archive := tar.NewWriter(w)
+
+	for _, name := range names {
+		filePath := filepath.Join(localDir, filepath.FromSlash(name))
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if err := copyFile(archive, filePath); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func copyFile(w io.Writer, filePath string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// localChecksums returns the MD5 checksums of the regular files of the
+// directory, by slash separated relative path.
+func localChecksums(dir string, exclude []string) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	err := walkDir(dir, exclude, func(name, filePath string, info os.FileInfo) error {
+		f, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		hash := md5.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return err
+		}
+		checksums[name] = hex.EncodeToString(hash.Sum(nil))
+		return nil
+	})
+
+	return checksums, err
+}
+
+// parseChecksums parses the output of md5sum run on the files found in the
+// remote directory.
+func parseChecksums(output string) map[string]string {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), "  ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		checksums[strings.TrimPrefix(fields[1], "./")] = fields[0]
+	}
+
+	return checksums
+}
+
+// walkDir calls fn for the regular files of the directory which are not
+// excluded.
+func walkDir(dir string, exclude []string, fn func(name, filePath string, info os.FileInfo) error) error {
+	return filepath.Walk(dir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil || rel == "." {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		if excluded(name, exclude) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return fn(name, filePath, info)
+	})
+}
+
+// excluded reports whether the relative path, or one of its parent
+// directories, matches one of the patterns.
+func excluded(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		for p := name; p != "." && p != "/"; p = path.Dir(p) {
+			if match, _ := path.Match(pattern, p); match {
+				return true
+			}
+			if match, _ := path.Match(pattern, path.Base(p)); match {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// watchDir calls onChange whenever the size or modification time of the
+// files of the directory change, or files are added or removed, until the
+// Stop channel of the options is closed.
+func watchDir(dir string, opts SyncOptions, onChange func()) error {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = defaultSyncInterval
+	}
+
+	previous, err := dirSnapshot(dir, opts.Exclude)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-opts.Stop:
+			return nil
+		case <-ticker.C:
+		}
+
+		current, err := dirSnapshot(dir, opts.Exclude)
+		if err != nil {
+			log.Warnf("Error watching %s: %s", dir, err)
+			continue
+		}
+
+		if current != previous {
+			previous = current
+			onChange()
+		}
+	}
+}
+
+// dirSnapshot sums up the names, sizes and modification times of the files
+// of the directory, for watchDir to notice changes.
+func dirSnapshot(dir string, exclude []string) (string, error) {
+	hash := md5.New()
+
+	err := walkDir(dir, exclude, func(name, filePath string, info os.FileInfo) error {
+		fmt.Fprintf(hash, "%s %d %d\n", name, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+
+	return hex.EncodeToString(hash.Sum(nil)), err
+}
+
+// shellQuote quotes a value for the shell of the machine.
+func shellQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
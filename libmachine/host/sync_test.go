@@ -0,0 +1,140 @@
+package host
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSyncTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "machine-sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"main.go":             "package main\n",
+		"pkg/util.go":         "package pkg\n",
+		".git/HEAD":           "ref: refs/heads/master\n",
+		"vendor/lib/lib.go":   "package lib\n",
+		"build/output.tmp":    "tmp",
+		"pkg/nested/data.txt": "data",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestExcluded(t *testing.T) {
+	patterns := []string{".git", "*.tmp", "vendor/lib"}
+
+	assert.True(t, excluded(".git/HEAD", patterns))
+	assert.True(t, excluded("build/output.tmp", patterns))
+	assert.True(t, excluded("vendor/lib/lib.go", patterns))
+	assert.False(t, excluded("vendor/other.go", patterns))
+	assert.False(t, excluded("main.go", patterns))
+}
+
+func TestLocalChecksums(t *testing.T) {
+	dir := newSyncTestDir(t)
+	defer os.RemoveAll(dir)
+
+	checksums, err := localChecksums(dir, []string{".git", "*.tmp"})
+
+	assert.NoError(t, err)
+	assert.Len(t, checksums, 4)
+	assert.Equal(t, "8d777f385d3dfec8815d20f7496026dc", checksums["pkg/nested/data.txt"])
+	assert.NotContains(t, checksums, ".git/HEAD")
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksums := parseChecksums("8d777f385d3dfec8815d20f7496026dc  ./pkg/nested/data.txt\nd41d8cd98f00b204e9800998ecf8427e  ./with space.txt\n")
+
+	assert.Equal(t, map[string]string{
+		"pkg/nested/data.txt": "8d777f385d3dfec8815d20f7496026dc",
+		"with space.txt":      "d41d8cd98f00b204e9800998ecf8427e",
+	}, checksums)
+}
+
+func TestWriteTar(t *testing.T) {
+	dir := newSyncTestDir(t)
+	defer os.RemoveAll(dir)
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeTar(&buf, dir, []string{"main.go", "pkg/nested/data.txt"}))
+
+	archive := tar.NewReader(&buf)
+	names := []string{}
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+
+	assert.Equal(t, []string{"main.go", "pkg/nested/data.txt"}, names)
+}
+
+func TestRsyncArgs(t *testing.T) {
+	client := &ssh.ExternalClient{
+		BinaryPath: "/usr/bin/ssh",
+		BaseArgs:   []string{"-o", "LogLevel=quiet", "docker@1.2.3.4", "-i", "/store/id rsa", "-p", "22"},
+	}
+
+	args := rsyncArgs(client, "docker@1.2.3.4", "/src", "/home/docker/src", SyncOptions{
+		Delete:  true,
+		Exclude: []string{".git"},
+	})
+
+	assert.Equal(t, []string{
+		"-rlptz",
+		"-e", "'/usr/bin/ssh' '-o' 'LogLevel=quiet' '-i' '/store/id rsa' '-p' '22'",
+		"--delete",
+		"--exclude=.git",
+		"/src" + string(filepath.Separator),
+		"docker@1.2.3.4:/home/docker/src/",
+	}, args)
+}
+
+func TestWatchDir(t *testing.T) {
+	dir := newSyncTestDir(t)
+	defer os.RemoveAll(dir)
+
+	stop := make(chan struct{})
+	changes := make(chan struct{}, 1)
+	done := make(chan error)
+
+	go func() {
+		done <- watchDir(dir, SyncOptions{Interval: 10 * time.Millisecond, Stop: stop}, func() {
+			changes <- struct{}{}
+		})
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0644))
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the new file to be noticed")
+	}
+
+	close(stop)
+	assert.NoError(t, <-done)
+}
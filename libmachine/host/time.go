@@ -0,0 +1,128 @@
+package host
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// ClockSkewTolerance is the clock skew RepairClockSkew leaves to the time
+// synchronization of the machine, the skew being measured to the second.
+const ClockSkewTolerance = 5 * time.Second
+
+// syncTimeCommand sets the clock of the machine in a format both GNU and
+// busybox date take, saves it to the hardware clock, and has chrony step to
+// the time of its servers when it runs.
+const syncTimeCommand = `sudo date -u -s '%s' >/dev/null && (sudo hwclock -w -u >/dev/null 2>&1; sudo chronyc makestep >/dev/null 2>&1; true)`
+
+// sleepDriftDrivers are the drivers of the local VMs the clock of which
+// stops while the host sleeps, and lags behind once it resumes.
+var sleepDriftDrivers = map[string]bool{
+	"virtualbox":        true,
+	"vmwarefusion":      true,
+	"vmwareworkstation": true,
+}
+
+// clockProbe is swapped in tests.
+var clockProbe = probeClock
+
+// SyncTime sets the clock of the machine to the one of the local host,
+// typically after the host resumed from sleep, leaving the clock of local
+// VMs behind until their time synchronization catches up.
+func (h *Host) SyncTime() error {
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+
+	if output, err := h.RunSSHCommand(fmt.Sprintf(syncTimeCommand, now)); err != nil {
+		return fmt.Errorf("Error setting the clock of %s: %s\n%s", h.Name, err, output)
+	}
+
+	return nil
+}
+
+// ClockSkew returns how far the clock of the machine is ahead of the one of
+// the local host, negative when it is behind. The machine must be running.
+func (h *Host) ClockSkew() (time.Duration, error) {
+	before := time.Now()
+	machineTime, err := clockProbe(h)
+	if err != nil {
+		return 0, err
+	}
+	after := time.Now()
+
+	localTime := before.Add(after.Sub(before) / 2).Truncate(time.Second)
+	return machineTime.Sub(localTime), nil
+}
+
+// RepairClockSkew sets the clock of the machine with SyncTime when it is
+// more than ClockSkewTolerance off, and returns the skew it found.
+func (h *Host) RepairClockSkew() (time.Duration, error) {
+	skew, err := h.ClockSkew()
+	if err != nil {
+		return 0, err
+	}
+	if skew <= ClockSkewTolerance && skew >= -ClockSkewTolerance {
+		return skew, nil
+	}
+
+	log.Infof("The clock of %q is %s off, setting it...", h.Name, skew)
+	return skew, h.SyncTime()
+}
+
+// DriftsOnSleep tells whether the machine is a local VM the clock of which
+// lags behind after the host sleeps.
+func (h *Host) DriftsOnSleep() bool {
+	return sleepDriftDrivers[h.DriverName]
+}
+
+// repairClockSkewOnResume repairs the clock of the local VMs which drift,
+// the errors being only logged as the machine works otherwise.
+func (h *Host) repairClockSkewOnResume() {
+	if !h.DriftsOnSleep() {
+		return
+	}
+
+	if _, err := h.RepairClockSkew(); err != nil {
+		log.Warnf("Error repairing the clock of %q: %s", h.Name, err)
+	}
+}
+
+// RepairClockSkewIfRunning repairs the clock of the machine if it is a
+// running local VM which drifts, telling whether the clock was set.
+func (h *Host) RepairClockSkewIfRunning() (bool, error) {
+	if !h.DriftsOnSleep() {
+		return false, nil
+	}
+
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return false, err
+	}
+	if s != state.Running {
+		return false, nil
+	}
+
+	skew, err := h.RepairClockSkew()
+	if err != nil {
+		return false, err
+	}
+
+	return skew > ClockSkewTolerance || skew < -ClockSkewTolerance, nil
+}
+
+func probeClock(h *Host) (time.Time, error) {
+	output, err := h.RunSSHCommand("date -u +%s")
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Error reading the clock of %s: %s", h.Name, err)
+	}
+
+	seconds, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Unexpected time %q on %s", strings.TrimSpace(output), h.Name)
+	}
+
+	return time.Unix(seconds, 0), nil
+}
@@ -0,0 +1,63 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func withClockProbe(probe func(h *Host) (time.Time, error), f func()) {
+	defer func(saved func(h *Host) (time.Time, error)) {
+		clockProbe = saved
+	}(clockProbe)
+
+	clockProbe = probe
+
+	f()
+}
+
+func TestClockSkew(t *testing.T) {
+	host := &Host{Name: "test", DriverName: "virtualbox", Driver: &fakedriver.Driver{MockState: state.Running}}
+
+	withClockProbe(func(h *Host) (time.Time, error) {
+		return time.Now().Add(-time.Hour), nil
+	}, func() {
+		skew, err := host.ClockSkew()
+		assert.NoError(t, err)
+		assert.InDelta(t, float64(-time.Hour), float64(skew), float64(2*time.Second))
+
+		_, err = host.RepairClockSkew()
+		assert.Error(t, err, "the clock is set over SSH")
+	})
+}
+
+func TestRepairClockSkewWithinTolerance(t *testing.T) {
+	host := &Host{Name: "test", DriverName: "virtualbox", Driver: &fakedriver.Driver{MockState: state.Running}}
+
+	withClockProbe(func(h *Host) (time.Time, error) {
+		return time.Now().Add(2 * time.Second), nil
+	}, func() {
+		repaired, err := host.RepairClockSkewIfRunning()
+		assert.NoError(t, err)
+		assert.False(t, repaired)
+	})
+}
+
+func TestRepairClockSkewIfRunningSkipsOtherMachines(t *testing.T) {
+	withClockProbe(func(h *Host) (time.Time, error) {
+		t.Fatal("the clock of the machine should not be read")
+		return time.Time{}, nil
+	}, func() {
+		for _, host := range []*Host{
+			{Name: "cloud", DriverName: "amazonec2", Driver: &fakedriver.Driver{MockState: state.Running}},
+			{Name: "stopped", DriverName: "virtualbox", Driver: &fakedriver.Driver{MockState: state.Stopped}},
+		} {
+			repaired, err := host.RepairClockSkewIfRunning()
+			assert.NoError(t, err)
+			assert.False(t, repaired)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package host
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// SetTTL has the machine expire ttl from now, or never with a zero ttl.
+func (h *Host) SetTTL(ttl time.Duration) {
+	h.HostOptions.TTL = ttl
+	h.HostOptions.ExpiresAt = time.Time{}
+	if ttl > 0 {
+		h.HostOptions.ExpiresAt = time.Now().Add(ttl)
+	}
+}
+
+// IsExpired tells whether the machine outlived its TTL.
+func (h *Host) IsExpired() bool {
+	if h.HostOptions == nil || h.HostOptions.ExpiresAt.IsZero() {
+		return false
+	}
+
+	return time.Now().After(h.HostOptions.ExpiresAt)
+}
+
+// RemoveIfExpired removes the instance of the machine with removeInstance
+// when it is expired, see IsExpired, sending an EventExpired, so that
+// short-lived test machines do not linger and cost. removeInstance is e.g.
+// libmachine.RemoveInstance, which refuses the protected machines. It tells
+// whether the instance was removed, the machine is then to be removed from
+// the store.
+func (h *Host) RemoveIfExpired(removeInstance func() error) (bool, error) {
+	if !h.IsExpired() {
+		return false, nil
+	}
+
+	log.Infof("Machine %q expired on %s", h.Name, h.HostOptions.ExpiresAt.Format(time.RFC3339))
+	if err := removeInstance(); err != nil {
+		return false, err
+	}
+
+	h.emit(EventExpired, fmt.Sprintf("The machine was removed after its TTL of %s", h.HostOptions.TTL))
+
+	return true, nil
+}
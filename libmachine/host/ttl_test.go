@@ -0,0 +1,75 @@
+package host
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTTL(t *testing.T) {
+	host := &Host{Name: "test", HostOptions: &Options{}}
+
+	host.SetTTL(time.Hour)
+
+	assert.Equal(t, time.Hour, host.HostOptions.TTL)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), host.HostOptions.ExpiresAt, time.Minute)
+	assert.False(t, host.IsExpired())
+
+	host.SetTTL(0)
+
+	assert.True(t, host.HostOptions.ExpiresAt.IsZero())
+	assert.False(t, host.IsExpired())
+}
+
+func TestRemoveIfExpired(t *testing.T) {
+	events := recordEvents()
+	driver := &fakedriver.Driver{MockState: state.Running}
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{TTL: time.Hour, ExpiresAt: time.Now().Add(-time.Minute)},
+		Driver:      driver,
+	}
+
+	removed, err := host.RemoveIfExpired(driver.Remove)
+
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, 1, driver.Called("Remove"))
+	assert.Contains(t, *events, EventExpired)
+}
+
+func TestRemoveIfExpiredNotExpired(t *testing.T) {
+	driver := &fakedriver.Driver{MockState: state.Running}
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{TTL: time.Hour, ExpiresAt: time.Now().Add(time.Minute)},
+		Driver:      driver,
+	}
+
+	removed, err := host.RemoveIfExpired(driver.Remove)
+
+	assert.NoError(t, err)
+	assert.False(t, removed)
+	assert.Equal(t, 0, driver.Called("Remove"))
+}
+
+func TestRemoveIfExpiredRemovalFails(t *testing.T) {
+	events := recordEvents()
+	host := &Host{
+		Name:        "test",
+		HostOptions: &Options{ExpiresAt: time.Now().Add(-time.Minute)},
+		Driver:      &fakedriver.Driver{MockState: state.Running},
+	}
+
+	removed, err := host.RemoveIfExpired(func() error {
+		return errors.New("quota exceeded")
+	})
+
+	assert.EqualError(t, err, "quota exceeded")
+	assert.False(t, removed)
+	assert.NotContains(t, *events, EventExpired)
+}
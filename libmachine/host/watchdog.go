@@ -0,0 +1,120 @@
+package host
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// WatchdogAction is what the watchdog does with a machine whose engine
+// stopped answering.
+type WatchdogAction string
+
+const (
+	// WatchdogRestartEngine restarts the Docker daemon of the machine.
+	WatchdogRestartEngine WatchdogAction = "restart-engine"
+	// WatchdogRestartMachine restarts the whole machine.
+	WatchdogRestartMachine WatchdogAction = "restart-machine"
+	// WatchdogNotify only sends an EventUnhealthy.
+	WatchdogNotify WatchdogAction = "notify"
+)
+
+// DefaultWatchdogGracePeriod is how long the engine may not answer before
+// the watchdog acts, when the policy does not say.
+const DefaultWatchdogGracePeriod = 30 * time.Second
+
+// WatchdogPolicy tells the watchdog what to do with a machine whose engine
+// stopped answering, see CheckHealth.
+type WatchdogPolicy struct {
+	Action WatchdogAction
+
+	// GracePeriod is how long the engine may not answer before Action is
+	// taken, so that a busy engine is not restarted, and how long it has
+	// to answer again after the restart.
+	GracePeriod time.Duration `json:",omitempty"`
+}
+
+// watchdogRestarts are the restarts of each action, swapped in tests.
+var watchdogRestarts = map[WatchdogAction]func(h *Host) error{
+	WatchdogRestartEngine:  restartEngine,
+	WatchdogRestartMachine: (*Host).Restart,
+}
+
+// ParseWatchdogAction returns the action of the given name.
+func ParseWatchdogAction(name string) (WatchdogAction, error) {
+	switch action := WatchdogAction(name); action {
+	case WatchdogRestartEngine, WatchdogRestartMachine, WatchdogNotify:
+		return action, nil
+	}
+
+	return "", fmt.Errorf("Unknown watchdog action %q, must be one of %s, %s or %s", name, WatchdogRestartEngine, WatchdogRestartMachine, WatchdogNotify)
+}
+
+func (p *WatchdogPolicy) gracePeriod() time.Duration {
+	if p.GracePeriod > 0 {
+		return p.GracePeriod
+	}
+	return DefaultWatchdogGracePeriod
+}
+
+// CheckHealth checks that the engine of the machine answers, see
+// EngineReady, and applies the watchdog policy of the machine when it does
+// not for the grace period: an EventUnhealthy is sent, then the engine or
+// the machine is restarted, sending an EventWatchdogRestarted once the
+// engine answers again or an EventWatchdogFailed. Machines without a policy,
+// or which are not running, are not checked. It tells whether the engine
+// was found unhealthy.
+func (h *Host) CheckHealth() (bool, error) {
+	if h.HostOptions == nil || h.HostOptions.Watchdog == nil {
+		return false, nil
+	}
+	policy := h.HostOptions.Watchdog
+
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return false, err
+	}
+	if s != state.Running {
+		return false, nil
+	}
+
+	err = h.WaitUntil(EngineReady, policy.gracePeriod())
+	if err == nil {
+		return false, nil
+	}
+
+	log.Warnf("The engine of %q is not answering: %s", h.Name, err)
+	h.emit(EventUnhealthy, fmt.Sprintf("The engine did not answer for %s: %s", policy.gracePeriod(), err))
+
+	restart, ok := watchdogRestarts[policy.Action]
+	if !ok {
+		return true, nil
+	}
+
+	log.Infof("Watchdog of %q: %s...", h.Name, policy.Action)
+	if err := restart(h); err != nil {
+		h.emit(EventWatchdogFailed, fmt.Sprintf("The %s failed: %s", policy.Action, err))
+		return true, err
+	}
+	if err := h.WaitUntil(EngineReady, policy.gracePeriod()); err != nil {
+		h.emit(EventWatchdogFailed, fmt.Sprintf("The engine still does not answer after the %s: %s", policy.Action, err))
+		return true, err
+	}
+
+	h.emit(EventWatchdogRestarted, fmt.Sprintf("The engine answers again after the %s", policy.Action))
+
+	return true, nil
+}
+
+// restartEngine restarts the Docker daemon over SSH.
+func restartEngine(h *Host) error {
+	provisioner, err := h.Provisioner()
+	if err != nil {
+		return err
+	}
+
+	return provisioner.Service("docker", serviceaction.Restart)
+}
@@ -0,0 +1,141 @@
+package host
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func withWatchdogRestarts(restarts map[WatchdogAction]func(h *Host) error, f func()) {
+	defer func(saved map[WatchdogAction]func(h *Host) error) {
+		watchdogRestarts = saved
+	}(watchdogRestarts)
+
+	watchdogRestarts = restarts
+
+	f()
+}
+
+// engineProbes have the engine answer once healthy is set.
+func engineProbes(healthy *bool) map[ReadyLevel]func(h *Host) error {
+	ok := func(h *Host) error { return nil }
+	return map[ReadyLevel]func(h *Host) error{
+		InstanceRunning: ok,
+		SSHReady:        ok,
+		EngineReady: func(h *Host) error {
+			if !*healthy {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+}
+
+func newWatchdogTestHost(action WatchdogAction) *Host {
+	return &Host{
+		Name: "test",
+		HostOptions: &Options{
+			Watchdog: &WatchdogPolicy{Action: action, GracePeriod: 10 * time.Millisecond},
+		},
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+		},
+	}
+}
+
+func TestParseWatchdogAction(t *testing.T) {
+	action, err := ParseWatchdogAction("restart-engine")
+	assert.NoError(t, err)
+	assert.Equal(t, WatchdogRestartEngine, action)
+
+	_, err = ParseWatchdogAction("reboot")
+	assert.Error(t, err)
+}
+
+func TestCheckHealthHealthy(t *testing.T) {
+	healthy := true
+	host := newWatchdogTestHost(WatchdogRestartEngine)
+
+	var unhealthy bool
+	var err error
+	withReadinessProbes(engineProbes(&healthy), func() {
+		unhealthy, err = host.CheckHealth()
+	})
+
+	assert.NoError(t, err)
+	assert.False(t, unhealthy)
+}
+
+func TestCheckHealthRestarts(t *testing.T) {
+	events := recordEvents()
+	healthy := false
+	host := newWatchdogTestHost(WatchdogRestartEngine)
+
+	restarted := 0
+	var unhealthy bool
+	var err error
+	withWatchdogRestarts(map[WatchdogAction]func(h *Host) error{
+		WatchdogRestartEngine: func(h *Host) error {
+			restarted++
+			healthy = true
+			return nil
+		},
+	}, func() {
+		withReadinessProbes(engineProbes(&healthy), func() {
+			unhealthy, err = host.CheckHealth()
+		})
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, unhealthy)
+	assert.Equal(t, 1, restarted)
+	assert.Contains(t, *events, EventUnhealthy)
+	assert.Contains(t, *events, EventWatchdogRestarted)
+}
+
+func TestCheckHealthRestartDoesNotHelp(t *testing.T) {
+	events := recordEvents()
+	healthy := false
+	host := newWatchdogTestHost(WatchdogRestartMachine)
+
+	var err error
+	withWatchdogRestarts(map[WatchdogAction]func(h *Host) error{
+		WatchdogRestartMachine: func(h *Host) error { return nil },
+	}, func() {
+		withReadinessProbes(engineProbes(&healthy), func() {
+			_, err = host.CheckHealth()
+		})
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, *events, EventWatchdogFailed)
+}
+
+func TestCheckHealthNotify(t *testing.T) {
+	healthy := false
+	host := newWatchdogTestHost(WatchdogNotify)
+
+	var unhealthy bool
+	var err error
+	withReadinessProbes(engineProbes(&healthy), func() {
+		unhealthy, err = host.CheckHealth()
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, unhealthy)
+	assert.NotContains(t, host.Driver.(*fakedriver.Driver).Calls, "Restart")
+}
+
+func TestCheckHealthStoppedMachine(t *testing.T) {
+	host := newWatchdogTestHost(WatchdogRestartMachine)
+	host.Driver.(*fakedriver.Driver).MockState = state.Stopped
+
+	unhealthy, err := host.CheckHealth()
+
+	assert.NoError(t, err)
+	assert.False(t, unhealthy)
+}
@@ -0,0 +1,68 @@
+package libmachine
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// StopIdleMachines stops the machines of the store which have not been used
+// for longer than their IdleTimeout, and returns the names of the stopped
+// machines. Errors with a machine are logged and do not prevent stopping the
+// other machines.
+func StopIdleMachines(api API) ([]string, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := []string{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		if h.HostOptions == nil || h.HostOptions.IdleTimeout <= 0 {
+			continue
+		}
+
+		ok, err := h.StopIfIdle()
+		if err != nil {
+			log.Warnf("Error checking whether machine %q is idle: %s", name, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		RecordOperationDetails(api, name, persist.AuditStop, "idle", nil)
+
+		if err := api.Save(h); err != nil {
+			log.Warnf("Error saving machine %q: %s", name, err)
+		}
+		stopped = append(stopped, name)
+	}
+
+	return stopped, nil
+}
+
+// WatchIdleMachines runs StopIdleMachines every interval until done is
+// closed.
+func WatchIdleMachines(api API, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := StopIdleMachines(api); err != nil {
+			log.Warnf("Error stopping idle machines: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
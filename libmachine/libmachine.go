@@ -1,8 +1,10 @@
 package libmachine
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"io"
 
@@ -18,6 +20,8 @@ import (
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnerror"
 	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/metrics"
+	"github.com/docker/machine/libmachine/namegen"
 	"github.com/docker/machine/libmachine/persist"
 	"github.com/docker/machine/libmachine/provision"
 	"github.com/docker/machine/libmachine/ssh"
@@ -39,6 +43,13 @@ type Client struct {
 	IsDebug        bool
 	SSHClientType  ssh.ClientType
 	GithubAPIToken string
+	KnownHostsFile string
+	CallTimeouts   drivers.CallTimeouts
+
+	// ManageSSHConfig keeps the entries of the machines in the ssh_config
+	// file at SSHConfigPath up to date as they are created and removed.
+	ManageSSHConfig bool
+
 	*persist.Filestore
 	clientDriverFactory rpcdriver.RPCClientDriverFactory
 }
@@ -48,6 +59,7 @@ func NewClient(storePath, certsDir string) *Client {
 		certsDir:            certsDir,
 		IsDebug:             false,
 		SSHClientType:       ssh.External,
+		KnownHostsFile:      filepath.Join(storePath, "known_hosts"),
 		Filestore:           persist.NewFilestore(storePath, certsDir, certsDir),
 		clientDriverFactory: rpcdriver.NewRPCClientDriverFactory(),
 	}
@@ -58,10 +70,15 @@ func (api *Client) NewHost(driverName string, rawDriver []byte) (*host.Host, err
 	if err != nil {
 		return nil, err
 	}
+	driver.SetCallTimeouts(drivers.CallTimeoutsFor(driverName, api.CallTimeouts))
+
+	name := driver.GetMachineName()
+	driver.SetKnownHosts(api.KnownHosts(name))
+	machineDir := filepath.Join(api.GetMachinesDir(), name)
 
 	return &host.Host{
 		ConfigVersion: version.ConfigVersion,
-		Name:          driver.GetMachineName(),
+		Name:          name,
 		Driver:        driver,
 		DriverName:    driver.DriverName(),
 		HostOptions: &host.Options{
@@ -71,23 +88,75 @@ func (api *Client) NewHost(driverName string, rawDriver []byte) (*host.Host, err
 				CaPrivateKeyPath: filepath.Join(api.certsDir, "ca-key.pem"),
 				ClientCertPath:   filepath.Join(api.certsDir, "cert.pem"),
 				ClientKeyPath:    filepath.Join(api.certsDir, "key.pem"),
-				ServerCertPath:   filepath.Join(api.GetMachinesDir(), "server.pem"),
-				ServerKeyPath:    filepath.Join(api.GetMachinesDir(), "server-key.pem"),
+				ServerCertPath:   filepath.Join(machineDir, "server.pem"),
+				ServerKeyPath:    filepath.Join(machineDir, "server-key.pem"),
+				StorePath:        machineDir,
 			},
 			EngineOptions: &engine.Options{
 				InstallURL:    drivers.DefaultEngineInstallURL,
 				StorageDriver: "aufs",
 				TLSVerify:     true,
 			},
-			SwarmOptions: &swarm.Options{
-				Host:     "tcp://0.0.0.0:3376",
-				Image:    "swarm:latest",
-				Strategy: "spread",
-			},
+			SwarmOptions: defaultSwarmOptions(),
 		},
 	}, nil
 }
 
+// NewGeneratedHost returns a new host of the driver, named by the generator
+// with a name no machine of the store has, e.g. for the ephemeral machines
+// of CI pools.
+func (api *Client) NewGeneratedHost(driverName string, generator namegen.Generator) (*host.Host, error) {
+	name, err := namegen.Unique(api, generator)
+	if err != nil {
+		return nil, err
+	}
+
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: name,
+		StorePath:   api.Path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return api.NewHost(driverName, rawDriver)
+}
+
+// KnownHosts returns where the host key of the machine is recorded: in the
+// known_hosts file of the client, under the name of the machine followed by
+// the ID of the store, for the stores sharing the file not to mix up their
+// machines of the same name.
+func (api *Client) KnownHosts(name string) ssh.KnownHosts {
+	return ssh.KnownHosts{
+		File:  api.KnownHostsFile,
+		Alias: name + "." + api.StoreID(),
+	}
+}
+
+// KnownHostsKeeper is implemented by the APIs recording the host keys of
+// their machines, e.g. Client.
+type KnownHostsKeeper interface {
+	KnownHosts(name string) ssh.KnownHosts
+}
+
+// KnownHostsOf returns where the API records the host key of the machine.
+// The host keys of the machines of the other APIs are not checked.
+func KnownHostsOf(api API, name string) ssh.KnownHosts {
+	if k, ok := api.(KnownHostsKeeper); ok {
+		return k.KnownHosts(name)
+	}
+
+	return ssh.KnownHosts{Alias: name}
+}
+
+func defaultSwarmOptions() *swarm.Options {
+	return &swarm.Options{
+		Host:     "tcp://0.0.0.0:3376",
+		Image:    "swarm:latest",
+		Strategy: "spread",
+	}
+}
+
 func (api *Client) Load(name string) (*host.Host, error) {
 	h, err := api.Filestore.Load(name)
 	if err != nil {
@@ -103,23 +172,252 @@ func (api *Client) Load(name string) (*host.Host, error) {
 		}
 		return nil, err
 	}
+	d.SetCallTimeouts(drivers.CallTimeoutsFor(h.DriverName, api.CallTimeouts))
+	d.SetKnownHosts(api.KnownHosts(h.Name))
 
 	if h.DriverName == "virtualbox" {
 		h.Driver = drivers.NewSerialDriver(d)
 	} else {
 		h.Driver = d
 	}
+	h.RedactSecretsInLogs()
 
 	return h, nil
 }
 
+// Remove removes the machine from the store, to its trash, and from the
+// managed ssh_config file.
+func (api *Client) Remove(name string) error {
+	if err := api.Filestore.Remove(name); err != nil {
+		return err
+	}
+
+	api.updateManagedSSHConfig(name, nil)
+	return nil
+}
+
+// Restore puts a removed machine back in the store, and in the managed
+// ssh_config file.
+func (api *Client) Restore(name string) (*host.Host, error) {
+	h, err := api.Filestore.Restore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	api.updateManagedSSHConfig(name, h)
+	return h, nil
+}
+
 // Create is the wrapper method which covers all of the boilerplate around
 // actually creating, provisioning, and persisting an instance in the store.
 func (api *Client) Create(h *host.Host) error {
+	err := api.create(h, h.Driver.Create)
+	RecordOperation(api, h.Name, persist.AuditCreate, err)
+	return err
+}
+
+// Clone creates a new machine named newName with the same driver
+// configuration and options as h. Drivers able to copy an existing machine
+// (see drivers.Cloner) do so, others create the clone from scratch. Either
+// way, the clone gets its own SSH keys and certificates.
+func (api *Client) Clone(h *host.Host, newName string) (*host.Host, error) {
+	if !host.ValidateHostName(newName) {
+		return nil, mcnerror.ErrInvalidHostname
+	}
+
+	exists, err := api.Exists(newName)
+	if err != nil {
+		return nil, fmt.Errorf("Error checking if host exists: %s", err)
+	}
+	if exists {
+		return nil, mcnerror.ErrHostAlreadyExists{
+			Name: newName,
+		}
+	}
+
+	sourceConfig, err := json.Marshal(h.Driver)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading driver configuration of %q: %s", h.Name, err)
+	}
+
+	rawDriver, err := h.CloneDriverConfig(newName)
+	if err != nil {
+		return nil, fmt.Errorf("Error copying driver configuration of %q: %s", h.Name, err)
+	}
+
+	clone, err := api.NewHost(h.DriverName, rawDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	clone.HostOptions, err = h.CloneOptions(filepath.Join(api.GetMachinesDir(), newName))
+	if err != nil {
+		return nil, fmt.Errorf("Error copying options of %q: %s", h.Name, err)
+	}
+
+	log.Infof("Cloning %q into %q...", h.Name, newName)
+
+	copied := false
+	createClone := func() error {
+		err := drivers.CreateClone(clone.Driver, sourceConfig)
+		if err == drivers.ErrNotImplemented {
+			log.Infof("Unable to clone %q, creating %q from scratch...", h.Name, newName)
+			return clone.Driver.Create()
+		}
+		copied = err == nil
+		return err
+	}
+
+	err = api.create(clone, createClone)
+	RecordOperationDetails(api, newName, persist.AuditCreate, "clone of "+h.Name, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := api.Save(clone); err != nil {
+		return nil, fmt.Errorf("Error saving host to store: %s", err)
+	}
+
+	// A copied disk authorizes the SSH key of the source
+	if copied {
+		if err := clone.RegenerateSSHKey(); err != nil {
+			log.Warnf("%q still uses the SSH key of %q: %s", newName, h.Name, err)
+			log.Warnf("Run \"docker-machine regenerate-ssh-key %s\" to give it its own key", newName)
+		}
+	}
+
+	return clone, nil
+}
+
+func (api *Client) create(h *host.Host, createInstance func() error) (err error) {
+	defer metrics.Observe("create", h.Name, h.DriverName, time.Now(), &err)
+
+	if api.ReadOnly {
+		return persist.ErrReadOnlyStore
+	}
+
+	h.RedactSecretsInLogs()
+
 	if err := cert.BootstrapCertificates(h.AuthOptions()); err != nil {
 		return fmt.Errorf("Error generating certificates: %s", err)
 	}
 
+	resuming := h.CreatePhase != ""
+	if !resuming {
+		h.CreatePhase = host.CreatePhasePending
+	}
+
+	// The TTL runs from the start of the creation, which a resumed creation
+	// does not reset
+	if h.HostOptions.TTL > 0 && h.HostOptions.ExpiresAt.IsZero() {
+		h.SetTTL(h.HostOptions.TTL)
+	}
+
+	if h.CreateReached(host.CreatePhaseInstanceCreated) {
+		log.Infof("Resuming the creation of %q after the %s phase...", h.Name, h.CreatePhase)
+	} else {
+		if resuming {
+			if err := checkInstanceMissing(h); err != nil {
+				return err
+			}
+		}
+		if err := api.prepareCreate(h); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Creating machine...")
+
+	if err := api.performCreate(h, createInstance); err != nil {
+		return fmt.Errorf("Error creating machine: %s", err)
+	}
+
+	if h.HostOptions.Autostart {
+		if err := h.SetAutostart(true, api.Path); err != nil {
+			log.Warnf("Error setting the machine to start on boot: %s", err)
+			h.HostOptions.Autostart = false
+		}
+
+		if err := api.Save(h); err != nil {
+			return fmt.Errorf("Error saving host to store after setting autostart: %s", err)
+		}
+	}
+
+	api.updateManagedSSHConfig(h.Name, h)
+
+	log.Debug("Reticulating splines...")
+
+	return nil
+}
+
+// prepareCreate checks the name of the machine with the driver, sets the
+// options of the driver, runs its pre-create checks and saves the machine
+// before its instance is created.
+func (api *Client) prepareCreate(h *host.Host) error {
+	if err := drivers.CheckMachineName(h.Driver); err != nil {
+		return err
+	}
+
+	if !h.HostOptions.NetworkOptions.IsEmpty() {
+		if err := drivers.SetNetworkOptions(h.Driver, *h.HostOptions.NetworkOptions); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver does not support network options", h.DriverName)
+			}
+			return fmt.Errorf("Error setting network options: %s", err)
+		}
+	}
+
+	if !h.HostOptions.InstanceOptions.IsEmpty() {
+		if err := drivers.SetInstanceOptions(h.Driver, *h.HostOptions.InstanceOptions); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver does not support instance profiles and tags", h.DriverName)
+			}
+			return fmt.Errorf("Error setting instance options: %s", err)
+		}
+	}
+
+	if h.HostOptions.TagResources {
+		if err := drivers.SetResourceTags(h.Driver, drivers.ResourceTags(h.Name)); err != nil && err != drivers.ErrNotImplemented {
+			return fmt.Errorf("Error setting resource tags: %s", err)
+		}
+	}
+
+	firewallRules := append(drivers.RequiredFirewallRules(h.HostOptions.EngineOptions.DaemonPort()), h.HostOptions.OpenPorts...)
+	if err := drivers.SetFirewallRules(h.Driver, firewallRules); err != nil {
+		if err != drivers.ErrNotImplemented {
+			return fmt.Errorf("Error setting firewall rules: %s", err)
+		}
+		if len(h.HostOptions.OpenPorts) > 0 {
+			return fmt.Errorf("The %s driver cannot open ports", h.DriverName)
+		}
+	}
+
+	if !h.HostOptions.GPUOptions.IsEmpty() {
+		if err := drivers.SetGPUOptions(h.Driver, *h.HostOptions.GPUOptions); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver does not support GPUs", h.DriverName)
+			}
+			return fmt.Errorf("Error setting GPU options: %s", err)
+		}
+	}
+
+	if err := api.setPlacement(h); err != nil {
+		return err
+	}
+
+	if err := api.setSize(h); err != nil {
+		return err
+	}
+
+	if h.HostOptions.SSHBastion != nil {
+		if err := drivers.SetSSHBastion(h.Driver, *h.HostOptions.SSHBastion); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver cannot reach machines through a bastion", h.DriverName)
+			}
+			return fmt.Errorf("Error setting the SSH bastion: %s", err)
+		}
+	}
+
 	log.Info("Running pre-create checks...")
 
 	if err := h.Driver.PreCreateCheck(); err != nil {
@@ -132,29 +430,57 @@ func (api *Client) Create(h *host.Host) error {
 		return fmt.Errorf("Error saving host to store before attempting creation: %s", err)
 	}
 
-	log.Info("Creating machine...")
+	return nil
+}
 
-	if err := api.performCreate(h); err != nil {
-		return fmt.Errorf("Error creating machine: %s", err)
+// checkInstanceMissing makes sure that the interrupted creation of a machine
+// left no instance behind before it is created again, which would leak the
+// instance or fail on its name being taken. The instance is looked up by the
+// driver, or else among the instances tagged with the name of the machine.
+func checkInstanceMissing(h *host.Host) error {
+	removeIt := fmt.Sprintf("Remove the machine with `docker-machine rm %s` and create it again.", h.Name)
+
+	exists, err := drivers.InstanceExists(h.Driver)
+	if err != nil && err != drivers.ErrNotImplemented {
+		return fmt.Errorf("Error checking whether the instance of %q exists: %s", h.Name, err)
+	}
+	if err == nil && exists {
+		return fmt.Errorf("The creation of %q was interrupted after its instance was created. %s", h.Name, removeIt)
 	}
+	checked := err == nil
 
-	log.Debug("Reticulating splines...")
+	instances, err := drivers.DiscoverInstances(h.Driver)
+	if err != nil && err != drivers.ErrNotImplemented {
+		return fmt.Errorf("Error looking for the instance of %q: %s", h.Name, err)
+	}
+	for _, instance := range instances {
+		if instance.MachineName == h.Name {
+			return fmt.Errorf("The creation of %q was interrupted after its instance %s was created. %s", h.Name, instance.ID, removeIt)
+		}
+	}
+	checked = checked || err == nil
+
+	if !checked {
+		return fmt.Errorf("The creation of %q was interrupted and the %s driver cannot tell whether its instance was created. %s", h.Name, h.DriverName, removeIt)
+	}
 
 	return nil
 }
 
-func (api *Client) performCreate(h *host.Host) error {
-	if err := h.Driver.Create(); err != nil {
-		return fmt.Errorf("Error in driver during machine creation: %s", err)
-	}
+func (api *Client) performCreate(h *host.Host, createInstance func() error) error {
+	if !h.CreateReached(host.CreatePhaseInstanceCreated) {
+		if err := createInstance(); err != nil {
+			return fmt.Errorf("Error in driver during machine creation: %s", err)
+		}
 
-	if err := api.Save(h); err != nil {
-		return fmt.Errorf("Error saving host to store after attempting creation: %s", err)
+		if err := api.saveCreatePhase(h, host.CreatePhaseInstanceCreated); err != nil {
+			return fmt.Errorf("Error saving host to store after attempting creation: %s", err)
+		}
 	}
 
 	// TODO: Not really a fan of just checking "none" or "ci-test" here.
 	if h.Driver.DriverName() == "none" || h.Driver.DriverName() == "ci-test" {
-		return nil
+		return api.saveCreatePhase(h, host.CreatePhaseProvisioned)
 	}
 
 	log.Info("Waiting for machine to be running, this may take a few minutes...")
@@ -162,27 +488,123 @@ func (api *Client) performCreate(h *host.Host) error {
 		return fmt.Errorf("Error waiting for machine to be running: %s", err)
 	}
 
+	if !h.CreateReached(host.CreatePhaseSSHReady) {
+		if err := drivers.WaitForSSH(h.Driver); err != nil {
+			return err
+		}
+
+		if err := api.saveCreatePhase(h, host.CreatePhaseSSHReady); err != nil {
+			return fmt.Errorf("Error saving host to store after waiting for SSH: %s", err)
+		}
+	}
+
 	log.Info("Detecting operating system of created instance...")
-	provisioner, err := provision.DetectProvisioner(h.Driver)
+	provisioner, err := h.Provisioner()
 	if err != nil {
 		return fmt.Errorf("Error detecting OS: %s", err)
 	}
 
+	if err := h.RecordHostKey(); err != nil {
+		log.Warnf("Error recording the host key: %s", err)
+	}
+
 	log.Infof("Provisioning with %s...", provisioner.String())
 	if err := provisioner.Provision(*h.HostOptions.SwarmOptions, *h.HostOptions.AuthOptions, *h.HostOptions.EngineOptions); err != nil {
 		return fmt.Errorf("Error running provisioning: %s", err)
 	}
 
-	// We should check the connection to docker here
-	log.Info("Checking connection to Docker...")
-	if _, _, err = check.DefaultConnChecker.Check(h, false); err != nil {
-		return fmt.Errorf("Error checking the host: %s", err)
+	if err := h.ConfigureSystem(provisioner); err != nil {
+		return err
+	}
+
+	// We should check the connection to docker here. The engine of the
+	// machines reached through a bastion may only be reachable from the
+	// private network, it was checked over SSH.
+	if h.HostOptions.SSHBastion == nil {
+		log.Info("Checking connection to Docker...")
+		if _, _, err = check.DefaultConnChecker.Check(h, false); err != nil {
+			return fmt.Errorf("Error checking the host: %s", err)
+		}
+	}
+
+	if err := provision.PreloadImages(provisioner, h.HostOptions.PreloadImages); err != nil {
+		return err
+	}
+
+	if err := api.saveCreatePhase(h, host.CreatePhaseProvisioned); err != nil {
+		return fmt.Errorf("Error saving host to store after provisioning: %s", err)
 	}
 
 	log.Info("Docker is up and running!")
 	return nil
 }
 
+// setPlacement has the driver place the machine in the region and zone of
+// its options, checks them with the provider and records the placement the
+// driver chose.
+func (api *Client) setPlacement(h *host.Host) error {
+	if !h.HostOptions.Placement.IsEmpty() {
+		if err := drivers.SetPlacement(h.Driver, *h.HostOptions.Placement); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver does not support regions and zones", h.DriverName)
+			}
+			return fmt.Errorf("Error setting the region and zone: %s", err)
+		}
+	}
+
+	if err := drivers.CheckPlacement(h.Driver); err != nil && err != drivers.ErrNotImplemented {
+		return fmt.Errorf("Invalid region and zone: %s", err)
+	}
+
+	placement, err := drivers.GetPlacement(h.Driver)
+	if err == drivers.ErrNotImplemented {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error getting the region and zone: %s", err)
+	}
+
+	h.HostOptions.Placement = &placement
+	return nil
+}
+
+// setSize has the driver size the machine with the memory and disk of the
+// host options, then records the size the driver settled on, for the options
+// to tell the actual size of the machine.
+func (api *Client) setSize(h *host.Host) error {
+	size := drivers.Size{
+		Memory: h.HostOptions.Memory,
+		Disk:   h.HostOptions.Disk,
+	}
+	if !size.IsEmpty() {
+		if err := drivers.SetSize(h.Driver, size); err != nil {
+			if err == drivers.ErrNotImplemented {
+				return fmt.Errorf("The %s driver does not support setting the memory and disk size", h.DriverName)
+			}
+			return fmt.Errorf("Invalid memory and disk size: %s", err)
+		}
+	}
+
+	size, err := drivers.GetSize(h.Driver)
+	if err == drivers.ErrNotImplemented {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Error getting the memory and disk size: %s", err)
+	}
+
+	h.HostOptions.Memory = size.Memory
+	h.HostOptions.Disk = size.Disk
+	return nil
+}
+
+// saveCreatePhase records that the creation of the machine completed the
+// phase, for an interrupted creation to resume after it.
+func (api *Client) saveCreatePhase(h *host.Host, phase host.CreatePhase) error {
+	h.CreatePhase = phase
+	return api.Save(h)
+}
+
 func (api *Client) Close() error {
 	return api.clientDriverFactory.Close()
 }
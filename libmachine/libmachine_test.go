@@ -0,0 +1,166 @@
+package libmachine
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCreatePhaseTestHost(t *testing.T, phase host.CreatePhase) (*Client, *host.Host, func()) {
+	storePath, err := ioutil.TempDir("", "machine-create")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := &host.Host{
+		ConfigVersion: version.ConfigVersion,
+		Name:          "test",
+		DriverName:    "none",
+		Driver:        none.NewDriver("test", storePath),
+		HostOptions:   &host.Options{},
+		CreatePhase:   phase,
+	}
+
+	return NewClient(storePath, storePath), h, func() { os.RemoveAll(storePath) }
+}
+
+func TestPerformCreateRecordsPhases(t *testing.T) {
+	api, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	created := false
+	assert.NoError(t, api.performCreate(h, func() error {
+		created = true
+		return nil
+	}))
+
+	assert.True(t, created)
+	assert.Equal(t, host.CreatePhaseProvisioned, h.CreatePhase)
+
+	saved, err := api.Load("test")
+	assert.NoError(t, err)
+	assert.Equal(t, host.CreatePhaseProvisioned, saved.CreatePhase)
+	assert.False(t, saved.CreateIncomplete())
+}
+
+func TestPerformCreateResumesAfterInstanceCreated(t *testing.T) {
+	api, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhaseInstanceCreated)
+	defer cleanup()
+
+	err := api.performCreate(h, func() error {
+		return errors.New("the instance must not be created again")
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, host.CreatePhaseProvisioned, h.CreatePhase)
+}
+
+func TestPerformCreateKeepsPhaseOnFailure(t *testing.T) {
+	api, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	err := api.performCreate(h, func() error {
+		return errors.New("quota exceeded")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, host.CreatePhasePending, h.CreatePhase)
+	assert.True(t, h.CreateIncomplete())
+}
+
+type placerDriver struct {
+	*none.Driver
+	placement drivers.Placement
+	checkErr  error
+}
+
+func (d *placerDriver) SetPlacement(placement drivers.Placement) error {
+	d.placement = placement
+	return nil
+}
+
+func (d *placerDriver) GetPlacement() (drivers.Placement, error) {
+	if d.placement.Zone == "" {
+		return drivers.Placement{Region: "us-east-1", Zone: "us-east-1a"}, nil
+	}
+	return d.placement, nil
+}
+
+func (d *placerDriver) CheckPlacement() error {
+	return d.checkErr
+}
+
+func TestSetPlacement(t *testing.T) {
+	api, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	assert.NoError(t, api.setPlacement(h))
+	assert.Nil(t, h.HostOptions.Placement)
+
+	h.HostOptions.Placement = &drivers.Placement{Region: "eu-west-1"}
+	assert.EqualError(t, api.setPlacement(h), "The none driver does not support regions and zones")
+
+	driver := &placerDriver{Driver: none.NewDriver("test", "")}
+	h.Driver = driver
+	h.HostOptions.Placement = &drivers.Placement{}
+	assert.NoError(t, api.setPlacement(h))
+	assert.Equal(t, &drivers.Placement{Region: "us-east-1", Zone: "us-east-1a"}, h.HostOptions.Placement, "the defaults of the driver are recorded")
+
+	driver.checkErr = errors.New("The zone eu-west-1e does not exist in the region eu-west-1")
+	h.HostOptions.Placement = &drivers.Placement{Region: "eu-west-1", Zone: "eu-west-1e"}
+	assert.EqualError(t, api.setPlacement(h), "Invalid region and zone: The zone eu-west-1e does not exist in the region eu-west-1")
+}
+
+func TestPrepareCreateWithUnsupportedNetworkOptions(t *testing.T) {
+	api, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	h.HostOptions.NetworkOptions = &drivers.NetworkOptions{StaticIP: "192.168.99.50"}
+
+	assert.EqualError(t, api.prepareCreate(h), "The none driver does not support network options")
+}
+
+func TestCheckInstanceMissing(t *testing.T) {
+	h := &host.Host{
+		Name:       "test",
+		DriverName: "fakedriver",
+		Driver:     &fakedriver.Driver{MockInstanceMissing: true},
+	}
+
+	assert.NoError(t, checkInstanceMissing(h))
+}
+
+func TestCheckInstanceMissingWhenCreated(t *testing.T) {
+	h := &host.Host{
+		Name:       "test",
+		DriverName: "fakedriver",
+		Driver:     &fakedriver.Driver{},
+	}
+
+	assert.EqualError(t, checkInstanceMissing(h), "The creation of \"test\" was interrupted after its instance was created. Remove the machine with `docker-machine rm test` and create it again.")
+}
+
+func TestCheckInstanceMissingUnknown(t *testing.T) {
+	_, h, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	assert.EqualError(t, checkInstanceMissing(h), "The creation of \"test\" was interrupted and the none driver cannot tell whether its instance was created. Remove the machine with `docker-machine rm test` and create it again.")
+}
+
+func TestKnownHostsPerStore(t *testing.T) {
+	work := NewClient("/stores/work", "/stores/work/certs")
+	personal := NewClient("/stores/personal", "/stores/personal/certs")
+	personal.KnownHostsFile = work.KnownHostsFile
+
+	assert.Equal(t, "/stores/work/known_hosts", work.KnownHosts("dev").File)
+	assert.NotEqual(t, work.KnownHosts("dev").Alias, personal.KnownHosts("dev").Alias)
+	assert.Equal(t, work.KnownHosts("dev"), KnownHostsOf(work, "dev"))
+}
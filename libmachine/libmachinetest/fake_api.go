@@ -1,15 +1,23 @@
 package libmachinetest
 
 import (
+	"sync"
+
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist"
 	"github.com/docker/machine/libmachine/state"
 )
 
+// auditLock serializes the recording of the operations run concurrently.
+var auditLock sync.Mutex
+
 type FakeAPI struct {
 	Hosts []*host.Host
+	// Audit holds the operations recorded in the audit log
+	Audit []persist.AuditEntry
 }
 
 func (api *FakeAPI) NewPluginDriver(string, []byte) (drivers.Driver, error) {
@@ -72,6 +80,27 @@ func (api *FakeAPI) Save(host *host.Host) error {
 	return nil
 }
 
+func (api *FakeAPI) RecordAudit(entry persist.AuditEntry) error {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	api.Audit = append(api.Audit, entry)
+	return nil
+}
+
+func (api *FakeAPI) QueryAudit(filter persist.AuditFilter) ([]persist.AuditEntry, error) {
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	entries := []persist.AuditEntry{}
+	for _, entry := range api.Audit {
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
 func (api FakeAPI) GetMachinesDir() string {
 	return ""
 }
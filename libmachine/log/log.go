@@ -20,6 +20,7 @@ func stripSecrets(original []string) []string {
 	for _, line := range original {
 		line = certRegex.ReplaceAllString(line, redactedText)
 		line = keyRegex.ReplaceAllString(line, redactedText)
+		line = redactValues(line)
 		stripped = append(stripped, line)
 	}
 	return stripped
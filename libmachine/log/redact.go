@@ -0,0 +1,38 @@
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+// minRedactedLength is the length under which values are not redacted, not
+// to mangle every log line with a short value, e.g. a password of "a".
+const minRedactedLength = 4
+
+var (
+	redactedValues      = map[string]bool{}
+	redactedValuesMutex sync.RWMutex
+)
+
+// RedactValue has a secret, e.g. the API token of a driver, replaced by
+// <REDACTED> wherever it appears in the logs from now on.
+func RedactValue(value string) {
+	if len(value) < minRedactedLength {
+		return
+	}
+
+	redactedValuesMutex.Lock()
+	defer redactedValuesMutex.Unlock()
+
+	redactedValues[value] = true
+}
+
+func redactValues(line string) string {
+	redactedValuesMutex.RLock()
+	defer redactedValuesMutex.RUnlock()
+
+	for value := range redactedValues {
+		line = strings.Replace(line, value, redactedText, -1)
+	}
+	return line
+}
@@ -0,0 +1,20 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactValue(t *testing.T) {
+	RedactValue("s3cr3t-t0ken")
+	RedactValue("abc")
+
+	testLogger := NewFmtMachineLogger()
+
+	assert.Equal(t, "Calling the API with <REDACTED> as abc", captureOutput(testLogger, func() {
+		testLogger.Infof("Calling the API with %s as abc", "s3cr3t-t0ken")
+	}))
+	assert.Equal(t, "token <REDACTED>", captureError(testLogger, func() { testLogger.Error("token", "s3cr3t-t0ken") }))
+	assert.Equal(t, []string{"Calling the API with <REDACTED> as abc", "token<REDACTED>"}, stripSecrets(testLogger.History()))
+}
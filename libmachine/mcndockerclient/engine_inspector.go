@@ -0,0 +1,58 @@
+package mcndockerclient
+
+import (
+	"fmt"
+
+	"github.com/samalba/dockerclient"
+)
+
+// CurrentEngineInspector is used to query the engines, swapped in tests.
+var CurrentEngineInspector EngineInspector = &defaultEngineInspector{}
+
+// EngineInspector reads the version and the state of engines over the
+// Docker remote API.
+type EngineInspector interface {
+	EngineVersion(host DockerHost) (*dockerclient.Version, error)
+	EngineInfo(host DockerHost) (*dockerclient.Info, error)
+}
+
+// EngineVersion returns what the engine reports of its version.
+func EngineVersion(host DockerHost) (*dockerclient.Version, error) {
+	return CurrentEngineInspector.EngineVersion(host)
+}
+
+// EngineInfo returns what the engine reports of its state, as docker info
+// prints it.
+func EngineInfo(host DockerHost) (*dockerclient.Info, error) {
+	return CurrentEngineInspector.EngineInfo(host)
+}
+
+type defaultEngineInspector struct{}
+
+func (ei *defaultEngineInspector) EngineVersion(host DockerHost) (*dockerclient.Version, error) {
+	client, err := DockerClient(host)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query docker version: %s", err)
+	}
+
+	version, err := client.Version()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query docker version: %s", err)
+	}
+
+	return version, nil
+}
+
+func (ei *defaultEngineInspector) EngineInfo(host DockerHost) (*dockerclient.Info, error) {
+	client, err := DockerClient(host)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query docker info: %s", err)
+	}
+
+	info, err := client.Info()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to query docker info: %s", err)
+	}
+
+	return info, nil
+}
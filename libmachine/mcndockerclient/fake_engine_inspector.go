@@ -0,0 +1,25 @@
+package mcndockerclient
+
+import "github.com/samalba/dockerclient"
+
+type FakeEngineInspector struct {
+	Version *dockerclient.Version
+	Info    *dockerclient.Info
+	Err     error
+}
+
+func (ei *FakeEngineInspector) EngineVersion(host DockerHost) (*dockerclient.Version, error) {
+	if ei.Err != nil {
+		return nil, ei.Err
+	}
+
+	return ei.Version, nil
+}
+
+func (ei *FakeEngineInspector) EngineInfo(host DockerHost) (*dockerclient.Info, error) {
+	if ei.Err != nil {
+		return nil, ei.Err
+	}
+
+	return ei.Info, nil
+}
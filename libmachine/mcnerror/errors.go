@@ -44,3 +44,21 @@ type ErrHostAlreadyInState struct {
 func (e ErrHostAlreadyInState) Error() string {
 	return fmt.Sprintf("Machine %q is already %s.", e.Name, strings.ToLower(e.State.String()))
 }
+
+type ErrEngineDowngrade struct {
+	Name    string
+	Current string
+	Target  string
+}
+
+func (e ErrEngineDowngrade) Error() string {
+	return fmt.Sprintf("Refusing to downgrade the engine of machine %q from %s to %s", e.Name, e.Current, e.Target)
+}
+
+type ErrHostProtected struct {
+	Name string
+}
+
+func (e ErrHostProtected) Error() string {
+	return fmt.Sprintf("Machine %q is protected against removal, unprotect it first or override the protection", e.Name)
+}
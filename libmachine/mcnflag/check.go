@@ -0,0 +1,87 @@
+package mcnflag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Checker is implemented by the flags which constrain the values they are
+// given.
+type Checker interface {
+	Flag
+	Check(value interface{}) error
+}
+
+// Check returns an error when the value of the flag is missing or invalid.
+func (f StringFlag) Check(value interface{}) error {
+	s, _ := value.(string)
+
+	if s == "" {
+		if f.Required {
+			return fmt.Errorf("--%s is required", f.Name)
+		}
+		return nil
+	}
+
+	if len(f.Choices) > 0 && !contains(f.Choices, s) {
+		return fmt.Errorf("invalid value %q for --%s, must be one of %s", s, f.Name, strings.Join(f.Choices, ", "))
+	}
+
+	if f.Validate != nil {
+		if err := f.Validate(s); err != nil {
+			return fmt.Errorf("invalid value %q for --%s: %s", s, f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Check returns an error when the values of the flag are missing or invalid.
+func (f StringSliceFlag) Check(value interface{}) error {
+	values, _ := value.([]string)
+
+	if len(values) == 0 && f.Required {
+		return fmt.Errorf("--%s is required", f.Name)
+	}
+
+	if f.Validate != nil {
+		if err := f.Validate(values); err != nil {
+			return fmt.Errorf("invalid value for --%s: %s", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Check returns an error when the value of the flag is invalid.
+func (f IntFlag) Check(value interface{}) error {
+	i, _ := value.(int)
+
+	if f.Validate != nil {
+		if err := f.Validate(i); err != nil {
+			return fmt.Errorf("invalid value %d for --%s: %s", i, f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// IntRange returns a function validating that an int is within bounds, for
+// use as the Validate of an IntFlag.
+func IntRange(min, max int) func(int) error {
+	return func(value int) error {
+		if value < min || value > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -12,6 +12,14 @@ type StringFlag struct {
 	Usage  string
 	EnvVar string
 	Value  string
+
+	// Required flags must be given a non empty value
+	Required bool
+	// Choices, when set, lists the values the flag accepts
+	Choices []string
+	// Validate checks the value given to the flag. It is only available
+	// in the process of the driver, as functions are not sent to plugins.
+	Validate func(value string) error
 }
 
 // TODO: Could this be done more succinctly using embedding?
@@ -28,6 +36,11 @@ type StringSliceFlag struct {
 	Usage  string
 	EnvVar string
 	Value  []string
+
+	// Required flags must be given at least one value
+	Required bool
+	// Validate checks the values given to the flag
+	Validate func(values []string) error
 }
 
 // TODO: Could this be done more succinctly using embedding?
@@ -44,6 +57,9 @@ type IntFlag struct {
 	Usage  string
 	EnvVar string
 	Value  int
+
+	// Validate checks the value given to the flag
+	Validate func(value int) error
 }
 
 // TODO: Could this be done more succinctly using embedding?
@@ -16,6 +16,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/docker/machine/libmachine/assets"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/version"
 )
@@ -458,6 +459,18 @@ func (b *B2dUtils) CopyIsoToMachineDir(isoURL, machineName string) error {
 		return err
 	}
 
+	// ISOs whose checksum is pinned with a #sha256=<checksum> fragment are
+	// kept in the asset cache, so that they are only downloaded once
+	if assets.IsRemote(downloadURL) && assets.IsPinned(downloadURL) {
+		cachedPath, err := assets.NewCache(filepath.Join(b.imgCachePath, "assets")).Fetch(downloadURL)
+		if err != nil {
+			return err
+		}
+
+		log.Infof("Copying %s to %s...", cachedPath, machineIsoPath)
+		return CopyFile(cachedPath, machineIsoPath)
+	}
+
 	return b.DownloadISO(machineDir, b.filename(), downloadURL)
 }
 
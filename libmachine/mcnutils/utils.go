@@ -2,10 +2,12 @@ package mcnutils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"time"
@@ -131,3 +133,15 @@ func GenerateRandomID() string {
 		return value
 	}
 }
+
+// PathID returns a short hash of the absolute path, telling apart what is
+// named after the machines of different stores, e.g. their host keys or
+// their autostart units.
+func PathID(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	hash := sha256.Sum256([]byte(filepath.Clean(path)))
+	return hex.EncodeToString(hash[:4])
+}
@@ -0,0 +1,124 @@
+// Package metrics lets programs embedding libmachine measure the operations
+// on machines, e.g. to feed Prometheus or statsd, without wrapping every
+// call to libmachine.
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// Operation is an operation libmachine performed on a machine.
+type Operation struct {
+	// Name of the operation: create, start, stop or provision
+	Name     string
+	Host     string
+	Driver   string
+	Duration time.Duration
+	// Err is the error the operation failed with, nil if it succeeded
+	Err error
+}
+
+// Succeeded tells whether the operation succeeded.
+func (o Operation) Succeeded() bool {
+	return o.Err == nil
+}
+
+// Recorder receives the operations performed by libmachine. Recorders are
+// called synchronously, from any goroutine, and must not block.
+type Recorder interface {
+	RecordOperation(op Operation)
+}
+
+// RecorderFunc adapts a function to a Recorder.
+type RecorderFunc func(op Operation)
+
+// RecordOperation calls f(op).
+func (f RecorderFunc) RecordOperation(op Operation) {
+	f(op)
+}
+
+var (
+	recorders      []Recorder
+	recordersMutex sync.RWMutex
+)
+
+// AddRecorder registers a recorder receiving all the operations.
+func AddRecorder(recorder Recorder) {
+	recordersMutex.Lock()
+	defer recordersMutex.Unlock()
+
+	recorders = append(recorders, recorder)
+}
+
+// Observe records the operation which started at start and failed with
+// *err, if any. It is meant to be deferred by the function performing the
+// operation:
+//
+//	func (h *Host) Start() (err error) {
+//		defer metrics.Observe("start", h.Name, h.DriverName, time.Now(), &err)
+//		...
+//	}
+func Observe(name, host, driver string, start time.Time, err *error) {
+	recordersMutex.RLock()
+	defer recordersMutex.RUnlock()
+
+	if len(recorders) == 0 {
+		return
+	}
+
+	op := Operation{
+		Name:     name,
+		Host:     host,
+		Driver:   driver,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		op.Err = *err
+	}
+
+	for _, recorder := range recorders {
+		recorder.RecordOperation(op)
+	}
+}
+
+// Retry is a request to the API of a provider retried after the provider
+// throttled it.
+type Retry struct {
+	// Provider is the driver sending the request, e.g. amazonec2.
+	Provider string
+
+	// Method and Host are the method of the request and the host of the
+	// API, e.g. ec2.us-east-1.amazonaws.com.
+	Method string
+	Host   string
+
+	// Attempt is the number of the retry, from 1, and Delay how long it was
+	// waited for.
+	Attempt int
+	Delay   time.Duration
+
+	// StatusCode is the status the provider throttled the request with,
+	// e.g. 429.
+	StatusCode int
+}
+
+// RetryRecorder is implemented by the recorders also receiving the retries
+// of the requests the providers throttled, e.g. to tell when batch creates
+// hit the rate limits of a provider.
+type RetryRecorder interface {
+	RecordRetry(retry Retry)
+}
+
+// ObserveRetry records the retry with the recorders implementing
+// RetryRecorder.
+func ObserveRetry(retry Retry) {
+	recordersMutex.RLock()
+	defer recordersMutex.RUnlock()
+
+	for _, recorder := range recorders {
+		if retryRecorder, ok := recorder.(RetryRecorder); ok {
+			retryRecorder.RecordRetry(retry)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func operation(name string, fail bool) (err error) {
+	defer Observe(name, "test", "fake", time.Now(), &err)
+
+	if fail {
+		return errors.New("failed")
+	}
+	return nil
+}
+
+func TestObserve(t *testing.T) {
+	defer func() { recorders = nil }()
+
+	ops := []Operation{}
+	AddRecorder(RecorderFunc(func(op Operation) {
+		ops = append(ops, op)
+	}))
+
+	operation("start", false)
+	operation("stop", true)
+
+	assert.Len(t, ops, 2)
+	assert.Equal(t, "start", ops[0].Name)
+	assert.Equal(t, "test", ops[0].Host)
+	assert.Equal(t, "fake", ops[0].Driver)
+	assert.True(t, ops[0].Succeeded())
+	assert.Equal(t, "stop", ops[1].Name)
+	assert.EqualError(t, ops[1].Err, "failed")
+	assert.False(t, ops[1].Succeeded())
+}
+
+type retryRecorder struct {
+	retries []Retry
+}
+
+func (r *retryRecorder) RecordOperation(op Operation) {}
+
+func (r *retryRecorder) RecordRetry(retry Retry) {
+	r.retries = append(r.retries, retry)
+}
+
+func TestObserveRetry(t *testing.T) {
+	defer func() { recorders = nil }()
+
+	ops := []Operation{}
+	AddRecorder(RecorderFunc(func(op Operation) {
+		ops = append(ops, op)
+	}))
+	recorder := &retryRecorder{}
+	AddRecorder(recorder)
+
+	ObserveRetry(Retry{Provider: "amazonec2", Attempt: 1, StatusCode: 503})
+
+	assert.Empty(t, ops)
+	assert.Equal(t, []Retry{{Provider: "amazonec2", Attempt: 1, StatusCode: 503}}, recorder.retries)
+}
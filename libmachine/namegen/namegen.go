@@ -0,0 +1,103 @@
+// Package namegen names machines automatically, e.g. the ephemeral machines
+// of CI pools, with names no machine of the store has.
+package namegen
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// MaxAttempts is how many names Unique tries before giving up.
+const MaxAttempts = 1000
+
+// Generator generates the names of machines. Generate is called with the
+// number of the attempt, from 0, the names of the previous attempts being
+// taken.
+type Generator interface {
+	Generate(attempt int) string
+}
+
+// GeneratorFunc adapts a function to a Generator.
+type GeneratorFunc func(attempt int) string
+
+// Generate calls f(attempt).
+func (f GeneratorFunc) Generate(attempt int) string {
+	return f(attempt)
+}
+
+var (
+	adjectives = []string{
+		"agile", "bold", "brave", "bright", "calm", "clever", "cosmic", "crisp",
+		"eager", "fancy", "fast", "gentle", "happy", "jolly", "keen", "lively",
+		"lucky", "mighty", "nimble", "noble", "proud", "quick", "quiet", "rapid",
+		"shiny", "silent", "smart", "steady", "swift", "tidy", "vivid", "witty",
+	}
+
+	nouns = []string{
+		"badger", "beaver", "bison", "condor", "coyote", "crane", "dolphin",
+		"eagle", "falcon", "ferret", "gecko", "heron", "ibex", "jaguar",
+		"koala", "lemur", "lynx", "marmot", "narwhal", "ocelot", "otter",
+		"panda", "pelican", "puffin", "quokka", "raven", "salmon", "tapir",
+		"toucan", "walrus", "wombat", "zebra",
+	}
+
+	random      = rand.New(rand.NewSource(time.Now().UnixNano()))
+	randomMutex sync.Mutex
+)
+
+func randomIntn(n int) int {
+	randomMutex.Lock()
+	defer randomMutex.Unlock()
+	return random.Intn(n)
+}
+
+// AdjectiveNoun generates names such as swift-otter, picked at random. Once
+// a few names are taken, a number is appended, e.g. swift-otter-4821.
+type AdjectiveNoun struct{}
+
+// Generate returns a random adjective-noun name.
+func (AdjectiveNoun) Generate(attempt int) string {
+	name := adjectives[randomIntn(len(adjectives))] + "-" + nouns[randomIntn(len(nouns))]
+	if attempt >= 5 {
+		name = fmt.Sprintf("%s-%d", name, randomIntn(10000))
+	}
+	return name
+}
+
+// Prefixed generates the names prefix-1, prefix-2... so that Unique returns
+// the lowest number free.
+type Prefixed struct {
+	Prefix string
+}
+
+// Generate returns the name numbered after the attempt.
+func (p Prefixed) Generate(attempt int) string {
+	return fmt.Sprintf("%s-%d", p.Prefix, attempt+1)
+}
+
+// Unique returns a name of the generator which is a valid host name and no
+// machine of the store has. Machines created concurrently may still be
+// given the same name, which their creation then fails on.
+func Unique(store persist.Store, generator Generator) (string, error) {
+	for attempt := 0; attempt < MaxAttempts; attempt++ {
+		name := generator.Generate(attempt)
+		if !host.ValidateHostName(name) {
+			return "", fmt.Errorf("Invalid generated name %q", name)
+		}
+
+		exists, err := store.Exists(name)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("No free name found after %d attempts", MaxAttempts)
+}
@@ -0,0 +1,40 @@
+package namegen
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdjectiveNoun(t *testing.T) {
+	assert.Regexp(t, regexp.MustCompile(`^[a-z]+-[a-z]+$`), AdjectiveNoun{}.Generate(0))
+	assert.Regexp(t, regexp.MustCompile(`^[a-z]+-[a-z]+-\d+$`), AdjectiveNoun{}.Generate(5))
+}
+
+func TestUniquePrefixed(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{{Name: "ci-1"}, {Name: "ci-2"}, {Name: "ci-4"}},
+	}
+
+	name, err := Unique(store, Prefixed{Prefix: "ci"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ci-3", name)
+}
+
+func TestUniqueInvalidName(t *testing.T) {
+	_, err := Unique(&persisttest.FakeStore{}, Prefixed{Prefix: "ci pool"})
+
+	assert.EqualError(t, err, `Invalid generated name "ci pool-1"`)
+}
+
+func TestUniqueExhausted(t *testing.T) {
+	store := &persisttest.FakeStore{Hosts: []*host.Host{{Name: "fixed"}}}
+
+	_, err := Unique(store, GeneratorFunc(func(attempt int) string { return "fixed" }))
+
+	assert.EqualError(t, err, "No free name found after 1000 attempts")
+}
@@ -0,0 +1,141 @@
+package libmachine
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+var (
+	ErrNamespaceNotFound = errors.New("Namespace not found")
+)
+
+// Namespaces are independent machine stores a process works with side by
+// side, e.g. "work" and "personal", each with its own machines and
+// certificates, the namespace being selected per operation.
+//
+// The host keys of the machines are checked against the known_hosts file of
+// the client of their namespace, see Client.KnownHosts.
+type Namespaces struct {
+	mutex sync.Mutex
+	apis  map[string]API
+}
+
+// NewNamespaces returns an empty set of namespaces.
+func NewNamespaces() *Namespaces {
+	return &Namespaces{
+		apis: map[string]API{},
+	}
+}
+
+// NamespaceDir returns the store path of a namespace under a base directory,
+// <baseDir>/namespaces/<name>.
+func NamespaceDir(baseDir, name string) string {
+	return filepath.Join(baseDir, "namespaces", name)
+}
+
+// Add makes the store of the api available under the namespace name.
+func (n *Namespaces) Add(name string, api API) error {
+	if !host.ValidateHostName(name) {
+		return fmt.Errorf("Invalid namespace name %q", name)
+	}
+
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if _, ok := n.apis[name]; ok {
+		return fmt.Errorf("Namespace %q already exists", name)
+	}
+
+	n.apis[name] = api
+	return nil
+}
+
+// Open adds a namespace for the store at storePath, with the certificates in
+// certsDir, and returns its client to set up further, e.g. its SSH client
+// type.
+func (n *Namespaces) Open(name, storePath, certsDir string) (*Client, error) {
+	client := NewClient(storePath, certsDir)
+	if err := n.Add(name, client); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Get returns the api of the namespace.
+func (n *Namespaces) Get(name string) (API, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	api, ok := n.apis[name]
+	if !ok {
+		return nil, ErrNamespaceNotFound
+	}
+
+	return api, nil
+}
+
+// In runs f with the api of the namespace.
+func (n *Namespaces) In(name string, f func(api API) error) error {
+	api, err := n.Get(name)
+	if err != nil {
+		return err
+	}
+
+	return f(api)
+}
+
+// Names returns the names of the namespaces, sorted.
+func (n *Namespaces) Names() []string {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	names := []string{}
+	for name := range n.apis {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Remove closes the api of the namespace and forgets the namespace. Its
+// store is left on disk.
+func (n *Namespaces) Remove(name string) error {
+	n.mutex.Lock()
+	api, ok := n.apis[name]
+	delete(n.apis, name)
+	n.mutex.Unlock()
+
+	if !ok {
+		return ErrNamespaceNotFound
+	}
+
+	return api.Close()
+}
+
+// Close closes the apis of all the namespaces.
+func (n *Namespaces) Close() error {
+	n.mutex.Lock()
+	apis := n.apis
+	n.apis = map[string]API{}
+	n.mutex.Unlock()
+
+	var errs []error
+	for _, api := range apis {
+		if err := api.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return mcnutils.MultiError{Errs: errs}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package libmachine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespacesAreIndependent(t *testing.T) {
+	work := &persisttest.FakeStore{Hosts: []*host.Host{newPruneTestHost("build", false)}}
+	personal := &persisttest.FakeStore{Hosts: []*host.Host{newPruneTestHost("blog", false)}}
+
+	namespaces := NewNamespaces()
+	assert.NoError(t, namespaces.Add("work", &pruneTestAPI{work}))
+	assert.NoError(t, namespaces.Add("personal", &pruneTestAPI{personal}))
+
+	assert.Equal(t, []string{"personal", "work"}, namespaces.Names())
+
+	err := namespaces.In("work", func(api API) error {
+		names, err := api.List()
+		assert.Equal(t, []string{"build"}, names)
+		return err
+	})
+	assert.NoError(t, err)
+
+	api, err := namespaces.Get("personal")
+	assert.NoError(t, err)
+	exists, _ := api.Exists("build")
+	assert.False(t, exists)
+}
+
+func TestNamespacesErrors(t *testing.T) {
+	namespaces := NewNamespaces()
+
+	assert.Error(t, namespaces.Add("not/valid", &pruneTestAPI{&persisttest.FakeStore{}}))
+	assert.NoError(t, namespaces.Add("work", &pruneTestAPI{&persisttest.FakeStore{}}))
+	assert.Error(t, namespaces.Add("work", &pruneTestAPI{&persisttest.FakeStore{}}))
+
+	_, err := namespaces.Get("personal")
+	assert.Equal(t, ErrNamespaceNotFound, err)
+
+	assert.NoError(t, namespaces.Remove("work"))
+	assert.Equal(t, ErrNamespaceNotFound, namespaces.Remove("work"))
+	assert.Empty(t, namespaces.Names())
+}
+
+func TestNamespacesOpen(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "machine-namespaces")
+	assert.NoError(t, err)
+	defer os.RemoveAll(baseDir)
+
+	namespaces := NewNamespaces()
+	defer namespaces.Close()
+
+	storePath := NamespaceDir(baseDir, "work")
+	client, err := namespaces.Open("work", storePath, filepath.Join(storePath, "certs"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(baseDir, "namespaces", "work", "machines"), client.GetMachinesDir())
+}
@@ -0,0 +1,56 @@
+package persist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func (s Filestore) activePath() string {
+	return filepath.Join(s.Path, "active")
+}
+
+// GetActive returns the name of the machine selected as the active one, or ""
+// if none is, or if the selected machine was removed since.
+func (s Filestore) GetActive() (string, error) {
+	data, err := ioutil.ReadFile(s.activePath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return "", nil
+	}
+
+	exists, err := s.Exists(name)
+	if err != nil || !exists {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// SetActive selects the active machine, or clears the selection if name is "".
+func (s Filestore) SetActive(name string) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	if name == "" {
+		if err := os.Remove(s.activePath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(s.Path, 0700); err != nil {
+		return err
+	}
+
+	return s.saveToFile([]byte(name+"\n"), s.activePath())
+}
@@ -0,0 +1,274 @@
+package persist
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+)
+
+// machineCerts are the copies of the CA and client certificates kept in the
+// directory of every machine, which an imported machine uses since the store
+// it is imported into has another CA.
+var machineCerts = []struct {
+	file string
+	set  func(authOptions map[string]interface{}, path string)
+}{
+	{"ca.pem", func(o map[string]interface{}, path string) { o["CaCertPath"] = path }},
+	{"cert.pem", func(o map[string]interface{}, path string) { o["ClientCertPath"] = path }},
+	{"key.pem", func(o map[string]interface{}, path string) { o["ClientKeyPath"] = path }},
+}
+
+// Export writes the machine to w as a gzipped tar archive of its directory,
+// which Import reads back. The configuration is written decrypted, for the
+// archive to be imported in a store with another secret key.
+func (s Filestore) Export(name string, w io.Writer) error {
+	h, err := s.Load(name)
+	if err != nil {
+		return err
+	}
+
+	config, err := json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	archive := tar.NewWriter(gz)
+
+	if err := writeArchiveFile(archive, filepath.Join(name, configFileName), config, 0600); err != nil {
+		return err
+	}
+
+	hostPath := filepath.Join(s.GetMachinesDir(), name)
+	err = filepath.Walk(hostPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() || isStoreFile(info.Name()) {
+			return nil
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(s.GetMachinesDir(), path)
+		if err != nil {
+			return err
+		}
+
+		return writeArchiveFile(archive, relPath, data, info.Mode().Perm())
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// Import adds the machine of an archive written by Export to the store. The
+// paths of its configuration are moved to the store, and its engine is
+// reached with the certificates of the archive.
+func (s Filestore) Import(r io.Reader) (*host.Host, error) {
+	if s.ReadOnly {
+		return nil, ErrReadOnlyStore
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the archive: %s", err)
+	}
+	defer gz.Close()
+
+	if err := os.MkdirAll(s.GetMachinesDir(), 0700); err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := ioutil.TempDir(s.GetMachinesDir(), ".import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	name, err := extractArchive(tar.NewReader(gz), tmpDir)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the archive: %s", err)
+	}
+
+	if !host.ValidateHostName(name) {
+		return nil, fmt.Errorf("Invalid machine name %q in the archive", name)
+	}
+
+	exists, err := s.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("Machine %q already exists", name)
+	}
+
+	if err := s.relocateConfig(filepath.Join(tmpDir, name), name); err != nil {
+		return nil, err
+	}
+
+	if err := os.Rename(filepath.Join(tmpDir, name), filepath.Join(s.GetMachinesDir(), name)); err != nil {
+		return nil, err
+	}
+
+	h, err := s.Load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Saving encrypts the configuration with the key of the store, if any.
+	if err := s.Save(h); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// relocateConfig rewrites the paths of the configuration of an extracted
+// machine from the store it was exported from to this store.
+func (s Filestore) relocateConfig(dir, name string) error {
+	configPath := filepath.Join(dir, configFileName)
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("The archive holds no configuration: %s", err)
+	}
+
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("Error reading the configuration of %q: %s", name, err)
+	}
+
+	driver, _ := config["Driver"].(map[string]interface{})
+	oldStorePath, _ := driver["StorePath"].(string)
+	if oldStorePath != "" {
+		oldHostPath := filepath.Join(oldStorePath, "machines", name)
+		newHostPath := filepath.Join(s.GetMachinesDir(), name)
+		config = relocatePaths(config, oldHostPath, newHostPath).(map[string]interface{})
+		config = relocatePaths(config, oldStorePath, s.Path).(map[string]interface{})
+	}
+
+	hostOptions, _ := config["HostOptions"].(map[string]interface{})
+	if authOptions, ok := hostOptions["AuthOptions"].(map[string]interface{}); ok {
+		for _, cert := range machineCerts {
+			if _, err := os.Stat(filepath.Join(dir, cert.file)); err == nil {
+				cert.set(authOptions, filepath.Join(s.GetMachinesDir(), name, cert.file))
+			}
+		}
+	}
+
+	data, err = json.MarshalIndent(config, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(configPath, data, 0600)
+}
+
+// relocatePaths replaces the oldPath prefix of the paths found in the
+// strings of the decoded JSON value with newPath.
+func relocatePaths(value interface{}, oldPath, newPath string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = relocatePaths(item, oldPath, newPath)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = relocatePaths(item, oldPath, newPath)
+		}
+	case string:
+		if v == oldPath || strings.HasPrefix(v, oldPath+string(filepath.Separator)) {
+			return newPath + v[len(oldPath):]
+		}
+	}
+	return value
+}
+
+// extractArchive extracts the files of the archive of a machine in dir and
+// returns the name of the machine, the directory all the files are in.
+func extractArchive(archive *tar.Reader, dir string) (string, error) {
+	name := ""
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Clean(filepath.FromSlash(header.Name))
+		parts := strings.SplitN(path, string(filepath.Separator), 2)
+		if len(parts) != 2 || filepath.IsAbs(path) || parts[0] == ".." || strings.HasPrefix(parts[1], "..") {
+			return "", fmt.Errorf("unexpected file %s", header.Name)
+		}
+		if name == "" {
+			name = parts[0]
+		} else if parts[0] != name {
+			return "", fmt.Errorf("the archive holds more than one machine: %s and %s", name, parts[0])
+		}
+
+		target := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return "", err
+		}
+
+		file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode).Perm())
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(file, archive)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("the archive is empty")
+	}
+
+	return name, nil
+}
+
+func writeArchiveFile(archive *tar.Writer, path string, data []byte, mode os.FileMode) error {
+	if err := archive.WriteHeader(&tar.Header{
+		Name:     filepath.ToSlash(path),
+		Typeflag: tar.TypeReg,
+		Mode:     int64(mode),
+		Size:     int64(len(data)),
+	}); err != nil {
+		return err
+	}
+
+	_, err := archive.Write(data)
+	return err
+}
+
+// isStoreFile tells whether the file of a machine directory is kept by the
+// store rather than by the machine, e.g. the backups of its configuration.
+func isStoreFile(name string) bool {
+	return name == ownerFileName || strings.HasPrefix(name, configFileName)
+}
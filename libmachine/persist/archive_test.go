@@ -0,0 +1,99 @@
+package persist
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/hosttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportImport(t *testing.T) {
+	source := getTestStore()
+	defer os.RemoveAll(source.Path)
+	target := getTestStore()
+	defer os.RemoveAll(target.Path)
+
+	h, err := hosttest.GetDefaultTestHost()
+	assert.NoError(t, err)
+
+	sourceDir := filepath.Join(source.GetMachinesDir(), h.Name)
+	driver := none.NewDriver(h.Name, source.Path)
+	driver.SSHKeyPath = filepath.Join(sourceDir, "id_rsa")
+	h.Driver = driver
+	h.HostOptions.AuthOptions.StorePath = sourceDir
+	h.HostOptions.AuthOptions.CaCertPath = filepath.Join(source.Path, "certs", "ca.pem")
+	h.HostOptions.AuthOptions.ServerCertPath = filepath.Join(sourceDir, "server.pem")
+
+	assert.NoError(t, source.Save(h))
+	for _, file := range []string{"id_rsa", "ca.pem", "server.pem"} {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, file), []byte(file), 0600))
+	}
+
+	archive := &bytes.Buffer{}
+	assert.NoError(t, source.Export(h.Name, archive))
+
+	imported, err := target.Import(archive)
+	assert.NoError(t, err)
+	assert.Equal(t, h.Name, imported.Name)
+
+	targetDir := filepath.Join(target.GetMachinesDir(), h.Name)
+	key, err := ioutil.ReadFile(filepath.Join(targetDir, "id_rsa"))
+	assert.NoError(t, err)
+	assert.Equal(t, "id_rsa", string(key))
+
+	authOptions := imported.HostOptions.AuthOptions
+	assert.Equal(t, targetDir, authOptions.StorePath)
+	assert.Equal(t, filepath.Join(targetDir, "ca.pem"), authOptions.CaCertPath)
+	assert.Equal(t, filepath.Join(targetDir, "server.pem"), authOptions.ServerCertPath)
+
+	importedDriver := none.NewDriver(h.Name, "")
+	assert.NoError(t, json.Unmarshal(imported.Driver.(*host.RawDataDriver).Data, importedDriver))
+	assert.Equal(t, filepath.Join(targetDir, "id_rsa"), importedDriver.SSHKeyPath)
+	assert.Equal(t, target.Path, importedDriver.StorePath)
+
+	_, err = target.Import(bytes.NewReader(archive.Bytes()))
+	assert.Error(t, err)
+}
+
+func TestImportExisting(t *testing.T) {
+	store := getTestStore()
+	defer os.RemoveAll(store.Path)
+
+	h, err := hosttest.GetDefaultTestHost()
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(h))
+
+	archive := &bytes.Buffer{}
+	assert.NoError(t, store.Export(h.Name, archive))
+
+	_, err = store.Import(archive)
+
+	assert.EqualError(t, err, `Machine "test-host" already exists`)
+}
+
+func TestImportRejectsEscapingPaths(t *testing.T) {
+	store := getTestStore()
+	defer os.RemoveAll(store.Path)
+
+	archive := &bytes.Buffer{}
+	gz := gzip.NewWriter(archive)
+	tw := tar.NewWriter(gz)
+	assert.NoError(t, writeArchiveFile(tw, "test-host/../../evil", []byte("evil"), 0600))
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gz.Close())
+
+	_, err := store.Import(archive)
+
+	assert.Error(t, err)
+	_, statErr := os.Stat(filepath.Join(store.Path, "evil"))
+	assert.True(t, os.IsNotExist(statErr))
+}
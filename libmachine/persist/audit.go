@@ -0,0 +1,150 @@
+package persist
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const auditFileName = "audit.log"
+
+// Operations recorded in the audit log.
+const (
+	AuditCreate       = "create"
+	AuditStart        = "start"
+	AuditStop         = "stop"
+	AuditRestart      = "restart"
+	AuditKill         = "kill"
+	AuditRemove       = "remove"
+	AuditRestore      = "restore"
+	AuditUpgrade      = "upgrade"
+	AuditProvision    = "provision"
+	AuditConfigChange = "config-change"
+	AuditCertRotate   = "cert-rotate"
+	AuditSSHKeyRotate = "ssh-key-rotate"
+	AuditDeregister   = "deregister"
+	AuditRegister     = "register"
+	AuditAdopt        = "adopt"
+)
+
+// auditLock serializes the appends of the operations run concurrently on
+// several machines.
+var auditLock sync.Mutex
+
+// AuditEntry is an operation on a machine recorded in the audit log.
+type AuditEntry struct {
+	Time      time.Time
+	User      string
+	Hostname  string
+	Machine   string
+	Operation string
+	// Details describes the operation, e.g. the changed setting
+	Details string `json:",omitempty"`
+	// Error is the error the operation failed with, if any
+	Error string `json:",omitempty"`
+}
+
+// AuditFilter selects entries of the audit log. Empty fields match all the
+// entries.
+type AuditFilter struct {
+	Machine   string
+	Operation string
+	User      string
+	Since     time.Time
+	Until     time.Time
+}
+
+// Match reports whether the entry is selected by the filter.
+func (f AuditFilter) Match(entry AuditEntry) bool {
+	switch {
+	case f.Machine != "" && entry.Machine != f.Machine:
+		return false
+	case f.Operation != "" && entry.Operation != f.Operation:
+		return false
+	case f.User != "" && entry.User != f.User:
+		return false
+	case !f.Since.IsZero() && entry.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && entry.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+func (s Filestore) auditPath() string {
+	return filepath.Join(s.Path, auditFileName)
+}
+
+// RecordAudit appends an entry to the audit log of the store. The time and
+// who ran the operation are filled in when empty.
+func (s Filestore) RecordAudit(entry AuditEntry) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	owner := currentOwner()
+	if entry.Time.IsZero() {
+		entry.Time = owner.Created
+	}
+	if entry.User == "" {
+		entry.User = owner.User
+		entry.Hostname = owner.Hostname
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	auditLock.Lock()
+	defer auditLock.Unlock()
+
+	if err := os.MkdirAll(s.Path, 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.auditPath(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// QueryAudit returns the entries of the audit log selected by the filter,
+// oldest first. Lines which are not valid entries, e.g. truncated by a crash,
+// are skipped.
+func (s Filestore) QueryAudit(filter AuditFilter) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+
+	f, err := os.Open(s.auditPath())
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, scanner.Err()
+}
@@ -0,0 +1,52 @@
+package persist
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreAudit(t *testing.T) {
+	store := getTestStore()
+	defer os.RemoveAll(store.Path)
+
+	yesterday := time.Now().Add(-24 * time.Hour).UTC()
+
+	assert.NoError(t, store.RecordAudit(AuditEntry{Time: yesterday, User: "alice", Machine: "dev", Operation: AuditCreate}))
+	assert.NoError(t, store.RecordAudit(AuditEntry{Machine: "dev", Operation: AuditStop, Error: "timeout"}))
+	assert.NoError(t, store.RecordAudit(AuditEntry{Machine: "ci", Operation: AuditCertRotate}))
+
+	entries, err := store.QueryAudit(AuditFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "alice", entries[0].User)
+	assert.True(t, entries[0].Time.Equal(yesterday))
+	assert.NotEmpty(t, entries[1].User)
+	assert.False(t, entries[1].Time.IsZero())
+	assert.Equal(t, "timeout", entries[1].Error)
+
+	entries, err = store.QueryAudit(AuditFilter{Machine: "dev"})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = store.QueryAudit(AuditFilter{Operation: AuditCertRotate})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "ci", entries[0].Machine)
+
+	entries, err = store.QueryAudit(AuditFilter{Since: time.Now().Add(-time.Hour)})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStoreAuditEmpty(t *testing.T) {
+	store := getTestStore()
+	defer os.RemoveAll(store.Path)
+
+	entries, err := store.QueryAudit(AuditFilter{})
+
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -7,15 +7,54 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/docker/machine/libmachine/credentials"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/secrets"
+)
+
+// keyringWarning makes the warning about the keyring being unavailable shown
+// once, rather than on every save.
+var keyringWarning sync.Once
+
+const (
+	configFileName = "config.json"
+
+	// DefaultConfigBackups is the number of previous versions of config.json
+	// kept by the stores created with NewFilestore.
+	DefaultConfigBackups = 3
 )
 
 type Filestore struct {
 	Path             string
 	CaCertPath       string
 	CaPrivateKeyPath string
+	// SecretBox, when set, is used to encrypt the sensitive driver fields
+	// written to config.json.
+	SecretBox *secrets.Box
+	// Keyring, when set and SecretBox is not, is where the sensitive driver
+	// fields are stored, config.json only holding references to them. It is
+	// only meant for stores not shared with other users, who cannot read the
+	// keyring.
+	Keyring credentials.Keyring
+	// ConfigBackups is the number of previous versions of config.json kept
+	// as config.json.1, config.json.2... the first being the most recent.
+	ConfigBackups int
+	// ReadOnly, when set, makes the store refuse any change, e.g. to let
+	// anyone look at the machines of a store shared by a team.
+	ReadOnly bool
+	// TrashRetention is how long Remove keeps the removed machines in the
+	// trash, from which Restore puts them back, before deleting them for
+	// good. Zero deletes them right away.
+	TrashRetention time.Duration
+
+	revisions *revisions
 }
 
 func NewFilestore(path, caCertPath, caPrivateKeyPath string) *Filestore {
@@ -23,6 +62,9 @@ func NewFilestore(path, caCertPath, caPrivateKeyPath string) *Filestore {
 		Path:             path,
 		CaCertPath:       caCertPath,
 		CaPrivateKeyPath: caPrivateKeyPath,
+		ConfigBackups:    DefaultConfigBackups,
+		TrashRetention:   DefaultTrashRetention,
+		revisions:        newRevisions(),
 	}
 }
 
@@ -30,18 +72,30 @@ func (s Filestore) GetMachinesDir() string {
 	return filepath.Join(s.Path, "machines")
 }
 
-func (s Filestore) saveToFile(data []byte, file string) error {
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		return ioutil.WriteFile(file, data, 0600)
-	}
+// StoreID is a short hash of the absolute path of the store, telling its
+// machines apart from the ones of the same name in other stores wherever
+// they share a namespace, e.g. a known_hosts file.
+func (s Filestore) StoreID() string {
+	return mcnutils.PathID(s.Path)
+}
 
-	tmpfi, err := ioutil.TempFile(filepath.Dir(file), "config.json.tmp")
+// saveToFile writes the file atomically: the data is written to a temporary
+// file which replaces the file once it is complete, so that an interrupted
+// write never leaves a truncated file.
+func (s Filestore) saveToFile(data []byte, file string) error {
+	tmpfi, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
 	if err != nil {
 		return err
 	}
 	defer os.Remove(tmpfi.Name())
 
-	if err = ioutil.WriteFile(tmpfi.Name(), data, 0600); err != nil {
+	if _, err = tmpfi.Write(data); err != nil {
+		tmpfi.Close()
+		return err
+	}
+
+	if err = tmpfi.Sync(); err != nil {
+		tmpfi.Close()
 		return err
 	}
 
@@ -49,20 +103,70 @@ func (s Filestore) saveToFile(data []byte, file string) error {
 		return err
 	}
 
-	if err = os.Remove(file); err != nil {
+	if err = os.Chmod(tmpfi.Name(), 0600); err != nil {
 		return err
 	}
 
-	err = os.Rename(tmpfi.Name(), file)
-	return err
+	return os.Rename(tmpfi.Name(), file)
+}
+
+func configBackupPath(file string, index int) string {
+	return fmt.Sprintf("%s.%d", file, index)
+}
+
+// backupConfig rotates the backups of the configuration file, making the
+// current configuration the most recent backup. A configuration which is not
+// valid JSON, e.g. truncated, is not worth keeping and is left out.
+func (s Filestore) backupConfig(file string) error {
+	if s.ConfigBackups <= 0 {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !isValidJSON(data) {
+		return nil
+	}
+
+	for i := s.ConfigBackups - 1; i > 0; i-- {
+		err := os.Rename(configBackupPath(file, i), configBackupPath(file, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return s.saveToFile(data, configBackupPath(file, 1))
+}
+
+func isValidJSON(data []byte) bool {
+	var v interface{}
+	return json.Unmarshal(data, &v) == nil
 }
 
 func (s Filestore) Save(host *host.Host) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
 	data, err := json.MarshalIndent(host, "", "    ")
 	if err != nil {
 		return err
 	}
 
+	if s.SecretBox != nil {
+		if data, err = s.SecretBox.SealHost(data); err != nil {
+			return fmt.Errorf("Error encrypting host configuration: %s", err)
+		}
+	} else if s.Keyring != nil {
+		data = s.storeCredentials(host, data)
+	}
+
 	hostPath := filepath.Join(s.GetMachinesDir(), host.Name)
 
 	// Ensure that the directory we want to save to exists.
@@ -70,12 +174,163 @@ func (s Filestore) Save(host *host.Host) error {
 		return err
 	}
 
-	return s.saveToFile(data, filepath.Join(hostPath, "config.json"))
+	if err := s.recordOwner(hostPath); err != nil {
+		return fmt.Errorf("Error recording the owner of %q: %s", host.Name, err)
+	}
+
+	configPath := filepath.Join(hostPath, configFileName)
+	if err := s.revisions.check(host.Name, configPath); err != nil {
+		return err
+	}
+
+	if err := s.backupConfig(configPath); err != nil {
+		return fmt.Errorf("Error backing up the configuration of %q: %s", host.Name, err)
+	}
+
+	if err := s.saveToFile(data, configPath); err != nil {
+		return err
+	}
+
+	s.revisions.record(host.Name, data)
+
+	return nil
+}
+
+// RestoreConfigBackup replaces the configuration of the machine with its most
+// recent valid backup, for when config.json was damaged, e.g. truncated by an
+// interrupted write, and returns the host loaded from it.
+func (s Filestore) RestoreConfigBackup(name string) (*host.Host, error) {
+	if s.ReadOnly {
+		return nil, ErrReadOnlyStore
+	}
+
+	configPath := filepath.Join(s.GetMachinesDir(), name, configFileName)
+
+	for i := 1; ; i++ {
+		data, err := ioutil.ReadFile(configBackupPath(configPath, i))
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !isValidJSON(data) {
+			continue
+		}
+
+		if err := s.saveToFile(data, configPath); err != nil {
+			return nil, err
+		}
+
+		return s.Load(name)
+	}
+
+	return nil, fmt.Errorf("No valid backup of the configuration of %q found", name)
 }
 
 func (s Filestore) Remove(name string) error {
-	hostPath := filepath.Join(s.GetMachinesDir(), name)
-	return os.RemoveAll(hostPath)
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	if s.TrashRetention > 0 {
+		if err := s.moveToTrash(name); err != nil {
+			return err
+		}
+
+		// Best effort, what is left is purged by the next removals
+		s.PurgeTrash(time.Now().Add(-s.TrashRetention))
+	} else {
+		hostPath := filepath.Join(s.GetMachinesDir(), name)
+		s.forgetCredentials(filepath.Join(hostPath, configFileName), nil)
+		if err := os.RemoveAll(hostPath); err != nil {
+			return err
+		}
+	}
+
+	s.revisions.forget(name)
+
+	return nil
+}
+
+// keyring returns the keyring the credentials referenced by config.json are
+// read from, the one of the OS unless another one is set.
+func (s Filestore) keyring() credentials.Keyring {
+	if s.Keyring != nil {
+		return s.Keyring
+	}
+
+	return credentials.OSKeyring{}
+}
+
+// credentialAccountPrefix names the keyring accounts of the credentials of a
+// machine after its directory, for the machines of different stores not to
+// share them.
+func (s Filestore) credentialAccountPrefix(name string) string {
+	return filepath.Join(s.GetMachinesDir(), name)
+}
+
+// storeCredentials moves the sensitive driver fields of the serialized host
+// to the keyring. They are kept in the data, with a warning, when the keyring
+// is not available, e.g. on a headless Linux without a Secret Service.
+func (s Filestore) storeCredentials(h *host.Host, data []byte) []byte {
+	extraFields, err := drivers.GetSensitiveFields(h.Driver)
+	if err != nil {
+		extraFields = nil
+	}
+
+	stored, err := secrets.StoreHostCredentials(s.Keyring, s.credentialAccountPrefix(h.Name), data, extraFields)
+	if err != nil {
+		keyringWarning.Do(func() {
+			log.Warnf("Unable to store the credentials of %q in the OS keychain, they are saved in plain text: %s", h.Name, err)
+			log.Warn("Set MACHINE_CONFIG_PASSPHRASE to have them encrypted instead.")
+		})
+		return data
+	}
+
+	return stored
+}
+
+// credentialAccounts returns the keyring accounts the configuration of a
+// machine references.
+func credentialAccounts(configPath string) []string {
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+
+	accounts, err := secrets.HostCredentialAccounts(data)
+	if err != nil {
+		return nil
+	}
+
+	return accounts
+}
+
+// forgetCredentials removes from the keyring, on a best effort basis, the
+// credentials referenced by a configuration of a machine which is deleted for
+// good, but the ones to keep.
+func (s Filestore) forgetCredentials(configPath string, keep []string) {
+	for _, account := range credentialAccounts(configPath) {
+		if containsString(keep, account) {
+			continue
+		}
+
+		if err := s.keyring().Delete(account); err != nil {
+			log.Debugf("Unable to remove %s from the keyring: %s", account, err)
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (s Filestore) List() ([]string, error) {
@@ -108,11 +363,26 @@ func (s Filestore) Exists(name string) (bool, error) {
 }
 
 func (s Filestore) loadConfig(h *host.Host) error {
-	data, err := ioutil.ReadFile(filepath.Join(s.GetMachinesDir(), h.Name, "config.json"))
+	rawData, err := ioutil.ReadFile(filepath.Join(s.GetMachinesDir(), h.Name, configFileName))
+	if err != nil {
+		return err
+	}
+
+	if !isValidJSON(rawData) {
+		return fmt.Errorf("The configuration of %q is damaged. You can restore its last backup using 'docker-machine restore-config %s'", h.Name, h.Name)
+	}
+
+	s.revisions.record(h.Name, rawData)
+
+	data, err := s.SecretBox.OpenHost(rawData)
 	if err != nil {
 		return err
 	}
 
+	if data, err = secrets.ResolveHostCredentials(s.keyring(), data); err != nil {
+		return fmt.Errorf("Error reading the credentials of %q: %s", h.Name, err)
+	}
+
 	// Remember the machine name so we don't have to pass it through each
 	// struct in the migration.
 	name := h.Name
@@ -127,8 +397,8 @@ func (s Filestore) loadConfig(h *host.Host) error {
 	h.Name = name
 
 	// If we end up performing a migration, we should save afterwards so we don't have to do it again on subsequent invocations.
-	if migrationPerformed {
-		if err := s.saveToFile(data, filepath.Join(s.GetMachinesDir(), h.Name, "config.json.bak")); err != nil {
+	if migrationPerformed && !s.ReadOnly {
+		if err := s.saveToFile(rawData, filepath.Join(s.GetMachinesDir(), h.Name, "config.json.bak")); err != nil {
 			return fmt.Errorf("Error attempting to save backup after migration: %s", err)
 		}
 
@@ -2,17 +2,20 @@ package persist
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/docker/machine/commands/mcndirs"
 	"github.com/docker/machine/drivers/none"
 	"github.com/docker/machine/libmachine/host"
 	"github.com/docker/machine/libmachine/hosttest"
+	"github.com/docker/machine/libmachine/secrets"
 )
 
 func cleanup() {
@@ -247,3 +250,237 @@ func TestStoreLoad(t *testing.T) {
 		t.Fatalf("GetURL is not %q, got %q", expectedURL, actualURL)
 	}
 }
+
+type secretDriver struct {
+	*none.Driver
+	AccessToken string
+}
+
+func TestStoreSaveEncryptsSecrets(t *testing.T) {
+	defer cleanup()
+
+	store := getTestStore()
+	box, err := secrets.NewBox("passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.SecretBox = box
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Driver = &secretDriver{
+		Driver:      h.Driver.(*none.Driver),
+		AccessToken: "my-token",
+	}
+
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(store.GetMachinesDir(), h.Name, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "my-token") {
+		t.Fatal("Expected the access token to be encrypted in config.json")
+	}
+
+	loadedHost, err := store.Load(h.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(loadedHost.RawDriver), "my-token") {
+		t.Fatalf("Expected the access token to be decrypted on load, got %s", loadedHost.RawDriver)
+	}
+
+	store.SecretBox = nil
+	if _, err := store.Load(h.Name); err != secrets.ErrEncrypted {
+		t.Fatalf("Expected %s, got %v", secrets.ErrEncrypted, err)
+	}
+}
+
+type fakeKeyring struct {
+	values map[string]string
+}
+
+func (k *fakeKeyring) Get(account string) (string, error) {
+	if value, ok := k.values[account]; ok {
+		return value, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (k *fakeKeyring) Set(account, value string) error {
+	k.values[account] = value
+	return nil
+}
+
+func (k *fakeKeyring) Delete(account string) error {
+	delete(k.values, account)
+	return nil
+}
+
+func TestStoreSaveKeepsSecretsInKeyring(t *testing.T) {
+	defer cleanup()
+
+	store := getTestStore()
+	keyring := &fakeKeyring{values: map[string]string{}}
+	store.Keyring = keyring
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Driver = &secretDriver{
+		Driver:      h.Driver.(*none.Driver),
+		AccessToken: "my-token",
+	}
+
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath := filepath.Join(store.GetMachinesDir(), h.Name, "config.json")
+	data, err := ioutil.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "my-token") {
+		t.Fatal("Expected the access token to be kept out of config.json")
+	}
+
+	account := filepath.Join(store.GetMachinesDir(), h.Name) + "/Driver.AccessToken"
+	if !strings.Contains(string(data), secrets.KeyringPrefix+account) {
+		t.Fatalf("Expected a reference to the access token in config.json, got %s", data)
+	}
+	if keyring.values[account] != "my-token" {
+		t.Fatalf("Expected the access token in the keyring, got %v", keyring.values)
+	}
+
+	loadedHost, err := store.Load(h.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(loadedHost.RawDriver), "my-token") {
+		t.Fatalf("Expected the access token to be read from the keyring on load, got %s", loadedHost.RawDriver)
+	}
+
+	store.TrashRetention = 0
+	if err := store.Remove(h.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keyring.values) != 0 {
+		t.Fatalf("Expected the access token to be removed from the keyring, got %v", keyring.values)
+	}
+}
+
+func TestStoreSaveKeepsBackups(t *testing.T) {
+	defer cleanup()
+
+	store := getTestStore()
+	store.ConfigBackups = 2
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := store.Save(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	configPath := filepath.Join(store.GetMachinesDir(), h.Name, "config.json")
+	for _, backup := range []string{configPath + ".1", configPath + ".2"} {
+		if _, err := os.Stat(backup); err != nil {
+			t.Fatalf("Expected backup %s to exist: %s", backup, err)
+		}
+	}
+
+	if _, err := os.Stat(configPath + ".3"); !os.IsNotExist(err) {
+		t.Fatal("Expected no more than 2 backups to be kept")
+	}
+}
+
+func TestStoreRestoreConfigBackup(t *testing.T) {
+	defer cleanup()
+
+	store := getTestStore()
+	store.ConfigBackups = DefaultConfigBackups
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a write interrupted halfway
+	configPath := filepath.Join(store.GetMachinesDir(), h.Name, "config.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"ConfigVersion": 3, "Dri`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Load(h.Name); err == nil || !strings.Contains(err.Error(), "restore-config") {
+		t.Fatalf("Expected an error suggesting to restore the configuration, got %v", err)
+	}
+
+	restored := &host.Host{Name: h.Name}
+	if err := restored.RestoreConfigBackup(store); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.DriverName != h.DriverName {
+		t.Fatalf("Expected to restore the %s driver, got %q", h.DriverName, restored.DriverName)
+	}
+
+	if restored.Name != h.Name {
+		t.Fatalf("Expected to restore %q, got %q", h.Name, restored.Name)
+	}
+
+	if _, err := store.Load(h.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	// The damaged configuration is not kept as a backup on the next save
+	if err := ioutil.WriteFile(configPath, []byte(`{`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(configPath + ".1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) == "{" {
+		t.Fatal("Expected the damaged configuration not to be backed up")
+	}
+}
+
+func TestStoreID(t *testing.T) {
+	store := Filestore{Path: "/store/a"}
+
+	if store.StoreID() != (Filestore{Path: "/store/a/"}).StoreID() {
+		t.Fatal("Expected the same ID for the same store")
+	}
+	if store.StoreID() == (Filestore{Path: "/store/b"}).StoreID() {
+		t.Fatal("Expected different IDs for different stores")
+	}
+	if len(store.StoreID()) != 8 {
+		t.Fatalf("Expected a short ID, got %q", store.StoreID())
+	}
+}
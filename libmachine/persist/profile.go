@@ -0,0 +1,117 @@
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const profileExt = ".json"
+
+// Profile is a named set of create flags, e.g. the driver, engine and swarm
+// options of the dev machines of a team, to create machines from.
+type Profile struct {
+	Name string `json:"-"`
+	// Flags are the values of the create flags by flag name: strings,
+	// ints, bools or string slices.
+	Flags map[string]interface{}
+}
+
+func (s Filestore) getProfilesDir() string {
+	return filepath.Join(s.Path, "profiles")
+}
+
+func (s Filestore) profilePath(name string) string {
+	return filepath.Join(s.getProfilesDir(), name+profileExt)
+}
+
+// SaveProfile saves a profile in the store, replacing the profile of the same
+// name if any.
+func (s Filestore) SaveProfile(profile *Profile) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	data, err := json.MarshalIndent(profile, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.getProfilesDir(), 0700); err != nil {
+		return err
+	}
+
+	return s.saveToFile(data, s.profilePath(profile.Name))
+}
+
+// LoadProfile loads a profile by name.
+func (s Filestore) LoadProfile(name string) (*Profile, error) {
+	data, err := ioutil.ReadFile(s.profilePath(name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("Profile %q does not exist", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &Profile{}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, fmt.Errorf("Error reading profile %q: %s", name, err)
+	}
+
+	profile.Name = name
+	for flagName, value := range profile.Flags {
+		profile.Flags[flagName] = normalizeFlagValue(value)
+	}
+
+	return profile, nil
+}
+
+// normalizeFlagValue converts a flag value decoded from JSON to the type the
+// flag had: JSON has no ints and decodes arrays to []interface{}.
+func normalizeFlagValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	}
+	return value
+}
+
+// ListProfiles returns the names of the profiles in the store.
+func (s Filestore) ListProfiles() ([]string, error) {
+	files, err := ioutil.ReadDir(s.getProfilesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), profileExt) {
+			names = append(names, strings.TrimSuffix(file.Name(), profileExt))
+		}
+	}
+
+	return names, nil
+}
+
+// RemoveProfile removes a profile from the store.
+func (s Filestore) RemoveProfile(name string) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	err := os.Remove(s.profilePath(name))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("Profile %q does not exist", name)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package persist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStoreProfiles(t *testing.T) {
+	defer cleanup()
+
+	store := getTestStore()
+
+	profile := &Profile{
+		Name: "dev",
+		Flags: map[string]interface{}{
+			"driver":            "virtualbox",
+			"virtualbox-memory": 4096,
+			"engine-label":      []string{"env=dev"},
+			"swarm":             true,
+		},
+	}
+
+	if err := store.SaveProfile(profile); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := store.ListProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"dev"}) {
+		t.Fatalf("Expected profile dev to be listed, got %v", names)
+	}
+
+	loaded, err := store.LoadProfile("dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, profile) {
+		t.Fatalf("Expected profile %v, got %v", profile, loaded)
+	}
+
+	if err := store.RemoveProfile("dev"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.LoadProfile("dev"); err == nil {
+		t.Fatal("Expected an error loading a removed profile")
+	}
+}
@@ -0,0 +1,155 @@
+package persist
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+const ownerFileName = "owner.json"
+
+var (
+	// ErrReadOnlyStore is returned when changing a store opened read-only.
+	ErrReadOnlyStore = errors.New("The store is read-only")
+)
+
+// ErrConfigConflict is returned when saving a machine whose configuration was
+// changed by someone else since it was loaded, e.g. by a teammate sharing the
+// store.
+type ErrConfigConflict struct {
+	Name string
+}
+
+func (e ErrConfigConflict) Error() string {
+	return fmt.Sprintf("The configuration of %q was changed by someone else since it was loaded, please retry", e.Name)
+}
+
+// Owner is who created a machine, for stores shared by a team.
+type Owner struct {
+	User     string
+	Hostname string
+	Created  time.Time
+}
+
+func (o Owner) String() string {
+	if o.Hostname == "" {
+		return o.User
+	}
+	return fmt.Sprintf("%s@%s", o.User, o.Hostname)
+}
+
+// currentOwner returns the owner of the machines created now.
+func currentOwner() Owner {
+	hostname, _ := os.Hostname()
+
+	return Owner{
+		User:     mcnutils.GetUsername(),
+		Hostname: hostname,
+		Created:  time.Now().UTC(),
+	}
+}
+
+// revisions remembers the configurations as they were loaded, so that a
+// store shared by several users detects when a machine is saved over changes
+// made by another user.
+type revisions struct {
+	sync.Mutex
+	sums map[string][sha256.Size]byte
+}
+
+func newRevisions() *revisions {
+	return &revisions{
+		sums: map[string][sha256.Size]byte{},
+	}
+}
+
+func (r *revisions) record(name string, data []byte) {
+	if r == nil {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.sums[name] = sha256.Sum256(data)
+}
+
+func (r *revisions) forget(name string) {
+	if r == nil {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	delete(r.sums, name)
+}
+
+// check tells whether the configuration is still as it was loaded. The
+// configurations never loaded, e.g. of new machines, are not checked.
+func (r *revisions) check(name string, file string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.Lock()
+	sum, loaded := r.sums[name]
+	r.Unlock()
+	if !loaded {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if sha256.Sum256(data) != sum {
+		return ErrConfigConflict{Name: name}
+	}
+
+	return nil
+}
+
+// Owner returns who created the machine, or nil if the store does not know,
+// e.g. for machines created before owners were recorded.
+func (s Filestore) Owner(name string) (*Owner, error) {
+	data, err := ioutil.ReadFile(filepath.Join(s.GetMachinesDir(), name, ownerFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	owner := &Owner{}
+	if err := json.Unmarshal(data, owner); err != nil {
+		return nil, fmt.Errorf("Error reading the owner of %q: %s", name, err)
+	}
+
+	return owner, nil
+}
+
+// recordOwner records the current user as owner of a machine which has none.
+func (s Filestore) recordOwner(hostPath string) error {
+	ownerPath := filepath.Join(hostPath, ownerFileName)
+	if _, err := os.Stat(ownerPath); !os.IsNotExist(err) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(currentOwner(), "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return s.saveToFile(data, ownerPath)
+}
@@ -0,0 +1,109 @@
+package persist
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/hosttest"
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+func getSharedTestStores() (*Filestore, *Filestore) {
+	store := getTestStore()
+
+	return NewFilestore(store.Path, store.CaCertPath, store.CaPrivateKeyPath),
+		NewFilestore(store.Path, store.CaCertPath, store.CaPrivateKeyPath)
+}
+
+func TestStoreSaveRecordsOwner(t *testing.T) {
+	defer cleanup()
+
+	store, _ := getSharedTestStores()
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	owner, err := store.Owner(h.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if owner == nil || owner.User != mcnutils.GetUsername() {
+		t.Fatalf("Expected the current user to own the machine, got %v", owner)
+	}
+}
+
+func TestStoreSaveDetectsConflicts(t *testing.T) {
+	defer cleanup()
+
+	mine, theirs := getSharedTestStores()
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mine.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	myHost, err := mine.Load(h.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	theirHost, err := theirs.Load(h.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	theirHost.HostOptions.EngineOptions.Labels = []string{"owner=them"}
+	if err := theirs.Save(theirHost); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mine.Save(myHost); err != (ErrConfigConflict{Name: h.Name}) {
+		t.Fatalf("Expected a conflict, got %v", err)
+	}
+
+	// Saving again once the changes are loaded is fine
+	if myHost, err = mine.Load(h.Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := mine.Save(myHost); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadOnlyStore(t *testing.T) {
+	defer cleanup()
+
+	store, readOnlyStore := getSharedTestStores()
+	readOnlyStore.ReadOnly = true
+
+	h, err := hosttest.GetDefaultTestHost()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := readOnlyStore.Save(h); err != ErrReadOnlyStore {
+		t.Fatalf("Expected %s, got %v", ErrReadOnlyStore, err)
+	}
+
+	if err := store.Save(h); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := readOnlyStore.Load(h.Name); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := readOnlyStore.Remove(h.Name); err != ErrReadOnlyStore {
+		t.Fatalf("Expected %s, got %v", ErrReadOnlyStore, err)
+	}
+}
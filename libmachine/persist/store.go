@@ -1,6 +1,9 @@
 package persist
 
 import (
+	"io"
+	"time"
+
 	"github.com/docker/machine/libmachine/host"
 )
 
@@ -21,6 +24,74 @@ type Store interface {
 	Save(host *host.Host) error
 }
 
+// OwnerReader is implemented by the stores recording who created the
+// machines, for stores shared by a team.
+type OwnerReader interface {
+	// Owner returns who created the machine, or nil if unknown
+	Owner(name string) (*Owner, error)
+}
+
+// ProfileStore is implemented by the stores keeping profiles to create
+// machines from.
+type ProfileStore interface {
+	// SaveProfile saves a profile, replacing the profile of the same name
+	SaveProfile(profile *Profile) error
+
+	// LoadProfile loads a profile by name
+	LoadProfile(name string) (*Profile, error)
+
+	// ListProfiles returns the names of the profiles
+	ListProfiles() ([]string, error)
+
+	// RemoveProfile removes a profile
+	RemoveProfile(name string) error
+}
+
+// ActiveStore is implemented by the stores keeping the machine explicitly
+// selected as the active one.
+type ActiveStore interface {
+	// GetActive returns the name of the selected machine, or "" if none
+	GetActive() (string, error)
+
+	// SetActive selects a machine, or clears the selection if name is ""
+	SetActive(name string) error
+}
+
+// AuditLog is implemented by the stores recording the operations on the
+// machines, for teams sharing machines.
+type AuditLog interface {
+	// RecordAudit appends an entry to the audit log
+	RecordAudit(entry AuditEntry) error
+
+	// QueryAudit returns the entries selected by the filter, oldest first
+	QueryAudit(filter AuditFilter) ([]AuditEntry, error)
+}
+
+// Archiver is implemented by the stores able to write a machine to an
+// archive and to add the machine of an archive, e.g. to hand a machine off
+// to someone else.
+type Archiver interface {
+	// Export writes the machine to w as an archive
+	Export(name string, w io.Writer) error
+
+	// Import adds the machine of an archive written by Export
+	Import(r io.Reader) (*host.Host, error)
+}
+
+// Trash is implemented by the stores keeping the removed machines for a
+// while, for an accidental removal not to lose the only copy of the keys of
+// an instance which still exists.
+type Trash interface {
+	// ListTrash returns the removed machines kept, most recent first
+	ListTrash() ([]TrashEntry, error)
+
+	// Restore puts the most recently removed machine of the name back
+	Restore(name string) (*host.Host, error)
+
+	// PurgeTrash deletes for good the machines removed before the time
+	PurgeTrash(before time.Time) error
+}
+
 func LoadHosts(s Store, hostNames []string) ([]*host.Host, map[string]error) {
 	loadedHosts := []*host.Host{}
 	errors := map[string]error{}
@@ -0,0 +1,175 @@
+package persist
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/mcnerror"
+)
+
+// DefaultTrashRetention is how long the stores created with NewFilestore
+// keep the removed machines.
+const DefaultTrashRetention = 7 * 24 * time.Hour
+
+// TrashEntry is a removed machine kept in the trash.
+type TrashEntry struct {
+	Name string
+
+	// Removed is when the machine was removed, Expires when it is deleted
+	// for good.
+	Removed time.Time
+	Expires time.Time
+}
+
+// byRemovalTime sorts the entries of the trash, the most recently removed
+// first.
+type byRemovalTime []TrashEntry
+
+func (e byRemovalTime) Len() int           { return len(e) }
+func (e byRemovalTime) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byRemovalTime) Less(i, j int) bool { return e[i].Removed.After(e[j].Removed) }
+
+func (s Filestore) getTrashDir() string {
+	return filepath.Join(s.Path, "trash")
+}
+
+// trashPath is where a machine removed at the given time is kept, named
+// after the time for the machines removed several times to be told apart.
+func (s Filestore) trashPath(name string, removed time.Time) string {
+	return filepath.Join(s.getTrashDir(), name, strconv.FormatInt(removed.UnixNano(), 10))
+}
+
+// moveToTrash moves the directory of the machine, with its certificates and
+// SSH keys, to the trash.
+func (s Filestore) moveToTrash(name string) error {
+	hostPath := filepath.Join(s.GetMachinesDir(), name)
+	if _, err := os.Stat(hostPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	trashPath := s.trashPath(name, time.Now())
+	if err := os.MkdirAll(filepath.Dir(trashPath), 0700); err != nil {
+		return err
+	}
+
+	return os.Rename(hostPath, trashPath)
+}
+
+// ListTrash returns the removed machines kept in the trash, the most
+// recently removed first.
+func (s Filestore) ListTrash() ([]TrashEntry, error) {
+	names, err := ioutil.ReadDir(s.getTrashDir())
+	if os.IsNotExist(err) {
+		return []TrashEntry{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []TrashEntry{}
+	for _, name := range names {
+		if !name.IsDir() {
+			continue
+		}
+
+		removals, err := ioutil.ReadDir(filepath.Join(s.getTrashDir(), name.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, removal := range removals {
+			nanos, err := strconv.ParseInt(removal.Name(), 10, 64)
+			if err != nil || !removal.IsDir() {
+				continue
+			}
+
+			removed := time.Unix(0, nanos)
+			entries = append(entries, TrashEntry{
+				Name:    name.Name(),
+				Removed: removed,
+				Expires: removed.Add(s.TrashRetention),
+			})
+		}
+	}
+
+	sort.Sort(byRemovalTime(entries))
+
+	return entries, nil
+}
+
+// Restore puts the machine of the given name most recently removed back in
+// the store. It fails when a machine of that name exists.
+func (s Filestore) Restore(name string) (*host.Host, error) {
+	if s.ReadOnly {
+		return nil, ErrReadOnlyStore
+	}
+
+	exists, err := s.Exists(name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, mcnerror.ErrHostAlreadyExists{Name: name}
+	}
+
+	entries, err := s.ListTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Name != name {
+			continue
+		}
+
+		if err := os.MkdirAll(s.GetMachinesDir(), 0700); err != nil {
+			return nil, err
+		}
+		if err := os.Rename(s.trashPath(name, entry.Removed), filepath.Join(s.GetMachinesDir(), name)); err != nil {
+			return nil, err
+		}
+
+		// Only removes the directory of the name once it is empty
+		os.Remove(filepath.Join(s.getTrashDir(), name))
+
+		return s.Load(name)
+	}
+
+	return nil, fmt.Errorf("%q is not in the trash", name)
+}
+
+// PurgeTrash deletes for good the machines removed before the given time.
+func (s Filestore) PurgeTrash(before time.Time) error {
+	if s.ReadOnly {
+		return ErrReadOnlyStore
+	}
+
+	entries, err := s.ListTrash()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.Removed.Before(before) {
+			continue
+		}
+
+		// A machine created again with the same name shares the accounts
+		// of the removed one.
+		inUse := credentialAccounts(filepath.Join(s.GetMachinesDir(), entry.Name, configFileName))
+		s.forgetCredentials(filepath.Join(s.trashPath(entry.Name, entry.Removed), configFileName), inUse)
+
+		if err := os.RemoveAll(s.trashPath(entry.Name, entry.Removed)); err != nil {
+			return err
+		}
+		os.Remove(filepath.Join(s.getTrashDir(), entry.Name))
+	}
+
+	return nil
+}
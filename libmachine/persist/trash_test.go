@@ -0,0 +1,134 @@
+package persist
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/hosttest"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestTrashStore(t *testing.T) Filestore {
+	store := getTestStore()
+	store.TrashRetention = time.Hour
+
+	h, err := hosttest.GetDefaultTestHost()
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(h))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(store.GetMachinesDir(), h.Name, "id_rsa"), []byte("key"), 0600))
+
+	return store
+}
+
+func TestRemoveMovesToTrash(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+
+	assert.NoError(t, store.Remove(hosttest.DefaultHostName))
+
+	exists, err := store.Exists(hosttest.DefaultHostName)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	entries, err := store.ListTrash()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, hosttest.DefaultHostName, entries[0].Name)
+	assert.Equal(t, time.Hour, entries[0].Expires.Sub(entries[0].Removed))
+}
+
+func TestRestore(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+
+	assert.NoError(t, store.Remove(hosttest.DefaultHostName))
+
+	h, err := store.Restore(hosttest.DefaultHostName)
+	assert.NoError(t, err)
+	assert.Equal(t, hosttest.DefaultHostName, h.Name)
+
+	key, err := ioutil.ReadFile(filepath.Join(store.GetMachinesDir(), h.Name, "id_rsa"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key", string(key))
+
+	entries, err := store.ListTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRestoreExisting(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+
+	_, err := store.Restore(hosttest.DefaultHostName)
+
+	assert.Equal(t, mcnerror.ErrHostAlreadyExists{Name: hosttest.DefaultHostName}, err)
+}
+
+func TestRestoreNotInTrash(t *testing.T) {
+	store := getTestStore()
+	defer os.RemoveAll(store.Path)
+
+	_, err := store.Restore("unknown")
+
+	assert.EqualError(t, err, `"unknown" is not in the trash`)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+
+	assert.NoError(t, store.Remove(hosttest.DefaultHostName))
+
+	assert.NoError(t, store.PurgeTrash(time.Now().Add(-time.Minute)))
+	entries, err := store.ListTrash()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	assert.NoError(t, store.PurgeTrash(time.Now()))
+	entries, err = store.ListTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPurgeTrashKeepsCredentialsInUse(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+	keyring := &fakeKeyring{values: map[string]string{}}
+	store.Keyring = keyring
+
+	h, err := hosttest.GetDefaultTestHost()
+	assert.NoError(t, err)
+	h.Driver = &secretDriver{
+		Driver:      h.Driver.(*none.Driver),
+		AccessToken: "my-token",
+	}
+	assert.NoError(t, store.Save(h))
+	assert.NoError(t, store.Remove(h.Name))
+
+	// The same name is used again before the removed machine is purged.
+	assert.NoError(t, store.Save(h))
+	assert.NoError(t, store.PurgeTrash(time.Now()))
+	assert.Len(t, keyring.values, 1)
+
+	assert.NoError(t, store.Remove(h.Name))
+	assert.NoError(t, store.PurgeTrash(time.Now()))
+	assert.Empty(t, keyring.values)
+}
+
+func TestRemoveWithoutTrash(t *testing.T) {
+	store := getTestTrashStore(t)
+	defer os.RemoveAll(store.Path)
+	store.TrashRetention = 0
+
+	assert.NoError(t, store.Remove(hosttest.DefaultHostName))
+
+	entries, err := store.ListTrash()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,181 @@
+package provision
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+func init() {
+	Register("Alpine", &RegisteredProvisioner{
+		New: NewAlpineProvisioner,
+	})
+}
+
+// NewAlpineProvisioner creates a provisioner of Alpine Linux machines. The
+// OpenRC script of the docker package reads the daemon options from
+// /etc/conf.d/docker.
+func NewAlpineProvisioner(d drivers.Driver) Provisioner {
+	return &AlpineProvisioner{
+		GenericProvisioner{
+			SSHCommander:      GenericSSHCommander{Driver: d},
+			DockerOptionsDir:  "/etc/docker",
+			DaemonOptionsFile: "/etc/conf.d/docker",
+			OsReleaseID:       "alpine",
+			Packages: []string{
+				"curl",
+			},
+			Driver: d,
+		},
+	}
+}
+
+type AlpineProvisioner struct {
+	GenericProvisioner
+}
+
+func (provisioner *AlpineProvisioner) String() string {
+	return "alpine"
+}
+
+// Service manages the OpenRC services. Services are enabled in the default
+// runlevel.
+func (provisioner *AlpineProvisioner) Service(name string, action serviceaction.ServiceAction) error {
+	var command string
+
+	switch action {
+	case serviceaction.Enable:
+		command = fmt.Sprintf("sudo rc-update add %s default", name)
+	case serviceaction.Disable:
+		command = fmt.Sprintf("sudo rc-update del %s default", name)
+	case serviceaction.DaemonReload:
+		return nil
+	default:
+		command = fmt.Sprintf("sudo rc-service %s %s", name, action.String())
+	}
+
+	if _, err := provisioner.SSHCommand(command); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PackageManager returns the apk package manager of the machine.
+func (provisioner *AlpineProvisioner) PackageManager() PackageManager {
+	return &apkPackageManager{provisioner}
+}
+
+func (provisioner *AlpineProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
+}
+
+func (provisioner *AlpineProvisioner) dockerDaemonResponding() bool {
+	log.Debug("checking docker daemon")
+
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
+		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
+		log.Debugf("'docker version' output:\n%s", out)
+		return false
+	}
+
+	// The daemon is up if the command worked.  Carry on.
+	return true
+}
+
+// installDocker installs the docker package of the community repository,
+// which the install script does not support. The version is matched
+// loosely, e.g. 17.09 picks the latest 17.09 package.
+func (provisioner *AlpineProvisioner) installDocker(engineOptions engine.Options) error {
+	if _, err := provisioner.SSHCommand(`sudo sed -i -e 's|^#\(.*/community\)$|\1|' /etc/apk/repositories`); err != nil {
+		return err
+	}
+
+	name := "docker"
+	if engineOptions.InstallVersion != "" {
+		name = fmt.Sprintf("'docker~=%s'", engineOptions.InstallVersion)
+	}
+
+	return provisioner.Package(name, pkgaction.Install)
+}
+
+func (provisioner *AlpineProvisioner) Provision(swarmOptions swarm.Options, authOptions auth.Options, engineOptions engine.Options) error {
+	provisioner.SwarmOptions = swarmOptions
+	provisioner.AuthOptions = authOptions
+	provisioner.EngineOptions = engineOptions
+	swarmOptions.Env = engineOptions.Env
+
+	storageDriver, err := decideStorageDriver(provisioner, "overlay2", engineOptions.StorageDriver)
+	if err != nil {
+		return err
+	}
+	provisioner.EngineOptions.StorageDriver = storageDriver
+
+	// Minimal images may only come with doas, and sudo is run by the
+	// other steps
+	log.Debug("Installing sudo")
+	if _, err := provisioner.SSHCommand("if ! type sudo; then doas apk add --update sudo || apk add --update sudo; fi"); err != nil {
+		return err
+	}
+
+	log.Debug("Setting hostname")
+	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
+		return err
+	}
+
+	log.Debug("Installing base packages")
+	for _, pkg := range provisioner.Packages {
+		if err := provisioner.Package(pkg, pkgaction.Install); err != nil {
+			return err
+		}
+	}
+
+	log.Info("Installing Docker...")
+	if err := provisioner.installDocker(engineOptions); err != nil {
+		return err
+	}
+
+	log.Debug("Starting the docker service")
+	if err := provisioner.Service("docker", serviceaction.Start); err != nil {
+		return err
+	}
+
+	log.Debug("Waiting for docker daemon")
+	if err := mcnutils.WaitFor(provisioner.dockerDaemonResponding); err != nil {
+		return err
+	}
+
+	if err := makeDockerOptionsDir(provisioner); err != nil {
+		return err
+	}
+
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
+	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
+
+	log.Debug("Configuring auth")
+	if err := ConfigureAuth(provisioner); err != nil {
+		return err
+	}
+
+	log.Debug("Configuring swarm")
+	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+		return err
+	}
+
+	log.Debug("Enabling docker in OpenRC")
+	return provisioner.Service("docker", serviceaction.Enable)
+}
@@ -0,0 +1,51 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlpineCompatibleWithHost(t *testing.T) {
+	p := NewAlpineProvisioner(nil)
+
+	p.SetOsReleaseInfo(&OsRelease{ID: "alpine", VersionID: "3.6.2"})
+	assert.True(t, p.CompatibleWithHost())
+
+	p.SetOsReleaseInfo(&OsRelease{ID: "ubuntu", VersionID: "16.04"})
+	assert.False(t, p.CompatibleWithHost())
+}
+
+func TestAlpineServiceAndPackage(t *testing.T) {
+	p := NewAlpineProvisioner(&fakedriver.Driver{}).(*AlpineProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"sudo rc-update add docker default": "",
+			"sudo rc-service docker restart":    "",
+			"sudo apk add --update curl":        "",
+			"sudo apk del curl":                 "",
+		},
+	}
+
+	assert.NoError(t, p.Service("docker", serviceaction.Enable))
+	assert.NoError(t, p.Service("docker", serviceaction.Restart))
+	assert.NoError(t, p.Service("docker", serviceaction.DaemonReload))
+	assert.NoError(t, p.Package("curl", pkgaction.Install))
+	assert.NoError(t, p.Package("curl", pkgaction.Remove))
+}
+
+func TestAlpineDefaultStorageDriver(t *testing.T) {
+	p := NewAlpineProvisioner(&fakedriver.Driver{}).(*AlpineProvisioner)
+	p.SSHCommander = provisiontest.NewFakeSSHCommander(provisiontest.FakeSSHCommanderOptions{})
+
+	p.Provision(swarm.Options{}, auth.Options{}, engine.Options{})
+
+	assert.Equal(t, "overlay2", p.EngineOptions.StorageDriver)
+}
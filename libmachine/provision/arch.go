@@ -1,8 +1,6 @@
 package provision
 
 import (
-	"fmt"
-
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/engine"
@@ -37,50 +35,24 @@ func (provisioner *ArchProvisioner) CompatibleWithHost() bool {
 	return provisioner.OsReleaseInfo.ID == provisioner.OsReleaseID || provisioner.OsReleaseInfo.IDLike == provisioner.OsReleaseID
 }
 
-func (provisioner *ArchProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	updateMetadata := true
-
-	switch action {
-	case pkgaction.Install, pkgaction.Upgrade:
-		packageAction = "S"
-	case pkgaction.Remove:
-		packageAction = "R"
-		updateMetadata = false
-	}
-
-	switch name {
-	case "docker-engine":
-		name = "docker"
-	case "docker":
-		name = "docker"
-	}
-
-	pacmanOpts := "-" + packageAction
-	if updateMetadata {
-		pacmanOpts = pacmanOpts + "y"
-	}
-
-	pacmanOpts = pacmanOpts + " --noconfirm --noprogressbar"
-
-	command := fmt.Sprintf("sudo -E pacman %s %s", pacmanOpts, name)
-
-	log.Debugf("package: action=%s name=%s", action.String(), name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
+// PackageManager returns the pacman package manager of the machine.
+func (provisioner *ArchProvisioner) PackageManager() PackageManager {
+	return &pacmanPackageManager{
+		SSHCommander: provisioner,
+		names:        packageNames{"docker-engine": "docker"},
 	}
+}
 
-	return nil
+func (provisioner *ArchProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func (provisioner *ArchProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -133,6 +105,14 @@ func (provisioner *ArchProvisioner) Provision(swarmOptions swarm.Options, authOp
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debug("Configuring auth")
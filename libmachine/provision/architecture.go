@@ -0,0 +1,66 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+)
+
+// archPlaceholder is replaced in the engine install URL and bundle with the
+// architecture of the machine, so that one setting serves machines of
+// different architectures, e.g. /opt/docker/docker-{arch}.tgz
+const archPlaceholder = "{arch}"
+
+// engineArchitectures maps the output of `uname -m` to the name the engine
+// binaries are released under for that architecture.
+var engineArchitectures = map[string]string{
+	"x86_64":  "x86_64",
+	"amd64":   "x86_64",
+	"armv7l":  "armhf",
+	"armv8l":  "armhf",
+	"aarch64": "aarch64",
+	"arm64":   "aarch64",
+	"ppc64le": "ppc64le",
+	"s390x":   "s390x",
+}
+
+// detectArchitecture returns the architecture of the machine, named like the
+// engine binaries released for it.
+func detectArchitecture(p SSHCommander) (string, error) {
+	output, err := p.SSHCommand("uname -m")
+	if err != nil {
+		return "", fmt.Errorf("error detecting the architecture of the machine: %s", err)
+	}
+
+	return engineArchitecture(strings.TrimSpace(output))
+}
+
+func engineArchitecture(machine string) (string, error) {
+	arch, ok := engineArchitectures[machine]
+	if !ok {
+		return "", fmt.Errorf("the engine is not available for the %q architecture", machine)
+	}
+
+	return arch, nil
+}
+
+// expandArchitecture replaces the architecture placeholder of the install URL
+// and bundle of the engine, detecting the architecture only when needed.
+func expandArchitecture(p SSHCommander, engineOptions *engine.Options) error {
+	if !strings.Contains(engineOptions.InstallURL, archPlaceholder) && !strings.Contains(engineOptions.InstallBundle, archPlaceholder) {
+		return nil
+	}
+
+	arch, err := detectArchitecture(p)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Installing the engine for the %s architecture", arch)
+	engineOptions.InstallURL = strings.Replace(engineOptions.InstallURL, archPlaceholder, arch, -1)
+	engineOptions.InstallBundle = strings.Replace(engineOptions.InstallBundle, archPlaceholder, arch, -1)
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineArchitecture(t *testing.T) {
+	var tests = []struct {
+		machine  string
+		expected string
+	}{
+		{"x86_64", "x86_64"},
+		{"armv7l", "armhf"},
+		{"aarch64", "aarch64"},
+		{"arm64", "aarch64"},
+	}
+
+	for _, test := range tests {
+		arch, err := engineArchitecture(test.machine)
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, arch)
+	}
+
+	_, err := engineArchitecture("i686")
+	assert.Error(t, err)
+}
+
+func TestInstallDockerGenericArchitecture(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"uname -m": "armv7l\n",
+			"if ! type docker; then curl -sSL https://example.com/install-armhf.sh | sh -; fi": "",
+		},
+	}
+
+	assert.NoError(t, installDockerGeneric(p, engine.Options{InstallURL: "https://example.com/install-{arch}.sh"}))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"path"
+	"strconv"
 	"text/template"
 	"time"
 
@@ -21,6 +22,10 @@ import (
 	"github.com/docker/machine/libmachine/swarm"
 )
 
+const (
+	boot2DockerReleaseURL = "https://github.com/boot2docker/boot2docker/releases/download/v%s/boot2docker.iso"
+)
+
 func init() {
 	Register("boot2docker", &RegisteredProvisioner{
 		New: NewBoot2DockerProvisioner,
@@ -51,11 +56,6 @@ func (provisioner *Boot2DockerProvisioner) Service(name string, action serviceac
 }
 
 func (provisioner *Boot2DockerProvisioner) upgradeIso() error {
-	// TODO: Ideally, we should not read from mcndirs directory at all.
-	// The driver should be able to communicate how and where to place the
-	// relevant files.
-	b2dutils := mcnutils.NewB2dUtils(mcndirs.GetBaseDir())
-
 	// Check if the driver has specified a custom b2d url
 	jsonDriver, err := json.Marshal(provisioner.GetDriver())
 	if err != nil {
@@ -66,6 +66,21 @@ func (provisioner *Boot2DockerProvisioner) upgradeIso() error {
 	}
 	json.Unmarshal(jsonDriver, &d)
 
+	return provisioner.upgradeIsoFrom(d.Boot2DockerURL)
+}
+
+// InstallEngineVersion switches the machine to the boot2docker release
+// shipping the given engine version.
+func (provisioner *Boot2DockerProvisioner) InstallEngineVersion(version string) error {
+	return provisioner.upgradeIsoFrom(fmt.Sprintf(boot2DockerReleaseURL, version))
+}
+
+func (provisioner *Boot2DockerProvisioner) upgradeIsoFrom(isoURL string) error {
+	// TODO: Ideally, we should not read from mcndirs directory at all.
+	// The driver should be able to communicate how and where to place the
+	// relevant files.
+	b2dutils := mcnutils.NewB2dUtils(mcndirs.GetBaseDir())
+
 	log.Info("Stopping machine to do the upgrade...")
 
 	if err := provisioner.Driver.Stop(); err != nil {
@@ -82,7 +97,7 @@ func (provisioner *Boot2DockerProvisioner) upgradeIso() error {
 
 	// Either download the latest version of the b2d url that was explicitly
 	// specified when creating the VM or copy the (updated) default ISO
-	if err := b2dutils.CopyIsoToMachineDir(d.Boot2DockerURL, machineName); err != nil {
+	if err := b2dutils.CopyIsoToMachineDir(isoURL, machineName); err != nil {
 		return err
 	}
 
@@ -128,6 +143,10 @@ func (provisioner *Boot2DockerProvisioner) GetAuthOptions() auth.Options {
 	return provisioner.AuthOptions
 }
 
+func (provisioner *Boot2DockerProvisioner) GetEngineOptions() engine.Options {
+	return provisioner.EngineOptions
+}
+
 func (provisioner *Boot2DockerProvisioner) GetSwarmOptions() swarm.Options {
 	return provisioner.SwarmOptions
 }
@@ -149,7 +168,7 @@ EXTRA_ARGS='
 {{ end }}
 '
 CACERT={{.AuthOptions.CaCertRemotePath}}
-DOCKER_HOST='-H tcp://0.0.0.0:{{.DockerPort}}'
+DOCKER_HOST='-H tcp://{{.BindAddress}}:{{.DockerPort}}'
 DOCKER_STORAGE={{.EngineOptions.StorageDriver}}
 DOCKER_TLS=auto
 SERVERKEY={{.AuthOptions.ServerKeyRemotePath}}
@@ -165,8 +184,9 @@ SERVERCERT={{.AuthOptions.ServerCertRemotePath}}
 
 	engineConfigContext := EngineConfigContext{
 		DockerPort:    dockerPort,
+		BindAddress:   engineBindAddress(provisioner.Driver),
 		AuthOptions:   provisioner.AuthOptions,
-		EngineOptions: provisioner.EngineOptions,
+		EngineOptions: withDaemonFlags(withClusterStore(withProxyEnv(provisioner.EngineOptions, provisioner.Driver), provisioner.SwarmOptions, provisioner.AuthOptions, provisioner.Driver, dockerPort)),
 	}
 
 	t.Execute(&engineCfg, engineConfigContext)
@@ -197,7 +217,7 @@ func (provisioner *Boot2DockerProvisioner) AttemptIPContact(dockerPort int) {
 		return
 	}
 
-	if conn, err := net.DialTimeout("tcp", fmt.Sprintf("%s:%d", ip, dockerPort), 5*time.Second); err != nil {
+	if conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(dockerPort)), 5*time.Second); err != nil {
 		log.Warnf(`
 This machine has been allocated an IP address, but Docker Machine could not
 reach it successfully.
@@ -209,7 +229,7 @@ You may need to add the route manually, or use another related workaround.
 
 This could be due to a VPN, proxy, or host file configuration issue.
 
-You also might want to clear any VirtualBox host only interfaces you are not using.`, engine.DefaultPort)
+You also might want to clear any VirtualBox host only interfaces you are not using.`, dockerPort)
 	} else {
 		conn.Close()
 	}
@@ -222,7 +242,7 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.Options,
 
 	defer func() {
 		if err == nil {
-			provisioner.AttemptIPContact(engine.DefaultPort)
+			provisioner.AttemptIPContact(provisioner.EngineOptions.DaemonPort())
 		}
 	}()
 
@@ -235,12 +255,17 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.Options,
 		provisioner.EngineOptions.StorageDriver = "aufs"
 	}
 
+	if engineOptions.InstallVersion != "" {
+		log.Warnf("The engine of a boot2docker machine comes with its ISO, ignoring the engine install version %s", engineOptions.InstallVersion)
+	}
+
 	if err = provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
 		return err
 	}
 
 	// b2d hosts need to wait for the daemon to be up
-	// before continuing with provisioning
+	// before continuing with provisioning, still on the port of the
+	// configuration of the ISO
 	if err = WaitForDocker(provisioner, engine.DefaultPort); err != nil {
 		return err
 	}
@@ -249,6 +274,14 @@ func (provisioner *Boot2DockerProvisioner) Provision(swarmOptions swarm.Options,
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	if err = ConfigureAuth(provisioner); err != nil {
@@ -0,0 +1,83 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+// clusterStore returns the cluster store the engine uses for multi-host
+// networking: the one of the engine options, or else the discovery of the
+// swarm when cluster networking is enabled and the discovery is a key-value
+// store.
+func clusterStore(engineOptions engine.Options, swarmOptions swarm.Options) string {
+	if engineOptions.ClusterStore != "" {
+		return engineOptions.ClusterStore
+	}
+
+	if !swarmOptions.IsSwarm || !swarmOptions.ClusterNetworking {
+		return ""
+	}
+
+	if discovery, err := swarm.ParseDiscovery(swarmOptions.Discovery); err == nil && discovery.IsKVStore() {
+		return swarmOptions.Discovery
+	}
+
+	log.Warnf("The swarm discovery %q is not a key-value store, multi-host networking is not configured", swarmOptions.Discovery)
+	return ""
+}
+
+// withClusterStore returns a copy of the engine options whose flags also set
+// up the cluster store, so that overlay networks span the machines of the
+// cluster. The engine advertises the address of the machine by default, and
+// connects with TLS to a discovery requiring it.
+func withClusterStore(engineOptions engine.Options, swarmOptions swarm.Options, authOptions auth.Options, d drivers.Driver, dockerPort int) engine.Options {
+	store := clusterStore(engineOptions, swarmOptions)
+	if store == "" || hasArbitraryFlag(engineOptions, "cluster-store") {
+		return engineOptions
+	}
+
+	flags := append([]string{}, engineOptions.ArbitraryFlags...)
+	flags = append(flags, "cluster-store="+store)
+
+	advertise := engineOptions.ClusterAdvertise
+	if advertise == "" {
+		ip, err := d.GetIP()
+		if err != nil {
+			log.Warnf("Could not get the IP to advertise to the cluster store: %s", err)
+		} else {
+			advertise = net.JoinHostPort(ip, strconv.Itoa(dockerPort))
+		}
+	}
+	if advertise != "" {
+		flags = append(flags, "cluster-advertise="+advertise)
+	}
+
+	opts := engineOptions.ClusterStoreOpts
+	if engineOptions.ClusterStore == "" && swarmOptions.DiscoveryTLS {
+		opts = append(swarm.DiscoveryTLSOpts(authOptions.CaCertRemotePath, authOptions.ServerCertRemotePath, authOptions.ServerKeyRemotePath), opts...)
+	}
+	for _, opt := range opts {
+		flags = append(flags, fmt.Sprintf("cluster-store-opt=%s", opt))
+	}
+
+	engineOptions.ArbitraryFlags = flags
+
+	return engineOptions
+}
+
+func hasArbitraryFlag(engineOptions engine.Options, name string) bool {
+	for _, flag := range engineOptions.ArbitraryFlags {
+		if flag == name || strings.HasPrefix(flag, name+"=") {
+			return true
+		}
+	}
+	return false
+}
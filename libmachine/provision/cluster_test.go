@@ -0,0 +1,97 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithClusterStore(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "192.168.99.100",
+	}
+
+	engineOptions := engine.Options{
+		ArbitraryFlags:   []string{"debug"},
+		ClusterStore:     "etcd://10.0.0.2:2379",
+		ClusterStoreOpts: []string{"kv.path=/docker"},
+	}
+
+	withStore := withClusterStore(engineOptions, swarm.Options{}, auth.Options{}, driver, 2376)
+
+	assert.Equal(t, []string{
+		"debug",
+		"cluster-store=etcd://10.0.0.2:2379",
+		"cluster-advertise=192.168.99.100:2376",
+		"cluster-store-opt=kv.path=/docker",
+	}, withStore.ArbitraryFlags)
+	assert.Equal(t, []string{"debug"}, engineOptions.ArbitraryFlags)
+}
+
+func TestWithClusterStoreFromSwarmDiscovery(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "192.168.99.100",
+	}
+
+	swarmOptions := swarm.Options{
+		IsSwarm:           true,
+		Discovery:         "consul://10.0.0.2:8500",
+		ClusterNetworking: true,
+	}
+
+	withStore := withClusterStore(engine.Options{ClusterAdvertise: "eth1:2376"}, swarmOptions, auth.Options{}, driver, 2376)
+	assert.Equal(t, []string{
+		"cluster-store=consul://10.0.0.2:8500",
+		"cluster-advertise=eth1:2376",
+	}, withStore.ArbitraryFlags)
+
+	// A token discovery cannot be used as cluster store
+	swarmOptions.Discovery = "token://abc"
+	assert.Empty(t, withClusterStore(engine.Options{}, swarmOptions, auth.Options{}, driver, 2376).ArbitraryFlags)
+
+	// The cluster store set with --engine-opt wins
+	engineOptions := engine.Options{ArbitraryFlags: []string{"cluster-store=zk://10.0.0.3:2181"}}
+	swarmOptions.Discovery = "consul://10.0.0.2:8500"
+	assert.Equal(t, engineOptions, withClusterStore(engineOptions, swarmOptions, auth.Options{}, driver, 2376))
+}
+
+func TestWithClusterStoreFromSwarmDiscoveryWithTLS(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "192.168.99.100",
+	}
+
+	swarmOptions := swarm.Options{
+		IsSwarm:           true,
+		Discovery:         "consul://10.0.0.2:8500",
+		ClusterNetworking: true,
+		DiscoveryTLS:      true,
+	}
+	authOptions := auth.Options{
+		CaCertRemotePath:     "/etc/docker/ca.pem",
+		ServerCertRemotePath: "/etc/docker/server.pem",
+		ServerKeyRemotePath:  "/etc/docker/server-key.pem",
+	}
+
+	withStore := withClusterStore(engine.Options{}, swarmOptions, authOptions, driver, 2376)
+
+	assert.Equal(t, []string{
+		"cluster-store=consul://10.0.0.2:8500",
+		"cluster-advertise=192.168.99.100:2376",
+		"cluster-store-opt=kv.cacertfile=/etc/docker/ca.pem",
+		"cluster-store-opt=kv.certfile=/etc/docker/server.pem",
+		"cluster-store-opt=kv.keyfile=/etc/docker/server-key.pem",
+	}, withStore.ArbitraryFlags)
+	assert.Equal(t, []string{
+		"--discovery-opt", "kv.cacertfile=/etc/docker/ca.pem",
+		"--discovery-opt", "kv.certfile=/etc/docker/server.pem",
+		"--discovery-opt", "kv.keyfile=/etc/docker/server-key.pem",
+	}, discoveryOpts(swarmOptions, authOptions))
+}
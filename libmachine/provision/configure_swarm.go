@@ -2,12 +2,13 @@ package provision
 
 import (
 	"fmt"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
 
 	"github.com/docker/machine/libmachine/auth"
-	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcndockerclient"
 	"github.com/docker/machine/libmachine/swarm"
@@ -15,6 +16,10 @@ import (
 )
 
 func configureSwarm(p Provisioner, swarmOptions swarm.Options, authOptions auth.Options) error {
+	if err := configureDiscoveryStore(p, swarmOptions, authOptions); err != nil {
+		return err
+	}
+
 	if !swarmOptions.IsSwarm {
 		return nil
 	}
@@ -26,38 +31,42 @@ func configureSwarm(p Provisioner, swarmOptions swarm.Options, authOptions auth.
 		return err
 	}
 
-	u, err := url.Parse(swarmOptions.Host)
+	// Without a discovery, the swarm discovers its nodes with the store the
+	// machine runs
+	if swarmOptions.Discovery == "" && swarmOptions.DiscoveryStore != "" {
+		discovery, err := swarm.KVStoreDiscovery(swarmOptions.DiscoveryStore, ip)
+		if err != nil {
+			return err
+		}
+		swarmOptions.Discovery = discovery.String()
+	}
+
+	advertiseIP, err := swarmAdvertiseIP(p.GetDriver())
 	if err != nil {
 		return err
 	}
 
-	enginePort := engine.DefaultPort
-	engineURL, err := p.GetDriver().GetURL()
+	u, err := url.Parse(swarmOptions.Host)
 	if err != nil {
 		return err
 	}
 
-	parts := strings.Split(engineURL, ":")
-	if len(parts) == 3 {
-		dPort, err := strconv.Atoi(parts[2])
-		if err != nil {
-			return err
-		}
-		enginePort = dPort
+	dockerPort, err := enginePort(p)
+	if err != nil {
+		return err
 	}
 
-	parts = strings.Split(u.Host, ":")
-	port := parts[1]
+	port := u.Port()
 
 	dockerDir := p.GetDockerOptionsDir()
 	dockerHost := &mcndockerclient.RemoteDocker{
-		HostURL:    fmt.Sprintf("tcp://%s:%d", ip, enginePort),
+		HostURL:    fmt.Sprintf("tcp://%s", net.JoinHostPort(ip, strconv.Itoa(dockerPort))),
 		AuthOption: &authOptions,
 	}
-	advertiseInfo := fmt.Sprintf("%s:%d", ip, enginePort)
+	advertiseInfo := net.JoinHostPort(advertiseIP, strconv.Itoa(dockerPort))
 
 	if swarmOptions.Master {
-		advertiseMasterInfo := fmt.Sprintf("%s:%s", ip, "3376")
+		advertiseMasterInfo := net.JoinHostPort(advertiseIP, "3376")
 		cmd := fmt.Sprintf("manage --tlsverify --tlscacert=%s --tlscert=%s --tlskey=%s -H %s --strategy %s --advertise %s",
 			authOptions.CaCertRemotePath,
 			authOptions.ServerCertRemotePath,
@@ -75,6 +84,8 @@ func configureSwarm(p Provisioner, swarmOptions swarm.Options, authOptions auth.
 			cmdMaster = append(cmdMaster, "--"+option)
 		}
 
+		cmdMaster = append(cmdMaster, discoveryOpts(swarmOptions, authOptions)...)
+
 		//Discovery must be at end of command
 		cmdMaster = append(cmdMaster, swarmOptions.Discovery)
 
@@ -128,8 +139,15 @@ func configureSwarm(p Provisioner, swarmOptions swarm.Options, authOptions auth.
 		for _, option := range swarmOptions.ArbitraryJoinFlags {
 			cmdWorker = append(cmdWorker, "--"+option)
 		}
+		cmdWorker = append(cmdWorker, discoveryOpts(swarmOptions, authOptions)...)
 		cmdWorker = append(cmdWorker, swarmOptions.Discovery)
 
+		// The worker reads the certificates of the machine to connect to
+		// the discovery with TLS
+		if swarmOptions.DiscoveryTLS {
+			workerHostConfig.Binds = []string{fmt.Sprintf("%s:%s", dockerDir, dockerDir)}
+		}
+
 		swarmWorkerConfig := &dockerclient.ContainerConfig{
 			Image:      swarmOptions.Image,
 			Env:        swarmOptions.Env,
@@ -147,3 +165,19 @@ func configureSwarm(p Provisioner, swarmOptions swarm.Options, authOptions auth.
 	}
 	return nil
 }
+
+// swarmAdvertiseIP returns the address the swarm containers advertise: the
+// private address of the machine when it has one, so that the swarm traffic
+// stays on the private network, or the address of the machine otherwise.
+func swarmAdvertiseIP(d drivers.Driver) (string, error) {
+	privateIP, err := drivers.GetPrivateIP(d)
+	if err == nil && privateIP != "" {
+		log.Debugf("Advertising the private address %s for swarm", privateIP)
+		return privateIP, nil
+	}
+	if err != nil && err != drivers.ErrNotImplemented {
+		return "", err
+	}
+
+	return d.GetIP()
+}
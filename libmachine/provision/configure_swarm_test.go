@@ -0,0 +1,34 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwarmAdvertiseIPPrefersPrivateIP(t *testing.T) {
+	d := &fakedriver.Driver{
+		MockState:     state.Running,
+		MockIP:        "203.0.113.10",
+		MockPrivateIP: "10.132.0.2",
+	}
+
+	ip, err := swarmAdvertiseIP(d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "10.132.0.2", ip)
+}
+
+func TestSwarmAdvertiseIPWithoutPrivateIP(t *testing.T) {
+	d := &fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "203.0.113.10",
+	}
+
+	ip, err := swarmAdvertiseIP(d)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.10", ip)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/serviceaction"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/libmachine/versioncmp"
 )
@@ -78,7 +79,7 @@ func (provisioner *CoreOSProvisioner) GenerateDockerOptions(dockerPort int) (*Do
 	engineConfigTmpl := `[Service]
 Environment=TMPDIR=/var/tmp
 ExecStart=
-ExecStart=/usr/lib/coreos/dockerd ` + arg + ` --host=unix:///var/run/docker.sock --host=tcp://0.0.0.0:{{.DockerPort}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}}{{ range .EngineOptions.Labels }} --label {{.}}{{ end }}{{ range .EngineOptions.InsecureRegistry }} --insecure-registry {{.}}{{ end }}{{ range .EngineOptions.RegistryMirror }} --registry-mirror {{.}}{{ end }}{{ range .EngineOptions.ArbitraryFlags }} --{{.}}{{ end }} \$DOCKER_OPTS \$DOCKER_OPT_BIP \$DOCKER_OPT_MTU \$DOCKER_OPT_IPMASQ
+ExecStart=/usr/lib/coreos/dockerd ` + arg + `{{ if not .EngineOptions.DisableUnixSocket }} --host=unix:///var/run/docker.sock{{ end }} --host=tcp://{{.BindAddress}}:{{.DockerPort}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}}{{ range .EngineOptions.Labels }} --label {{.}}{{ end }}{{ range .EngineOptions.InsecureRegistry }} --insecure-registry {{.}}{{ end }}{{ range .EngineOptions.RegistryMirror }} --registry-mirror {{.}}{{ end }}{{ range .EngineOptions.ArbitraryFlags }} --{{.}}{{ end }} \$DOCKER_OPTS \$DOCKER_OPT_BIP \$DOCKER_OPT_MTU \$DOCKER_OPT_IPMASQ
 Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 `
 
@@ -89,8 +90,9 @@ Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 
 	engineConfigContext := EngineConfigContext{
 		DockerPort:    dockerPort,
+		BindAddress:   engineBindAddress(provisioner.Driver),
 		AuthOptions:   provisioner.AuthOptions,
-		EngineOptions: provisioner.EngineOptions,
+		EngineOptions: withDaemonFlags(withClusterStore(withProxyEnv(provisioner.EngineOptions, provisioner.Driver), provisioner.SwarmOptions, provisioner.AuthOptions, provisioner.Driver, dockerPort)),
 	}
 
 	t.Execute(&engineCfg, engineConfigContext)
@@ -101,15 +103,42 @@ Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 	}, nil
 }
 
+// Package only accepts installing Docker, which comes with the OS: CoreOS
+// has no package manager, and updates Docker along with the OS.
 func (provisioner *CoreOSProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	return nil
+	switch name {
+	case "docker", "docker-engine":
+		if action == pkgaction.Install {
+			return nil
+		}
+		return fmt.Errorf("Docker cannot be %sd on CoreOS, it is updated along with the OS", action.String())
+	}
+
+	return fmt.Errorf("CoreOS has no package manager, %s cannot be installed", name)
+}
+
+// InstallEngineVersion refuses to change the version of Docker, which is
+// the one of the CoreOS release.
+func (provisioner *CoreOSProvisioner) InstallEngineVersion(version string) error {
+	return fmt.Errorf("The Docker version of CoreOS is the one of the OS release, %s cannot be installed", version)
 }
 
+// Provision configures the Docker pre-installed on CoreOS with a systemd
+// drop-in of the docker unit, and enables the unit for the daemon to listen
+// on TCP from boot, instead of being started on demand by its socket.
 func (provisioner *CoreOSProvisioner) Provision(swarmOptions swarm.Options, authOptions auth.Options, engineOptions engine.Options) error {
 	provisioner.SwarmOptions = swarmOptions
 	provisioner.AuthOptions = authOptions
 	provisioner.EngineOptions = engineOptions
 
+	if _, err := DockerClientVersion(provisioner); err != nil {
+		return fmt.Errorf("Docker is not installed on the machine, is it running CoreOS? %s", err)
+	}
+
+	if engineOptions.InstallVersion != "" || engineOptions.InstallBundle != "" {
+		log.Warn("CoreOS comes with Docker installed, the engine install options are ignored")
+	}
+
 	if err := provisioner.SetHostname(provisioner.Driver.GetMachineName()); err != nil {
 		return err
 	}
@@ -118,7 +147,14 @@ func (provisioner *CoreOSProvisioner) Provision(swarmOptions swarm.Options, auth
 		return err
 	}
 
-	log.Debugf("Preparing certificates")
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debugf("Setting up certificates")
@@ -127,6 +163,10 @@ func (provisioner *CoreOSProvisioner) Provision(swarmOptions swarm.Options, auth
 	}
 
 	log.Debug("Configuring swarm")
-	err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
-	return err
+	if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+		return err
+	}
+
+	log.Debug("Enabling docker in systemd")
+	return provisioner.Service("docker", serviceaction.Enable)
 }
@@ -0,0 +1,85 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoreOSPackage(t *testing.T) {
+	p := NewCoreOSProvisioner(&fakedriver.Driver{})
+
+	assert.NoError(t, p.Package("docker", pkgaction.Install))
+	assert.EqualError(t, p.Package("docker", pkgaction.Upgrade), "Docker cannot be upgraded on CoreOS, it is updated along with the OS")
+	assert.EqualError(t, p.Package("curl", pkgaction.Install), "CoreOS has no package manager, curl cannot be installed")
+}
+
+func TestCoreOSGenerateDockerOptionsDropIn(t *testing.T) {
+	p := NewCoreOSProvisioner(&fakedriver.Driver{}).(*CoreOSProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"docker --version": "Docker version 17.09.0-ce, build afdb6d4",
+		},
+	}
+	p.AuthOptions = auth.Options{
+		CaCertRemotePath:     "/etc/docker/ca.pem",
+		ServerCertRemotePath: "/etc/docker/server.pem",
+		ServerKeyRemotePath:  "/etc/docker/server-key.pem",
+	}
+
+	options, err := p.GenerateDockerOptions(2376)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/systemd/system/docker.service.d/10-machine.conf", options.EngineOptionsPath)
+	assert.Contains(t, options.EngineOptions, "ExecStart=/usr/lib/coreos/dockerd  --host=unix:///var/run/docker.sock --host=tcp://0.0.0.0:2376 --tlsverify")
+}
+
+func TestCoreOSGenerateDockerOptionsWithoutUnixSocket(t *testing.T) {
+	p := NewCoreOSProvisioner(&fakedriver.Driver{}).(*CoreOSProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"docker --version": "Docker version 17.09.0-ce, build afdb6d4",
+		},
+	}
+	p.EngineOptions.DisableUnixSocket = true
+
+	options, err := p.GenerateDockerOptions(12376)
+
+	assert.NoError(t, err)
+	assert.Contains(t, options.EngineOptions, "ExecStart=/usr/lib/coreos/dockerd  --host=tcp://0.0.0.0:12376 --tlsverify")
+	assert.NotContains(t, options.EngineOptions, "docker.sock")
+}
+
+func TestCoreOSGenerateDockerOptionsIPv6(t *testing.T) {
+	p := NewCoreOSProvisioner(&fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "1.2.3.4",
+		MockIPs:   []string{"1.2.3.4", "2001:db8::1"},
+	}).(*CoreOSProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"docker --version": "Docker version 17.09.0-ce, build afdb6d4",
+		},
+	}
+
+	options, err := p.GenerateDockerOptions(2376)
+
+	assert.NoError(t, err)
+	assert.Contains(t, options.EngineOptions, "--host=tcp://[::]:2376 --tlsverify")
+}
+
+func TestCoreOSProvisionWithoutDocker(t *testing.T) {
+	p := NewCoreOSProvisioner(&fakedriver.Driver{}).(*CoreOSProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{Responses: map[string]string{}}
+
+	err := p.Provision(swarm.Options{}, auth.Options{}, engine.Options{})
+
+	assert.Error(t, err)
+}
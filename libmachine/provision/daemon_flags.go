@@ -0,0 +1,44 @@
+package provision
+
+import (
+	"github.com/docker/machine/libmachine/engine"
+)
+
+// withDaemonFlags returns a copy of the engine options whose flags also set
+// the daemon options which have their own fields, e.g. the log driver.
+// Flags given as arbitrary flags take precedence.
+func withDaemonFlags(engineOptions engine.Options) engine.Options {
+	flags := append([]string{}, engineOptions.ArbitraryFlags...)
+
+	add := func(name, value string) {
+		if !hasArbitraryFlag(engineOptions, name) {
+			flags = append(flags, name+"="+value)
+		}
+	}
+
+	for _, plugin := range engineOptions.AuthorizationPlugins {
+		flags = append(flags, "authorization-plugin="+plugin)
+	}
+	if engineOptions.LogDriver != "" {
+		add("log-driver", engineOptions.LogDriver)
+	}
+	for _, opt := range engineOptions.LogOpts {
+		flags = append(flags, "log-opt="+opt)
+	}
+	if engineOptions.LiveRestore && !hasArbitraryFlag(engineOptions, "live-restore") {
+		flags = append(flags, "live-restore")
+	}
+	if engineOptions.CgroupDriver != "" && !hasArbitraryFlag(engineOptions, "exec-opt=native.cgroupdriver") {
+		flags = append(flags, "exec-opt=native.cgroupdriver="+engineOptions.CgroupDriver)
+	}
+	if engineOptions.GPURuntime {
+		if !hasArbitraryFlag(engineOptions, "add-runtime="+gpuRuntimeName) {
+			flags = append(flags, "add-runtime="+gpuRuntimeName+"="+gpuRuntimePath)
+		}
+		add("default-runtime", gpuRuntimeName)
+	}
+
+	engineOptions.ArbitraryFlags = flags
+
+	return engineOptions
+}
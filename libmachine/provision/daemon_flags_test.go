@@ -0,0 +1,59 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDaemonFlags(t *testing.T) {
+	engineOptions := engine.Options{
+		ArbitraryFlags:       []string{"debug"},
+		AuthorizationPlugins: []string{"opa", "authz-broker"},
+		LogDriver:            "journald",
+		LogOpts:              []string{"tag={{.Name}}"},
+		LiveRestore:          true,
+		CgroupDriver:         "systemd",
+	}
+
+	withFlags := withDaemonFlags(engineOptions)
+
+	assert.Equal(t, []string{
+		"debug",
+		"authorization-plugin=opa",
+		"authorization-plugin=authz-broker",
+		"log-driver=journald",
+		"log-opt=tag={{.Name}}",
+		"live-restore",
+		"exec-opt=native.cgroupdriver=systemd",
+	}, withFlags.ArbitraryFlags)
+	assert.Equal(t, []string{"debug"}, engineOptions.ArbitraryFlags)
+}
+
+func TestWithDaemonFlagsArbitraryFlagsFirst(t *testing.T) {
+	engineOptions := engine.Options{
+		ArbitraryFlags: []string{"log-driver=syslog", "exec-opt=native.cgroupdriver=cgroupfs"},
+		LogDriver:      "journald",
+		CgroupDriver:   "systemd",
+	}
+
+	withFlags := withDaemonFlags(engineOptions)
+
+	assert.Equal(t, []string{"log-driver=syslog", "exec-opt=native.cgroupdriver=cgroupfs"}, withFlags.ArbitraryFlags)
+}
+
+func TestWithDaemonFlagsNone(t *testing.T) {
+	withFlags := withDaemonFlags(engine.Options{})
+
+	assert.Empty(t, withFlags.ArbitraryFlags)
+}
+
+func TestWithDaemonFlagsGPURuntime(t *testing.T) {
+	withFlags := withDaemonFlags(engine.Options{GPURuntime: true})
+
+	assert.Equal(t, []string{
+		"add-runtime=nvidia=/usr/bin/nvidia-container-runtime",
+		"default-runtime=nvidia",
+	}, withFlags.ArbitraryFlags)
+}
@@ -1,8 +1,6 @@
 package provision
 
 import (
-	"fmt"
-
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/engine"
@@ -33,50 +31,24 @@ func (provisioner *DebianProvisioner) String() string {
 	return "debian"
 }
 
-func (provisioner *DebianProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	updateMetadata := true
-
-	switch action {
-	case pkgaction.Install, pkgaction.Upgrade:
-		packageAction = "install"
-	case pkgaction.Remove:
-		packageAction = "remove"
-		updateMetadata = false
-	case pkgaction.Purge:
-		packageAction = "purge"
-		updateMetadata = false
-	}
-
-	switch name {
-	case "docker":
-		name = "docker-engine"
-	}
-
-	if updateMetadata {
-		if err := waitForLockAptGetUpdate(provisioner); err != nil {
-			return err
-		}
-	}
-
-	command := fmt.Sprintf("DEBIAN_FRONTEND=noninteractive sudo -E apt-get %s -y  %s", packageAction, name)
-
-	log.Debugf("package: action=%s name=%s", action.String(), name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
+// PackageManager returns the apt package manager of the machine.
+func (provisioner *DebianProvisioner) PackageManager() PackageManager {
+	return &aptPackageManager{
+		SSHCommander: provisioner,
+		names:        packageNames{"docker": "docker-engine"},
 	}
+}
 
-	return nil
+func (provisioner *DebianProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func (provisioner *DebianProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -115,7 +87,7 @@ func (provisioner *DebianProvisioner) Provision(swarmOptions swarm.Options, auth
 	}
 
 	log.Debug("installing docker")
-	if err := installDockerGeneric(provisioner, engineOptions.InstallURL); err != nil {
+	if err := installDockerGeneric(provisioner, engineOptions); err != nil {
 		return err
 	}
 
@@ -124,6 +96,14 @@ func (provisioner *DebianProvisioner) Provision(swarmOptions swarm.Options, auth
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debug("configuring auth")
@@ -0,0 +1,110 @@
+package provision
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcndockerclient"
+	"github.com/docker/machine/libmachine/swarm"
+	"github.com/samalba/dockerclient"
+)
+
+const discoveryStoreContainer = "discovery-store"
+
+// discoveryStoreConfig returns the container running a key-value store for
+// the swarm discovery of other machines, listening on the default port of
+// the store.
+func discoveryStoreConfig(store, ip string) (*dockerclient.ContainerConfig, error) {
+	d, err := swarm.KVStoreDiscovery(store, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	_, port, _ := net.SplitHostPort(d.Hosts[0])
+	config := &dockerclient.ContainerConfig{
+		ExposedPorts: map[string]struct{}{
+			port + "/tcp": {},
+		},
+		HostConfig: dockerclient.HostConfig{
+			RestartPolicy: dockerclient.RestartPolicy{
+				Name: "always",
+			},
+			PortBindings: map[string][]dockerclient.PortBinding{
+				port + "/tcp": {
+					{
+						HostIp:   "0.0.0.0",
+						HostPort: port,
+					},
+				},
+			},
+		},
+	}
+
+	switch store {
+	case "consul":
+		config.Image = "progrium/consul"
+		config.Cmd = []string{"-server", "-bootstrap"}
+	case "etcd":
+		config.Image = "quay.io/coreos/etcd"
+		config.Cmd = []string{
+			"/usr/local/bin/etcd",
+			"--listen-client-urls", "http://0.0.0.0:" + port,
+			"--advertise-client-urls", "http://" + d.Hosts[0],
+		}
+	case "zk":
+		config.Image = "zookeeper"
+	}
+
+	return config, nil
+}
+
+// configureDiscoveryStore runs the key-value store other machines discover
+// the swarm nodes with, when the machine is to run one.
+func configureDiscoveryStore(p Provisioner, swarmOptions swarm.Options, authOptions auth.Options) error {
+	if swarmOptions.DiscoveryStore == "" {
+		return nil
+	}
+
+	log.Infof("Running the %s discovery store...", swarmOptions.DiscoveryStore)
+
+	ip, err := p.GetDriver().GetIP()
+	if err != nil {
+		return err
+	}
+
+	engineURL, err := p.GetDriver().GetURL()
+	if err != nil {
+		return err
+	}
+
+	config, err := discoveryStoreConfig(swarmOptions.DiscoveryStore, ip)
+	if err != nil {
+		return err
+	}
+
+	dockerHost := &mcndockerclient.RemoteDocker{
+		HostURL:    engineURL,
+		AuthOption: &authOptions,
+	}
+	if err := mcndockerclient.CreateContainer(dockerHost, config, discoveryStoreContainer); err != nil {
+		return fmt.Errorf("Error running the %s discovery store: %s", swarmOptions.DiscoveryStore, err)
+	}
+
+	return nil
+}
+
+// discoveryOpts returns the flags of the swarm containers connecting to the
+// discovery with TLS, using the certificates of the machine.
+func discoveryOpts(swarmOptions swarm.Options, authOptions auth.Options) []string {
+	if !swarmOptions.DiscoveryTLS {
+		return nil
+	}
+
+	flags := []string{}
+	for _, opt := range swarm.DiscoveryTLSOpts(authOptions.CaCertRemotePath, authOptions.ServerCertRemotePath, authOptions.ServerKeyRemotePath) {
+		flags = append(flags, "--discovery-opt", opt)
+	}
+	return flags
+}
@@ -0,0 +1,16 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryStoreConfig(t *testing.T) {
+	config, err := discoveryStoreConfig("etcd", "10.0.0.2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "quay.io/coreos/etcd", config.Image)
+	assert.Contains(t, config.Cmd, "http://10.0.0.2:2379")
+	assert.Equal(t, "2379", config.HostConfig.PortBindings["2379/tcp"][0].HostPort)
+}
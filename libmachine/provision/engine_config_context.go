@@ -7,6 +7,7 @@ import (
 
 type EngineConfigContext struct {
 	DockerPort       int
+	BindAddress      string
 	AuthOptions      auth.Options
 	EngineOptions    engine.Options
 	DockerOptionsDir string
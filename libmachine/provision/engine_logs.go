@@ -0,0 +1,81 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EngineLogger is implemented by provisioners which know where the init
+// system of the machine keeps the logs of the Docker daemon.
+type EngineLogger interface {
+	// EngineLogsCommand returns the command printing the last tail lines
+	// of the logs of the daemon, or all of them when tail is not positive,
+	// then printing the new lines as they are logged when follow is set.
+	EngineLogsCommand(tail int, follow bool) string
+}
+
+// EngineLogsCommand returns the command printing the logs of the daemon on
+// the machine of the provisioner.
+func EngineLogsCommand(p Provisioner, tail int, follow bool) (string, error) {
+	logger, ok := p.(EngineLogger)
+	if !ok {
+		return "", fmt.Errorf("Reading the engine logs is not supported on %s", p)
+	}
+	return logger.EngineLogsCommand(tail, follow), nil
+}
+
+// tailLogFileCommand prints a log file the daemon writes to.
+func tailLogFileCommand(path string, tail int, follow bool) string {
+	args := []string{"sudo", "tail"}
+	if tail > 0 {
+		args = append(args, "-n", fmt.Sprint(tail))
+	} else {
+		args = append(args, "-n", "+1")
+	}
+	if follow {
+		args = append(args, "-F")
+	}
+	return strings.Join(append(args, path), " ")
+}
+
+// EngineLogsCommand reads the log file the install script sets the daemon
+// up to write to.
+func (provisioner *GenericProvisioner) EngineLogsCommand(tail int, follow bool) string {
+	return tailLogFileCommand("/var/log/docker.log", tail, follow)
+}
+
+// EngineLogsCommand reads the journal of the docker unit.
+func (p *SystemdProvisioner) EngineLogsCommand(tail int, follow bool) string {
+	args := []string{"sudo", "journalctl", "-u", "docker", "--no-pager"}
+	if tail > 0 {
+		args = append(args, "-n", fmt.Sprint(tail))
+	}
+	if follow {
+		args = append(args, "-f")
+	}
+	return strings.Join(args, " ")
+}
+
+// EngineLogsCommand reads the log file upstart keeps for the docker job.
+func (provisioner *UbuntuProvisioner) EngineLogsCommand(tail int, follow bool) string {
+	return tailLogFileCommand("/var/log/upstart/docker.log", tail, follow)
+}
+
+// EngineLogsCommand reads the log file the boot2docker init script makes the
+// daemon write to.
+func (provisioner *Boot2DockerProvisioner) EngineLogsCommand(tail int, follow bool) string {
+	return tailLogFileCommand("/var/log/docker.log", tail, follow)
+}
+
+// EngineLogsCommand reads the logs of the system container running the
+// user daemon.
+func (provisioner *RancherProvisioner) EngineLogsCommand(tail int, follow bool) string {
+	args := []string{"sudo", "system-docker", "logs"}
+	if tail > 0 {
+		args = append(args, "--tail", fmt.Sprint(tail))
+	}
+	if follow {
+		args = append(args, "-f")
+	}
+	return strings.Join(append(args, "docker"), " ")
+}
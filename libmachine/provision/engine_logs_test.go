@@ -0,0 +1,38 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineLogsCommand(t *testing.T) {
+	d := &fakedriver.Driver{}
+
+	var tests = []struct {
+		provisioner Provisioner
+		tail        int
+		follow      bool
+		expected    string
+	}{
+		{NewUbuntuSystemdProvisioner(d), 100, false, "sudo journalctl -u docker --no-pager -n 100"},
+		{NewCentosProvisioner(d), 0, true, "sudo journalctl -u docker --no-pager -f"},
+		{NewUbuntuProvisioner(d), 50, true, "sudo tail -n 50 -F /var/log/upstart/docker.log"},
+		{NewBoot2DockerProvisioner(d), 0, false, "sudo tail -n +1 /var/log/docker.log"},
+		{NewRancherProvisioner(d), 10, true, "sudo system-docker logs --tail 10 -f docker"},
+	}
+
+	for _, test := range tests {
+		command, err := EngineLogsCommand(test.provisioner, test.tail, test.follow)
+
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, command, test.provisioner.String())
+	}
+}
+
+func TestEngineLogsCommandNotSupported(t *testing.T) {
+	_, err := EngineLogsCommand(&FakeProvisioner{}, 10, false)
+
+	assert.Error(t, err)
+}
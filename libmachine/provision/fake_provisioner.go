@@ -47,6 +47,10 @@ func (fp *FakeProvisioner) GetSwarmOptions() swarm.Options {
 	return swarm.Options{}
 }
 
+func (fp *FakeProvisioner) GetEngineOptions() engine.Options {
+	return engine.Options{}
+}
+
 func (fp *FakeProvisioner) Package(name string, action pkgaction.PackageAction) error {
 	return nil
 }
@@ -80,6 +80,10 @@ func (provisioner *GenericProvisioner) GetSwarmOptions() swarm.Options {
 	return provisioner.SwarmOptions
 }
 
+func (provisioner *GenericProvisioner) GetEngineOptions() engine.Options {
+	return provisioner.EngineOptions
+}
+
 func (provisioner *GenericProvisioner) SetOsReleaseInfo(info *OsRelease) {
 	provisioner.OsReleaseInfo = info
 }
@@ -98,9 +102,9 @@ func (provisioner *GenericProvisioner) GenerateDockerOptions(dockerPort int) (*D
 
 	engineConfigTmpl := `
 DOCKER_OPTS='
--H tcp://0.0.0.0:{{.DockerPort}}
--H unix:///var/run/docker.sock
---storage-driver {{.EngineOptions.StorageDriver}}
+-H tcp://{{.BindAddress}}:{{.DockerPort}}
+{{ if not .EngineOptions.DisableUnixSocket }}-H unix:///var/run/docker.sock
+{{ end }}--storage-driver {{.EngineOptions.StorageDriver}}
 --tlsverify
 --tlscacert {{.AuthOptions.CaCertRemotePath}}
 --tlscert {{.AuthOptions.ServerCertRemotePath}}
@@ -121,8 +125,9 @@ DOCKER_OPTS='
 
 	engineConfigContext := EngineConfigContext{
 		DockerPort:    dockerPort,
+		BindAddress:   engineBindAddress(provisioner.Driver),
 		AuthOptions:   provisioner.AuthOptions,
-		EngineOptions: provisioner.EngineOptions,
+		EngineOptions: withDaemonFlags(withClusterStore(withProxyEnv(provisioner.EngineOptions, provisioner.Driver), provisioner.SwarmOptions, provisioner.AuthOptions, provisioner.Driver, dockerPort)),
 	}
 
 	t.Execute(&engineCfg, engineConfigContext)
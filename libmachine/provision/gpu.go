@@ -0,0 +1,64 @@
+package provision
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+)
+
+const (
+	gpuRuntimeName    = "nvidia"
+	gpuRuntimePath    = "/usr/bin/nvidia-container-runtime"
+	gpuRuntimePackage = "nvidia-container-runtime"
+	gpuRuntimeRepoURL = "https://nvidia.github.io/nvidia-container-runtime"
+)
+
+// installGPURuntime installs the NVIDIA container runtime the daemon runs
+// containers with when GPURuntime is set, from the repositories NVIDIA
+// publishes for the main distributions. The NVIDIA driver itself is expected
+// to come with the image of the machine, as it depends on its kernel.
+func installGPURuntime(p Provisioner, engineOptions engine.Options) error {
+	if !engineOptions.GPURuntime {
+		return nil
+	}
+
+	info, err := p.GetOsReleaseInfo()
+	if err != nil {
+		return err
+	}
+
+	// The repositories are named after the distribution and its version,
+	// e.g. ubuntu16.04 or centos7
+	distribution := info.ID + info.VersionID
+
+	var addRepository string
+	switch info.ID {
+	case "ubuntu", "debian":
+		addRepository = fmt.Sprintf("curl -sSL %[1]s/gpgkey | sudo apt-key add - && "+
+			"curl -sSL %[1]s/%[2]s/%[3]s.list | sudo tee /etc/apt/sources.list.d/%[3]s.list >/dev/null",
+			gpuRuntimeRepoURL, distribution, gpuRuntimePackage)
+	case "centos", "rhel", "amzn":
+		addRepository = fmt.Sprintf("curl -sSL %[1]s/%[2]s/%[3]s.repo | sudo tee /etc/yum.repos.d/%[3]s.repo >/dev/null",
+			gpuRuntimeRepoURL, distribution, gpuRuntimePackage)
+	default:
+		return fmt.Errorf("The GPU container runtime is not available for %s", p)
+	}
+
+	log.Info("Installing the GPU container runtime...")
+
+	if output, err := p.SSHCommand(addRepository); err != nil {
+		return fmt.Errorf("Error adding the repository of the GPU container runtime: %s\n%s", err, output)
+	}
+
+	if err := p.Package(gpuRuntimePackage, pkgaction.Install); err != nil {
+		return fmt.Errorf("Error installing the GPU container runtime: %s", err)
+	}
+
+	if _, err := p.SSHCommand("type nvidia-smi"); err != nil {
+		log.Warn("The NVIDIA driver is not installed on the machine, containers will not see the GPUs until it is")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newGPUTestProvisioner(id, versionID string, responses map[string]string) *fakeProvisioner {
+	return &fakeProvisioner{GenericProvisioner{
+		SSHCommander:  &provisiontest.FakeSSHCommander{Responses: responses},
+		OsReleaseInfo: &OsRelease{ID: id, VersionID: versionID},
+		Driver:        &fakedriver.Driver{},
+	}}
+}
+
+func TestInstallGPURuntime(t *testing.T) {
+	p := newGPUTestProvisioner("ubuntu", "16.04", map[string]string{
+		"curl -sSL https://nvidia.github.io/nvidia-container-runtime/gpgkey | sudo apt-key add - && " +
+			"curl -sSL https://nvidia.github.io/nvidia-container-runtime/ubuntu16.04/nvidia-container-runtime.list | " +
+			"sudo tee /etc/apt/sources.list.d/nvidia-container-runtime.list >/dev/null": "",
+		"type nvidia-smi": "",
+	})
+
+	assert.NoError(t, installGPURuntime(p, engine.Options{GPURuntime: true}))
+}
+
+func TestInstallGPURuntimeUnsupported(t *testing.T) {
+	p := newGPUTestProvisioner("boot2docker", "17.10.0-ce", map[string]string{})
+
+	err := installGPURuntime(p, engine.Options{GPURuntime: true})
+
+	assert.EqualError(t, err, "The GPU container runtime is not available for fake")
+}
+
+func TestInstallGPURuntimeDisabled(t *testing.T) {
+	p := newGPUTestProvisioner("boot2docker", "17.10.0-ce", map[string]string{})
+
+	assert.NoError(t, installGPURuntime(p, engine.Options{}))
+}
@@ -0,0 +1,81 @@
+package provision
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+var imageReferenceRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/:@-]*$`)
+
+// isImageArchive tells whether the image to preload is a local archive, as
+// docker save writes them, rather than a reference to pull.
+func isImageArchive(image string) bool {
+	info, err := os.Stat(image)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// ValidatePreloadImages checks that the images to preload are either local
+// archives or references to pull, e.g. redis:5 or quay.io/coreos/etcd.
+func ValidatePreloadImages(images []string) error {
+	for _, image := range images {
+		if !isImageArchive(image) && !imageReferenceRegexp.MatchString(image) {
+			return fmt.Errorf("Invalid image %q to preload, neither a local archive nor an image reference", image)
+		}
+	}
+
+	return nil
+}
+
+// PreloadImages pulls the images to preload, or loads the local archives
+// streamed over SSH, into the engine of the machine, for machines to come
+// up with the images their jobs need, e.g. in CI. The engine must be up.
+func PreloadImages(p Provisioner, images []string) error {
+	engineOptions := p.GetEngineOptions()
+
+	for _, image := range images {
+		if isImageArchive(image) {
+			log.Infof("Loading the images of %s...", image)
+			if err := loadImageArchive(p, &engineOptions, image); err != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Infof("Pulling %s...", image)
+		if output, err := p.SSHCommand(DockerCommand(p, &engineOptions, "pull "+image)); err != nil {
+			return fmt.Errorf("Error pulling %s: %s\n%s", image, err, output)
+		}
+	}
+
+	return nil
+}
+
+func loadImageArchive(p Provisioner, engineOptions *engine.Options, path string) error {
+	client, err := drivers.GetSSHClientFromDriver(p.GetDriver())
+	if err != nil {
+		return err
+	}
+
+	uploader, ok := client.(ssh.InputClient)
+	if !ok {
+		return fmt.Errorf("The SSH client cannot copy files to the machine")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if output, err := uploader.OutputWithInput(DockerCommand(p, engineOptions, "load"), file); err != nil {
+		return fmt.Errorf("Error loading the images of %s: %s\n%s", path, err, output)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package provision
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePreloadImages(t *testing.T) {
+	archive, err := ioutil.TempFile("", "machine-images")
+	assert.NoError(t, err)
+	archive.Close()
+	defer os.Remove(archive.Name())
+
+	assert.NoError(t, ValidatePreloadImages([]string{"redis:5", "quay.io/coreos/etcd:v3.3", "alpine@sha256:0123abcd", archive.Name()}))
+	assert.Error(t, ValidatePreloadImages([]string{"redis; reboot"}))
+	assert.Error(t, ValidatePreloadImages([]string{"/nonexistent/images.tar"}))
+}
+
+func TestPreloadImages(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{Responses: map[string]string{
+		"sudo docker pull redis:5":  "",
+		"sudo docker pull alpine:3": "",
+	}}
+
+	assert.NoError(t, PreloadImages(p, []string{"redis:5", "alpine:3"}))
+	assert.EqualError(t, PreloadImages(p, []string{"postgres:10"}), "Error pulling postgres:10: Command not registered in FakeSSHCommander\n")
+}
@@ -0,0 +1,303 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+)
+
+var (
+	// packageAttempts is how many times a package command failing because
+	// of a mirror is run, waiting packageBackoff, then twice as long, and
+	// so on, between the attempts.
+	packageAttempts = 5
+	packageBackoff  = 5 * time.Second
+
+	// transientPackageErrors are the messages of apt, yum, zypper, pacman
+	// and apk telling that a mirror could not be reached or served a
+	// broken file, which is usually fixed by trying again.
+	transientPackageErrors = []string{
+		"Temporary failure resolving",
+		"Could not resolve",
+		"Could not connect to",
+		"Failed to fetch",
+		"Unable to fetch some archives",
+		"Hash Sum mismatch",
+		"Connection timed out",
+		"Connection reset by peer",
+		"Cannot retrieve repository metadata",
+		"Cannot find a valid baseurl",
+		"Could not retrieve mirrorlist",
+		"No more mirrors to try",
+		"Timeout was reached",
+		"Download (curl) error",
+		"Valid metadata not found",
+		"failed retrieving file",
+		"temporary error (try again later)",
+		"network error",
+	}
+)
+
+// PackageManager installs and removes the packages of the system of a
+// machine.
+type PackageManager interface {
+	// Install installs the package, doing nothing if it is installed.
+	Install(name string) error
+
+	// Update installs the latest version of the package.
+	Update(name string) error
+
+	// Remove uninstalls the package.
+	Remove(name string) error
+
+	// IsInstalled reports whether the package is installed.
+	IsInstalled(name string) (bool, error)
+}
+
+// packagePurger is implemented by the package managers which can remove the
+// configuration files of the packages along with them.
+type packagePurger interface {
+	Purge(name string) error
+}
+
+// packageAction runs the action of a provisioner's Package with its package
+// manager.
+func packageAction(manager PackageManager, name string, action pkgaction.PackageAction) error {
+	log.Debugf("package: action=%s name=%s", action.String(), name)
+
+	switch action {
+	case pkgaction.Install:
+		return manager.Install(name)
+	case pkgaction.Upgrade:
+		return manager.Update(name)
+	case pkgaction.Remove:
+		return manager.Remove(name)
+	case pkgaction.Purge:
+		if purger, ok := manager.(packagePurger); ok {
+			return purger.Purge(name)
+		}
+		return manager.Remove(name)
+	}
+
+	return fmt.Errorf("Unknown package action %d", action)
+}
+
+// withPackageRetries runs a package operation, again while it fails because
+// of a mirror.
+func withPackageRetries(operation func() error) error {
+	backoff := packageBackoff
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil || attempt == packageAttempts || !isTransientPackageError(err) {
+			return err
+		}
+
+		log.Warnf("A package mirror failed, retrying in %s (attempt %d/%d)", backoff, attempt+1, packageAttempts)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func isTransientPackageError(err error) bool {
+	for _, message := range transientPackageErrors {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPackageInstalled runs a command exiting with 0 when the package is
+// installed. Its status is echoed for the failures of the command to be
+// told from the ones of SSH.
+func isPackageInstalled(ssh SSHCommander, command string) (bool, error) {
+	output, err := ssh.SSHCommand(fmt.Sprintf("if %s >/dev/null 2>&1; then echo installed; else echo missing; fi", command))
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(output) == "installed", nil
+}
+
+// packageNames maps the generic names of packages, e.g. docker, to the ones
+// of a distribution.
+type packageNames map[string]string
+
+func (names packageNames) of(name string) string {
+	if distributionName, ok := names[name]; ok {
+		return distributionName
+	}
+	return name
+}
+
+// aptPackageManager manages the packages of Debian and Ubuntu.
+type aptPackageManager struct {
+	SSHCommander
+	names packageNames
+
+	// options are extra options of apt-get.
+	options string
+}
+
+func (m *aptPackageManager) Install(name string) error {
+	return m.run("install", name, true)
+}
+
+func (m *aptPackageManager) Update(name string) error {
+	return m.run("install", name, true)
+}
+
+func (m *aptPackageManager) Remove(name string) error {
+	return m.run("remove", name, false)
+}
+
+func (m *aptPackageManager) Purge(name string) error {
+	return m.run("purge", name, false)
+}
+
+func (m *aptPackageManager) IsInstalled(name string) (bool, error) {
+	return isPackageInstalled(m, fmt.Sprintf("dpkg-query -W -f='${Status}' %s | grep -q 'install ok installed'", m.names.of(name)))
+}
+
+// run runs apt-get, after updating the package lists for installs. Both are
+// retried together as a broken mirror often lets the update succeed.
+func (m *aptPackageManager) run(command, name string, updateMetadata bool) error {
+	return withPackageRetries(func() error {
+		if updateMetadata {
+			if err := waitForLockAptGetUpdate(m); err != nil {
+				return err
+			}
+		}
+
+		_, err := m.SSHCommand(fmt.Sprintf("DEBIAN_FRONTEND=noninteractive sudo -E apt-get %s -y %s%s", command, m.options, m.names.of(name)))
+		return err
+	})
+}
+
+// yumPackageManager manages the packages of RedHat, CentOS, Fedora and
+// Oracle Linux.
+type yumPackageManager struct {
+	SSHCommander
+}
+
+func (m *yumPackageManager) Install(name string) error {
+	return m.run("install", name)
+}
+
+func (m *yumPackageManager) Update(name string) error {
+	return m.run("upgrade", name)
+}
+
+func (m *yumPackageManager) Remove(name string) error {
+	return m.run("remove", name)
+}
+
+func (m *yumPackageManager) IsInstalled(name string) (bool, error) {
+	return isPackageInstalled(m, fmt.Sprintf("rpm -q %s", name))
+}
+
+func (m *yumPackageManager) run(command, name string) error {
+	return withPackageRetries(func() error {
+		_, err := m.SSHCommand(fmt.Sprintf("sudo -E yum %s -y %s", command, name))
+		return err
+	})
+}
+
+// zypperPackageManager manages the packages of SUSE.
+type zypperPackageManager struct {
+	SSHCommander
+}
+
+// Install skips installed packages. This is an optimization that reduces
+// the provisioning time of certain systems in a significant way: "zypper
+// in" downloads the metadata of all the repositories that have never been
+// refreshed or have automatic refresh toggled and have not been refreshed
+// recently, which can take quite some time on machines that have been
+// pre-optimized for docker by including all the needed packages.
+func (m *zypperPackageManager) Install(name string) error {
+	if installed, err := m.IsInstalled(name); err == nil && installed {
+		log.Debugf("%s is already installed, skipping operation", name)
+		return nil
+	}
+
+	return m.run("in", name)
+}
+
+func (m *zypperPackageManager) Update(name string) error {
+	return m.run("up", name)
+}
+
+func (m *zypperPackageManager) Remove(name string) error {
+	return m.run("rm", name)
+}
+
+func (m *zypperPackageManager) IsInstalled(name string) (bool, error) {
+	return isPackageInstalled(m, fmt.Sprintf("rpm -q %s", name))
+}
+
+func (m *zypperPackageManager) run(command, name string) error {
+	return withPackageRetries(func() error {
+		_, err := m.SSHCommand(fmt.Sprintf("sudo -E zypper -n %s %s", command, name))
+		return err
+	})
+}
+
+// pacmanPackageManager manages the packages of Arch Linux.
+type pacmanPackageManager struct {
+	SSHCommander
+	names packageNames
+}
+
+func (m *pacmanPackageManager) Install(name string) error {
+	return m.run("-Sy", name)
+}
+
+func (m *pacmanPackageManager) Update(name string) error {
+	return m.run("-Sy", name)
+}
+
+func (m *pacmanPackageManager) Remove(name string) error {
+	return m.run("-R", name)
+}
+
+func (m *pacmanPackageManager) IsInstalled(name string) (bool, error) {
+	return isPackageInstalled(m, fmt.Sprintf("pacman -Q %s", m.names.of(name)))
+}
+
+func (m *pacmanPackageManager) run(options, name string) error {
+	return withPackageRetries(func() error {
+		_, err := m.SSHCommand(fmt.Sprintf("sudo -E pacman %s --noconfirm --noprogressbar %s", options, m.names.of(name)))
+		return err
+	})
+}
+
+// apkPackageManager manages the packages of Alpine.
+type apkPackageManager struct {
+	SSHCommander
+}
+
+func (m *apkPackageManager) Install(name string) error {
+	return m.run("sudo apk add --update " + name)
+}
+
+func (m *apkPackageManager) Update(name string) error {
+	return m.run("sudo apk add --update --upgrade " + name)
+}
+
+func (m *apkPackageManager) Remove(name string) error {
+	return m.run("sudo apk del " + name)
+}
+
+func (m *apkPackageManager) IsInstalled(name string) (bool, error) {
+	return isPackageInstalled(m, fmt.Sprintf("apk info -e %s", name))
+}
+
+func (m *apkPackageManager) run(command string) error {
+	return withPackageRetries(func() error {
+		_, err := m.SSHCommand(command)
+		return err
+	})
+}
@@ -0,0 +1,106 @@
+package provision
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakySSHCommander fails the commands with the given error a number of
+// times before running them.
+type flakySSHCommander struct {
+	failures int
+	err      error
+	commands []string
+	output   string
+}
+
+func (c *flakySSHCommander) SSHCommand(command string) (string, error) {
+	c.commands = append(c.commands, command)
+	if c.failures > 0 {
+		c.failures--
+		return "", c.err
+	}
+	return c.output, nil
+}
+
+func withoutPackageBackoff() func() {
+	backoff := packageBackoff
+	packageBackoff = 0
+	return func() { packageBackoff = backoff }
+}
+
+func TestAptPackageManagerRetriesMirrorFailures(t *testing.T) {
+	defer withoutPackageBackoff()()
+
+	commander := &flakySSHCommander{
+		failures: 2,
+		err:      errors.New("E: Failed to fetch http://deb.debian.org/debian/pool/main/c/curl.deb  Hash Sum mismatch"),
+	}
+	manager := &aptPackageManager{
+		SSHCommander: commander,
+		names:        packageNames{"docker": "docker-ce"},
+	}
+
+	assert.NoError(t, manager.Install("docker"))
+	assert.Equal(t, []string{
+		"sudo apt-get update",
+		"sudo apt-get update",
+		"sudo apt-get update",
+		"DEBIAN_FRONTEND=noninteractive sudo -E apt-get install -y docker-ce",
+	}, commander.commands)
+}
+
+func TestPackageManagerGivesUp(t *testing.T) {
+	defer withoutPackageBackoff()()
+
+	commander := &flakySSHCommander{
+		failures: packageAttempts,
+		err:      errors.New("Cannot find a valid baseurl for repo: base/7/x86_64"),
+	}
+
+	assert.Error(t, (&yumPackageManager{commander}).Install("curl"))
+	assert.Len(t, commander.commands, packageAttempts)
+}
+
+func TestPackageManagerDoesNotRetryOtherFailures(t *testing.T) {
+	commander := &flakySSHCommander{
+		failures: 1,
+		err:      errors.New("No package curlx available."),
+	}
+
+	assert.Error(t, (&yumPackageManager{commander}).Install("curlx"))
+	assert.Len(t, commander.commands, 1)
+}
+
+func TestPackageManagerIsInstalled(t *testing.T) {
+	commander := &flakySSHCommander{output: "installed\n"}
+	manager := &zypperPackageManager{commander}
+
+	installed, err := manager.IsInstalled("docker")
+	assert.NoError(t, err)
+	assert.True(t, installed)
+
+	assert.NoError(t, manager.Install("docker"))
+	assert.Len(t, commander.commands, 2, "installed packages are skipped")
+
+	commander.output = "missing\n"
+	installed, err = manager.IsInstalled("docker")
+	assert.NoError(t, err)
+	assert.False(t, installed)
+}
+
+func TestPackageAction(t *testing.T) {
+	commander := &flakySSHCommander{}
+
+	assert.NoError(t, packageAction(&aptPackageManager{SSHCommander: commander}, "curl", pkgaction.Purge))
+	assert.NoError(t, packageAction(&apkPackageManager{commander}, "curl", pkgaction.Purge))
+	assert.Error(t, packageAction(&apkPackageManager{commander}, "curl", pkgaction.PackageAction(42)))
+
+	assert.Equal(t, []string{
+		"DEBIAN_FRONTEND=noninteractive sudo -E apt-get purge -y curl",
+		"sudo apk del curl",
+	}, commander.commands)
+}
@@ -2,6 +2,8 @@ package provision
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/drivers"
@@ -53,6 +55,9 @@ type Provisioner interface {
 	// Get the swarm options associated with this host.
 	GetSwarmOptions() swarm.Options
 
+	// Get the engine options associated with this host.
+	GetEngineOptions() engine.Options
+
 	// Run a package action e.g. install
 	Package(name string, action pkgaction.PackageAction) error
 
@@ -87,6 +92,13 @@ type Provisioner interface {
 	GetOsReleaseInfo() (*OsRelease, error)
 }
 
+// EngineVersionInstaller is implemented by provisioners of distributions
+// shipping the engine with the OS, which install a given engine version
+// their own way rather than through the install script.
+type EngineVersionInstaller interface {
+	InstallEngineVersion(version string) error
+}
+
 // RegisteredProvisioner creates a new provisioner
 type RegisteredProvisioner struct {
 	New func(d drivers.Driver) Provisioner
@@ -100,33 +112,122 @@ func DetectProvisioner(d drivers.Driver) (Provisioner, error) {
 	return detector.DetectProvisioner(d)
 }
 
+// Detected is what is needed to create the provisioner of a machine again
+// without connecting to it: the name the provisioner is registered with and
+// the content of /etc/os-release on the machine.
+type Detected struct {
+	Name      string
+	OsRelease *OsRelease
+}
+
+// Names returns the names of the registered provisioners, sorted.
+func Names() []string {
+	names := []string{}
+	for name := range provisioners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// lookup returns the name and the registered provisioner matching the name,
+// regardless of the case.
+func lookup(name string) (string, *RegisteredProvisioner, error) {
+	for registeredName, p := range provisioners {
+		if strings.EqualFold(registeredName, name) {
+			return registeredName, p, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("Unknown provisioner %q, use one of %s", name, strings.Join(Names(), ", "))
+}
+
+// ValidateName checks that a provisioner is registered with the name.
+func ValidateName(name string) error {
+	_, _, err := lookup(name)
+	return err
+}
+
+// NewProvisioner creates the provisioner detected earlier.
+func NewProvisioner(detected *Detected, d drivers.Driver) (Provisioner, error) {
+	_, p, err := lookup(detected.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	provisioner := p.New(d)
+	if detected.OsRelease != nil {
+		provisioner.SetOsReleaseInfo(detected.OsRelease)
+	}
+
+	return provisioner, nil
+}
+
+// Detect detects the provisioner of the machine like DetectProvisioner and
+// tells how to create it again with NewProvisioner. When forced is set, the
+// provisioner registered with that name is used whatever the OS of the
+// machine. Detectors set with SetDetector other than the standard one are
+// asked for the provisioner, which cannot be created again then.
+func Detect(d drivers.Driver, forced string) (*Detected, Provisioner, error) {
+	switch standard := detector.(type) {
+	case *StandardDetector:
+		return standard.detect(d, forced)
+	case StandardDetector:
+		return standard.detect(d, forced)
+	}
+
+	provisioner, err := detector.DetectProvisioner(d)
+	return nil, provisioner, err
+}
+
 func (detector StandardDetector) DetectProvisioner(d drivers.Driver) (Provisioner, error) {
+	_, provisioner, err := detector.detect(d, "")
+	return provisioner, err
+}
+
+func (detector StandardDetector) detect(d drivers.Driver, forced string) (*Detected, Provisioner, error) {
+	var forcedProvisioner *RegisteredProvisioner
+	if forced != "" {
+		name, p, err := lookup(forced)
+		if err != nil {
+			return nil, nil, err
+		}
+		forced, forcedProvisioner = name, p
+	}
+
 	log.Info("Waiting for SSH to be available...")
 	if err := drivers.WaitForSSH(d); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	log.Info("Detecting the provisioner...")
 
 	osReleaseOut, err := drivers.RunSSHCommandFromDriver(d, "cat /etc/os-release")
 	if err != nil {
-		return nil, fmt.Errorf("Error getting SSH command: %s", err)
+		return nil, nil, fmt.Errorf("Error getting SSH command: %s", err)
 	}
 
 	osReleaseInfo, err := NewOsRelease([]byte(osReleaseOut))
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing /etc/os-release file: %s", err)
+		return nil, nil, fmt.Errorf("Error parsing /etc/os-release file: %s", err)
+	}
+
+	if forcedProvisioner != nil {
+		provisioner := forcedProvisioner.New(d)
+		provisioner.SetOsReleaseInfo(osReleaseInfo)
+		log.Debugf("using the %s provisioner on %s", forced, osReleaseInfo.ID)
+		return &Detected{Name: forced, OsRelease: osReleaseInfo}, provisioner, nil
 	}
 
-	for _, p := range provisioners {
+	for name, p := range provisioners {
 		provisioner := p.New(d)
 		provisioner.SetOsReleaseInfo(osReleaseInfo)
 
 		if provisioner.CompatibleWithHost() {
 			log.Debugf("found compatible host: %s", osReleaseInfo.ID)
-			return provisioner, nil
+			return &Detected{Name: name, OsRelease: osReleaseInfo}, provisioner, nil
 		}
 	}
 
-	return nil, ErrDetectionFailed
+	return nil, nil, ErrDetectionFailed
 }
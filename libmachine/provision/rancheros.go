@@ -2,6 +2,7 @@ package provision
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -19,15 +20,8 @@ import (
 )
 
 const (
-	versionsURL  = "http://releases.rancher.com/os/versions.yml"
-	isoURL       = "https://github.com/rancherio/os/releases/download/%s/machine-rancheros.iso"
-	hostnameTmpl = `sudo mkdir -p /var/lib/rancher/conf/cloud-config.d/  
-sudo tee /var/lib/rancher/conf/cloud-config.d/machine-hostname.yml << EOF
-#cloud-config
-
-hostname: %s
-EOF
-`
+	versionsURL = "http://releases.rancher.com/os/versions.yml"
+	isoURL      = "https://github.com/rancherio/os/releases/download/%s/machine-rancheros.iso"
 )
 
 func init() {
@@ -36,14 +30,17 @@ func init() {
 	})
 }
 
+// NewRancherProvisioner creates a provisioner of RancherOS machines. The
+// user Docker runs as a container of the system Docker and is configured
+// through the RancherOS configuration, see GenerateDockerOptions. The
+// certificates are kept in /var/lib/rancher/conf, which is persisted.
 func NewRancherProvisioner(d drivers.Driver) Provisioner {
 	return &RancherProvisioner{
 		GenericProvisioner{
-			SSHCommander:      GenericSSHCommander{Driver: d},
-			DockerOptionsDir:  "/var/lib/rancher/conf",
-			DaemonOptionsFile: "/var/lib/rancher/conf/docker",
-			OsReleaseID:       "rancheros",
-			Driver:            d,
+			SSHCommander:     GenericSSHCommander{Driver: d},
+			DockerOptionsDir: "/var/lib/rancher/conf",
+			OsReleaseID:      "rancheros",
+			Driver:           d,
 		},
 	}
 }
@@ -75,15 +72,15 @@ func (provisioner *RancherProvisioner) Package(name string, action pkgaction.Pac
 
 	switch action {
 	case pkgaction.Install:
-		packageAction = "enabled"
-	case pkgaction.Remove:
+		packageAction = "enable"
+	case pkgaction.Remove, pkgaction.Purge:
 		packageAction = "disable"
 	case pkgaction.Upgrade:
 		// TODO: support upgrade
 		packageAction = "upgrade"
 	}
 
-	command := fmt.Sprintf("sudo rancherctl service %s %s", packageAction, name)
+	command := fmt.Sprintf("sudo ros service %s %s", packageAction, name)
 
 	if _, err := provisioner.SSHCommand(command); err != nil {
 		return err
@@ -127,7 +124,21 @@ func (provisioner *RancherProvisioner) Provision(swarmOptions swarm.Options, aut
 		}
 	}
 
-	log.Debugf("Preparing certificates")
+	if engineOptions.InstallVersion != "" {
+		log.Debugf("Selecting docker engine version: %s", engineOptions.InstallVersion)
+		if err := provisioner.InstallEngineVersion(engineOptions.InstallVersion); err != nil {
+			return err
+		}
+	}
+
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debugf("Setting up certificates")
@@ -150,20 +161,95 @@ func (provisioner *RancherProvisioner) SetHostname(hostname string) error {
 		return err
 	}
 
-	if _, err := provisioner.SSHCommand(fmt.Sprintf(hostnameTmpl, hostname)); err != nil {
+	// Persist the hostname across reboots, /etc/hostname is not
+	if _, err := provisioner.SSHCommand(fmt.Sprintf("sudo ros config set hostname %s", hostname)); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// GenerateDockerOptions returns the ros config commands that configure the
+// user Docker, one per line. RancherOS starts it with the arguments of its
+// configuration rather than from a file of daemon options: rancher.docker.tls
+// makes it listen with the tls_args, the remaining flags go to extra_args.
+func (provisioner *RancherProvisioner) GenerateDockerOptions(dockerPort int) (*DockerOptions, error) {
+	driverNameLabel := fmt.Sprintf("provider=%s", provisioner.Driver.DriverName())
+	provisioner.EngineOptions.Labels = append(provisioner.EngineOptions.Labels, driverNameLabel)
+
+	engineOptions := withDaemonFlags(withClusterStore(withProxyEnv(provisioner.EngineOptions, provisioner.Driver), provisioner.SwarmOptions, provisioner.AuthOptions, provisioner.Driver, dockerPort))
+
+	tlsArgs := []string{
+		"--tlsverify",
+		"--tlscacert=" + provisioner.AuthOptions.CaCertRemotePath,
+		"--tlscert=" + provisioner.AuthOptions.ServerCertRemotePath,
+		"--tlskey=" + provisioner.AuthOptions.ServerKeyRemotePath,
+		fmt.Sprintf("--host=tcp://%s:%d", engineBindAddress(provisioner.Driver), dockerPort),
+	}
+
+	extraArgs := []string{"--storage-driver=" + engineOptions.StorageDriver}
+	for _, label := range engineOptions.Labels {
+		extraArgs = append(extraArgs, "--label="+label)
+	}
+	for _, registry := range engineOptions.InsecureRegistry {
+		extraArgs = append(extraArgs, "--insecure-registry="+registry)
+	}
+	for _, mirror := range engineOptions.RegistryMirror {
+		extraArgs = append(extraArgs, "--registry-mirror="+mirror)
+	}
+	for _, flag := range engineOptions.ArbitraryFlags {
+		extraArgs = append(extraArgs, "--"+flag)
+	}
+
+	environment := engineOptions.Env
+	if environment == nil {
+		environment = []string{}
+	}
+
+	settings := []struct {
+		key   string
+		value interface{}
+	}{
+		{"rancher.docker.tls", true},
+		{"rancher.docker.tls_args", tlsArgs},
+		{"rancher.docker.extra_args", extraArgs},
+		{"rancher.docker.environment", environment},
+	}
+
+	commands := []string{}
+	for _, setting := range settings {
+		// JSON is valid YAML, which ros config set parses the values as
+		value, err := json.Marshal(setting.value)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, fmt.Sprintf("sudo ros config set %s %s", setting.key, singleQuote(string(value))))
+	}
+
+	return &DockerOptions{
+		EngineOptions: strings.Join(commands, "\n"),
+	}, nil
+}
+
+// WriteEngineConfig runs the ros config commands of GenerateDockerOptions.
+// The user Docker picks them up when ConfigureAuth starts it again.
+func (provisioner *RancherProvisioner) WriteEngineConfig(dockerOptions *DockerOptions) error {
+	for _, command := range strings.Split(dockerOptions.EngineOptions, "\n") {
+		if output, err := provisioner.SSHCommand(command); err != nil {
+			return fmt.Errorf("Error configuring the user Docker: %s\n%s", err, output)
+		}
+	}
+
+	return nil
+}
+
 func (provisioner *RancherProvisioner) upgrade() error {
 	switch provisioner.Driver.DriverName() {
 	case "virtualbox":
 		return provisioner.upgradeIso()
 	default:
 		log.Infof("Running upgrade")
-		if _, err := provisioner.SSHCommand("sudo rancherctl os upgrade -f --no-reboot"); err != nil {
+		if _, err := provisioner.SSHCommand("sudo ros os upgrade -f --no-reboot"); err != nil {
 			return err
 		}
 
@@ -240,6 +326,16 @@ func (provisioner *RancherProvisioner) getLatestISOURL() (string, error) {
 	return "", fmt.Errorf("Failed to find current version")
 }
 
+// InstallEngineVersion switches the machine to the given engine version,
+// among the ones RancherOS provides.
+func (provisioner *RancherProvisioner) InstallEngineVersion(version string) error {
+	if _, err := provisioner.SSHCommand(fmt.Sprintf("sudo ros engine switch docker-%s", version)); err != nil {
+		return fmt.Errorf("Error switching to engine %s: %s", version, err)
+	}
+
+	return nil
+}
+
 func selectDocker(p Provisioner, baseURL string) error {
 	// TODO: detect if its a cloud-init, or a ros setting - and use that..
 	if output, err := p.SSHCommand(fmt.Sprintf("wget -O- %s | sh -", baseURL)); err != nil {
@@ -248,3 +344,9 @@ func selectDocker(p Provisioner, baseURL string) error {
 
 	return nil
 }
+
+// singleQuote quotes a value for the shell, closing and reopening the quotes
+// around the single quotes it contains.
+func singleQuote(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
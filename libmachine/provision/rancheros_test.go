@@ -0,0 +1,71 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRancherProvisioner() *RancherProvisioner {
+	p := NewRancherProvisioner(&fakedriver.Driver{}).(*RancherProvisioner)
+	p.AuthOptions = auth.Options{
+		CaCertRemotePath:     "/var/lib/rancher/conf/ca.pem",
+		ServerCertRemotePath: "/var/lib/rancher/conf/server.pem",
+		ServerKeyRemotePath:  "/var/lib/rancher/conf/server-key.pem",
+	}
+	p.EngineOptions = engine.Options{
+		StorageDriver:  "overlay",
+		RegistryMirror: []string{"https://mirror.example.com"},
+		ArbitraryFlags: []string{"debug"},
+		Env:            []string{"FOO=it's"},
+	}
+	return p
+}
+
+func TestRancherGenerateDockerOptions(t *testing.T) {
+	p := newTestRancherProvisioner()
+
+	dockerOptions, err := p.GenerateDockerOptions(2376)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", dockerOptions.EngineOptionsPath)
+	assert.Equal(t, `sudo ros config set rancher.docker.tls 'true'
+sudo ros config set rancher.docker.tls_args '["--tlsverify","--tlscacert=/var/lib/rancher/conf/ca.pem","--tlscert=/var/lib/rancher/conf/server.pem","--tlskey=/var/lib/rancher/conf/server-key.pem","--host=tcp://0.0.0.0:2376"]'
+sudo ros config set rancher.docker.extra_args '["--storage-driver=overlay","--label=provider=Driver","--registry-mirror=https://mirror.example.com","--debug"]'
+sudo ros config set rancher.docker.environment '["FOO=it'\''s"]'`, dockerOptions.EngineOptions)
+}
+
+func TestRancherWriteEngineConfig(t *testing.T) {
+	p := newTestRancherProvisioner()
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"sudo ros config set rancher.docker.tls 'true'":       "",
+			"sudo ros config set rancher.docker.environment '[]'": "",
+		},
+	}
+
+	assert.NoError(t, p.WriteEngineConfig(&DockerOptions{
+		EngineOptions: "sudo ros config set rancher.docker.tls 'true'\nsudo ros config set rancher.docker.environment '[]'",
+	}))
+	assert.Error(t, p.WriteEngineConfig(&DockerOptions{
+		EngineOptions: "sudo ros config set rancher.docker.extra_args '[]'",
+	}))
+}
+
+func TestRancherPackage(t *testing.T) {
+	p := newTestRancherProvisioner()
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"sudo ros service enable kernel-headers":  "",
+			"sudo ros service disable kernel-headers": "",
+		},
+	}
+
+	assert.NoError(t, p.Package("kernel-headers", pkgaction.Install))
+	assert.NoError(t, p.Package("kernel-headers", pkgaction.Remove))
+}
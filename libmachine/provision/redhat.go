@@ -21,7 +21,7 @@ var (
 	ErrUnknownYumOsRelease = errors.New("unknown OS for Yum repository")
 	engineConfigTemplate   = `[Service]
 ExecStart=
-ExecStart=/usr/bin/dockerd -H tcp://0.0.0.0:{{.DockerPort}} -H unix:///var/run/docker.sock --storage-driver {{.EngineOptions.StorageDriver}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}} {{ range .EngineOptions.Labels }}--label {{.}} {{ end }}{{ range .EngineOptions.InsecureRegistry }}--insecure-registry {{.}} {{ end }}{{ range .EngineOptions.RegistryMirror }}--registry-mirror {{.}} {{ end }}{{ range .EngineOptions.ArbitraryFlags }}--{{.}} {{ end }}
+ExecStart=/usr/bin/dockerd -H tcp://{{.BindAddress}}:{{.DockerPort}} {{ if not .EngineOptions.DisableUnixSocket }}-H unix:///var/run/docker.sock {{ end }}--storage-driver {{.EngineOptions.StorageDriver}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}} {{ range .EngineOptions.Labels }}--label {{.}} {{ end }}{{ range .EngineOptions.InsecureRegistry }}--insecure-registry {{.}} {{ end }}{{ range .EngineOptions.RegistryMirror }}--registry-mirror {{.}} {{ end }}{{ range .EngineOptions.ArbitraryFlags }}--{{.}} {{ end }}
 Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 `
 	majorVersionRE = regexp.MustCompile(`^(\d+)(\..*)?`)
@@ -78,31 +78,17 @@ func (provisioner *RedHatProvisioner) SetHostname(hostname string) error {
 	return nil
 }
 
-func (provisioner *RedHatProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	switch action {
-	case pkgaction.Install:
-		packageAction = "install"
-	case pkgaction.Remove:
-		packageAction = "remove"
-	case pkgaction.Purge:
-		packageAction = "remove"
-	case pkgaction.Upgrade:
-		packageAction = "upgrade"
-	}
-
-	command := fmt.Sprintf("sudo -E yum %s -y %s", packageAction, name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
-	}
+// PackageManager returns the yum package manager of the machine.
+func (provisioner *RedHatProvisioner) PackageManager() PackageManager {
+	return &yumPackageManager{provisioner}
+}
 
-	return nil
+func (provisioner *RedHatProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func installDocker(provisioner *RedHatProvisioner) error {
-	if err := installDockerGeneric(provisioner, provisioner.EngineOptions.InstallURL); err != nil {
+	if err := installDockerGeneric(provisioner, provisioner.EngineOptions); err != nil {
 		return err
 	}
 
@@ -117,9 +103,9 @@ func installDocker(provisioner *RedHatProvisioner) error {
 func (provisioner *RedHatProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -169,6 +155,14 @@ func (provisioner *RedHatProvisioner) Provision(swarmOptions swarm.Options, auth
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	if err := ConfigureAuth(provisioner); err != nil {
@@ -197,8 +191,9 @@ func (provisioner *RedHatProvisioner) GenerateDockerOptions(dockerPort int) (*Do
 
 	engineConfigContext := EngineConfigContext{
 		DockerPort:       dockerPort,
+		BindAddress:      engineBindAddress(provisioner.Driver),
 		AuthOptions:      provisioner.AuthOptions,
-		EngineOptions:    provisioner.EngineOptions,
+		EngineOptions:    withDaemonFlags(withClusterStore(withProxyEnv(provisioner.EngineOptions, provisioner.Driver), provisioner.SwarmOptions, provisioner.AuthOptions, provisioner.Driver, dockerPort)),
 		DockerOptionsDir: provisioner.DockerOptionsDir,
 	}
 
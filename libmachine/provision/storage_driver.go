@@ -0,0 +1,175 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+)
+
+// storageRequirement is what a storage driver needs of the machine.
+type storageRequirement struct {
+	// filesystem is the name the kernel lists the filesystem of the driver
+	// under in /proc/filesystems, empty for the drivers not mounting one.
+	filesystem string
+
+	// module is the kernel module providing the driver.
+	module string
+
+	// minKernel is the oldest kernel the driver works with.
+	minKernel kernelVersion
+
+	// backingFilesystem is the filesystem /var/lib/docker must be on.
+	backingFilesystem string
+}
+
+var storageRequirements = map[string]storageRequirement{
+	"aufs":         {filesystem: "aufs", module: "aufs"},
+	"overlay":      {filesystem: "overlay", module: "overlay", minKernel: kernelVersion{3, 18, 0}},
+	"overlay2":     {filesystem: "overlay", module: "overlay", minKernel: kernelVersion{4, 0, 0}},
+	"devicemapper": {module: "dm_thin_pool"},
+	"btrfs":        {filesystem: "btrfs", module: "btrfs", backingFilesystem: "btrfs"},
+	"zfs":          {filesystem: "zfs", module: "zfs", backingFilesystem: "zfs"},
+}
+
+// storageModulesConf is where the module of the storage driver is listed for
+// systemd to load it on boot.
+const storageModulesConf = "/etc/modules-load.d/docker-storage.conf"
+
+// kernelVersion is the major, minor and patch version of a Linux kernel.
+type kernelVersion [3]int
+
+var (
+	reKernelRelease = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?`)
+	reRHEL7Kernel   = regexp.MustCompile(`^3\.10\.0-(\d+)\..*el7`)
+	reBlockDevice   = regexp.MustCompile(`^/dev/[\w./-]+$`)
+)
+
+// parseKernelRelease parses the version of a kernel release as uname -r
+// prints it, e.g. 4.15.0-1021-aws.
+func parseKernelRelease(release string) (kernelVersion, error) {
+	match := reKernelRelease.FindStringSubmatch(strings.TrimSpace(release))
+	if match == nil {
+		return kernelVersion{}, fmt.Errorf("Unexpected kernel release %q", release)
+	}
+
+	var version kernelVersion
+	for i, part := range match[1:] {
+		if part != "" {
+			version[i], _ = strconv.Atoi(part)
+		}
+	}
+	return version, nil
+}
+
+func (v kernelVersion) less(other kernelVersion) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+	return false
+}
+
+func (v kernelVersion) String() string {
+	return fmt.Sprintf("%d.%d", v[0], v[1])
+}
+
+// overlay2Backported tells whether the kernel is one of RHEL 7.3 or later,
+// to which Red Hat backported overlay2 from 4.0.
+func overlay2Backported(release string) bool {
+	match := reRHEL7Kernel.FindStringSubmatch(release)
+	if match == nil {
+		return false
+	}
+	build, _ := strconv.Atoi(match[1])
+	return build >= 514
+}
+
+// prepareStorageDriver checks that the kernel of the machine supports the
+// storage driver of the engine and loads its module, on boot too. With
+// devicemapper on a StorageDevice, it installs the LVM tools for the daemon
+// to allocate its thin pool on the device. The storage drivers with no
+// known requirement, e.g. vfs, are left to the daemon.
+func prepareStorageDriver(p Provisioner, engineOptions *engine.Options) error {
+	driver := engineOptions.StorageDriver
+
+	if engineOptions.StorageDevice != "" && driver != "devicemapper" {
+		return fmt.Errorf("A storage device is only used by the devicemapper storage driver, not %s", driver)
+	}
+
+	requirement, ok := storageRequirements[driver]
+	if !ok {
+		return nil
+	}
+
+	log.Debugf("Preparing the %s storage driver", driver)
+
+	release, err := p.SSHCommand("uname -r")
+	if err != nil {
+		return fmt.Errorf("Error getting the kernel release: %s", err)
+	}
+	release = strings.TrimSpace(release)
+	version, err := parseKernelRelease(release)
+	if err != nil {
+		return err
+	}
+	if version.less(requirement.minKernel) && !(driver == "overlay2" && overlay2Backported(release)) {
+		return fmt.Errorf("The %s storage driver needs a kernel %s or newer, the machine runs %s", driver, requirement.minKernel, release)
+	}
+
+	loadModule := fmt.Sprintf("sudo modprobe %s", requirement.module)
+	if requirement.filesystem != "" {
+		loadModule = fmt.Sprintf("grep -qw %s /proc/filesystems || %s", requirement.filesystem, loadModule)
+	}
+	if output, err := p.SSHCommand(loadModule); err != nil {
+		return fmt.Errorf("The kernel %s does not support the %s storage driver: %s\n%s", release, driver, err, output)
+	}
+	if _, err := p.SSHCommand(fmt.Sprintf("if [ -d /etc/modules-load.d ]; then echo %s | sudo tee %s >/dev/null; fi", requirement.module, storageModulesConf)); err != nil {
+		log.Warnf("Error setting the %s module to load on boot: %s", requirement.module, err)
+	}
+
+	if requirement.backingFilesystem != "" {
+		filesystem, err := getFilesystemType(p, "/var/lib")
+		if err != nil {
+			return err
+		}
+		if filesystem != requirement.backingFilesystem {
+			return fmt.Errorf("The %s storage driver needs /var/lib/docker on a %s filesystem, not %s", driver, requirement.backingFilesystem, filesystem)
+		}
+	}
+
+	if engineOptions.StorageDevice != "" {
+		return prepareThinPoolDevice(p, engineOptions)
+	}
+
+	return nil
+}
+
+// prepareThinPoolDevice has the daemon allocate the thin pool of
+// devicemapper on the StorageDevice, the direct-lvm mode of production
+// setups, rather than on loopback files.
+func prepareThinPoolDevice(p Provisioner, engineOptions *engine.Options) error {
+	device := engineOptions.StorageDevice
+	if !reBlockDevice.MatchString(device) {
+		return fmt.Errorf("Invalid storage device %q, expected a path in /dev", device)
+	}
+
+	if _, err := p.SSHCommand(fmt.Sprintf("test -b %s", device)); err != nil {
+		return fmt.Errorf("%s is not a block device of the machine", device)
+	}
+
+	if err := p.Package("lvm2", pkgaction.Install); err != nil {
+		return fmt.Errorf("Error installing lvm2: %s", err)
+	}
+	if _, err := p.SSHCommand("type lvcreate && type thin_check"); err != nil {
+		return fmt.Errorf("The LVM and thin provisioning tools are needed on the machine to use %s", device)
+	}
+
+	engineOptions.ArbitraryFlags = append(engineOptions.ArbitraryFlags, "storage-opt dm.directlvm_device="+device)
+	return nil
+}
@@ -0,0 +1,80 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStorageDriverTestProvisioner(responses map[string]string) Provisioner {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{Responses: responses}
+	return p
+}
+
+func TestParseKernelRelease(t *testing.T) {
+	version, err := parseKernelRelease("4.15.0-1021-aws\n")
+	assert.NoError(t, err)
+	assert.Equal(t, kernelVersion{4, 15, 0}, version)
+
+	version, err = parseKernelRelease("3.18")
+	assert.NoError(t, err)
+	assert.Equal(t, kernelVersion{3, 18, 0}, version)
+	assert.True(t, version.less(kernelVersion{4, 0, 0}))
+	assert.False(t, version.less(kernelVersion{3, 18, 0}))
+
+	_, err = parseKernelRelease("unknown")
+	assert.Error(t, err)
+}
+
+func TestPrepareStorageDriver(t *testing.T) {
+	loadOverlay := "grep -qw overlay /proc/filesystems || sudo modprobe overlay"
+	persistOverlay := "if [ -d /etc/modules-load.d ]; then echo overlay | sudo tee /etc/modules-load.d/docker-storage.conf >/dev/null; fi"
+
+	p := newStorageDriverTestProvisioner(map[string]string{
+		"uname -r":     "4.9.0-8-amd64\n",
+		loadOverlay:    "",
+		persistOverlay: "",
+	})
+	assert.NoError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "overlay2"}))
+
+	p = newStorageDriverTestProvisioner(map[string]string{
+		"uname -r":     "3.10.0-862.el7.x86_64\n",
+		loadOverlay:    "",
+		persistOverlay: "",
+	})
+	assert.NoError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "overlay2"}), "RHEL 7 kernels have overlay2")
+
+	p = newStorageDriverTestProvisioner(map[string]string{"uname -r": "3.16.0-4-amd64\n"})
+	assert.EqualError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "overlay2"}), "The overlay2 storage driver needs a kernel 4.0 or newer, the machine runs 3.16.0-4-amd64")
+
+	p = newStorageDriverTestProvisioner(map[string]string{"uname -r": "4.9.0-8-amd64\n"})
+	assert.Error(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "aufs"}), "the aufs module cannot be loaded")
+
+	p = newStorageDriverTestProvisioner(map[string]string{
+		"uname -r": "4.9.0-8-amd64\n",
+		"grep -qw btrfs /proc/filesystems || sudo modprobe btrfs":                                                          "",
+		"if [ -d /etc/modules-load.d ]; then echo btrfs | sudo tee /etc/modules-load.d/docker-storage.conf >/dev/null; fi": "",
+		"stat -f -c %T /var/lib": "ext2/ext3\n",
+	})
+	assert.EqualError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "btrfs"}), "The btrfs storage driver needs /var/lib/docker on a btrfs filesystem, not ext2/ext3")
+}
+
+func TestPrepareStorageDriverDevice(t *testing.T) {
+	p := newStorageDriverTestProvisioner(map[string]string{})
+
+	assert.EqualError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "overlay2", StorageDevice: "/dev/xvdf"}), "A storage device is only used by the devicemapper storage driver, not overlay2")
+	assert.NoError(t, prepareStorageDriver(p, &engine.Options{StorageDriver: "vfs"}), "drivers with no known requirement are left to the daemon")
+
+	engineOptions := &engine.Options{StorageDriver: "devicemapper", StorageDevice: "/dev/xvdf; reboot"}
+	p = newStorageDriverTestProvisioner(map[string]string{
+		"uname -r":                   "4.9.0-8-amd64\n",
+		"sudo modprobe dm_thin_pool": "",
+		"if [ -d /etc/modules-load.d ]; then echo dm_thin_pool | sudo tee /etc/modules-load.d/docker-storage.conf >/dev/null; fi": "",
+	})
+	assert.EqualError(t, prepareStorageDriver(p, engineOptions), `Invalid storage device "/dev/xvdf; reboot", expected a path in /dev`)
+	assert.Empty(t, engineOptions.ArbitraryFlags)
+}
@@ -56,47 +56,21 @@ func (provisioner *SUSEProvisioner) String() string {
 	return "openSUSE"
 }
 
-func (provisioner *SUSEProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	switch action {
-	case pkgaction.Install:
-		packageAction = "in"
-		// This is an optimization that reduces the provisioning time of certain
-		// systems in a significant way.
-		// The invocation of "zypper in <pkg>" causes the download of the metadata
-		// of all the repositories that have never been refreshed or that have
-		// automatic refresh toggled and have not been refreshed recently.
-		// Refreshing the repository metadata can take quite some time and can cause
-		// longer provisioning times for machines that have been pre-optimized for
-		// docker by including all the needed packages.
-		if _, err := provisioner.SSHCommand(fmt.Sprintf("rpm -q %s", name)); err == nil {
-			log.Debugf("%s is already installed, skipping operation", name)
-			return nil
-		}
-	case pkgaction.Remove:
-		packageAction = "rm"
-	case pkgaction.Upgrade:
-		packageAction = "up"
-	}
-
-	command := fmt.Sprintf("sudo -E zypper -n %s %s", packageAction, name)
-
-	log.Debugf("zypper: action=%s name=%s", action.String(), name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
-	}
+// PackageManager returns the zypper package manager of the machine.
+func (provisioner *SUSEProvisioner) PackageManager() PackageManager {
+	return &zypperPackageManager{provisioner}
+}
 
-	return nil
+func (provisioner *SUSEProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func (provisioner *SUSEProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -173,7 +147,7 @@ func (provisioner *SUSEProvisioner) Provision(swarmOptions swarm.Options, authOp
 	// Is yast2 firewall installed?
 	if _, installed := provisioner.SSHCommand("rpm -q yast2-firewall"); installed == nil {
 		// Open the firewall port required by docker
-		if _, err := provisioner.SSHCommand("sudo -E /sbin/yast2 firewall services add ipprotocol=tcp tcpport=2376 zone=EXT"); err != nil {
+		if _, err := provisioner.SSHCommand(fmt.Sprintf("sudo -E /sbin/yast2 firewall services add ipprotocol=tcp tcpport=%d zone=EXT", provisioner.EngineOptions.DaemonPort())); err != nil {
 			return err
 		}
 	}
@@ -188,6 +162,14 @@ func (provisioner *SUSEProvisioner) Provision(swarmOptions swarm.Options, authOp
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debug("Configuring auth")
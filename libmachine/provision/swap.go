@@ -0,0 +1,85 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+const (
+	// swapFile is the file the swap of the machine is allocated in.
+	swapFile = "/swapfile"
+
+	// swapSysctlConf is the file of the swappiness. It is read before the
+	// one of the sysctls, for a vm.swappiness sysctl to win.
+	swapSysctlConf = "/etc/sysctl.d/89-docker-machine-swap.conf"
+)
+
+// Swap is the swap of a machine, many small cloud instances having none and
+// running out of memory during image builds.
+type Swap struct {
+	// Size is the size of the swap file in MB, zero to not add one.
+	Size int
+
+	// Swappiness is the vm.swappiness of the kernel, -1 to keep the
+	// default of the system.
+	Swappiness int
+}
+
+// Validate checks the size and the swappiness.
+func (s Swap) Validate() error {
+	if s.Size < 0 {
+		return fmt.Errorf("Invalid swap size %d MB", s.Size)
+	}
+	if s.Swappiness < -1 || s.Swappiness > 200 {
+		return fmt.Errorf("Invalid swappiness %d, it must be between 0 and 200", s.Swappiness)
+	}
+	return nil
+}
+
+// ConfigureSwap allocates the swap file, enables it on boot too, and sets
+// the swappiness. A swap file of another size is replaced. The systems the
+// root filesystem of which is in memory, e.g. boot2docker, cannot get a swap
+// file.
+func ConfigureSwap(p SSHCommander, swap *Swap) error {
+	if swap == nil {
+		return nil
+	}
+
+	log.Info("Configuring the swap...")
+
+	if output, err := p.SSHCommand(configureSwapCommand(*swap)); err != nil {
+		return fmt.Errorf("Error configuring the swap: %s\n%s", err, output)
+	}
+
+	return nil
+}
+
+func configureSwapCommand(swap Swap) string {
+	commands := []string{"set -e"}
+
+	if swap.Size > 0 {
+		commands = append(commands, fmt.Sprintf(`case "$(stat -f -c %%T /)" in tmpfs|ramfs)
+	echo 'The root filesystem is in memory, a swap file cannot be added' >&2
+	exit 1
+esac
+if [ "$(stat -c %%s %[1]s 2>/dev/null)" != "%[2]d" ]; then
+	sudo swapoff %[1]s 2>/dev/null || true
+	sudo rm -f %[1]s
+	sudo fallocate -l %[3]dM %[1]s 2>/dev/null || sudo dd if=/dev/zero of=%[1]s bs=1M count=%[3]d 2>/dev/null
+	sudo chmod 600 %[1]s
+	sudo mkswap %[1]s >/dev/null
+fi
+grep -q '^%[1]s ' /proc/swaps || sudo swapon %[1]s
+grep -q '^%[1]s ' /etc/fstab || echo '%[1]s none swap sw 0 0' | sudo tee -a /etc/fstab >/dev/null`, swapFile, int64(swap.Size)*1024*1024, swap.Size))
+	}
+
+	if swap.Swappiness >= 0 {
+		commands = append(commands, fmt.Sprintf(`sudo mkdir -p /etc/sysctl.d
+echo 'vm.swappiness = %[1]d' | sudo tee %[2]s >/dev/null
+sudo sysctl -w vm.swappiness=%[1]d >/dev/null`, swap.Swappiness, swapSysctlConf))
+	}
+
+	return strings.Join(commands, "\n")
+}
@@ -0,0 +1,36 @@
+package provision
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwapValidate(t *testing.T) {
+	assert.NoError(t, Swap{Size: 2048, Swappiness: -1}.Validate())
+	assert.NoError(t, Swap{Swappiness: 0}.Validate())
+	assert.Error(t, Swap{Size: -1, Swappiness: -1}.Validate())
+	assert.Error(t, Swap{Swappiness: 300}.Validate())
+}
+
+func TestConfigureSwapCommand(t *testing.T) {
+	command := configureSwapCommand(Swap{Size: 1024, Swappiness: -1})
+	assert.Contains(t, command, `!= "1073741824"`)
+	assert.Contains(t, command, "sudo fallocate -l 1024M /swapfile")
+	assert.Contains(t, command, "echo '/swapfile none swap sw 0 0' | sudo tee -a /etc/fstab")
+	assert.NotContains(t, command, "swappiness")
+
+	command = configureSwapCommand(Swap{Swappiness: 10})
+	assert.False(t, strings.Contains(command, "/swapfile"))
+	assert.Contains(t, command, "sudo sysctl -w vm.swappiness=10")
+}
+
+func TestConfigureSwap(t *testing.T) {
+	assert.NoError(t, ConfigureSwap(&provisiontest.FakeSSHCommander{}, nil), "nothing is run without swap options")
+
+	swap := &Swap{Size: 512, Swappiness: 60}
+	commander := &provisiontest.FakeSSHCommander{Responses: map[string]string{configureSwapCommand(*swap): ""}}
+	assert.NoError(t, ConfigureSwap(commander, swap))
+}
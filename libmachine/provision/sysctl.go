@@ -0,0 +1,67 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// sysctlConf is the file of the sysctls of the machine, read by the
+// systems on boot.
+const sysctlConf = "/etc/sysctl.d/90-docker-machine.conf"
+
+var sysctlKeyRegexp = regexp.MustCompile(`^[a-z0-9_]+([./][a-zA-Z0-9_-]+)+$`)
+
+// ValidateSysctls checks the keys and the values of the sysctls, e.g.
+// vm.max_map_count=262144.
+func ValidateSysctls(sysctls map[string]string) error {
+	for key, value := range sysctls {
+		if !sysctlKeyRegexp.MatchString(key) {
+			return fmt.Errorf("Invalid sysctl %q, keys are made of dot separated words, e.g. vm.max_map_count", key)
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "'\n") {
+			return fmt.Errorf("Invalid value %q for the sysctl %s", value, key)
+		}
+	}
+
+	return nil
+}
+
+// ConfigureSysctls writes the sysctls to the sysctl.d directory of the
+// machine, for them to persist across reboots, and applies them. The file
+// is replaced as a whole, a sysctl removed from the options keeping its
+// value until the machine reboots.
+func ConfigureSysctls(p SSHCommander, sysctls map[string]string) error {
+	if len(sysctls) == 0 {
+		return nil
+	}
+
+	log.Info("Configuring the kernel parameters...")
+
+	if output, err := p.SSHCommand(configureSysctlsCommand(sysctls)); err != nil {
+		return fmt.Errorf("Error configuring the kernel parameters: %s\n%s", err, output)
+	}
+
+	return nil
+}
+
+func configureSysctlsCommand(sysctls map[string]string) string {
+	keys := []string{}
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := []string{}
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("'%s = %s'", key, sysctls[key]))
+	}
+
+	return fmt.Sprintf(`set -e
+sudo mkdir -p /etc/sysctl.d
+printf '%%s\n' %s | sudo tee %s >/dev/null
+sudo sysctl -p %s`, strings.Join(lines, " "), sysctlConf, sysctlConf)
+}
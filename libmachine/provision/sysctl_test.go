@@ -0,0 +1,42 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSysctls(t *testing.T) {
+	assert.NoError(t, ValidateSysctls(map[string]string{
+		"vm.max_map_count":                    "262144",
+		"net.ipv4.ip_local_port_range":        "1024 65000",
+		"net.ipv4.conf.eth0/1.rp_filter":      "0",
+		"fs.inotify.max_user_watches":         "524288",
+		"net.ipv6.conf.docker-0.disable_ipv6": "1",
+	}))
+
+	assert.Error(t, ValidateSysctls(map[string]string{"swappiness": "10"}))
+	assert.Error(t, ValidateSysctls(map[string]string{"vm.swappiness; reboot": "10"}))
+	assert.Error(t, ValidateSysctls(map[string]string{"vm.swappiness": ""}))
+	assert.Error(t, ValidateSysctls(map[string]string{"vm.swappiness": "10'"}))
+}
+
+func TestConfigureSysctls(t *testing.T) {
+	command := configureSysctlsCommand(map[string]string{
+		"vm.max_map_count":    "262144",
+		"net.ipv4.ip_forward": "1",
+	})
+
+	commander := &provisiontest.FakeSSHCommander{Responses: map[string]string{command: ""}}
+	assert.NoError(t, ConfigureSysctls(commander, map[string]string{
+		"vm.max_map_count":    "262144",
+		"net.ipv4.ip_forward": "1",
+	}))
+	assert.NoError(t, ConfigureSysctls(&provisiontest.FakeSSHCommander{}, nil), "nothing is run without sysctls")
+
+	assert.Equal(t, `set -e
+sudo mkdir -p /etc/sysctl.d
+printf '%s\n' 'net.ipv4.ip_forward = 1' 'vm.max_map_count = 262144' | sudo tee /etc/sysctl.d/90-docker-machine.conf >/dev/null
+sudo sysctl -p /etc/sysctl.d/90-docker-machine.conf`, command)
+}
@@ -53,7 +53,7 @@ func (p *SystemdProvisioner) GenerateDockerOptions(dockerPort int) (*DockerOptio
 
 	engineConfigTmpl := `[Service]
 ExecStart=
-ExecStart=/usr/bin/` + arg + ` -H tcp://0.0.0.0:{{.DockerPort}} -H unix:///var/run/docker.sock --storage-driver {{.EngineOptions.StorageDriver}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}} {{ range .EngineOptions.Labels }}--label {{.}} {{ end }}{{ range .EngineOptions.InsecureRegistry }}--insecure-registry {{.}} {{ end }}{{ range .EngineOptions.RegistryMirror }}--registry-mirror {{.}} {{ end }}{{ range .EngineOptions.ArbitraryFlags }}--{{.}} {{ end }}
+ExecStart=/usr/bin/` + arg + ` -H tcp://{{.BindAddress}}:{{.DockerPort}} {{ if not .EngineOptions.DisableUnixSocket }}-H unix:///var/run/docker.sock {{ end }}--storage-driver {{.EngineOptions.StorageDriver}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}} {{ range .EngineOptions.Labels }}--label {{.}} {{ end }}{{ range .EngineOptions.InsecureRegistry }}--insecure-registry {{.}} {{ end }}{{ range .EngineOptions.RegistryMirror }}--registry-mirror {{.}} {{ end }}{{ range .EngineOptions.ArbitraryFlags }}--{{.}} {{ end }}
 Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 `
 	t, err := template.New("engineConfig").Parse(engineConfigTmpl)
@@ -63,8 +63,9 @@ Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 
 	engineConfigContext := EngineConfigContext{
 		DockerPort:    dockerPort,
+		BindAddress:   engineBindAddress(p.Driver),
 		AuthOptions:   p.AuthOptions,
-		EngineOptions: p.EngineOptions,
+		EngineOptions: withDaemonFlags(withClusterStore(withProxyEnv(p.EngineOptions, p.Driver), p.SwarmOptions, p.AuthOptions, p.Driver, dockerPort)),
 	}
 
 	t.Execute(&engineCfg, engineConfigContext)
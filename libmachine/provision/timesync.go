@@ -0,0 +1,61 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/pkgaction"
+)
+
+// timeSyncPackage is installed on the systems with no time synchronization
+// service.
+const timeSyncPackage = "chrony"
+
+// enableTimeSyncCommand enables the first time synchronization service of
+// the system among chrony, systemd-timesyncd and the ntpd of busybox and
+// boot2docker, printing none when there is none.
+const enableTimeSyncCommand = `if command -v chronyd >/dev/null 2>&1; then
+	if command -v systemctl >/dev/null 2>&1; then
+		unit=chronyd; systemctl cat chronyd >/dev/null 2>&1 || unit=chrony
+		sudo systemctl enable $unit >/dev/null 2>&1 && sudo systemctl restart $unit
+	else
+		sudo rc-update add chronyd default >/dev/null 2>&1; sudo rc-service chronyd restart
+	fi
+elif command -v timedatectl >/dev/null 2>&1 && systemctl cat systemd-timesyncd >/dev/null 2>&1; then
+	sudo timedatectl set-ntp true
+elif command -v ntpd >/dev/null 2>&1; then
+	pgrep ntpd >/dev/null || sudo ntpd -p pool.ntp.org
+else
+	echo none
+fi`
+
+// ConfigureTimeSync enables the time synchronization of the machine,
+// installing chrony when the system has no service for it. A machine the
+// clock of which drifts fails the TLS handshakes with its engine once the
+// certificates look expired or not valid yet.
+func ConfigureTimeSync(p Provisioner) error {
+	log.Info("Enabling time synchronization...")
+
+	output, err := p.SSHCommand(enableTimeSyncCommand)
+	if err != nil {
+		return fmt.Errorf("Error enabling time synchronization: %s\n%s", err, output)
+	}
+	if strings.TrimSpace(output) != "none" {
+		return nil
+	}
+
+	if err := p.Package(timeSyncPackage, pkgaction.Install); err != nil {
+		return fmt.Errorf("Error installing %s: %s", timeSyncPackage, err)
+	}
+
+	output, err = p.SSHCommand(enableTimeSyncCommand)
+	if err != nil {
+		return fmt.Errorf("Error enabling time synchronization: %s\n%s", err, output)
+	}
+	if strings.TrimSpace(output) == "none" {
+		return fmt.Errorf("No time synchronization service is available on %s", p)
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package provision
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureTimeSync(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+
+	p.SSHCommander = &provisiontest.FakeSSHCommander{Responses: map[string]string{enableTimeSyncCommand: ""}}
+	assert.NoError(t, ConfigureTimeSync(p))
+
+	p.SSHCommander = &provisiontest.FakeSSHCommander{Responses: map[string]string{enableTimeSyncCommand: "none\n"}}
+	err := ConfigureTimeSync(p)
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "Error installing chrony"), "chrony is installed when there is no service")
+}
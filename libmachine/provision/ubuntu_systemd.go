@@ -1,7 +1,6 @@
 package provision
 
 import (
-	"fmt"
 	"strconv"
 
 	"github.com/docker/machine/libmachine/auth"
@@ -49,50 +48,24 @@ func (provisioner *UbuntuSystemdProvisioner) CompatibleWithHost() bool {
 
 }
 
-func (provisioner *UbuntuSystemdProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	updateMetadata := true
-
-	switch action {
-	case pkgaction.Install, pkgaction.Upgrade:
-		packageAction = "install"
-	case pkgaction.Remove:
-		packageAction = "remove"
-		updateMetadata = false
-	case pkgaction.Purge:
-		packageAction = "purge"
-		updateMetadata = false
-	}
-
-	switch name {
-	case "docker":
-		name = "docker-ce"
-	}
-
-	if updateMetadata {
-		if err := waitForLockAptGetUpdate(provisioner); err != nil {
-			return err
-		}
-	}
-
-	command := fmt.Sprintf("DEBIAN_FRONTEND=noninteractive sudo -E apt-get %s -y  %s", packageAction, name)
-
-	log.Debugf("package: action=%s name=%s", action.String(), name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
+// PackageManager returns the apt package manager of the machine.
+func (provisioner *UbuntuSystemdProvisioner) PackageManager() PackageManager {
+	return &aptPackageManager{
+		SSHCommander: provisioner,
+		names:        packageNames{"docker": "docker-ce"},
 	}
+}
 
-	return nil
+func (provisioner *UbuntuSystemdProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func (provisioner *UbuntuSystemdProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -125,7 +98,7 @@ func (provisioner *UbuntuSystemdProvisioner) Provision(swarmOptions swarm.Option
 	}
 
 	log.Info("Installing Docker...")
-	if err := installDockerGeneric(provisioner, engineOptions.InstallURL); err != nil {
+	if err := installDockerGeneric(provisioner, engineOptions); err != nil {
 		return err
 	}
 
@@ -134,6 +107,14 @@ func (provisioner *UbuntuSystemdProvisioner) Provision(swarmOptions swarm.Option
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	log.Debug("configuring auth")
@@ -68,47 +68,25 @@ func (provisioner *UbuntuProvisioner) Service(name string, action serviceaction.
 	return nil
 }
 
-func (provisioner *UbuntuProvisioner) Package(name string, action pkgaction.PackageAction) error {
-	var packageAction string
-
-	updateMetadata := true
-
-	switch action {
-	case pkgaction.Install, pkgaction.Upgrade:
-		packageAction = "install"
-	case pkgaction.Remove, pkgaction.Purge:
-		packageAction = "remove"
-		updateMetadata = false
-	}
-
-	switch name {
-	case "docker":
-		name = "docker-engine"
-	}
-
-	if updateMetadata {
-		if err := waitForLockAptGetUpdate(provisioner); err != nil {
-			return err
-		}
-	}
-
-	command := fmt.Sprintf("DEBIAN_FRONTEND=noninteractive sudo -E apt-get %s -y -o Dpkg::Options::=\"--force-confnew\" %s", packageAction, name)
-
-	log.Debugf("package: action=%s name=%s", action.String(), name)
-
-	if _, err := provisioner.SSHCommand(command); err != nil {
-		return err
+// PackageManager returns the apt package manager of the machine.
+func (provisioner *UbuntuProvisioner) PackageManager() PackageManager {
+	return &aptPackageManager{
+		SSHCommander: provisioner,
+		names:        packageNames{"docker": "docker-engine"},
+		options:      `-o Dpkg::Options::="--force-confnew" `,
 	}
+}
 
-	return nil
+func (provisioner *UbuntuProvisioner) Package(name string, action pkgaction.PackageAction) error {
+	return packageAction(provisioner.PackageManager(), name, action)
 }
 
 func (provisioner *UbuntuProvisioner) dockerDaemonResponding() bool {
 	log.Debug("checking docker daemon")
 
-	if out, err := provisioner.SSHCommand("sudo docker version"); err != nil {
+	if out, err := provisioner.SSHCommand(DockerCommand(provisioner, &provisioner.EngineOptions, "version")); err != nil {
 		log.Warnf("Error getting SSH command to check if the daemon is up: %s", err)
-		log.Debugf("'sudo docker version' output:\n%s", out)
+		log.Debugf("'docker version' output:\n%s", out)
 		return false
 	}
 
@@ -139,7 +117,7 @@ func (provisioner *UbuntuProvisioner) Provision(swarmOptions swarm.Options, auth
 	}
 
 	log.Info("Installing Docker...")
-	if err := installDockerGeneric(provisioner, engineOptions.InstallURL); err != nil {
+	if err := installDockerGeneric(provisioner, engineOptions); err != nil {
 		return err
 	}
 
@@ -151,6 +129,14 @@ func (provisioner *UbuntuProvisioner) Provision(swarmOptions swarm.Options, auth
 		return err
 	}
 
+	if err := prepareStorageDriver(provisioner, &provisioner.EngineOptions); err != nil {
+		return err
+	}
+
+	if err := installGPURuntime(provisioner, engineOptions); err != nil {
+		return err
+	}
+
 	provisioner.AuthOptions = setRemoteAuthOptions(provisioner)
 
 	if err := ConfigureAuth(provisioner); err != nil {
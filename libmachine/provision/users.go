@@ -0,0 +1,86 @@
+package provision
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+)
+
+var userNameRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_-]{0,31}$`)
+
+// User is an additional user of a machine, which logs in over SSH with its
+// own keys and reaches the engine through the docker group, so that a team
+// can share a machine without sharing its key.
+type User struct {
+	Name string
+
+	// PublicKeys are the authorized keys of the user, in the format of the
+	// authorized_keys file.
+	PublicKeys []string
+}
+
+// Validate checks the name and the keys of the user.
+func (u User) Validate() error {
+	if !userNameRegexp.MatchString(u.Name) {
+		return fmt.Errorf("Invalid user name %q, it must start with a lowercase letter and only hold lowercase letters, digits, - and _", u.Name)
+	}
+
+	if len(u.PublicKeys) == 0 {
+		return fmt.Errorf("The user %s has no public key", u.Name)
+	}
+
+	for _, key := range u.PublicKeys {
+		if strings.ContainsAny(key, "'\n") {
+			return fmt.Errorf("Invalid public key for the user %s: quotes and new lines are not supported", u.Name)
+		}
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key)); err != nil {
+			return fmt.Errorf("Invalid public key for the user %s: %s", u.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ConfigureUsers creates the users missing on the machine, adds them to the
+// docker group and replaces their authorized keys with the given ones. It
+// runs once the engine is installed, the docker group being created with
+// it.
+func ConfigureUsers(p SSHCommander, users []User) error {
+	for _, user := range users {
+		log.Infof("Configuring the user %s...", user.Name)
+
+		if output, err := p.SSHCommand(configureUserCommand(user)); err != nil {
+			return fmt.Errorf("Error configuring the user %s: %s\n%s", user.Name, err, output)
+		}
+	}
+
+	return nil
+}
+
+// configureUserCommand supports both the shadow utilities and the busybox
+// ones of boot2docker and Alpine. The password of the users busybox creates
+// is set to "*" rather than left locked, as sshd refuses locked accounts
+// even to keys on those systems.
+func configureUserCommand(user User) string {
+	keys := "'" + strings.Join(user.PublicKeys, "' '") + "'"
+
+	return fmt.Sprintf(`set -e
+if ! id -u %[1]s >/dev/null 2>&1; then
+	if command -v useradd >/dev/null 2>&1; then
+		sudo useradd -m -s /bin/sh %[1]s
+	else
+		sudo adduser -D -s /bin/sh %[1]s
+		echo '%[1]s:*' | sudo chpasswd -e
+	fi
+fi
+sudo usermod -aG docker %[1]s 2>/dev/null || sudo addgroup %[1]s docker
+home=$(eval echo ~%[1]s)
+sudo mkdir -p "$home/.ssh"
+printf '%%s\n' %[2]s | sudo tee "$home/.ssh/authorized_keys" >/dev/null
+sudo chown -R %[1]s "$home/.ssh"
+sudo chmod 700 "$home/.ssh"
+sudo chmod 600 "$home/.ssh/authorized_keys"`, user.Name, keys)
+}
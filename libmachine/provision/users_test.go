@@ -0,0 +1,39 @@
+package provision
+
+import (
+	"testing"
+
+	"github.com/docker/machine/libmachine/provision/provisiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUserKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIHJz0kdy0xOwGQ9mLE3sCmYMoAUc07b+bz3MbpZsK+hc alice@laptop"
+
+func TestUserValidate(t *testing.T) {
+	assert.NoError(t, User{Name: "alice", PublicKeys: []string{testUserKey}}.Validate())
+
+	invalid := []User{
+		{Name: "Alice", PublicKeys: []string{testUserKey}},
+		{Name: "al ice", PublicKeys: []string{testUserKey}},
+		{Name: "alice"},
+		{Name: "alice", PublicKeys: []string{"not a key"}},
+		{Name: "alice", PublicKeys: []string{testUserKey + "'; rm -rf /'"}},
+	}
+	for _, user := range invalid {
+		assert.Error(t, user.Validate(), user.Name)
+	}
+}
+
+func TestConfigureUsers(t *testing.T) {
+	user := User{Name: "alice", PublicKeys: []string{testUserKey, testUserKey}}
+	command := configureUserCommand(user)
+
+	assert.Contains(t, command, "sudo useradd -m -s /bin/sh alice")
+	assert.Contains(t, command, "sudo usermod -aG docker alice")
+	assert.Contains(t, command, "printf '%s\\n' '"+testUserKey+"' '"+testUserKey+"' | sudo tee \"$home/.ssh/authorized_keys\"")
+
+	commander := &provisiontest.FakeSSHCommander{Responses: map[string]string{command: ""}}
+	assert.NoError(t, ConfigureUsers(commander, []User{user}))
+	assert.Error(t, ConfigureUsers(commander, []User{{Name: "bob", PublicKeys: []string{testUserKey}}}))
+	assert.NoError(t, ConfigureUsers(commander, nil))
+}
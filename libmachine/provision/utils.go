@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -11,12 +12,24 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/machine/libmachine/assets"
 	"github.com/docker/machine/libmachine/auth"
 	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/drivers"
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/mcnutils"
 	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/ssh"
+)
+
+const (
+	// localInstallURLPrefix marks an install script to copy from the local
+	// filesystem, e.g. file:///opt/docker/install.sh
+	localInstallURLPrefix = "file://"
+
+	remoteInstallScriptPath = "/tmp/docker-install.sh"
+	remoteInstallBundlePath = "/tmp/docker-bundle.tgz"
 )
 
 type DockerOptions struct {
@@ -24,16 +37,141 @@ type DockerOptions struct {
 	EngineOptionsPath string
 }
 
-func installDockerGeneric(p Provisioner, baseURL string) error {
+// EngineConfigWriter is implemented by the provisioners of the systems that
+// do not read the engine options from a file, e.g. RancherOS. ConfigureAuth
+// hands them the generated options instead of writing EngineOptionsPath.
+type EngineConfigWriter interface {
+	WriteEngineConfig(dockerOptions *DockerOptions) error
+}
+
+func installDockerGeneric(p Provisioner, engineOptions engine.Options) error {
+	if err := expandArchitecture(p, &engineOptions); err != nil {
+		return err
+	}
+
+	if engineOptions.AssetCacheDir != "" {
+		if err := useCachedAssets(&engineOptions); err != nil {
+			return err
+		}
+	}
+
+	// The install script installs the given version rather than the latest
+	// one when VERSION is set
+	installEnv := ""
+	if engineOptions.InstallVersion != "" {
+		installEnv = fmt.Sprintf("VERSION=%s ", engineOptions.InstallVersion)
+	}
+
+	// Air-gapped machines cannot reach the install script, so a local
+	// script or bundle of packages is copied to the machine instead
+	localScript := strings.HasPrefix(engineOptions.InstallURL, localInstallURLPrefix)
+	if localScript || engineOptions.InstallBundle != "" {
+		if _, err := p.SSHCommand("type docker"); err == nil {
+			return nil
+		}
+
+		if engineOptions.InstallBundle != "" {
+			return installDockerFromBundle(p, engineOptions.InstallBundle)
+		}
+
+		return installDockerFromScript(p, strings.TrimPrefix(engineOptions.InstallURL, localInstallURLPrefix), installEnv)
+	}
+
 	// install docker - until cloudinit we use ubuntu everywhere so we
 	// just install it using the docker repos
-	if output, err := p.SSHCommand(fmt.Sprintf("if ! type docker; then curl -sSL %s | sh -; fi", baseURL)); err != nil {
+	if output, err := p.SSHCommand(fmt.Sprintf("if ! type docker; then curl -sSL %s | %ssh -; fi", engineOptions.InstallURL, installEnv)); err != nil {
+		return fmt.Errorf("error installing docker: %s", output)
+	}
+
+	return nil
+}
+
+// useCachedAssets replaces the remote install bundle or script with its copy
+// of the asset cache, which is downloaded first when it is not cached yet.
+// The local copy is then installed like a local bundle or script.
+func useCachedAssets(engineOptions *engine.Options) error {
+	cache := assets.NewCache(engineOptions.AssetCacheDir)
+
+	if assets.IsRemote(engineOptions.InstallBundle) {
+		bundlePath, err := cache.Fetch(engineOptions.InstallBundle)
+		if err != nil {
+			return err
+		}
+		engineOptions.InstallBundle = bundlePath
+	} else if engineOptions.InstallBundle == "" && assets.IsRemote(engineOptions.InstallURL) {
+		scriptPath, err := cache.Fetch(engineOptions.InstallURL)
+		if err != nil {
+			return err
+		}
+		engineOptions.InstallURL = localInstallURLPrefix + scriptPath
+	}
+
+	return nil
+}
+
+func installDockerFromScript(p Provisioner, scriptPath, installEnv string) error {
+	log.Infof("Copying install script %s to the machine...", scriptPath)
+	if err := copyFileToMachine(p.GetDriver(), scriptPath, remoteInstallScriptPath); err != nil {
+		return err
+	}
+
+	if output, err := p.SSHCommand(fmt.Sprintf("%ssh %s", installEnv, remoteInstallScriptPath)); err != nil {
 		return fmt.Errorf("error installing docker: %s", output)
 	}
 
 	return nil
 }
 
+// installDockerFromBundle installs docker from a gzipped tarball holding
+// either an install.sh script, or the .deb or .rpm packages to install.
+func installDockerFromBundle(p Provisioner, bundlePath string) error {
+	log.Infof("Copying install bundle %s to the machine...", bundlePath)
+	if err := copyFileToMachine(p.GetDriver(), bundlePath, remoteInstallBundlePath); err != nil {
+		return err
+	}
+
+	bundleDir := strings.TrimSuffix(remoteInstallBundlePath, ".tgz")
+	cmd := fmt.Sprintf("mkdir -p %s && tar -xzf %s -C %s && cd %s && "+
+		"if [ -f install.sh ]; then sudo sh install.sh; "+
+		"elif ls *.deb >/dev/null 2>&1; then sudo dpkg -i *.deb; "+
+		"else sudo rpm -Uvh --replacepkgs *.rpm; fi",
+		bundleDir, remoteInstallBundlePath, bundleDir, bundleDir)
+
+	if output, err := p.SSHCommand(cmd); err != nil {
+		return fmt.Errorf("error installing docker from bundle: %s", output)
+	}
+
+	return nil
+}
+
+func copyFileToMachine(d drivers.Driver, localPath, remotePath string) error {
+	client, err := drivers.GetSSHClientFromDriver(d)
+	if err != nil {
+		return err
+	}
+
+	return uploadFile(client, localPath, remotePath)
+}
+
+func uploadFile(client ssh.Client, localPath, remotePath string) error {
+	uploader, ok := client.(ssh.InputClient)
+	if !ok {
+		return fmt.Errorf("The SSH client cannot copy files to the machine")
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if output, err := uploader.OutputWithInput(fmt.Sprintf("cat > %s", remotePath), file); err != nil {
+		return fmt.Errorf("Error copying %s to the machine: %s (%s)", localPath, err, output)
+	}
+
+	return nil
+}
+
 func makeDockerOptionsDir(p Provisioner) error {
 	dockerDir := p.GetDockerOptionsDir()
 	if _, err := p.SSHCommand(fmt.Sprintf("sudo mkdir -p %s", dockerDir)); err != nil {
@@ -43,6 +181,75 @@ func makeDockerOptionsDir(p Provisioner) error {
 	return nil
 }
 
+// DockerCommand returns the command running the docker client as root on the
+// machine with the arguments. When the engine does not listen on
+// /var/run/docker.sock, the client connects to its TCP port instead, with
+// the server certificate which then also authenticates clients.
+func DockerCommand(p Provisioner, engineOptions *engine.Options, args string) string {
+	if engineOptions == nil || !engineOptions.DisableUnixSocket || keepsUnixSocket(p) {
+		return "sudo docker " + args
+	}
+
+	dockerDir := p.GetDockerOptionsDir()
+	return fmt.Sprintf("sudo docker --tlsverify --tlscacert %s --tlscert %s --tlskey %s -H tcp://localhost:%d %s",
+		path.Join(dockerDir, "ca.pem"),
+		path.Join(dockerDir, "server.pem"),
+		path.Join(dockerDir, "server-key.pem"),
+		engineOptions.DaemonPort(),
+		args)
+}
+
+// engineBindAddress returns the address the engine listens on: all the IPv4
+// addresses, or all the addresses, IPv4 ones included, when the machine has
+// an IPv6 address its URL may use. Binding [::] fails where IPv6 is disabled.
+func engineBindAddress(d drivers.Driver) string {
+	ips, err := drivers.GetIPs(d)
+	if err != nil {
+		log.Debugf("Error getting the addresses of the machine, listening on IPv4: %s", err)
+		return "0.0.0.0"
+	}
+
+	if _, err := drivers.SelectIP(ips, drivers.IPv6Only); err == nil {
+		return "[::]"
+	}
+	return "0.0.0.0"
+}
+
+// serverCertIPs returns the addresses of the machine its server certificate
+// is valid for: its addresses, and its private address which the swarm
+// containers advertise.
+func serverCertIPs(d drivers.Driver) ([]string, error) {
+	ips, err := drivers.GetIPs(d)
+	if err != nil {
+		return nil, err
+	}
+
+	privateIP, err := drivers.GetPrivateIP(d)
+	if err != nil && err != drivers.ErrNotImplemented {
+		return nil, err
+	}
+	if privateIP == "" {
+		return ips, nil
+	}
+	for _, ip := range ips {
+		if ip == privateIP {
+			return ips, nil
+		}
+	}
+
+	return append(ips, privateIP), nil
+}
+
+// keepsUnixSocket tells whether the engine of the provisioner listens on
+// /var/run/docker.sock whatever the engine options.
+func keepsUnixSocket(p Provisioner) bool {
+	switch p.(type) {
+	case *Boot2DockerProvisioner, *RancherProvisioner:
+		return true
+	}
+	return false
+}
+
 func setRemoteAuthOptions(p Provisioner) auth.Options {
 	dockerDir := p.GetDockerOptionsDir()
 	authOptions := p.GetAuthOptions()
@@ -65,10 +272,13 @@ func ConfigureAuth(p Provisioner) error {
 	machineName := driver.GetMachineName()
 	authOptions := p.GetAuthOptions()
 	swarmOptions := p.GetSwarmOptions()
-	org := mcnutils.GetUsername() + "." + machineName
-	bits := 2048
+	org := authOptions.CertOrganization
+	if org == "" {
+		org = mcnutils.GetUsername()
+	}
+	org += "." + machineName
 
-	ip, err := driver.GetIP()
+	ips, err := serverCertIPs(driver)
 	if err != nil {
 		return err
 	}
@@ -87,8 +297,10 @@ func ConfigureAuth(p Provisioner) error {
 		return fmt.Errorf("Copying key.pem to machine dir failed: %s", err)
 	}
 
-	// The Host IP is always added to the certificate's SANs list
-	hosts := append(authOptions.ServerCertSANs, ip, "localhost")
+	// The Host IPs are always added to the certificate's SANs list
+	hosts := append([]string{}, authOptions.ServerCertSANs...)
+	hosts = append(hosts, ips...)
+	hosts = append(hosts, "localhost")
 	log.Debugf("generating server cert: %s ca-key=%s private-key=%s org=%s san=%s",
 		authOptions.ServerCertPath,
 		authOptions.CaCertPath,
@@ -97,21 +309,33 @@ func ConfigureAuth(p Provisioner) error {
 		hosts,
 	)
 
-	// TODO: Switch to passing just authOptions to this func
-	// instead of all these individual fields
-	err = cert.GenerateCert(&cert.Options{
-		Hosts:       hosts,
-		CertFile:    authOptions.ServerCertPath,
-		KeyFile:     authOptions.ServerKeyPath,
-		CAFile:      authOptions.CaCertPath,
-		CAKeyFile:   authOptions.CaPrivateKeyPath,
-		Org:         org,
-		Bits:        bits,
-		SwarmMaster: swarmOptions.Master,
-	})
+	if authOptions.ServerCertSource != "" {
+		log.Infof("Installing the server certificate %s...", authOptions.ServerCertSource)
+		err = cert.ImportCert(authOptions.ServerCertSource, authOptions.ServerKeySource, authOptions.CaCertPath, authOptions.ServerCertPath, authOptions.ServerKeyPath)
+		if err != nil {
+			return fmt.Errorf("error installing server cert: %s", err)
+		}
+	} else {
+		// TODO: Switch to passing just authOptions to this func
+		// instead of all these individual fields
+		err = cert.GenerateCert(&cert.Options{
+			Hosts:       hosts,
+			CertFile:    authOptions.ServerCertPath,
+			KeyFile:     authOptions.ServerKeyPath,
+			CAFile:      authOptions.CaCertPath,
+			CAKeyFile:   authOptions.CaPrivateKeyPath,
+			Org:         org,
+			Bits:        authOptions.KeyBits,
+			SwarmMaster: swarmOptions.Master,
+			ClientAuth:  p.GetEngineOptions().DisableUnixSocket,
+			KeyType:     authOptions.KeyType,
+			Curve:       authOptions.KeyCurve,
+			Validity:    authOptions.CertValidity,
+		})
 
-	if err != nil {
-		return fmt.Errorf("error generating server cert: %s", err)
+		if err != nil {
+			return fmt.Errorf("error generating server cert: %s", err)
+		}
 	}
 
 	if err := p.Service("docker", serviceaction.Stop); err != nil {
@@ -156,23 +380,10 @@ func ConfigureAuth(p Provisioner) error {
 		return err
 	}
 
-	dockerURL, err := driver.GetURL()
+	dockerPort, err := enginePort(p)
 	if err != nil {
 		return err
 	}
-	u, err := url.Parse(dockerURL)
-	if err != nil {
-		return err
-	}
-	dockerPort := engine.DefaultPort
-	parts := strings.Split(u.Host, ":")
-	if len(parts) == 2 {
-		dPort, err := strconv.Atoi(parts[1])
-		if err != nil {
-			return err
-		}
-		dockerPort = dPort
-	}
 
 	dkrcfg, err := p.GenerateDockerOptions(dockerPort)
 	if err != nil {
@@ -181,7 +392,12 @@ func ConfigureAuth(p Provisioner) error {
 
 	log.Info("Setting Docker configuration on the remote daemon...")
 
-	if _, err = p.SSHCommand(fmt.Sprintf("sudo mkdir -p %s && printf %%s \"%s\" | sudo tee %s", path.Dir(dkrcfg.EngineOptionsPath), dkrcfg.EngineOptions, dkrcfg.EngineOptionsPath)); err != nil {
+	if writer, ok := p.(EngineConfigWriter); ok {
+		err = writer.WriteEngineConfig(dkrcfg)
+	} else {
+		_, err = p.SSHCommand(fmt.Sprintf("sudo mkdir -p %s && printf %%s \"%s\" | sudo tee %s", path.Dir(dkrcfg.EngineOptionsPath), dkrcfg.EngineOptions, dkrcfg.EngineOptionsPath))
+	}
+	if err != nil {
 		return err
 	}
 
@@ -251,6 +467,29 @@ func getFilesystemType(p Provisioner, directory string) (string, error) {
 	return fstype, nil
 }
 
+// enginePort returns the port the daemon listens on: the one of the engine
+// options when set, or else the one of the URL of the driver.
+func enginePort(p Provisioner) (int, error) {
+	engineOptions := p.GetEngineOptions()
+	if engineOptions.Port != 0 {
+		return engineOptions.Port, nil
+	}
+
+	dockerURL, err := p.GetDriver().GetURL()
+	if err != nil {
+		return 0, err
+	}
+	u, err := url.Parse(dockerURL)
+	if err != nil {
+		return 0, err
+	}
+	if port := u.Port(); port != "" {
+		return strconv.Atoi(port)
+	}
+
+	return engine.DefaultPort, nil
+}
+
 func checkDaemonUp(p Provisioner, dockerPort int) func() bool {
 	reDaemonListening := fmt.Sprintf(":%d\\s+.*:.*", dockerPort)
 	return func() bool {
@@ -316,3 +555,49 @@ func waitForLockAptGetUpdate(ssh SSHCommander) error {
 	}
 	return nil
 }
+
+// withProxyEnv returns a copy of the engine options whose environment also
+// holds the proxy settings. The machine IP is added to NO_PROXY so that the
+// daemon does not go through the proxy to reach the machine itself.
+func withProxyEnv(engineOptions engine.Options, d drivers.Driver) engine.Options {
+	if engineOptions.HTTPProxy == "" && engineOptions.HTTPSProxy == "" {
+		return engineOptions
+	}
+
+	env := append([]string{}, engineOptions.Env...)
+
+	if engineOptions.HTTPProxy != "" {
+		env = append(env, "HTTP_PROXY="+engineOptions.HTTPProxy)
+	}
+	if engineOptions.HTTPSProxy != "" {
+		env = append(env, "HTTPS_PROXY="+engineOptions.HTTPSProxy)
+	}
+
+	noProxy := []string{}
+	if engineOptions.NoProxy != "" {
+		noProxy = strings.Split(engineOptions.NoProxy, ",")
+	}
+
+	if ip, err := d.GetIP(); err != nil {
+		log.Debugf("Could not get the IP to add to NO_PROXY: %s", err)
+	} else if !containsString(noProxy, ip) {
+		noProxy = append(noProxy, ip)
+	}
+
+	if len(noProxy) > 0 {
+		env = append(env, "NO_PROXY="+strings.Join(noProxy, ","))
+	}
+
+	engineOptions.Env = env
+
+	return engineOptions
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
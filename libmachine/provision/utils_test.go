@@ -2,6 +2,8 @@ package provision
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"regexp"
 	"strings"
 	"testing"
@@ -12,6 +14,8 @@ import (
 	"github.com/docker/machine/libmachine/provision/pkgaction"
 	"github.com/docker/machine/libmachine/provision/provisiontest"
 	"github.com/docker/machine/libmachine/provision/serviceaction"
+	"github.com/docker/machine/libmachine/ssh/sshtest"
+	"github.com/docker/machine/libmachine/state"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/stretchr/testify/assert"
 )
@@ -166,6 +170,22 @@ func TestMachineCustomPortBoot2Docker(t *testing.T) {
 	}
 }
 
+func TestEnginePort(t *testing.T) {
+	p := &Boot2DockerProvisioner{
+		Driver: &fakedriver.Driver{MockState: state.Running, MockIP: "1.2.3.4"},
+	}
+
+	port, err := enginePort(p)
+	assert.NoError(t, err)
+	assert.Equal(t, engine.DefaultPort, port)
+
+	p.EngineOptions.Port = 12376
+
+	port, err = enginePort(p)
+	assert.NoError(t, err)
+	assert.Equal(t, 12376, port)
+}
+
 func TestUbuntuSystemdDaemonBinary(t *testing.T) {
 	p := NewUbuntuSystemdProvisioner(&fakedriver.Driver{}).(*UbuntuSystemdProvisioner)
 	cases := []struct {
@@ -282,3 +302,144 @@ func TestDockerClientVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestWithProxyEnv(t *testing.T) {
+	driver := &fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "192.168.99.100",
+	}
+
+	engineOptions := engine.Options{
+		Env:        []string{"FOO=bar"},
+		HTTPProxy:  "http://proxy:3128",
+		HTTPSProxy: "http://proxy:3129",
+		NoProxy:    "localhost,.corp",
+	}
+
+	withProxy := withProxyEnv(engineOptions, driver)
+
+	assert.Equal(t, []string{
+		"FOO=bar",
+		"HTTP_PROXY=http://proxy:3128",
+		"HTTPS_PROXY=http://proxy:3129",
+		"NO_PROXY=localhost,.corp,192.168.99.100",
+	}, withProxy.Env)
+	assert.Equal(t, []string{"FOO=bar"}, engineOptions.Env)
+}
+
+func TestWithProxyEnvWithoutProxy(t *testing.T) {
+	engineOptions := engine.Options{
+		Env: []string{"FOO=bar"},
+	}
+
+	assert.Equal(t, engineOptions, withProxyEnv(engineOptions, &fakedriver.Driver{}))
+}
+
+func TestGenerateDockerOptionsBoot2DockerProxy(t *testing.T) {
+	p := &Boot2DockerProvisioner{
+		Driver: &fakedriver.Driver{
+			MockState: state.Running,
+			MockIP:    "192.168.99.100",
+		},
+		EngineOptions: engine.Options{
+			HTTPProxy: "http://proxy:3128",
+		},
+	}
+
+	dockerCfg, err := p.GenerateDockerOptions(2376)
+
+	assert.NoError(t, err)
+	assert.Contains(t, dockerCfg.EngineOptions, "HTTP_PROXY=http://proxy:3128")
+	assert.Contains(t, dockerCfg.EngineOptions, "NO_PROXY=192.168.99.100")
+}
+
+func TestInstallDockerGenericVersion(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"if ! type docker; then curl -sSL https://get.docker.com | sh -; fi":                 "",
+			"if ! type docker; then curl -sSL https://get.docker.com | VERSION=17.06.2 sh -; fi": "",
+		},
+	}
+
+	assert.NoError(t, installDockerGeneric(p, engine.Options{InstallURL: "https://get.docker.com"}))
+	assert.NoError(t, installDockerGeneric(p, engine.Options{InstallURL: "https://get.docker.com", InstallVersion: "17.06.2"}))
+	assert.Error(t, installDockerGeneric(p, engine.Options{InstallURL: "https://get.docker.com", InstallVersion: "17.03.0"}))
+}
+
+func TestInstallDockerGenericAlreadyInstalled(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{}).(*DebianProvisioner)
+	p.SSHCommander = &provisiontest.FakeSSHCommander{
+		Responses: map[string]string{
+			"type docker": "docker is /usr/bin/docker",
+		},
+	}
+
+	assert.NoError(t, installDockerGeneric(p, engine.Options{InstallBundle: "/does/not/exist.tgz"}))
+	assert.NoError(t, installDockerGeneric(p, engine.Options{InstallURL: "file:///does/not/exist.sh"}))
+}
+
+func TestUploadFile(t *testing.T) {
+	tmpFile, err := ioutil.TempFile("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString("#!/bin/sh\necho install\n")
+	tmpFile.Close()
+
+	client := &sshtest.FakeClient{}
+
+	assert.NoError(t, uploadFile(client, tmpFile.Name(), "/tmp/docker-install.sh"))
+	assert.Equal(t, "#!/bin/sh\necho install\n", string(client.Inputs["cat > /tmp/docker-install.sh"]))
+}
+
+func TestNewProvisioner(t *testing.T) {
+	p, err := NewProvisioner(&Detected{Name: "redhat", OsRelease: &OsRelease{ID: "rhel"}}, &fakedriver.Driver{})
+
+	assert.NoError(t, err)
+	assert.IsType(t, &RedHatProvisioner{}, p)
+	assert.True(t, p.CompatibleWithHost())
+}
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("boot2docker"))
+	assert.NoError(t, ValidateName("ubuntu-systemd"))
+	assert.Error(t, ValidateName("gentoo"))
+}
+
+func TestDockerCommand(t *testing.T) {
+	p := NewDebianProvisioner(&fakedriver.Driver{})
+
+	assert.Equal(t, "sudo docker ps -q", DockerCommand(p, &engine.Options{}, "ps -q"))
+	assert.Equal(t,
+		"sudo docker --tlsverify --tlscacert /etc/docker/ca.pem --tlscert /etc/docker/server.pem --tlskey /etc/docker/server-key.pem -H tcp://localhost:2377 ps -q",
+		DockerCommand(p, &engine.Options{Port: 2377, DisableUnixSocket: true}, "ps -q"))
+}
+
+func TestDockerCommandBoot2DockerKeepsUnixSocket(t *testing.T) {
+	p := NewBoot2DockerProvisioner(&fakedriver.Driver{})
+
+	assert.Equal(t, "sudo docker ps -q", DockerCommand(p, &engine.Options{DisableUnixSocket: true}, "ps -q"))
+}
+
+func TestServerCertIPs(t *testing.T) {
+	ips, err := serverCertIPs(&fakedriver.Driver{MockState: state.Running, MockIP: "1.2.3.4"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.2.3.4"}, ips)
+
+	ips, err = serverCertIPs(&fakedriver.Driver{MockState: state.Running, MockIP: "1.2.3.4", MockPrivateIP: "10.0.0.2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1.2.3.4", "10.0.0.2"}, ips)
+}
+
+func TestEngineBindAddress(t *testing.T) {
+	assert.Equal(t, "0.0.0.0", engineBindAddress(&fakedriver.Driver{MockState: state.Running, MockIP: "1.2.3.4"}))
+	assert.Equal(t, "0.0.0.0", engineBindAddress(&fakedriver.Driver{MockState: state.Stopped}))
+	assert.Equal(t, "[::]", engineBindAddress(&fakedriver.Driver{
+		MockState: state.Running,
+		MockIP:    "1.2.3.4",
+		MockIPs:   []string{"1.2.3.4", "2001:db8::1"},
+	}))
+}
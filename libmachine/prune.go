@@ -0,0 +1,76 @@
+package libmachine
+
+import (
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// PruneResult is the outcome of Prune for a machine the instance of which no
+// longer exists.
+type PruneResult struct {
+	Name string
+	// Removed is set when the machine was removed from the store rather
+	// than flagged.
+	Removed bool
+	Err     error
+}
+
+// Prune finds the machines of the store the instance of which no longer
+// exists at the provider, e.g. deleted from its console. With remove, they
+// are removed from the store as with RemoveMachine, otherwise they are flagged with
+// InstanceMissing, so that operating them fails with ErrInstanceMissing
+// rather than with errors of the provider. Machines the driver of which
+// cannot tell whether the instance exists are left alone.
+func Prune(api API, remove bool) ([]PruneResult, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []PruneResult{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		if !h.CreateReached(host.CreatePhaseInstanceCreated) {
+			continue
+		}
+
+		exists, err := drivers.InstanceExists(h.Driver)
+		if err == drivers.ErrNotImplemented {
+			continue
+		}
+		if err != nil {
+			log.Warnf("Error checking whether the instance of %q exists: %s", name, err)
+			continue
+		}
+
+		if exists {
+			if h.InstanceMissing {
+				h.InstanceMissing = false
+				if err := api.Save(h); err != nil {
+					log.Warnf("Error saving machine %q: %s", name, err)
+				}
+			}
+			continue
+		}
+
+		result := PruneResult{Name: name, Removed: remove}
+		if remove {
+			result.Err = removeMachine(api, name, "pruned, the instance no longer exists")
+		} else if !h.InstanceMissing {
+			h.InstanceMissing = true
+			result.Err = api.Save(h)
+			RecordOperationDetails(api, name, persist.AuditConfigChange, "flagged, the instance no longer exists", result.Err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,75 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+type pruneTestAPI struct {
+	*persisttest.FakeStore
+}
+
+func (api *pruneTestAPI) Close() error {
+	return nil
+}
+
+func (api *pruneTestAPI) NewHost(driverName string, rawDriver []byte) (*host.Host, error) {
+	return nil, nil
+}
+
+func (api *pruneTestAPI) Create(h *host.Host) error {
+	return nil
+}
+
+func (api *pruneTestAPI) GetMachinesDir() string {
+	return ""
+}
+
+func newPruneTestHost(name string, missing bool) *host.Host {
+	return &host.Host{
+		Name: name,
+		Driver: &fakedriver.Driver{
+			BaseDriver:          &drivers.BaseDriver{MachineName: name},
+			MockState:           state.Running,
+			MockInstanceMissing: missing,
+		},
+	}
+}
+
+func TestPruneFlagsMissingInstances(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{
+			newPruneTestHost("deleted", true),
+			newPruneTestHost("running", false),
+		},
+	}
+
+	results, err := Prune(&pruneTestAPI{store}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PruneResult{{Name: "deleted"}}, results)
+	assert.True(t, store.Hosts[0].InstanceMissing)
+	assert.False(t, store.Hosts[1].InstanceMissing)
+}
+
+func TestPruneRemovesMissingInstances(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{
+			newPruneTestHost("deleted", true),
+			newPruneTestHost("running", false),
+		},
+	}
+
+	results, err := Prune(&pruneTestAPI{store}, true)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []PruneResult{{Name: "deleted", Removed: true}}, results)
+	assert.Len(t, store.Hosts, 1)
+	assert.Equal(t, "running", store.Hosts[0].Name)
+}
@@ -0,0 +1,52 @@
+package libmachine
+
+import (
+	"errors"
+	"io"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+var errNoArchiver = errors.New("The store cannot archive machines")
+
+// Deregister removes a machine from the store without removing its instance,
+// see host.Deregister. When archive is not nil, the machine is written to it
+// first for Register to add it back, possibly to another store.
+func Deregister(api API, name string, archive io.Writer) error {
+	h, err := api.Load(name)
+	if err != nil {
+		return err
+	}
+
+	if archive != nil {
+		archiver, ok := api.(persist.Archiver)
+		if !ok {
+			return errNoArchiver
+		}
+		if err := archiver.Export(name, archive); err != nil {
+			return err
+		}
+	}
+
+	err = h.Deregister(api, storePath(api))
+	RecordOperation(api, name, persist.AuditDeregister, err)
+	return err
+}
+
+// Register adds the machine of an archive written by Deregister to the
+// store.
+func Register(api API, archive io.Reader) (*host.Host, error) {
+	archiver, ok := api.(persist.Archiver)
+	if !ok {
+		return nil, errNoArchiver
+	}
+
+	h, err := archiver.Import(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	RecordOperation(api, h.Name, persist.AuditRegister, nil)
+	return h, nil
+}
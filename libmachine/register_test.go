@@ -0,0 +1,45 @@
+package libmachine
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/stretchr/testify/assert"
+)
+
+type removeCountingDriver struct {
+	*fakedriver.Driver
+	removed int
+}
+
+func (d *removeCountingDriver) Remove() error {
+	d.removed++
+	return nil
+}
+
+func TestDeregisterKeepsInstance(t *testing.T) {
+	driver := &removeCountingDriver{Driver: &fakedriver.Driver{}}
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{{Name: "handed-off", Driver: driver}},
+	}
+
+	err := Deregister(&pruneTestAPI{store}, "handed-off", nil)
+
+	assert.NoError(t, err)
+	assert.Empty(t, store.Hosts)
+	assert.Equal(t, 0, driver.removed)
+}
+
+func TestDeregisterArchiveNotSupported(t *testing.T) {
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{newPruneTestHost("handed-off", false)},
+	}
+
+	err := Deregister(&pruneTestAPI{store}, "handed-off", &bytes.Buffer{})
+
+	assert.Equal(t, errNoArchiver, err)
+	assert.Len(t, store.Hosts, 1)
+}
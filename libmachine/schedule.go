@@ -0,0 +1,88 @@
+package libmachine
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/state"
+)
+
+// ScheduleResult is the outcome of ApplySchedules for a machine it started
+// or stopped, or failed to.
+type ScheduleResult struct {
+	Name string
+	// State is state.Running when the machine was started, state.Stopped
+	// when it was stopped.
+	State state.State
+	Err   error
+}
+
+// ApplySchedules starts and stops the machines of the store the schedule of
+// which has a start or stop time in (since, until], see
+// host.ApplySchedule.
+func ApplySchedules(api API, since, until time.Time) ([]ScheduleResult, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ScheduleResult{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		if h.HostOptions == nil || h.HostOptions.Schedule.IsEmpty() {
+			continue
+		}
+
+		s, err := h.ApplySchedule(since, until)
+		if err != nil {
+			results = append(results, ScheduleResult{Name: name, Err: err})
+			continue
+		}
+		if s == state.None {
+			continue
+		}
+
+		operation := persist.AuditStart
+		if s == state.Stopped {
+			operation = persist.AuditStop
+		}
+		RecordOperationDetails(api, name, operation, "schedule", nil)
+
+		if err := api.Save(h); err != nil {
+			log.Warnf("Error saving machine %q: %s", name, err)
+		}
+		results = append(results, ScheduleResult{Name: name, State: s})
+	}
+
+	return results, nil
+}
+
+// WatchSchedules runs ApplySchedules every interval, for the times elapsed
+// since the previous run, until done is closed. The results are passed to
+// report.
+func WatchSchedules(api API, interval time.Duration, done <-chan struct{}, report func([]ScheduleResult)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	since := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			results, err := ApplySchedules(api, since, now)
+			if err != nil {
+				log.Warnf("Error applying the schedules of the machines: %s", err)
+				continue
+			}
+			since = now
+			report(results)
+		}
+	}
+}
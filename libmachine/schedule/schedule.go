@@ -0,0 +1,243 @@
+// Package schedule parses the cron-like expressions telling when machines
+// are started and stopped, e.g. "0 8 * * 1-5" for 8am on weekdays.
+//
+// An expression has the five fields of crontab: minute (0-59), hour (0-23),
+// day of the month (1-31), month (1-12) and day of the week (0-6, Sunday
+// being 0 or 7). A field is either *, a value, a range such as 1-5, a step
+// such as */15 or 8-18/2, or a comma separated list of those. As with cron,
+// when both the day of the month and the day of the week are restricted, a
+// day matching either of them matches.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+// maxSearch bounds the search of the next matching time, for expressions
+// which never match such as "0 0 31 2 *".
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of the month", 1, 31},
+	{"month", 1, 12},
+	{"day of the week", 0, 7},
+}
+
+// Expr is a parsed expression.
+type Expr struct {
+	raw                                 string
+	minutes, hours, days, months, wdays map[int]bool
+	anyDay, anyWeekday                  bool
+}
+
+// Parse parses an expression.
+func Parse(expr string) (*Expr, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("Invalid schedule %q: expected %d fields, minute hour day month weekday", expr, len(fields))
+	}
+
+	sets := make([]map[int]bool, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid schedule %q: %s", expr, err)
+		}
+		sets[i] = set
+	}
+
+	// Sunday is both 0 and 7
+	if sets[4][7] {
+		sets[4][0] = true
+	}
+
+	return &Expr{
+		raw:        strings.Join(parts, " "),
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		wdays:      sets[4],
+		anyDay:     parts[2] == "*",
+		anyWeekday: parts[4] == "*",
+	}, nil
+}
+
+func (e *Expr) String() string {
+	return e.raw
+}
+
+// Matches tells whether the expression matches the minute of t.
+func (e *Expr) Matches(t time.Time) bool {
+	return e.minutes[t.Minute()] && e.hours[t.Hour()] && e.months[int(t.Month())] && e.matchesDay(t)
+}
+
+func (e *Expr) matchesDay(t time.Time) bool {
+	day, wday := e.days[t.Day()], e.wdays[int(t.Weekday())]
+
+	switch {
+	case e.anyDay && e.anyWeekday:
+		return true
+	case e.anyDay:
+		return wday
+	case e.anyWeekday:
+		return day
+	default:
+		return day || wday
+	}
+}
+
+// Next returns the first minute after t the expression matches, or the zero
+// time if it never matches.
+func (e *Expr) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for next.Before(limit) {
+		switch {
+		case !e.months[int(next.Month())]:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !e.matchesDay(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case !e.hours[next.Hour()]:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case !e.minutes[next.Minute()]:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+
+	return time.Time{}
+}
+
+// Last returns the last minute in (since, until] the expression matches, or
+// the zero time if it matches none.
+func (e *Expr) Last(since, until time.Time) time.Time {
+	last := time.Time{}
+	for next := e.Next(since); !next.IsZero() && !next.After(until); next = e.Next(next) {
+		last = next
+	}
+	return last
+}
+
+func parseField(part string, f field) (map[int]bool, error) {
+	set := map[int]bool{}
+
+	for _, item := range strings.Split(part, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			var err error
+			rng = item[:i]
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step < 1 {
+				return nil, fmt.Errorf("invalid step in %s %q", f.name, item)
+			}
+		}
+
+		low, high := f.min, f.max
+		if rng != "*" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if low, err = parseValue(bounds[0], f); err != nil {
+				return nil, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = parseValue(bounds[1], f); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				high = f.max
+			}
+			if high < low {
+				return nil, fmt.Errorf("invalid range in %s %q", f.name, item)
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			set[v] = true
+		}
+	}
+
+	return set, nil
+}
+
+func parseValue(value string, f field) (int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid %s %q, expected %d-%d", f.name, value, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Schedule tells when a machine is started and stopped, each with an
+// expression. Either may be empty, e.g. to only stop dev machines overnight.
+type Schedule struct {
+	Start string `json:",omitempty"`
+	Stop  string `json:",omitempty"`
+}
+
+// IsEmpty tells whether the schedule neither starts nor stops the machine.
+func (s *Schedule) IsEmpty() bool {
+	return s == nil || (s.Start == "" && s.Stop == "")
+}
+
+// Validate checks the expressions of the schedule.
+func (s *Schedule) Validate() error {
+	_, _, err := s.parse()
+	return err
+}
+
+// Due returns the state the machine must be put in for the start and stop
+// times in (since, until]: the state of the latest of them, or state.None
+// when there are none. A machine started or stopped by hand in between is
+// left alone until the next time.
+func (s *Schedule) Due(since, until time.Time) (state.State, error) {
+	start, stop, err := s.parse()
+	if err != nil {
+		return state.None, err
+	}
+
+	lastStart, lastStop := time.Time{}, time.Time{}
+	if start != nil {
+		lastStart = start.Last(since, until)
+	}
+	if stop != nil {
+		lastStop = stop.Last(since, until)
+	}
+
+	switch {
+	case lastStart.IsZero() && lastStop.IsZero():
+		return state.None, nil
+	case lastStart.After(lastStop):
+		return state.Running, nil
+	default:
+		return state.Stopped, nil
+	}
+}
+
+func (s *Schedule) parse() (start, stop *Expr, err error) {
+	if s.Start != "" {
+		if start, err = Parse(s.Start); err != nil {
+			return nil, nil, err
+		}
+	}
+	if s.Stop != "" {
+		if stop, err = Parse(s.Stop); err != nil {
+			return nil, nil, err
+		}
+	}
+	return start, stop, nil
+}
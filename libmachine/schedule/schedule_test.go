@@ -0,0 +1,110 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// monday is Monday, October 2nd 2017 at 9:30.
+var monday = time.Date(2017, time.October, 2, 9, 30, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	expr, err := Parse("*/15 8-18/2 * * 1-5")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "*/15 8-18/2 * * 1-5", expr.String())
+	assert.True(t, expr.Matches(time.Date(2017, time.October, 2, 10, 45, 0, 0, time.UTC)))
+	assert.False(t, expr.Matches(time.Date(2017, time.October, 2, 11, 45, 0, 0, time.UTC)))
+	assert.False(t, expr.Matches(time.Date(2017, time.October, 1, 10, 45, 0, 0, time.UTC)))
+}
+
+func TestParseErrors(t *testing.T) {
+	invalid := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	}
+
+	for _, expr := range invalid {
+		_, err := Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSundayIsZeroOrSeven(t *testing.T) {
+	sunday := time.Date(2017, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, raw := range []string{"0 0 * * 0", "0 0 * * 7"} {
+		expr, err := Parse(raw)
+		assert.NoError(t, err)
+		assert.True(t, expr.Matches(sunday), raw)
+	}
+}
+
+func TestDayOfMonthOrWeek(t *testing.T) {
+	expr, err := Parse("0 0 13 * 5")
+	assert.NoError(t, err)
+
+	// Friday the 6th and Tuesday the 13th both match
+	assert.True(t, expr.Matches(time.Date(2017, time.October, 6, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, expr.Matches(time.Date(2017, time.June, 13, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, expr.Matches(time.Date(2017, time.October, 7, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNext(t *testing.T) {
+	expr, err := Parse("0 20 * * 1-5")
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Date(2017, time.October, 2, 20, 0, 0, 0, time.UTC), expr.Next(monday))
+
+	friday := time.Date(2017, time.October, 6, 20, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2017, time.October, 9, 20, 0, 0, 0, time.UTC), expr.Next(friday))
+}
+
+func TestNextNeverMatching(t *testing.T) {
+	expr, err := Parse("0 0 31 2 *")
+	assert.NoError(t, err)
+
+	assert.True(t, expr.Next(monday).IsZero())
+}
+
+func TestDue(t *testing.T) {
+	s := &Schedule{Start: "0 8 * * 1-5", Stop: "0 20 * * *"}
+	assert.NoError(t, s.Validate())
+
+	due, err := s.Due(monday.Add(-2*time.Hour), monday)
+	assert.NoError(t, err)
+	assert.Equal(t, state.Running, due)
+
+	due, err = s.Due(monday, monday.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, state.None, due)
+
+	due, err = s.Due(monday.Add(-24*time.Hour), monday.Add(-12*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, state.Stopped, due)
+
+	// Both happened, the latest wins
+	due, err = s.Due(monday.Add(-14*time.Hour), monday)
+	assert.NoError(t, err)
+	assert.Equal(t, state.Running, due)
+}
+
+func TestScheduleIsEmpty(t *testing.T) {
+	var s *Schedule
+
+	assert.True(t, s.IsEmpty())
+	assert.True(t, (&Schedule{}).IsEmpty())
+	assert.False(t, (&Schedule{Stop: "0 20 * * *"}).IsEmpty())
+	assert.Error(t, (&Schedule{Stop: "20:00"}).Validate())
+}
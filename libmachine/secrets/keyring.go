@@ -0,0 +1,163 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/credentials"
+)
+
+// KeyringPrefix marks the string values of config.json which are a reference
+// to a secret stored in the OS keychain, and is followed by the account it is
+// stored under.
+const KeyringPrefix = "machine-credential:keyring:"
+
+func IsKeyringReference(value string) bool {
+	return strings.HasPrefix(value, KeyringPrefix)
+}
+
+// decodeObject decodes a JSON object, keeping its numbers as json.Number for
+// the 64-bit integers not to lose their precision when it is encoded again.
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	var obj map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// StoreHostCredentials moves the values of the sensitive fields of the driver
+// section of a serialized host, the ones of SensitiveFields and the extra
+// ones, to the keyring and replaces them by references. Each value is stored
+// under an account named after the prefix and the path of its field. The data
+// is returned untouched if there is nothing to store.
+func StoreHostCredentials(keyring credentials.Keyring, accountPrefix string, data []byte, extraFields []string) ([]byte, error) {
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, ok := obj["Driver"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	stored, err := storeFields(keyring, accountPrefix+"/Driver", driver, extraFields)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return data, nil
+	}
+
+	return json.MarshalIndent(obj, "", "    ")
+}
+
+func storeFields(keyring credentials.Keyring, path string, obj map[string]interface{}, extraFields []string) (bool, error) {
+	stored := false
+
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if v == "" || IsEncrypted(v) || IsKeyringReference(v) || !isSensitiveIn(name, extraFields) {
+				continue
+			}
+
+			account := path + "." + name
+			// Skip the write when the keyring is already up to date, as it
+			// is on most saves.
+			if current, err := keyring.Get(account); err != nil || current != v {
+				if err := keyring.Set(account, v); err != nil {
+					return false, fmt.Errorf("Error storing %s in the keyring: %s", name, err)
+				}
+			}
+
+			obj[name] = KeyringPrefix + account
+			stored = true
+		case map[string]interface{}:
+			nestedStored, err := storeFields(keyring, path+"."+name, v, extraFields)
+			if err != nil {
+				return false, err
+			}
+			stored = stored || nestedStored
+		}
+	}
+
+	return stored, nil
+}
+
+// ResolveHostCredentials replaces the keyring references of a serialized host
+// by the secrets they point to.
+func ResolveHostCredentials(keyring credentials.Keyring, data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(KeyringPrefix)) {
+		return data, nil
+	}
+
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := resolveFields(keyring, obj); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(obj)
+}
+
+func resolveFields(keyring credentials.Keyring, obj map[string]interface{}) error {
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if !IsKeyringReference(v) {
+				continue
+			}
+
+			secret, err := keyring.Get(strings.TrimPrefix(v, KeyringPrefix))
+			if err != nil {
+				return fmt.Errorf("Error reading %s from the keyring: %s", name, err)
+			}
+			obj[name] = secret
+		case map[string]interface{}:
+			if err := resolveFields(keyring, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// HostCredentialAccounts returns the keyring accounts the references of a
+// serialized host point to.
+func HostCredentialAccounts(data []byte) ([]string, error) {
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialAccounts(obj), nil
+}
+
+func credentialAccounts(obj map[string]interface{}) []string {
+	accounts := []string{}
+
+	for _, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if IsKeyringReference(v) {
+				accounts = append(accounts, strings.TrimPrefix(v, KeyringPrefix))
+			}
+		case map[string]interface{}:
+			accounts = append(accounts, credentialAccounts(v)...)
+		}
+	}
+
+	return accounts
+}
@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeKeyring struct {
+	values map[string]string
+	err    error
+}
+
+func (k *fakeKeyring) Get(account string) (string, error) {
+	if value, ok := k.values[account]; ok {
+		return value, nil
+	}
+	return "", errors.New("not found")
+}
+
+func (k *fakeKeyring) Set(account, value string) error {
+	if k.err != nil {
+		return k.err
+	}
+	k.values[account] = value
+	return nil
+}
+
+func (k *fakeKeyring) Delete(account string) error {
+	delete(k.values, account)
+	return nil
+}
+
+func TestStoreAndResolveHostCredentials(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{}}
+
+	data := []byte(`{"Name": "foo", "Driver": {"AccessToken": "my-token", "Region": "nyc3", "Nested": {"Password": "pass"}, "UserPassword": "user-pass"}}`)
+
+	stored, err := StoreHostCredentials(keyring, "/store/machines/foo", data, []string{"UserPassword"})
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(stored), "my-token"))
+	assert.False(t, strings.Contains(string(stored), "pass\""))
+	assert.True(t, strings.Contains(string(stored), "nyc3"))
+	assert.Equal(t, map[string]string{
+		"/store/machines/foo/Driver.AccessToken":     "my-token",
+		"/store/machines/foo/Driver.Nested.Password": "pass",
+		"/store/machines/foo/Driver.UserPassword":    "user-pass",
+	}, keyring.values)
+
+	accounts, err := HostCredentialAccounts(stored)
+	assert.NoError(t, err)
+	assert.Len(t, accounts, 3)
+	assert.Contains(t, accounts, "/store/machines/foo/Driver.AccessToken")
+
+	resolved, err := ResolveHostCredentials(keyring, stored)
+	assert.NoError(t, err)
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resolved, &obj))
+
+	driver := obj["Driver"].(map[string]interface{})
+	assert.Equal(t, "my-token", driver["AccessToken"])
+	assert.Equal(t, "nyc3", driver["Region"])
+	assert.Equal(t, "pass", driver["Nested"].(map[string]interface{})["Password"])
+	assert.Equal(t, "user-pass", driver["UserPassword"])
+}
+
+func TestStoreHostCredentialsWithoutSecrets(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{}}
+
+	data := []byte(`{"Name": "foo", "Driver": {"Region": "nyc3"}}`)
+
+	stored, err := StoreHostCredentials(keyring, "/store/machines/foo", data, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, data, stored)
+	assert.Empty(t, keyring.values)
+}
+
+func TestStoreHostCredentialsKeyringUnavailable(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{}, err: errors.New("no keyring")}
+
+	_, err := StoreHostCredentials(keyring, "/store/machines/foo", []byte(`{"Driver": {"AccessToken": "my-token"}}`), nil)
+	assert.EqualError(t, err, "Error storing AccessToken in the keyring: no keyring")
+}
+
+func TestResolveHostCredentialsMissing(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{}}
+
+	_, err := ResolveHostCredentials(keyring, []byte(`{"Driver": {"AccessToken": "`+KeyringPrefix+`/store/machines/foo/Driver.AccessToken"}}`))
+	assert.EqualError(t, err, "Error reading AccessToken from the keyring: not found")
+}
+
+func TestStoreAndResolveHostCredentialsKeepIntegers(t *testing.T) {
+	keyring := &fakeKeyring{values: map[string]string{}}
+
+	data := []byte(`{"Name": "foo", "Driver": {"AccessToken": "my-token", "DropletID": 9007199254740993}}`)
+
+	stored, err := StoreHostCredentials(keyring, "/store/machines/foo", data, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, string(stored), "9007199254740993")
+
+	resolved, err := ResolveHostCredentials(keyring, stored)
+	assert.NoError(t, err)
+	assert.Contains(t, string(resolved), "9007199254740993")
+}
@@ -0,0 +1,107 @@
+package secrets
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Redacted replaces the values of the sensitive fields in the output of the
+// configurations.
+const Redacted = "<REDACTED>"
+
+// RedactHost replaces the values of the sensitive fields of the driver
+// section of a serialized host, the ones of SensitiveFields and the extra
+// ones the driver declares, by Redacted. The data is returned untouched if
+// there is nothing to redact.
+func RedactHost(data []byte, extraFields []string) ([]byte, error) {
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, ok := obj["Driver"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	if !redactFields(driver, extraFields) {
+		return data, nil
+	}
+
+	return json.Marshal(obj)
+}
+
+func redactFields(obj map[string]interface{}, extraFields []string) bool {
+	redacted := false
+
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if v == "" || !isSensitiveIn(name, extraFields) {
+				continue
+			}
+			obj[name] = Redacted
+			redacted = true
+		case map[string]interface{}:
+			redacted = redactFields(v, extraFields) || redacted
+		}
+	}
+
+	return redacted
+}
+
+// SensitiveValues returns the values of the sensitive fields of a serialized
+// driver, the ones of SensitiveFields and the extra ones, for them to be
+// redacted wherever they appear, e.g. in the logs.
+func SensitiveValues(driverData []byte, extraFields []string) ([]string, error) {
+	var obj map[string]interface{}
+	if err := json.Unmarshal(driverData, &obj); err != nil {
+		return nil, err
+	}
+
+	return sensitiveValues(obj, extraFields), nil
+}
+
+func sensitiveValues(obj map[string]interface{}, extraFields []string) []string {
+	values := []string{}
+
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if v != "" && !IsEncrypted(v) && !IsKeyringReference(v) && isSensitiveIn(name, extraFields) {
+				values = append(values, v)
+			}
+		case map[string]interface{}:
+			values = append(values, sensitiveValues(v, extraFields)...)
+		}
+	}
+
+	return values
+}
+
+// IsSensitiveFlag tells whether a create flag, e.g. digitalocean-access-token,
+// sets one of the SensitiveFields.
+func IsSensitiveFlag(name string) bool {
+	name = strings.ToLower(strings.Replace(name, "-", "", -1))
+	for _, field := range SensitiveFields {
+		if strings.HasSuffix(name, strings.ToLower(field)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSensitiveIn(name string, extraFields []string) bool {
+	if isSensitive(name) {
+		return true
+	}
+
+	for _, field := range extraFields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const redactTestHost = `{
+	"Name": "dev",
+	"Driver": {
+		"AccessToken": "d0-t0ken",
+		"UserPassword": "hunter22",
+		"Region": "nyc3",
+		"Empty": {"Password": ""}
+	}
+}`
+
+func TestRedactHost(t *testing.T) {
+	data, err := RedactHost([]byte(redactTestHost), []string{"UserPassword"})
+	assert.NoError(t, err)
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &obj))
+
+	driver := obj["Driver"].(map[string]interface{})
+	assert.Equal(t, Redacted, driver["AccessToken"])
+	assert.Equal(t, Redacted, driver["UserPassword"])
+	assert.Equal(t, "nyc3", driver["Region"])
+	assert.Equal(t, "", driver["Empty"].(map[string]interface{})["Password"])
+	assert.Equal(t, "dev", obj["Name"])
+}
+
+func TestRedactHostNothingToRedact(t *testing.T) {
+	data := []byte(`{"Name": "dev", "Driver": {"Region": "nyc3"}}`)
+
+	redacted, err := RedactHost(data, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, data, redacted)
+}
+
+func TestRedactHostKeepsIntegers(t *testing.T) {
+	data, err := RedactHost([]byte(`{"Driver": {"AccessToken": "d0-t0ken", "ProjectID": 123456789012345678}}`), nil)
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"ProjectID":123456789012345678`)
+}
+
+func TestSensitiveValues(t *testing.T) {
+	values, err := SensitiveValues([]byte(`{"AccessToken": "d0-t0ken", "Region": "nyc3", "SecretKey": "`+Prefix+`abc"}`), nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"d0-t0ken"}, values)
+}
+
+func TestIsSensitiveFlag(t *testing.T) {
+	assert.True(t, IsSensitiveFlag("digitalocean-access-token"))
+	assert.True(t, IsSensitiveFlag("amazonec2-secret-key"))
+	assert.True(t, IsSensitiveFlag("vmwarevsphere-password"))
+	assert.False(t, IsSensitiveFlag("digitalocean-region"))
+	assert.False(t, IsSensitiveFlag("amazonec2-keypair-name"))
+}
@@ -0,0 +1,333 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/docker/machine/libmachine/credentials"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Prefix marks the string values of config.json which are encrypted.
+	Prefix = "machine-secret:v2:"
+
+	// prefixV1 marks the values encrypted with a key derived with PBKDF2,
+	// which are still decrypted, and encrypted again with scrypt when the
+	// host is saved.
+	prefixV1 = "machine-secret:v1:"
+
+	saltSize = 16
+	keySize  = 32
+
+	// The cost of scrypt recommended for interactive logins
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	pbkdf2Iterations = 10000
+)
+
+var (
+	ErrNoPassphrase = errors.New("The config encryption passphrase is empty")
+	ErrEncrypted    = errors.New("The host configuration is encrypted, set MACHINE_CONFIG_PASSPHRASE or MACHINE_CONFIG_ENCRYPTION=keyring to decrypt it")
+	ErrCorrupted    = errors.New("Unable to decrypt secret: the value is corrupted or the passphrase is wrong")
+
+	// SensitiveFields are the driver fields encrypted before a host is
+	// written to disk.
+	SensitiveFields = []string{
+		"AccessKey",
+		"AccessToken",
+		"ApiKey",
+		"ApiSecretKey",
+		"ApplicationCredentialSecret",
+		"ClientSecret",
+		"Password",
+		"SecretKey",
+		"SessionToken",
+		"Token",
+	}
+)
+
+// Box encrypts and decrypts values with a key derived from a passphrase.
+// Each value gets its own random nonce. As deriving a key is slow on purpose,
+// the values a Box encrypts share a random salt, and the keys are derived
+// once per salt.
+type Box struct {
+	passphrase []byte
+
+	mutex sync.Mutex
+	salt  []byte
+	keys  map[string][]byte
+}
+
+func NewBox(passphrase string) (*Box, error) {
+	if passphrase == "" {
+		return nil, ErrNoPassphrase
+	}
+
+	return &Box{
+		passphrase: []byte(passphrase),
+		keys:       map[string][]byte{},
+	}, nil
+}
+
+// DefaultBox returns the Box configured by the environment: the passphrase is
+// read from MACHINE_CONFIG_PASSPHRASE, or from the OS keychain when
+// MACHINE_CONFIG_ENCRYPTION is "keyring". It returns nil when encryption is
+// not enabled.
+func DefaultBox() (*Box, error) {
+	if passphrase := os.Getenv("MACHINE_CONFIG_PASSPHRASE"); passphrase != "" {
+		return NewBox(passphrase)
+	}
+
+	if os.Getenv("MACHINE_CONFIG_ENCRYPTION") != "keyring" {
+		return nil, nil
+	}
+
+	passphrase, err := credentials.KeyringSource{}.Get("config", "passphrase")
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the config passphrase from the keyring: %s", err)
+	}
+
+	return NewBox(passphrase)
+}
+
+// key returns the key derived from the passphrase and the salt, with the
+// function of the version of the prefix.
+func (b *Box) key(prefix string, salt []byte) ([]byte, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := prefix + string(salt)
+	if key, ok := b.keys[id]; ok {
+		return key, nil
+	}
+
+	var key []byte
+	if prefix == prefixV1 {
+		key = pbkdf2.Key(b.passphrase, salt, pbkdf2Iterations, keySize, sha256.New)
+	} else {
+		var err error
+		if key, err = scrypt.Key(b.passphrase, salt, scryptN, scryptR, scryptP, keySize); err != nil {
+			return nil, err
+		}
+	}
+
+	b.keys[id] = key
+
+	return key, nil
+}
+
+// encryptionSalt returns the salt of the values the box encrypts.
+func (b *Box) encryptionSalt() ([]byte, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.salt == nil {
+		salt := make([]byte, saltSize)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return nil, err
+		}
+		b.salt = salt
+	}
+
+	return b.salt, nil
+}
+
+func (b *Box) Encrypt(plaintext string) (string, error) {
+	salt, err := b.encryptionSalt()
+	if err != nil {
+		return "", err
+	}
+
+	key, err := b.key(Prefix, salt)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+
+	data := append(append(append([]byte{}, salt...), nonce...), sealed...)
+
+	return Prefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (b *Box) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	prefix := Prefix
+	if strings.HasPrefix(value, prefixV1) {
+		prefix = prefixV1
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(data) < saltSize {
+		return "", ErrCorrupted
+	}
+
+	key, err := b.key(prefix, data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < saltSize+gcm.NonceSize() {
+		return "", ErrCorrupted
+	}
+
+	nonce := data[saltSize : saltSize+gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, data[saltSize+gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrCorrupted
+	}
+
+	return string(plaintext), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, Prefix) || strings.HasPrefix(value, prefixV1)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// SealHost encrypts the sensitive fields of the driver section of a
+// serialized host. The data is returned untouched if there is nothing to
+// encrypt.
+func (b *Box) SealHost(data []byte) ([]byte, error) {
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, ok := obj["Driver"].(map[string]interface{})
+	if !ok {
+		return data, nil
+	}
+
+	sealed, err := b.sealFields(driver)
+	if err != nil {
+		return nil, err
+	}
+	if !sealed {
+		return data, nil
+	}
+
+	return json.MarshalIndent(obj, "", "    ")
+}
+
+func (b *Box) sealFields(obj map[string]interface{}) (bool, error) {
+	sealed := false
+
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			if v == "" || IsEncrypted(v) || !isSensitive(name) {
+				continue
+			}
+
+			encrypted, err := b.Encrypt(v)
+			if err != nil {
+				return false, err
+			}
+
+			obj[name] = encrypted
+			sealed = true
+		case map[string]interface{}:
+			nestedSealed, err := b.sealFields(v)
+			if err != nil {
+				return false, err
+			}
+			sealed = sealed || nestedSealed
+		}
+	}
+
+	return sealed, nil
+}
+
+// OpenHost decrypts every encrypted value of a serialized host. A nil Box
+// may be used as long as the data has no encrypted value.
+func (b *Box) OpenHost(data []byte) ([]byte, error) {
+	if !bytes.Contains(data, []byte(Prefix)) && !bytes.Contains(data, []byte(prefixV1)) {
+		return data, nil
+	}
+
+	if b == nil {
+		return nil, ErrEncrypted
+	}
+
+	obj, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.openFields(obj); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(obj)
+}
+
+func (b *Box) openFields(obj map[string]interface{}) error {
+	for name, value := range obj {
+		switch v := value.(type) {
+		case string:
+			decrypted, err := b.Decrypt(v)
+			if err != nil {
+				return fmt.Errorf("Error decrypting %s: %s", name, err)
+			}
+			obj[name] = decrypted
+		case map[string]interface{}:
+			if err := b.openFields(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isSensitive(name string) bool {
+	for _, field := range SensitiveFields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+
+	return false
+}
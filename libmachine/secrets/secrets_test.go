@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	box, err := NewBox("passphrase")
+	assert.NoError(t, err)
+
+	encrypted, err := box.Encrypt("my-token")
+	assert.NoError(t, err)
+	assert.True(t, IsEncrypted(encrypted))
+	assert.False(t, strings.Contains(encrypted, "my-token"))
+
+	decrypted, err := box.Decrypt(encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, "my-token", decrypted)
+}
+
+func TestDecryptV1(t *testing.T) {
+	box, _ := NewBox("passphrase")
+
+	// Encrypted with a key derived with PBKDF2
+	decrypted, err := box.Decrypt("machine-secret:v1:Wl9nLElxnAcqelcgKU4iroUIf5Sgz4rV4+5gcJFX+WoiKkdvx4+kRMSjj9GFSc5Ea+jgOQ==")
+	assert.NoError(t, err)
+	assert.Equal(t, "my-token", decrypted)
+}
+
+func TestDecryptWrongPassphrase(t *testing.T) {
+	box, _ := NewBox("passphrase")
+	otherBox, _ := NewBox("other")
+
+	encrypted, err := box.Encrypt("my-token")
+	assert.NoError(t, err)
+
+	_, err = otherBox.Decrypt(encrypted)
+	assert.Equal(t, ErrCorrupted, err)
+}
+
+func TestDecryptPlaintext(t *testing.T) {
+	box, _ := NewBox("passphrase")
+
+	decrypted, err := box.Decrypt("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, "plain", decrypted)
+}
+
+func TestNewBoxEmptyPassphrase(t *testing.T) {
+	_, err := NewBox("")
+	assert.Equal(t, ErrNoPassphrase, err)
+}
+
+func TestSealAndOpenHost(t *testing.T) {
+	box, _ := NewBox("passphrase")
+
+	data := []byte(`{"Name": "foo", "Driver": {"AccessToken": "my-token", "Region": "nyc3", "Nested": {"Password": "pass"}}}`)
+
+	sealed, err := box.SealHost(data)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(sealed), "my-token"))
+	assert.False(t, strings.Contains(string(sealed), "pass\""))
+	assert.True(t, strings.Contains(string(sealed), "nyc3"))
+
+	opened, err := box.OpenHost(sealed)
+	assert.NoError(t, err)
+
+	var obj map[string]interface{}
+	assert.NoError(t, json.Unmarshal(opened, &obj))
+
+	driver := obj["Driver"].(map[string]interface{})
+	assert.Equal(t, "my-token", driver["AccessToken"])
+	assert.Equal(t, "nyc3", driver["Region"])
+	assert.Equal(t, "pass", driver["Nested"].(map[string]interface{})["Password"])
+}
+
+func TestSealHostWithoutSecrets(t *testing.T) {
+	box, _ := NewBox("passphrase")
+
+	data := []byte(`{"Name": "foo", "Driver": {"Region": "nyc3"}}`)
+
+	sealed, err := box.SealHost(data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, sealed)
+}
+
+func TestOpenHostWithoutBox(t *testing.T) {
+	var box *Box
+
+	data := []byte(`{"Name": "foo"}`)
+	opened, err := box.OpenHost(data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, opened)
+
+	_, err = box.OpenHost([]byte(`{"Driver": {"AccessToken": "` + Prefix + `abc"}}`))
+	assert.Equal(t, ErrEncrypted, err)
+}
+
+func TestDefaultBox(t *testing.T) {
+	defer os.Unsetenv("MACHINE_CONFIG_PASSPHRASE")
+
+	os.Unsetenv("MACHINE_CONFIG_PASSPHRASE")
+	box, err := DefaultBox()
+	assert.NoError(t, err)
+	assert.Nil(t, box)
+
+	os.Setenv("MACHINE_CONFIG_PASSPHRASE", "passphrase")
+	box, err = DefaultBox()
+	assert.NoError(t, err)
+	assert.NotNil(t, box)
+}
+
+func TestSealAndOpenHostKeepIntegers(t *testing.T) {
+	box, _ := NewBox("passphrase")
+
+	data := []byte(`{"Name": "foo", "Driver": {"AccessToken": "my-token", "DropletID": 9007199254740993}}`)
+
+	sealed, err := box.SealHost(data)
+	assert.NoError(t, err)
+	assert.Contains(t, string(sealed), "9007199254740993")
+
+	opened, err := box.OpenHost(sealed)
+	assert.NoError(t, err)
+	assert.Contains(t, string(opened), "9007199254740993")
+}
@@ -0,0 +1,346 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/engine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+const (
+	machinesPrefix = "/machines"
+)
+
+var (
+	ErrMethodNotAllowed = errors.New("Method not allowed")
+	ErrNotFound         = errors.New("Not found")
+	ErrNoMachineName    = errors.New("No machine name specified")
+)
+
+// APIFactory returns a fresh libmachine.API. The server opens one API per
+// request and closes it afterwards, so that the driver plugin processes
+// spawned while serving a request do not pile up in a long-running process.
+type APIFactory func() libmachine.API
+
+// Server exposes the host operations of libmachine as JSON endpoints:
+//
+//	GET    /machines               list the machines in the store
+//	POST   /machines               create a machine (see CreateRequest)
+//	GET    /machines/<name>        inspect a machine
+//	DELETE /machines/<name>        remove a machine and its instance
+//	                               (override-protection=true to remove
+//	                               a protected machine)
+//	POST   /machines/<name>/<op>   run start, stop, restart or kill
+//
+// The operations are recorded in the audit log of the store, as the same
+// operations of the CLI are.
+//
+// The machines are returned with the secrets of their driver redacted,
+// unless the request has the show-secrets=true query parameter.
+type Server struct {
+	newAPI APIFactory
+}
+
+// MachineSummary is the representation of a machine in listings.
+type MachineSummary struct {
+	Name       string
+	DriverName string
+	State      string
+	URL        string
+	Error      string
+}
+
+// CreateRequest is the payload expected when creating a machine. The
+// DriverOptions keys are the driver create flag names, e.g.
+// "virtualbox-memory". Options which are not set take the driver defaults.
+type CreateRequest struct {
+	Name          string
+	DriverName    string
+	DriverOptions map[string]interface{}
+	EngineOptions *engine.Options
+	SwarmOptions  *swarm.Options
+}
+
+type errorResponse struct {
+	Error string
+}
+
+func NewServer(newAPI APIFactory) *Server {
+	return &Server{
+		newAPI: newAPI,
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("%s %s", r.Method, r.URL.Path)
+
+	if r.URL.Path != machinesPrefix && !strings.HasPrefix(r.URL.Path, machinesPrefix+"/") {
+		writeError(w, ErrNotFound)
+		return
+	}
+
+	api := s.newAPI()
+	defer api.Close()
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, machinesPrefix), "/"), "/")
+
+	switch {
+	case parts[0] == "":
+		s.handleMachines(api, w, r)
+	case len(parts) == 1:
+		s.handleMachine(api, parts[0], w, r)
+	case len(parts) == 2:
+		s.handleAction(api, parts[0], parts[1], w, r)
+	default:
+		writeError(w, ErrNotFound)
+	}
+}
+
+func (s *Server) handleMachines(api libmachine.API, w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		hosts, hostsInError, err := persist.LoadAllHosts(api)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, summarize(hosts, hostsInError))
+	case "POST":
+		var req CreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{fmt.Sprintf("Error decoding request: %s", err)})
+			return
+		}
+
+		h, err := create(api, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeHost(w, r, http.StatusCreated, h)
+	default:
+		writeError(w, ErrMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleMachine(api libmachine.API, name string, w http.ResponseWriter, r *http.Request) {
+	h, err := api.Load(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch r.Method {
+	case "GET":
+		writeHost(w, r, http.StatusOK, h)
+	case "DELETE":
+		overrideProtection := r.URL.Query().Get("override-protection") == "true"
+		if err := libmachine.RemoveInstance(api, name, overrideProtection); err != nil {
+			if _, ok := err.(mcnerror.ErrHostProtected); ok {
+				writeError(w, err)
+				return
+			}
+			writeError(w, fmt.Errorf("Error removing host %q: %s", name, err))
+			return
+		}
+
+		if err := libmachine.RemoveMachine(api, name); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, ErrMethodNotAllowed)
+	}
+}
+
+// serverActions are the actions of libmachine.RunAction the server runs.
+var serverActions = map[string]bool{
+	"start":   true,
+	"stop":    true,
+	"restart": true,
+	"kill":    true,
+}
+
+func (s *Server) handleAction(api libmachine.API, name, action string, w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		writeError(w, ErrMethodNotAllowed)
+		return
+	}
+
+	h, err := api.Load(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if !serverActions[action] {
+		writeError(w, ErrNotFound)
+		return
+	}
+
+	if err := libmachine.RunAction(api, h, action); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := api.Save(h); err != nil {
+		writeError(w, fmt.Errorf("Error saving host to store: %s", err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, summarize([]*host.Host{h}, nil)[0])
+}
+
+func summarize(hosts []*host.Host, hostsInError map[string]error) []MachineSummary {
+	summaries := []MachineSummary{}
+
+	for _, h := range hosts {
+		summary := MachineSummary{
+			Name:       h.Name,
+			DriverName: h.DriverName,
+		}
+
+		currentState, err := h.Driver.GetState()
+		if err != nil {
+			summary.Error = err.Error()
+		} else {
+			summary.State = currentState.String()
+			if url, err := h.URL(); err == nil {
+				summary.URL = url
+			}
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	for name, err := range hostsInError {
+		summaries = append(summaries, MachineSummary{
+			Name:  name,
+			Error: err.Error(),
+		})
+	}
+
+	return summaries
+}
+
+func create(api libmachine.API, req *CreateRequest) (*host.Host, error) {
+	if req.Name == "" {
+		return nil, ErrNoMachineName
+	}
+
+	if !host.ValidateHostName(req.Name) {
+		return nil, mcnerror.ErrInvalidHostname
+	}
+
+	exists, err := api.Exists(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, mcnerror.ErrHostAlreadyExists{
+			Name: req.Name,
+		}
+	}
+
+	storePath := filepath.Dir(api.GetMachinesDir())
+	rawDriver, err := json.Marshal(&drivers.BaseDriver{
+		MachineName: req.Name,
+		StorePath:   storePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error attempting to marshal bare driver data: %s", err)
+	}
+
+	h, err := api.NewHost(req.DriverName, rawDriver)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting new host: %s", err)
+	}
+
+	if req.EngineOptions != nil {
+		h.HostOptions.EngineOptions = req.EngineOptions
+	}
+	if req.SwarmOptions != nil {
+		h.HostOptions.SwarmOptions = req.SwarmOptions
+	}
+
+	driverOpts := libmachine.DriverOptionsFromValues(h.Driver.GetCreateFlags(), req.DriverOptions, h.HostOptions.SwarmOptions)
+	if err := h.Driver.SetConfigFromFlags(driverOpts); err != nil {
+		return nil, fmt.Errorf("Error setting machine configuration from flags provided: %s", err)
+	}
+
+	if err := api.Create(h); err != nil {
+		return nil, err
+	}
+
+	if err := api.Save(h); err != nil {
+		return nil, fmt.Errorf("Error attempting to save store: %s", err)
+	}
+
+	return h, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Debugf("Error encoding response: %s", err)
+	}
+}
+
+// writeHost writes the configuration of the machine, with the secrets of its
+// driver redacted unless the request asks to show them.
+func writeHost(w http.ResponseWriter, r *http.Request, status int, h *host.Host) {
+	var (
+		data []byte
+		err  error
+	)
+	if r.URL.Query().Get("show-secrets") == "true" {
+		data, err = json.Marshal(h)
+	} else {
+		data, err = host.MarshalRedacted(h)
+	}
+	if err != nil {
+		writeError(w, fmt.Errorf("Error encoding host %q: %s", h.Name, err))
+		return
+	}
+
+	writeJSON(w, status, json.RawMessage(data))
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch err.(type) {
+	case mcnerror.ErrHostDoesNotExist:
+		status = http.StatusNotFound
+	case mcnerror.ErrHostAlreadyExists, mcnerror.ErrHostAlreadyInState, mcnerror.ErrHostProtected:
+		status = http.StatusConflict
+	}
+
+	switch err {
+	case ErrNotFound:
+		status = http.StatusNotFound
+	case ErrMethodNotAllowed:
+		status = http.StatusMethodNotAllowed
+	case ErrNoMachineName, mcnerror.ErrInvalidHostname:
+		status = http.StatusBadRequest
+	}
+
+	writeJSON(w, status, errorResponse{err.Error()})
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/libmachinetest"
+	"github.com/docker/machine/libmachine/persist"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServer(hosts ...*host.Host) *Server {
+	api := &libmachinetest.FakeAPI{
+		Hosts: hosts,
+	}
+
+	return NewServer(func() libmachine.API {
+		return api
+	})
+}
+
+func newTestHost(name string, s state.State) *host.Host {
+	return &host.Host{
+		Name:       name,
+		DriverName: "fake",
+		Driver: &fakedriver.Driver{
+			MockState: s,
+			MockIP:    "1.2.3.4",
+		},
+	}
+}
+
+func doRequest(s *Server, method, path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestInspect(t *testing.T) {
+	s := newTestServer(newTestHost("foo", state.Running))
+
+	recorder := doRequest(s, "GET", "/machines/foo")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	assert.Equal(t, "foo", decoded["Name"])
+}
+
+// secretDriver is a driver whose configuration holds a secret.
+type secretDriver struct {
+	*fakedriver.Driver
+	AccessToken string
+}
+
+func newSecretTestHost(name string) *host.Host {
+	h := newTestHost(name, state.Running)
+	h.Driver = &secretDriver{
+		Driver:      h.Driver.(*fakedriver.Driver),
+		AccessToken: "s3cr3t",
+	}
+	return h
+}
+
+func TestInspectRedactsSecrets(t *testing.T) {
+	s := newTestServer(newSecretTestHost("foo"))
+
+	recorder := doRequest(s, "GET", "/machines/foo")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "s3cr3t")
+
+	var decoded struct {
+		Driver map[string]interface{}
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+	assert.Equal(t, "<REDACTED>", decoded.Driver["AccessToken"])
+}
+
+func TestInspectShowSecrets(t *testing.T) {
+	s := newTestServer(newSecretTestHost("foo"))
+
+	recorder := doRequest(s, "GET", "/machines/foo?show-secrets=true")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"AccessToken":"s3cr3t"`)
+}
+
+func TestInspectUnknownMachine(t *testing.T) {
+	s := newTestServer()
+
+	recorder := doRequest(s, "GET", "/machines/foo")
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestStop(t *testing.T) {
+	h := newTestHost("foo", state.Running)
+	s := newTestServer(h)
+
+	recorder := doRequest(s, "POST", "/machines/foo/stop")
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var summary MachineSummary
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &summary))
+	assert.Equal(t, "Stopped", summary.State)
+}
+
+func TestStopAlreadyStopped(t *testing.T) {
+	s := newTestServer(newTestHost("foo", state.Stopped))
+
+	recorder := doRequest(s, "POST", "/machines/foo/stop")
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestActionsAreAudited(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{newTestHost("foo", state.Running)},
+	}
+	s := NewServer(func() libmachine.API {
+		return api
+	})
+
+	doRequest(s, "POST", "/machines/foo/stop")
+	doRequest(s, "POST", "/machines/foo/stop")
+
+	assert.Len(t, api.Audit, 2)
+	assert.Equal(t, "foo", api.Audit[0].Machine)
+	assert.Equal(t, persist.AuditStop, api.Audit[0].Operation)
+	assert.Empty(t, api.Audit[0].Error)
+	assert.NotEmpty(t, api.Audit[1].Error)
+}
+
+func TestUnknownAction(t *testing.T) {
+	s := newTestServer(newTestHost("foo", state.Running))
+
+	assert.Equal(t, http.StatusNotFound, doRequest(s, "POST", "/machines/foo/explode").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, doRequest(s, "GET", "/machines/foo/stop").Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(s, "GET", "/other").Code)
+}
+
+func TestRemove(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{newTestHost("foo", state.Running)},
+	}
+	s := NewServer(func() libmachine.API {
+		return api
+	})
+
+	recorder := doRequest(s, "DELETE", "/machines/foo")
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.False(t, libmachinetest.Exists(api, "foo"))
+}
+
+func TestRemoveIsAudited(t *testing.T) {
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{newTestHost("foo", state.Running)},
+	}
+	s := NewServer(func() libmachine.API {
+		return api
+	})
+
+	doRequest(s, "DELETE", "/machines/foo")
+
+	assert.Len(t, api.Audit, 1)
+	assert.Equal(t, persist.AuditRemove, api.Audit[0].Operation)
+}
+
+func TestRemoveProtected(t *testing.T) {
+	h := newTestHost("foo", state.Running)
+	h.HostOptions = &host.Options{Protected: true}
+	api := &libmachinetest.FakeAPI{
+		Hosts: []*host.Host{h},
+	}
+	s := NewServer(func() libmachine.API {
+		return api
+	})
+
+	recorder := doRequest(s, "DELETE", "/machines/foo")
+
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	assert.True(t, libmachinetest.Exists(api, "foo"))
+
+	recorder = doRequest(s, "DELETE", "/machines/foo?override-protection=true")
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.False(t, libmachinetest.Exists(api, "foo"))
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/mcnutils"
+)
+
+// EnsureServerCertificate generates the certificate the API server presents
+// to its clients, signed by the machine CA, unless it already exists.
+func EnsureServerCertificate(authOptions *auth.Options, certPath, keyPath string, hosts []string) error {
+	if _, err := os.Stat(certPath); err == nil {
+		return nil
+	}
+
+	log.Infof("Creating API server certificate: %s", certPath)
+
+	return cert.GenerateCert(&cert.Options{
+		Hosts:     append(hosts, "localhost", "127.0.0.1"),
+		CertFile:  certPath,
+		KeyFile:   keyPath,
+		CAFile:    authOptions.CaCertPath,
+		CAKeyFile: authOptions.CaPrivateKeyPath,
+		Org:       mcnutils.GetUsername() + ".<serve>",
+		Bits:      2048,
+	})
+}
+
+// ClientCertificates are the files of the certificates the clients of the
+// API authenticate with, signed by a CA of their own rather than by the
+// machine CA, which also signs the certificates of the machines, e.g. the
+// swarm masters authenticating as clients to their nodes.
+type ClientCertificates struct {
+	CaCertPath       string
+	CaPrivateKeyPath string
+	CertPath         string
+	KeyPath          string
+}
+
+// EnsureClientCertificates generates the CA of the API clients and a client
+// certificate signed by it, unless they already exist.
+func EnsureClientCertificates(certs ClientCertificates) error {
+	org := mcnutils.GetUsername() + ".<serve-clients>"
+
+	if _, err := os.Stat(certs.CaCertPath); os.IsNotExist(err) {
+		log.Infof("Creating API client CA: %s", certs.CaCertPath)
+
+		if err := cert.GenerateCA(&cert.Options{
+			CertFile: certs.CaCertPath,
+			KeyFile:  certs.CaPrivateKeyPath,
+			Org:      org,
+			Bits:     2048,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if _, err := os.Stat(certs.CertPath); err == nil {
+		return nil
+	}
+
+	log.Infof("Creating API client certificate: %s", certs.CertPath)
+
+	return cert.GenerateCert(&cert.Options{
+		Hosts:     []string{""},
+		CertFile:  certs.CertPath,
+		KeyFile:   certs.KeyPath,
+		CAFile:    certs.CaCertPath,
+		CAKeyFile: certs.CaPrivateKeyPath,
+		Org:       org,
+		Bits:      2048,
+	})
+}
+
+// NewTLSConfig returns a TLS configuration which only accepts clients
+// presenting a certificate signed by the CA of the API clients, see
+// EnsureClientCertificates.
+func NewTLSConfig(clientCaCertPath, certPath, keyPath string) (*tls.Config, error) {
+	caCert, err := ioutil.ReadFile(clientCaCertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("There was an error reading the CA certificate")
+	}
+
+	keypair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{keypair},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    certPool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ListenAndServeTLS serves the API on addr until an error occurs.
+func (s *Server) ListenAndServeTLS(addr string, tlsConfig *tls.Config) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Error listening on %s: %s", addr, err)
+	}
+
+	log.Infof("Serving the machine API on https://%s", listener.Addr())
+
+	return http.Serve(tls.NewListener(listener, tlsConfig), s)
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/cert"
+	"github.com/stretchr/testify/assert"
+)
+
+func verifyClient(t *testing.T, config *tls.Config, certPath, keyPath string) error {
+	keypair, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientCert, err := x509.ParseCertificate(keypair.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = clientCert.Verify(x509.VerifyOptions{
+		Roots:     config.ClientCAs,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err
+}
+
+func TestTLSConfigOnlyAcceptsAPIClients(t *testing.T) {
+	dir, err := ioutil.TempDir("", "machine-serve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	authOptions := &auth.Options{
+		CertDir:          dir,
+		CaCertPath:       filepath.Join(dir, "ca.pem"),
+		CaPrivateKeyPath: filepath.Join(dir, "ca-key.pem"),
+		ClientCertPath:   filepath.Join(dir, "cert.pem"),
+		ClientKeyPath:    filepath.Join(dir, "key.pem"),
+	}
+	assert.NoError(t, cert.BootstrapCertificates(authOptions))
+
+	serverCert := filepath.Join(dir, "serve.pem")
+	serverKey := filepath.Join(dir, "serve-key.pem")
+	assert.NoError(t, EnsureServerCertificate(authOptions, serverCert, serverKey, nil))
+
+	// A swarm master authenticates as a client to its nodes
+	swarmCert := filepath.Join(dir, "swarm.pem")
+	swarmKey := filepath.Join(dir, "swarm-key.pem")
+	assert.NoError(t, cert.GenerateCert(&cert.Options{
+		Hosts:       []string{"192.168.99.100"},
+		CertFile:    swarmCert,
+		KeyFile:     swarmKey,
+		CAFile:      authOptions.CaCertPath,
+		CAKeyFile:   authOptions.CaPrivateKeyPath,
+		Org:         "test.swarm",
+		Bits:        2048,
+		SwarmMaster: true,
+	}))
+
+	clientCerts := ClientCertificates{
+		CaCertPath:       filepath.Join(dir, "serve-ca.pem"),
+		CaPrivateKeyPath: filepath.Join(dir, "serve-ca-key.pem"),
+		CertPath:         filepath.Join(dir, "serve-client.pem"),
+		KeyPath:          filepath.Join(dir, "serve-client-key.pem"),
+	}
+	assert.NoError(t, EnsureClientCertificates(clientCerts))
+
+	config, err := NewTLSConfig(clientCerts.CaCertPath, serverCert, serverKey)
+	assert.NoError(t, err)
+
+	assert.NoError(t, verifyClient(t, config, clientCerts.CertPath, clientCerts.KeyPath))
+	assert.Error(t, verifyClient(t, config, swarmCert, swarmKey))
+	assert.Error(t, verifyClient(t, config, authOptions.ClientCertPath, authOptions.ClientKeyPath))
+}
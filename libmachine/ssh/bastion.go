@@ -0,0 +1,186 @@
+package ssh
+
+import (
+	"fmt"
+	"net"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+)
+
+// Bastion is a host the SSH connections to a machine go through, for the
+// machines on private networks without a public address, as with the
+// ProxyJump option of ssh.
+type Bastion struct {
+	User string
+	Host string
+	Port int
+
+	// KeyPath is the private key authenticating with the bastion, the key
+	// of the machine by default.
+	KeyPath string `json:",omitempty"`
+}
+
+// ParseBastion parses a bastion given as [user@]host[:port]. The user
+// defaults to the local one as with ssh, and the port to 22.
+func ParseBastion(value string) (*Bastion, error) {
+	bastion := &Bastion{Port: 22}
+
+	if i := strings.LastIndex(value, "@"); i >= 0 {
+		bastion.User = value[:i]
+		value = value[i+1:]
+	}
+
+	bastion.Host = value
+	if host, port, err := net.SplitHostPort(value); err == nil {
+		bastion.Host = host
+		if bastion.Port, err = strconv.Atoi(port); err != nil || bastion.Port < 1 || bastion.Port > 65535 {
+			return nil, fmt.Errorf("Invalid port %q of the bastion", port)
+		}
+	}
+
+	if bastion.Host == "" || strings.ContainsAny(bastion.Host, " :/") {
+		return nil, fmt.Errorf("Invalid bastion %q, expected [user@]host[:port]", value)
+	}
+
+	return bastion, nil
+}
+
+func (b *Bastion) String() string {
+	address := net.JoinHostPort(b.Host, strconv.Itoa(b.Port))
+	if b.User == "" {
+		return address
+	}
+	return b.User + "@" + address
+}
+
+// knownHosts locates the host key of the bastion in the given known_hosts
+// file of the machines, under an alias no machine can take.
+func (b *Bastion) knownHosts(file string) KnownHosts {
+	return KnownHosts{
+		File:  file,
+		Alias: "bastion:" + net.JoinHostPort(b.Host, strconv.Itoa(b.Port)),
+	}
+}
+
+// hostKeyCallback checks the key presented by the bastion against the key
+// recorded the first time the bastion was reached, which is then recorded.
+func (b *Bastion) hostKeyCallback(file string) ssh.HostKeyCallback {
+	knownHosts := b.knownHosts(file)
+	check := hostKeyCallback(knownHosts)
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		keys, err := hostKeys(knownHosts)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 && knownHosts.enabled() {
+			return recordHostKey(knownHosts, key)
+		}
+
+		return check(hostname, remote, key)
+	}
+}
+
+// user returns the user logging into the bastion, the local one by default
+// as with ssh.
+func (b *Bastion) user() string {
+	if b.User != "" {
+		return b.User
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		log.Debugf("Error getting the current user for the bastion %s: %s", b, err)
+		return ""
+	}
+
+	// Windows user names are prefixed with their domain
+	name := current.Username
+	if i := strings.LastIndex(name, `\`); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// ProxyCommand returns the command the ssh binary reaches the machine with
+// through the bastion, as its ProxyCommand option. The host key of the
+// bastion is checked once recorded in the given known_hosts file.
+func (b *Bastion) ProxyCommand(knownHostsFile string) string {
+	args := append([]string{"ssh"}, externalHostKeyArgs(b.knownHosts(knownHostsFile))...)
+	args = append(args, baseSSHArgs...)
+	if b.KeyPath != "" {
+		args = append(args, "-o", "IdentitiesOnly=yes", "-i", shellQuote(b.KeyPath))
+	}
+	args = append(args, "-p", strconv.Itoa(b.Port), "-W", "%h:%p")
+	if b.User != "" {
+		args = append(args, "-l", b.User)
+	}
+	args = append(args, b.Host)
+
+	return strings.Join(args, " ")
+}
+
+// dial opens an SSH connection to the address through the bastion. The
+// connection to the bastion is closed along with it.
+func (b *Bastion) dial(addr string, config *ssh.ClientConfig, knownHostsFile string) (*ssh.Client, error) {
+	auth := &Auth{}
+	if b.KeyPath != "" {
+		auth.Keys = []string{b.KeyPath}
+	}
+
+	bastionConfig, err := NewNativeConfig(b.user(), auth)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting config for the bastion %s: %s", b, err)
+	}
+	bastionConfig.HostKeyCallback = b.hostKeyCallback(knownHostsFile)
+	bastionConfig.Timeout = config.Timeout
+
+	bastion, err := ssh.Dial("tcp", net.JoinHostPort(b.Host, strconv.Itoa(b.Port)), &bastionConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Error connecting to the bastion %s: %s", b, err)
+	}
+
+	conn, err := bastion.Dial("tcp", addr)
+	if err != nil {
+		closeConn(bastion)
+		return nil, fmt.Errorf("Error connecting to %s through the bastion %s: %s", addr, b, err)
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		closeConn(conn)
+		closeConn(bastion)
+		return nil, err
+	}
+
+	client := ssh.NewClient(c, chans, reqs)
+	go func() {
+		client.Wait()
+		closeConn(bastion)
+	}()
+
+	return client, nil
+}
+
+// dial opens an SSH connection to the address, through the bastion if any,
+// whose host key is recorded in the given known_hosts file.
+func dial(addr string, config *ssh.ClientConfig, bastion *Bastion, knownHostsFile string) (*ssh.Client, error) {
+	if bastion == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+	return bastion.dial(addr, config, knownHostsFile)
+}
+
+// shellQuote quotes the paths with spaces in a command run by a shell,
+// e.g. the ProxyCommand.
+func shellQuote(value string) string {
+	if strings.ContainsAny(value, " \t'\"") {
+		return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+	}
+	return value
+}
@@ -0,0 +1,70 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBastion(t *testing.T) {
+	bastion, err := ParseBastion("ubuntu@bastion.example.com:2222")
+	assert.NoError(t, err)
+	assert.Equal(t, &Bastion{User: "ubuntu", Host: "bastion.example.com", Port: 2222}, bastion)
+	assert.Equal(t, "ubuntu@bastion.example.com:2222", bastion.String())
+
+	bastion, err = ParseBastion("10.0.0.5")
+	assert.NoError(t, err)
+	assert.Equal(t, &Bastion{Host: "10.0.0.5", Port: 22}, bastion)
+
+	_, err = ParseBastion("bastion:ssh")
+	assert.Error(t, err)
+
+	_, err = ParseBastion("ubuntu@")
+	assert.Error(t, err)
+}
+
+func TestBastionProxyCommand(t *testing.T) {
+	bastion := &Bastion{User: "ubuntu", Host: "bastion.example.com", Port: 2222, KeyPath: "/home/Jane Doe/bastion.pem"}
+
+	command := bastion.ProxyCommand("")
+
+	assert.True(t, strings.HasPrefix(command, "ssh -F /dev/null "))
+	assert.True(t, strings.HasSuffix(command, ` -o IdentitiesOnly=yes -i '/home/Jane Doe/bastion.pem' -p 2222 -W %h:%p -l ubuntu bastion.example.com`))
+}
+
+func TestExternalClientThroughBastion(t *testing.T) {
+	bastion := &Bastion{Host: "bastion.example.com", Port: 22}
+
+	client, err := NewExternalClient("/usr/bin/ssh", "docker", "10.0.1.12", 22, &Auth{Bastion: bastion})
+
+	assert.NoError(t, err)
+	assert.Contains(t, client.BaseArgs, "ProxyCommand="+bastion.ProxyCommand(""))
+}
+
+func TestConnKeyWithBastion(t *testing.T) {
+	direct := connKey("docker", "10.0.1.12", 22, &Auth{})
+	throughBastion := connKey("docker", "10.0.1.12", 22, &Auth{Bastion: &Bastion{Host: "bastion.example.com", Port: 22}})
+
+	assert.NotEqual(t, direct, throughBastion)
+}
+
+func TestBastionHostKeyRecordedThenChecked(t *testing.T) {
+	bastion := &Bastion{Host: "bastion.example.com", Port: 22}
+	key := newTestHostKey(t)
+
+	withKnownHostsFile(t, "", func(path string) {
+		assert.NoError(t, bastion.hostKeyCallback(path)("bastion.example.com:22", nil, key))
+		assert.NoError(t, bastion.hostKeyCallback(path)("bastion.example.com:22", nil, key))
+
+		err := bastion.hostKeyCallback(path)("bastion.example.com:22", nil, newTestHostKey(t))
+		assert.Equal(t, ErrHostKeyMismatch{Alias: "bastion:bastion.example.com:22", File: path}, err)
+
+		assert.Contains(t, bastion.ProxyCommand(path), "HostKeyAlias=bastion:bastion.example.com:22")
+	})
+}
+
+func TestBastionUserDefaultsToLocalUser(t *testing.T) {
+	assert.Equal(t, "ubuntu", (&Bastion{User: "ubuntu"}).user())
+	assert.NotEmpty(t, (&Bastion{}).user())
+}
@@ -35,6 +35,12 @@ type Client interface {
 	Wait() error
 }
 
+// InputClient is implemented by clients able to feed a command with data,
+// e.g. to copy a file to the remote host with "cat > path".
+type InputClient interface {
+	OutputWithInput(command string, input io.Reader) (string, error)
+}
+
 type ExternalClient struct {
 	BaseArgs   []string
 	BinaryPath string
@@ -47,11 +53,30 @@ type NativeClient struct {
 	Port        int
 	openSession *ssh.Session
 	openClient  *ssh.Client
+
+	// Bastion is the host connections go through, if any.
+	Bastion *Bastion
+
+	// KnownHostsFile is the known_hosts file where the host key of the
+	// bastion is recorded.
+	KnownHostsFile string
+
+	// connKey is the key under which the connection of the client is
+	// shared, see SetMultiplexing.
+	connKey string
 }
 
 type Auth struct {
 	Passwords []string
 	Keys      []string
+
+	// KnownHosts is where the host key of the machine is recorded. Using
+	// the machine name rather than its address keeps the key valid when the
+	// address of the machine changes.
+	KnownHosts KnownHosts
+
+	// Bastion is the host to connect through, nil to connect directly.
+	Bastion *Bastion
 }
 
 type ClientType string
@@ -74,7 +99,7 @@ var (
 		"-o", "LogLevel=quiet", // suppress "Warning: Permanently added '[localhost]:2022' (ECDSA) to the list of known hosts."
 		"-o", "ConnectionAttempts=3", // retry 3 times if SSH connection fails
 		"-o", "ConnectTimeout=10", // timeout after 10 seconds
-		"-o", "ControlMaster=no", // disable ssh multiplexing
+		"-o", "ControlMaster=no", // no ssh multiplexing unless SetMultiplexing is called
 		"-o", "ControlPath=none",
 	}
 	defaultClientType = External
@@ -121,9 +146,12 @@ func NewNativeClient(user, host string, port int, auth *Auth) (Client, error) {
 	}
 
 	return &NativeClient{
-		Config:   config,
-		Hostname: host,
-		Port:     port,
+		Config:         config,
+		Hostname:       host,
+		Port:           port,
+		Bastion:        auth.Bastion,
+		KnownHostsFile: auth.KnownHosts.File,
+		connKey:        connKey(user, host, port, auth),
 	}, nil
 }
 
@@ -153,12 +181,12 @@ func NewNativeConfig(user string, auth *Auth) (ssh.ClientConfig, error) {
 	return ssh.ClientConfig{
 		User:            user,
 		Auth:            authMethods,
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		HostKeyCallback: hostKeyCallback(auth.KnownHosts),
 	}, nil
 }
 
 func (client *NativeClient) dialSuccess() bool {
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := dial(net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config, client.Bastion, client.KnownHostsFile)
 	if err != nil {
 		log.Debugf("Error dialing TCP: %s", err)
 		return false
@@ -168,17 +196,31 @@ func (client *NativeClient) dialSuccess() bool {
 }
 
 func (client *NativeClient) session(command string) (*ssh.Client, *ssh.Session, error) {
+	if conn := acquireConn(client.connKey); conn != nil {
+		session, err := conn.NewSession()
+		if err == nil {
+			return conn, session, nil
+		}
+		log.Debugf("Error reusing the SSH connection to %s: %s", client.connKey, err)
+		dropConn(conn)
+	}
+
 	if err := mcnutils.WaitFor(client.dialSuccess); err != nil {
 		return nil, nil, fmt.Errorf("Error attempting SSH client dial: %s", err)
 	}
 
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := dial(net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config, client.Bastion, client.KnownHostsFile)
 	if err != nil {
 		return nil, nil, fmt.Errorf("Mysterious error dialing TCP for SSH (we already succeeded at least once) : %s", err)
 	}
 	session, err := conn.NewSession()
+	if err != nil {
+		closeConn(conn)
+		return nil, nil, err
+	}
+	shareConn(client.connKey, conn)
 
-	return conn, session, err
+	return conn, session, nil
 }
 
 func (client *NativeClient) Output(command string) (string, error) {
@@ -186,7 +228,7 @@ func (client *NativeClient) Output(command string) (string, error) {
 	if err != nil {
 		return "", nil
 	}
-	defer closeConn(conn)
+	defer releaseConn(conn)
 	defer session.Close()
 
 	output, err := session.CombinedOutput(command)
@@ -194,12 +236,26 @@ func (client *NativeClient) Output(command string) (string, error) {
 	return string(output), err
 }
 
+func (client *NativeClient) OutputWithInput(command string, input io.Reader) (string, error) {
+	conn, session, err := client.session(command)
+	if err != nil {
+		return "", err
+	}
+	defer releaseConn(conn)
+	defer session.Close()
+
+	session.Stdin = input
+	output, err := session.CombinedOutput(command)
+
+	return string(output), err
+}
+
 func (client *NativeClient) OutputWithPty(command string) (string, error) {
 	conn, session, err := client.session(command)
 	if err != nil {
 		return "", nil
 	}
-	defer closeConn(conn)
+	defer releaseConn(conn)
 	defer session.Close()
 
 	fd := int(os.Stdout.Fd())
@@ -251,27 +307,20 @@ func (client *NativeClient) Start(command string) (io.ReadCloser, io.ReadCloser,
 
 func (client *NativeClient) Wait() error {
 	err := client.openSession.Wait()
-	if err != nil {
-		return err
-	}
 
 	_ = client.openSession.Close()
-
-	err = client.openClient.Close()
-	if err != nil {
-		return err
-	}
+	releaseConn(client.openClient)
 
 	client.openSession = nil
 	client.openClient = nil
-	return nil
+	return err
 }
 
 func (client *NativeClient) Shell(args ...string) error {
 	var (
 		termWidth, termHeight int
 	)
-	conn, err := ssh.Dial("tcp", net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config)
+	conn, err := dial(net.JoinHostPort(client.Hostname, strconv.Itoa(client.Port)), &client.Config, client.Bastion, client.KnownHostsFile)
 	if err != nil {
 		return err
 	}
@@ -293,8 +342,9 @@ func (client *NativeClient) Shell(args ...string) error {
 	}
 
 	fd := os.Stdin.Fd()
+	isTerminal := term.IsTerminal(fd)
 
-	if term.IsTerminal(fd) {
+	if isTerminal {
 		oldState, err := term.MakeRaw(fd)
 		if err != nil {
 			return err
@@ -316,16 +366,38 @@ func (client *NativeClient) Shell(args ...string) error {
 		return err
 	}
 
+	if isTerminal {
+		stopWatching := watchWindowSize(fd, session)
+		defer stopWatching()
+	}
+
+	// The exit status of the remote shell or command is reported as an
+	// *ssh.ExitError, see ExitStatus.
 	if len(args) == 0 {
 		if err := session.Shell(); err != nil {
 			return err
 		}
-		session.Wait()
-	} else {
-		session.Run(strings.Join(args, " "))
+		return session.Wait()
 	}
 
-	return nil
+	return session.Run(strings.Join(args, " "))
+}
+
+// windowChange tells the remote pty about a new size of the local terminal,
+// see RFC 4254 section 6.7.
+func windowChange(session *ssh.Session, height, width int) error {
+	req := struct {
+		Columns uint32
+		Rows    uint32
+		Width   uint32
+		Height  uint32
+	}{
+		Columns: uint32(width),
+		Rows:    uint32(height),
+	}
+
+	_, err := session.SendRequest("window-change", false, ssh.Marshal(&req))
+	return err
 }
 
 func NewExternalClient(sshBinaryPath, user, host string, port int, auth *Auth) (*ExternalClient, error) {
@@ -333,7 +405,14 @@ func NewExternalClient(sshBinaryPath, user, host string, port int, auth *Auth) (
 		BinaryPath: sshBinaryPath,
 	}
 
-	args := append(baseSSHArgs, fmt.Sprintf("%s@%s", user, host))
+	// The first value of an option wins, the multiplexing options override
+	// the ones of baseSSHArgs disabling it.
+	args := append(externalHostKeyArgs(auth.KnownHosts), externalMultiplexArgs(auth)...)
+	if auth.Bastion != nil {
+		args = append(args, "-o", "ProxyCommand="+auth.Bastion.ProxyCommand(auth.KnownHosts.File))
+	}
+	args = append(args, baseSSHArgs...)
+	args = append(args, fmt.Sprintf("%s@%s", user, host))
 
 	// If no identities are explicitly provided, also look at the identities
 	// offered by ssh-agent
@@ -385,8 +464,23 @@ func (client *ExternalClient) Output(command string) (string, error) {
 	return string(output), err
 }
 
+func (client *ExternalClient) OutputWithInput(command string, input io.Reader) (string, error) {
+	args := append(client.BaseArgs, command)
+	cmd := getSSHCmd(client.BinaryPath, args...)
+	cmd.Stdin = input
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
 func (client *ExternalClient) Shell(args ...string) error {
-	args = append(client.BaseArgs, args...)
+	baseArgs := client.BaseArgs
+
+	// ssh only allocates a pty by itself when no command is given
+	if len(args) > 0 && term.IsTerminal(os.Stdin.Fd()) {
+		baseArgs = append([]string{"-t"}, baseArgs...)
+	}
+
+	args = append(baseArgs, args...)
 	cmd := getSSHCmd(client.BinaryPath, args...)
 
 	log.Debug(cmd)
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"runtime"
 
@@ -122,3 +123,19 @@ func GenerateSSHKey(path string) error {
 
 	return nil
 }
+
+// PublicKey returns the public key of the private key at the given path, in
+// the authorized_keys format.
+func PublicKey(privateKeyPath string) ([]byte, error) {
+	privateKey, err := ioutil.ReadFile(privateKeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := gossh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing key %s: %s", privateKeyPath, err)
+	}
+
+	return gossh.MarshalAuthorizedKey(signer.PublicKey()), nil
+}
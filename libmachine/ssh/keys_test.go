@@ -2,6 +2,9 @@ package ssh
 
 import (
 	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -19,3 +22,30 @@ func TestNewKeyPair(t *testing.T) {
 		t.Fatal("Unable to generate fingerprint")
 	}
 }
+
+func TestPublicKey(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	keyPath := filepath.Join(tmpDir, "id_rsa")
+	if err := GenerateSSHKey(keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, err := ioutil.ReadFile(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, err := PublicKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(publicKey) != string(expected) {
+		t.Fatalf("Expected public key %q but got %q", expected, publicKey)
+	}
+}
@@ -0,0 +1,258 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	hostKeyScanTimeout = 10 * time.Second
+)
+
+// knownHostsMutex serializes the changes of the known_hosts files.
+var knownHostsMutex sync.Mutex
+
+// KnownHosts locates the host key of a machine: the known_hosts file of its
+// store and the alias the key is recorded under, unique to the store when
+// several stores share the file. The host keys are not checked without file.
+type KnownHosts struct {
+	File  string
+	Alias string
+}
+
+func (k KnownHosts) enabled() bool {
+	return k.File != "" && k.Alias != ""
+}
+
+// ErrHostKeyMismatch is returned when a machine presents a host key other
+// than the one recorded when it was provisioned.
+type ErrHostKeyMismatch struct {
+	Alias string
+	File  string
+}
+
+func (e ErrHostKeyMismatch) Error() string {
+	return fmt.Sprintf("The host key of %q does not match the key recorded in %s. If the machine was recreated, reset its host key.", e.Alias, e.File)
+}
+
+// RecordHostKey connects to the SSH server of a machine, through the bastion
+// if any, and records its host key, replacing any key previously recorded.
+func RecordHostKey(knownHosts KnownHosts, host string, port int, bastion *Bastion) error {
+	if !knownHosts.enabled() {
+		return nil
+	}
+
+	var hostKey ssh.PublicKey
+
+	config := &ssh.ClientConfig{
+		User: "docker-machine",
+		HostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return nil
+		},
+		Timeout: hostKeyScanTimeout,
+	}
+
+	// The key is received before authentication, which is expected to fail
+	conn, err := dial(net.JoinHostPort(host, strconv.Itoa(port)), config, bastion, knownHosts.File)
+	if err == nil {
+		closeConn(conn)
+	}
+	if hostKey == nil {
+		return fmt.Errorf("Error getting the host key of %q: %s", knownHosts.Alias, err)
+	}
+
+	return recordHostKey(knownHosts, hostKey)
+}
+
+// recordHostKey records the host key, replacing any key previously recorded.
+func recordHostKey(knownHosts KnownHosts, hostKey ssh.PublicKey) error {
+	log.Debugf("Recording the %s host key of %q in %s", hostKey.Type(), knownHosts.Alias, knownHosts.File)
+
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+
+	lines, err := readKnownHosts(knownHosts)
+	if err != nil {
+		return err
+	}
+
+	lines = append(lines, fmt.Sprintf("%s %s", knownHosts.Alias, strings.TrimSpace(string(ssh.MarshalAuthorizedKey(hostKey)))))
+
+	return writeKnownHosts(knownHosts.File, lines)
+}
+
+// RemoveHostKey forgets the host key recorded for a machine.
+func RemoveHostKey(knownHosts KnownHosts) error {
+	if !knownHosts.enabled() {
+		return nil
+	}
+
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+
+	lines, err := readKnownHosts(knownHosts)
+	if err != nil {
+		return err
+	}
+
+	return writeKnownHosts(knownHosts.File, lines)
+}
+
+// hostKeys returns the keys recorded for a machine.
+func hostKeys(knownHosts KnownHosts) ([]ssh.PublicKey, error) {
+	if !knownHosts.enabled() {
+		return nil, nil
+	}
+
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+
+	data, err := ioutil.ReadFile(knownHosts.File)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []ssh.PublicKey{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 2)
+		if len(fields) != 2 || !matchesAlias(fields[0], knownHosts.Alias) {
+			continue
+		}
+
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(fields[1]))
+		if err != nil {
+			log.Debugf("Ignoring invalid host key of %q in %s: %s", knownHosts.Alias, knownHosts.File, err)
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, scanner.Err()
+}
+
+// hostKeyCallback checks the key presented by a machine against the keys
+// recorded for it. Machines without any recorded key, e.g. created by older
+// versions, are not checked.
+func hostKeyCallback(knownHosts KnownHosts) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		keys, err := hostKeys(knownHosts)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+
+		for _, known := range keys {
+			if bytes.Equal(known.Marshal(), key.Marshal()) {
+				return nil
+			}
+		}
+
+		return ErrHostKeyMismatch{
+			Alias: knownHosts.Alias,
+			File:  knownHosts.File,
+		}
+	}
+}
+
+// externalHostKeyArgs returns the options making the ssh binary check the
+// key recorded for a machine, if any. They take precedence over baseSSHArgs,
+// which disable the checks.
+func externalHostKeyArgs(knownHosts KnownHosts) []string {
+	keys, err := hostKeys(knownHosts)
+	if err != nil {
+		log.Debugf("Error reading the host keys of %q: %s", knownHosts.Alias, err)
+		return nil
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return []string{
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", fmt.Sprintf("UserKnownHostsFile=%s", knownHosts.File),
+		"-o", fmt.Sprintf("HostKeyAlias=%s", knownHosts.Alias),
+		"-o", "CheckHostIP=no",
+	}
+}
+
+// HostKeyOptions returns the options, in the Name=value format, making ssh
+// check the host key recorded for a machine, or none when no key is recorded.
+// They are the ones of the ssh binary of the external client.
+func HostKeyOptions(knownHosts KnownHosts) []string {
+	options := []string{}
+
+	args := externalHostKeyArgs(knownHosts)
+	for i := 1; i < len(args); i += 2 {
+		options = append(options, args[i])
+	}
+
+	return options
+}
+
+func matchesAlias(hosts, alias string) bool {
+	for _, host := range strings.Split(hosts, ",") {
+		if host == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// readKnownHosts returns the lines of the known_hosts file, except the ones
+// of the alias.
+func readKnownHosts(knownHosts KnownHosts) ([]string, error) {
+	data, err := ioutil.ReadFile(knownHosts.File)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+		if fields[0] == "" || matchesAlias(fields[0], knownHosts.Alias) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func writeKnownHosts(file string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+
+	content := ""
+	if len(lines) > 0 {
+		content = strings.Join(lines, "\n") + "\n"
+	}
+
+	return ioutil.WriteFile(file, []byte(content), 0600)
+}
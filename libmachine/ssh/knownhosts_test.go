@@ -0,0 +1,117 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	priv, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ssh.NewPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func withKnownHostsFile(t *testing.T, content string, f func(path string)) {
+	dir, err := ioutil.TempDir("", "machine-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "known_hosts")
+	if content != "" {
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f(path)
+}
+
+func knownHostsLine(alias string, key ssh.PublicKey) string {
+	return alias + " " + strings.TrimSpace(string(ssh.MarshalAuthorizedKey(key))) + "\n"
+}
+
+func TestHostKeyCallbackWithoutRecordedKey(t *testing.T) {
+	withKnownHostsFile(t, "", func(path string) {
+		err := hostKeyCallback(KnownHosts{File: path, Alias: "default"})("default", nil, newTestHostKey(t))
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestHostKeyCallbackMatch(t *testing.T) {
+	key := newTestHostKey(t)
+
+	withKnownHostsFile(t, knownHostsLine("default", key), func(path string) {
+		err := hostKeyCallback(KnownHosts{File: path, Alias: "default"})("default", nil, key)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestHostKeyCallbackMismatch(t *testing.T) {
+	withKnownHostsFile(t, knownHostsLine("default", newTestHostKey(t)), func(path string) {
+		err := hostKeyCallback(KnownHosts{File: path, Alias: "default"})("default", nil, newTestHostKey(t))
+
+		assert.Equal(t, ErrHostKeyMismatch{Alias: "default", File: path}, err)
+	})
+}
+
+func TestRemoveHostKey(t *testing.T) {
+	other := knownHostsLine("other", newTestHostKey(t))
+
+	withKnownHostsFile(t, knownHostsLine("default", newTestHostKey(t))+other, func(path string) {
+		assert.NoError(t, RemoveHostKey(KnownHosts{File: path, Alias: "default"}))
+
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, other, string(data))
+	})
+}
+
+func TestExternalHostKeyArgs(t *testing.T) {
+	withKnownHostsFile(t, knownHostsLine("default", newTestHostKey(t)), func(path string) {
+		assert.Nil(t, externalHostKeyArgs(KnownHosts{File: path, Alias: "other"}))
+		assert.Contains(t, externalHostKeyArgs(KnownHosts{File: path, Alias: "default"}), "HostKeyAlias=default")
+		assert.Contains(t, externalHostKeyArgs(KnownHosts{File: path, Alias: "default"}), "UserKnownHostsFile="+path)
+	})
+}
+
+func TestHostKeysNotCheckedWithoutFile(t *testing.T) {
+	withKnownHostsFile(t, knownHostsLine("default", newTestHostKey(t)), func(path string) {
+		err := hostKeyCallback(KnownHosts{Alias: "default"})("default", nil, newTestHostKey(t))
+
+		assert.NoError(t, err)
+		assert.Nil(t, externalHostKeyArgs(KnownHosts{Alias: "default"}))
+	})
+}
+
+func TestHostKeysOfSeveralFiles(t *testing.T) {
+	key := newTestHostKey(t)
+
+	withKnownHostsFile(t, knownHostsLine("default", key), func(path string) {
+		withKnownHostsFile(t, knownHostsLine("default", newTestHostKey(t)), func(otherPath string) {
+			assert.NoError(t, hostKeyCallback(KnownHosts{File: path, Alias: "default"})("default", nil, key))
+
+			err := hostKeyCallback(KnownHosts{File: otherPath, Alias: "default"})("default", nil, key)
+			assert.Equal(t, ErrHostKeyMismatch{Alias: "default", File: otherPath}, err)
+		})
+	})
+}
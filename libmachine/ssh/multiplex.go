@@ -0,0 +1,236 @@
+package ssh
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// DefaultControlPersist is how long an unused connection is kept open
+	// for the next command.
+	DefaultControlPersist = 60 * time.Second
+
+	// maxControlPathLen is the length limit of the path of a unix socket,
+	// the smallest across platforms. The external client creates the
+	// control socket under a temporary name 17 characters longer.
+	maxControlPathLen = 104 - 17
+)
+
+var (
+	multiplexMutex sync.Mutex
+	multiplexing   bool
+	controlDir     string
+	controlPersist time.Duration
+
+	// sharedConns are the connections of the native client to reuse, by
+	// connection key.
+	sharedConns = map[string]*sharedConn{}
+)
+
+// sharedConn is a connection of the native client shared by the commands
+// run on the same machine. Sessions are multiplexed over it.
+type sharedConn struct {
+	client *ssh.Client
+	key    string
+	users  int
+	idle   *time.Timer
+}
+
+// SetMultiplexing makes the clients reuse their connections to a machine
+// across commands, which spares an SSH handshake per command. Unused
+// connections are closed after persist. The external client keeps its
+// control sockets in dir, an empty dir leaving it without multiplexing.
+func SetMultiplexing(dir string, persist time.Duration) {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	multiplexing = true
+	controlDir = dir
+	controlPersist = persist
+}
+
+// DefaultControlDir returns the directory of the control sockets of the
+// external client, created if needed, or an empty string when the external
+// client cannot multiplex its connections. It is kept short as the length
+// of the path of a socket is limited.
+func DefaultControlDir() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+
+	dir := filepath.Join("/tmp", fmt.Sprintf("docker-machine-ssh-%d", os.Getuid()))
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Debugf("Not multiplexing SSH connections: %s", err)
+		return ""
+	}
+
+	// The directory must not be shared with other users
+	fi, err := os.Lstat(dir)
+	if err != nil || !fi.IsDir() || fi.Mode().Perm() != 0700 {
+		log.Debugf("Not multiplexing SSH connections: %s is not a private directory", dir)
+		return ""
+	}
+
+	return dir
+}
+
+// CloseConnections closes the connections of the native client kept open
+// for reuse.
+func CloseConnections() {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	for key, conn := range sharedConns {
+		if conn.idle != nil {
+			conn.idle.Stop()
+		}
+		delete(sharedConns, key)
+		closeConn(conn.client)
+	}
+}
+
+// connKey identifies the connections which can be shared: the ones to the
+// same address, as the same user, with the same keys.
+func connKey(user, host string, port int, auth *Auth) string {
+	return fmt.Sprintf("%s@%s:%d/%s", user, host, port, authHash(auth))
+}
+
+// authHash returns a short hash of the keys, known hosts and bastion,
+// for clients authenticating or connecting differently not to share their
+// connections.
+func authHash(auth *Auth) string {
+	hash := sha1.New()
+	for _, key := range auth.Keys {
+		fmt.Fprintf(hash, "%s\x00", key)
+	}
+	fmt.Fprintf(hash, "%s\x00%s", auth.KnownHosts.File, auth.KnownHosts.Alias)
+	if auth.Bastion != nil {
+		fmt.Fprintf(hash, "\x00%s\x00%s", auth.Bastion, auth.Bastion.KeyPath)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)[:4])
+}
+
+// externalMultiplexArgs returns the options of the external client making
+// it share a master connection, or nil without multiplexing. The control
+// path is made of the hash of the user, address and port of the machine
+// the client computes, prefixed with the one of the keys.
+func externalMultiplexArgs(auth *Auth) []string {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	if !multiplexing || controlDir == "" {
+		return nil
+	}
+
+	controlPath := filepath.Join(controlDir, authHash(auth)+"-%C")
+	if len(controlPath)-len("%C")+40 > maxControlPathLen {
+		log.Debugf("Not multiplexing SSH connections: %s is too long", controlPath)
+		return nil
+	}
+
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + controlPath,
+		"-o", fmt.Sprintf("ControlPersist=%d", int(controlPersist.Seconds())),
+	}
+}
+
+// acquireConn returns the shared connection with the given key, nil if
+// there is none.
+func acquireConn(key string) *ssh.Client {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	conn, ok := sharedConns[key]
+	if !ok {
+		return nil
+	}
+
+	conn.users++
+	if conn.idle != nil {
+		conn.idle.Stop()
+		conn.idle = nil
+	}
+	return conn.client
+}
+
+// shareConn makes a new connection available to the next commands, if the
+// connections are multiplexed and no other is shared with the same key.
+func shareConn(key string, client *ssh.Client) {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	if !multiplexing || key == "" {
+		return
+	}
+	if _, ok := sharedConns[key]; ok {
+		return
+	}
+
+	sharedConns[key] = &sharedConn{
+		client: client,
+		key:    key,
+		users:  1,
+	}
+}
+
+// releaseConn is called once a command is done with a connection. Shared
+// connections are closed once unused for the persist duration, the others
+// right away.
+func releaseConn(client *ssh.Client) {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	conn := findSharedConn(client)
+	if conn == nil {
+		closeConn(client)
+		return
+	}
+
+	conn.users--
+	if conn.users > 0 {
+		return
+	}
+
+	conn.idle = time.AfterFunc(controlPersist, func() {
+		multiplexMutex.Lock()
+		defer multiplexMutex.Unlock()
+
+		if conn.users == 0 && sharedConns[conn.key] == conn {
+			delete(sharedConns, conn.key)
+			closeConn(conn.client)
+		}
+	})
+}
+
+// dropConn stops sharing a connection that failed and closes it. The
+// commands still running on it fail with it.
+func dropConn(client *ssh.Client) {
+	multiplexMutex.Lock()
+	defer multiplexMutex.Unlock()
+
+	if conn := findSharedConn(client); conn != nil {
+		if conn.idle != nil {
+			conn.idle.Stop()
+		}
+		delete(sharedConns, conn.key)
+	}
+	closeConn(client)
+}
+
+func findSharedConn(client *ssh.Client) *sharedConn {
+	for _, conn := range sharedConns {
+		if conn.client == client {
+			return conn
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+// resetMultiplexing forgets the shared connections without closing them,
+// the tests only sharing fake ones.
+func resetMultiplexing() {
+	for _, conn := range sharedConns {
+		if conn.idle != nil {
+			conn.idle.Stop()
+		}
+	}
+	sharedConns = map[string]*sharedConn{}
+	multiplexing = false
+	controlDir = ""
+	controlPersist = 0
+}
+
+func TestExternalMultiplexArgs(t *testing.T) {
+	defer resetMultiplexing()
+
+	auth := &Auth{Keys: []string{"/store/machines/dev/id_rsa"}}
+	assert.Nil(t, externalMultiplexArgs(auth))
+
+	SetMultiplexing("/tmp/docker-machine-ssh-1000", 90*time.Second)
+	args := externalMultiplexArgs(auth)
+
+	assert.Equal(t, []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=/tmp/docker-machine-ssh-1000/" + authHash(auth) + "-%C",
+		"-o", "ControlPersist=90",
+	}, args)
+
+	client, err := NewExternalClient("ssh", "docker", "10.0.0.2", 22, &Auth{})
+	assert.NoError(t, err)
+	assert.Contains(t, strings.Join(client.BaseArgs, " "), "-o ControlMaster=auto")
+	assert.True(t, indexOf(client.BaseArgs, "ControlMaster=auto") < indexOf(client.BaseArgs, "ControlMaster=no"))
+}
+
+func TestExternalMultiplexArgsLongDir(t *testing.T) {
+	defer resetMultiplexing()
+
+	SetMultiplexing("/tmp/"+strings.Repeat("d", 60), time.Minute)
+
+	assert.Nil(t, externalMultiplexArgs(&Auth{}))
+}
+
+func TestConnKey(t *testing.T) {
+	knownHosts := KnownHosts{File: "/store/known_hosts", Alias: "dev"}
+	auth := &Auth{Keys: []string{"/store/machines/dev/id_rsa"}, KnownHosts: knownHosts}
+
+	assert.Equal(t, connKey("docker", "10.0.0.2", 22, auth), connKey("docker", "10.0.0.2", 22, auth))
+	assert.NotEqual(t, connKey("docker", "10.0.0.2", 22, auth), connKey("root", "10.0.0.2", 22, auth))
+	assert.NotEqual(t, connKey("docker", "10.0.0.2", 22, auth), connKey("docker", "10.0.0.2", 22, &Auth{
+		Keys:       []string{"/store/machines/dev/id_rsa.new"},
+		KnownHosts: knownHosts,
+	}))
+	assert.NotEqual(t, connKey("docker", "10.0.0.2", 22, auth), connKey("docker", "10.0.0.2", 22, &Auth{
+		Keys:       auth.Keys,
+		KnownHosts: KnownHosts{File: "/other/known_hosts", Alias: "dev"},
+	}))
+}
+
+func TestSharedConns(t *testing.T) {
+	defer resetMultiplexing()
+
+	conn := &ssh.Client{}
+
+	shareConn("key", conn)
+	assert.Nil(t, acquireConn("key"), "connections are not shared without multiplexing")
+
+	SetMultiplexing("", time.Hour)
+	shareConn("key", conn)
+	assert.Equal(t, conn, acquireConn("key"))
+	assert.Nil(t, acquireConn("other"))
+
+	releaseConn(conn)
+	assert.Equal(t, 1, sharedConns["key"].users)
+
+	releaseConn(conn)
+	assert.Equal(t, 0, sharedConns["key"].users)
+	assert.NotNil(t, sharedConns["key"].idle)
+
+	assert.Equal(t, conn, acquireConn("key"))
+	assert.Nil(t, sharedConns["key"].idle)
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
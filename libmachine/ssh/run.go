@@ -0,0 +1,104 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// externalClientErrorStatus is the exit status of the ssh binary when
+	// the connection fails, rather than the remote command.
+	externalClientErrorStatus = 255
+)
+
+// Run runs a command and returns its standard output, its standard error and
+// its exit status. A command exiting with a non zero status is not an error:
+// the error is only set when the command could not be run.
+func Run(client Client, command string) (string, string, int, error) {
+	var stdout, stderr bytes.Buffer
+
+	exitCode, err := RunStream(client, command, &stdout, &stderr)
+
+	return stdout.String(), stderr.String(), exitCode, err
+}
+
+// RunStream runs a command, copying its standard output and standard error
+// to the given writers as they are produced, and returns its exit status.
+// Either writer may be nil to discard the output.
+func RunStream(client Client, command string, stdout, stderr io.Writer) (int, error) {
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	stdoutPipe, stderrPipe, err := client.Start(command)
+	if err != nil {
+		return -1, err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go copyOutput(&wg, stdout, stdoutPipe)
+	go copyOutput(&wg, stderr, stderrPipe)
+
+	// The pipes must be drained before waiting for the command to exit
+	wg.Wait()
+
+	return exitStatus(client.Wait())
+}
+
+func copyOutput(wg *sync.WaitGroup, dst io.Writer, src io.ReadCloser) {
+	defer wg.Done()
+	defer src.Close()
+
+	io.Copy(dst, src)
+}
+
+// ExitStatus returns the exit status of a remote command from the error
+// returned when running it, e.g. by Shell. It returns false when the error
+// does not come from the command exiting with a non zero status.
+func ExitStatus(err error) (int, bool) {
+	switch exitErr := err.(type) {
+	case *ssh.ExitError:
+		return exitErr.ExitStatus(), true
+	case *exec.ExitError:
+		status, ok := exitErr.Sys().(syscall.WaitStatus)
+		if !ok {
+			return -1, false
+		}
+		// The ssh binary exits with 255 when the connection fails
+		if status.ExitStatus() == externalClientErrorStatus {
+			return -1, false
+		}
+		return status.ExitStatus(), true
+	}
+
+	return -1, false
+}
+
+// exitStatus tells apart commands which exited with a non zero status from
+// commands which could not be run.
+func exitStatus(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+
+	if status, ok := ExitStatus(err); ok {
+		return status, nil
+	}
+
+	if _, isExitErr := err.(*exec.ExitError); isExitErr {
+		return externalClientErrorStatus, fmt.Errorf("Error running the ssh command: %s", err)
+	}
+
+	return -1, err
+}
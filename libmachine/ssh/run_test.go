@@ -0,0 +1,100 @@
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"testing"
+
+	"github.com/docker/machine/libmachine/ssh/sshtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	client := &sshtest.FakeClient{
+		Outputs: map[string]sshtest.CmdResult{
+			"uname": {
+				Out:    "Linux\n",
+				Stderr: "warning\n",
+			},
+		},
+	}
+
+	stdout, stderr, exitCode, err := Run(client, "uname")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Linux\n", stdout)
+	assert.Equal(t, "warning\n", stderr)
+	assert.Equal(t, 0, exitCode)
+}
+
+func TestRunNonZeroExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("sh is not available on windows")
+	}
+
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	client := &sshtest.FakeClient{
+		Outputs: map[string]sshtest.CmdResult{
+			"false": {
+				Err: exitErr,
+			},
+		},
+	}
+
+	_, _, exitCode, err := Run(client, "false")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+}
+
+func TestRunError(t *testing.T) {
+	client := &sshtest.FakeClient{
+		Outputs: map[string]sshtest.CmdResult{
+			"uname": {
+				Err: errors.New("connection refused"),
+			},
+		},
+	}
+
+	_, _, exitCode, err := Run(client, "uname")
+
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, -1, exitCode)
+}
+
+func TestRunStream(t *testing.T) {
+	client := &sshtest.FakeClient{
+		Outputs: map[string]sshtest.CmdResult{
+			"docker logs app": {
+				Out: "line 1\nline 2\n",
+			},
+		},
+	}
+
+	var stdout bytes.Buffer
+	exitCode, err := RunStream(client, "docker logs app", &stdout, nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "line 1\nline 2\n", stdout.String())
+}
+
+func TestExitStatus(t *testing.T) {
+	status, ok := ExitStatus(errors.New("connection refused"))
+	assert.False(t, ok)
+	assert.Equal(t, -1, status)
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	status, ok = ExitStatus(exec.Command("sh", "-c", "exit 4").Run())
+	assert.True(t, ok)
+	assert.Equal(t, 4, status)
+
+	_, ok = ExitStatus(exec.Command("sh", "-c", "exit 255").Run())
+	assert.False(t, ok)
+}
@@ -1,15 +1,23 @@
 package sshtest
 
-import "io"
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
 
 type CmdResult struct {
-	Out string
-	Err error
+	Out    string
+	Stderr string
+	Err    error
 }
 
 type FakeClient struct {
 	ActivatedShell []string
+	ShellErr       error
 	Outputs        map[string]CmdResult
+	Inputs         map[string][]byte
+	started        string
 }
 
 func (fsc *FakeClient) Output(command string) (string, error) {
@@ -17,15 +25,29 @@ func (fsc *FakeClient) Output(command string) (string, error) {
 	return outerr.Out, outerr.Err
 }
 
+func (fsc *FakeClient) OutputWithInput(command string, input io.Reader) (string, error) {
+	data, err := ioutil.ReadAll(input)
+	if err != nil {
+		return "", err
+	}
+	if fsc.Inputs == nil {
+		fsc.Inputs = map[string][]byte{}
+	}
+	fsc.Inputs[command] = data
+	return fsc.Output(command)
+}
+
 func (fsc *FakeClient) Shell(args ...string) error {
 	fsc.ActivatedShell = args
-	return nil
+	return fsc.ShellErr
 }
 
 func (fsc *FakeClient) Start(command string) (io.ReadCloser, io.ReadCloser, error) {
-	return nil, nil, nil
+	fsc.started = command
+	outerr := fsc.Outputs[command]
+	return ioutil.NopCloser(strings.NewReader(outerr.Out)), ioutil.NopCloser(strings.NewReader(outerr.Stderr)), nil
 }
 
 func (fsc *FakeClient) Wait() error {
-	return nil
+	return fsc.Outputs[fsc.started].Err
 }
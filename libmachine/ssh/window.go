@@ -0,0 +1,41 @@
+// +build !windows
+
+package ssh
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/docker/docker/pkg/term"
+	"golang.org/x/crypto/ssh"
+)
+
+// watchWindowSize forwards the size changes of the local terminal to the
+// remote pty until the returned function is called.
+func watchWindowSize(fd uintptr, session *ssh.Session) func() {
+	sigwinch := make(chan os.Signal, 1)
+	signal.Notify(sigwinch, syscall.SIGWINCH)
+
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-sigwinch:
+				winsize, err := term.GetWinsize(fd)
+				if err != nil {
+					continue
+				}
+				windowChange(session, int(winsize.Height), int(winsize.Width))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigwinch)
+		close(done)
+	}
+}
@@ -0,0 +1,48 @@
+package ssh
+
+import (
+	"time"
+
+	"github.com/docker/docker/pkg/term"
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	windowSizePollInterval = 250 * time.Millisecond
+)
+
+// watchWindowSize forwards the size changes of the local console to the
+// remote pty until the returned function is called. Windows has no signal
+// for console resizes so the size is polled.
+func watchWindowSize(fd uintptr, session *ssh.Session) func() {
+	done := make(chan struct{})
+
+	go func() {
+		var width, height uint16
+
+		if winsize, err := term.GetWinsize(fd); err == nil {
+			width, height = winsize.Width, winsize.Height
+		}
+
+		ticker := time.NewTicker(windowSizePollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				winsize, err := term.GetWinsize(fd)
+				if err != nil || (winsize.Width == width && winsize.Height == height) {
+					continue
+				}
+				width, height = winsize.Width, winsize.Height
+				windowChange(session, int(height), int(width))
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
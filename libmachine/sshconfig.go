@@ -0,0 +1,102 @@
+package libmachine
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+)
+
+const sshConfigHeader = "# Managed by docker-machine, changes are overwritten.\n# Include this file from ~/.ssh/config to reach the machines by their names.\n"
+
+// SSHConfigPath is the ssh_config file of the store managed when
+// ManageSSHConfig is set, for users to include from their ~/.ssh/config.
+func (api *Client) SSHConfigPath() string {
+	return filepath.Join(api.Path, "ssh_config")
+}
+
+// updateSSHConfig replaces the entry of the machine in the managed
+// ssh_config file, or removes it when h is nil. The entries of the other
+// machines are kept as they are, without reaching them.
+func (api *Client) updateSSHConfig(name string, h *host.Host) error {
+	entries, err := readSSHConfigEntries(api.SSHConfigPath())
+	if err != nil {
+		return err
+	}
+
+	delete(entries, name)
+	if h != nil {
+		entry, err := h.SSHConfigEntry()
+		if err != nil {
+			return err
+		}
+		entries[name] = entry
+	}
+
+	names := []string{}
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	content := sshConfigHeader
+	for _, name := range names {
+		content += "\n" + entries[name]
+	}
+
+	if err := os.MkdirAll(filepath.Dir(api.SSHConfigPath()), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(api.SSHConfigPath(), []byte(content), 0600)
+}
+
+// updateManagedSSHConfig updates the managed ssh_config file if
+// ManageSSHConfig is set, the errors being only logged.
+func (api *Client) updateManagedSSHConfig(name string, h *host.Host) {
+	if !api.ManageSSHConfig {
+		return
+	}
+
+	if err := api.updateSSHConfig(name, h); err != nil {
+		log.Warnf("Error updating %s: %s", api.SSHConfigPath(), err)
+	}
+}
+
+// readSSHConfigEntries reads the Host blocks of the managed ssh_config file
+// by the name of their machine.
+func readSSHConfigEntries(path string) (map[string]string, error) {
+	entries := map[string]string{}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	name := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "Host "):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "Host "))
+			entries[name] = line + "\n"
+		case name != "" && strings.TrimSpace(line) != "":
+			entries[name] += line + "\n"
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading %s: %s", path, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,41 @@
+package libmachine
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/machine/drivers/none"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/stretchr/testify/assert"
+)
+
+type sshConfigDriver struct {
+	*none.Driver
+	hostname string
+}
+
+func (d *sshConfigDriver) GetSSHHostname() (string, error) {
+	return d.hostname, nil
+}
+
+func TestUpdateSSHConfig(t *testing.T) {
+	api, _, cleanup := newCreatePhaseTestHost(t, host.CreatePhasePending)
+	defer cleanup()
+
+	for _, name := range []string{"web", "db"} {
+		h := &host.Host{Name: name, Driver: &sshConfigDriver{Driver: none.NewDriver(name, api.Path), hostname: name + ".example.com"}}
+		assert.NoError(t, api.updateSSHConfig(name, h))
+	}
+
+	data, err := ioutil.ReadFile(api.SSHConfigPath())
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Host db\n    HostName db.example.com\n")
+	assert.Contains(t, string(data), "\n\nHost web\n    HostName web.example.com\n")
+
+	assert.NoError(t, api.updateSSHConfig("db", nil))
+
+	entries, err := readSSHConfigEntries(api.SSHConfigPath())
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries["web"], "HostName web.example.com")
+}
@@ -0,0 +1,175 @@
+package swarm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// kvStorePorts are the default client ports of the key-value stores swarm
+// discovers nodes with.
+var kvStorePorts = map[string]int{
+	"consul": 8500,
+	"etcd":   2379,
+	"zk":     2181,
+}
+
+var (
+	ErrDiscoveryToken = errors.New("The token of a token:// discovery must be a hexadecimal string")
+
+	validToken = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+)
+
+// Discovery is a parsed swarm discovery, e.g.
+// consul://10.0.0.2:8500,10.0.0.3:8500/swarm.
+type Discovery struct {
+	// Scheme is token, consul, etcd, zk, file or nodes
+	Scheme string
+	// Token is the cluster token of a token:// discovery
+	Token string
+	// Hosts are the host:port of the key-value store nodes or of the swarm
+	// nodes of a nodes:// discovery
+	Hosts []string
+	// Path is the path of the key-value store keys or of the file of a
+	// file:// discovery
+	Path string
+}
+
+// ParseDiscovery parses and validates a swarm discovery. The hosts of a
+// key-value store without a port get the default port of the store.
+func ParseDiscovery(discovery string) (*Discovery, error) {
+	parts := strings.SplitN(discovery, "://", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("Swarm Discovery URL was in the wrong format: %s", discovery)
+	}
+
+	d := &Discovery{Scheme: parts[0]}
+	rest := parts[1]
+
+	switch d.Scheme {
+	case "token":
+		if !validToken.MatchString(rest) {
+			return nil, ErrDiscoveryToken
+		}
+		d.Token = rest
+	case "file":
+		if rest == "" {
+			return nil, errors.New("A file:// discovery needs the path of the file")
+		}
+		d.Path = rest
+	case "nodes":
+		hosts, err := parseDiscoveryHosts(rest, 0)
+		if err != nil {
+			return nil, err
+		}
+		d.Hosts = hosts
+	default:
+		defaultPort, ok := kvStorePorts[d.Scheme]
+		if !ok {
+			return nil, fmt.Errorf("Unsupported swarm discovery %q, must be one of token, %s, file or nodes", d.Scheme, strings.Join(KVStores(), ", "))
+		}
+
+		hosts := rest
+		if i := strings.Index(rest, "/"); i >= 0 {
+			hosts, d.Path = rest[:i], strings.Trim(rest[i:], "/")
+		}
+
+		parsed, err := parseDiscoveryHosts(hosts, defaultPort)
+		if err != nil {
+			return nil, err
+		}
+		d.Hosts = parsed
+	}
+
+	return d, nil
+}
+
+// parseDiscoveryHosts parses a comma separated list of host:port. Hosts
+// without a port get the default port if any.
+func parseDiscoveryHosts(hosts string, defaultPort int) ([]string, error) {
+	if hosts == "" {
+		return nil, errors.New("The swarm discovery has no host")
+	}
+
+	parsed := []string{}
+	for _, h := range strings.Split(hosts, ",") {
+		host, port, err := net.SplitHostPort(h)
+		if err != nil {
+			if defaultPort == 0 {
+				return nil, fmt.Errorf("Invalid host %q in the swarm discovery, the host:port format is expected", h)
+			}
+			host, port = h, strconv.Itoa(defaultPort)
+		}
+
+		if host == "" {
+			return nil, fmt.Errorf("Invalid host %q in the swarm discovery", h)
+		}
+		if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("Invalid port %q in the swarm discovery", port)
+		}
+
+		parsed = append(parsed, net.JoinHostPort(host, port))
+	}
+
+	return parsed, nil
+}
+
+// IsKVStore tells whether the nodes are discovered through a key-value store,
+// which the engines can also use as cluster store.
+func (d *Discovery) IsKVStore() bool {
+	_, ok := kvStorePorts[d.Scheme]
+	return ok
+}
+
+func (d *Discovery) String() string {
+	switch d.Scheme {
+	case "token":
+		return "token://" + d.Token
+	case "file":
+		return "file://" + d.Path
+	}
+
+	discovery := d.Scheme + "://" + strings.Join(d.Hosts, ",")
+	if d.Path != "" {
+		discovery += "/" + d.Path
+	}
+	return discovery
+}
+
+// KVStores returns the key-value stores swarm can discover nodes with.
+func KVStores() []string {
+	stores := []string{}
+	for store := range kvStorePorts {
+		stores = append(stores, store)
+	}
+	sort.Strings(stores)
+	return stores
+}
+
+// KVStoreDiscovery returns the discovery of a key-value store running on the
+// given host with its default port.
+func KVStoreDiscovery(store, host string) (*Discovery, error) {
+	port, ok := kvStorePorts[store]
+	if !ok {
+		return nil, fmt.Errorf("Unsupported key-value store %q, must be one of %s", store, strings.Join(KVStores(), ", "))
+	}
+
+	return &Discovery{
+		Scheme: store,
+		Hosts:  []string{net.JoinHostPort(host, strconv.Itoa(port))},
+	}, nil
+}
+
+// DiscoveryTLSOpts returns the discovery options of swarm, or the cluster
+// store options of the engine, to connect to a key-value store with TLS.
+func DiscoveryTLSOpts(caCertPath, certPath, keyPath string) []string {
+	return []string{
+		"kv.cacertfile=" + caCertPath,
+		"kv.certfile=" + certPath,
+		"kv.keyfile=" + keyPath,
+	}
+}
@@ -0,0 +1,55 @@
+package swarm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiscovery(t *testing.T) {
+	testCases := []struct {
+		discovery string
+		expected  *Discovery
+		str       string
+	}{
+		{"token://deadbeefcafe", &Discovery{Scheme: "token", Token: "deadbeefcafe"}, "token://deadbeefcafe"},
+		{"consul://10.0.0.2", &Discovery{Scheme: "consul", Hosts: []string{"10.0.0.2:8500"}}, "consul://10.0.0.2:8500"},
+		{"etcd://10.0.0.2:4001,10.0.0.3/swarm/", &Discovery{Scheme: "etcd", Hosts: []string{"10.0.0.2:4001", "10.0.0.3:2379"}, Path: "swarm"}, "etcd://10.0.0.2:4001,10.0.0.3:2379/swarm"},
+		{"zk://zk1:2181/swarm", &Discovery{Scheme: "zk", Hosts: []string{"zk1:2181"}, Path: "swarm"}, "zk://zk1:2181/swarm"},
+		{"nodes://10.0.0.2:2375,10.0.0.3:2375", &Discovery{Scheme: "nodes", Hosts: []string{"10.0.0.2:2375", "10.0.0.3:2375"}}, "nodes://10.0.0.2:2375,10.0.0.3:2375"},
+		{"file:///etc/swarm/cluster", &Discovery{Scheme: "file", Path: "/etc/swarm/cluster"}, "file:///etc/swarm/cluster"},
+	}
+
+	for _, tc := range testCases {
+		discovery, err := ParseDiscovery(tc.discovery)
+
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, discovery)
+		assert.Equal(t, tc.str, discovery.String())
+	}
+}
+
+func TestParseDiscoveryInvalid(t *testing.T) {
+	for _, discovery := range []string{
+		"foo",
+		"token://not-a-token",
+		"redis://10.0.0.2",
+		"consul://",
+		"consul://10.0.0.2:99999",
+		"nodes://10.0.0.2",
+	} {
+		_, err := ParseDiscovery(discovery)
+		assert.Error(t, err, discovery)
+	}
+}
+
+func TestKVStoreDiscovery(t *testing.T) {
+	discovery, err := KVStoreDiscovery("consul", "10.0.0.2")
+
+	assert.NoError(t, err)
+	assert.True(t, discovery.IsKVStore())
+	assert.Equal(t, "consul://10.0.0.2:8500", discovery.String())
+
+	_, err = KVStoreDiscovery("redis", "10.0.0.2")
+	assert.Error(t, err)
+}
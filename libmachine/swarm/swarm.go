@@ -19,4 +19,7 @@ type Options struct {
 	ArbitraryJoinFlags []string
 	Env                []string
 	IsExperimental     bool
+	ClusterNetworking  bool
+	DiscoveryTLS       bool
+	DiscoveryStore     string
 }
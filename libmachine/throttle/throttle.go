@@ -0,0 +1,168 @@
+// Package throttle is the HTTP layer of the cloud drivers retrying the
+// requests the providers throttle, with an exponential backoff, for batch
+// creates not to fail on the rate limits of the providers.
+package throttle
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/metrics"
+)
+
+const (
+	// DefaultMaxRetries is how many times a throttled request is retried.
+	DefaultMaxRetries = 8
+
+	// DefaultBaseDelay is the delay before the first retry, doubled for
+	// each next one up to DefaultMaxDelay.
+	DefaultBaseDelay = 500 * time.Millisecond
+	DefaultMaxDelay  = 30 * time.Second
+
+	// maxPeekedBody is how much of the body of an error response is read
+	// to look for the throttling error codes.
+	maxPeekedBody = 64 * 1024
+)
+
+// throttlingCodes are the error codes the providers throttle requests with
+// in the body of the responses, when the status alone does not tell:
+// RequestLimitExceeded and Throttling are EC2's, with a 503, rateLimitExceeded
+// and userRateLimitExceeded GCE's, with a 403.
+var throttlingCodes = [][]byte{
+	[]byte("RequestLimitExceeded"),
+	[]byte("Throttling"),
+	[]byte("rateLimitExceeded"),
+	[]byte("userRateLimitExceeded"),
+}
+
+// Transport is an http.RoundTripper retrying the requests the provider
+// answers with 429 Too Many Requests, or with one of the throttling error
+// codes of the providers. The delays between the retries are doubled each
+// time, with jitter for the machines created in parallel not to retry in
+// lockstep, unless the provider tells how long to wait with Retry-After.
+type Transport struct {
+	// Provider is the name of the driver, for the metrics.
+	Provider string
+
+	// Base sends the requests, http.DefaultTransport when nil.
+	Base http.RoundTripper
+
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// NewTransport returns a transport with the default retries of the
+// provider.
+func NewTransport(provider string) *Transport {
+	return &Transport{
+		Provider:   provider,
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// NewClient returns an HTTP client retrying the throttled requests.
+func NewClient(provider string) *http.Client {
+	return &http.Client{Transport: NewTransport(provider)}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// RoundTrip sends the request, retrying it while it is throttled.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+
+	for attempt := 0; ; attempt++ {
+		if req.Body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		resp, err := t.base().RoundTrip(req)
+		if err != nil || attempt >= t.MaxRetries || !throttled(resp) {
+			return resp, err
+		}
+
+		delay := t.delay(attempt, resp)
+		resp.Body.Close()
+
+		log.Debugf("%s throttled %s %s, retrying in %s", t.Provider, req.Method, req.URL.Host, delay)
+		metrics.ObserveRetry(metrics.Retry{
+			Provider:   t.Provider,
+			Method:     req.Method,
+			Host:       req.URL.Host,
+			Attempt:    attempt + 1,
+			Delay:      delay,
+			StatusCode: resp.StatusCode,
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// throttled tells whether the provider throttled the request. The body of
+// the error responses read to tell is put back in the response.
+func throttled(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable, http.StatusForbidden, http.StatusBadRequest:
+	default:
+		return false
+	}
+
+	peeked, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPeekedBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peeked), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+
+	for _, code := range throttlingCodes {
+		if bytes.Contains(peeked, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before the retry: what the provider asks
+// for with Retry-After, or the exponential backoff with jitter.
+func (t *Transport) delay(attempt int, resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	delay := t.BaseDelay << uint(attempt)
+	if delay > t.MaxDelay || delay <= 0 {
+		delay = t.MaxDelay
+	}
+
+	// Waits between half and all of the delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
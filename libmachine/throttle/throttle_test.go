@@ -0,0 +1,128 @@
+package throttle
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/machine/libmachine/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+type retryRecorder struct {
+	retries []metrics.Retry
+}
+
+func (r *retryRecorder) RecordOperation(op metrics.Operation) {}
+
+func (r *retryRecorder) RecordRetry(retry metrics.Retry) {
+	r.retries = append(r.retries, retry)
+}
+
+// throttlingServer answers the first requests with the status and body,
+// then with 200.
+func throttlingServer(throttled int, status int, body string) (*httptest.Server, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		payload, _ := ioutil.ReadAll(r.Body)
+		if requests <= throttled {
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+			return
+		}
+		w.Write(payload)
+	}))
+	return server, &requests
+}
+
+func newTestTransport() *Transport {
+	transport := NewTransport("test")
+	transport.BaseDelay = time.Millisecond
+	transport.MaxDelay = 4 * time.Millisecond
+	return transport
+}
+
+func TestRetryTooManyRequests(t *testing.T) {
+	server, requests := throttlingServer(2, http.StatusTooManyRequests, "")
+	defer server.Close()
+
+	recorder := &retryRecorder{}
+	metrics.AddRecorder(recorder)
+
+	client := &http.Client{Transport: newTestTransport()}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "payload", string(body))
+	assert.Equal(t, 3, *requests)
+
+	assert.Len(t, recorder.retries, 2)
+	assert.Equal(t, "test", recorder.retries[1].Provider)
+	assert.Equal(t, "POST", recorder.retries[1].Method)
+	assert.Equal(t, 2, recorder.retries[1].Attempt)
+	assert.Equal(t, http.StatusTooManyRequests, recorder.retries[1].StatusCode)
+}
+
+func TestRetryRequestLimitExceeded(t *testing.T) {
+	server, requests := throttlingServer(1, http.StatusServiceUnavailable, "<Response><Errors><Error><Code>RequestLimitExceeded</Code></Error></Errors></Response>")
+	defer server.Close()
+
+	client := &http.Client{Transport: newTestTransport()}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, *requests)
+}
+
+func TestNoRetryOnOtherErrors(t *testing.T) {
+	server, requests := throttlingServer(1, http.StatusServiceUnavailable, "maintenance")
+	defer server.Close()
+
+	client := &http.Client{Transport: newTestTransport()}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, "maintenance", string(body))
+	assert.Equal(t, 1, *requests)
+}
+
+func TestGiveUpAfterMaxRetries(t *testing.T) {
+	server, requests := throttlingServer(10, http.StatusTooManyRequests, "")
+	defer server.Close()
+
+	transport := newTestTransport()
+	transport.MaxRetries = 3
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, 4, *requests)
+}
+
+func TestDelay(t *testing.T) {
+	transport := &Transport{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	resp := &http.Response{Header: http.Header{}}
+
+	for attempt, max := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second} {
+		delay := transport.delay(attempt, resp)
+		assert.True(t, delay >= max/2 && delay <= max, "attempt %d waits %s", attempt, delay)
+	}
+
+	resp.Header.Set("Retry-After", "7")
+	assert.Equal(t, 7*time.Second, transport.delay(0, resp))
+}
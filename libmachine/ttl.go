@@ -0,0 +1,65 @@
+package libmachine
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// RemoveExpiredMachines removes the machines of the store which outlived
+// their TTL, as RemoveInstance and RemoveMachine do, and returns the names of
+// the removed machines. The protected machines are not removed. Errors with a
+// machine are logged and do not prevent removing the other machines.
+func RemoveExpiredMachines(api API) ([]string, error) {
+	names, err := api.List()
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []string{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		ok, err := h.RemoveIfExpired(func() error {
+			return removeInstance(api, h, false)
+		})
+		if err != nil {
+			log.Warnf("Error removing expired machine %q: %s", name, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		if err := removeMachine(api, name, "expired"); err != nil {
+			log.Warnf("Error removing machine %q from the store: %s", name, err)
+			continue
+		}
+		removed = append(removed, name)
+	}
+
+	return removed, nil
+}
+
+// WatchExpiredMachines runs RemoveExpiredMachines every interval until done
+// is closed.
+func WatchExpiredMachines(api API, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := RemoveExpiredMachines(api); err != nil {
+			log.Warnf("Error removing expired machines: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package libmachine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoveExpiredMachines(t *testing.T) {
+	expired := newPruneTestHost("expired", false)
+	expired.HostOptions = &host.Options{ExpiresAt: time.Now().Add(-time.Minute)}
+	alive := newPruneTestHost("alive", false)
+	alive.HostOptions = &host.Options{ExpiresAt: time.Now().Add(time.Hour)}
+	forever := newPruneTestHost("forever", false)
+	forever.HostOptions = &host.Options{}
+
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{expired, alive, forever},
+	}
+
+	removed, err := RemoveExpiredMachines(&pruneTestAPI{store})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"expired"}, removed)
+	assert.Len(t, store.Hosts, 2)
+}
+
+func TestRemoveExpiredMachinesKeepsProtected(t *testing.T) {
+	protected := newPruneTestHost("protected", false)
+	protected.HostOptions = &host.Options{ExpiresAt: time.Now().Add(-time.Minute), Protected: true}
+
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{protected},
+	}
+
+	removed, err := RemoveExpiredMachines(&pruneTestAPI{store})
+
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+	assert.Len(t, store.Hosts, 1)
+	assert.Equal(t, 0, protected.Driver.(*fakedriver.Driver).Called("Remove"))
+}
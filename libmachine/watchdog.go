@@ -0,0 +1,69 @@
+package libmachine
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/persist"
+)
+
+// CheckMachinesHealth checks the engines of the machines of the store which
+// have a watchdog policy, restarting the ones which stopped answering as
+// their policy says, see host.CheckHealth, and returns the names of the
+// machines found unhealthy. The given names restrict the check to these
+// machines. Errors with a machine are logged and do not prevent checking the
+// other machines.
+func CheckMachinesHealth(api API, names ...string) ([]string, error) {
+	if len(names) == 0 {
+		var err error
+		if names, err = api.List(); err != nil {
+			return nil, err
+		}
+	}
+
+	unhealthy := []string{}
+	for _, name := range names {
+		h, err := api.Load(name)
+		if err != nil {
+			log.Warnf("Error loading machine %q: %s", name, err)
+			continue
+		}
+
+		if h.HostOptions == nil || h.HostOptions.Watchdog == nil {
+			continue
+		}
+
+		found, err := h.CheckHealth()
+		if found {
+			unhealthy = append(unhealthy, name)
+			if h.HostOptions.Watchdog.Action != host.WatchdogNotify {
+				RecordOperationDetails(api, name, persist.AuditRestart, "watchdog "+string(h.HostOptions.Watchdog.Action), err)
+			}
+		}
+		if err != nil {
+			log.Warnf("Error checking the health of machine %q: %s", name, err)
+		}
+	}
+
+	return unhealthy, nil
+}
+
+// WatchMachinesHealth runs CheckMachinesHealth every interval until done is
+// closed.
+func WatchMachinesHealth(api API, interval time.Duration, done <-chan struct{}, names ...string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := CheckMachinesHealth(api, names...); err != nil {
+			log.Warnf("Error checking the health of the machines: %s", err)
+		}
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package libmachine
+
+import (
+	"testing"
+
+	"github.com/docker/machine/drivers/fakedriver"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/docker/machine/libmachine/persist/persisttest"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMachinesHealthSkipsUnwatchedMachines(t *testing.T) {
+	stopped := newPruneTestHost("stopped", false)
+	stopped.HostOptions = &host.Options{
+		Watchdog: &host.WatchdogPolicy{Action: host.WatchdogRestartMachine},
+	}
+	stopped.Driver.(*fakedriver.Driver).MockState = state.Stopped
+
+	unwatched := newPruneTestHost("unwatched", false)
+	unwatched.HostOptions = &host.Options{}
+
+	store := &persisttest.FakeStore{
+		Hosts: []*host.Host{stopped, unwatched},
+	}
+
+	unhealthy, err := CheckMachinesHealth(&pruneTestAPI{store})
+
+	assert.NoError(t, err)
+	assert.Empty(t, unhealthy)
+	assert.Equal(t, []string{"GetState"}, stopped.Driver.(*fakedriver.Driver).Calls)
+	assert.Empty(t, unwatched.Driver.(*fakedriver.Driver).Calls)
+}